@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Validate SQL statements from a file or STDIN"
+	desc := "Checks arbitrary SQL DDL for syntax errors and other problems, without " +
+		"requiring the SQL to be part of a schema directory. The SQL is read from the " +
+		"supplied file path, or from STDIN if the file path is omitted or is \"-\". " +
+		"Each problem is written to STDOUT in the form file:line:column: message, which " +
+		"is suitable for consumption by editors and other tools.\n\n" +
+		"By default, the supported CREATE statements are executed in a workspace, in " +
+		"order to detect all errors for the configured flavor. See the --workspace option " +
+		"for more information. Workspace selection is based on the .skeema config files " +
+		"of the current directory, using the environment supplied as the second " +
+		"command-line arg (default \"production\"). With --offline, no database server " +
+		"is accessed, and only Skeema's own SQL parser is used; this detects malformed " +
+		"or unsupported statements, but not most syntax errors.\n\n" +
+		"An exit code of 0 will be returned if no problems were found; 1 if problems " +
+		"were found; or 2+ if a fatal error occurred."
+
+	cmd := mybase.NewCommand("check-syntax", summary, desc, CheckSyntaxHandler)
+	cmd.AddOption(mybase.BoolOption("offline", 0, false, "Only use the SQL parser, without executing statements in a workspace"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("file", "-", false)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// CheckSyntaxHandler is the handler method for `skeema check-syntax`
+func CheckSyntaxHandler(cfg *mybase.Config) error {
+	filePath := cfg.Get("file")
+	var sql []byte
+	var err error
+	if filePath == "-" {
+		filePath = ""
+		sql, err = io.ReadAll(os.Stdin)
+	} else {
+		sql, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return WrapExitCode(CodeNoInput, err)
+	}
+
+	var wsOpts *workspace.Options
+	if !cfg.GetBool("offline") {
+		dir, err := fs.ParseDir(".", cfg)
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		opts, err := workspaceOptionsForDir(dir)
+		if err != nil {
+			return err
+		}
+		wsOpts = &opts
+	}
+
+	syntaxErrors, err := workspace.CheckSyntax(string(sql), filePath, wsOpts)
+	if err != nil {
+		return err
+	}
+	for _, se := range syntaxErrors {
+		fmt.Println(se.Error())
+	}
+	if len(syntaxErrors) > 0 {
+		return NewExitValue(CodeDifferencesFound, "Found %s", countAndNoun(len(syntaxErrors), "problem", "problems"))
+	}
+	log.Info("No problems found")
+	return nil
+}
+
+// workspaceOptionsForDir returns workspace options based on dir's
+// configuration, for commands which do not otherwise interact with the
+// instance. As with `skeema format` and `skeema lint`, connection errors are
+// ignored with workspace=docker as long as flavor is set, and always ignored
+// with workspace=parse.
+func workspaceOptionsForDir(dir *fs.Dir) (workspace.Options, error) {
+	if dir.ParseError != nil {
+		return workspace.Options{}, WrapExitCode(CodeBadConfig, dir.ParseError)
+	}
+	inst, err := dir.FirstInstance()
+	if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker", "kubernetes", "parse"); wsType == "temp-schema" || (wsType != "parse" && !dir.Config.Changed("flavor")) {
+		if err != nil {
+			return workspace.Options{}, WrapExitCode(CodeBadConfig, err)
+		} else if inst == nil {
+			return workspace.Options{}, NewExitValue(CodeBadConfig, "This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker or workspace=kubernetes), but one is not configured for environment %q.", dir.Config.Get("environment"))
+		}
+	}
+	opts, err := workspace.OptionsForDir(dir, inst)
+	if err != nil {
+		return opts, WrapExitCode(CodeBadConfig, err)
+	}
+	return opts, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	summary := "Compare schemas on two DB servers"
+	desc := "Compares the schemas on two database servers directly, without using the " +
+		"filesystem. The output is a series of DDL commands that, if run on the first " +
+		"server, would cause its schemas to match those of the second server. This is " +
+		"useful for validating replicas, clones, and migration cutovers.\n\n" +
+		"Each host may be supplied as host:port. Other connection options, such as --user " +
+		"and --password, apply to both servers.\n\n" +
+		"By default, all schemas on either server are compared, excluding system schemas " +
+		"and any matching --ignore-schema. Comparisons are strict: purely cosmetic " +
+		"differences in index order, column definitions, and foreign key names are " +
+		"included, as are destructive changes.\n\n" +
+		"An exit code of 0 will be returned if no differences were found; 1 if some " +
+		"differences were found; or 2+ if an error occurred."
+
+	cmd := mybase.NewCommand("compare", summary, desc, CompareHandler)
+	cmd.AddOption(mybase.StringOption("schema", 0, "", "Only compare these comma-separated schema names"))
+	cmd.AddArg("host1", "", true)
+	cmd.AddArg("host2", "", true)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// CompareHandler is the handler method for `skeema compare`
+func CompareHandler(cfg *mybase.Config) error {
+	var dir *fs.Dir
+	var instances []*tengo.Instance
+	for _, host := range []string{cfg.Get("host1"), cfg.Get("host2")} {
+		cfg.SetRuntimeOverride("host", host)
+		var err error
+		if dir, err = fs.ParseDir(".", cfg); err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		inst, err := dir.FirstInstance()
+		if err != nil {
+			return err
+		} else if inst == nil {
+			return NewExitValue(CodeBadConfig, "Unable to determine database server for host %s", host)
+		}
+		instances = append(instances, inst)
+	}
+	if instances[0].String() == instances[1].String() {
+		return NewExitValue(CodeBadUsage, "Cannot compare %s to itself", instances[0])
+	}
+
+	schemaNames, err := compareSchemaNames(dir, instances)
+	if err != nil {
+		return err
+	}
+	mods := tengo.StatementModifiers{
+		AllowUnsafe:            true,
+		StrictIndexOrder:       true,
+		StrictCheckConstraints: true,
+		StrictForeignKeyNaming: true,
+		StrictColumnDefinition: true,
+		CompareMetadata:        true,
+		Flavor:                 instances[0].Flavor(),
+	}
+
+	schemasByName := make([]map[string]*tengo.Schema, len(instances))
+	for n, inst := range instances {
+		schemas, err := inst.Schemas(schemaNames...)
+		if err != nil {
+			return NewExitValue(CodeFatalError, "Cannot examine schemas on %s: %s", inst, err)
+		}
+		schemasByName[n] = make(map[string]*tengo.Schema, len(schemas))
+		for _, s := range schemas {
+			s.StripMatches(dir.IgnorePatterns)
+			schemasByName[n][s.Name] = s
+		}
+	}
+	var diffCount, skipCount int
+	for _, name := range schemaNames {
+		count, skipped := compareSchemas(schemasByName[0][name], schemasByName[1][name], name, mods)
+		diffCount += count
+		skipCount += skipped
+	}
+
+	if skipCount > 0 {
+		return NewExitValue(CodePartialError, "Skipped %s not supported by Skeema", countAndNoun(skipCount, "object", "objects"))
+	} else if diffCount > 0 {
+		return NewExitValue(CodeDifferencesFound, "")
+	}
+	log.Infof("No differences found between %s and %s", instances[0], instances[1])
+	return nil
+}
+
+// compareSchemaNames returns the sorted names of schemas to compare: either
+// the ones listed in the schema option, or else all non-system schemas present
+// on either instance which do not match the ignore-schema option.
+func compareSchemaNames(dir *fs.Dir, instances []*tengo.Instance) ([]string, error) {
+	if names := dir.Config.GetSlice("schema", ',', true); len(names) > 0 {
+		return names, nil
+	}
+	ignoreSchema, err := dir.Config.GetRegexp("ignore-schema")
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	var result []string
+	for _, inst := range instances {
+		names, err := inst.SchemaNames()
+		if err != nil {
+			return nil, NewExitValue(CodeFatalError, "Cannot examine schemas on %s: %s", inst, err)
+		}
+		for _, name := range names {
+			if inst.IsSystemSchema(name) || (ignoreSchema != nil && ignoreSchema.MatchString(name)) {
+				continue
+			}
+			if !slices.Contains(result, name) {
+				result = append(result, name)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// compareSchemas outputs DDL which would transform from into to, returning the
+// number of differences found and the number of objects skipped due to being
+// unsupported. Either schema may be nil if it only exists on one side.
+func compareSchemas(from, to *tengo.Schema, name string, mods tengo.StatementModifiers) (diffCount, skipCount int) {
+	for _, od := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		stmt, err := od.Statement(mods)
+		if err != nil {
+			log.Warnf("Skipping %s in schema %s: %s", od.ObjectKey(), name, err)
+			skipCount++
+			continue
+		} else if stmt == "" {
+			continue
+		}
+		if diffCount == 0 {
+			fmt.Printf("-- schema: %s\n", name)
+		}
+		if compounder, ok := od.(tengo.Compounder); ok && compounder.IsCompoundStatement() {
+			fmt.Printf("DELIMITER //\n%s//\nDELIMITER ;\n", stmt)
+		} else {
+			fmt.Printf("%s;\n", stmt)
+		}
+		diffCount++
+	}
+	return diffCount, skipCount
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Output a shell completion script"
+	desc := "Outputs a script for tab-completion of Skeema commands, options, and " +
+		"option values in the specified shell: \"bash\", \"zsh\", \"fish\", or " +
+		"\"powershell\". Environment names are completed dynamically, based on the " +
+		"environments which configure a host in .skeema files of the current " +
+		"directory, its parents, and its subdirectories.\n\n" +
+		"To load completions in the current bash or zsh session, run " +
+		"`source <(skeema completion bash)` or `source <(skeema completion zsh)`. " +
+		"For fish, run `skeema completion fish | source`. For PowerShell, run " +
+		"`skeema completion powershell | Out-String | Invoke-Expression`. To load " +
+		"completions in all sessions, add the corresponding command to your shell's " +
+		"startup file."
+
+	cmd := mybase.NewCommand("completion", summary, desc, CompletionHandler)
+	cmd.AddOption(mybase.BoolOption("list-environments", 0, false, "Output configured environment names, for use by completion scripts").Hidden())
+	cmd.AddOption(mybase.StringOption("environment", 0, "production", "Environment section of option files to use when listing environments").Hidden())
+	cmd.AddArg("shell", "", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// CompletionHandler is the handler method for `skeema completion`
+func CompletionHandler(cfg *mybase.Config) error {
+	if cfg.GetBool("list-environments") {
+		dir, err := fs.ParseDir(".", cfg)
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		for _, name := range completionEnvironments(dir, cfg) {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	shell := cfg.Get("shell")
+	tmpl, ok := completionTemplates[shell]
+	if shell == "" {
+		return NewExitValue(CodeBadUsage, "Shell name must be supplied: \"bash\", \"zsh\", \"fish\", or \"powershell\"")
+	} else if !ok {
+		return NewExitValue(CodeBadUsage, "Unsupported shell %q: must be \"bash\", \"zsh\", \"fish\", or \"powershell\"", shell)
+	}
+	return tmpl.Execute(os.Stdout, newCompletionSpec(CommandSuite))
+}
+
+// completionEnvironments returns the sorted names of environments which
+// configure a host in dir's option file, those of its subdirectories, or those
+// of its parent directories.
+func completionEnvironments(dir *fs.Dir, cfg *mybase.Config) []string {
+	environments := configuredEnvironments(dir, 5)
+	if parentFiles, _, err := fs.ParentOptionFiles(dir.Path, cfg); err == nil {
+		for _, f := range parentFiles {
+			for _, name := range append(f.SectionsWithOption("host"), f.SectionsWithOption("host-wrapper")...) {
+				if name != "" && !strings.HasPrefix(name, util.ProfileSectionPrefix) && !slices.Contains(environments, name) {
+					environments = append(environments, name)
+				}
+			}
+		}
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// completionShells lists the shells supported by `skeema completion`.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionOption describes an option for purposes of shell completion.
+type completionOption struct {
+	Name        string // long name, without leading dashes
+	Shorthand   string // single-character shorthand, or empty string if none
+	Description string
+	TakesValue  bool
+	Values      []string // valid values, if the option is limited to a known set
+}
+
+// completionCommand describes a subcommand for purposes of shell completion.
+type completionCommand struct {
+	Name         string
+	Summary      string
+	Options      []completionOption // options specific to this command
+	Environments bool               // true if the command's positional args include an environment name
+	ArgValues    []string           // valid values for the command's positional args, if known
+}
+
+// completionSpec describes a command suite for purposes of shell completion.
+type completionSpec struct {
+	Program  string
+	Global   []completionOption
+	Commands []completionCommand
+}
+
+var reValidValues = regexp.MustCompile(`\(valid values: ([^)]*)\)`)
+var reQuotedValue = regexp.MustCompile(`"([^"]*)"`)
+
+// newCompletionOptions converts options to completionOptions, sorted by name.
+// Options with names in the exclude map are omitted, as are hidden options.
+// Boolean options which default to true also include their "skip-" form.
+func newCompletionOptions(options map[string]*mybase.Option, exclude map[string]*mybase.Option) (result []completionOption) {
+	for name, opt := range options {
+		if opt.HiddenOnCLI || exclude[name] != nil {
+			continue
+		}
+		co := completionOption{
+			Name:        name,
+			Description: opt.Description,
+			TakesValue:  opt.Type == mybase.OptionTypeString,
+		}
+		if opt.Shorthand != 0 {
+			co.Shorthand = string(opt.Shorthand)
+		}
+		if matches := reValidValues.FindStringSubmatch(opt.Description); matches != nil {
+			for _, valueMatch := range reQuotedValue.FindAllStringSubmatch(matches[1], -1) {
+				co.Values = append(co.Values, valueMatch[1])
+			}
+		}
+		result = append(result, co)
+		if opt.Type == mybase.OptionTypeBool && opt.Default != "" {
+			result = append(result, completionOption{
+				Name:        "skip-" + name,
+				Description: "Negated form of --" + name,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// newCompletionSpec returns a completionSpec describing suite's subcommands and
+// options.
+func newCompletionSpec(suite *mybase.Command) *completionSpec {
+	globalOptions := suite.Options()
+	spec := &completionSpec{
+		Program: suite.Name,
+		Global:  newCompletionOptions(globalOptions, nil),
+	}
+	commandNames := make([]string, 0, len(suite.SubCommands))
+	for name := range suite.SubCommands {
+		commandNames = append(commandNames, name)
+	}
+	sort.Strings(commandNames)
+	for _, name := range commandNames {
+		cmd := suite.SubCommands[name]
+		cc := completionCommand{
+			Name:         name,
+			Summary:      cmd.Summary,
+			Options:      newCompletionOptions(cmd.Options(), globalOptions),
+			Environments: cmd.HasArg("environment"),
+		}
+		if cmd.HasArg("command") {
+			cc.ArgValues = commandNames
+		} else if cmd.HasArg("shell") {
+			cc.ArgValues = completionShells
+		}
+		spec.Commands = append(spec.Commands, cc)
+	}
+	return spec
+}
+
+// completionEnumOption is an option with enumerated valid values, along with
+// the name of the command it belongs to.
+type completionEnumOption struct {
+	Command string // empty string for global options
+	completionOption
+}
+
+// EnumOptions returns global options followed by each command's options,
+// including only options which have enumerated valid values.
+func (spec *completionSpec) EnumOptions() (result []completionEnumOption) {
+	add := func(command string, options []completionOption) {
+		for _, opt := range options {
+			if len(opt.Values) > 0 {
+				result = append(result, completionEnumOption{command, opt})
+			}
+		}
+	}
+	add("", spec.Global)
+	for _, cmd := range spec.Commands {
+		add(cmd.Name, cmd.Options)
+	}
+	return result
+}
+
+// zshOptionSpecs returns optspec args for zsh's _arguments, for completing opt.
+func zshOptionSpecs(opt completionOption) []string {
+	desc := strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(opt.Description)
+	var action string
+	if len(opt.Values) > 0 {
+		action = ":value:(" + strings.Join(opt.Values, " ") + ")"
+	} else if opt.TakesValue {
+		action = ":value: "
+	}
+	var specs []string
+	if opt.Shorthand != "" {
+		short := "-" + opt.Shorthand
+		if opt.TakesValue {
+			short += "+"
+		}
+		specs = append(specs, short+"["+desc+"]"+action)
+	}
+	long := "--" + opt.Name
+	if opt.TakesValue {
+		long += "="
+	}
+	specs = append(specs, long+"["+desc+"]"+action)
+	for n := range specs {
+		specs[n] = shellSingleQuote(specs[n])
+	}
+	return specs
+}
+
+// fishOptionArgs returns args for fish's complete builtin, for completing opt.
+func fishOptionArgs(opt completionOption) string {
+	args := " -l " + opt.Name
+	if opt.Shorthand != "" {
+		args += " -s " + fishQuote(opt.Shorthand)
+	}
+	args += " -d " + fishQuote(opt.Description)
+	if len(opt.Values) > 0 {
+		args += " -x -a " + fishQuote(strings.Join(opt.Values, " "))
+	} else if opt.TakesValue {
+		args += " -r"
+	}
+	return args
+}
+
+// shellSingleQuote wraps s in single quotes for use in bash or zsh.
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote wraps s in single quotes for use in fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// powerShellQuote wraps s in single quotes for use in PowerShell.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// powerShellArray returns a PowerShell array expression of quoted strings.
+func powerShellArray(values []string) string {
+	quoted := make([]string, len(values))
+	for n, value := range values {
+		quoted[n] = powerShellQuote(value)
+	}
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
+
+// CommandNames returns the names of spec's commands.
+func (spec *completionSpec) CommandNames() []string {
+	names := make([]string, len(spec.Commands))
+	for n, cmd := range spec.Commands {
+		names[n] = cmd.Name
+	}
+	return names
+}
+
+// optionWords returns a space-separated list of completion words for options:
+// "--name=" for options taking a value, or "--name" otherwise. Shorthands are
+// omitted.
+func optionWords(options []completionOption) string {
+	words := make([]string, len(options))
+	for n, opt := range options {
+		words[n] = "--" + opt.Name
+		if opt.TakesValue {
+			words[n] += "="
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var completionFuncs = template.FuncMap{
+	"join":            strings.Join,
+	"optionWords":     optionWords,
+	"zshOptionSpecs":  zshOptionSpecs,
+	"fishOptionArgs":  fishOptionArgs,
+	"shellQuote":      shellSingleQuote,
+	"fishQuote":       fishQuote,
+	"powerShellQuote": powerShellQuote,
+	"powerShellArray": powerShellArray,
+}
+
+var completionTemplates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Funcs(completionFuncs).Parse(`# bash completion for {{.Program}}
+# To load completions in the current shell: source <({{.Program}} completion bash)
+
+_{{.Program}}() {
+	local cur prev opt cmd opts values i
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+	# The command is the first word which isn't an option or option value
+	for ((i=1; i<COMP_CWORD; i++)); do
+		if [[ "${COMP_WORDS[i]}" == "=" || "${COMP_WORDS[i-1]}" == "=" ]]; then
+			continue
+		elif [[ "${COMP_WORDS[i]}" != -* ]]; then
+			cmd="${COMP_WORDS[i]}"
+			break
+		fi
+	done
+	if [[ -z "$cmd" ]]; then
+		COMPREPLY=($(compgen -W "{{join .CommandNames " "}}" -- "$cur"))
+		return
+	fi
+
+	# Complete values of options in form --name=value
+	if [[ "$cur" == "=" ]]; then
+		opt="$prev"
+		cur=""
+	elif [[ "$prev" == "=" ]]; then
+		opt="${COMP_WORDS[COMP_CWORD-2]}"
+	fi
+	if [[ -n "$opt" ]]; then
+		case "$cmd $opt" in
+{{- range .EnumOptions}}
+		{{if .Command}}"{{.Command}} --{{.Name}}"{{else}}*" --{{.Name}}"{{end}}) values="{{join .Values " "}}" ;;
+{{- end}}
+		esac
+		COMPREPLY=($(compgen -W "$values" -- "$cur"))
+		return
+	fi
+
+	if [[ "$cur" == -* ]]; then
+		opts="{{optionWords .Global}}"
+		case "$cmd" in
+{{- range .Commands}}{{if .Options}}
+		{{.Name}}) opts="$opts {{optionWords .Options}}" ;;
+{{- end}}{{end}}
+		esac
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+		[[ "${COMPREPLY[0]}" == *= ]] && compopt -o nospace
+		return
+	fi
+
+	case "$cmd" in
+{{- range .Commands}}{{if .Environments}}
+	{{.Name}}) values="$({{$.Program}} completion --list-environments 2>/dev/null)" ;;
+{{- else if .ArgValues}}
+	{{.Name}}) values="{{join .ArgValues " "}}" ;;
+{{- end}}{{end}}
+	esac
+	COMPREPLY=($(compgen -W "$values" -- "$cur"))
+}
+
+complete -F _{{.Program}} {{.Program}}
+`)),
+
+	"zsh": template.Must(template.New("zsh").Funcs(completionFuncs).Parse(`#compdef {{.Program}}
+# zsh completion for {{.Program}}
+# To load completions in the current shell: source <({{.Program}} completion zsh)
+
+_{{.Program}}_environments() {
+	local -a environments
+	environments=(${(f)"$({{.Program}} completion --list-environments 2>/dev/null)"})
+	_describe -t environments 'environment' environments
+}
+
+_{{.Program}}_commands() {
+	local -a commands
+	commands=(
+{{- range .Commands}}
+		{{shellQuote (print .Name ":" .Summary)}}
+{{- end}}
+	)
+	_describe -t commands '{{.Program}} command' commands
+}
+
+_{{.Program}}() {
+	local curcontext="$curcontext" state line
+	local -a global_options
+	global_options=(
+{{- range .Global}}{{range zshOptionSpecs .}}
+		{{.}}
+{{- end}}{{end}}
+	)
+	_arguments -C $global_options '1: :_{{.Program}}_commands' '*:: :->args'
+	case $state in
+	args)
+		case $words[1] in
+{{- range .Commands}}
+		{{.Name}})
+			_arguments $global_options
+{{- range .Options}}{{range zshOptionSpecs .}} \
+				{{.}}
+{{- end}}{{end}}
+{{- if .Environments}} \
+				'*: :_{{$.Program}}_environments'
+{{- else if .ArgValues}} \
+				'*: :({{join .ArgValues " "}})'
+{{- end}}
+			;;
+{{- end}}
+		esac
+		;;
+	esac
+}
+
+if [ "$funcstack[1]" = "_{{.Program}}" ]; then
+	_{{.Program}} "$@"
+else
+	compdef _{{.Program}} {{.Program}}
+fi
+`)),
+
+	"fish": template.Must(template.New("fish").Funcs(completionFuncs).Parse(`# fish completion for {{.Program}}
+# To load completions in the current shell: {{.Program}} completion fish | source
+
+function __{{.Program}}_environments
+	{{.Program}} completion --list-environments 2>/dev/null
+end
+
+complete -c {{.Program}} -f
+{{- range .Commands}}
+complete -c {{$.Program}} -n __fish_use_subcommand -a {{.Name}} -d {{fishQuote .Summary}}
+{{- end}}
+{{- range .Global}}
+complete -c {{$.Program}}{{fishOptionArgs .}}
+{{- end}}
+{{- range $cmd := .Commands}}
+{{- range .Options}}
+complete -c {{$.Program}} -n '__fish_seen_subcommand_from {{$cmd.Name}}'{{fishOptionArgs .}}
+{{- end}}
+{{- if .Environments}}
+complete -c {{$.Program}} -n '__fish_seen_subcommand_from {{$cmd.Name}}' -a '(__{{$.Program}}_environments)' -d environment
+{{- else if .ArgValues}}
+complete -c {{$.Program}} -n '__fish_seen_subcommand_from {{$cmd.Name}}' -a {{fishQuote (join .ArgValues " ")}}
+{{- end}}
+{{- end}}
+`)),
+
+	"powershell": template.Must(template.New("powershell").Funcs(completionFuncs).Parse(`# PowerShell completion for {{.Program}}
+# To load completions in the current shell: {{.Program}} completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName '{{.Program}}' -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$globalOptions = @(
+{{- range .Global}}
+		@{ Name = '--{{.Name}}'; Description = {{powerShellQuote .Description}}; Values = {{powerShellArray .Values}} }
+{{- end}}
+	)
+	$commands = [ordered]@{
+{{- range .Commands}}
+		'{{.Name}}' = @{
+			Summary = {{powerShellQuote .Summary}}
+			Environments = ${{.Environments}}
+			ArgValues = {{powerShellArray .ArgValues}}
+			Options = @(
+{{- range .Options}}
+				@{ Name = '--{{.Name}}'; Description = {{powerShellQuote .Description}}; Values = {{powerShellArray .Values}} }
+{{- end}}
+			)
+		}
+{{- end}}
+	}
+
+	# The command is the first word before the cursor which isn't an option
+	$command = $null
+	foreach ($element in ($commandAst.CommandElements | Select-Object -Skip 1)) {
+		if ($element.Extent.EndOffset -ge $cursorPosition) {
+			break
+		}
+		$word = $element.ToString()
+		if (-not $word.StartsWith('-')) {
+			$command = $word
+			break
+		}
+	}
+
+	if ($null -eq $command) {
+		$candidates = $commands.Keys | ForEach-Object { @{ Text = $_; Description = $commands[$_].Summary } }
+	} elseif (-not $commands.Contains($command)) {
+		return
+	} elseif ($wordToComplete -match '^(--[\w-]+)=') {
+		$name = $Matches[1]
+		$option = ($globalOptions + $commands[$command].Options) | Where-Object { $_.Name -eq $name } | Select-Object -First 1
+		$candidates = $option.Values | ForEach-Object { @{ Text = "$name=$_"; Description = $_ } }
+	} elseif ($wordToComplete.StartsWith('-')) {
+		$candidates = ($globalOptions + $commands[$command].Options) | ForEach-Object { @{ Text = $_.Name; Description = $_.Description } }
+	} elseif ($commands[$command].Environments) {
+		$candidates = & '{{.Program}}' completion --list-environments 2>$null | ForEach-Object { @{ Text = $_; Description = 'environment' } }
+	} else {
+		$candidates = $commands[$command].ArgValues | ForEach-Object { @{ Text = $_; Description = $_ } }
+	}
+	$candidates | Where-Object { $_.Text -like "$wordToComplete*" } | ForEach-Object {
+		[System.Management.Automation.CompletionResult]::new($_.Text, $_.Text, 'ParameterValue', $_.Description)
+	}
+}
+`)),
+}
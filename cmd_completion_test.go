@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	suite := mybase.NewCommandSuite("skeema", "1.0.0", "test suite")
+	suite.AddOptions("global",
+		mybase.StringOption("user", 'u', "root", "Username to connect to database host"),
+		mybase.StringOption("ssl-mode", 0, "", `Connection security (valid values: "disabled", "preferred", "required")`),
+		mybase.StringOption("host", 0, "", "Database hostname").Hidden(),
+	)
+	cmd := mybase.NewCommand("diff", "Compare a DB instance's schemas and tables to the filesystem", "", nil)
+	cmd.AddOption(mybase.BoolOption("verify", 0, true, "Test all generated ALTER statements on temp schema to verify correctness"))
+	cmd.AddOption(mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`))
+	cmd.AddArg("environment", "production", false)
+	suite.AddSubCommand(cmd)
+	cmd = mybase.NewCommand("completion", "Output a shell completion script", "", nil)
+	cmd.AddArg("shell", "", false)
+	suite.AddSubCommand(cmd)
+
+	spec := newCompletionSpec(suite)
+	if names := strings.Join(spec.CommandNames(), " "); names != "completion diff help version" {
+		t.Errorf("Unexpected command names: %s", names)
+	}
+	if words := optionWords(spec.Commands[1].Options); words != "--alter-lock= --skip-verify --verify" {
+		t.Errorf("Unexpected options for diff: %s", words)
+	}
+	if words := optionWords(spec.Global); strings.Contains(words, "--host") || !strings.Contains(words, "--ssl-mode=") {
+		t.Errorf("Unexpected global options: %s", words)
+	}
+	if !spec.Commands[1].Environments || spec.Commands[0].Environments || len(spec.Commands[0].ArgValues) != len(completionShells) {
+		t.Errorf("Unexpected positional arg completion: %+v", spec.Commands)
+	}
+
+	expected := map[string][]string{
+		"bash": {
+			"COMPREPLY=($(compgen -W \"completion diff help version\" -- \"$cur\"))\n",
+			"\t\t*\" --ssl-mode\") values=\"disabled preferred required\" ;;\n",
+			"\t\t\"diff --alter-lock\") values=\"none shared exclusive\" ;;\n",
+			"\t\tdiff) opts=\"$opts --alter-lock= --skip-verify --verify\" ;;\n",
+			"\tdiff) values=\"$(skeema completion --list-environments 2>/dev/null)\" ;;\n",
+			"\thelp) values=\"completion diff help version\" ;;\n",
+			"complete -F _skeema skeema\n",
+		},
+		"zsh": {
+			"\t\t'diff:Compare a DB instance'\\''s schemas and tables to the filesystem'\n",
+			"\t\t'-u+[Username to connect to database host]:value: '\n",
+			"\t\t'--ssl-mode=[Connection security (valid values: \"disabled\", \"preferred\", \"required\")]:value:(disabled preferred required)'\n",
+			"\t\t\t_arguments $global_options \\\n\t\t\t\t'--alter-lock=[",
+			"\t\t\t\t'--skip-verify[Negated form of --verify]' \\\n",
+			"\t\t\t\t'*: :_skeema_environments'\n",
+			"\t\t\t\t'*: :(bash zsh fish powershell)'\n",
+		},
+		"fish": {
+			"complete -c skeema -n __fish_use_subcommand -a diff -d 'Compare a DB instance\\'s schemas and tables to the filesystem'\n",
+			"complete -c skeema -l user -s 'u' -d 'Username to connect to database host' -r\n",
+			"complete -c skeema -n '__fish_seen_subcommand_from diff' -l alter-lock -d 'Apply a LOCK clause to all ALTER TABLEs (valid values: \"none\", \"shared\", \"exclusive\")' -x -a 'none shared exclusive'\n",
+			"complete -c skeema -n '__fish_seen_subcommand_from diff' -a '(__skeema_environments)' -d environment\n",
+		},
+		"powershell": {
+			"\t\t'diff' = @{\n\t\t\tSummary = 'Compare a DB instance''s schemas and tables to the filesystem'\n\t\t\tEnvironments = $true\n\t\t\tArgValues = @()\n",
+			"\t\t\t\t@{ Name = '--alter-lock'; Description = 'Apply a LOCK clause to all ALTER TABLEs (valid values: \"none\", \"shared\", \"exclusive\")'; Values = @('none', 'shared', 'exclusive') }\n",
+			"\t\t\tArgValues = @('bash', 'zsh', 'fish', 'powershell')\n",
+		},
+	}
+	for shell, substrings := range expected {
+		var b strings.Builder
+		if err := completionTemplates[shell].Execute(&b, spec); err != nil {
+			t.Fatalf("Unexpected error executing %s template: %v", shell, err)
+		}
+		script := b.String()
+		for _, substr := range substrings {
+			if !strings.Contains(script, substr) {
+				t.Errorf("Expected %s script to contain %q, but it did not. Full output:\n%s", shell, substr, script)
+			}
+		}
+	}
+}
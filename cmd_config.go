@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Inspect resolved configuration of a directory tree"
+	desc := "Provides subcommands for inspecting how options in .skeema files resolve " +
+		"across directories and environments."
+	suite := mybase.NewCommandSuite("config", summary, desc)
+
+	summary = "Compare resolved options between two environments"
+	desc = "Compares the resolved option values of the current directory and its " +
+		"subdirectories between two environments, and outputs each option that differs. " +
+		"This is useful for auditing environment-specific configuration, for example to " +
+		"catch allow-unsafe being enabled in production but not in staging, or to review " +
+		"which hosts and flavors each environment maps to.\n\n" +
+		"All options of `skeema push` are compared, including linter and workspace " +
+		"options. To reduce noise, a difference inherited unchanged from a parent " +
+		"directory is only shown for the parent. Password values are compared but never displayed.\n\n" +
+		"An exit code of 0 will be returned if no differences were found; 1 if some " +
+		"differences were found; or 2+ if an error occurred."
+
+	cmd := mybase.NewCommand("diff", summary, desc, ConfigDiffHandler)
+	cmd.AddArg("environment", "", true)
+	cmd.AddArg("other-environment", "", true)
+	suite.AddSubCommand(cmd)
+	CommandSuite.AddSubCommand(suite)
+}
+
+// ConfigDiffHandler is the handler method for `skeema config diff`
+func ConfigDiffHandler(cfg *mybase.Config) error {
+	envs := [2]string{cfg.Get("environment"), cfg.Get("other-environment")}
+	if envs[0] == envs[1] {
+		return NewExitValue(CodeBadUsage, "Two different environment names must be supplied")
+	}
+	var dirs [2]*fs.Dir
+	for n, env := range envs {
+		envCfg, err := configForEnvironment(cfg, env)
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		if dirs[n], err = fs.ParseDir(".", envCfg); err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+	}
+
+	diffCount, err := configDiffWalker(os.Stdout, dirs, envs, nil, 5)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if diffCount > 0 {
+		return NewExitValue(CodeDifferencesFound, "Found %s between environments %s and %s",
+			countAndNoun(diffCount, "option difference", "option differences"), envs[0], envs[1])
+	}
+	log.Infof("No option differences found between environments %s and %s", envs[0], envs[1])
+	return nil
+}
+
+// configForEnvironment returns a Config for the `skeema push` command, using
+// the same command-line options as cfg, but with the supplied environment
+// name. Global option files are re-read, so that their sections for the
+// supplied environment are used. Using push's option set permits comparison of
+// all options relevant to applying changes, including linter and workspace
+// options.
+func configForEnvironment(cfg *mybase.Config, environment string) (*mybase.Config, error) {
+	push, ok := CommandSuite.SubCommands["push"]
+	if !ok {
+		return nil, errors.New("Unable to locate push command")
+	}
+	cli := &mybase.CommandLine{
+		InvokedAs:    cfg.CLI.InvokedAs,
+		Command:      push,
+		OptionValues: cfg.CLI.OptionValues,
+		ArgValues:    []string{environment},
+	}
+	envCfg := mybase.NewConfig(cli)
+	envCfg.IsTest = cfg.IsTest
+	util.AddGlobalConfigFiles(envCfg)
+	return envCfg, nil
+}
+
+// configDiffWalker writes option differences between dirs[0] and dirs[1] to
+// w, and then recurses into subdirectories. dirs[0] and dirs[1] must have the
+// same path, but be parsed using the two environments in envs. Differences
+// identical to those already reported in parentDiffs are not repeated. The
+// total number of differences written is returned.
+func configDiffWalker(w io.Writer, dirs [2]*fs.Dir, envs [2]string, parentDiffs map[string][2]string, maxDepth int) (int, error) {
+	for _, dir := range dirs {
+		if dir.ParseError != nil {
+			return 0, fmt.Errorf("Unable to parse directory %s: %w", dir.RelPath(), dir.ParseError)
+		}
+	}
+	diffs := configDiffs(dirs[0].Config, dirs[1].Config)
+	names := make([]string, 0, len(diffs))
+	for name, values := range diffs {
+		if parentDiffs == nil || parentDiffs[name] != values {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		options := dirs[0].Config.CLI.Command.Options()
+		fmt.Fprintf(w, "-- dir: %s\n", dirs[0].RelPath())
+		for _, name := range names {
+			opt := options[name]
+			fmt.Fprintf(w, "%s: %s=%s %s=%s\n", name,
+				envs[0], configDisplayValue(opt, diffs[name][0]),
+				envs[1], configDisplayValue(opt, diffs[name][1]))
+		}
+	}
+	diffCount := len(names)
+
+	var subdirs [2][]*fs.Dir
+	for n := range dirs {
+		var err error
+		if subdirs[n], err = dirs[n].Subdirs(); err != nil {
+			return diffCount, fmt.Errorf("Cannot list subdirs of %s: %w", dirs[n], err)
+		}
+	}
+	if len(subdirs[0]) > 0 && maxDepth <= 0 {
+		return diffCount, fmt.Errorf("Not walking subdirs of %s: max depth reached", dirs[0])
+	}
+	for n := range subdirs[0] {
+		subCount, err := configDiffWalker(w, [2]*fs.Dir{subdirs[0][n], subdirs[1][n]}, envs, diffs, maxDepth-1)
+		diffCount += subCount
+		if err != nil {
+			return diffCount, err
+		}
+	}
+	return diffCount, nil
+}
+
+// configDiffs returns a map of option name to a pair of values, for each
+// option whose value differs between cfgs a and b. The environment option
+// itself is excluded.
+func configDiffs(a, b *mybase.Config) map[string][2]string {
+	diffs := make(map[string][2]string)
+	for name, opt := range a.CLI.Command.Options() {
+		if name == "environment" {
+			continue
+		}
+		values := [2]string{configValue(a, opt), configValue(b, opt)}
+		if values[0] != values[1] {
+			diffs[name] = values
+		}
+	}
+	return diffs
+}
+
+// configValue returns a normalized representation of opt's value in cfg.
+func configValue(cfg *mybase.Config, opt *mybase.Option) string {
+	if opt.Type == mybase.OptionTypeBool {
+		return strconv.FormatBool(cfg.GetBool(opt.Name))
+	}
+	return cfg.Get(opt.Name)
+}
+
+// configDisplayValue returns a representation of value suitable for output,
+// never revealing passwords.
+func configDisplayValue(opt *mybase.Option, value string) string {
+	if opt.Type == mybase.OptionTypeBool {
+		return value
+	} else if opt.Name == "password" && value != "" {
+		return "<hidden>"
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func TestConfigDiffWalker(t *testing.T) {
+	baseDir := t.TempDir()
+	writeFile := func(path, contents string) {
+		t.Helper()
+		path = filepath.Join(baseDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unexpected error from MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Unexpected error from WriteFile: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(baseDir, ".git"), 0755); err != nil {
+		t.Fatalf("Unexpected error from Mkdir: %v", err)
+	}
+	writeFile(".skeema", "[staging]\nhost=stg-db\npassword=abc\n\n[production]\nhost=prod-db\nallow-unsafe\npassword=xyz\n")
+	writeFile("product/.skeema", "schema=product\n[production]\nlint-pk=error\n")
+	writeFile("analytics/.skeema", "schema=analytics\n")
+
+	// Global option files must also use each environment's section
+	fs.WriteTestFile(t, "fake-etc/skeema", "[production]\nalter-algorithm=inplace\n")
+	defer os.RemoveAll("fake-etc")
+
+	cfg := mybase.ParseFakeCLI(t, CommandSuite, "skeema config diff staging production")
+	util.AddGlobalConfigFiles(cfg)
+	envs := [2]string{"staging", "production"}
+	var dirs [2]*fs.Dir
+	for n, env := range envs {
+		envCfg, err := configForEnvironment(cfg, env)
+		if err != nil {
+			t.Fatalf("Unexpected error from configForEnvironment: %v", err)
+		}
+		if dirs[n], err = fs.ParseDir(baseDir, envCfg); err != nil {
+			t.Fatalf("Unexpected error from ParseDir: %v", err)
+		}
+	}
+
+	var b strings.Builder
+	diffCount, err := configDiffWalker(&b, dirs, envs, nil, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error from configDiffWalker: %v", err)
+	}
+	expected := "-- dir: .\n" +
+		"allow-unsafe: staging=false production=true\n" +
+		"alter-algorithm: staging=\"\" production=\"inplace\"\n" +
+		"host: staging=\"stg-db\" production=\"prod-db\"\n" +
+		"password: staging=<hidden> production=<hidden>\n" +
+		"-- dir: product\n" +
+		"lint-pk: staging=\"warning\" production=\"error\"\n"
+	if b.String() != expected {
+		t.Errorf("Unexpected output from configDiffWalker:\n%s\nExpected:\n%s", b.String(), expected)
+	}
+	if diffCount != 5 {
+		t.Errorf("Expected configDiffWalker to return 5 differences, instead found %d", diffCount)
+	}
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
 )
 
 func init() {
@@ -17,10 +24,21 @@ func init() {
 		"top of the file. If no environment name is supplied, the default is " +
 		"\"production\".\n\n" +
 		"The `skeema diff` command is equivalent to running `skeema push` with its --dry-run option enabled.\n\n" +
+		"With --against-dump, the filesystem is instead compared to a schema-only dump " +
+		"from mysqldump (optionally gzip-compressed), mydumper, or MySQL Shell's " +
+		"util.dumpInstance / util.dumpSchemas, without accessing the " +
+		"DB server's schemas. Both sides are converted using a workspace; see the " +
+		"--workspace option for more information. The output is a series of DDL " +
+		"commands that would cause the dump's schemas to match the filesystem.\n\n" +
+		"With --from-git, the filesystem is instead compared to the *.sql files as of " +
+		"the supplied git ref, for reviewing changes without a database server " +
+		"connection. This also uses a workspace to convert both sides.\n\n" +
 		"An exit code of 0 will be returned if no differences were found; 1 if some " +
 		"differences were found; or 2+ if an error occurred."
 
 	cmd := mybase.NewCommand("diff", summary, desc, DiffHandler)
+	cmd.AddOption(mybase.StringOption("against-dump", 0, "", "Compare to schema-only dump file, or mydumper or MySQL Shell dump dir, at this path instead of a DB server"))
+	cmd.AddOption(mybase.StringOption("from-git", 0, "", "Compare to the *.sql files as of this git ref, instead of a DB server"))
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
 	clonePushOptionsToDiff()
@@ -28,6 +46,14 @@ func init() {
 
 // DiffHandler is the handler method for `skeema diff`
 func DiffHandler(cfg *mybase.Config) error {
+	if dumpPath, ref := cfg.Get("against-dump"), cfg.Get("from-git"); dumpPath != "" && ref != "" {
+		return NewExitValue(CodeBadUsage, "Options --against-dump and --from-git cannot be used together")
+	} else if dumpPath != "" {
+		return diffAgainstDump(cfg, dumpPath)
+	} else if ref != "" {
+		return diffAgainstGit(cfg, ref)
+	}
+
 	// We just delegate to PushHandler, forcing dry-run to be enabled
 	cfg.SetRuntimeOverride("dry-run", "1")
 	return PushHandler(cfg)
@@ -47,12 +73,42 @@ func clonePushOptionsToDiff() {
 		"allow-unsafe":    "Permit generating ALTER or DROP operations that are potentially destructive",
 		"alter-wrapper":   "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
 		"brief":           "Don't output DDL to STDOUT; instead output list of database servers with at least one difference",
+		"explain":         "Don't output DDL to STDOUT; instead output a plain-language summary and impact class of each change",
+		"plan":            "Only output statements exactly matching this plan file, or report any drift since its creation",
+		"save-plan":       "Write the generated statements and target fingerprints to this plan file, for use with `skeema push --plan`",
+		"save-rollback":   "Write a SQL script to this file which reverts the generated statements, where possible",
+		"report":          "Write a self-contained HTML report to this file, with a side-by-side diff of each changed object's CREATE statement",
 		"safe-below-size": "Always permit generating destructive operations for tables below this size in bytes",
 	}
 	hiddenRewrites := map[string]bool{
-		"brief":              false,
-		"dry-run":            true,
-		"foreign-key-checks": true,
+		"brief":                   false,
+		"explain":                 false,
+		"save-plan":               false,
+		"dry-run":                 true,
+		"foreign-key-checks":      true,
+		"before-push":             true,
+		"after-push":              true,
+		"before-statement":        true,
+		"after-statement":         true,
+		"canary":                  true,
+		"canary-soak":             true,
+		"replicas":                true,
+		"max-replica-lag":         true,
+		"retry-attempts":          true,
+		"retry-backoff":           true,
+		"mdl-report-after":        true,
+		"mdl-kill-idle":           true,
+		"mdl-kill-users":          true,
+		"init-sql":                true,
+		"statement-timeout":       true,
+		"wsrep-osu-method":        true,
+		"wsrep-max-recv-queue":    true,
+		"verify-writable":         true,
+		"verify-replicas":         true,
+		"state-file":              true,
+		"resume":                  true,
+		"interactive":             true,
+		"verify-replicas-timeout": true,
 	}
 
 	diffOptions := diff.Options()
@@ -72,3 +128,171 @@ func clonePushOptionsToDiff() {
 		diff.AddOption(&diffOpt)
 	}
 }
+
+// diffAgainstDump compares the filesystem representation of schemas to the
+// schema-only dump at dumpPath, rather than to live database servers.
+func diffAgainstDump(cfg *mybase.Config, dumpPath string) error {
+	dumpSchemas, err := fs.ParseDump(dumpPath)
+	if err != nil {
+		return WrapExitCode(CodeNoInput, err)
+	}
+	return diffAgainstBaseline(cfg, func(dir *fs.Dir) (*fs.LogicalSchema, error) {
+		ls := dumpSchemaForDir(dir, dumpSchemas)
+		if ls == nil {
+			log.Warnf("Skipping %s: no corresponding schema found in dump", dir)
+		}
+		return ls, nil
+	})
+}
+
+// diffAgainstGit compares the filesystem representation of schemas to their
+// representation at the supplied git ref, rather than to live database
+// servers.
+func diffAgainstGit(cfg *mybase.Config, ref string) error {
+	return diffAgainstBaseline(cfg, func(dir *fs.Dir) (*fs.LogicalSchema, error) {
+		return fs.ParseGitRevision(dir, ref)
+	})
+}
+
+// baselineFunc returns the LogicalSchema which a dir's filesystem schema should
+// be compared to, or nil if the dir should be skipped.
+type baselineFunc func(dir *fs.Dir) (*fs.LogicalSchema, error)
+
+// diffAgainstBaseline walks the dir tree, comparing each dir's schema to the
+// one returned by baseline.
+func diffAgainstBaseline(cfg *mybase.Config, baseline baselineFunc) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	diffCount, skipCount := baselineDiffWalker(dir, baseline, 5)
+	if skipCount > 0 {
+		return NewExitValue(CodeFatalError, "Skipped %s due to errors", countAndNoun(skipCount, "operation", "operations"))
+	} else if diffCount > 0 {
+		return NewExitValue(CodeDifferencesFound, "")
+	}
+	return nil
+}
+
+// baselineDiffWalker compares each directory containing *.sql files to the
+// corresponding baseline schema, recursing into subdirectories. It returns the
+// number of differences found, and the number of directories that were
+// skipped due to errors.
+func baselineDiffWalker(dir *fs.Dir, baseline baselineFunc, maxDepth int) (diffCount, skipCount int) {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError)
+		return 0, 1
+	}
+	if len(dir.LogicalSchemas) > 0 {
+		if count, err := diffDirAgainstBaseline(dir, baseline); err != nil {
+			log.Errorf("Skipping directory %s due to error: %s", dir.RelPath(), err)
+			skipCount++
+		} else {
+			diffCount += count
+		}
+	}
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return diffCount, skipCount + 1
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Errorf("Not walking subdirs of %s: max depth reached", dir)
+		return diffCount, skipCount + 1
+	}
+	for _, sub := range subdirs {
+		subDiffCount, subSkipCount := baselineDiffWalker(sub, baseline, maxDepth-1)
+		diffCount += subDiffCount
+		skipCount += subSkipCount
+	}
+	return diffCount, skipCount
+}
+
+// diffDirAgainstBaseline outputs DDL which would transform the baseline
+// version of dir's schema into the filesystem version, returning the number of
+// differences found.
+func diffDirAgainstBaseline(dir *fs.Dir, baseline baselineFunc) (int, error) {
+	baseLS, err := baseline(dir)
+	if baseLS == nil || err != nil {
+		return 0, err
+	}
+	wsOpts, err := workspaceOptionsForDir(dir)
+	if err != nil {
+		return 0, err
+	} else if wsOpts.Type == workspace.TypeParse {
+		return 0, WrapExitCode(CodeBadConfig, workspace.ErrParseWorkspace)
+	}
+	mods, err := applier.StatementModifiersForDir(dir)
+	if err != nil {
+		return 0, WrapExitCode(CodeBadConfig, err)
+	}
+	// Since no database server is modified, destructive changes are shown too
+	mods.AllowUnsafe = true
+	mods.Flavor = wsOpts.Flavor
+
+	fsSchema, err := execLogicalSchemaStrict(dir.LogicalSchemas[0], wsOpts)
+	if err != nil {
+		return 0, err
+	}
+	baseSchema, err := execLogicalSchemaStrict(baseLS, wsOpts)
+	if err != nil {
+		return 0, err
+	}
+	baseSchema.StripMatches(dir.IgnorePatterns)
+
+	var count int
+	for _, od := range tengo.NewSchemaDiff(baseSchema.Schema, fsSchema.Schema).ObjectDiffs() {
+		stmt, err := od.Statement(mods)
+		if tengo.IsUnsupportedDiff(err) {
+			log.Warnf("Skipping %s: Skeema does not support generating a diff of this table", od.ObjectKey())
+			continue
+		} else if err != nil {
+			return count, err
+		} else if stmt == "" {
+			continue
+		}
+		if count == 0 {
+			fmt.Printf("-- dir: %s\n", dir.RelPath())
+		}
+		if compounder, ok := od.(tengo.Compounder); ok && compounder.IsCompoundStatement() {
+			fmt.Printf("DELIMITER //\n%s//\nDELIMITER ;\n", stmt)
+		} else {
+			fmt.Printf("%s;\n", stmt)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// dumpSchemaForDir returns the logical schema from dumpSchemas corresponding
+// to dir. If the dump only contains one schema, it is always used. Otherwise,
+// the dump schema is matched by name, using the first name in dir's schema
+// option that is present in the dump.
+func dumpSchemaForDir(dir *fs.Dir, dumpSchemas map[string]*fs.LogicalSchema) *fs.LogicalSchema {
+	if len(dumpSchemas) == 1 {
+		for _, ls := range dumpSchemas {
+			return ls
+		}
+	}
+	if name := dir.LogicalSchemas[0].Name; name != "" {
+		return dumpSchemas[name]
+	}
+	for _, name := range dir.Config.GetSliceAllowEnvVar("schema", ',', true) {
+		if ls, ok := dumpSchemas[name]; ok {
+			return ls
+		}
+	}
+	return nil
+}
+
+// execLogicalSchemaStrict converts ls into a real schema using a workspace.
+// Any statement errors are treated as fatal, since the resulting diff would
+// otherwise be misleading.
+func execLogicalSchemaStrict(ls *fs.LogicalSchema, wsOpts workspace.Options) (*workspace.Schema, error) {
+	wsSchema, err := workspace.ExecLogicalSchema(ls, wsOpts)
+	if err != nil {
+		return nil, err
+	} else if len(wsSchema.Failures) > 0 {
+		return nil, wsSchema.Failures[0]
+	}
+	return wsSchema, nil
+}
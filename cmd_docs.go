@@ -0,0 +1,497 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Generate documentation of the schemas"
+	desc := "Generates reference documentation of the schemas defined in this directory " +
+		"and its subdirectories, in Markdown or HTML format. For each schema, the " +
+		"documentation describes every table's columns, indexes, foreign keys, and " +
+		"check constraints, including their comments, as well as every stored " +
+		"procedure and function. The output is suitable for publishing to a wiki or " +
+		"static site, for example from a CI pipeline.\n\n" +
+		"By default, documentation of all schemas is written to STDOUT. With " +
+		"--output-dir, one file is instead written per schema, along with an index " +
+		"file linking to each one.\n\n" +
+		"As with `skeema graph`, the *.sql files are converted into schemas using a " +
+		"workspace, unless --live is used to read the schemas from the database server " +
+		"instead. Markdown output includes a Mermaid entity-relationship diagram of " +
+		"each schema, unless --skip-diagram is used.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("docs", summary, desc, DocsHandler)
+	cmd.AddOption(mybase.StringOption("output-format", 0, "markdown", `Format of documentation (valid values: "markdown", "html")`))
+	cmd.AddOption(mybase.StringOption("output-dir", 0, "", "Write one file per schema to this directory, instead of writing to STDOUT"))
+	cmd.AddOption(mybase.BoolOption("live", 0, false, "Document the schemas on the database server, rather than the *.sql files"))
+	cmd.AddOption(mybase.BoolOption("diagram", 0, true, "Include a Mermaid entity-relationship diagram in Markdown output"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// DocsHandler is the handler method for `skeema docs`
+func DocsHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	format, err := dir.Config.GetEnum("output-format", "markdown", "html")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	schemas, skipCount := schemaWalker(dir, 5)
+	if len(schemas) == 0 && skipCount == 0 {
+		return NewExitValue(CodeBadConfig, "No schemas found in %s or its subdirectories", dir)
+	}
+	docs := make([]*schemaDoc, len(schemas))
+	for n, s := range schemas {
+		docs[n] = newSchemaDoc(s, format == "markdown" && dir.Config.GetBool("diagram"))
+	}
+
+	if outputDir := dir.Config.Get("output-dir"); outputDir != "" {
+		if err := writeSchemaDocs(outputDir, format, docs); err != nil {
+			return WrapExitCode(CodeCantCreate, err)
+		}
+	} else if format == "html" {
+		if err := docsHTMLTemplate.Execute(os.Stdout, docs); err != nil {
+			return err
+		}
+	} else {
+		for n, doc := range docs {
+			if n > 0 {
+				fmt.Print("\n")
+			}
+			fmt.Print(doc.Markdown())
+		}
+	}
+	if skipCount > 0 {
+		return NewExitValue(CodeFatalError, "Skipped %s due to errors", countAndNoun(skipCount, "directory", "directories"))
+	}
+	return nil
+}
+
+// writeSchemaDocs writes one file per schema to outputDir, creating it if
+// necessary, along with an index file linking to each schema's file.
+func writeSchemaDocs(outputDir, format string, docs []*schemaDoc) error {
+	if err := os.MkdirAll(outputDir, 0777); err != nil {
+		return err
+	}
+	ext := ".md"
+	if format == "html" {
+		ext = ".html"
+	}
+	var index strings.Builder
+	index.WriteString("# Schemas\n\n")
+	for _, doc := range docs {
+		var contents strings.Builder
+		if format == "html" {
+			if err := docsHTMLTemplate.Execute(&contents, []*schemaDoc{doc}); err != nil {
+				return err
+			}
+		} else {
+			contents.WriteString(doc.Markdown())
+		}
+		fileName := doc.Name + ext
+		if err := os.WriteFile(filepath.Join(outputDir, fileName), []byte(contents.String()), 0666); err != nil {
+			return err
+		}
+		log.Infof("Wrote %s", filepath.Join(outputDir, fileName))
+		fmt.Fprintf(&index, "- [%s](%s)\n", doc.Name, fileName)
+	}
+	indexContents := index.String()
+	if format == "html" {
+		var b strings.Builder
+		if err := docsIndexHTMLTemplate.Execute(&b, docs); err != nil {
+			return err
+		}
+		indexContents = b.String()
+	}
+	return os.WriteFile(filepath.Join(outputDir, "index"+ext), []byte(indexContents), 0666)
+}
+
+// schemaDoc is the documentation of a single schema, in a form which is
+// independent of output format.
+type schemaDoc struct {
+	Name      string
+	CharSet   string
+	Collation string
+	Tables    []*tableDoc
+	Routines  []*routineDoc
+	Diagram   string // Mermaid erDiagram, or blank if not requested
+}
+
+type tableDoc struct {
+	Name        string
+	Comment     string
+	Columns     []columnDoc
+	Indexes     []indexDoc
+	ForeignKeys []foreignKeyDoc
+	Checks      []*tengo.Check
+}
+
+type columnDoc struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	Extra    string // auto_increment, ON UPDATE, generation expression, etc
+	Comment  string
+}
+
+type indexDoc struct {
+	Name    string
+	Kind    string // "PRIMARY", "UNIQUE", "FULLTEXT", "SPATIAL", or "INDEX"
+	Columns string
+	Comment string
+}
+
+type foreignKeyDoc struct {
+	Name       string
+	Columns    string
+	References string
+	OnUpdate   string
+	OnDelete   string
+}
+
+type routineDoc struct {
+	Name          string
+	Type          string // "Procedure" or "Function"
+	Params        string
+	Returns       string
+	Comment       string
+	Deterministic bool
+	DataAccess    string
+	Security      string
+}
+
+// newSchemaDoc returns documentation describing s. If diagram is true, a
+// Mermaid entity-relationship diagram is also included.
+func newSchemaDoc(s *tengo.Schema, diagram bool) *schemaDoc {
+	doc := &schemaDoc{
+		Name:      s.Name,
+		CharSet:   s.CharSet,
+		Collation: s.Collation,
+	}
+	for _, table := range s.Tables {
+		doc.Tables = append(doc.Tables, newTableDoc(table))
+	}
+	for _, r := range s.Routines {
+		doc.Routines = append(doc.Routines, &routineDoc{
+			Name:          r.Name,
+			Type:          strings.ToUpper(string(r.Type[0:1])) + string(r.Type[1:]),
+			Params:        strings.TrimSpace(r.ParamString),
+			Returns:       r.ReturnDataType,
+			Comment:       r.Comment,
+			Deterministic: r.Deterministic,
+			DataAccess:    r.SQLDataAccess,
+			Security:      r.SecurityType,
+		})
+	}
+	if diagram && len(s.Tables) > 0 {
+		doc.Diagram = newERGraph([]*tengo.Schema{s}, true).Mermaid(false)
+	}
+	return doc
+}
+
+func newTableDoc(table *tengo.Table) *tableDoc {
+	td := &tableDoc{
+		Name:    table.Name,
+		Comment: table.Comment,
+		Checks:  table.Checks,
+	}
+	for _, col := range table.Columns {
+		cd := columnDoc{
+			Name:     col.Name,
+			Type:     col.Type.String(),
+			Nullable: col.Nullable,
+			Default:  col.Default,
+			Comment:  col.Comment,
+		}
+		var extras []string
+		if col.AutoIncrement {
+			extras = append(extras, "auto_increment")
+		}
+		if col.OnUpdate != "" {
+			extras = append(extras, "ON UPDATE "+col.OnUpdate)
+		}
+		if col.GenerationExpr != "" {
+			storage := "STORED"
+			if col.Virtual {
+				storage = "VIRTUAL"
+			}
+			extras = append(extras, fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", col.GenerationExpr, storage))
+		}
+		if col.Invisible {
+			extras = append(extras, "INVISIBLE")
+		}
+		cd.Extra = strings.Join(extras, ", ")
+		td.Columns = append(td.Columns, cd)
+	}
+	indexes := table.SecondaryIndexes
+	if table.PrimaryKey != nil {
+		indexes = append([]*tengo.Index{table.PrimaryKey}, indexes...)
+	}
+	for _, idx := range indexes {
+		kind := "INDEX"
+		if idx.PrimaryKey {
+			kind = "PRIMARY"
+		} else if idx.Unique {
+			kind = "UNIQUE"
+		} else if idx.Type == "FULLTEXT" || idx.Type == "SPATIAL" {
+			kind = idx.Type
+		}
+		parts := make([]string, len(idx.Parts))
+		for n, part := range idx.Parts {
+			if part.Expression != "" {
+				parts[n] = "(" + part.Expression + ")"
+			} else if part.PrefixLength > 0 {
+				parts[n] = fmt.Sprintf("%s(%d)", part.ColumnName, part.PrefixLength)
+			} else {
+				parts[n] = part.ColumnName
+			}
+			if part.Descending {
+				parts[n] += " DESC"
+			}
+		}
+		td.Indexes = append(td.Indexes, indexDoc{Name: idx.Name, Kind: kind, Columns: strings.Join(parts, ", "), Comment: idx.Comment})
+	}
+	for _, fk := range table.ForeignKeys {
+		refTable := fk.ReferencedTableName
+		if fk.ReferencedSchemaName != "" {
+			refTable = fk.ReferencedSchemaName + "." + refTable
+		}
+		td.ForeignKeys = append(td.ForeignKeys, foreignKeyDoc{
+			Name:       fk.Name,
+			Columns:    strings.Join(fk.ColumnNames, ", "),
+			References: fmt.Sprintf("%s (%s)", refTable, strings.Join(fk.ReferencedColumnNames, ", ")),
+			OnUpdate:   fk.UpdateRule,
+			OnDelete:   fk.DeleteRule,
+		})
+	}
+	return td
+}
+
+// Markdown returns the documentation in GitHub-flavored Markdown format.
+func (doc *schemaDoc) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Schema `%s`\n\n", doc.Name)
+	if doc.CharSet != "" {
+		fmt.Fprintf(&b, "Default character set `%s`, collation `%s`.\n\n", doc.CharSet, doc.Collation)
+	}
+	if len(doc.Tables) > 0 {
+		b.WriteString("**Tables:**")
+		for n, table := range doc.Tables {
+			if n > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, " [%s](#table-%s)", table.Name, docsAnchor(table.Name))
+		}
+		b.WriteString("\n\n")
+	}
+	if len(doc.Routines) > 0 {
+		b.WriteString("**Routines:**")
+		for n, r := range doc.Routines {
+			if n > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, " [%s](#%s-%s)", r.Name, strings.ToLower(r.Type), docsAnchor(r.Name))
+		}
+		b.WriteString("\n\n")
+	}
+	if doc.Diagram != "" {
+		fmt.Fprintf(&b, "## Entity-relationship diagram\n\n```mermaid\n%s```\n\n", doc.Diagram)
+	}
+
+	if len(doc.Tables) > 0 {
+		b.WriteString("## Tables\n")
+	}
+	for _, table := range doc.Tables {
+		fmt.Fprintf(&b, "\n### Table `%s`\n\n", table.Name)
+		if table.Comment != "" {
+			b.WriteString(docsMarkdownEscape(table.Comment) + "\n\n")
+		}
+		b.WriteString("| Column | Type | Nullable | Default | Extra | Comment |\n|---|---|---|---|---|---|\n")
+		for _, col := range table.Columns {
+			nullable := "NO"
+			if col.Nullable {
+				nullable = "YES"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n", col.Name, docsMarkdownCode(col.Type), nullable,
+				docsMarkdownCode(col.Default), docsMarkdownCode(col.Extra), docsMarkdownEscape(col.Comment))
+		}
+		if len(table.Indexes) > 0 {
+			b.WriteString("\n**Indexes**\n\n| Name | Type | Columns | Comment |\n|---|---|---|---|\n")
+			for _, idx := range table.Indexes {
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", idx.Name, idx.Kind, docsMarkdownCode(idx.Columns), docsMarkdownEscape(idx.Comment))
+			}
+		}
+		if len(table.ForeignKeys) > 0 {
+			b.WriteString("\n**Foreign keys**\n\n| Name | Columns | References | On update | On delete |\n|---|---|---|---|---|\n")
+			for _, fk := range table.ForeignKeys {
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", fk.Name, docsMarkdownCode(fk.Columns), docsMarkdownCode(fk.References), fk.OnUpdate, fk.OnDelete)
+			}
+		}
+		if len(table.Checks) > 0 {
+			b.WriteString("\n**Check constraints**\n\n| Name | Clause | Enforced |\n|---|---|---|\n")
+			for _, check := range table.Checks {
+				enforced := "YES"
+				if !check.Enforced {
+					enforced = "NO"
+				}
+				fmt.Fprintf(&b, "| `%s` | %s | %s |\n", check.Name, docsMarkdownCode(check.Clause), enforced)
+			}
+		}
+	}
+
+	if len(doc.Routines) > 0 {
+		b.WriteString("\n## Routines\n")
+	}
+	for _, r := range doc.Routines {
+		fmt.Fprintf(&b, "\n### %s `%s`\n\n", r.Type, r.Name)
+		if r.Comment != "" {
+			b.WriteString(docsMarkdownEscape(r.Comment) + "\n\n")
+		}
+		if r.Params != "" {
+			fmt.Fprintf(&b, "- Parameters: %s\n", docsMarkdownCode(r.Params))
+		}
+		if r.Returns != "" {
+			fmt.Fprintf(&b, "- Returns: %s\n", docsMarkdownCode(r.Returns))
+		}
+		if r.DataAccess != "" {
+			fmt.Fprintf(&b, "- SQL data access: %s\n", r.DataAccess)
+		}
+		if r.Security != "" {
+			fmt.Fprintf(&b, "- Security: %s\n", r.Security)
+		}
+		if r.Deterministic {
+			b.WriteString("- Deterministic\n")
+		}
+	}
+	return b.String()
+}
+
+// docsAnchor returns the anchor which GitHub generates for a heading
+// containing name.
+func docsAnchor(name string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if r == ' ' {
+			return '-'
+		} else if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, name))
+}
+
+// docsMarkdownEscape replaces newlines and escapes characters which would
+// otherwise be interpreted as Markdown table or inline formatting syntax.
+func docsMarkdownEscape(s string) string {
+	replacer := strings.NewReplacer("\n", " ", "|", "\\|", "*", "\\*", "_", "\\_", "<", "&lt;")
+	return replacer.Replace(s)
+}
+
+// docsMarkdownCode returns s formatted as inline code, suitable for use in a
+// Markdown table cell. Blank strings are returned as-is.
+func docsMarkdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.NewReplacer("\n", " ", "|", "\\|").Replace(s)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+const docsHTMLStyle = `<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #d0d7de; padding: 0.25em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 0.9em; }
+.schema { margin-bottom: 3em; }
+.object { margin-top: 2em; border-top: 1px solid #d0d7de; }
+.comment { color: #57606a; }
+</style>`
+
+var docsHTMLTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if eq (len .) 1}}Schema {{(index . 0).Name}}{{else}}Schema documentation{{end}}</title>
+` + docsHTMLStyle + `
+</head>
+<body>
+{{range .}}<div class="schema" id="schema-{{.Name}}">
+<h1>Schema <code>{{.Name}}</code></h1>
+{{if .CharSet}}<p>Default character set <code>{{.CharSet}}</code>, collation <code>{{.Collation}}</code>.</p>
+{{end}}{{if .Tables}}<p><b>Tables:</b>{{range $n, $t := .Tables}}{{if $n}},{{end}} <a href="#table-{{$t.Name}}">{{$t.Name}}</a>{{end}}</p>
+{{end}}{{if .Routines}}<p><b>Routines:</b>{{range $n, $r := .Routines}}{{if $n}},{{end}} <a href="#routine-{{$r.Name}}">{{$r.Name}}</a>{{end}}</p>
+{{end}}{{range .Tables}}<div class="object" id="table-{{.Name}}">
+<h2>Table <code>{{.Name}}</code></h2>
+{{if .Comment}}<p class="comment">{{.Comment}}</p>
+{{end}}<table>
+<tr><th>Column</th><th>Type</th><th>Nullable</th><th>Default</th><th>Extra</th><th>Comment</th></tr>
+{{range .Columns}}<tr><td><code>{{.Name}}</code></td><td><code>{{.Type}}</code></td><td>{{if .Nullable}}YES{{else}}NO{{end}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Extra}}</td><td>{{.Comment}}</td></tr>
+{{end}}</table>
+{{if .Indexes}}<h3>Indexes</h3>
+<table>
+<tr><th>Name</th><th>Type</th><th>Columns</th><th>Comment</th></tr>
+{{range .Indexes}}<tr><td><code>{{.Name}}</code></td><td>{{.Kind}}</td><td>{{.Columns}}</td><td>{{.Comment}}</td></tr>
+{{end}}</table>
+{{end}}{{if .ForeignKeys}}<h3>Foreign keys</h3>
+<table>
+<tr><th>Name</th><th>Columns</th><th>References</th><th>On update</th><th>On delete</th></tr>
+{{range .ForeignKeys}}<tr><td><code>{{.Name}}</code></td><td>{{.Columns}}</td><td>{{.References}}</td><td>{{.OnUpdate}}</td><td>{{.OnDelete}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Checks}}<h3>Check constraints</h3>
+<table>
+<tr><th>Name</th><th>Clause</th><th>Enforced</th></tr>
+{{range .Checks}}<tr><td><code>{{.Name}}</code></td><td><code>{{.Clause}}</code></td><td>{{if .Enforced}}YES{{else}}NO{{end}}</td></tr>
+{{end}}</table>
+{{end}}</div>
+{{end}}{{range .Routines}}<div class="object" id="routine-{{.Name}}">
+<h2>{{.Type}} <code>{{.Name}}</code></h2>
+{{if .Comment}}<p class="comment">{{.Comment}}</p>
+{{end}}<ul>
+{{if .Params}}<li>Parameters: <code>{{.Params}}</code></li>
+{{end}}{{if .Returns}}<li>Returns: <code>{{.Returns}}</code></li>
+{{end}}{{if .DataAccess}}<li>SQL data access: {{.DataAccess}}</li>
+{{end}}{{if .Security}}<li>Security: {{.Security}}</li>
+{{end}}{{if .Deterministic}}<li>Deterministic</li>
+{{end}}</ul>
+</div>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
+
+var docsIndexHTMLTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Schemas</title>
+` + docsHTMLStyle + `
+</head>
+<body>
+<h1>Schemas</h1>
+<ul>
+{{range .}}<li><a href="{{.Name}}.html">{{.Name}}</a> ({{len .Tables}} tables, {{len .Routines}} routines)</li>
+{{end}}</ul>
+</body>
+</html>
+`))
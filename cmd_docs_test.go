@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestSchemaDocs(t *testing.T) {
+	creates := []string{
+		"CREATE TABLE `customers` (\n  `id` int unsigned NOT NULL AUTO_INCREMENT,\n  `email` varchar(100) NOT NULL COMMENT 'Login | contact <email>',\n  `updated_at` timestamp NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,\n  PRIMARY KEY (`id`),\n  UNIQUE KEY `email` (`email`(50))\n) ENGINE=InnoDB COMMENT='People who buy things'",
+		"CREATE TABLE `orders` (\n  `id` int unsigned NOT NULL,\n  `customer_id` int unsigned NOT NULL,\n  `total` decimal(10,2) NOT NULL DEFAULT '0.00',\n  PRIMARY KEY (`id`),\n  KEY `customer_id` (`customer_id`),\n  CONSTRAINT `orders_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`) ON DELETE CASCADE,\n  CONSTRAINT `total_positive` CHECK ((`total` >= 0))\n) ENGINE=InnoDB",
+	}
+	flavor := tengo.ParseFlavor("mysql:8.0")
+	s := &tengo.Schema{Name: "shop", CharSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}
+	for _, stmt := range creates {
+		table, err := tengo.ParseCreateTable(stmt, flavor, s.CharSet, s.Collation)
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+		}
+		s.Tables = append(s.Tables, table)
+	}
+	s.Routines = []*tengo.Routine{{
+		Name:           "order_count",
+		Type:           tengo.ObjectTypeFunc,
+		ParamString:    "\ncid int unsigned\n",
+		ReturnDataType: "int",
+		Comment:        "Counts orders",
+		Deterministic:  true,
+		SQLDataAccess:  "READS SQL DATA",
+		SecurityType:   "DEFINER",
+	}}
+
+	doc := newSchemaDoc(s, true)
+	md := doc.Markdown()
+	expected := []string{
+		"# Schema `shop`\n\nDefault character set `utf8mb4`, collation `utf8mb4_0900_ai_ci`.\n",
+		"**Tables:** [customers](#table-customers), [orders](#table-orders)\n",
+		"**Routines:** [order_count](#function-order_count)\n",
+		"```mermaid\nerDiagram\n    customers\n    orders\n    orders }o--|| customers : \"orders_customer\"\n```\n",
+		"### Table `customers`\n\nPeople who buy things\n",
+		"| `email` | `varchar(100)` | NO |  |  | Login \\| contact &lt;email> |\n",
+		"| `updated_at` | `timestamp` | YES | `NULL` | `ON UPDATE CURRENT_TIMESTAMP` |  |\n",
+		"| `email` | UNIQUE | `email(50)` |  |\n",
+		"| `orders_customer` | `customer_id` | `customers (id)` | NO ACTION | CASCADE |\n",
+		"| `total_positive` | `` (`total` >= 0) `` | YES |\n",
+		"### Function `order_count`\n\nCounts orders\n\n- Parameters: `cid int unsigned`\n- Returns: `int`\n- SQL data access: READS SQL DATA\n- Security: DEFINER\n- Deterministic\n",
+	}
+	for _, substr := range expected {
+		if !strings.Contains(md, substr) {
+			t.Errorf("Expected Markdown to contain %q, but it did not. Full output:\n%s", substr, md)
+		}
+	}
+	if md := newSchemaDoc(s, false).Markdown(); strings.Contains(md, "mermaid") {
+		t.Errorf("Expected no diagram, but found one. Full output:\n%s", md)
+	}
+
+	dir := t.TempDir()
+	if err := writeSchemaDocs(dir, "html", []*schemaDoc{doc}); err != nil {
+		t.Fatalf("Unexpected error from writeSchemaDocs: %v", err)
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "shop.html"))
+	if err != nil {
+		t.Fatalf("Unexpected error reading output: %v", err)
+	}
+	expected = []string{
+		"<title>Schema shop</title>",
+		`<div class="object" id="table-customers">`,
+		"<td>Login | contact &lt;email&gt;</td>",
+		"<td><code>customer_id</code></td><td>INDEX</td><td>customer_id</td>",
+		`<h2>Function <code>order_count</code></h2>`,
+	}
+	for _, substr := range expected {
+		if !strings.Contains(string(contents), substr) {
+			t.Errorf("Expected HTML to contain %q, but it did not. Full output:\n%s", substr, contents)
+		}
+	}
+	if index, err := os.ReadFile(filepath.Join(dir, "index.html")); err != nil || !strings.Contains(string(index), `<a href="shop.html">shop</a> (2 tables, 1 routines)`) {
+		t.Errorf("Unexpected index.html contents: %s, err=%v", index, err)
+	}
+}
+
+func TestDocsMarkdownCode(t *testing.T) {
+	cases := map[string]string{
+		"":               "",
+		"abc":            "`abc`",
+		"a|b":            "`a\\|b`",
+		"(`total` >= 0)": "`` (`total` >= 0) ``",
+		"line1\nline2":   "`line1 line2`",
+	}
+	for input, expected := range cases {
+		if actual := docsMarkdownCode(input); actual != expected {
+			t.Errorf("Unexpected result from docsMarkdownCode(%q): expected %q, found %q", input, expected, actual)
+		}
+	}
+}
@@ -92,14 +92,15 @@ func formatDir(dir *fs.Dir) error {
 	// instance, so that any auto-detect-related settings work properly. However,
 	// with workspace=docker we can ignore connection errors; we'll get reasonable
 	// defaults from workspace.OptionsForDir if inst is nil as long as flavor is set.
+	// With workspace=parse, connection errors are always ignored.
 	var wsOpts workspace.Options
 	if len(dir.LogicalSchemas) > 0 {
 		inst, err := dir.FirstInstance()
-		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker"); wsType != "docker" || !dir.Config.Changed("flavor") {
+		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker", "kubernetes", "parse"); wsType == "temp-schema" || (wsType != "parse" && !dir.Config.Changed("flavor")) {
 			if err != nil {
 				return WrapExitCode(CodeBadConfig, err)
 			} else if inst == nil {
-				return NewExitValue(CodeBadConfig, "This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker), but one is not configured for environment %q", dir.Config.Get("environment"))
+				return NewExitValue(CodeBadConfig, "This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker or workspace=kubernetes), but one is not configured for environment %q", dir.Config.Get("environment"))
 			}
 		}
 		if wsOpts, err = workspace.OptionsForDir(dir, inst); err != nil {
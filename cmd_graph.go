@@ -0,0 +1,481 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Output an entity-relationship diagram of the schemas"
+	desc := "Outputs an entity-relationship graph of the tables defined in this " +
+		"directory and its subdirectories, in a format suitable for rendering by " +
+		"Graphviz (\"dot\"), Mermaid (\"mermaid\"), or PlantUML (\"plantuml\").\n\n" +
+		"Relationships are determined from foreign keys. Additionally, unless " +
+		"--skip-infer-relationships is used, columns named like a table followed by " +
+		"\"_id\" (for example orders.customer_id) are treated as references to that " +
+		"table's single-column primary key, even without a foreign key. Inferred " +
+		"relationships are drawn with dashed lines.\n\n" +
+		"By default, the *.sql files are converted into schemas using a workspace; see " +
+		"the --workspace option for more information. With --live, the schemas are " +
+		"instead read from the first database server and schema configured for each " +
+		"directory.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("graph", summary, desc, GraphHandler)
+	cmd.AddOption(mybase.StringOption("output-format", 0, "dot", `Format of STDOUT output (valid values: "dot", "mermaid", "plantuml")`))
+	cmd.AddOption(mybase.BoolOption("live", 0, false, "Graph the schemas on the database server, rather than the *.sql files"))
+	cmd.AddOption(mybase.BoolOption("columns", 0, true, "Include each table's columns in the graph"))
+	cmd.AddOption(mybase.BoolOption("infer-relationships", 0, true, "Infer relationships from column naming conventions, in addition to foreign keys"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// GraphHandler is the handler method for `skeema graph`
+func GraphHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	format, err := dir.Config.GetEnum("output-format", "dot", "mermaid", "plantuml")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	schemas, skipCount := schemaWalker(dir, 5)
+	if len(schemas) == 0 && skipCount == 0 {
+		return NewExitValue(CodeBadConfig, "No schemas found in %s or its subdirectories", dir)
+	}
+
+	graph := newERGraph(schemas, dir.Config.GetBool("infer-relationships"))
+	columns := dir.Config.GetBool("columns")
+	switch format {
+	case "mermaid":
+		fmt.Print(graph.Mermaid(columns))
+	case "plantuml":
+		fmt.Print(graph.PlantUML(columns))
+	default:
+		fmt.Print(graph.DOT(columns))
+	}
+	if skipCount > 0 {
+		return NewExitValue(CodeFatalError, "Skipped %s due to errors", countAndNoun(skipCount, "directory", "directories"))
+	}
+	return nil
+}
+
+// schemaWalker returns the schema represented by each directory containing
+// *.sql files, recursing into subdirectories, for commands which describe
+// schemas such as `skeema graph` and `skeema docs`. It also returns the number
+// of directories skipped due to errors.
+func schemaWalker(dir *fs.Dir, maxDepth int) (schemas []*tengo.Schema, skipCount int) {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError)
+		return nil, 1
+	}
+	if len(dir.LogicalSchemas) > 0 {
+		if schema, err := schemaForDir(dir); err != nil {
+			log.Errorf("Skipping directory %s due to error: %s", dir.RelPath(), err)
+			skipCount++
+		} else if schema != nil {
+			schemas = append(schemas, schema)
+		}
+	}
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return schemas, skipCount + 1
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Errorf("Not walking subdirs of %s: max depth reached", dir)
+		return schemas, skipCount + 1
+	}
+	for _, sub := range subdirs {
+		subSchemas, subSkipCount := schemaWalker(sub, maxDepth-1)
+		schemas = append(schemas, subSchemas...)
+		skipCount += subSkipCount
+	}
+	return schemas, skipCount
+}
+
+// schemaForDir returns the schema represented by dir: either the live schema
+// from dir's first instance with --live, or otherwise the result of executing
+// dir's *.sql files in a workspace.
+func schemaForDir(dir *fs.Dir) (*tengo.Schema, error) {
+	if dir.Config.GetBool("live") {
+		inst, err := dir.FirstInstance()
+		if err != nil {
+			return nil, err
+		} else if inst == nil {
+			return nil, fmt.Errorf("no host defined for environment %q", dir.Config.Get("environment"))
+		}
+		schemaNames, err := dir.SchemaNames(inst)
+		if err != nil {
+			return nil, err
+		} else if len(schemaNames) == 0 {
+			log.Warnf("Skipping %s: no schema defined for environment %q", dir, dir.Config.Get("environment"))
+			return nil, nil
+		}
+		// Graphs only include tables, so skip introspection of routines
+		schemas, err := inst.SchemasWithOptions(tengo.IntrospectionOptions{SkipRoutines: true}, schemaNames[0])
+		if err != nil {
+			return nil, err
+		} else if len(schemas) == 0 {
+			return nil, fmt.Errorf("schema %s does not exist on %s", schemaNames[0], inst)
+		}
+		schema := schemas[0]
+		schema.StripMatches(dir.IgnorePatterns)
+		return schema, nil
+	}
+
+	wsOpts, err := workspaceOptionsForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(dir.LogicalSchemas[0], wsOpts)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmtErr := range wsSchema.Failures {
+		log.Warnf("Omitting object: %s", stmtErr)
+	}
+	schema := wsSchema.Schema
+	schema.Name = dir.LogicalSchemas[0].Name
+	if schema.Name == "" {
+		if names := dir.Config.GetSliceAllowEnvVar("schema", ',', true); len(names) > 0 && names[0] != "*" && !strings.HasPrefix(names[0], "`") {
+			schema.Name = names[0]
+		} else {
+			schema.Name = dir.BaseName()
+		}
+	}
+	return schema, nil
+}
+
+// erEntity represents a table in an erGraph.
+type erEntity struct {
+	Name  string // table name, qualified by schema name if the graph has multiple schemas
+	Table *tengo.Table
+}
+
+// erRelationship represents a reference from columns of one entity to columns
+// of another entity.
+type erRelationship struct {
+	From, To          *erEntity
+	Columns           []string
+	ReferencedColumns []string
+	Label             string // foreign key name, or column name if inferred
+	Inferred          bool   // true if based on naming conventions rather than a foreign key
+	Optional          bool   // true if any referencing column is nullable
+	OneToOne          bool   // true if the referencing columns are unique
+}
+
+// erGraph is an entity-relationship graph of one or more schemas.
+type erGraph struct {
+	Entities      []*erEntity
+	Relationships []*erRelationship
+}
+
+// newERGraph returns a graph of the tables in schemas, with relationships
+// based on foreign keys, and optionally also inferred from column names.
+func newERGraph(schemas []*tengo.Schema, infer bool) *erGraph {
+	g := &erGraph{}
+	qualify := len(schemas) > 1
+	byName := make(map[string]*erEntity) // keyed by schema name + "." + table name
+	for _, s := range schemas {
+		for _, table := range s.Tables {
+			entity := &erEntity{Name: table.Name, Table: table}
+			if qualify {
+				entity.Name = s.Name + "." + table.Name
+			}
+			g.Entities = append(g.Entities, entity)
+			byName[s.Name+"."+table.Name] = entity
+		}
+	}
+
+	for _, s := range schemas {
+		for _, table := range s.Tables {
+			from := byName[s.Name+"."+table.Name]
+			referenced := make(map[string]bool) // column names already covered by an FK
+			for _, fk := range table.ForeignKeys {
+				refSchema := fk.ReferencedSchemaName
+				if refSchema == "" {
+					refSchema = s.Name
+				}
+				to, ok := byName[refSchema+"."+fk.ReferencedTableName]
+				if !ok {
+					log.Debugf("Omitting foreign key %s of table %s from graph: referenced table %s.%s not present", fk.Name, table.Name, refSchema, fk.ReferencedTableName)
+					continue
+				}
+				g.Relationships = append(g.Relationships, newERRelationship(from, to, fk.ColumnNames, fk.ReferencedColumnNames, fk.Name, false))
+				if len(fk.ColumnNames) == 1 {
+					referenced[fk.ColumnNames[0]] = true
+				}
+			}
+			if !infer {
+				continue
+			}
+			for _, col := range table.Columns {
+				if referenced[col.Name] {
+					continue
+				}
+				if to := inferReferencedTable(s, table, col); to != nil {
+					toEntity := byName[s.Name+"."+to.Name]
+					pkCol := to.PrimaryKey.Parts[0].ColumnName
+					g.Relationships = append(g.Relationships, newERRelationship(from, toEntity, []string{col.Name}, []string{pkCol}, col.Name, true))
+				}
+			}
+		}
+	}
+	return g
+}
+
+func newERRelationship(from, to *erEntity, cols, refCols []string, label string, inferred bool) *erRelationship {
+	rel := &erRelationship{
+		From:              from,
+		To:                to,
+		Columns:           cols,
+		ReferencedColumns: refCols,
+		Label:             label,
+		Inferred:          inferred,
+	}
+	colsByName := from.Table.ColumnsByName()
+	for _, name := range cols {
+		if col := colsByName[name]; col != nil && col.Nullable {
+			rel.Optional = true
+		}
+	}
+	uniques := from.Table.SecondaryIndexes
+	if from.Table.PrimaryKey != nil {
+		uniques = append([]*tengo.Index{from.Table.PrimaryKey}, uniques...)
+	}
+	for _, idx := range uniques {
+		if (idx.Unique || idx.PrimaryKey) && indexHasExactColumns(idx, cols) {
+			rel.OneToOne = true
+		}
+	}
+	return rel
+}
+
+// indexHasExactColumns returns true if idx covers exactly the supplied columns,
+// in any order, without prefixes.
+func indexHasExactColumns(idx *tengo.Index, cols []string) bool {
+	if len(idx.Parts) != len(cols) {
+		return false
+	}
+	for _, part := range idx.Parts {
+		if part.PrefixLength > 0 || !slices.Contains(cols, part.ColumnName) {
+			return false
+		}
+	}
+	return true
+}
+
+// inferReferencedTable returns the table in s which col appears to refer to
+// based on naming conventions, or nil if none. A column named "foo_id" may
+// refer to a table named "foo", "foos", or "fooes" (or "fies" for a name
+// ending in "y"), as long as that table has a single-column primary key with a
+// compatible type. Tables are never inferred to refer to themselves.
+func inferReferencedTable(s *tengo.Schema, table *tengo.Table, col *tengo.Column) *tengo.Table {
+	lowerName := strings.ToLower(col.Name)
+	base, ok := strings.CutSuffix(lowerName, "_id")
+	if !ok || base == "" {
+		return nil
+	}
+	candidates := []string{base, base + "s", base + "es"}
+	if stem, ok := strings.CutSuffix(base, "y"); ok {
+		candidates = append(candidates, stem+"ies")
+	}
+	for _, candidate := range candidates {
+		for _, other := range s.Tables {
+			if other == table || strings.ToLower(other.Name) != candidate || other.PrimaryKey == nil || len(other.PrimaryKey.Parts) != 1 {
+				continue
+			}
+			pkCol := other.ColumnsByName()[other.PrimaryKey.Parts[0].ColumnName]
+			if pkCol != nil && pkCol.Type.Base == col.Type.Base {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// columnKeyMarkers returns the key markers for col in entity: "PK" if part of
+// the primary key, "FK" if part of a relationship, and "UK" if part of a
+// unique secondary index.
+func (g *erGraph) columnKeyMarkers(entity *erEntity, col *tengo.Column) (markers []string) {
+	table := entity.Table
+	if table.PrimaryKey != nil && indexHasColumnName(table.PrimaryKey, col.Name) {
+		markers = append(markers, "PK")
+	}
+	for _, rel := range g.Relationships {
+		if rel.From == entity && slices.Contains(rel.Columns, col.Name) {
+			markers = append(markers, "FK")
+			break
+		}
+	}
+	for _, idx := range table.SecondaryIndexes {
+		if idx.Unique && indexHasColumnName(idx, col.Name) {
+			markers = append(markers, "UK")
+			break
+		}
+	}
+	return markers
+}
+
+func indexHasColumnName(idx *tengo.Index, name string) bool {
+	for _, part := range idx.Parts {
+		if part.ColumnName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DOT returns the graph in Graphviz DOT format. Each table is rendered as an
+// HTML-like label listing its columns if columns is true.
+func (g *erGraph) DOT(columns bool) string {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n\trankdir=LR;\n\tnode [shape=plaintext, fontname=\"Helvetica\"];\n\tedge [arrowhead=none, arrowtail=crow, dir=both];\n")
+	for _, entity := range g.Entities {
+		fmt.Fprintf(&b, "\t%q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", entity.Name)
+		fmt.Fprintf(&b, "<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", html.EscapeString(entity.Name))
+		if columns {
+			for _, col := range entity.Table.Columns {
+				text := col.Name + " " + col.Type.String()
+				if markers := g.columnKeyMarkers(entity, col); len(markers) > 0 {
+					text += " (" + strings.Join(markers, ", ") + ")"
+				}
+				fmt.Fprintf(&b, "<tr><td align=\"left\">%s</td></tr>", html.EscapeString(text))
+			}
+		}
+		b.WriteString("</table>>];\n")
+	}
+	for _, rel := range g.Relationships {
+		attrs := []string{fmt.Sprintf("label=%q", rel.Label)}
+		if rel.Inferred {
+			attrs = append(attrs, "style=dashed")
+		}
+		if rel.OneToOne {
+			attrs = append(attrs, "arrowtail=tee")
+		}
+		if rel.Optional {
+			attrs = append(attrs, "arrowhead=odot")
+		} else {
+			attrs = append(attrs, "arrowhead=tee")
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [%s];\n", rel.From.Name, rel.To.Name, strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid returns the graph as a Mermaid erDiagram.
+func (g *erGraph) Mermaid(columns bool) string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, entity := range g.Entities {
+		if !columns || len(entity.Table.Columns) == 0 {
+			fmt.Fprintf(&b, "    %s\n", diagramIdentifier(entity.Name))
+			continue
+		}
+		fmt.Fprintf(&b, "    %s {\n", diagramIdentifier(entity.Name))
+		for _, col := range entity.Table.Columns {
+			line := fmt.Sprintf("        %s %s", mermaidType(col.Type), diagramIdentifier(col.Name))
+			if markers := g.columnKeyMarkers(entity, col); len(markers) > 0 {
+				line += " " + strings.Join(markers, ", ")
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, rel := range g.Relationships {
+		fmt.Fprintf(&b, "    %s %s %s : %q\n", diagramIdentifier(rel.From.Name), rel.crowsFoot(), diagramIdentifier(rel.To.Name), rel.Label)
+	}
+	return b.String()
+}
+
+// PlantUML returns the graph as a PlantUML entity-relationship diagram, using
+// information engineering notation.
+func (g *erGraph) PlantUML(columns bool) string {
+	var b strings.Builder
+	b.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n\n")
+	for _, entity := range g.Entities {
+		fmt.Fprintf(&b, "entity %q as %s {\n", entity.Name, diagramIdentifier(entity.Name))
+		if columns {
+			var pkDone bool
+			for _, col := range entity.Table.Columns {
+				markers := g.columnKeyMarkers(entity, col)
+				isPK := len(markers) > 0 && markers[0] == "PK"
+				if !isPK && !pkDone && entity.Table.PrimaryKey != nil {
+					b.WriteString("  --\n")
+					pkDone = true
+				}
+				line := "  "
+				if !col.Nullable {
+					line += "* "
+				}
+				line += col.Name + " : " + col.Type.String()
+				for _, marker := range markers {
+					line += " <<" + marker + ">>"
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+		b.WriteString("}\n")
+	}
+	if len(g.Relationships) > 0 {
+		b.WriteString("\n")
+	}
+	for _, rel := range g.Relationships {
+		fmt.Fprintf(&b, "%s %s %s : %s\n", diagramIdentifier(rel.From.Name), rel.crowsFoot(), diagramIdentifier(rel.To.Name), rel.Label)
+	}
+	b.WriteString("@enduml\n")
+	return b.String()
+}
+
+// crowsFoot returns the relationship in crow's foot notation, as used by both
+// Mermaid and PlantUML. The referencing side is many (or one, if unique), and
+// the referenced side is exactly one (or zero or one, if nullable). Inferred
+// relationships use a dotted line.
+func (rel *erRelationship) crowsFoot() string {
+	left, line, right := "}o", "--", "||"
+	if rel.OneToOne {
+		left = "|o"
+	}
+	if rel.Inferred {
+		line = ".."
+	}
+	if rel.Optional {
+		right = "o|"
+	}
+	return left + line + right
+}
+
+var reDiagramInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// diagramIdentifier returns name with any characters not permitted in Mermaid
+// or PlantUML identifiers replaced by underscores.
+func diagramIdentifier(name string) string {
+	return reDiagramInvalidChars.ReplaceAllString(name, "_")
+}
+
+var reMermaidTypeInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]`)
+
+// mermaidType returns a representation of ct which is a valid Mermaid
+// attribute type: spaces become underscores, and types with other special
+// characters (such as enum value lists) are reduced to their base type.
+func mermaidType(ct tengo.ColumnType) string {
+	typ := strings.ReplaceAll(ct.String(), " ", "_")
+	if reMermaidTypeInvalidChars.MatchString(typ) {
+		return ct.Base
+	}
+	return typ
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestERGraph(t *testing.T) {
+	creates := []string{
+		"CREATE TABLE `customers` (\n  `id` int unsigned NOT NULL,\n  `email` varchar(100) NOT NULL,\n  PRIMARY KEY (`id`),\n  UNIQUE KEY `email` (`email`)\n) ENGINE=InnoDB",
+		"CREATE TABLE `orders` (\n  `id` int unsigned NOT NULL,\n  `customer_id` int unsigned NOT NULL,\n  `category_id` int unsigned DEFAULT NULL,\n  `status` enum('new','paid') NOT NULL,\n  PRIMARY KEY (`id`),\n  KEY `customer_id` (`customer_id`),\n  CONSTRAINT `orders_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)\n) ENGINE=InnoDB",
+		"CREATE TABLE `categories` (\n  `id` int unsigned NOT NULL,\n  `parent_id` int unsigned DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+		"CREATE TABLE `invoices` (\n  `order_id` int unsigned NOT NULL,\n  `party_id` varchar(20) NOT NULL,\n  PRIMARY KEY (`order_id`)\n) ENGINE=InnoDB",
+		"CREATE TABLE `parties` (\n  `id` int unsigned NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+	}
+	flavor := tengo.ParseFlavor("mysql:8.0")
+	s := &tengo.Schema{Name: "shop"}
+	for _, stmt := range creates {
+		table, err := tengo.ParseCreateTable(stmt, flavor, "utf8mb4", "utf8mb4_0900_ai_ci")
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+		}
+		s.Tables = append(s.Tables, table)
+	}
+	schemas := []*tengo.Schema{s}
+
+	// Expected relationships: the foreign key from orders to customers; the
+	// inferred reference from orders to categories; and the inferred one-to-one
+	// reference from invoices to orders. The categories.parent_id column has no
+	// matching table, and invoices.party_id has a mismatched type.
+	g := newERGraph(schemas, true)
+	if len(g.Entities) != 5 {
+		t.Errorf("Expected 5 entities, instead found %d", len(g.Entities))
+	}
+	var rels []string
+	for _, rel := range g.Relationships {
+		rels = append(rels, rel.From.Name+" "+rel.crowsFoot()+" "+rel.To.Name)
+	}
+	expected := "orders }o--|| customers, orders }o..o| categories, invoices |o..|| orders"
+	if actual := strings.Join(rels, ", "); actual != expected {
+		t.Errorf("Unexpected relationships:\nexpected: %s\nfound:    %s", expected, actual)
+	}
+	if g = newERGraph(schemas, false); len(g.Relationships) != 1 || g.Relationships[0].Label != "orders_customer" {
+		t.Errorf("Unexpected relationships without inference: %+v", g.Relationships)
+	}
+
+	g = newERGraph(schemas, true)
+	mermaid := g.Mermaid(true)
+	expectedLines := []string{
+		"erDiagram\n",
+		"    orders {\n        int(10)_unsigned id PK\n        int(10)_unsigned customer_id FK\n        int(10)_unsigned category_id FK\n        enum status\n    }\n",
+		"        varchar(100) email UK\n",
+		"        int(10)_unsigned order_id PK, FK\n",
+		"    orders }o--|| customers : \"orders_customer\"\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("Expected Mermaid output to contain %q, but it did not. Full output:\n%s", line, mermaid)
+		}
+	}
+	if mermaid = g.Mermaid(false); strings.Contains(mermaid, "{") || !strings.Contains(mermaid, "\n    parties\n") {
+		t.Errorf("Unexpected Mermaid output without columns:\n%s", mermaid)
+	}
+
+	plantuml := g.PlantUML(true)
+	expectedLines = []string{
+		"@startuml\n",
+		"entity \"orders\" as orders {\n  * id : int(10) unsigned <<PK>>\n  --\n  * customer_id : int(10) unsigned <<FK>>\n  category_id : int(10) unsigned <<FK>>\n",
+		"invoices |o..|| orders : order_id\n",
+		"@enduml\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(plantuml, line) {
+			t.Errorf("Expected PlantUML output to contain %q, but it did not. Full output:\n%s", line, plantuml)
+		}
+	}
+
+	dot := g.DOT(true)
+	expectedLines = []string{
+		"digraph schema {\n",
+		"<td align=\"left\">status enum(&#39;new&#39;,&#39;paid&#39;)</td>",
+		"\t\"orders\" -> \"customers\" [label=\"orders_customer\", arrowhead=tee];\n",
+		"\t\"invoices\" -> \"orders\" [label=\"order_id\", style=dashed, arrowtail=tee, arrowhead=tee];\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(dot, line) {
+			t.Errorf("Expected DOT output to contain %q, but it did not. Full output:\n%s", line, dot)
+		}
+	}
+
+	// With multiple schemas, entity names should be qualified
+	schemas = append(schemas, &tengo.Schema{Name: "other", Tables: []*tengo.Table{schemas[0].Tables[4]}})
+	g = newERGraph(schemas, false)
+	if g.Entities[0].Name != "shop.customers" || g.Entities[5].Name != "other.parties" {
+		t.Errorf("Unexpected entity names with multiple schemas: %s, %s", g.Entities[0].Name, g.Entities[5].Name)
+	}
+	if id := diagramIdentifier(g.Entities[0].Name); id != "shop_customers" {
+		t.Errorf("Unexpected result from diagramIdentifier: %q", id)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	summary := "Display statements previously executed by push"
+	desc := "Displays the most recent statements executed by `skeema push` against the " +
+		"schemas mapped to the current directory, as recorded in the _skeema_history " +
+		"table. Recording only occurs if the history-schema option was configured at " +
+		"push time; this command reads from the same history-schema.\n\n" +
+		"Only the first instance and schema of the directory are examined, unless " +
+		"--all-schemas is used.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("history", summary, desc, HistoryHandler)
+	cmd.AddOption(mybase.StringOption("history-schema", 0, "", "Schema containing the _skeema_history table written by `skeema push`"))
+	cmd.AddOption(mybase.StringOption("limit", 0, "20", "Maximum number of statements to display per schema"))
+	cmd.AddOption(mybase.BoolOption("all-schemas", 0, false, "Display history for all schemas mapped to this dir, not just the first"))
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// HistoryHandler is the handler method for `skeema history`
+func HistoryHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	historySchema := dir.Config.Get("history-schema")
+	if historySchema == "" {
+		return NewExitValue(CodeBadConfig, "Option history-schema must be configured to use this command")
+	}
+	limit, err := dir.Config.GetInt("limit")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if limit < 1 {
+		return NewExitValue(CodeBadConfig, "Option limit must be at least 1")
+	}
+	inst, err := dir.FirstInstance()
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if inst == nil {
+		return NewExitValue(CodeBadConfig, "No host defined for environment %q", dir.Config.Get("environment"))
+	}
+	schemaNames, err := dir.SchemaNames(inst)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if len(schemaNames) == 0 {
+		return NewExitValue(CodeBadConfig, "No schema defined for environment %q", dir.Config.Get("environment"))
+	} else if !dir.Config.GetBool("all-schemas") {
+		schemaNames = schemaNames[:1]
+	}
+
+	for _, schemaName := range schemaNames {
+		entries, err := applier.QueryHistory(inst, historySchema, schemaName, limit)
+		if tengo.IsObjectNotFoundError(err) {
+			return NewExitValue(CodeBadConfig, "No push history table found in schema %s on %s", historySchema, inst)
+		} else if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			log.Infof("%s %s: no push history recorded", inst, schemaName)
+			continue
+		}
+		fmt.Printf("-- instance: %s\nUSE %s;\n", inst, tengo.EscapeIdentifier(schemaName))
+		for _, entry := range entries {
+			fmt.Print(formatHistoryEntry(entry))
+		}
+	}
+	return nil
+}
+
+// formatHistoryEntry returns a human-readable representation of entry, with
+// its metadata as a SQL comment preceding its statement.
+func formatHistoryEntry(entry applier.HistoryEntry) string {
+	status := "success"
+	if !entry.Success {
+		status = "failed: " + entry.Error
+	}
+	gitSHA := entry.GitSHA
+	if len(gitSHA) > 12 {
+		gitSHA = gitSHA[:12]
+	}
+	if gitSHA == "" {
+		gitSHA = "unknown"
+	}
+	statement := entry.Statement + ";\n"
+	if objType := tengo.ObjectType(entry.ObjectType); objType == tengo.ObjectTypeProc || objType == tengo.ObjectTypeFunc {
+		statement = "DELIMITER //\n" + entry.Statement + "//\nDELIMITER ;\n"
+	}
+	return fmt.Sprintf("-- #%d at %s by %s, git %s, %dms, %s\n%s",
+		entry.ID, entry.ExecutedAt.UTC().Format("2006-01-02 15:04:05 MST"), entry.User, gitSHA, entry.DurationMS, status, statement)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/applier"
+)
+
+func TestFormatHistoryEntry(t *testing.T) {
+	entry := applier.HistoryEntry{
+		ID:         42,
+		ObjectType: "table",
+		Statement:  "ALTER TABLE `users` ADD COLUMN `name` varchar(30)",
+		DurationMS: 1234,
+		User:       "alice",
+		GitSHA:     "0123456789abcdef0123456789abcdef01234567",
+		Success:    true,
+		ExecutedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	expected := "-- #42 at 2026-01-02 03:04:05 UTC by alice, git 0123456789ab, 1234ms, success\nALTER TABLE `users` ADD COLUMN `name` varchar(30);\n"
+	if actual := formatHistoryEntry(entry); actual != expected {
+		t.Errorf("Unexpected result from formatHistoryEntry:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+
+	entry.ObjectType = "procedure"
+	entry.Statement = "CREATE PROCEDURE `p`() BEGIN SELECT 1; END"
+	entry.GitSHA = ""
+	entry.Success = false
+	entry.Error = "Error 1304: PROCEDURE p already exists"
+	expected = "-- #42 at 2026-01-02 03:04:05 UTC by alice, git unknown, 1234ms, failed: Error 1304: PROCEDURE p already exists\nDELIMITER //\nCREATE PROCEDURE `p`() BEGIN SELECT 1; END//\nDELIMITER ;\n"
+	if actual := formatHistoryEntry(entry); actual != expected {
+		t.Errorf("Unexpected result from formatHistoryEntry:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+}
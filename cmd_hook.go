@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/shellout"
+)
+
+func init() {
+	summary := "Manage git hooks which check schema changes before commit or push"
+	desc := "Manages git hooks which run `skeema format` and `skeema lint` against " +
+		"directories containing modified *.sql or .skeema files."
+	suite := mybase.NewCommandSuite("hook", summary, desc)
+
+	summary = "Install a git hook which lints changed schema directories"
+	desc = "Installs a git pre-commit or pre-push hook into the git repository containing " +
+		"the working directory. The hook determines which directories contain modified " +
+		"*.sql or .skeema files, and then runs `skeema format --skip-write` and " +
+		"`skeema lint --skip-format` on only those directories. The commit or push is " +
+		"blocked if any files are not formatted canonically, or if any linter errors " +
+		"are found.\n\n" +
+		"To keep hook latency low, the --docker-workspace option configures the hook to " +
+		"use a persistent Docker container as its workspace, which is re-used by " +
+		"subsequent invocations rather than being created from scratch each time.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will " +
+		"affect which section of .skeema config files is used by the hook's lint and " +
+		"format commands. If no environment name is supplied, the default is " +
+		"\"production\"."
+
+	cmd := mybase.NewCommand("install", summary, desc, HookInstallHandler)
+	cmd.AddOptions("hook",
+		mybase.StringOption("type", 0, "pre-commit", `Type of git hook to install (valid values: "pre-commit", "pre-push")`),
+		mybase.BoolOption("force", 0, false, "Overwrite any existing hook of the same type"),
+		mybase.BoolOption("docker-workspace", 0, false, "Run hook commands with a persistent Docker workspace container, to reduce latency"),
+		mybase.StringOption("skeema-path", 0, "skeema", "Path to the skeema binary to invoke from the hook"),
+	)
+	cmd.AddArg("environment", "production", false)
+	suite.AddSubCommand(cmd)
+	CommandSuite.AddSubCommand(suite)
+}
+
+// HookInstallHandler is the handler method for `skeema hook install`
+func HookInstallHandler(cfg *mybase.Config) error {
+	hookType, err := cfg.GetEnum("type", "pre-commit", "pre-push")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	environment := cfg.Get("environment")
+	if environment == "" || strings.ContainsAny(environment, "[]\n\r\"'`$\\") {
+		return NewExitValue(CodeBadConfig, "Environment name \"%s\" is invalid", environment)
+	}
+
+	// Ask git for the hooks path, which correctly handles worktrees as well as
+	// any custom core.hooksPath setting
+	hookPath, err := shellout.New("git rev-parse --git-path hooks/" + hookType).RunCapture()
+	if err != nil {
+		return NewExitValue(CodeBadUsage, "Unable to locate git hooks directory: %s. This command must be run from within a git repository.", err)
+	}
+	hookPath = strings.TrimSpace(hookPath)
+	if _, err := os.Stat(hookPath); err == nil && !cfg.GetBool("force") {
+		return NewExitValue(CodeCantCreate, "A %s hook already exists at %s. Use --force to overwrite it.", hookType, hookPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return WrapExitCode(CodeCantCreate, err)
+	}
+
+	var extraArgs string
+	if cfg.GetBool("docker-workspace") {
+		extraArgs = " --workspace=docker --docker-cleanup=none"
+	}
+	contents := hookScript(hookType, cfg.Get("skeema-path"), environment, extraArgs)
+	if err := os.WriteFile(hookPath, []byte(contents), 0755); err != nil {
+		return WrapExitCode(CodeCantCreate, err)
+	}
+	log.Infof("Installed %s hook at %s", hookType, hookPath)
+	return nil
+}
+
+// hookScript returns the contents of a POSIX shell script suitable for use as
+// a git hook of the supplied type.
+func hookScript(hookType, skeemaPath, environment, extraArgs string) string {
+	// pre-commit hooks examine the staged changes, whereas pre-push hooks examine
+	// all commits not yet present on the upstream branch
+	changedFiles := "git diff --cached --name-only --diff-filter=ACMR"
+	if hookType == "pre-push" {
+		changedFiles = "git diff --name-only --diff-filter=ACMR @{upstream}...HEAD 2>/dev/null || git diff --name-only --diff-filter=ACMR HEAD~1...HEAD"
+	}
+	// For pre-commit hooks, lint only examines objects in files changed relative
+	// to HEAD, rather than every object in each affected directory
+	lintArgs := extraArgs
+	if hookType == "pre-commit" {
+		lintArgs += " --changed-since=HEAD"
+	}
+	skeemaPath, environment = shellSingleQuote(skeemaPath), shellSingleQuote(environment)
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# %s hook installed by `skeema hook install`, %s\n", hookType, versionString())
+	b.WriteString("# Runs skeema format and lint on directories with modified *.sql or .skeema files.\n\n")
+	fmt.Fprintf(&b, "toplevel=$(git rev-parse --show-toplevel) || exit 1\ncd \"$toplevel\" || exit 1\n")
+	fmt.Fprintf(&b, "dirs=$( (%s) | grep -E '(\\.sql|(^|/)\\.skeema)$' | sed -e '/\\//!s,.*,.,' -e 's,/[^/]*$,,' | sort -u)\n", changedFiles)
+	b.WriteString("[ -z \"$dirs\" ] && exit 0\n\n")
+	b.WriteString("status=0\n")
+	b.WriteString("IFS='\n'\n") // split dirs only on newlines, in case of spaces in dir names
+	b.WriteString("for dir in $dirs; do\n")
+	b.WriteString("\t[ -d \"$dir\" ] || continue\n")
+	fmt.Fprintf(&b, "\t(cd \"$dir\" && %s format --skip-write%s %s) || status=1\n", skeemaPath, extraArgs, environment)
+	fmt.Fprintf(&b, "\t(cd \"$dir\" && %s lint --skip-format%s %s)\n", skeemaPath, lintArgs, environment)
+	b.WriteString("\t[ $? -ge 2 ] && status=1\n")
+	b.WriteString("done\n")
+	b.WriteString("if [ $status -ne 0 ]; then\n")
+	fmt.Fprintf(&b, "\techo \"skeema %s hook: problems found in schema files; run skeema format and skeema lint for details\" >&2\n", hookType)
+	b.WriteString("fi\n")
+	b.WriteString("exit $status\n")
+	return b.String()
+}
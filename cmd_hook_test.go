@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookScript(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available on PATH")
+	}
+	repoDir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error from git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	writeFile := func(path, contents string, perm os.FileMode) {
+		t.Helper()
+		path = filepath.Join(repoDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unexpected error from MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), perm); err != nil {
+			t.Fatalf("Unexpected error from WriteFile: %v", err)
+		}
+	}
+
+	// The fake skeema binary logs its working dir and args, and exits with the
+	// code in $FAKE_LINT_EXIT when running lint
+	logPath := filepath.Join(repoDir, "invocations.log")
+	fakeSkeema := filepath.Join(repoDir, "fake-skeema")
+	writeFile("fake-skeema", "#!/bin/sh\necho \"$(basename \"$PWD\") $*\" >> "+logPath+"\n[ \"$1\" = lint ] && exit ${FAKE_LINT_EXIT:-0}\nexit 0\n", 0755)
+	runGit("init", "-q")
+	writeFile("product/users.sql", "CREATE TABLE users (id int);\n", 0644)
+	writeFile("analytics/.skeema", "schema=analytics\n", 0644)
+	writeFile("README.md", "readme\n", 0644)
+	runGit("add", "product/users.sql", "analytics/.skeema", "README.md")
+
+	script := hookScript("pre-commit", fakeSkeema, "staging", "")
+	runHook := func(lintExitCode string) error {
+		cmd := exec.Command("sh", "-c", script)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "FAKE_LINT_EXIT="+lintExitCode)
+		return cmd.Run()
+	}
+
+	if err := runHook("1"); err != nil {
+		t.Errorf("Expected hook to succeed when lint only emits warnings, but it returned %v", err)
+	}
+	contents, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Unexpected error reading invocation log: %v", err)
+	}
+	expected := "analytics format --skip-write staging\nanalytics lint --skip-format --changed-since=HEAD staging\nproduct format --skip-write staging\nproduct lint --skip-format --changed-since=HEAD staging\n"
+	if string(contents) != expected {
+		t.Errorf("Unexpected invocations from hook script:\n%s\nExpected:\n%s", contents, expected)
+	}
+
+	if err := runHook("2"); err == nil {
+		t.Error("Expected hook to fail when lint emits errors, but it succeeded")
+	}
+
+	// Environment names are passed as a single literal arg, without any shell
+	// interpretation
+	if err := os.Remove(logPath); err != nil {
+		t.Fatalf("Unexpected error removing invocation log: %v", err)
+	}
+	script = hookScript("pre-commit", fakeSkeema, "my env;touch pwned", "")
+	if err := runHook("0"); err != nil {
+		t.Errorf("Expected hook to succeed, but it returned %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "analytics", "pwned")); err == nil {
+		t.Error("Environment name was interpreted by the shell")
+	}
+	if contents, err = os.ReadFile(logPath); err != nil {
+		t.Fatalf("Unexpected error reading invocation log: %v", err)
+	}
+	if !strings.Contains(string(contents), "analytics format --skip-write my env;touch pwned\n") {
+		t.Errorf("Unexpected invocations from hook script:\n%s", contents)
+	}
+}
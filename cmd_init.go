@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/skeema/skeema/internal/dumper"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
 )
 
 func init() {
@@ -28,7 +30,14 @@ func init() {
 		"For example, running `skeema init staging` will add config directives to the " +
 		"[staging] section of config files. If no environment name is supplied, the " +
 		"default is \"production\", so directives will be written to the [production] " +
-		"section of the file."
+		"section of the file.\n\n" +
+		"With --from-dump, schemas are instead obtained from a schema-only dump from " +
+		"mysqldump (optionally gzip-compressed), mydumper, or MySQL Shell's " +
+		"util.dumpInstance / util.dumpSchemas, which is loaded into a workspace " +
+		"without accessing any live database server. This requires --workspace=docker " +
+		"or --workspace=kubernetes along with --flavor, or --workspace=parse. The " +
+		"resulting directories are not associated with any host; use `skeema " +
+		"add-environment` afterwards to configure one."
 
 	cmd := mybase.NewCommand("init", summary, desc, InitHandler)
 	cmd.AddOption(mybase.StringOption("host", 'h', "", "Database hostname or IP address"))
@@ -38,12 +47,12 @@ func init() {
 	cmd.AddOption(mybase.StringOption("schema", 0, "", "Only import the one specified schema; skip creation of subdirs for each schema"))
 	cmd.AddOption(mybase.BoolOption("include-auto-inc", 0, false, "Include starting auto-inc values in table files"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Omit PARTITION BY clause when writing partitioned tables to filesystem"))
+	cmd.AddOption(mybase.StringOption("from-dump", 0, "", "Obtain schemas from schema-only dump file, or mydumper or MySQL Shell dump dir, at this path instead of a DB server"))
 
-	// The temp-schema option is normally added via workspace.AddCommandOptions()
-	// only in subcommands that actually interact with workspaces. init doesn't use
-	// workspaces, but it just needs this one option to prevent accidental export
-	// of the temp-schema to the filesystem.
-	cmd.AddOption(mybase.StringOption("temp-schema", 't', "_skeema_tmp", "").Hidden())
+	// Workspaces are only used with --from-dump, but the temp-schema option is
+	// also needed to prevent accidental export of the temp-schema to the
+	// filesystem.
+	workspace.AddCommandOptions(cmd)
 
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -65,6 +74,10 @@ func InitHandler(cfg *mybase.Config) error {
 		return NewExitValue(CodeBadConfig, "Environment name \"%s\" is invalid", environment)
 	}
 
+	if dumpPath := cfg.Get("from-dump"); dumpPath != "" {
+		return initFromDump(cfg, dumpPath)
+	}
+
 	hostDir, err := createHostDir(cfg)
 	if err != nil {
 		return err
@@ -131,7 +144,12 @@ func createHostDir(cfg *mybase.Config) (*fs.Dir, error) {
 	if !cfg.Changed("dir") { // default for dir is to base it on the hostname
 		hostDirName = fs.HostDefaultDirName(cfg.Get("host"), cfg.GetIntOrDefault("port"))
 	}
+	return createInitDir(cfg, hostDirName)
+}
 
+// createInitDir creates and parses the top-level dir for `skeema init`,
+// confirming it is sufficiently empty/usable.
+func createInitDir(cfg *mybase.Config, hostDirName string) (*fs.Dir, error) {
 	// Attempt to create the dir, without erroring if it already exists. Then parse
 	// it and confirm it is sufficiently empty/usable.
 	if err := os.MkdirAll(hostDirName, 0777); err != nil {
@@ -197,9 +215,116 @@ func createHostOptionFile(cfg *mybase.Config, hostDir *fs.Dir, inst *tengo.Insta
 	return nil
 }
 
+// initFromDump handles `skeema init --from-dump`, populating a new dir tree
+// using the schemas in the dump at dumpPath, which are loaded into a workspace
+// to obtain their canonical form.
+func initFromDump(cfg *mybase.Config, dumpPath string) error {
+	if cfg.OnCLI("host") {
+		return NewExitValue(CodeBadUsage, "Options --from-dump and --host cannot be used together")
+	}
+	dumpSchemas, err := fs.ParseDump(dumpPath)
+	if err != nil {
+		return WrapExitCode(CodeNoInput, err)
+	}
+
+	// Determine which schemas to use. A dump of a single database without
+	// mysqldump --databases has no schema name, so --schema is required to supply
+	// one.
+	onlySchema := cfg.Get("schema")
+	var logicalSchemas []*fs.LogicalSchema
+	if onlySchema != "" {
+		ls := dumpSchemas[onlySchema]
+		if ls == nil && len(dumpSchemas) == 1 && dumpSchemas[""] != nil {
+			ls = dumpSchemas[""]
+			ls.Name = onlySchema
+		}
+		if ls == nil {
+			return NewExitValue(CodeBadConfig, "Schema %s does not exist in dump %s", onlySchema, dumpPath)
+		}
+		logicalSchemas = append(logicalSchemas, ls)
+	} else {
+		for name, ls := range dumpSchemas {
+			if name == "" {
+				return NewExitValue(CodeBadConfig, "Dump %s contains statements without a database name. Use --schema to specify which schema name to use.", dumpPath)
+			} else if !isSystemSchema(name) && name != "test" {
+				logicalSchemas = append(logicalSchemas, ls)
+			}
+		}
+		slices.SortFunc(logicalSchemas, func(a, b *fs.LogicalSchema) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	if len(logicalSchemas) == 0 {
+		return NewExitValue(CodeBadConfig, "Dump %s does not contain any supported object definitions", dumpPath)
+	}
+
+	dirName := cfg.Get("dir")
+	if !cfg.Changed("dir") { // default for dir is to base it on the dump's name
+		dirName = strings.TrimSuffix(filepath.Base(filepath.Clean(dumpPath)), ".gz")
+		dirName = strings.TrimSuffix(dirName, filepath.Ext(dirName))
+	}
+	hostDir, err := createInitDir(cfg, dirName)
+	if err != nil {
+		return err
+	}
+	wsOpts, err := workspace.OptionsForDir(hostDir, nil)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if wsOpts.Type == workspace.TypeTempSchema {
+		return NewExitValue(CodeBadConfig, "Option --from-dump requires --workspace=docker, --workspace=kubernetes, or --workspace=parse")
+	} else if !wsOpts.Flavor.Known() {
+		return NewExitValue(CodeBadConfig, "Option --from-dump requires --flavor to indicate which database server version the dump was obtained from")
+	}
+
+	// Load each schema into a workspace. This is done before writing any files,
+	// so that the dir may still be re-used after correcting any problems.
+	schemas := make([]*tengo.Schema, 0, len(logicalSchemas))
+	for _, ls := range logicalSchemas {
+		wsSchema, err := workspace.ExecLogicalSchema(ls, wsOpts)
+		if err != nil {
+			return NewExitValue(CodeFatalError, "Unable to load schema %s from dump: %s", ls.Name, err)
+		}
+		for _, failure := range wsSchema.Failures {
+			log.Warnf("Skipping %s in schema %s: %s", failure.ObjectKey(), ls.Name, failure)
+		}
+		s := wsSchema.Schema
+		s.Name = ls.Name
+		schemas = append(schemas, s)
+	}
+
+	// Write top-level option file. Since the dump is not associated with any host,
+	// no environment-specific options are written.
+	optionFile := mybase.NewFile(hostDir.Path, ".skeema")
+	if !cfg.Changed("generator") {
+		optionFile.SetOptionValue("", "generator", generatorString())
+	}
+	optionFile.SetOptionValue("", "flavor", wsOpts.Flavor.Family().String())
+	if onlySchema != "" {
+		optionFile.SetOptionValue("", "schema", onlySchema)
+		setSchemaDefaultOptions(optionFile, schemas[0])
+	}
+	if err := hostDir.CreateOptionFile(optionFile); err != nil {
+		return NewExitValue(CodeCantCreate, "Unable to use directory %s: Unable to write to %s: %s", hostDir.Path, optionFile.Path(), err)
+	}
+	log.Infof("Using dir %s for dump %s\n", hostDir.Path, dumpPath)
+
+	for _, s := range schemas {
+		s.StripMatches(hostDir.IgnorePatterns)
+		if err := PopulateSchemaDir(s, hostDir, onlySchema == ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var persistConnectivityOptionExactMatch = []string{
 	"user",
 	"connect-options",
+	"connect-timeout",
+	"read-timeout",
+	"max-open-conns",
+	"max-idle-conns",
+	"conn-max-lifetime",
 }
 
 var persistConnectivityOptionPrefix = []string{
@@ -226,6 +351,18 @@ func persistOptionAlongsideHost(optionName string) bool {
 	return false
 }
 
+// setSchemaDefaultOptions sets the default-character-set and default-collation
+// options in optionFile based on s. Blank values, which are only possible for
+// schemas loaded from a dump into workspace=parse, are omitted.
+func setSchemaDefaultOptions(optionFile *mybase.File, s *tengo.Schema) {
+	if s.CharSet != "" {
+		optionFile.SetOptionValue("", "default-character-set", s.CharSet)
+	}
+	if s.Collation != "" {
+		optionFile.SetOptionValue("", "default-collation", s.Collation)
+	}
+}
+
 func generatorString() string {
 	return "skeema:" + versionString()
 }
@@ -237,8 +374,9 @@ func generatorString() string {
 // responsibility to ensure its .skeema option file exists and maps to the
 // correct schema name.
 func PopulateSchemaDir(s *tengo.Schema, parentDir *fs.Dir, makeSubdir bool) error {
-	// Ignore any attempt to populate a dir for the temp schema
-	if s.Name == parentDir.Config.GetAllowEnvVar("temp-schema") {
+	// Ignore any attempt to populate a dir for the temp schema, or any of its
+	// pooled variants
+	if workspace.IsTempSchemaName(s.Name, parentDir.Config.GetAllowEnvVar("temp-schema")) {
 		return nil
 	}
 
@@ -263,8 +401,7 @@ func PopulateSchemaDir(s *tengo.Schema, parentDir *fs.Dir, makeSubdir bool) erro
 		}
 		optionFile := mybase.NewFile(dir.Path, ".skeema")
 		optionFile.SetOptionValue("", "schema", s.Name)
-		optionFile.SetOptionValue("", "default-character-set", s.CharSet)
-		optionFile.SetOptionValue("", "default-collation", s.Collation)
+		setSchemaDefaultOptions(optionFile, s)
 		if err = dir.CreateOptionFile(optionFile); err != nil {
 			return NewExitValue(CodeCantCreate, "Cannot use dir %s for schema %s: %v", dir.Path, s.Name, err)
 		}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -31,12 +32,18 @@ func init() {
 		"apply config directives from the [staging] section of config files, as well as " +
 		"any sectionless directives at the top of the file. If no environment name is " +
 		"supplied, the default is \"production\".\n\n" +
+		"With the --changed-since option, only objects defined in *.sql files which differ " +
+		"from the supplied git ref (including uncommitted and untracked files) are linted " +
+		"and reformatted. If a directory's .skeema file has changed, all objects in that " +
+		"directory are linted.\n\n" +
 		"An exit code of 0 will be returned if no errors or warnings were emitted and all " +
 		"files were already formatted properly; 1 if any warnings were emitted and/or " +
 		"some files were reformatted; or 2+ if any errors were emitted for any reason."
 
 	cmd := mybase.NewCommand("lint", summary, desc, LintHandler)
 	linter.AddCommandOptions(cmd)
+	cmd.AddOption(mybase.StringOption("changed-since", 0, "", "Only lint objects in files changed relative to this git ref"))
+	cmd.AddOption(mybase.StringOption("output-format", 0, "text", "Format of STDOUT output: \"text\" for none beyond log messages, or \"markdown\" for a summary of problems grouped by rule"))
 	cmd.AddOptions("Format",
 		mybase.BoolOption("format", 0, true, "Reformat SQL statements to match canonical SHOW CREATE"),
 		mybase.BoolOption("strip-partitioning", 0, false, "Remove PARTITION BY clauses from *.sql files"),
@@ -52,8 +59,25 @@ func LintHandler(cfg *mybase.Config) error {
 	if err != nil {
 		return err
 	}
+	outputFormat, err := dir.Config.GetEnum("output-format", "text", "markdown")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+
+	// With --changed-since, determine which files have been modified relative to
+	// the supplied git ref. A nil map means no such filtering is performed.
+	var changedFiles map[string]bool
+	if ref := cfg.Get("changed-since"); ref != "" {
+		if changedFiles, err = util.GitChangedFiles(dir.Path, ref); err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		log.Debugf("Found %d files changed since git ref %s", len(changedFiles), ref)
+	}
 
-	result := lintWalker(dir, 5)
+	result := lintWalker(dir, 5, changedFiles)
+	if outputFormat == "markdown" {
+		fmt.Print(result.Markdown())
+	}
 	switch {
 	case len(result.Exceptions) > 0:
 		exitCode := ExitCode(HighestExitCode(result.Exceptions...))
@@ -79,13 +103,13 @@ func LintHandler(cfg *mybase.Config) error {
 	return nil
 }
 
-func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
+func lintWalker(dir *fs.Dir, maxDepth int, changedFiles map[string]bool) *linter.Result {
 	if dir.ParseError != nil {
 		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError))
 		return linter.BadConfigResult(dir, dir.ParseError)
 	}
 	log.Infof("Linting %s", dir)
-	result := lintDir(dir)
+	result := lintDir(dir, changedFiles)
 	for _, err := range result.Exceptions {
 		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.RelPath(), err))
 	}
@@ -108,7 +132,7 @@ func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
 		subdirErr = fmt.Errorf("Not walking subdirs of %s: max depth reached", dir)
 	} else {
 		for _, sub := range subdirs {
-			result.Merge(lintWalker(sub, maxDepth-1))
+			result.Merge(lintWalker(sub, maxDepth-1, changedFiles))
 		}
 	}
 	if subdirErr != nil {
@@ -120,8 +144,10 @@ func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
 
 // lintDir lints all logical schemas in dir, optionally also reformatting
 // SQL statements along the way. A combined result for the directory is
-// returned. This function does not recurse into subdirs.
-func lintDir(dir *fs.Dir) *linter.Result {
+// returned. This function does not recurse into subdirs. If changedFiles is
+// non-nil, only objects defined in those files are linted, unless the dir's
+// own .skeema file is also in changedFiles.
+func lintDir(dir *fs.Dir, changedFiles map[string]bool) *linter.Result {
 	opts, err := linter.OptionsForDir(dir)
 	if err != nil {
 		// If there's nothing to lint here anyway, return an empty result instead
@@ -133,18 +159,36 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	}
 	opts.StripAnnotationNewlines = !util.StderrIsTerminal()
 
+	var changedKeys []tengo.ObjectKey
+	if changedFiles != nil && (dir.OptionFile == nil || !fileChanged(dir.OptionFile.Path(), changedFiles)) {
+		changedKeys = []tengo.ObjectKey{} // non-nil even if empty, to distinguish from no filtering
+		for _, logicalSchema := range dir.LogicalSchemas {
+			for key, stmt := range logicalSchema.Creates {
+				if fileChanged(stmt.File, changedFiles) {
+					changedKeys = append(changedKeys, key)
+				}
+			}
+		}
+		if len(changedKeys) == 0 {
+			log.Debugf("Skipping %s: no changed objects", dir)
+			return &linter.Result{}
+		}
+		opts.OnlyKeys(changedKeys)
+	}
+
 	// Get workspace options for dir. This involves connecting to the first defined
 	// instance, so that any auto-detect-related settings work properly. However,
 	// with workspace=docker we can ignore connection errors; we'll get reasonable
 	// defaults from workspace.OptionsForDir if inst is nil as long as flavor is set.
+	// With workspace=parse, connection errors are always ignored.
 	var wsOpts workspace.Options
 	if len(dir.LogicalSchemas) > 0 {
 		inst, err := dir.FirstInstance()
-		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker"); wsType != "docker" || !dir.Config.Changed("flavor") {
+		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker", "kubernetes", "parse"); wsType == "temp-schema" || (wsType != "parse" && !dir.Config.Changed("flavor")) {
 			if err != nil {
 				return linter.BadConfigResult(dir, err)
 			} else if inst == nil {
-				return linter.BadConfigResult(dir, fmt.Errorf("This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker), but one is not configured for environment %q", dir.Config.Get("environment")))
+				return linter.BadConfigResult(dir, fmt.Errorf("This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker or workspace=kubernetes), but one is not configured for environment %q", dir.Config.Get("environment")))
 			}
 		}
 		if wsOpts, err = workspace.OptionsForDir(dir, inst); err != nil {
@@ -179,6 +223,9 @@ func lintDir(dir *fs.Dir) *linter.Result {
 				dumpOpts.Partitioning = tengo.PartitioningRemove
 			}
 			dumpOpts.IgnoreKeys(wsSchema.FailedKeys())
+			if changedKeys != nil {
+				dumpOpts.OnlyKeys(changedKeys)
+			}
 			result.ReformatCount, err = dumper.DumpSchema(wsSchema.Schema, dir, dumpOpts)
 			if err != nil {
 				log.Errorf("Skipping format operation for %s: %s", dir, err)
@@ -199,6 +246,9 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	// exception, in which case skip it to avoid extra noise!)
 	if len(result.Exceptions) == 0 {
 		for _, stmt := range dir.UnparsedStatements {
+			if changedFiles != nil && !fileChanged(stmt.File, changedFiles) {
+				continue
+			}
 			note := linter.Note{
 				Summary: "Unable to parse statement",
 				Message: "Ignoring unsupported or unparseable SQL statement",
@@ -212,6 +262,16 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	return result
 }
 
+// fileChanged returns true if filePath is present in changedFiles, either as-is
+// or after evaluating symlinks.
+func fileChanged(filePath string, changedFiles map[string]bool) bool {
+	if changedFiles[filePath] {
+		return true
+	}
+	realPath, err := filepath.EvalSymlinks(filePath)
+	return err == nil && changedFiles[realPath]
+}
+
 func countAndNoun(n int, singular, plural string) string {
 	if n == 1 {
 		return fmt.Sprintf("1 %s", singular)
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -24,7 +25,10 @@ func init() {
 		"running `skeema pull staging` will apply config directives from the " +
 		"[staging] section of config files, as well as any sectionless directives at the " +
 		"top of the file. If no environment name is supplied, the default is " +
-		"\"production\"."
+		"\"production\".\n\n" +
+		"Only objects whose normalized definitions differ from the filesystem are " +
+		"rewritten. With --changed-only, these objects are listed on STDOUT instead, " +
+		"without modifying any files; the exit code is 1 if any differences were found."
 
 	cmd := mybase.NewCommand("pull", summary, desc, PullHandler)
 	cmd.AddOption(mybase.BoolOption("include-auto-inc", 0, false, "Include starting auto-inc values in new table files, and update in existing files"))
@@ -33,6 +37,7 @@ func init() {
 	cmd.AddOption(mybase.BoolOption("new-schemas", 0, true, "Detect any new schemas and populate new dirs for them"))
 	cmd.AddOption(mybase.BoolOption("update-partitioning", 0, false, "Update PARTITION BY clauses in existing table files"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Omit PARTITION BY clause when writing partitioned tables to filesystem"))
+	cmd.AddOption(mybase.BoolOption("changed-only", 0, false, "Only list objects whose definitions differ from the filesystem, without modifying any files"))
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -86,8 +91,10 @@ func pullWalker(dir *fs.Dir, maxDepth int) error {
 		// Otherwise, we're in a "flat" dir that defines both host and schema: update
 		// the flavor if needed and process the pull operation on *.sql files, but no
 		// need to look for new schemas with this layout
-		updateFlavor(dir, instance)
-		updateGenerator(dir)
+		if !dir.Config.GetBool("changed-only") {
+			updateFlavor(dir, instance)
+			updateGenerator(dir)
+		}
 		_, err := pullSchemaDir(dir, instance) // already logs err (if non-nil)
 		return err
 	}
@@ -102,6 +109,7 @@ func pullWalker(dir *fs.Dir, maxDepth int) error {
 	}
 
 	allSchemaNames := []string{}
+	var subdirFailed bool
 	for _, sub := range subdirs {
 		// If dir does not define host, simply recurse into subdirs.
 		if instance == nil {
@@ -115,15 +123,20 @@ func pullWalker(dir *fs.Dir, maxDepth int) error {
 		// new schema dirs need to be created (if requested).
 		subSchemaNames, subErr := pullSchemaDir(sub, instance) // already logs subErr (if non-nil)
 		err = HighestExitCode(err, subErr)
+		subdirFailed = subdirFailed || (subErr != nil && subErr != errPullDifferences)
 		allSchemaNames = append(allSchemaNames, subSchemaNames...)
 	}
 
 	if instance != nil {
-		updateFlavor(dir, instance)
-		updateGenerator(dir)
-		if dir.Config.GetBool("new-schemas") && err == nil {
-			if err = findNewSchemas(dir, instance, allSchemaNames); err != nil {
-				log.Warnf("Unable to populate new schemas from %s: %s", dir, err)
+		if !dir.Config.GetBool("changed-only") {
+			updateFlavor(dir, instance)
+			updateGenerator(dir)
+		}
+		if dir.Config.GetBool("new-schemas") && !subdirFailed {
+			if newErr := findNewSchemas(dir, instance, allSchemaNames); newErr == errPullDifferences {
+				err = HighestExitCode(err, newErr)
+			} else if newErr != nil {
+				log.Warnf("Unable to populate new schemas from %s: %s", dir, newErr)
 				return NewExitValue(CodePartialError, "")
 			}
 		}
@@ -131,6 +144,11 @@ func pullWalker(dir *fs.Dir, maxDepth int) error {
 	return err
 }
 
+// errPullDifferences is returned with --changed-only when differences were
+// found between the filesystem and a database server. Unlike other errors, it
+// is not logged.
+var errPullDifferences = NewExitValue(CodeDifferencesFound, "differences found")
+
 // pullSchemaDir updates all logical schemas in dir to reflect the actual
 // definitions found in instance. A slice of handled schema names is returned,
 // along with any error encountered.
@@ -143,7 +161,7 @@ func pullSchemaDir(dir *fs.Dir, instance *tengo.Instance) (schemaNames []string,
 		// TODO: support multiple logical schemas per dir
 		logicalSchema := dir.LogicalSchemas[0]
 		schemaNames, err = pullLogicalSchema(dir, instance, logicalSchema)
-		if err != nil {
+		if err != nil && err != errPullDifferences {
 			log.Errorf("Skipping %s: %s\n", dir, err)
 		}
 	}
@@ -162,8 +180,18 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 		}
 	}
 
+	// Schema names in the dir are logical names, which may be mapped to different
+	// physical names in the current environment
+	mapping, err := dir.SchemaNameMap()
+	if err != nil {
+		return nil, err
+	}
 	if logicalSchema.Name != "" {
-		schemaNames = []string{logicalSchema.Name}
+		if physicalName, ok := mapping[logicalSchema.Name]; ok {
+			schemaNames = []string{physicalName}
+		} else {
+			schemaNames = []string{logicalSchema.Name}
+		}
 	} else if schemaNames, err = dir.SchemaNames(instance); err != nil {
 		return nil, fmt.Errorf("unable to fetch schema names mapped by this dir: %w", err)
 	}
@@ -171,8 +199,12 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 		log.Warnf("Ignoring directory %s -- did not map to any schema names for environment %q\n", dir, dir.Config.Get("environment"))
 		return
 	}
+	changedOnly := dir.Config.GetBool("changed-only")
 	instSchema, err := instance.Schema(schemaNames[0])
-	if err == sql.ErrNoRows {
+	if err == sql.ErrNoRows && changedOnly {
+		fmt.Printf("DROP database %s %s\n", tengo.EscapeIdentifier(schemaNames[0]), dir.RelPath())
+		return nil, errPullDifferences
+	} else if err == sql.ErrNoRows {
 		log.Infof("Deleted directory %s -- schema %s no longer exists\n", dir, schemaNames[0])
 		return nil, dir.Delete()
 	} else if err != nil {
@@ -180,12 +212,28 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 	}
 	instSchema.StripMatches(dir.IgnorePatterns)
 
-	log.Infof("Updating %s to reflect %s %s", dir, instance, instSchema.Name)
+	// Express any cross-schema foreign key references using logical names
+	logicalNames := make(map[string]string, len(mapping))
+	for logicalName, physicalName := range mapping {
+		logicalNames[physicalName] = logicalName
+	}
+	instSchema = instSchema.RenameReferences(logicalNames)
 
-	// Handle changes in schema's default character set and/or collation by
-	// persisting changes to the dir's option file.
-	if err := updateCharSetCollation(dir, instSchema); err != nil {
-		return nil, err
+	var differences bool
+	if changedOnly {
+		log.Infof("Comparing %s to %s %s", dir, instance, instSchema.Name)
+		if dir.Config.Get("default-character-set") != instSchema.CharSet || dir.Config.Get("default-collation") != instSchema.Collation {
+			fmt.Printf("ALTER database %s %s\n", tengo.EscapeIdentifier(instSchema.Name), dir.OptionFile.Path())
+			differences = true
+		}
+	} else {
+		log.Infof("Updating %s to reflect %s %s", dir, instance, instSchema.Name)
+
+		// Handle changes in schema's default character set and/or collation by
+		// persisting changes to the dir's option file.
+		if err := updateCharSetCollation(dir, instSchema); err != nil {
+			return nil, err
+		}
 	}
 
 	dumpOpts := dumper.Options{
@@ -209,6 +257,9 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 	if !dir.Config.GetBool("format") || !dir.Config.GetBool("normalize") {
 		mods := statementModifiersForPull(dir.Config, instance)
 		opts, err := workspace.OptionsForDir(dir, instance)
+		if err == nil && opts.Type == workspace.TypeParse {
+			err = workspace.ErrParseWorkspace
+		}
 		if err != nil {
 			return nil, WrapExitCode(CodeBadConfig, err)
 		}
@@ -219,6 +270,31 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 		dumpOpts.OnlyKeys(inDiff)
 	}
 
+	// Compare per-object checksums of normalized definitions, so that objects
+	// whose files differ only in line endings or trailing whitespace are left
+	// alone, rather than churning file mtimes and git status.
+	changes := dumper.ChangedObjects(instSchema, dir, dumpOpts)
+	if changedOnly {
+		for _, change := range changes {
+			fmt.Printf("%s %s %s\n", change.Type, change.Key, filepath.Join(dir.RelPath(), filepath.Base(change.FilePath)))
+		}
+		if differences || len(changes) > 0 {
+			return schemaNames, errPullDifferences
+		}
+		return schemaNames, nil
+	}
+	changed := make(map[tengo.ObjectKey]bool, len(changes))
+	for _, change := range changes {
+		changed[change.Key] = true
+	}
+	var unchanged []tengo.ObjectKey
+	for key := range instSchema.Objects() {
+		if !changed[key] {
+			unchanged = append(unchanged, key)
+		}
+	}
+	dumpOpts.IgnoreKeys(unchanged)
+
 	_, err = dumper.DumpSchema(instSchema, dir, dumpOpts)
 	if err == nil {
 		os.Stderr.WriteString("\n")
@@ -268,10 +344,11 @@ func objectsInDiff(logicalSchema *fs.LogicalSchema, instSchema *tengo.Schema, op
 	}
 
 	// Run a diff, and create a map to track objects in the diff
-	diff := tengo.NewSchemaDiff(wsSchema.Schema, instSchema)
+	objDiffs := tengo.NewSchemaDiff(wsSchema.Schema, instSchema).ObjectDiffs()
+	stmts, errs := tengo.ObjectDiffStatements(objDiffs, mods)
 	inDiff := make([]tengo.ObjectKey, 0)
-	for _, od := range diff.ObjectDiffs() {
-		odStatement, odStatementErr := od.Statement(mods)
+	for n, od := range objDiffs {
+		odStatement, odStatementErr := stmts[n], errs[n]
 		key := od.ObjectKey()
 		// Errors are fatal, except for UnsupportedDiffError which we can safely
 		// ignore (since pull doesn't actually run ALTERs; it just needs to know
@@ -347,9 +424,14 @@ func findNewSchemas(dir *fs.Dir, instance *tengo.Instance, seenNames []string) e
 	if err != nil {
 		return err
 	}
+	var differences bool
 	for _, name := range schemaNames {
-		// If no existing subdir maps to the schema, we need to create and populate new dir
-		if !subdirHasSchema[name] {
+		// If no existing subdir maps to the schema, we need to create and populate
+		// new dir, or just report it with --changed-only
+		if !subdirHasSchema[name] && dir.Config.GetBool("changed-only") {
+			fmt.Printf("CREATE database %s %s\n", tengo.EscapeIdentifier(name), filepath.Join(dir.RelPath(), name))
+			differences = true
+		} else if !subdirHasSchema[name] {
 			s, err := instance.Schema(name)
 			if err != nil {
 				return err
@@ -361,6 +443,8 @@ func findNewSchemas(dir *fs.Dir, instance *tengo.Instance, seenNames []string) e
 			}
 		}
 	}
-
+	if differences {
+		return errPullDifferences
+	}
 	return nil
 }
@@ -1,16 +1,22 @@
 package main
 
 import (
-	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/applier"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/skeema/internal/workspace"
-	"golang.org/x/sync/errgroup"
 )
 
 func init() {
@@ -24,6 +30,25 @@ func init() {
 		"running `skeema push staging` will apply config directives from the " +
 		"[staging] section of config files, as well as any sectionless directives at the " +
 		"top of the file. If no environment name is supplied, the default is \"production\".\n\n" +
+		"For automation by external tooling, such as a Terraform provider, `skeema diff " +
+		"--save-plan` writes a JSON plan file, and `skeema push --plan` applies exactly " +
+		"that plan. Plan files, as well as --output-format=json, identify each schema and " +
+		"object by a stable resource ID of the form \"instance/schema/type/name\".\n\n" +
+		"With --notify-slack-webhook or --notify-teams-webhook, a summary of the " +
+		"objects changed, failures, and duration is posted to a chat channel once all " +
+		"targets have been processed. These options may be set in environment-specific " +
+		"sections of .skeema files, to notify different channels for each environment.\n\n" +
+		"When operating on multiple database servers or schemas, each target is processed " +
+		"independently, and a failed statement on one target does not prevent others " +
+		"from proceeding. Fatal errors, such as invalid configuration or failure to " +
+		"introspect a schema, prevent any further targets from being started. A summary of which targets succeeded, failed, or were skipped is " +
+		"logged once all targets are complete. With --stop-on-failure, no further targets " +
+		"are started once any target fails; targets already in progress are allowed to " +
+		"complete.\n\n" +
+		"For schema-per-tenant environments, where a dir maps to many identically-defined " +
+		"schemas on each database server, --dedupe-schemas obtains checksums of all schemas' " +
+		"metadata in batches, and only fully introspects one schema per distinct checksum. " +
+		"With --output-format=markdown, targets with identical changes are grouped together.\n\n" +
 		"An exit code of 0 will be returned if the operation was fully successful; 1 if " +
 		"at least one table could not be updated due to use of unsupported features, or if " +
 		"the --dry-run option was used and differences were found; or 2+ if a fatal error " +
@@ -36,16 +61,45 @@ func init() {
 		mybase.BoolOption("compare-metadata", 0, false, "For stored programs, detect changes to creation-time sql_mode or DB collation"),
 		mybase.BoolOption("alter-validate-virtual", 0, false, "Apply a WITH VALIDATION clause to ALTER TABLEs affecting virtual columns"),
 		mybase.BoolOption("lax-column-order", 0, false, "When comparing tables, don't re-order columns if they only differ by position"),
+		mybase.BoolOption("append-columns", 0, false, "Never emit FIRST or AFTER clauses: add new columns at the end of tables, and don't re-order columns"),
 		mybase.BoolOption("lax-comments", 0, false, "When comparing tables or routines, don't modify them if they only differ by comment clauses"),
+		mybase.StringOption("ignore-table-options", 0, "", "Comma-separated table options to ignore differences in, e.g. row_format,key_block_size,stats_persistent,comment"),
+		mybase.BoolOption("staged-charset-conversion", 0, false, "Convert columns to utf8mb4 one ALTER TABLE at a time, warning about index length and row size limits"),
 		mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`),
-		mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy")`),
+		mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy", "auto")`),
+		mybase.StringOption("alter-granularity", 0, "table", `Combine all clauses per table into one ALTER TABLE, or emit one ALTER TABLE per clause (valid values: "table", "clause")`),
 		mybase.StringOption("partitioning", 0, "keep", `Specify handling of partitioning status on the database side (valid values: "keep", "remove", "modify")`),
 	)
 
 	cmd.AddOptions("External tool",
 		mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE; see manual for template vars"),
-		mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore --alter-wrapper for tables smaller than this size in bytes"),
+		mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore --alter-wrapper and --osc-tool for tables smaller than this size in bytes"),
 		mybase.StringOption("ddl-wrapper", 'X', "", "Like --alter-wrapper, but applies to all DDL types (CREATE, DROP, ALTER)"),
+		mybase.StringOption("osc-tool", 0, "none", `Natively run ALTER TABLE via an online schema change tool (valid values: "none", "gh-ost", "pt-osc", "builtin")`),
+		mybase.StringOption("osc-tool-bin", 0, "", "Path to binary for --osc-tool, if not on PATH under its standard name"),
+		mybase.StringOption("osc-tool-options", 0, "", "Additional command-line options to pass to --osc-tool"),
+		mybase.BoolOption("osc-postpone-cut-over", 0, false, "With --osc-tool, postpone table cut-over until a flag file is removed"),
+		mybase.StringOption("osc-chunk-size", 0, "1000", "With --osc-tool=builtin, number of rows to copy per chunk"),
+		mybase.StringOption("osc-max-replica-lag", 0, "10", "With --osc-tool=builtin, pause copying while any --osc-replicas lag exceeds this many seconds"),
+		mybase.StringOption("osc-replicas", 0, "", "With --osc-tool=builtin, comma-separated list of replica host[:port] to monitor for lag, instead of --replicas"),
+	)
+
+	cmd.AddOptions("progress",
+		mybase.BoolOption("alter-progress", 0, false, "Display progress of ALTER TABLE statements run directly by Skeema"),
+		mybase.StringOption("alter-progress-stream", 0, "", `Write ALTER TABLE progress as JSON lines to this file path ("-" for STDOUT)`),
+	)
+
+	cmd.AddOptions("hooks",
+		mybase.StringOption("before-push", 0, "", "Shell command to run before executing statements on each target; non-zero exit skips the target"),
+		mybase.StringOption("after-push", 0, "", "Shell command to run after executing statements on each target"),
+		mybase.StringOption("before-statement", 0, "", "Shell command to run before each statement; non-zero exit halts the target"),
+		mybase.StringOption("after-statement", 0, "", "Shell command to run after each statement"),
+	)
+
+	cmd.AddOptions("notifications",
+		mybase.StringOption("notify-slack-webhook", 0, "", "Upon completion, post a summary of results to this Slack incoming webhook URL"),
+		mybase.StringOption("notify-teams-webhook", 0, "", "Upon completion, post a summary of results to this Microsoft Teams incoming webhook URL"),
+		mybase.StringOption("notify-on", 0, "changes", `When to post notifications (valid values: "changes", "failures", "always")`),
 	)
 
 	cmd.AddOptions("linter rule",
@@ -58,13 +112,63 @@ func init() {
 		mybase.BoolOption("allow-unsafe", 0, false, "Permit running ALTER or DROP operations that are potentially destructive"),
 		mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"),
 		mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"),
+		mybase.StringOption("sql-mode-parity", 0, "warn", `Handling of *.sql statements affected by sql_mode differences between workspace and target (valid values: "ignore", "warn", "error")`),
 		mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"),
+		mybase.StringOption("protect-table", 0, "", "Never permit dropping or destructively altering tables matching this regex, even with --allow-unsafe"),
+		mybase.StringOption("protect-column", 0, "", "Never permit dropping or destructively modifying columns matching this regex, even with --allow-unsafe"),
+		mybase.StringOption("large-table-size", 0, "0", "Classify table rebuilds as lock-heavy for tables at least this size in bytes (0 to disable)"),
+		mybase.StringOption("require-osc-size", 0, "0", "Refuse ALTER TABLE without --alter-wrapper or --osc-tool for tables at least this size in bytes (0 to disable)"),
+		mybase.StringOption("require-osc-rows", 0, "0", "Refuse ALTER TABLE without --alter-wrapper or --osc-tool for tables with at least this many estimated rows (0 to disable)"),
+		mybase.StringOption("max-impact", 0, "", "Refuse statements with impact above this class: metadata-only, online-capable, table-rebuild, or lock-heavy"),
+		mybase.StringOption("data-check", 0, "none", `Scan existing rows before narrowing or NOT NULL column changes (valid values: "none", "warn", "block")`),
+		mybase.StringOption("data-check-rows", 0, "0", "With --data-check, only examine this many rows per table (0 to scan all rows)"),
+		mybase.StringOption("journal-schema", 0, "", "Journal each statement in a table in this schema, so that an interrupted push can be safely re-run"),
+		mybase.StringOption("history-schema", 0, "", "Record each executed statement in a _skeema_history table in this schema, for use with `skeema history`"),
+		mybase.BoolOption("push-lock", 0, false, "Hold an advisory lock on each target schema while executing statements, to prevent concurrent pushes"),
+		mybase.StringOption("push-lock-timeout", 0, "30", "With --push-lock, maximum number of seconds to wait for another push to release its lock"),
+		mybase.StringOption("replicas", 0, "", `Comma-separated list of replica host[:port] to monitor for lag, or "auto" to discover via SHOW REPLICAS`),
+		mybase.StringOption("max-replica-lag", 0, "0", "Pause before each statement while any --replicas lag exceeds this many seconds (0 to disable)"),
+		mybase.StringOption("retry-attempts", 0, "0", "Retry statements failing due to lock wait timeouts or deadlocks up to this many times"),
+		mybase.StringOption("retry-backoff", 0, "1s", "With --retry-attempts, initial wait before retrying, doubling after each retry"),
+		mybase.StringOption("mdl-report-after", 0, "0", "Report sessions blocking a statement waiting on a metadata lock for this long (0 to disable)"),
+		mybase.StringOption("mdl-kill-idle", 0, "0", "Kill sessions blocking a statement's metadata lock if idle for at least this long (0 to disable)"),
+		mybase.StringOption("mdl-kill-users", 0, "", "With --mdl-kill-idle, only kill blocking sessions from this comma-separated list of users"),
+		mybase.StringOption("init-sql", 0, "", "SQL statements to run on each connection before executing DDL, e.g. to set lock_wait_timeout"),
+		mybase.StringOption("statement-timeout", 0, "0", "Kill any DDL run directly by Skeema which takes longer than this duration (0 for no limit)"),
+		mybase.StringOption("wsrep-osu-method", 0, "", `For Galera clusters, set wsrep_OSU_method for DDL (valid values: "TOI", "RSU")`),
+		mybase.StringOption("wsrep-max-recv-queue", 0, "0", "For Galera clusters, pause before each statement while wsrep_local_recv_queue exceeds this length (0 to disable)"),
+		mybase.BoolOption("discover-primary", 0, false, "For group replication members, operate on the group's current primary instead; implies --verify-writable"),
+		mybase.BoolOption("verify-writable", 0, false, "Refuse to execute statements on servers with read_only or super_read_only enabled"),
+		mybase.BoolOption("verify-replicas", 0, false, "After pushing, confirm the schema on each of --replicas matches the desired schema"),
+		mybase.StringOption("verify-replicas-timeout", 0, "30s", "With --verify-replicas, maximum time to wait for replicas to apply the changes"),
+		mybase.StringOption("plan", 0, "", "Only execute statements exactly matching this plan file from `skeema diff --save-plan`"),
+		mybase.StringOption("save-plan", 0, "", "<overridden by diff command>").Hidden(),
+		mybase.StringOption("save-rollback", 0, "", "Write a SQL script to this file which reverts the executed statements, where possible"),
+		mybase.StringOption("report", 0, "", "Write an HTML report to this file, comparing each changed object's CREATE statement before and after"),
+		mybase.StringOption("state-file", 0, "", "Record push progress to this file, so that an interrupted push can be continued with --resume"),
+		mybase.BoolOption("resume", 0, false, "Continue an interrupted push from the progress recorded in --state-file"),
+		mybase.BoolOption("interactive", 0, false, "Prompt for approval before pushing each target and executing each statement"),
 	)
 
 	cmd.AddOptions("sharding",
 		mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple hosts or schemas, only run against the first target per dir"),
 		mybase.BoolOption("brief", 'q', false, "<overridden by diff command>").Hidden(),
+		mybase.BoolOption("explain", 0, false, "<overridden by diff command>").Hidden(),
+		mybase.StringOption("output-format", 0, "sql", "Format of STDOUT output: \"sql\"; \"json\" for a document describing each target's statements and results; or \"markdown\" for a summary of all targets"),
+		mybase.StringOption("create-diff", 0, "", `Before each ALTER TABLE, also output a line diff of the CREATE TABLE statements (valid values: "unified", "side-by-side")`),
+		mybase.BoolOption("annotate-source", 0, false, "Before each statement, also output the file and line range defining the object"),
+		mybase.BoolOption("table-stats", 0, false, "Also output the size and estimated row count of each altered or dropped table"),
+		mybase.StringOption("metrics-pushgateway", 0, "", "Upon completion, send Prometheus metrics about each target to the Pushgateway at this URL"),
+		mybase.StringOption("metrics-job", 0, "skeema", "With --metrics-pushgateway, job name to group the metrics under"),
 		mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of database servers concurrently"),
+		mybase.StringOption("concurrent-per-instance", 0, "1", "Perform operations on this number of schemas concurrently on each database server"),
+		mybase.StringOption("concurrent-per-cluster", 0, "0", "Limit concurrent operations on schemas sharing the same --cluster name (0 for no limit)"),
+		mybase.StringOption("concurrent-total", 0, "0", "Limit concurrent operations on schemas across all database servers (0 for no limit)"),
+		mybase.StringOption("cluster", 0, "", "Name of the cluster containing this dir's host, for use with --concurrent-per-cluster"),
+		mybase.BoolOption("stop-on-failure", 0, false, "Don't start any further targets once any target fails"),
+		mybase.BoolOption("dedupe-schemas", 0, false, "For dirs mapping to many schemas per host, only introspect one schema per distinct definition"),
+		mybase.StringOption("canary", 0, "0", "Push to this number (or percentage, with % suffix) of targets first, before the rest"),
+		mybase.StringOption("canary-soak", 0, "0", "With --canary, wait this duration (e.g. \"5m\") after the canary push, instead of prompting for confirmation"),
 	)
 
 	workspace.AddCommandOptions(cmd)
@@ -79,8 +183,11 @@ func PushHandler(cfg *mybase.Config) error {
 	// * --brief only affects `skeema diff` (aka `skeema push --dry-run`)
 	// * --brief automatically uses --skip-verify --skip-lint --allow-unsafe
 	// * --brief omits INFO-level logging, unless --debug was used
+	// --explain is likewise only available in `skeema diff`.
 	if !cfg.GetBool("dry-run") {
 		cfg.SetRuntimeOverride("brief", "0")
+		cfg.SetRuntimeOverride("explain", "0")
+		cfg.SetRuntimeOverride("save-plan", "")
 	} else if cfg.GetBool("brief") {
 		cfg.SetRuntimeOverride("verify", "0")
 		cfg.SetRuntimeOverride("lint", "0")
@@ -95,46 +202,156 @@ func PushHandler(cfg *mybase.Config) error {
 		return err
 	}
 
-	concurrency, err := dir.Config.GetInt("concurrent-instances")
+	// With --state-file, refuse to clobber progress of an interrupted push unless
+	// --resume is used. The state file is removed upon successful completion.
+	if statePath := dir.Config.Get("state-file"); statePath != "" && !dir.Config.GetBool("dry-run") {
+		_, statErr := os.Stat(statePath)
+		if dir.Config.GetBool("resume") && statErr != nil {
+			return NewExitValue(CodeNoInput, "Unable to resume: %s", statErr)
+		} else if !dir.Config.GetBool("resume") && statErr == nil {
+			return NewExitValue(CodeBadUsage, "State file %s exists from an interrupted push. Use --resume to continue that push, or delete the file to start over.", statePath)
+		}
+		defer applier.CloseCheckpoint(statePath, false)
+	} else if dir.Config.GetBool("resume") && !dir.Config.GetBool("dry-run") {
+		return NewExitValue(CodeBadConfig, "Option --resume requires --state-file to also be set")
+	}
+
+	if _, err := dir.Config.GetEnum("output-format", "sql", "json", "markdown"); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if _, err := dir.Config.GetEnum("create-diff", "unified", "side-by-side"); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if _, err := dir.Config.GetEnum("notify-on", "changes", "failures", "always"); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	limits, err := applier.ConcurrencyLimitsForDir(dir)
 	if err != nil {
 		return WrapExitCode(CodeBadConfig, err)
-	} else if concurrency < 1 {
-		return NewExitValue(CodeBadConfig, "concurrent-instances cannot be less than 1")
 	}
-	printer := applier.NewPrinter(dir.Config)
+	basePrinter := applier.NewPrinter(dir.Config)
+	printer := basePrinter
+	if gatewayURL := dir.Config.Get("metrics-pushgateway"); gatewayURL != "" {
+		metricsPrinter := applier.NewMetricsPrinter(printer)
+		printer = metricsPrinter
+		defer func() {
+			if err := metricsPrinter.Metrics.PushToGateway(gatewayURL, dir.Config.Get("metrics-job")); err != nil {
+				log.Warnf("Unable to send metrics to %s: %s", gatewayURL, err)
+			}
+		}()
+	}
+	notifyPrinter := applier.NewNotifyPrinter(printer)
+	printer = notifyPrinter
+	defer func() {
+		if err := notifyPrinter.Notify(dir.Config.Get("environment")); err != nil {
+			log.Warn(err)
+		}
+	}()
+	var recorder *applier.RecordingPrinter
+	if dir.Config.Get("save-plan") != "" || dir.Config.Get("save-rollback") != "" || dir.Config.Get("report") != "" {
+		recorder = applier.NewRecordingPrinter(printer)
+		printer = recorder
+	}
+
+	// With --interactive, prompt before each target and statement. Targets are
+	// processed one at a time, so that prompts are not interleaved.
+	if dir.Config.GetBool("interactive") && !dir.Config.GetBool("dry-run") {
+		if !util.StdinIsTerminal() {
+			return NewExitValue(CodeBadUsage, "Option --interactive requires STDIN to be a terminal")
+		}
+		limits = applier.ConcurrencyLimits{Instances: 1, PerInstance: 1}
+		printer = applier.NewInteractivePrinter(printer, os.Stdin, os.Stderr)
+	}
 
-	g, ctx := errgroup.WithContext(context.Background())
-	g.SetLimit(concurrency)
 	groups, skipCount := applier.TargetGroupsForDir(dir)
 	sum := applier.Result{SkipCount: skipCount}
-	var sumLock sync.Mutex
+	summary := applier.NewRunSummary()
+	stopOnFailure := dir.Config.GetBool("stop-on-failure")
+	if dir.Config.GetBool("dry-run") {
+		defer summary.Log("diff")
+	} else {
+		defer summary.Log("push")
+	}
 
-	for n := range groups {
-		tg := groups[n] // avoid loop iteration variable in closure below
-		g.Go(func() error {
-			defer panicHandler()
-			for _, t := range tg {
-				select {
-				case <-ctx.Done():
-					return nil // Exit early if context cancelled
-				default:
-					result, err := applier.ApplyTarget(t, printer)
-					if err != nil {
-						return err
-					}
-					sumLock.Lock()
-					sum.Merge(result)
-					sumLock.Unlock()
-				}
+	// With --canary, first push to a subset of targets, and then only continue
+	// with the rest if the canary targets succeeded and the soak time or
+	// confirmation passed
+	if canarySpec := dir.Config.Get("canary"); canarySpec != "" && canarySpec != "0" && !dir.Config.GetBool("dry-run") {
+		canaryGroups, restGroups, err := splitCanaryTargets(groups, canarySpec)
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		soak, err := time.ParseDuration(dir.Config.Get("canary-soak"))
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		} else if soak == 0 && len(restGroups) > 0 && !util.StdinIsTerminal() {
+			return NewExitValue(CodeBadConfig, "With --canary, --canary-soak must be set if STDIN is not a terminal")
+		}
+		log.Infof("Pushing to %s first", countAndNoun(countTargets(canaryGroups), "canary target", "canary targets"))
+		canaryResult, err := applyTargetGroups(canaryGroups, printer, limits, summary, stopOnFailure)
+		sum.Merge(canaryResult)
+		if err != nil || canaryResult.SkipCount > 0 {
+			summary.RecordSkipped(flattenTargetGroups(restGroups), "canary push failed")
+		}
+		if err != nil {
+			return err
+		} else if canaryResult.SkipCount > 0 {
+			return NewExitValue(CodeFatalError, "Canary push failed; halting without pushing to remaining %s. %s", countAndNoun(countTargets(restGroups), "target", "targets"), canaryResult.Error())
+		}
+		if len(restGroups) > 0 {
+			if soak > 0 {
+				log.Infof("Canary push complete; soaking for %s before pushing to remaining %s", soak, countAndNoun(countTargets(restGroups), "target", "targets"))
+				time.Sleep(soak)
+			} else if ok, err := util.PromptConfirm("Canary push complete. Continue pushing to remaining %s?", countAndNoun(countTargets(restGroups), "target", "targets")); err != nil {
+				return WrapExitCode(CodeFatalError, err)
+			} else if !ok {
+				summary.RecordSkipped(flattenTargetGroups(restGroups), "halted after canary push")
+				return NewExitValue(CodeFatalError, "Halting after canary push; remaining %s not pushed", countAndNoun(countTargets(restGroups), "target", "targets"))
 			}
-			return nil
-		})
+		}
+		groups = restGroups
 	}
 
-	if err := g.Wait(); err != nil {
+	result, err := applyTargetGroups(groups, printer, limits, summary, stopOnFailure)
+	if err != nil {
 		return err
-	} else if sum.SkipCount > 0 {
+	}
+	sum.Merge(result)
+	if summarizer, ok := basePrinter.(applier.Summarizer); ok {
+		if err := summarizer.Summarize(); err != nil {
+			return err
+		}
+	}
+
+	if planPath := dir.Config.Get("save-plan"); planPath != "" {
+		if err := recorder.PlanFile().Write(planPath); err != nil {
+			return WrapExitCode(CodeCantCreate, err)
+		}
+		log.Infof("Wrote plan file %s", planPath)
+	}
+	if rollbackPath := dir.Config.Get("save-rollback"); rollbackPath != "" {
+		if err := applier.WriteRollbackScript(rollbackPath, recorder.Plans()); err != nil {
+			return WrapExitCode(CodeCantCreate, err)
+		}
+		if sum.SkipCount > 0 && !dir.Config.GetBool("dry-run") {
+			log.Warnf("Wrote rollback script %s, but some statements were skipped or failed; review the script carefully before using it", rollbackPath)
+		} else {
+			log.Infof("Wrote rollback script %s", rollbackPath)
+		}
+	}
+	if reportPath := dir.Config.Get("report"); reportPath != "" {
+		if err := applier.WriteHTMLReport(reportPath, recorder.Plans()); err != nil {
+			return WrapExitCode(CodeCantCreate, err)
+		}
+		log.Infof("Wrote HTML report %s", reportPath)
+	}
+	if statePath := dir.Config.Get("state-file"); statePath != "" && sum.SkipCount == 0 && !dir.Config.GetBool("dry-run") {
+		if err := applier.CloseCheckpoint(statePath, true); err != nil {
+			log.Warnf("Unable to remove state file %s: %s", statePath, err)
+		}
+	}
+	if sum.SkipCount > 0 {
 		return sum.Error()
+	} else if sum.ReplicaMismatchCount > 0 {
+		return NewExitValue(CodePartialError, "Push complete, but %s did not match the desired schema", countAndNoun(sum.ReplicaMismatchCount, "replica", "replicas"))
 	} else if sum.UnsupportedCount > 0 {
 		return WrapExitCode(CodePartialError, sum.Error())
 	} else if dir.Config.GetBool("dry-run") && sum.Differences {
@@ -142,3 +359,87 @@ func PushHandler(cfg *mybase.Config) error {
 	}
 	return nil
 }
+
+// applyTargetGroups applies each target in groups, subject to the supplied
+// concurrency limits. Each target's outcome is tracked in summary, which may be
+// nil. If stopOnFailure is true, no further targets are started once any
+// target has an error or skipped operations; targets which were never started
+// are counted in the returned result's SkipCount.
+func applyTargetGroups(groups []applier.TargetGroup, printer applier.Printer, limits applier.ConcurrencyLimits, summary *applier.RunSummary, stopOnFailure bool) (applier.Result, error) {
+	var sum applier.Result
+	var sumLock sync.Mutex
+	scheduler := applier.NewScheduler(limits)
+	err := scheduler.Run(groups, func(t *applier.Target) error {
+		defer panicHandler()
+		result, err := applier.ApplyTarget(t, printer)
+		sumLock.Lock()
+		sum.Merge(result)
+		sumLock.Unlock()
+		summary.Record(t, result, err)
+		if stopOnFailure && (err != nil || result.SkipCount > 0) {
+			scheduler.Stop()
+		}
+		return err
+	})
+	if unstarted := scheduler.Unstarted(); len(unstarted) > 0 {
+		reason := "--stop-on-failure"
+		if err != nil {
+			reason = "a fatal error"
+		}
+		log.Warnf("Not starting remaining %s due to %s", countAndNoun(len(unstarted), "target", "targets"), reason)
+		summary.RecordSkipped(unstarted, "not started due to "+strings.TrimPrefix(reason, "--"))
+		sum.SkipCount += len(unstarted)
+	}
+	return sum, err
+}
+
+// flattenTargetGroups returns all targets in groups, in order.
+func flattenTargetGroups(groups []applier.TargetGroup) []*applier.Target {
+	var targets []*applier.Target
+	for _, tg := range groups {
+		targets = append(targets, tg...)
+	}
+	return targets
+}
+
+// splitCanaryTargets splits groups into canary targets and remaining targets.
+// The spec may be a number of targets, or a percentage of targets suffixed with
+// "%", in which case the number is rounded up. Targets are ordered by instance
+// for determinism, retaining each instance's schema order.
+func splitCanaryTargets(groups []applier.TargetGroup, spec string) (canary, rest []applier.TargetGroup, err error) {
+	sorted := slices.Clone(groups)
+	slices.SortFunc(sorted, func(a, b applier.TargetGroup) int {
+		return strings.Compare(a[0].Instance.String(), b[0].Instance.String())
+	})
+	total := countTargets(sorted)
+	var n int
+	if pct, isPct := strings.CutSuffix(spec, "%"); isPct {
+		p, err := strconv.ParseFloat(pct, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, nil, fmt.Errorf("Option canary has invalid percentage %q", spec)
+		}
+		n = int(math.Ceil(float64(total) * p / 100))
+	} else if n, err = strconv.Atoi(spec); err != nil || n < 1 {
+		return nil, nil, fmt.Errorf("Option canary must be a positive number of targets or a percentage, not %q", spec)
+	}
+	for _, tg := range sorted {
+		if n >= len(tg) {
+			canary = append(canary, tg)
+		} else if n > 0 {
+			canary = append(canary, tg[:n])
+			rest = append(rest, tg[n:])
+		} else {
+			rest = append(rest, tg)
+		}
+		n = max(n-len(tg), 0)
+	}
+	return canary, rest, nil
+}
+
+// countTargets returns the total number of targets in groups.
+func countTargets(groups []applier.TargetGroup) (count int) {
+	for _, tg := range groups {
+		count += len(tg)
+	}
+	return count
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestSplitCanaryTargets(t *testing.T) {
+	// Two instances with 3 schemas each, supplied in reverse order of instance
+	var groups []applier.TargetGroup
+	for _, port := range []int{3307, 3306} {
+		inst, err := tengo.NewInstance("mysql", fmt.Sprintf("root:@tcp(127.0.0.1:%d)/", port))
+		if err != nil {
+			t.Fatalf("Unexpected error from NewInstance: %v", err)
+		}
+		var tg applier.TargetGroup
+		for _, schemaName := range []string{"shard1", "shard2", "shard3"} {
+			tg = append(tg, &applier.Target{Instance: inst, SchemaName: schemaName})
+		}
+		groups = append(groups, tg)
+	}
+
+	cases := []struct {
+		spec         string
+		expectCanary []string
+	}{
+		{"1", []string{"127.0.0.1:3306 shard1"}},
+		{"4", []string{"127.0.0.1:3306 shard1", "127.0.0.1:3306 shard2", "127.0.0.1:3306 shard3", "127.0.0.1:3307 shard1"}},
+		{"10%", []string{"127.0.0.1:3306 shard1"}},
+		{"50%", []string{"127.0.0.1:3306 shard1", "127.0.0.1:3306 shard2", "127.0.0.1:3306 shard3"}},
+	}
+	for _, c := range cases {
+		canary, rest, err := splitCanaryTargets(groups, c.spec)
+		if err != nil {
+			t.Errorf("Unexpected error from splitCanaryTargets(%q): %v", c.spec, err)
+			continue
+		}
+		var actual []string
+		for _, tg := range canary {
+			for _, target := range tg {
+				actual = append(actual, target.String())
+			}
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(c.expectCanary) {
+			t.Errorf("Unexpected canary targets for %q: expected %v, found %v", c.spec, c.expectCanary, actual)
+		}
+		if countTargets(rest) != 6-len(c.expectCanary) {
+			t.Errorf("Unexpected number of remaining targets for %q: %d", c.spec, countTargets(rest))
+		}
+	}
+
+	for _, spec := range []string{"-1", "abc", "0%", "150%"} {
+		if _, _, err := splitCanaryTargets(groups, spec); err == nil {
+			t.Errorf("Expected error from splitCanaryTargets(%q), but it was nil", spec)
+		}
+	}
+}
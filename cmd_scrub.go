@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Write a sanitized copy of the schemas to a new directory"
+	desc := "Writes a copy of the schemas defined in this directory and its subdirectories " +
+		"to a new directory, with potentially-sensitive metadata scrubbed. The result is " +
+		"suitable for sharing with vendors, or attaching to a bug report.\n\n" +
+		"By default, all table, column, index, partition, and routine comments are " +
+		"removed. With --redact-pattern, comments are kept, but any portion of a comment " +
+		"matching the regular expression is replaced with REDACTED. Use " +
+		"--skip-strip-comments to keep comments unchanged.\n\n" +
+		"The DEFINER of stored procedures and functions is removed, or replaced with " +
+		"the user@host value of --definer if supplied. Schema names may be mapped to " +
+		"new names using --schema-map, for example --schema-map=\"prod_main=app,prod_logs=logs\". " +
+		"Mapped names are also applied to cross-schema foreign keys, as well as to " +
+		"schema-qualified references within routine bodies.\n\n" +
+		"The output directory must be new or empty, and must not be located within the " +
+		"current directory. Its option files only contain each schema's name and " +
+		"default character set and collation; no host or connection information is " +
+		"ever written.\n\n" +
+		"As with `skeema graph`, the *.sql files are converted into schemas using a " +
+		"workspace, unless --live is used to read the schemas from the database server " +
+		"instead. workspace=parse is not supported.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("scrub", summary, desc, ScrubHandler)
+	cmd.AddOption(mybase.StringOption("output-dir", 0, "", "Directory to write the sanitized copy to (required)"))
+	cmd.AddOption(mybase.BoolOption("strip-comments", 0, true, "Remove comments on tables, columns, indexes, partitions, and routines"))
+	cmd.AddOption(mybase.StringOption("redact-pattern", 0, "", "Keep comments, but replace any portion matching this regex with REDACTED"))
+	cmd.AddOption(mybase.StringOption("definer", 0, "", "Replace routine DEFINERs with this user@host, instead of removing them"))
+	cmd.AddOption(mybase.StringOption("schema-map", 0, "", "Comma-separated list of old=new schema name mappings"))
+	cmd.AddOption(mybase.BoolOption("live", 0, false, "Scrub the schemas on the database server, rather than the *.sql files"))
+	cmd.AddOption(mybase.BoolOption("include-auto-inc", 0, false, "Include starting auto-inc values in table files"))
+	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Omit PARTITION BY clause when writing partitioned tables to filesystem"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// ScrubHandler is the handler method for `skeema scrub`
+func ScrubHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	outputDir := dir.Config.Get("output-dir")
+	if outputDir == "" {
+		return NewExitValue(CodeBadConfig, "Option --output-dir is required")
+	} else if absOutputDir, err := filepath.Abs(outputDir); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if absOutputDir == dir.Path || strings.HasPrefix(absOutputDir, dir.Path+string(filepath.Separator)) {
+		return NewExitValue(CodeBadConfig, "Option --output-dir must not be located within %s", dir)
+	}
+	if !dir.Config.GetBool("live") {
+		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker", "kubernetes", "parse"); wsType == "parse" {
+			return WrapExitCode(CodeBadConfig, workspace.ErrParseWorkspace)
+		}
+	}
+	opts, err := scrubOptionsForDir(dir)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+
+	schemas, skipCount := schemaWalker(dir, 5)
+	if len(schemas) == 0 && skipCount == 0 {
+		return NewExitValue(CodeBadConfig, "No schemas found in %s or its subdirectories", dir)
+	}
+	outDir, err := createInitDir(cfg, outputDir)
+	if err != nil {
+		return err
+	}
+	flavor := scrubFlavor(dir)
+	if flavor.Known() {
+		optionFile := mybase.NewFile(outDir.Path, ".skeema")
+		optionFile.SetOptionValue("", "flavor", flavor.Family().String())
+		if err := outDir.CreateOptionFile(optionFile); err != nil {
+			return NewExitValue(CodeCantCreate, "Unable to create option file in %s: %v", outDir, err)
+		}
+	}
+
+	seen := make(map[string]bool, len(schemas))
+	for _, s := range schemas {
+		for _, key := range scrubSchema(s, flavor, opts) {
+			log.Warnf("Omitting %s from schema %s: uses features which cannot be scrubbed reliably", key, s.Name)
+		}
+		if seen[s.Name] {
+			log.Warnf("Skipping duplicate schema name %s", s.Name)
+			skipCount++
+			continue
+		}
+		seen[s.Name] = true
+		if err := PopulateSchemaDir(s, outDir, true); err != nil {
+			return err
+		}
+	}
+	if skipCount > 0 {
+		return NewExitValue(CodeFatalError, "Skipped %s due to errors", countAndNoun(skipCount, "schema", "schemas"))
+	}
+	return nil
+}
+
+// scrubOptions controls which metadata is altered by scrubSchema.
+type scrubOptions struct {
+	StripComments bool
+	RedactPattern *regexp.Regexp
+	Definer       tengo.Definer
+	SchemaMap     map[string]string
+}
+
+// scrubOptionsForDir returns the scrubOptions configured for dir, or an error
+// if any option value is invalid.
+func scrubOptionsForDir(dir *fs.Dir) (opts scrubOptions, err error) {
+	opts.StripComments = dir.Config.GetBool("strip-comments")
+	if opts.RedactPattern, err = dir.Config.GetRegexp("redact-pattern"); err != nil {
+		return opts, err
+	}
+	opts.Definer = tengo.Definer(dir.Config.Get("definer"))
+	if opts.Definer != "" && !strings.Contains(string(opts.Definer), "@") {
+		return opts, fmt.Errorf("Option --definer must be in the form user@host, instead found %q", opts.Definer)
+	}
+	opts.SchemaMap = make(map[string]string)
+	for _, mapping := range dir.Config.GetSlice("schema-map", ',', true) {
+		from, to, ok := strings.Cut(mapping, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return opts, fmt.Errorf("Option --schema-map must contain old=new pairs, instead found %q", mapping)
+		}
+		opts.SchemaMap[from] = to
+	}
+	return opts, nil
+}
+
+// scrubFlavor returns the flavor used for regenerating scrubbed CREATE
+// statements for dir. This is the flavor of dir's first instance if available,
+// or otherwise the configured flavor option.
+func scrubFlavor(dir *fs.Dir) tengo.Flavor {
+	if inst, _ := dir.FirstInstance(); inst != nil && inst.Flavor().Known() {
+		return inst.Flavor()
+	}
+	return tengo.ParseFlavor(dir.Config.Get("flavor"))
+}
+
+// scrubSchema modifies s in-place as specified by opts, regenerating the CREATE
+// statement of each altered object. Tables whose CREATE cannot be regenerated
+// accurately are removed from s, since their metadata cannot be scrubbed; the
+// keys of any removed tables are returned.
+func scrubSchema(s *tengo.Schema, flavor tengo.Flavor, opts scrubOptions) (omitted []tengo.ObjectKey) {
+	if newName, ok := opts.SchemaMap[s.Name]; ok {
+		s.Name = newName
+	}
+
+	tables := make([]*tengo.Table, 0, len(s.Tables))
+	for _, t := range s.Tables {
+		if t.UnsupportedDDL || t.GeneratedCreateStatement(flavor) != t.CreateStatement {
+			omitted = append(omitted, t.ObjectKey())
+			continue
+		}
+		t.Comment = opts.scrubComment(t.Comment)
+		for _, col := range t.Columns {
+			col.Comment = opts.scrubComment(col.Comment)
+		}
+		if t.PrimaryKey != nil {
+			t.PrimaryKey.Comment = opts.scrubComment(t.PrimaryKey.Comment)
+		}
+		for _, idx := range t.SecondaryIndexes {
+			idx.Comment = opts.scrubComment(idx.Comment)
+		}
+		if t.Partitioning != nil {
+			for _, p := range t.Partitioning.Partitions {
+				p.Comment = opts.scrubComment(p.Comment)
+			}
+		}
+		for _, fk := range t.ForeignKeys {
+			if newName, ok := opts.SchemaMap[fk.ReferencedSchemaName]; ok {
+				fk.ReferencedSchemaName = newName
+			}
+		}
+		t.CreateStatement = t.GeneratedCreateStatement(flavor)
+		tables = append(tables, t)
+	}
+	s.Tables = tables
+
+	for _, r := range s.Routines {
+		r.Comment = opts.scrubComment(r.Comment)
+		if r.Definer != "" {
+			r.Definer = opts.Definer
+		}
+		r.Body = opts.mapSchemaQualifiers(r.Body)
+		r.CreateStatement = r.Definition(flavor)
+	}
+	return omitted
+}
+
+// scrubComment returns the scrubbed form of comment.
+func (opts scrubOptions) scrubComment(comment string) string {
+	if opts.RedactPattern != nil {
+		return opts.RedactPattern.ReplaceAllLiteralString(comment, "REDACTED")
+	} else if opts.StripComments {
+		return ""
+	}
+	return comment
+}
+
+var unquotedIdentifierRegexp = regexp.MustCompile(`^[0-9a-zA-Z_$]*[a-zA-Z_$][0-9a-zA-Z_$]*$`)
+
+// mapSchemaQualifiers replaces schema-qualified object references in body,
+// for example `prod_main`.`orders` or prod_main.orders, with references using
+// the mapped schema names from opts.SchemaMap.
+func (opts scrubOptions) mapSchemaQualifiers(body string) string {
+	for from, to := range opts.SchemaMap {
+		re := regexp.MustCompile("(`" + regexp.QuoteMeta(strings.ReplaceAll(from, "`", "``")) + "`|\\b" + regexp.QuoteMeta(from) + ")\\.")
+		body = re.ReplaceAllStringFunc(body, func(match string) string {
+			if match[0] != '`' && unquotedIdentifierRegexp.MatchString(to) {
+				return to + "."
+			}
+			return tengo.EscapeIdentifier(to) + "."
+		})
+	}
+	return body
+}
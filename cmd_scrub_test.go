@@ -0,0 +1,97 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestScrubSchema(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.32")
+	create := "CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL COMMENT 'Contact jane@example.com',\n" +
+		"  `customer_id` int NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `customer` (`customer_id`) COMMENT 'Requested by jane@example.com',\n" +
+		"  CONSTRAINT `orders_cust` FOREIGN KEY (`customer_id`) REFERENCES `prod_crm`.`customers` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='Owned by jane@example.com'"
+	newSchema := func() *tengo.Schema {
+		table, err := tengo.ParseCreateTable(create, flavor, "utf8mb4", "utf8mb4_0900_ai_ci")
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+		}
+		routine := &tengo.Routine{
+			Name:           "order_count",
+			Type:           tengo.ObjectTypeFunc,
+			Body:           "RETURN (SELECT COUNT(*) FROM prod_main.orders o JOIN `prod_crm`.customers c ON c.id = o.customer_id)",
+			ReturnDataType: "int",
+			Definer:        "admin@10.0.0.%",
+			Comment:        "Written by jane@example.com",
+			SQLDataAccess:  "READS SQL DATA",
+			SecurityType:   "DEFINER",
+		}
+		routine.CreateStatement = routine.Definition(flavor)
+		return &tengo.Schema{
+			Name:     "prod_main",
+			Tables:   []*tengo.Table{table},
+			Routines: []*tengo.Routine{routine},
+		}
+	}
+
+	// Default options: comments and definers stripped
+	s := newSchema()
+	if omitted := scrubSchema(s, flavor, scrubOptions{StripComments: true}); len(omitted) > 0 {
+		t.Fatalf("Unexpected omitted objects: %v", omitted)
+	}
+	if strings.Contains(s.Tables[0].CreateStatement, "COMMENT") || strings.Contains(s.Routines[0].CreateStatement, "COMMENT") {
+		t.Errorf("Expected comments to be stripped, but they were not:\n%s\n%s", s.Tables[0].CreateStatement, s.Routines[0].CreateStatement)
+	}
+	if strings.Contains(s.Routines[0].CreateStatement, "DEFINER") {
+		t.Errorf("Expected definer to be stripped, but it was not:\n%s", s.Routines[0].CreateStatement)
+	}
+	if s.Name != "prod_main" || !strings.Contains(s.Tables[0].CreateStatement, "REFERENCES `prod_crm`.`customers`") {
+		t.Errorf("Expected schema names to be unchanged, but they were not:\n%s", s.Tables[0].CreateStatement)
+	}
+
+	// Redaction, definer normalization, and schema name mapping
+	s = newSchema()
+	opts := scrubOptions{
+		StripComments: true,
+		RedactPattern: regexp.MustCompile(`\w+@example\.com`),
+		Definer:       "root@localhost",
+		SchemaMap:     map[string]string{"prod_main": "app", "prod_crm": "crm-data"},
+	}
+	scrubSchema(s, flavor, opts)
+	table, routine := s.Tables[0], s.Routines[0]
+	if s.Name != "app" {
+		t.Errorf("Expected schema name to be mapped, instead found %q", s.Name)
+	}
+	if table.Comment != "Owned by REDACTED" || table.Columns[0].Comment != "Contact REDACTED" || table.SecondaryIndexes[0].Comment != "Requested by REDACTED" {
+		t.Errorf("Unexpected table comments after redaction:\n%s", table.CreateStatement)
+	}
+	if !strings.Contains(table.CreateStatement, "REFERENCES `crm-data`.`customers`") {
+		t.Errorf("Expected foreign key schema name to be mapped, but it was not:\n%s", table.CreateStatement)
+	}
+	if routine.Comment != "Written by REDACTED" || routine.Definer != "root@localhost" || !strings.Contains(routine.CreateStatement, "CREATE DEFINER=`root`@`localhost` FUNCTION") {
+		t.Errorf("Unexpected routine after scrubbing:\n%s", routine.CreateStatement)
+	}
+	if expected := "RETURN (SELECT COUNT(*) FROM app.orders o JOIN `crm-data`.customers c ON c.id = o.customer_id)"; routine.Body != expected {
+		t.Errorf("Unexpected routine body after schema mapping: %s", routine.Body)
+	}
+
+	// Keeping comments
+	s = newSchema()
+	scrubSchema(s, flavor, scrubOptions{})
+	if s.Tables[0].Comment != "Owned by jane@example.com" || s.Tables[0].CreateStatement != create {
+		t.Errorf("Expected table to be unchanged, instead found:\n%s", s.Tables[0].CreateStatement)
+	}
+
+	// Tables which cannot be regenerated accurately are omitted
+	s = newSchema()
+	s.Tables[0].UnsupportedDDL = true
+	if omitted := scrubSchema(s, flavor, scrubOptions{StripComments: true}); len(omitted) != 1 || len(s.Tables) != 0 {
+		t.Errorf("Expected unsupported table to be omitted; instead omitted=%v, tables=%v", omitted, s.Tables)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Run an HTTP API server for diff, lint, and push operations"
+	desc := "Runs a long-lived HTTP server, exposing `skeema diff`, `skeema lint`, and " +
+		"`skeema push` as API operations. This permits platform teams to offer schema " +
+		"changes as a service, without clients needing to install or shell out to Skeema.\n\n" +
+		"Each request operates on a fresh checkout of the git repository configured by " +
+		"--repo, at the git ref supplied in the request, so concurrent requests never " +
+		"interfere with one another. Requests are POSTed as JSON to /v1/diff, /v1/lint, " +
+		"or /v1/push, with the following optional fields: \"ref\" (default \"HEAD\"), " +
+		"\"dir\" (subdirectory of the repository to operate on, default the top-level " +
+		"dir), \"environment\" (default \"production\"), and \"allow_unsafe\" (push only). " +
+		"The response is a JSON document containing the command's exit code, log output, " +
+		"and, for diff and push, a report of each target in the same format as " +
+		"--output-format=json. A GET to /v1/health may be used for health checks, and " +
+		"a GET to /v1/metrics returns Prometheus metrics about the targets of all diff " +
+		"and push operations.\n\n" +
+		"All requests other than health checks must supply the value of --api-token in " +
+		"an \"Authorization: Bearer\" header. To avoid exposing the token in process " +
+		"listings, configure it in an option file, where it may also reference an " +
+		"environment variable, for example api-token=$SKEEMA_API_TOKEN. " +
+		"Requests are served over HTTPS if --tls-cert and --tls-key are both supplied.\n\n" +
+		"Since .skeema files may configure options which execute external commands, " +
+		"such as host-wrapper or alter-wrapper, requests may only supply refs matching " +
+		"--allowed-refs, a comma-separated list of refs or glob patterns (default " +
+		"\"HEAD\"). Only list refs whose contents are reviewed before being committed, " +
+		"such as protected branches.\n\n" +
+		"Operations run as a child process of this command, using the global option " +
+		"files and environment variables of the server, which is where database " +
+		"credentials should be configured. Only the options in the repository's " +
+		".skeema files and the request fields listed above are otherwise applied."
+
+	cmd := mybase.NewCommand("serve", summary, desc, ServeHandler)
+	cmd.AddOption(mybase.StringOption("listen", 0, "127.0.0.1:8080", "Address to listen for HTTP requests on"))
+	cmd.AddOption(mybase.StringOption("repo", 0, "", "URL or path of the git repository to check out for each request (required)"))
+	cmd.AddOption(mybase.StringOption("api-token", 0, "", "Bearer token which clients must supply in requests (required)"))
+	cmd.AddOption(mybase.StringOption("tls-cert", 0, "", "Path to TLS certificate file, for serving HTTPS"))
+	cmd.AddOption(mybase.StringOption("tls-key", 0, "", "Path to TLS private key file, for serving HTTPS"))
+	cmd.AddOption(mybase.StringOption("request-timeout", 0, "1h", "Maximum duration of each operation, e.g. \"30m\""))
+	cmd.AddOption(mybase.StringOption("allowed-refs", 0, "HEAD", "Comma-separated list of git refs or glob patterns which requests may supply"))
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// ServeHandler is the handler method for `skeema serve`
+func ServeHandler(cfg *mybase.Config) error {
+	srv := &apiServer{
+		repo:        cfg.Get("repo"),
+		token:       cfg.GetAllowEnvVar("api-token"),
+		allowedRefs: cfg.GetSlice("allowed-refs", ',', true),
+		metrics:     applier.NewMetrics(),
+	}
+	if srv.repo == "" {
+		return NewExitValue(CodeBadConfig, "Option --repo is required")
+	} else if srv.token == "" {
+		return NewExitValue(CodeBadConfig, "Option --api-token is required")
+	} else if len(srv.allowedRefs) == 0 {
+		return NewExitValue(CodeBadConfig, "Option --allowed-refs must not be empty")
+	}
+	for _, pattern := range srv.allowedRefs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NewExitValue(CodeBadConfig, "Option --allowed-refs contains invalid pattern %q", pattern)
+		}
+	}
+	var err error
+	if srv.timeout, err = time.ParseDuration(cfg.Get("request-timeout")); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if srv.timeout <= 0 {
+		return NewExitValue(CodeBadConfig, "Option request-timeout must be a positive duration")
+	}
+	certFile, keyFile := cfg.Get("tls-cert"), cfg.Get("tls-key")
+	if (certFile == "") != (keyFile == "") {
+		return NewExitValue(CodeBadConfig, "Options --tls-cert and --tls-key must be used together")
+	}
+	if srv.exe, err = os.Executable(); err != nil {
+		return NewExitValue(CodeFatalError, "Unable to determine path of skeema executable: %s", err)
+	}
+	listener, err := net.Listen("tcp", cfg.Get("listen"))
+	if err != nil {
+		return NewExitValue(CodeBadConfig, "Unable to listen for requests: %s", err)
+	}
+
+	httpServer := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Info("Shutting down; waiting for in-progress requests to complete")
+		httpServer.Shutdown(context.Background())
+	}()
+	log.Infof("Listening for requests on %s", listener.Addr())
+	if certFile != "" {
+		err = httpServer.ServeTLS(listener, certFile, keyFile)
+	} else {
+		err = httpServer.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return NewExitValue(CodeFatalError, "Server stopped: %s", err)
+	}
+	return nil
+}
+
+// apiServer is the http.Handler for `skeema serve`.
+type apiServer struct {
+	repo        string        // git repository URL or path
+	token       string        // required bearer token
+	allowedRefs []string      // refs or glob patterns which requests may supply
+	exe         string        // path to skeema executable, for running operations
+	timeout     time.Duration // maximum duration of each operation
+	metrics     *applier.Metrics
+}
+
+// apiRequest is the JSON body of a request to an operation endpoint.
+type apiRequest struct {
+	Ref         string `json:"ref"`
+	Dir         string `json:"dir"`
+	Environment string `json:"environment"`
+	AllowUnsafe bool   `json:"allow_unsafe"`
+}
+
+// apiResponse is the JSON body of the response from an operation endpoint.
+type apiResponse struct {
+	Command  string                  `json:"command"`
+	Ref      string                  `json:"ref,omitempty"`
+	Commit   string                  `json:"commit,omitempty"`
+	ExitCode int                     `json:"exit_code"`
+	Targets  []*applier.TargetReport `json:"targets,omitempty"` // only for diff and push
+	Log      string                  `json:"log,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+var apiEnvironmentRegexp = regexp.MustCompile(`^[\w.][\w.-]*$`)
+
+// validate returns an error if req contains invalid values, after first
+// filling in defaults for any omitted fields. allow_unsafe is only permitted
+// for push, since diff and lint never modify a database.
+func (req *apiRequest) validate(command string) error {
+	if req.Ref == "" {
+		req.Ref = "HEAD"
+	}
+	if req.Dir == "" {
+		req.Dir = "."
+	}
+	if req.Environment == "" {
+		req.Environment = "production"
+	}
+	if strings.HasPrefix(req.Ref, "-") {
+		return fmt.Errorf("invalid ref %q", req.Ref)
+	} else if !filepath.IsLocal(req.Dir) {
+		return fmt.Errorf("invalid dir %q: must be a relative path within the repository", req.Dir)
+	} else if !apiEnvironmentRegexp.MatchString(req.Environment) {
+		return fmt.Errorf("invalid environment %q", req.Environment)
+	} else if req.AllowUnsafe && command != "push" {
+		return fmt.Errorf("allow_unsafe is only permitted for push")
+	}
+	return nil
+}
+
+// args returns the command-line args for running command with req.
+func (req *apiRequest) args(command string) []string {
+	args := []string{command, req.Environment}
+	if command == "diff" || command == "push" {
+		args = append(args, "--output-format=json")
+	}
+	if req.AllowUnsafe {
+		args = append(args, "--allow-unsafe")
+	}
+	return args
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (srv *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v1/health" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+		return
+	}
+	command, ok := strings.CutPrefix(r.URL.Path, "/v1/")
+	if !ok || (command != "diff" && command != "lint" && command != "push" && command != "metrics") {
+		srv.writeError(w, http.StatusNotFound, command, "unknown endpoint %s", r.URL.Path)
+		return
+	}
+	if command == "metrics" {
+		if !srv.authorized(w, r, command) {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(srv.metrics.String()))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		srv.writeError(w, http.StatusMethodNotAllowed, command, "method %s not allowed", r.Method)
+		return
+	}
+	if !srv.authorized(w, r, command) {
+		return
+	}
+	var req apiRequest
+	if r.ContentLength != 0 {
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			srv.writeError(w, http.StatusBadRequest, command, "unable to parse request body: %s", err)
+			return
+		}
+	}
+	if err := req.validate(command); err != nil {
+		srv.writeError(w, http.StatusBadRequest, command, "%s", err)
+		return
+	} else if !srv.refAllowed(req.Ref) {
+		srv.writeError(w, http.StatusForbidden, command, "ref %q is not permitted by allowed-refs", req.Ref)
+		return
+	}
+
+	resp, status := srv.run(r.Context(), command, &req)
+	log.Infof("%s %s ref=%s dir=%s environment=%s: exit code %d", r.RemoteAddr, command, req.Ref, req.Dir, req.Environment, resp.ExitCode)
+	srv.writeResponse(w, status, resp)
+}
+
+// authorized returns true if r supplies the server's bearer token. Otherwise,
+// it writes an error response and returns false.
+func (srv *apiServer) authorized(w http.ResponseWriter, r *http.Request, command string) bool {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(srv.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		srv.writeError(w, http.StatusUnauthorized, command, "missing or invalid bearer token")
+		return false
+	}
+	return true
+}
+
+// refAllowed returns true if ref matches any of the server's allowed-refs.
+// This restricts requests to refs whose contents have been reviewed, since
+// .skeema files may configure options which execute arbitrary commands.
+func (srv *apiServer) refAllowed(ref string) bool {
+	for _, pattern := range srv.allowedRefs {
+		if matched, _ := path.Match(pattern, ref); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// run performs command on a fresh checkout of the repository, and returns the
+// response along with an HTTP status code.
+func (srv *apiServer) run(ctx context.Context, command string, req *apiRequest) (*apiResponse, int) {
+	resp := &apiResponse{Command: command, Ref: req.Ref}
+	checkoutDir, err := os.MkdirTemp("", "skeema-serve-*")
+	if err != nil {
+		resp.ExitCode, resp.Error = CodeFatalError, err.Error()
+		return resp, http.StatusInternalServerError
+	}
+	defer os.RemoveAll(checkoutDir)
+	if resp.Commit, err = util.GitCheckout(srv.repo, req.Ref, checkoutDir); err != nil {
+		resp.ExitCode, resp.Error = CodeFatalError, err.Error()
+		return resp, http.StatusUnprocessableEntity
+	}
+	workDir := filepath.Join(checkoutDir, req.Dir)
+	if fi, err := os.Stat(workDir); err != nil || !fi.IsDir() {
+		resp.ExitCode, resp.Error = CodeBadConfig, fmt.Sprintf("dir %s does not exist at commit %s", req.Dir, resp.Commit)
+		return resp, http.StatusUnprocessableEntity
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, srv.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, srv.exe, req.args(command)...)
+	cmd.Dir = workDir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = time.Minute
+	err = cmd.Run()
+	resp.Log = stderr.String()
+	var exitErr *exec.ExitError
+	if ctx.Err() == context.DeadlineExceeded {
+		resp.ExitCode, resp.Error = CodeFatalError, "operation exceeded request-timeout"
+		return resp, http.StatusGatewayTimeout
+	} else if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		resp.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		resp.ExitCode, resp.Error = CodeFatalError, err.Error()
+		return resp, http.StatusInternalServerError
+	}
+
+	if command == "diff" || command == "push" {
+		decoder := json.NewDecoder(&stdout)
+		for decoder.More() {
+			report := &applier.TargetReport{}
+			if err := decoder.Decode(report); err != nil {
+				resp.Error = fmt.Sprintf("unable to parse %s output: %s", command, err)
+				break
+			}
+			resp.Targets = append(resp.Targets, report)
+			srv.metrics.Record(report)
+		}
+	}
+	return resp, http.StatusOK
+}
+
+func (srv *apiServer) writeError(w http.ResponseWriter, status int, command, format string, a ...interface{}) {
+	resp := &apiResponse{
+		Command:  command,
+		ExitCode: CodeBadUsage,
+		Error:    fmt.Sprintf(format, a...),
+	}
+	srv.writeResponse(w, status, resp)
+}
+
+func (srv *apiServer) writeResponse(w http.ResponseWriter, status int, resp *apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/applier"
+)
+
+func TestAPIServerRequests(t *testing.T) {
+	srv := &apiServer{
+		repo:        filepath.Join(t.TempDir(), "doesnt-exist"),
+		token:       "s3cret",
+		allowedRefs: []string{"HEAD", "main", "release/*"},
+		exe:         "/bin/false",
+		timeout:     time.Minute,
+		metrics:     applier.NewMetrics(),
+	}
+	cases := []struct {
+		method   string
+		path     string
+		token    string
+		body     string
+		expected int
+	}{
+		{"GET", "/v1/health", "", "", http.StatusOK},
+		{"GET", "/v1/metrics", "s3cret", "", http.StatusOK},
+		{"GET", "/v1/metrics", "", "", http.StatusUnauthorized},
+		{"POST", "/v1/format", "s3cret", "", http.StatusNotFound},
+		{"POST", "/metrics", "s3cret", "", http.StatusNotFound},
+		{"GET", "/v1/diff", "s3cret", "", http.StatusMethodNotAllowed},
+		{"POST", "/v1/diff", "", "", http.StatusUnauthorized},
+		{"POST", "/v1/diff", "wrong", "", http.StatusUnauthorized},
+		{"POST", "/v1/diff", "s3cret", `{"dir": "../elsewhere"}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"dir": "/etc"}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"ref": "--upload-pack=foo"}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"environment": "--allow-unsafe"}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"allow_unsafe": true}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"options": {"alter-wrapper": "rm"}}`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `not json`, http.StatusBadRequest},
+		{"POST", "/v1/diff", "s3cret", `{"ref": "feature"}`, http.StatusForbidden},
+		{"POST", "/v1/diff", "s3cret", `{"ref": "release/1.0/extra"}`, http.StatusForbidden},
+		{"POST", "/v1/diff", "s3cret", `{"ref": "release/1.0"}`, http.StatusUnprocessableEntity},                               // repo doesn't exist
+		{"POST", "/v1/push", "s3cret", `{"ref": "main", "dir": "mydb", "allow_unsafe": true}`, http.StatusUnprocessableEntity}, // repo doesn't exist
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		if c.token != "" {
+			r.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		if w.Code != c.expected {
+			t.Errorf("%s %s with body %q: expected status %d, instead found %d: %s", c.method, c.path, c.body, c.expected, w.Code, w.Body.String())
+		} else if c.expected != http.StatusOK {
+			var resp apiResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" || resp.ExitCode == 0 {
+				t.Errorf("%s %s with body %q: unexpected response body %s (err=%v)", c.method, c.path, c.body, w.Body.String(), err)
+			}
+		}
+	}
+}
+
+func TestAPIRequestArgs(t *testing.T) {
+	req := &apiRequest{}
+	if err := req.validate("diff"); err != nil {
+		t.Fatalf("Unexpected error from validate: %v", err)
+	} else if req.Ref != "HEAD" || req.Dir != "." || req.Environment != "production" {
+		t.Errorf("Unexpected defaults after validate: %+v", *req)
+	}
+	if args := strings.Join(req.args("diff"), " "); args != "diff production --output-format=json" {
+		t.Errorf("Unexpected args for diff: %s", args)
+	}
+	if args := strings.Join(req.args("lint"), " "); args != "lint production" {
+		t.Errorf("Unexpected args for lint: %s", args)
+	}
+	req = &apiRequest{Environment: "staging", AllowUnsafe: true}
+	if err := req.validate("push"); err != nil {
+		t.Fatalf("Unexpected error from validate: %v", err)
+	}
+	if args := strings.Join(req.args("push"), " "); args != "push staging --output-format=json --allow-unsafe" {
+		t.Errorf("Unexpected args for push: %s", args)
+	}
+}
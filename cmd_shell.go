@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Open a mysql or mariadb client session to a database server"
+	desc := "Launches the mysql or mariadb command-line client, connected to the first " +
+		"reachable database server configured for this directory. The connection uses " +
+		"Skeema's own resolution of the host, port, socket, user, password, and ssl-mode " +
+		"options, including any credential-helper, external secret store, or IAM auth " +
+		"token. Session variables in connect-options are also applied. If this directory " +
+		"maps to a schema, it is selected as the default database.\n\n" +
+		"The password is supplied to the client using a temporary option file, which is " +
+		"readable only by the current user and is removed after the client exits.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("shell", summary, desc, ShellHandler)
+	cmd.AddOption(mybase.StringOption("client", 0, "", `Path to client program (default "mysql" or "mariadb" from $PATH)`))
+	cmd.AddOption(mybase.StringOption("execute", 'e', "", "Execute the supplied statement and exit, instead of opening an interactive session"))
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// ShellHandler is the handler method for `skeema shell`
+func ShellHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	client, err := findSQLClient(dir.Config.Get("client"))
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	connOpts, err := util.RealConnectOptions(dir.Config.Get("connect-options"))
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	inst, err := dir.FirstInstance()
+	if err != nil {
+		return err
+	} else if inst == nil {
+		return NewExitValue(CodeBadConfig, "No host defined for environment %q in %s", dir.Config.Get("environment"), dir)
+	}
+	var schemaName string
+	if schemaNames, err := dir.SchemaNames(inst); err != nil {
+		return err
+	} else if len(schemaNames) > 0 {
+		schemaName = schemaNames[0]
+	}
+
+	// The client requires --defaults-extra-file to be its first arg
+	args := client.Args(inst, schemaName, connOpts, dir.Config.Get("execute"))
+	password, err := inst.CurrentPassword()
+	if err != nil {
+		return err
+	} else if password != "" {
+		optionFilePath, err := writeClientOptionFile(password)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(optionFilePath)
+		args = append([]string{"--defaults-extra-file=" + optionFilePath}, args...)
+	}
+
+	log.Debugf("Running %s %s", client.Path, strings.Join(args, " "))
+	cmd := exec.Command(client.Path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	// The client handles interrupts itself, for example by killing the running
+	// query upon ctrl-c, so Skeema must not exit upon receiving one
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return NewExitValue(exitErr.ExitCode(), "%s exited with code %d", client.Path, exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+// sqlClient represents a mysql or mariadb command-line client program.
+type sqlClient struct {
+	Path    string
+	MariaDB bool // true if the client is from MariaDB, which has different TLS options
+}
+
+// findSQLClient returns a sqlClient for the supplied program path. If path is
+// blank, the mysql or mariadb client in $PATH is used.
+func findSQLClient(path string) (*sqlClient, error) {
+	if path == "" {
+		for _, name := range []string{"mysql", "mariadb"} {
+			if found, err := exec.LookPath(name); err == nil {
+				path = found
+				break
+			}
+		}
+		if path == "" {
+			return nil, errors.New("Unable to find mysql or mariadb client in $PATH. Use --client to specify its location.")
+		}
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to run client %s: %w", path, err)
+	}
+	client := &sqlClient{
+		Path:    path,
+		MariaDB: strings.Contains(string(out), "MariaDB"),
+	}
+	return client, nil
+}
+
+// Args returns command-line args for connecting the client to inst, using the
+// TLS settings from inst's connection params. If connOpts is non-empty, it
+// should be a comma-separated list of session variables, as returned by
+// util.RealConnectOptions. The password is not included in the result.
+func (client *sqlClient) Args(inst *tengo.Instance, schemaName, connOpts, execute string) []string {
+	var args []string
+	if inst.SocketPath != "" {
+		args = append(args, "--socket="+inst.SocketPath)
+	} else {
+		args = append(args, "--host="+inst.Host, "--port="+strconv.Itoa(inst.Port))
+	}
+	args = append(args, "--user="+inst.User)
+
+	// See Dir.InstanceDefaultParams for how Skeema's ssl-mode maps to the driver's
+	// tls param. Other values of tls, such as "preferred", correspond to the
+	// clients' default behavior.
+	params, _ := url.ParseQuery(inst.BuildParamString(""))
+	switch tls := params.Get("tls"); {
+	case tls == "false" && client.MariaDB:
+		args = append(args, "--skip-ssl")
+	case tls == "false":
+		args = append(args, "--ssl-mode=DISABLED")
+	case tls == "skip-verify" && client.MariaDB:
+		args = append(args, "--ssl", "--skip-ssl-verify-server-cert")
+	case tls == "skip-verify":
+		args = append(args, "--ssl-mode=REQUIRED")
+	case tls == "true" && client.MariaDB:
+		args = append(args, "--ssl", "--ssl-verify-server-cert")
+	case tls == "true":
+		args = append(args, "--ssl-mode=VERIFY_IDENTITY")
+	}
+
+	// IAM auth tokens are sent using the cleartext plugin, which the MySQL client
+	// requires enabling explicitly
+	if params.Get("allowCleartextPasswords") == "true" && !client.MariaDB {
+		args = append(args, "--enable-cleartext-plugin")
+	}
+	if connOpts != "" {
+		args = append(args, "--init-command=SET SESSION "+connOpts)
+	}
+	if execute != "" {
+		args = append(args, "--execute="+execute)
+	}
+	if schemaName != "" {
+		args = append(args, "--database="+schemaName)
+	}
+	return args
+}
+
+// writeClientOptionFile writes password to a new temporary option file, which
+// may be supplied to the client using --defaults-extra-file. This avoids
+// exposing the password in the process list. The caller should remove the
+// file once the client exits.
+func writeClientOptionFile(password string) (string, error) {
+	f, err := os.CreateTemp("", "skeema-shell-*.cnf") // created with mode 0600
+	if err != nil {
+		return "", err
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(password)
+	_, err = fmt.Fprintf(f, "[client]\npassword=\"%s\"\n", escaped)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestSQLClientArgs(t *testing.T) {
+	cases := []struct {
+		dsn      string
+		mariaDB  bool
+		schema   string
+		connOpts string
+		execute  string
+		expected string
+	}{
+		{"root@tcp(127.0.0.1:3307)/?tls=preferred", false, "", "", "", "--host=127.0.0.1 --port=3307 --user=root"},
+		{"root@tcp(127.0.0.1:3307)/?tls=false", false, "product", "", "", "--host=127.0.0.1 --port=3307 --user=root --ssl-mode=DISABLED --database=product"},
+		{"root@tcp(127.0.0.1:3307)/?tls=false", true, "", "", "", "--host=127.0.0.1 --port=3307 --user=root --skip-ssl"},
+		{"app@tcp(db.example.com:3306)/?tls=skip-verify&allowCleartextPasswords=true", false, "", "wait_timeout=60", "SELECT 1", "--host=db.example.com --port=3306 --user=app --ssl-mode=REQUIRED --enable-cleartext-plugin --init-command=SET SESSION wait_timeout=60 --execute=SELECT 1"},
+		{"app@tcp(db.example.com:3306)/?tls=skip-verify&allowCleartextPasswords=true", true, "", "", "", "--host=db.example.com --port=3306 --user=app --ssl --skip-ssl-verify-server-cert"},
+		{"app@tcp(db.example.com:3306)/?tls=true", false, "", "", "", "--host=db.example.com --port=3306 --user=app --ssl-mode=VERIFY_IDENTITY"},
+		{"app@tcp(db.example.com:3306)/?tls=true", true, "", "", "", "--host=db.example.com --port=3306 --user=app --ssl --ssl-verify-server-cert"},
+		{"root@unix(/var/lib/mysql/mysql.sock)/?tls=oldtls", true, "analytics", "", "", "--socket=/var/lib/mysql/mysql.sock --user=root --database=analytics"},
+	}
+	for _, c := range cases {
+		inst, err := tengo.NewInstance("mysql", c.dsn)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewInstance: %v", err)
+		}
+		client := &sqlClient{Path: "mysql", MariaDB: c.mariaDB}
+		if actual := strings.Join(client.Args(inst, c.schema, c.connOpts, c.execute), " "); actual != c.expected {
+			t.Errorf("Unexpected args for DSN %s (MariaDB=%t):\nexpected: %s\nfound:    %s", c.dsn, c.mariaDB, c.expected, actual)
+		}
+	}
+}
+
+func TestWriteClientOptionFile(t *testing.T) {
+	path, err := writeClientOptionFile(`pa"ss\word`)
+	if err != nil {
+		t.Fatalf("Unexpected error from writeClientOptionFile: %v", err)
+	}
+	defer os.Remove(path)
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reading option file: %v", err)
+	}
+	if expected := "[client]\npassword=\"pa\\\"ss\\\\word\"\n"; string(contents) != expected {
+		t.Errorf("Unexpected option file contents: %q", contents)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("Unexpected option file permissions: %v, err=%v", fi.Mode().Perm(), err)
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Run SQL unit tests against the schemas in a workspace"
+	desc := "Runs SQL unit tests, typically of stored procedures and functions, found in " +
+		"*.sqltest files of this directory and its subdirectories. For each test file, the " +
+		"directory's *.sql files are executed in a workspace, and then the test file's " +
+		"statements are run there. See the --workspace option for more information on " +
+		"workspaces; workspace=docker is recommended for running tests in CI.\n\n" +
+		"A test file consists of optional setup statements, such as INSERTs of seed data, " +
+		"followed by one or more test cases. Each test case begins with a comment line of " +
+		"the form \"-- test: <name>\" and contains one or more statements. By default a test " +
+		"case passes if all of its statements succeed, which permits assertion procedures " +
+		"that SIGNAL an error upon failure. A comment line \"-- expect:\" in a test case, " +
+		"followed by one comment line per row with columns separated by \"|\", instead " +
+		"requires the test case's final resultset to match those rows. A comment line " +
+		"\"-- expect-error\" or \"-- expect-error: <code>\" requires a statement to fail, " +
+		"optionally with a specific error code.\n\n" +
+		"If a directory contains a file named setup.sqltest, its statements are run before " +
+		"those of every other test file in that directory. Each test case runs in a " +
+		"transaction which is rolled back afterwards, so test cases do not affect each " +
+		"other.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("test", summary, desc, TestHandler)
+	cmd.AddOption(mybase.StringOption("run", 0, "", "Only run test cases with names matching this regular expression"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// TestHandler is the handler method for `skeema test`
+func TestHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	var runPattern *regexp.Regexp
+	if run := dir.Config.Get("run"); run != "" {
+		if runPattern, err = regexp.Compile(run); err != nil {
+			return NewExitValue(CodeBadConfig, "Invalid regular expression for option run: %s", err)
+		}
+	}
+
+	result := sqlTestWalker(dir, 5, runPattern)
+	switch {
+	case result.SkipCount > 0:
+		return NewExitValue(CodeFatalError, "Skipped %s due to errors; %d tests failed, %d passed",
+			countAndNoun(result.SkipCount, "directory or file", "directories or files"),
+			result.FailCount,
+			result.PassCount,
+		)
+	case result.FailCount > 0:
+		return NewExitValue(CodeFatalError, "%s failed, %d passed",
+			countAndNoun(result.FailCount, "test", "tests"),
+			result.PassCount,
+		)
+	case result.PassCount == 0:
+		log.Warnf("No test cases found in %s or its subdirectories", dir)
+	default:
+		log.Infof("%s passed", countAndNoun(result.PassCount, "test", "tests"))
+	}
+	return nil
+}
+
+// sqlTestResult tallies the outcome of running tests.
+type sqlTestResult struct {
+	PassCount int
+	FailCount int
+	SkipCount int // directories or test files which could not be run
+}
+
+func sqlTestWalker(dir *fs.Dir, maxDepth int, runPattern *regexp.Regexp) (result sqlTestResult) {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError)
+		result.SkipCount++
+		return result
+	}
+	result = runSQLTestsInDir(dir, runPattern)
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		result.SkipCount++
+		return result
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Errorf("Not walking subdirs of %s: max depth reached", dir)
+		result.SkipCount++
+		return result
+	}
+	for _, sub := range subdirs {
+		subResult := sqlTestWalker(sub, maxDepth-1, runPattern)
+		result.PassCount += subResult.PassCount
+		result.FailCount += subResult.FailCount
+		result.SkipCount += subResult.SkipCount
+	}
+	return result
+}
+
+// runSQLTestsInDir runs the test cases of all *.sqltest files in dir, logging
+// the outcome of each test case.
+func runSQLTestsInDir(dir *fs.Dir, runPattern *regexp.Regexp) (result sqlTestResult) {
+	setup, testFiles, err := sqlTestFilesForDir(dir)
+	if err != nil {
+		log.Errorf("Skipping tests in %s due to error: %s", dir, err)
+		result.SkipCount++
+		return result
+	} else if len(testFiles) == 0 {
+		return result
+	} else if len(dir.LogicalSchemas) == 0 {
+		log.Errorf("Skipping tests in %s: directory does not contain any *.sql files defining a schema", dir)
+		result.SkipCount++
+		return result
+	}
+	wsOpts, err := workspaceOptionsForDir(dir)
+	if err == nil && wsOpts.Type == workspace.TypeParse {
+		err = workspace.ErrParseWorkspace
+	}
+	if err != nil {
+		log.Errorf("Skipping tests in %s due to error: %s", dir, err)
+		result.SkipCount++
+		return result
+	}
+
+	log.Infof("Testing %s", dir)
+	for _, tf := range testFiles {
+		cases := tf.Cases
+		if runPattern != nil {
+			cases = slices.DeleteFunc(slices.Clone(cases), func(tc *sqlTestCase) bool {
+				return !runPattern.MatchString(tc.Name)
+			})
+		}
+		if len(cases) == 0 {
+			continue
+		}
+		var caseErrs []error
+		_, err := workspace.ExecLogicalSchemaFunc(dir.LogicalSchemas[0], wsOpts, func(ws workspace.Workspace, wsSchema *workspace.Schema) (err error) {
+			caseErrs, err = runSQLTestFile(ws, wsSchema, setup, tf.Setup, cases)
+			return err
+		})
+		for n, tc := range cases {
+			if n >= len(caseErrs) {
+				break
+			} else if caseErrs[n] != nil {
+				log.Errorf("FAIL %s (%s): %s", tc.Name, tc.Location(), caseErrs[n])
+				result.FailCount++
+			} else {
+				log.Debugf("PASS %s (%s)", tc.Name, tc.Location())
+				result.PassCount++
+			}
+		}
+		if err != nil {
+			log.Errorf("Unable to run tests in %s: %s", tf.Path, err)
+			result.SkipCount++
+		}
+	}
+	return result
+}
+
+// sqlTestFilesForDir returns the parsed *.sqltest files in dir. The setup file,
+// if any, is returned separately from the others.
+func sqlTestFilesForDir(dir *fs.Dir) (setup *sqlTestFile, testFiles []*sqlTestFile, err error) {
+	entries, err := os.ReadDir(dir.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sqltest") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir.Path, name))
+		if err != nil {
+			return nil, nil, err
+		}
+		tf, err := parseSQLTestFile(f, filepath.Join(dir.RelPath(), name))
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if name == "setup.sqltest" {
+			if len(tf.Cases) > 0 {
+				return nil, nil, fmt.Errorf("%s: setup.sqltest cannot contain test cases", tf.Path)
+			}
+			setup = tf
+		} else {
+			testFiles = append(testFiles, tf)
+		}
+	}
+	return setup, testFiles, nil
+}
+
+// sqlTestFile represents a parsed *.sqltest file.
+type sqlTestFile struct {
+	Path  string
+	Setup []*tengo.Statement // statements before the first test case
+	Cases []*sqlTestCase
+}
+
+// sqlTestCase represents a single test case within a sqlTestFile.
+type sqlTestCase struct {
+	Name            string
+	File            string
+	LineNo          int
+	Statements      []*tengo.Statement
+	ExpectRows      bool       // if true, the final resultset must match Expected
+	Expected        [][]string // expected rows of the final resultset
+	ExpectError     bool       // if true, a statement must fail
+	ExpectErrorCode uint16     // if non-zero, the failure must have this error code
+}
+
+// Location returns the file and line number where the test case begins.
+func (tc *sqlTestCase) Location() string {
+	return tc.File + ":" + strconv.Itoa(tc.LineNo)
+}
+
+// sqlTestMarker returns the value following a "-- keyword:" comment line, and
+// a boolean indicating whether line is such a marker at all. The colon is
+// optional if no value follows the keyword.
+func sqlTestMarker(line, keyword string) (string, bool) {
+	comment, ok := strings.CutPrefix(strings.TrimSpace(line), "--")
+	if !ok {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(comment), keyword)
+	if !ok {
+		return "", false
+	} else if rest == "" {
+		return "", true
+	} else if rest[0] != ':' {
+		return "", false
+	}
+	return strings.TrimSpace(rest[1:]), true
+}
+
+// parseSQLTestFile parses a test file from r. The supplied path is used in
+// error messages and test case locations.
+func parseSQLTestFile(r io.Reader, path string) (*sqlTestFile, error) {
+	tf := &sqlTestFile{Path: path}
+	var (
+		current        *sqlTestCase // nil while still in setup statements
+		text           strings.Builder
+		textStartLine  = 1
+		inExpectedRows bool
+	)
+
+	// flush parses the SQL accumulated since the previous marker, appending the
+	// statements to the setup or the current test case
+	flush := func() error {
+		statements, err := tengo.ParseStatementsInString(text.String())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, textStartLine, err)
+		}
+		for _, stmt := range statements {
+			if stmt.Type == tengo.StatementTypeNoop || stmt.Type == tengo.StatementTypeCommand {
+				continue
+			}
+			stmt.File = path
+			stmt.LineNo += textStartLine - 1
+			if current == nil {
+				tf.Setup = append(tf.Setup, stmt)
+			} else {
+				current.Statements = append(current.Statements, stmt)
+			}
+		}
+		text.Reset()
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if inExpectedRows {
+			row, isComment := strings.CutPrefix(strings.TrimSpace(line), "--")
+			if row = strings.TrimSpace(row); isComment && row != "" {
+				cells := strings.Split(row, "|")
+				for n := range cells {
+					cells[n] = strings.TrimSpace(cells[n])
+				}
+				current.Expected = append(current.Expected, cells)
+				textStartLine = lineNo + 1
+				continue
+			}
+			inExpectedRows = false
+		}
+		if name, ok := sqlTestMarker(line, "test"); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			} else if name == "" {
+				return nil, fmt.Errorf("%s:%d: test case name is required", path, lineNo)
+			}
+			current = &sqlTestCase{Name: name, File: path, LineNo: lineNo}
+			tf.Cases = append(tf.Cases, current)
+			textStartLine = lineNo + 1
+			continue
+		}
+		_, isExpect := sqlTestMarker(line, "expect")
+		code, isExpectError := sqlTestMarker(line, "expect-error")
+		if (isExpect || isExpectError) && current == nil {
+			return nil, fmt.Errorf("%s:%d: expectation found outside of a test case", path, lineNo)
+		} else if isExpect {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			current.ExpectRows = true
+			inExpectedRows = true
+			textStartLine = lineNo + 1
+			continue
+		} else if isExpectError {
+			current.ExpectError = true
+			if code != "" {
+				n, err := strconv.ParseUint(code, 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: invalid error code %q", path, lineNo, code)
+				}
+				current.ExpectErrorCode = uint16(n)
+			}
+		}
+		text.WriteString(line)
+		text.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	} else if err := flush(); err != nil {
+		return nil, err
+	}
+	for _, tc := range tf.Cases {
+		if len(tc.Statements) == 0 {
+			return nil, fmt.Errorf("%s: test case %q does not contain any statements", tc.Location(), tc.Name)
+		} else if tc.ExpectRows && tc.ExpectError {
+			return nil, fmt.Errorf("%s: test case %q cannot expect both rows and an error", tc.Location(), tc.Name)
+		}
+	}
+	return tf, nil
+}
+
+// runSQLTestFile runs the setup statements of dirSetup (if non-nil) and
+// fileSetup, followed by each test case, using a single transaction in the
+// workspace. Each test case is isolated from the others using a savepoint. The
+// returned slice has one element per test case that was run, with a nil value
+// for each passing test case. A non-nil error return indicates a problem which
+// prevented some or all test cases from running.
+func runSQLTestFile(ws workspace.Workspace, wsSchema *workspace.Schema, dirSetup *sqlTestFile, fileSetup []*tengo.Statement, cases []*sqlTestCase) (caseErrs []error, err error) {
+	if len(wsSchema.Failures) > 0 {
+		return nil, fmt.Errorf("Unable to create schema in workspace: %s", wsSchema.Failures[0])
+	}
+
+	// Test cases may rely on foreign keys being enforced, unlike other uses of
+	// workspaces
+	db, err := ws.ConnectionPool("foreign_key_checks=1")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tx.Rollback()
+		if emptyErr := emptyWorkspaceTables(ws, wsSchema.Tables); err == nil {
+			err = emptyErr
+		}
+	}()
+
+	setup := fileSetup
+	if dirSetup != nil {
+		setup = append(slices.Clone(dirSetup.Setup), fileSetup...)
+	}
+	for _, stmt := range setup {
+		if _, err := tx.Exec(stmt.Body()); err != nil {
+			return nil, fmt.Errorf("Setup statement at %s failed: %w", stmt.Location(), err)
+		}
+	}
+	for _, tc := range cases {
+		if _, err := tx.Exec("SAVEPOINT skeema_test"); err != nil {
+			return caseErrs, err
+		}
+		caseErrs = append(caseErrs, tc.run(tx))
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT skeema_test"); err != nil {
+			return caseErrs, fmt.Errorf("Unable to roll back test case %q, possibly due to an implicit commit: %w", tc.Name, err)
+		}
+	}
+	return caseErrs, nil
+}
+
+// run executes the test case's statements using tx, returning a non-nil error
+// if the test case fails.
+func (tc *sqlTestCase) run(tx *sqlx.Tx) error {
+	var actual [][]string
+	var hasResultSet bool
+	for _, stmt := range tc.Statements {
+		rows, err := tx.Query(stmt.Body())
+		if err == nil {
+			var stmtHasResultSet bool
+			var stmtRows [][]string
+			if stmtRows, stmtHasResultSet, err = readResultSets(rows); stmtHasResultSet {
+				actual, hasResultSet = stmtRows, true
+			}
+		}
+		if err != nil && tc.ExpectError {
+			var mysqlErr *mysql.MySQLError
+			if tc.ExpectErrorCode == 0 || (errors.As(err, &mysqlErr) && mysqlErr.Number == tc.ExpectErrorCode) {
+				return nil
+			}
+			return fmt.Errorf("expected error %d, but statement at %s failed with: %w", tc.ExpectErrorCode, stmt.Location(), err)
+		} else if err != nil {
+			return fmt.Errorf("statement at %s failed: %w", stmt.Location(), err)
+		}
+	}
+	if tc.ExpectError {
+		return errors.New("expected an error, but all statements succeeded")
+	} else if !tc.ExpectRows {
+		return nil
+	} else if !hasResultSet {
+		return errors.New("expected rows, but no statements returned a resultset")
+	} else if !slices.EqualFunc(tc.Expected, actual, slices.Equal) {
+		return fmt.Errorf("resultset did not match\nexpected:\n%s\nactual:\n%s", formatSQLTestRows(tc.Expected), formatSQLTestRows(actual))
+	}
+	return nil
+}
+
+// readResultSets reads all rows, returning the values of the final resultset
+// (with NULLs as "NULL") and whether any resultset was returned at all. A CALL
+// to a stored procedure may return multiple resultsets.
+func readResultSets(rows *sql.Rows) (result [][]string, hasResultSet bool, err error) {
+	defer rows.Close()
+	for {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, false, err
+		}
+		if len(cols) > 0 {
+			result, hasResultSet = [][]string{}, true
+			values := make([]sql.NullString, len(cols))
+			ptrs := make([]any, len(cols))
+			for n := range values {
+				ptrs[n] = &values[n]
+			}
+			for rows.Next() {
+				if err := rows.Scan(ptrs...); err != nil {
+					return nil, false, err
+				}
+				row := make([]string, len(cols))
+				for n, value := range values {
+					if value.Valid {
+						row[n] = value.String
+					} else {
+						row[n] = "NULL"
+					}
+				}
+				result = append(result, row)
+			}
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	return result, hasResultSet, rows.Err()
+}
+
+// formatSQLTestRows formats rows for display in a test failure message, using
+// the same format as expected rows in test files.
+func formatSQLTestRows(rows [][]string) string {
+	if len(rows) == 0 {
+		return "  (no rows)"
+	}
+	lines := make([]string, len(rows))
+	for n, row := range rows {
+		lines[n] = "  " + strings.Join(row, " | ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// emptyWorkspaceTables deletes any rows remaining in the workspace's tables,
+// for example due to a test case or routine causing an implicit commit. This
+// is necessary since workspace cleanup refuses to drop tables with rows.
+func emptyWorkspaceTables(ws workspace.Workspace, tables []*tengo.Table) error {
+	db, err := ws.ConnectionPool("foreign_key_checks=0")
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		var hasRows bool
+		query := "SELECT 1 FROM " + tengo.EscapeIdentifier(table.Name) + " LIMIT 1"
+		if err := db.QueryRow(query).Scan(&hasRows); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if _, err := db.Exec("TRUNCATE TABLE " + tengo.EscapeIdentifier(table.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSQLTestFile(t *testing.T) {
+	contents := `-- Seed data shared by all test cases
+INSERT INTO customers (id, email) VALUES (1, 'a@example.com');
+INSERT INTO orders (id, customer_id, total) VALUES (1, 1, 9.99), (2, 1, 5.00);
+
+-- test: order_count counts orders
+SELECT order_count(1) AS n, 'x|y';
+-- expect:
+-- 2 | x
+--
+SELECT order_count(2);
+
+-- test: assertion proc
+CALL assert_order_totals();
+
+-- test: negative totals are rejected
+-- expect-error: 3819
+INSERT INTO orders (id, customer_id, total) VALUES (3, 1, -1);
+
+-- test: compound statement
+DELIMITER //
+CREATE TEMPORARY TABLE t (id int) //
+DELIMITER ;
+-- expect:
+`
+	tf, err := parseSQLTestFile(strings.NewReader(contents), "shop/orders.sqltest")
+	if err != nil {
+		t.Fatalf("Unexpected error from parseSQLTestFile: %v", err)
+	}
+	if len(tf.Setup) != 2 || tf.Setup[1].LineNo != 3 || tf.Setup[1].Location() != "shop/orders.sqltest:3:1" {
+		t.Errorf("Unexpected setup statements: %+v", tf.Setup)
+	}
+	if len(tf.Cases) != 4 {
+		t.Fatalf("Expected 4 test cases, instead found %d", len(tf.Cases))
+	}
+
+	tc := tf.Cases[0]
+	if tc.Name != "order_count counts orders" || tc.Location() != "shop/orders.sqltest:5" || len(tc.Statements) != 2 {
+		t.Errorf("Unexpected test case: %+v", tc)
+	} else if tc.Statements[1].LineNo != 10 || tc.Statements[1].Body() != "SELECT order_count(2)" {
+		t.Errorf("Unexpected statement after expected rows: %+v", tc.Statements[1])
+	}
+	if !tc.ExpectRows || tc.ExpectError || len(tc.Expected) != 1 || strings.Join(tc.Expected[0], ",") != "2,x" {
+		t.Errorf("Unexpected expectations: %+v", tc)
+	}
+	if tc = tf.Cases[1]; tc.ExpectRows || tc.ExpectError || len(tc.Statements) != 1 {
+		t.Errorf("Unexpected test case: %+v", tc)
+	}
+	if tc = tf.Cases[2]; !tc.ExpectError || tc.ExpectErrorCode != 3819 || len(tc.Statements) != 1 {
+		t.Errorf("Unexpected test case: %+v", tc)
+	}
+	if tc = tf.Cases[3]; !tc.ExpectRows || len(tc.Expected) != 0 || len(tc.Statements) != 1 || tc.Statements[0].Body() != "CREATE TEMPORARY TABLE t (id int)" {
+		t.Errorf("Unexpected test case: %+v", tc)
+	}
+
+	badContents := map[string]string{
+		"-- expect:\nSELECT 1;\n":                                       "expectation found outside of a test case",
+		"-- test:\nSELECT 1;\n":                                         "test case name is required",
+		"-- test: empty\n-- test: other\nSELECT 1;\n":                   "does not contain any statements",
+		"-- test: bad code\n-- expect-error: abc\nSELECT 1;\n":          "invalid error code",
+		"-- test: both\n-- expect-error\nSELECT 1;\n-- expect:\n-- 1\n": "cannot expect both",
+	}
+	for contents, expected := range badContents {
+		if _, err := parseSQLTestFile(strings.NewReader(contents), "bad.sqltest"); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, instead found %v", expected, err)
+		}
+	}
+}
+
+func TestSQLTestMarker(t *testing.T) {
+	cases := []struct {
+		line    string
+		keyword string
+		value   string
+		ok      bool
+	}{
+		{"-- test: foo bar", "test", "foo bar", true},
+		{"  --test:foo", "test", "foo", true},
+		{"-- expect", "expect", "", true},
+		{"-- expect-error: 1644", "expect", "", false},
+		{"-- expect-error: 1644", "expect-error", "1644", true},
+		{"-- testing things", "test", "", false},
+		{"SELECT 1; -- test: foo", "test", "", false},
+	}
+	for _, c := range cases {
+		if value, ok := sqlTestMarker(c.line, c.keyword); value != c.value || ok != c.ok {
+			t.Errorf("Unexpected result from sqlTestMarker(%q, %q): %q, %t", c.line, c.keyword, value, ok)
+		}
+	}
+
+	expected := "  1 | NULL\n  2 | b"
+	if actual := formatSQLTestRows([][]string{{"1", "NULL"}, {"2", "b"}}); actual != expected {
+		t.Errorf("Unexpected result from formatSQLTestRows: %q", actual)
+	}
+	if actual := formatSQLTestRows([][]string{}); actual != "  (no rows)" {
+		t.Errorf("Unexpected result from formatSQLTestRows: %q", actual)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Summarize differences between DB servers and the filesystem"
+	desc := "Displays a compact overview of which database objects differ from their " +
+		"filesystem representation, for each environment configured in .skeema " +
+		"files. For each environment, the output shows how many targets (schemas on " +
+		"database servers) differ, the number of differing objects by type, whether any " +
+		"of the changes needed to resolve the differences would be unsafe, and when the " +
+		"environment was checked. No DDL is output; use `skeema diff` to see the " +
+		"full details for an environment.\n\n" +
+		"By default, every environment which configures a host in the .skeema files of " +
+		"this directory or its subdirectories is checked, in addition to \"production\" if " +
+		"a host is configured outside of any environment section. You may optionally " +
+		"pass an environment name as a command-line arg to only check that environment.\n\n" +
+		"An exit code of 0 will be returned if no differences were found; 1 if some " +
+		"differences were found; or 2+ if an error occurred."
+
+	cmd := mybase.NewCommand("status", summary, desc, StatusHandler)
+	cmd.AddArg("environment", "", false)
+	CommandSuite.AddSubCommand(cmd)
+	clonePushOptionsForDriftCheck("status")
+}
+
+// clonePushOptionsForDriftCheck copies options from `skeema push` into the
+// named command, which finds differences using the same code path as
+// `skeema diff`. Options which affect which differences are found remain
+// visible; all others are hidden.
+func clonePushOptionsForDriftCheck(cmdName string) {
+	cmd, ok1 := CommandSuite.SubCommands[cmdName]
+	push, ok2 := CommandSuite.SubCommands["push"]
+	if !ok1 || !ok2 {
+		return
+	}
+	visible := []string{
+		"exact-match",
+		"compare-metadata",
+		"lax-column-order",
+		"append-columns",
+		"lax-comments",
+		"ignore-table-options",
+		"partitioning",
+		"first-only",
+		"dedupe-schemas",
+		"concurrent-instances",
+		"concurrent-per-instance",
+	}
+	cmdOptions := cmd.Options()
+	for name, pushOpt := range push.Options() {
+		if _, already := cmdOptions[name]; already {
+			continue
+		}
+		opt := *pushOpt
+		opt.HiddenOnCLI = !slices.Contains(visible, name)
+		cmd.AddOption(&opt)
+	}
+}
+
+// StatusHandler is the handler method for `skeema status`
+func StatusHandler(cfg *mybase.Config) error {
+	environments, err := prepareDriftCheck(cfg)
+	if err != nil {
+		return err
+	}
+	var statuses []*envStatus
+	for _, env := range environments {
+		status, err := checkEnvironment(cfg, env)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, status)
+	}
+
+	fmt.Print(formatStatusTable(statuses))
+	var drift, errorCount int
+	for _, status := range statuses {
+		drift += status.DriftCount
+		errorCount += status.ErrorCount
+	}
+	if errorCount > 0 {
+		return NewExitValue(CodeFatalError, "Unable to check %s due to errors", countAndNoun(errorCount, "target", "targets"))
+	} else if drift > 0 {
+		return NewExitValue(CodeDifferencesFound, "")
+	}
+	return nil
+}
+
+// prepareDriftCheck sets config overrides so that differences are found using
+// the same logic as `skeema diff --brief`, and returns the environments to
+// check: either the one supplied on the command-line, or else all configured
+// environments. Unsafe changes are permitted so that they are included in the
+// counts; they are detected separately by statusPrinter.
+func prepareDriftCheck(cfg *mybase.Config) ([]string, error) {
+	cfg.SetRuntimeOverride("dry-run", "1")
+	cfg.SetRuntimeOverride("verify", "0")
+	cfg.SetRuntimeOverride("lint", "0")
+	cfg.SetRuntimeOverride("allow-unsafe", "1")
+	cfg.SetRuntimeOverride("output-format", "sql")
+	for _, name := range []string{"save-plan", "save-rollback", "report", "plan", "state-file", "before-push", "after-push"} {
+		cfg.SetRuntimeOverride(name, "")
+	}
+	if !cfg.GetBool("debug") {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	if env := cfg.Get("environment"); env != "" {
+		return []string{env}, nil
+	}
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	return configuredEnvironments(dir, 5), nil
+}
+
+// checkEnvironment finds the differences between the filesystem and all
+// targets of env. Errors affecting individual targets are logged and counted
+// in the returned envStatus, rather than being returned.
+func checkEnvironment(cfg *mybase.Config, env string) (*envStatus, error) {
+	cfg.SetRuntimeOverride("environment", env)
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	limits, err := applier.ConcurrencyLimitsForDir(dir)
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	groups, skipCount := applier.TargetGroupsForDir(dir)
+	printer := &statusPrinter{
+		status: &envStatus{
+			Environment:  env,
+			ErrorCount:   skipCount,
+			ObjectCounts: make(map[tengo.ObjectType]int),
+		},
+	}
+	if _, err := applyTargetGroups(groups, printer, limits, nil, false); err != nil {
+		log.Errorf("Environment %s: %s", env, err)
+	}
+	printer.status.CheckedAt = time.Now()
+	return printer.status, nil
+}
+
+// configuredEnvironments returns the sorted names of environments which
+// configure host or host-wrapper in dir's option file or those of its
+// subdirectories, recursing up to maxDepth levels. If a host is configured
+// outside of any environment section, or no environments configure a host,
+// "production" is included.
+func configuredEnvironments(dir *fs.Dir, maxDepth int) []string {
+	seen := make(map[string]bool)
+	var walk func(dir *fs.Dir, maxDepth int)
+	walk = func(dir *fs.Dir, maxDepth int) {
+		if dir.OptionFile != nil {
+			for _, name := range append(dir.OptionFile.SectionsWithOption("host"), dir.OptionFile.SectionsWithOption("host-wrapper")...) {
+				if name == "" {
+					name = "production"
+				} else if strings.HasPrefix(name, util.ProfileSectionPrefix) {
+					continue
+				}
+				seen[name] = true
+			}
+		}
+		if maxDepth <= 0 {
+			return
+		}
+		subdirs, err := dir.Subdirs()
+		if err != nil {
+			log.Warnf("Cannot list subdirs of %s: %s", dir, err)
+			return
+		}
+		for _, sub := range subdirs {
+			walk(sub, maxDepth-1)
+		}
+	}
+	walk(dir, maxDepth)
+	if len(seen) == 0 {
+		return []string{"production"}
+	}
+	environments := make([]string, 0, len(seen))
+	for name := range seen {
+		environments = append(environments, name)
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// envStatus summarizes the differences found in a single environment.
+type envStatus struct {
+	Environment  string
+	TargetCount  int                      // targets checked successfully
+	DriftCount   int                      // targets with at least one difference
+	ErrorCount   int                      // targets or dirs which could not be checked
+	ObjectCounts map[tengo.ObjectType]int // differing objects, by type
+	Unsupported  int                      // tables with differences that Skeema cannot generate
+	Unsafe       bool                     // true if some change would be destructive
+	CheckedAt    time.Time
+}
+
+// statusPrinter tallies the outcome of each target into an envStatus, rather
+// than outputting any statements.
+type statusPrinter struct {
+	status *envStatus
+	m      sync.Mutex
+}
+
+// Print satisfies the applier.Printer interface, but does not output anything.
+func (sp *statusPrinter) Print(stmt applier.PlannedStatement) {}
+
+// ReportResult records the differences found for t.
+func (sp *statusPrinter) ReportResult(t *applier.Target, plan *applier.Plan, result applier.Result, err error) {
+	sp.m.Lock()
+	defer sp.m.Unlock()
+	if err != nil || plan == nil {
+		sp.status.ErrorCount++
+		return
+	}
+	sp.status.TargetCount++
+	if result.Differences {
+		sp.status.DriftCount++
+	}
+	for _, key := range plan.DiffKeys {
+		sp.status.ObjectCounts[key.Type]++
+	}
+	sp.status.Unsupported += len(plan.Unsupported)
+	for _, stmt := range plan.Statements {
+		if explainer, ok := stmt.(applier.Explainer); ok && explainer.Explain().Impact == applier.ImpactDataDestructive {
+			sp.status.Unsafe = true
+		}
+	}
+}
+
+// formatStatusTable returns a table summarizing each envStatus, one row per
+// environment.
+func formatStatusTable(statuses []*envStatus) string {
+	header := []string{"ENVIRONMENT", "TARGETS", "DIFFERING", "OBJECTS", "UNSAFE", "CHECKED"}
+	rows := [][]string{header}
+	for _, status := range statuses {
+		targets := fmt.Sprintf("%d", status.TargetCount)
+		if status.ErrorCount > 0 {
+			targets += fmt.Sprintf(" (%d failed)", status.ErrorCount)
+		}
+		unsafe := "no"
+		if status.Unsafe {
+			unsafe = "yes"
+		}
+		rows = append(rows, []string{
+			status.Environment,
+			targets,
+			fmt.Sprintf("%d", status.DriftCount),
+			status.objectSummary(),
+			unsafe,
+			status.CheckedAt.UTC().Format("2006-01-02 15:04:05 MST"),
+		})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for n, cell := range row {
+			widths[n] = max(widths[n], len(cell))
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		for n, cell := range row {
+			if n == len(row)-1 {
+				b.WriteString(cell + "\n")
+			} else {
+				fmt.Fprintf(&b, "%-*s  ", widths[n], cell)
+			}
+		}
+	}
+	return b.String()
+}
+
+// objectSummary returns a compact description of the number of differing
+// objects by type, for example "3 tables, 1 procedure".
+func (status *envStatus) objectSummary() string {
+	var parts []string
+	for _, objType := range []tengo.ObjectType{tengo.ObjectTypeDatabase, tengo.ObjectTypeTable, tengo.ObjectTypeProc, tengo.ObjectTypeFunc} {
+		if count := status.ObjectCounts[objType]; count > 0 {
+			parts = append(parts, countAndNoun(count, string(objType), string(objType)+"s"))
+		}
+	}
+	if status.Unsupported > 0 {
+		parts = append(parts, countAndNoun(status.Unsupported, "unsupported table", "unsupported tables"))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestFormatStatusTable(t *testing.T) {
+	checkedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	statuses := []*envStatus{
+		{
+			Environment:  "production",
+			TargetCount:  4,
+			DriftCount:   2,
+			ObjectCounts: map[tengo.ObjectType]int{tengo.ObjectTypeTable: 3, tengo.ObjectTypeProc: 1},
+			Unsafe:       true,
+			CheckedAt:    checkedAt,
+		},
+		{
+			Environment:  "staging",
+			TargetCount:  3,
+			ErrorCount:   1,
+			ObjectCounts: map[tengo.ObjectType]int{},
+			CheckedAt:    checkedAt,
+		},
+		{
+			Environment:  "dev",
+			TargetCount:  1,
+			DriftCount:   1,
+			ObjectCounts: map[tengo.ObjectType]int{tengo.ObjectTypeFunc: 2},
+			Unsupported:  1,
+			CheckedAt:    checkedAt,
+		},
+	}
+	expected := "" +
+		"ENVIRONMENT  TARGETS       DIFFERING  OBJECTS                           UNSAFE  CHECKED\n" +
+		"production   4             2          3 tables, 1 procedure             yes     2026-01-02 03:04:05 UTC\n" +
+		"staging      3 (1 failed)  0          -                                 no      2026-01-02 03:04:05 UTC\n" +
+		"dev          1             1          2 functions, 1 unsupported table  no      2026-01-02 03:04:05 UTC\n"
+	if actual := formatStatusTable(statuses); actual != expected {
+		t.Errorf("Unexpected result from formatStatusTable:\nexpected:\n%s\nfound:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Compare checksums of the filesystem and live schemas"
+	desc := "Computes a checksum of the normalized definition of every object, as well as " +
+		"an overall checksum, for each schema in the filesystem and for the corresponding " +
+		"schema on each database server. Each target is reported as matching or " +
+		"mismatched, along with any objects whose checksums differ. This provides a fast " +
+		"yes/no answer to whether drift exists, suitable for use in monitoring probes. " +
+		"No DDL is generated; use `skeema diff` to see how to resolve any mismatches.\n\n" +
+		"Checksums exclude each table's next AUTO_INCREMENT value, but are otherwise " +
+		"strict: cosmetic differences which `skeema diff` ignores by default, such as " +
+		"differing index order or partitioning clauses, are reported as mismatches. The " +
+		"overall checksum of a schema is the same value used as a target fingerprint in " +
+		"`skeema diff --save-plan` plan files.\n\n" +
+		"With --output-format=json, a JSON document is output for each target instead, " +
+		"one per line.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no environment " +
+		"name is supplied, the default is \"production\".\n\n" +
+		"An exit code of 0 will be returned if all checksums match; 1 if at least one " +
+		"target has a mismatch; or 2+ if an error occurred."
+
+	cmd := mybase.NewCommand("verify", summary, desc, VerifyHandler)
+	cmd.AddOption(mybase.StringOption("output-format", 0, "text", `Format of STDOUT output (valid values: "text", "json")`))
+	cmd.AddOption(mybase.BoolOption("all-objects", 0, false, "Also list the checksums of objects which match, rather than only mismatched objects"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+	clonePushOptionsForDriftCheck("verify")
+
+	// Options which only affect diff generation have no effect on checksums
+	for _, name := range []string{"exact-match", "compare-metadata", "lax-column-order", "append-columns", "lax-comments", "ignore-table-options", "partitioning"} {
+		if opt := cmd.Options()[name]; opt != nil {
+			opt.HiddenOnCLI = true
+		}
+	}
+}
+
+// VerifyHandler is the handler method for `skeema verify`
+func VerifyHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	format, err := dir.Config.GetEnum("output-format", "text", "json")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	limits, err := applier.ConcurrencyLimitsForDir(dir)
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	allObjects := dir.Config.GetBool("all-objects")
+
+	groups, skipCount := applier.TargetGroupsForDir(dir)
+	var results []*verifyResult
+	var m sync.Mutex
+	err = applier.NewScheduler(limits).Run(groups, func(t *applier.Target) error {
+		defer panicHandler()
+		result := verifyTarget(t, allObjects)
+		m.Lock()
+		results = append(results, result)
+		m.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Instance != results[j].Instance {
+			return results[i].Instance < results[j].Instance
+		}
+		return results[i].Schema < results[j].Schema
+	})
+	var mismatches int
+	for _, result := range results {
+		if result.Status == "error" {
+			skipCount++
+		} else if result.Status == "mismatch" {
+			mismatches++
+		}
+		if format == "json" {
+			if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+				return err
+			}
+		} else {
+			fmt.Print(result.String())
+		}
+	}
+	if skipCount > 0 {
+		return NewExitValue(CodeFatalError, "Unable to verify %s due to errors", countAndNoun(skipCount, "target", "targets"))
+	} else if mismatches > 0 {
+		log.Warnf("%s of %s had checksum mismatches", countAndNoun(mismatches, "target", "targets"), countAndNoun(len(results), "target", "targets"))
+		return NewExitValue(CodeDifferencesFound, "")
+	}
+	log.Infof("All checksums match for %s", countAndNoun(len(results), "target", "targets"))
+	return nil
+}
+
+// verifyResult describes the outcome of comparing checksums for one target.
+type verifyResult struct {
+	Instance   string               `json:"instance"`
+	Schema     string               `json:"schema"`
+	Status     string               `json:"status"` // "match", "mismatch", or "error"
+	FSChecksum string               `json:"fs_checksum,omitempty"`
+	DBChecksum string               `json:"db_checksum,omitempty"`
+	Objects    []verifyObjectResult `json:"objects,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// verifyObjectResult describes the checksums of a single object. A blank
+// checksum indicates the object does not exist on that side.
+type verifyObjectResult struct {
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Match      bool   `json:"match"`
+	FSChecksum string `json:"fs_checksum,omitempty"`
+	DBChecksum string `json:"db_checksum,omitempty"`
+}
+
+// verifyTarget compares checksums of the filesystem and live versions of t's
+// schema. Only mismatched objects are included in the result, unless
+// allObjects is true.
+func verifyTarget(t *applier.Target, allObjects bool) *verifyResult {
+	result := &verifyResult{
+		Instance: t.Instance.String(),
+		Schema:   t.SchemaName,
+	}
+	live, err := t.SchemaFromInstance()
+	if err != nil {
+		log.Errorf("Skipping %s: %s", t, err)
+		result.Status, result.Error = "error", err.Error()
+		return result
+	}
+	desired := t.SchemaFromDir()
+	compareSchemaChecksums(result, desired, live, allObjects)
+	return result
+}
+
+// compareSchemaChecksums populates result by comparing checksums of the
+// desired and live schemas. The live schema may be nil if it does not exist.
+func compareSchemaChecksums(result *verifyResult, desired, live *tengo.Schema, allObjects bool) {
+	result.FSChecksum = applier.SchemaChecksum(desired)
+	result.DBChecksum = applier.SchemaChecksum(live)
+	result.Status = "match"
+	if result.FSChecksum != result.DBChecksum {
+		result.Status = "mismatch"
+	}
+
+	fsChecksums, dbChecksums := applier.ObjectChecksums(desired), applier.ObjectChecksums(live)
+	keys := make([]tengo.ObjectKey, 0, len(fsChecksums)+len(dbChecksums))
+	for key := range fsChecksums {
+		keys = append(keys, key)
+	}
+	for key := range dbChecksums {
+		if _, ok := fsChecksums[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	for _, key := range keys {
+		obj := verifyObjectResult{
+			Type:       string(key.Type),
+			Name:       key.Name,
+			FSChecksum: fsChecksums[key],
+			DBChecksum: dbChecksums[key],
+		}
+		obj.Match = (obj.FSChecksum == obj.DBChecksum)
+		if !obj.Match || allObjects {
+			result.Objects = append(result.Objects, obj)
+		}
+	}
+}
+
+// String returns a human-readable description of the result, with one line for
+// the target and one additional indented line per listed object.
+func (result *verifyResult) String() string {
+	var b strings.Builder
+	target := result.Instance + " " + result.Schema
+	switch result.Status {
+	case "error":
+		fmt.Fprintf(&b, "ERROR     %s: %s\n", target, result.Error)
+		return b.String()
+	case "match":
+		fmt.Fprintf(&b, "OK        %s  %s\n", target, shortChecksum(result.FSChecksum))
+	default:
+		fmt.Fprintf(&b, "MISMATCH  %s  fs=%s db=%s\n", target, shortChecksum(result.FSChecksum), shortChecksum(result.DBChecksum))
+	}
+	for _, obj := range result.Objects {
+		key := tengo.ObjectKey{Type: tengo.ObjectType(obj.Type), Name: obj.Name}
+		switch {
+		case obj.Match:
+			fmt.Fprintf(&b, "          %s: %s\n", key, shortChecksum(obj.FSChecksum))
+		case obj.DBChecksum == "":
+			fmt.Fprintf(&b, "          %s: missing from database\n", key)
+		case obj.FSChecksum == "":
+			fmt.Fprintf(&b, "          %s: not in filesystem\n", key)
+		default:
+			fmt.Fprintf(&b, "          %s: fs=%s db=%s\n", key, shortChecksum(obj.FSChecksum), shortChecksum(obj.DBChecksum))
+		}
+	}
+	return b.String()
+}
+
+// shortChecksum abbreviates a checksum for display purposes.
+func shortChecksum(checksum string) string {
+	if checksum == "" {
+		return "(none)"
+	} else if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
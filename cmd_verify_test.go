@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestCompareSchemaChecksums(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.32")
+	newSchema := func(creates ...string) *tengo.Schema {
+		s := &tengo.Schema{Name: "product", CharSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}
+		for _, create := range creates {
+			table, err := tengo.ParseCreateTable(create, flavor, s.CharSet, s.Collation)
+			if err != nil {
+				t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+			}
+			s.Tables = append(s.Tables, table)
+		}
+		return s
+	}
+	users := "CREATE TABLE `users` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"
+	usersAutoInc := strings.Replace(users, "ENGINE=InnoDB", "ENGINE=InnoDB AUTO_INCREMENT=123", 1)
+	usersAltered := strings.Replace(users, "`id` int", "`id` bigint", 1)
+	posts := "CREATE TABLE `posts` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"
+	comments := strings.Replace(posts, "`posts`", "`comments`", 1)
+
+	// Differing next auto-increment value should not affect checksums
+	result := verifyResult{Instance: "db1:3306", Schema: "product"}
+	compareSchemaChecksums(&result, newSchema(users, posts), newSchema(usersAutoInc, posts), false)
+	if result.Status != "match" || result.FSChecksum == "" || result.FSChecksum != result.DBChecksum || len(result.Objects) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if output := result.String(); output != "OK        db1:3306 product  "+result.FSChecksum[:12]+"\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+	result = verifyResult{}
+	compareSchemaChecksums(&result, newSchema(users, posts), newSchema(usersAutoInc, posts), true)
+	if result.Status != "match" || len(result.Objects) != 3 {
+		t.Errorf("Expected all objects to be listed with allObjects=true, instead found %+v", result.Objects)
+	}
+
+	// Altered, missing, and extra tables
+	result = verifyResult{Instance: "db1:3306", Schema: "product"}
+	compareSchemaChecksums(&result, newSchema(users, posts), newSchema(usersAltered, comments), false)
+	if result.Status != "mismatch" || len(result.Objects) != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	lines := strings.Split(strings.TrimSpace(result.String()), "\n")
+	expected := []string{
+		"MISMATCH  db1:3306 product  fs=" + result.FSChecksum[:12] + " db=" + result.DBChecksum[:12],
+		"          table `comments`: not in filesystem",
+		"          table `posts`: missing from database",
+		"          table `users`: fs=" + result.Objects[2].FSChecksum[:12] + " db=" + result.Objects[2].DBChecksum[:12],
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected output:\n%s", result.String())
+	}
+
+	// Schema not existing on the database side; also differing default collation
+	result = verifyResult{}
+	compareSchemaChecksums(&result, newSchema(users), nil, false)
+	if result.Status != "mismatch" || result.DBChecksum != "" || len(result.Objects) != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	desired, live := newSchema(), newSchema()
+	live.Collation = "utf8mb4_general_ci"
+	result = verifyResult{}
+	compareSchemaChecksums(&result, desired, live, false)
+	if result.Status != "mismatch" || len(result.Objects) != 1 || result.Objects[0].Type != string(tengo.ObjectTypeDatabase) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Continuously monitor DB servers for differences from the filesystem"
+	desc := "Runs continuously, periodically checking each configured environment for " +
+		"differences between its database servers and the filesystem, in the same manner " +
+		"as `skeema status`. An initial check is performed immediately; subsequent " +
+		"checks occur every --interval, or at the times matching the cron expression " +
+		"in --schedule.\n\n" +
+		"Whenever differences first appear in an environment, change, or are resolved, " +
+		"an alert is logged. Alerts may also be sent as JSON POST requests to " +
+		"--webhook-url. With --metrics-listen, the most recent results are exposed " +
+		"in Prometheus format at /metrics. With --exit-on-drift, the command exits " +
+		"with code 1 as soon as differences are found, which is useful when run " +
+		"under a supervisor that alerts on exit status.\n\n" +
+		"The filesystem is re-read upon each check, so changes to *.sql files are " +
+		"picked up automatically. You may optionally pass an environment name as a " +
+		"command-line arg to only monitor that environment; otherwise, all environments " +
+		"configuring a host are monitored, as with `skeema status`."
+
+	cmd := mybase.NewCommand("watch", summary, desc, WatchHandler)
+	cmd.AddOptions("monitoring",
+		mybase.StringOption("interval", 0, "5m", "Duration between checks, e.g. \"30s\" or \"1h\""),
+		mybase.StringOption("schedule", 0, "", "Cron expression for when to perform checks, instead of --interval"),
+		mybase.StringOption("webhook-url", 0, "", "POST a JSON document to this URL whenever differences appear, change, or are resolved"),
+		mybase.StringOption("metrics-listen", 0, "", "Serve Prometheus metrics about differences at /metrics on this address, e.g. \":9273\""),
+		mybase.BoolOption("exit-on-drift", 0, false, "Exit with code 1 as soon as any differences are found"),
+	)
+	cmd.AddArg("environment", "", false)
+	CommandSuite.AddSubCommand(cmd)
+	clonePushOptionsForDriftCheck("watch")
+}
+
+// WatchHandler is the handler method for `skeema watch`
+func WatchHandler(cfg *mybase.Config) error {
+	interval, err := time.ParseDuration(cfg.Get("interval"))
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if interval <= 0 {
+		return NewExitValue(CodeBadConfig, "Option interval must be a positive duration")
+	}
+	var schedule *util.CronSchedule
+	if spec := cfg.Get("schedule"); spec != "" {
+		if schedule, err = util.ParseCronSchedule(spec); err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+	}
+	environments, err := prepareDriftCheck(cfg)
+	if err != nil {
+		return err
+	}
+
+	watcher := newDriftWatcher(cfg.Get("webhook-url"))
+	if addr := cfg.Get("metrics-listen"); addr != "" {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return NewExitValue(CodeBadConfig, "Unable to listen for metrics requests: %s", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", watcher)
+		go func() {
+			if err := http.Serve(listener, mux); err != nil {
+				log.Errorf("Metrics server stopped: %s", err)
+			}
+		}()
+	}
+
+	for {
+		var statuses []*envStatus
+		for _, env := range environments {
+			status, err := checkEnvironment(cfg, env)
+			if err != nil {
+				log.Errorf("Unable to check environment %s: %s", env, err)
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+		for _, alert := range watcher.record(statuses) {
+			log.Warn(alert.Text)
+			if err := watcher.sendWebhook(alert); err != nil {
+				log.Errorf("Unable to send alert to webhook: %s", err)
+			}
+		}
+		if cfg.GetBool("exit-on-drift") {
+			for _, status := range statuses {
+				if status.DriftCount > 0 {
+					return NewExitValue(CodeDifferencesFound, "")
+				}
+			}
+		}
+
+		next := time.Now().Add(interval)
+		if schedule != nil {
+			next = schedule.Next(time.Now())
+		}
+		log.Debugf("Next check at %s", next.Format(time.RFC3339))
+		time.Sleep(time.Until(next))
+	}
+}
+
+// driftAlert is the JSON document sent to --webhook-url whenever an
+// environment's differences appear, change, or are resolved.
+type driftAlert struct {
+	Environment      string         `json:"environment"`
+	Event            string         `json:"event"` // "drift" or "resolved"
+	DifferingTargets int            `json:"differing_targets"`
+	Objects          map[string]int `json:"objects"`
+	Unsupported      int            `json:"unsupported_tables"`
+	Unsafe           bool           `json:"unsafe"`
+	CheckedAt        time.Time      `json:"checked_at"`
+	Text             string         `json:"text"` // human-readable summary, for chat webhooks
+}
+
+// driftWatcher tracks the most recent envStatus of each environment, in order
+// to determine when alerts are needed and to serve metrics.
+type driftWatcher struct {
+	webhookURL string
+	client     *http.Client
+	latest     map[string]*envStatus
+	order      []string // environment names, in order first checked
+	m          sync.Mutex
+}
+
+func newDriftWatcher(webhookURL string) *driftWatcher {
+	return &driftWatcher{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		latest:     make(map[string]*envStatus),
+	}
+}
+
+// record stores the supplied statuses, returning an alert for each environment
+// whose differences have appeared, changed, or been resolved since its previous
+// check. Environments with errors are alerted based on the targets which could
+// be checked.
+func (w *driftWatcher) record(statuses []*envStatus) (alerts []driftAlert) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	for _, status := range statuses {
+		prev, seen := w.latest[status.Environment]
+		if !seen {
+			w.order = append(w.order, status.Environment)
+		}
+		w.latest[status.Environment] = status
+		if status.DriftCount > 0 && (prev == nil || prev.DriftCount == 0 || driftSignature(prev) != driftSignature(status)) {
+			alerts = append(alerts, newDriftAlert(status, "drift"))
+		} else if status.DriftCount == 0 && prev != nil && prev.DriftCount > 0 {
+			alerts = append(alerts, newDriftAlert(status, "resolved"))
+		}
+	}
+	return alerts
+}
+
+// driftSignature returns a string summarizing status's differences, for
+// purposes of determining whether they have changed between checks.
+func driftSignature(status *envStatus) string {
+	return fmt.Sprintf("%d %s %t", status.DriftCount, status.objectSummary(), status.Unsafe)
+}
+
+// newDriftAlert returns an alert describing status. The event should be
+// "drift" or "resolved".
+func newDriftAlert(status *envStatus, event string) driftAlert {
+	alert := driftAlert{
+		Environment:      status.Environment,
+		Event:            event,
+		DifferingTargets: status.DriftCount,
+		Objects:          make(map[string]int, len(status.ObjectCounts)),
+		Unsupported:      status.Unsupported,
+		Unsafe:           status.Unsafe,
+		CheckedAt:        status.CheckedAt.UTC(),
+	}
+	for objType, count := range status.ObjectCounts {
+		alert.Objects[string(objType)] = count
+	}
+	if event == "resolved" {
+		alert.Text = fmt.Sprintf("Schema drift resolved in environment %s", status.Environment)
+	} else {
+		alert.Text = fmt.Sprintf("Schema drift detected in environment %s: %s (%s)",
+			status.Environment, countAndNoun(status.DriftCount, "target differs", "targets differ"), status.objectSummary())
+		if status.Unsafe {
+			alert.Text += ", including unsafe changes"
+		}
+	}
+	return alert
+}
+
+// sendWebhook POSTs alert to the watcher's webhook URL, if one is configured.
+func (w *driftWatcher) sendWebhook(alert driftAlert) error {
+	if w.webhookURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, w.webhookURL)
+	}
+	return nil
+}
+
+// ServeHTTP responds with metrics about the most recent check of each
+// environment, in Prometheus text exposition format.
+func (w *driftWatcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.m.Lock()
+	statuses := make([]*envStatus, 0, len(w.order))
+	for _, env := range w.order {
+		statuses = append(statuses, w.latest[env])
+	}
+	w.m.Unlock()
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	rw.Write([]byte(formatDriftMetrics(statuses)))
+}
+
+// formatDriftMetrics returns Prometheus metrics describing statuses.
+func formatDriftMetrics(statuses []*envStatus) string {
+	var b strings.Builder
+	metric := func(name, help string, value func(status *envStatus) string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "%s{environment=%q} %s\n", name, status.Environment, value(status))
+		}
+	}
+	metric("skeema_drift_differing_targets", "Number of targets with differences from the filesystem.", func(status *envStatus) string {
+		return fmt.Sprintf("%d", status.DriftCount)
+	})
+	metric("skeema_drift_unsafe", "Whether resolving the differences would require unsafe changes.", func(status *envStatus) string {
+		if status.Unsafe {
+			return "1"
+		}
+		return "0"
+	})
+	metric("skeema_drift_unsupported_tables", "Number of tables with differences that Skeema cannot generate.", func(status *envStatus) string {
+		return fmt.Sprintf("%d", status.Unsupported)
+	})
+	metric("skeema_drift_check_errors", "Number of targets which could not be checked due to errors.", func(status *envStatus) string {
+		return fmt.Sprintf("%d", status.ErrorCount)
+	})
+	metric("skeema_drift_last_check_timestamp_seconds", "Time of the most recent check, in seconds since the Unix epoch.", func(status *envStatus) string {
+		return fmt.Sprintf("%d", status.CheckedAt.Unix())
+	})
+
+	name := "skeema_drift_objects"
+	fmt.Fprintf(&b, "# HELP %s Number of objects with differences from the filesystem, by type.\n# TYPE %s gauge\n", name, name)
+	for _, status := range statuses {
+		for _, objType := range []tengo.ObjectType{tengo.ObjectTypeDatabase, tengo.ObjectTypeTable, tengo.ObjectTypeProc, tengo.ObjectTypeFunc} {
+			fmt.Fprintf(&b, "%s{environment=%q,type=%q} %d\n", name, status.Environment, objType, status.ObjectCounts[objType])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestDriftWatcher(t *testing.T) {
+	var received []driftAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert driftAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, alert)
+	}))
+	defer server.Close()
+	watcher := newDriftWatcher(server.URL)
+
+	checkedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clean := func(env string) *envStatus {
+		return &envStatus{Environment: env, TargetCount: 2, ObjectCounts: map[tengo.ObjectType]int{}, CheckedAt: checkedAt}
+	}
+	drifted := func(env string, tables int, unsafe bool) *envStatus {
+		status := clean(env)
+		status.DriftCount = 1
+		status.ObjectCounts[tengo.ObjectTypeTable] = tables
+		status.Unsafe = unsafe
+		return status
+	}
+
+	// Each step lists statuses from one round of checks, and the expected
+	// events of the resulting alerts
+	steps := []struct {
+		statuses []*envStatus
+		expected []string
+	}{
+		{[]*envStatus{clean("production"), drifted("staging", 1, false)}, []string{"staging drift"}},
+		{[]*envStatus{clean("production"), drifted("staging", 1, false)}, []string{}},
+		{[]*envStatus{drifted("production", 2, true), drifted("staging", 1, false)}, []string{"production drift"}},
+		{[]*envStatus{drifted("production", 2, true), drifted("staging", 3, false)}, []string{"staging drift"}},
+		{[]*envStatus{clean("production"), clean("staging")}, []string{"production resolved", "staging resolved"}},
+		{[]*envStatus{clean("production"), clean("staging")}, []string{}},
+	}
+	for n, step := range steps {
+		alerts := watcher.record(step.statuses)
+		actual := []string{}
+		for _, alert := range alerts {
+			actual = append(actual, alert.Environment+" "+alert.Event)
+		}
+		if strings.Join(actual, ",") != strings.Join(step.expected, ",") {
+			t.Errorf("Step %d: expected alerts %v, instead found %v", n, step.expected, actual)
+		}
+	}
+
+	alert := newDriftAlert(drifted("production", 2, true), "drift")
+	expectedText := "Schema drift detected in environment production: 1 target differs (2 tables), including unsafe changes"
+	if alert.Text != expectedText {
+		t.Errorf("Unexpected alert text: %q", alert.Text)
+	}
+	if err := watcher.sendWebhook(alert); err != nil {
+		t.Fatalf("Unexpected error from sendWebhook: %v", err)
+	} else if len(received) != 1 || received[0].Environment != "production" || received[0].Objects["table"] != 2 || !received[0].Unsafe || !received[0].CheckedAt.Equal(checkedAt) {
+		t.Errorf("Unexpected alerts received by webhook: %+v", received)
+	}
+	watcher.webhookURL = server.URL + "/missing\x7f"
+	if err := watcher.sendWebhook(alert); err == nil {
+		t.Error("Expected error from sendWebhook with invalid URL, but err was nil")
+	}
+
+	// Metrics should reflect the most recent round of checks
+	watcher.record([]*envStatus{drifted("production", 2, true)})
+	rec := httptest.NewRecorder()
+	watcher.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	metrics := rec.Body.String()
+	expectedLines := []string{
+		"# TYPE skeema_drift_differing_targets gauge\n",
+		`skeema_drift_differing_targets{environment="production"} 1` + "\n",
+		`skeema_drift_differing_targets{environment="staging"} 0` + "\n",
+		`skeema_drift_unsafe{environment="production"} 1` + "\n",
+		`skeema_drift_objects{environment="production",type="table"} 2` + "\n",
+		`skeema_drift_objects{environment="staging",type="procedure"} 0` + "\n",
+		`skeema_drift_last_check_timestamp_seconds{environment="staging"} 1767323045` + "\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("Expected metrics to contain %q, but it did not. Full output:\n%s", line, metrics)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package applier
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// algorithmFallback tracks the chain of ALGORITHM clauses attempted for an
+// ALTER TABLE when alter-algorithm=auto is configured.
+type algorithmFallback struct {
+	algorithms []string                 // ALGORITHM clause values to attempt, in order
+	mods       tengo.StatementModifiers // mods for generating each attempt's statement
+	used       string                   // algorithm (or OSC tool name) that succeeded
+}
+
+// useAlgorithmFallback returns true if config specifies alter-algorithm=auto
+// and diff is an ALTER TABLE which is not being run by ddl-wrapper. Since
+// ddl-wrapper shells out for all DDL, the fallback chain cannot be used with
+// it.
+func useAlgorithmFallback(config *mybase.Config, diff tengo.ObjectDiff) bool {
+	if diff.ObjectKey().Type != tengo.ObjectTypeTable || diff.DiffType() != tengo.DiffTypeAlter || config.Changed("ddl-wrapper") {
+		return false
+	}
+	return strings.EqualFold(config.Get("alter-algorithm"), "auto")
+}
+
+// newAlgorithmFallback returns an algorithmFallback for the supplied flavor.
+// ALGORITHM=INSTANT is only attempted on flavors which support it. If handOff
+// is true, only ALGORITHM=INSTANT is attempted, before handing off to the
+// alter-wrapper or osc-tool: an ALGORITHM=INPLACE table rebuild may still take
+// hours on a large table, which is precisely what those tools avoid.
+func newAlgorithmFallback(mods tengo.StatementModifiers, handOff bool) *algorithmFallback {
+	var algorithms []string
+	if mods.Flavor.MinMySQL(8, 0, 12) || mods.Flavor.MinMariaDB(10, 3) {
+		algorithms = append(algorithms, "instant")
+	}
+	if !handOff {
+		algorithms = append(algorithms, "inplace", "copy")
+	}
+	return &algorithmFallback{
+		algorithms: algorithms,
+		mods:       mods,
+	}
+}
+
+// statement returns the ALTER TABLE for td using the supplied algorithm.
+// ALGORITHM=INSTANT does not permit any LOCK clause besides the default, so
+// the LOCK clause is omitted in that case.
+func (af *algorithmFallback) statement(td *tengo.TableDiff, algorithm string) (string, error) {
+	mods := af.mods
+	mods.AlgorithmClause = algorithm
+	if algorithm == "instant" {
+		mods.LockClause = ""
+	}
+	return td.Statement(mods)
+}
+
+// executeWithFallback runs ddl using each algorithm in its fallback chain,
+// moving on to the next one whenever the database server indicates that the
+// requested algorithm is not supported for the ALTER. If all algorithms are
+// unsupported (or the chain is empty), and an alter-wrapper or osc-tool is
+// configured, execution is handed off to it; otherwise, the error from the
+// final attempt is returned.
+func (ddl *DDLStatement) executeWithFallback() error {
+	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, ddl.connectParams)
+	if err != nil {
+		return err
+	}
+	key := ddl.diff.ObjectKey()
+	var lastErr error
+	td := ddl.diff.(*tengo.TableDiff)
+	for _, algorithm := range ddl.fallback.algorithms {
+		stmt, err := ddl.fallback.statement(td, algorithm)
+		if err != nil {
+			return err
+		}
+		if err = ddl.execSQL(db, stmt); err == nil {
+			ddl.fallback.used = strings.ToUpper(algorithm)
+			log.Infof("Altered %s using ALGORITHM=%s", key, ddl.fallback.used)
+			return nil
+		} else if !tengo.IsAlterAlgorithmError(err) {
+			return err
+		}
+		log.Infof("ALGORITHM=%s not supported for %s: %s", strings.ToUpper(algorithm), key, err)
+		lastErr = err
+	}
+
+	if ddl.osc != nil {
+		log.Infof("Using %s for %s", ddl.osc.name(), key)
+		err = ddl.runOSC()
+		ddl.fallback.used = ddl.osc.name()
+	} else if ddl.shellOut != nil {
+		log.Infof("Using alter-wrapper for %s", key)
+		err = ddl.shellOut.Run()
+		ddl.fallback.used = "alter-wrapper"
+	} else {
+		err = lastErr
+	}
+	if err != nil {
+		ddl.fallback.used = ""
+	}
+	return err
+}
+
+// AlgorithmUsed returns the ALGORITHM clause value that was used to
+// successfully execute ddl with alter-algorithm=auto, or the name of the
+// external tool that was used if all algorithms were unsupported. A blank
+// string is returned if ddl has not been executed successfully, or if
+// alter-algorithm=auto was not in use.
+func (ddl *DDLStatement) AlgorithmUsed() string {
+	if ddl.fallback == nil {
+		return ""
+	}
+	return ddl.fallback.used
+}
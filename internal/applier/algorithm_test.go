@@ -0,0 +1,148 @@
+package applier
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestAlgorithmFallback(t *testing.T) {
+	cases := []struct {
+		flavor   string
+		handOff  bool
+		expected []string
+	}{
+		{"mysql:8.0.30", false, []string{"instant", "inplace", "copy"}},
+		{"mysql:8.0.11", false, []string{"inplace", "copy"}},
+		{"mysql:5.7", true, nil},
+		{"mariadb:10.3", true, []string{"instant"}},
+		{"mariadb:10.2", false, []string{"inplace", "copy"}},
+	}
+	for _, c := range cases {
+		mods := tengo.StatementModifiers{Flavor: tengo.ParseFlavor(c.flavor)}
+		if af := newAlgorithmFallback(mods, c.handOff); !slices.Equal(af.algorithms, c.expected) {
+			t.Errorf("Unexpected algorithm chain for flavor=%s handOff=%t: expected %v, found %v", c.flavor, c.handOff, c.expected, af.algorithms)
+		}
+	}
+
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	makeTable := func(cols ...*tengo.Column) *tengo.Table {
+		table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: cols}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	td := tengo.NewAlterTable(makeTable(idCol), makeTable(idCol, nameCol))
+	af := newAlgorithmFallback(tengo.StatementModifiers{LockClause: "none"}, false)
+	expected := map[string]string{
+		"instant": "ALTER TABLE `users` ALGORITHM=INSTANT, ADD COLUMN `name` varchar(30) DEFAULT NULL",
+		"inplace": "ALTER TABLE `users` ALGORITHM=INPLACE, LOCK=NONE, ADD COLUMN `name` varchar(30) DEFAULT NULL",
+	}
+	for algorithm, expectedStmt := range expected {
+		if stmt, err := af.statement(td, algorithm); err != nil || stmt != expectedStmt {
+			t.Errorf("Unexpected return from statement(%q):\nexpected: %s\nfound:    %s (err=%v)", algorithm, expectedStmt, stmt, err)
+		}
+	}
+
+	cfg := mybase.SimpleConfig(map[string]string{"alter-algorithm": "AUTO", "ddl-wrapper": ""})
+	if !useAlgorithmFallback(cfg, td) {
+		t.Error("Expected useAlgorithmFallback to return true for ALTER TABLE, but it returned false")
+	}
+	if useAlgorithmFallback(cfg, tengo.NewCreateTable(td.To)) {
+		t.Error("Expected useAlgorithmFallback to return false for CREATE TABLE, but it returned true")
+	}
+	cfg = mybase.SimpleConfig(map[string]string{"alter-algorithm": "auto", "ddl-wrapper": "/bin/echo {DDL}"})
+	if useAlgorithmFallback(cfg, td) {
+		t.Error("Expected useAlgorithmFallback to return false with ddl-wrapper, but it returned true")
+	}
+}
+
+func (s ApplierIntegrationSuite) TestExecuteWithFallback(t *testing.T) {
+	flavor := s.d[0].Flavor()
+	if flavor.IsMySQL(5, 5) {
+		t.Skip("ALGORITHM clause not supported in MySQL 5.5")
+	}
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	exec := func(query string) {
+		t.Helper()
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	getSchema := func() *tengo.Schema {
+		t.Helper()
+		schema, err := s.d[0].Schema("product")
+		if err != nil {
+			t.Fatalf("Unexpected error from Schema: %s", err)
+		}
+		return schema
+	}
+	exec("CREATE TABLE fallbacktest (id int unsigned NOT NULL PRIMARY KEY, name varchar(30))")
+	from := getSchema()
+
+	// Changing a column's data type cannot be done with INSTANT or INPLACE, so
+	// this should fall back to COPY. No LOCK clause is used, since LOCK=NONE is
+	// incompatible with ALGORITHM=COPY.
+	exec("ALTER TABLE fallbacktest MODIFY COLUMN id bigint unsigned NOT NULL")
+	to := getSchema()
+	exec("ALTER TABLE fallbacktest MODIFY COLUMN id int unsigned NOT NULL")
+
+	cfg := mybase.SimpleConfig(map[string]string{
+		"alter-algorithm":        "auto",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "0",
+		"alter-progress":         "",
+		"alter-progress-stream":  "",
+		"init-sql":               "",
+		"mdl-report-after":       "0",
+		"mdl-kill-idle":          "0",
+		"mdl-kill-users":         "",
+		"statement-timeout":      "0",
+		"wsrep-osu-method":       "",
+		"ddl-wrapper":            "",
+		"osc-tool":               "none",
+		"safe-below-size":        "0",
+		"large-table-size":       "0",
+		"require-osc-size":       "0",
+		"require-osc-rows":       "0",
+		"table-stats":            "false",
+		"foreign-key-checks":     "",
+	})
+	target := &Target{
+		Instance:      s.d[0].Instance,
+		Dir:           &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName:    "product",
+		DesiredSchema: &workspace.Schema{Schema: to},
+	}
+	var ddl *DDLStatement
+	for _, diff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		if diff.ObjectKey().Name == "fallbacktest" {
+			mods := tengo.StatementModifiers{Flavor: flavor, AllowUnsafe: true}
+			if ddl, err = NewDDLStatement(diff, mods, target); err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %s", err)
+			}
+		}
+	}
+	if ddl == nil || ddl.fallback == nil {
+		t.Fatalf("Expected DDLStatement using algorithm fallback, instead found %+v", ddl)
+	}
+	if err := ddl.Execute(); err != nil {
+		t.Fatalf("Unexpected error from Execute: %s", err)
+	} else if ddl.AlgorithmUsed() != "COPY" {
+		t.Errorf("Expected ALGORITHM=COPY to be used, instead found %q", ddl.AlgorithmUsed())
+	}
+	if table := getSchema().Table("fallbacktest"); table.Columns[0].Type.Base != "bigint" {
+		t.Errorf("Expected ALTER to change column type, instead found %s", table.Columns[0].Type)
+	}
+}
@@ -3,14 +3,18 @@
 package applier
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/linter"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/tracing"
 	"github.com/skeema/skeema/internal/util"
 )
 
@@ -51,28 +55,163 @@ type Plan struct {
 	DiffKeys    []tengo.ObjectKey          // objects with non-blank supported schema differences
 	Unsupported map[tengo.ObjectKey]string // map of object key => details on why unsupported
 	Unsafe      []UnsafeStatement
+	Protected   []UnsafeStatement   // statements blocked by protect-table or protect-column
+	HighImpact  []UnsafeStatement   // statements exceeding max-impact, require-osc-size, or require-osc-rows
+	DataLoss    []UnsafeStatement   // statements affecting existing rows, only populated if data-check=block
+	Fingerprint string              // fingerprint of the target's schema at the time of planning
+	Rollback    []RollbackStatement // only populated if the save-rollback option is set
+	Results     []StatementResult   // outcome of each statement; only populated by Run if not a dry-run
+}
+
+// StatementResult describes the outcome of executing a single statement in a
+// Plan.
+type StatementResult struct {
+	Executed bool
+	Err      error
+	Duration time.Duration // time spent executing, including any retries
 }
 
 // Run prints each statement in the plan, and also executes them if the Target's
 // configuration indicates that this is not a dry-run.
 func (plan *Plan) Run(printer Printer) (skipCount int) {
 	dryRun := plan.Target.Dir.Config.GetBool("dry-run")
+	var j *journal
+	var hist *history
+	var h *hooks
+	var throttler *lagThrottler
+	var retries *retryPolicy
+	var wsrep *wsrepMonitor
+	var cp *checkpoint
+	approver, _ := printer.(Approver)
+	if !dryRun && approver != nil && len(plan.Statements) > 0 {
+		switch approver.ApproveTarget(plan) {
+		case ApprovalNo, ApprovalQuit:
+			plan.Target.logEntry().Warnf("Skipping %d operations for %s: not approved", len(plan.Statements), plan.Target)
+			return len(plan.Statements)
+		case ApprovalAll:
+			approver = nil
+		}
+	}
+	var declined int
+	if !dryRun {
+		plan.Results = make([]StatementResult, len(plan.Statements))
+	}
+	if !dryRun && len(plan.Statements) > 0 {
+		lock, err := acquireTargetLock(plan.Target)
+		if err != nil {
+			plan.Target.logEntry().Errorf("Skipping %d operations for %s: %s", len(plan.Statements), plan.Target, err)
+			return len(plan.Statements)
+		}
+		defer lock.release()
+		if plan.Target.Dir.Config.GetBool("verify-writable") || plan.Target.Dir.Config.GetBool("discover-primary") {
+			if err := verifyWritable(plan.Target.Instance); err != nil {
+				plan.Target.logEntry().Errorf("Skipping %d operations for %s: %s", len(plan.Statements), plan.Target, err)
+				return len(plan.Statements)
+			}
+		}
+		h = newHooks(plan.Target, len(plan.Statements))
+		if err := h.beforePush(); err != nil {
+			plan.Target.logEntry().Errorf("Skipping %d operations for %s: %s", len(plan.Statements), plan.Target, err)
+			return len(plan.Statements)
+		}
+		j, err = newJournal(plan.Target)
+		if err == nil {
+			hist, err = newHistory(plan.Target)
+		}
+		if err == nil {
+			throttler, err = newLagThrottler(plan.Target.Dir.Config, "max-replica-lag", "replicas")
+		}
+		if err == nil {
+			retries, err = newRetryPolicy(plan.Target.Dir.Config)
+		}
+		if err == nil {
+			wsrep, err = newWsrepMonitor(plan.Target)
+		}
+		if err == nil {
+			cp, err = openCheckpoint(plan.Target.Dir.Config)
+		}
+		if err != nil {
+			plan.Target.logEntry().Errorf("Skipping %d operations for %s: %s", len(plan.Statements), plan.Target, err)
+			h.afterPush(err)
+			return len(plan.Statements)
+		}
+	}
 	for i, stmt := range plan.Statements {
 		printer.Print(stmt)
+		if !dryRun && approver != nil {
+			switch approver.ApproveStatement(stmt) {
+			case ApprovalNo:
+				plan.Target.logEntry().Warnf("Skipping statement for %s: not approved", plan.Target)
+				declined++
+				continue
+			case ApprovalAll:
+				approver = nil
+			case ApprovalQuit:
+				skipCount = len(plan.Statements) - i + declined
+				plan.Target.logEntry().Warnf("Skipping %d operations for %s: not approved", skipCount, plan.Target)
+				h.afterPush(errors.New("push halted by operator"))
+				return skipCount
+			}
+		}
 		if !dryRun {
-			if err := stmt.Execute(); err != nil {
-				log.Errorf("Error running SQL statement on %s: %s\nFull SQL statement: %s%s", plan.Target, err, stmt.Statement(), stmt.ClientState().Delimiter)
+			err := throttler.wait(plan.Target.Instance, "push")
+			if err == nil {
+				err = wsrep.wait(plan.Target)
+			}
+			if err == nil {
+				err = h.beforeStatement(stmt)
+			}
+			if err == nil {
+				start := time.Now()
+				span := tracing.StartKind(plan.Target.span, "execute statement", tracing.KindClient,
+					tracing.String("db.system", "mysql"),
+					tracing.String("db.name", plan.Target.SchemaName),
+					tracing.String("db.statement", stmt.Statement()),
+				)
+				err = retries.run(plan.Target, stmt, func() error {
+					if j != nil {
+						return j.execute(stmt)
+					}
+					return stmt.Execute()
+				})
+				span.End(err)
+				plan.Results[i].Duration = time.Since(start)
+				entry := plan.Target.statementLogEntry(stmt).WithField("duration", plan.Results[i].Duration.Seconds())
+				if hist != nil {
+					hist.record(stmt, start, err)
+				}
+				h.afterStatement(stmt, err)
+				plan.Results[i].Executed = (err == nil)
+				if err == nil {
+					cp.record(plan.Target, stmt)
+					entry.Debugf("Executed statement on %s in %s", plan.Target, plan.Results[i].Duration.Round(time.Millisecond))
+				} else {
+					entry.Errorf("Error running SQL statement on %s: %s\nFull SQL statement: %s%s", plan.Target, err, stmt.Statement(), stmt.ClientState().Delimiter)
+				}
+			} else {
+				log.Error(err)
+			}
+			if err != nil {
+				plan.Results[i].Err = err
 				skipCount = len(plan.Statements) - i
 				if skipCount > 1 {
-					log.Warnf("Skipping %d additional operations for %s due to previous error", skipCount-1, plan.Target)
+					plan.Target.logEntry().Warnf("Skipping %d additional operations for %s due to previous error", skipCount-1, plan.Target)
 				}
-				return skipCount
+				h.afterPush(err)
+				return skipCount + declined
 			}
 		}
 	}
 	if printerFinisher, ok := printer.(Finisher); ok && len(plan.Statements) > 0 {
 		printerFinisher.Finish(plan.Target)
 	}
+	if h != nil {
+		h.afterPush(nil)
+	}
+	if declined > 0 {
+		return declined
+	}
+	cp.finish(plan.Target)
 	return 0
 }
 
@@ -92,9 +231,10 @@ func (plan *Plan) LintModifiedObjects() (*linter.Result, error) {
 // Result stores the result of applying an individual target, or a combined
 // summary of multiple targets.
 type Result struct {
-	Differences      bool
-	SkipCount        int
-	UnsupportedCount int
+	Differences          bool
+	SkipCount            int
+	UnsupportedCount     int
+	ReplicaMismatchCount int // replicas not matching the desired schema after push, with verify-replicas
 }
 
 // Merge modifies the receiver to include the sub-totals from the supplied arg.
@@ -102,6 +242,7 @@ func (r *Result) Merge(other Result) {
 	r.Differences = r.Differences || other.Differences
 	r.SkipCount += other.SkipCount
 	r.UnsupportedCount += other.UnsupportedCount
+	r.ReplicaMismatchCount += other.ReplicaMismatchCount
 }
 
 // Error returns an error with a message indicating the number of problems
@@ -128,29 +269,58 @@ func (r Result) Error() error {
 // ApplyTarget generates the diff for the supplied target, prints the resulting
 // SQL, and executes the SQL if this isn't a dry-run.
 func ApplyTarget(t *Target, printer Printer) (Result, error) {
-	var result Result
+	t.span = tracing.Start(nil, "apply target",
+		tracing.String("skeema.instance", t.Instance.String()),
+		tracing.String("db.name", t.SchemaName),
+		tracing.String("skeema.dir", t.Dir.RelPath()),
+		tracing.Bool("skeema.dry_run", t.Dir.Config.GetBool("dry-run")),
+	)
+	result, plan, err := applyTarget(t, printer)
+	t.span.SetAttributes(tracing.Int("skeema.skip_count", int64(result.SkipCount)))
+	t.span.End(err)
+	if reporter, ok := printer.(ResultReporter); ok {
+		reporter.ReportResult(t, plan, result, err)
+	}
+	return result, err
+}
+
+// applyTarget implements ApplyTarget, additionally returning the target's
+// plan, which is nil if an error occurred prior to planning.
+func applyTarget(t *Target, printer Printer) (result Result, plan *Plan, err error) {
+	start := time.Now()
 
+	// With --resume, skip targets which were completed prior to the interruption
+	cp, err := openCheckpoint(t.Dir.Config)
+	if err != nil {
+		return result, plan, ConfigError(err.Error())
+	} else if t.Dir.Config.GetBool("resume") && cp.done(t) {
+		t.logEntry().Infof("%s: already completed by the resumed push; skipping\n", t)
+		return result, plan, nil
+	}
+
+	span := tracing.Start(t.span, "introspect schema")
 	schemaFromInstance, err := t.SchemaFromInstance()
+	span.End(err)
 	if err != nil {
 		result.SkipCount++
-		log.Errorf("Skipping %s schema %s for %s: %s\n", t.Instance, t.SchemaName, t.Dir, err)
-		return result, err
+		t.logEntry().Errorf("Skipping %s schema %s for %s: %s\n", t.Instance, t.SchemaName, t.Dir, err)
+		return result, plan, err
 	}
 	schemaFromDir := t.SchemaFromDir()
 
 	if t.Dir.Config.GetBool("dry-run") {
-		log.Infof("Generating diff of %s vs %s%c*.sql", t, t.Dir, os.PathSeparator)
+		t.logEntry().Infof("Generating diff of %s vs %s%c*.sql", t, t.Dir, os.PathSeparator)
 	} else {
-		log.Infof("Pushing changes from %s%c*.sql to %s", t.Dir, os.PathSeparator, t)
+		t.logEntry().Infof("Pushing changes from %s%c*.sql to %s", t.Dir, os.PathSeparator, t)
 	}
 	if len(t.Dir.UnparsedStatements) > 0 {
-		log.Warnf("Ignoring %d unsupported or unparseable statements found in this directory's *.sql files; run `skeema lint` for more info", len(t.Dir.UnparsedStatements))
+		t.logEntry().Warnf("Ignoring %d unsupported or unparseable statements found in this directory's *.sql files; run `skeema lint` for more info", len(t.Dir.UnparsedStatements))
 	}
 
 	// Obtain StatementModifiers based on the dir's config
 	mods, err := StatementModifiersForDir(t.Dir)
 	if err != nil {
-		return result, ConfigError(err.Error())
+		return result, plan, ConfigError(err.Error())
 	}
 	mods.Flavor = t.Instance.Flavor()
 	if mods.Partitioning == tengo.PartitioningRemove {
@@ -162,20 +332,27 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 		schemaFromDir.StripTablePartitioning(mods.Flavor)
 	}
 
+	span = tracing.Start(t.span, "compute diff")
 	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
-	plan, err := CreatePlanForTarget(t, diff, mods)
+	plan, err = CreatePlanForTarget(t, diff, mods)
+	span.SetAttributes(tracing.Int("skeema.statement_count", int64(len(plan.Statements))))
+	span.End(err)
+	plan.Fingerprint = schemaFingerprint(schemaFromInstance)
+	if t.Dir.Config.Get("save-rollback") != "" {
+		plan.Rollback = rollbackStatements(diff, plan.DiffKeys, mods)
+	}
 	result.UnsupportedCount = len(plan.Unsupported)
 	result.Differences = (len(plan.DiffKeys) + len(plan.Unsupported)) > 0
 	if err != nil {
 		result.SkipCount += len(plan.Statements)
-		return result, err
+		return result, plan, err
 	}
 	for key, details := range plan.Unsupported {
 		var nonInnoWarning string
 		if table := schemaFromInstance.Table(key.Name); key.Type == tengo.ObjectTypeTable && table != nil && table.Engine != "InnoDB" {
 			nonInnoWarning = " This table's storage engine is " + table.Engine + ", but Skeema is designed to operate primarily on InnoDB tables."
 		}
-		log.Warnf("Skipping %s: Skeema does not support generating a diff of this table.%s Use --debug to see which properties of this table are not supported.", key, nonInnoWarning)
+		t.logEntry().WithField("object", key.String()).Warnf("Skipping %s: Skeema does not support generating a diff of this table.%s Use --debug to see which properties of this table are not supported.", key, nonInnoWarning)
 		log.Debug(details)
 	}
 
@@ -194,13 +371,31 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 		fatalProblems = append(fatalProblems, countAndNoun(len(plan.Unsafe), "unsafe statement"))
 		solutionMessage = ". Use --allow-unsafe " + onlyTablesMessage + "to permit this operation. Refer to the Safety Options section of --help."
 	}
+	// Statements violating protect-table, protect-column, max-impact, a
+	// require-osc option, or data-check cannot be permitted by --allow-unsafe
+	if len(plan.Protected) > 0 || len(plan.HighImpact) > 0 || len(plan.DataLoss) > 0 {
+		stderrTerminalWidth, _ := util.TerminalWidth(int(os.Stderr.Fd()))
+		for _, blocked := range slices.Concat(plan.Protected, plan.HighImpact, plan.DataLoss) {
+			log.Error(blocked.Reason + " Generated SQL statement:\n# " + util.WrapStringWithPadding(blocked.Statement, stderrTerminalWidth-29, "# "))
+		}
+		if len(plan.Protected) > 0 {
+			fatalProblems = append(fatalProblems, countAndNoun(len(plan.Protected), "statement affecting protected objects", "statements affecting protected objects"))
+		}
+		if len(plan.HighImpact) > 0 {
+			fatalProblems = append(fatalProblems, countAndNoun(len(plan.HighImpact), "statement exceeding impact or size limits", "statements exceeding impact or size limits"))
+		}
+		if len(plan.DataLoss) > 0 {
+			fatalProblems = append(fatalProblems, countAndNoun(len(plan.DataLoss), "statement affecting existing rows", "statements affecting existing rows"))
+		}
+		solutionMessage = ""
+	}
 
 	// Lint any modified objects, log any linter annotations, and add to summary
 	// error message
 	if t.Dir.Config.GetBool("lint") {
 		lintResult, err := plan.LintModifiedObjects()
 		if err != nil {
-			return result, ConfigError(err.Error())
+			return result, plan, ConfigError(err.Error())
 		}
 		for _, annotation := range lintResult.Annotations {
 			annotation.Log()
@@ -214,20 +409,41 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 	// Return early if we had any unsafe statements and/or linter errors
 	if len(fatalProblems) > 0 {
 		result.SkipCount += len(plan.Statements)
-		log.Warnf("Skipping %s due to %s%s\n", t, strings.Join(fatalProblems, " and "), solutionMessage)
-		return result, nil
+		t.logEntry().Warnf("Skipping %s due to %s%s\n", t, strings.Join(fatalProblems, " and "), solutionMessage)
+		return result, plan, nil
+	}
+
+	// With --plan, only proceed if the plan exactly matches the plan file
+	if planPath := t.Dir.Config.Get("plan"); planPath != "" {
+		pf, err := ReadPlanFile(planPath)
+		if err != nil {
+			return result, plan, ConfigError(err.Error())
+		} else if err := pf.verify(plan, cp.completed(t)); err != nil {
+			result.SkipCount += max(len(plan.Statements), 1)
+			t.logEntry().Errorf("Skipping %s: %s\n", t, err)
+			return result, plan, nil
+		}
 	}
 
 	// Apply plan (print if dry-run, or execute if not); final logging; return result
 	result.SkipCount += plan.Run(printer)
+	if result.SkipCount == 0 && len(plan.Statements) > 0 && !t.Dir.Config.GetBool("dry-run") && t.Dir.Config.GetBool("verify-replicas") {
+		if result.ReplicaMismatchCount, err = verifyReplicas(t, schemaFromInstance, schemaFromDir, mods); err != nil {
+			return result, plan, err
+		}
+	}
+	if recorder, ok := printer.(PlanRecorder); ok {
+		recorder.RecordPlan(plan)
+	}
+	entry := t.logEntry().WithField("duration", time.Since(start).Seconds())
 	if !result.Differences {
-		log.Infof("%s: No differences found\n", t)
+		entry.Infof("%s: No differences found\n", t)
 	} else if t.Dir.Config.GetBool("dry-run") {
-		log.Infof("%s: diff complete\n", t)
+		entry.Infof("%s: diff complete\n", t)
 	} else {
-		log.Infof("%s: push complete\n", t)
+		entry.Infof("%s: push complete\n", t)
 	}
-	return result, nil
+	return result, plan, nil
 }
 
 // CreatePlanForTarget converts a raw *tengo.SchemaDiff into a concrete Plan,
@@ -249,13 +465,22 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 	//   then we can mark it as supported and handle it like any other diff.
 	verifyAllAlterTables := t.Dir.Config.GetBool("verify")
 	allObjDiffs := diff.ObjectDiffs()
+	if t.Dir.Config.GetBool("staged-charset-conversion") {
+		allObjDiffs = stageCharSetConversions(t, allObjDiffs, mods)
+	}
+	if granularity, err := t.Dir.Config.GetEnum("alter-granularity", "table", "clause"); err != nil {
+		fatalErr = ConfigError(err.Error())
+	} else if granularity == "clause" {
+		allObjDiffs = splitAlterClauses(allObjDiffs)
+	}
 	objDiffs := make([]tengo.ObjectDiff, 0, len(allObjDiffs))
 	allAlterTables := make([]*tengo.TableDiff, 0)
 	verifyKeys := make(map[tengo.ObjectKey]bool)
-	for _, objDiff := range allObjDiffs {
+	allStmts, allErrs := tengo.ObjectDiffStatements(allObjDiffs, mods)
+	for n, objDiff := range allObjDiffs {
 		// Filter out cases where stmt is blank and err is nil. That return combo
 		// indicates a no-op difference, i.e. ignored based on the options supplied.
-		stmt, err := objDiff.Statement(mods)
+		stmt, err := allStmts[n], allErrs[n]
 		if stmt != "" || err != nil {
 			objDiffs = append(objDiffs, objDiff)
 		}
@@ -302,6 +527,23 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 		Unsupported: make(map[tengo.ObjectKey]string),
 	}
 
+	protection, err := newProtectionPolicy(t.Dir.Config)
+	if err != nil && fatalErr == nil {
+		fatalErr = ConfigError(err.Error())
+	}
+	maxImpact, err := maxImpactForConfig(t.Dir.Config)
+	if err != nil && fatalErr == nil {
+		fatalErr = ConfigError(err.Error())
+	}
+	oscReq, err := newOSCRequirement(t.Dir.Config)
+	if err != nil && fatalErr == nil {
+		fatalErr = ConfigError(err.Error())
+	}
+	dataCheck, err := newDataCheckPolicy(t.Dir.Config)
+	if err != nil && fatalErr == nil {
+		fatalErr = ConfigError(err.Error())
+	}
+
 	// Second pass over diffs: build plan
 	for _, objDiff := range objDiffs {
 		key := objDiff.ObjectKey()
@@ -313,13 +555,45 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 		if ddl != nil {
 			plan.Statements = append(plan.Statements, ddl)
 			plan.DiffKeys = append(plan.DiffKeys, key)
-			if tengo.IsUnsafeDiff(err) {
+			if reason := protection.violation(objDiff, mods); reason != "" {
+				plan.Protected = append(plan.Protected, UnsafeStatement{
+					Key:       key,
+					Statement: ddl.stmt,
+					Reason:    reason,
+				})
+			} else if tengo.IsUnsafeDiff(err) {
 				plan.Unsafe = append(plan.Unsafe, UnsafeStatement{
 					Key:       key,
 					Statement: ddl.stmt,
 					Reason:    err.Error(),
 				})
 			}
+			if impact := ddl.Impact(); maxImpact != "" && impact.level() > maxImpact.level() {
+				plan.HighImpact = append(plan.HighImpact, UnsafeStatement{
+					Key:       key,
+					Statement: ddl.stmt,
+					Reason:    fmt.Sprintf("Statement for %s has impact class %s, exceeding max-impact=%s.", key, impact, maxImpact),
+				})
+			} else if reason := oscReq.violation(ddl); reason != "" {
+				plan.HighImpact = append(plan.HighImpact, UnsafeStatement{
+					Key:       key,
+					Statement: ddl.stmt,
+					Reason:    reason,
+				})
+			}
+			if problems, err := dataCheck.problems(t, objDiff, mods); err != nil && fatalErr == nil {
+				fatalErr = err
+			} else if len(problems) > 0 && dataCheck.block {
+				plan.DataLoss = append(plan.DataLoss, UnsafeStatement{
+					Key:       key,
+					Statement: ddl.stmt,
+					Reason:    strings.Join(problems, " "),
+				})
+			} else {
+				for _, problem := range problems {
+					t.logEntry().WithField("object", key.String()).Warn(problem)
+				}
+			}
 		}
 		if err != nil && fatalErr == nil && !tengo.IsUnsafeDiff(err) {
 			// Track first non-unsupported, non-unsafe error for use in this function's return value
@@ -330,6 +604,49 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 	return plan, fatalErr
 }
 
+// stageCharSetConversions splits any ALTER TABLE which converts columns to
+// utf8mb4 into a series of ALTER TABLEs, converting one column at a time and
+// then changing the table's default character set. Potential problems with
+// each conversion, such as exceeding index length limits, are logged as
+// warnings.
+func stageCharSetConversions(t *Target, objDiffs []tengo.ObjectDiff, mods tengo.StatementModifiers) []tengo.ObjectDiff {
+	result := make([]tengo.ObjectDiff, 0, len(objDiffs))
+	for _, objDiff := range objDiffs {
+		td, ok := objDiff.(*tengo.TableDiff)
+		if !ok || td.DiffType() != tengo.DiffTypeAlter {
+			result = append(result, objDiff)
+			continue
+		}
+		stages := td.SplitCharSetConversions()
+		for _, stage := range stages {
+			result = append(result, stage)
+		}
+		if stmt, _ := td.Statement(mods); len(stages) > 1 && stmt != "" {
+			key := td.ObjectKey()
+			for _, warning := range td.CharSetConversionWarnings() {
+				t.logEntry().WithField("object", key.String()).Warnf("%s: converting %s to utf8mb4 may fail: %s", t, key, warning)
+			}
+		}
+	}
+	return result
+}
+
+// splitAlterClauses splits each ALTER TABLE into a separate ALTER TABLE per
+// clause, for use with alter-granularity=clause.
+func splitAlterClauses(objDiffs []tengo.ObjectDiff) []tengo.ObjectDiff {
+	result := make([]tengo.ObjectDiff, 0, len(objDiffs))
+	for _, objDiff := range objDiffs {
+		if td, ok := objDiff.(*tengo.TableDiff); ok {
+			for _, clauseDiff := range td.SplitClauses() {
+				result = append(result, clauseDiff)
+			}
+		} else {
+			result = append(result, objDiff)
+		}
+	}
+	return result
+}
+
 // supply 1 noun if pluralized form just adds an s; otherwise supply singular
 // and plural nouns separately
 func countAndNoun(n int, nouns ...string) string {
@@ -350,15 +667,29 @@ func StatementModifiersForDir(dir *fs.Dir) (mods tengo.StatementModifiers, err e
 	mods.CompareMetadata = dir.Config.GetBool("compare-metadata")
 	mods.VirtualColValidation = dir.Config.GetBool("alter-validate-virtual")
 	mods.LaxColumnOrder = dir.Config.GetBool("lax-column-order")
+	mods.OmitColumnPosition = dir.Config.GetBool("append-columns")
 	mods.LaxComments = dir.Config.GetBool("lax-comments")
+	ignorable := tengo.IgnorableTableOptions()
+	ignoreNames := dir.Config.GetSlice("ignore-table-options", ',', true)
+	for n, name := range ignoreNames {
+		ignoreNames[n] = strings.ToUpper(strings.TrimSpace(name))
+		if !slices.Contains(ignorable, ignoreNames[n]) {
+			return mods, fmt.Errorf("Option ignore-table-options does not support %q; valid values: %s", name, strings.ToLower(strings.Join(ignorable, ", ")))
+		}
+	}
+	mods.IgnoreTableOptions = strings.Join(ignoreNames, ",")
 	if dir.Config.GetBool("exact-match") {
 		mods.StrictIndexOrder = true
 		mods.StrictCheckConstraints = true
 		mods.StrictForeignKeyNaming = true
 		mods.StrictColumnDefinition = true // only affects MySQL 8
 	}
-	if mods.AlgorithmClause, err = dir.Config.GetEnum("alter-algorithm", "inplace", "copy", "instant", "nocopy", "default"); err != nil {
+	if mods.AlgorithmClause, err = dir.Config.GetEnum("alter-algorithm", "inplace", "copy", "instant", "nocopy", "default", "auto"); err != nil {
 		return
+	} else if mods.AlgorithmClause == "auto" {
+		// The fallback chain for alter-algorithm=auto is handled by DDLStatement at
+		// execution time; generated DDL omits the ALGORITHM clause
+		mods.AlgorithmClause = ""
 	}
 	if mods.LockClause, err = dir.Config.GetEnum("alter-lock", "none", "shared", "exclusive", "default"); err != nil {
 		return
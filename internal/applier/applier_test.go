@@ -24,19 +24,22 @@ func TestMain(m *testing.M) {
 
 func TestResultMerge(t *testing.T) {
 	r := Result{
-		Differences:      false,
-		SkipCount:        1,
-		UnsupportedCount: 0,
+		Differences:          false,
+		SkipCount:            1,
+		UnsupportedCount:     0,
+		ReplicaMismatchCount: 1,
 	}
 	other := Result{
-		Differences:      true,
-		SkipCount:        3,
-		UnsupportedCount: 5,
+		Differences:          true,
+		SkipCount:            3,
+		UnsupportedCount:     5,
+		ReplicaMismatchCount: 2,
 	}
 	expectSum := Result{
-		Differences:      true,
-		SkipCount:        4,
-		UnsupportedCount: 5,
+		Differences:          true,
+		SkipCount:            4,
+		UnsupportedCount:     5,
+		ReplicaMismatchCount: 3,
 	}
 	r.Merge(other)
 	if r != expectSum {
@@ -112,9 +115,25 @@ func (s ApplierIntegrationSuite) TestCreatePlanForTarget(t *testing.T) {
 		"ddl-wrapper":            "",
 		"alter-wrapper":          "",
 		"alter-wrapper-min-size": "0",
+		"alter-progress":         "",
+		"alter-progress-stream":  "",
+		"init-sql":               "",
+		"mdl-report-after":       "0",
+		"mdl-kill-idle":          "0",
+		"mdl-kill-users":         "",
+		"statement-timeout":      "0",
+		"wsrep-osu-method":       "",
+		"osc-tool":               "none",
 		"alter-algorithm":        "",
 		"alter-lock":             "",
 		"safe-below-size":        "0",
+		"large-table-size":       "0",
+		"require-osc-size":       "0",
+		"require-osc-rows":       "0",
+		"table-stats":            "false",
+		"max-impact":             "",
+		"protect-table":          "",
+		"protect-column":         "",
 		"connect-options":        "",
 		"environment":            "production",
 		"foreign-key-checks":     "",
@@ -125,6 +144,7 @@ func (s ApplierIntegrationSuite) TestCreatePlanForTarget(t *testing.T) {
 		"temp-schema":            "_skeema_tmp",
 		"temp-schema-binlog":     "auto",
 		"temp-schema-threads":    "5",
+		"temp-schema-pool-size":  "0",
 		"reuse-temp-schema":      "false",
 	}
 	dir := &fs.Dir{
@@ -214,6 +234,10 @@ func (s *ApplierIntegrationSuite) Teardown(backend string) error {
 	return err
 }
 
+func (s *ApplierIntegrationSuite) BackendFlavor(backend string) tengo.Flavor {
+	return s.d[0].Flavor()
+}
+
 func (s *ApplierIntegrationSuite) BeforeTest(backend string) error {
 	var g errgroup.Group
 	for n := range s.d {
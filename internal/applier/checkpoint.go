@@ -0,0 +1,179 @@
+package applier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+)
+
+// checkpointVersion is the current version of the state file format. State
+// files with a different version cannot be resumed.
+const checkpointVersion = 1
+
+// checkpoint tracks push progress, persisting the statements completed for
+// each target to the file specified by the state-file option. If a push is
+// interrupted, `skeema push --resume` uses the state file to skip targets which
+// were already completed, and to permit resuming a partially-applied --plan.
+//
+// A single checkpoint is shared by all targets using the same state file, so
+// its methods are safe for concurrent use.
+type checkpoint struct {
+	path    string
+	mu      sync.Mutex
+	Version int                `json:"version"`
+	Targets []checkpointTarget `json:"targets"`
+}
+
+// checkpointTarget is the portion of a checkpoint for a single Target.
+type checkpointTarget struct {
+	Instance  string   `json:"instance"`
+	Schema    string   `json:"schema"`
+	Completed []string `json:"completed"`
+	Done      bool     `json:"done"`
+}
+
+var (
+	checkpoints     = make(map[string]*checkpoint) // state file path -> checkpoint
+	checkpointsLock sync.Mutex
+)
+
+// openCheckpoint returns the checkpoint for the state-file in config. The
+// first call for a given path loads the existing state file if the resume
+// option is enabled; subsequent calls return the same checkpoint until
+// CloseCheckpoint is called. If the state-file option is not set, or this is
+// a dry-run, nil is returned.
+func openCheckpoint(config *mybase.Config) (*checkpoint, error) {
+	path := config.Get("state-file")
+	if path == "" || config.GetBool("dry-run") {
+		return nil, nil
+	}
+	checkpointsLock.Lock()
+	defer checkpointsLock.Unlock()
+	if cp := checkpoints[path]; cp != nil {
+		return cp, nil
+	}
+	cp := &checkpoint{path: path, Version: checkpointVersion}
+	if config.GetBool("resume") {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		} else if err := json.Unmarshal(b, cp); err != nil {
+			return nil, fmt.Errorf("Unable to parse state file %s: %w", path, err)
+		} else if cp.Version != checkpointVersion {
+			return nil, fmt.Errorf("State file %s has unsupported version %d", path, cp.Version)
+		}
+	}
+	checkpoints[path] = cp
+	return cp, nil
+}
+
+// CloseCheckpoint discards the in-memory state for the state file at path. If
+// remove is true, the state file is also deleted, since the push it tracked
+// has completed successfully.
+func CloseCheckpoint(path string, remove bool) error {
+	checkpointsLock.Lock()
+	delete(checkpoints, path)
+	checkpointsLock.Unlock()
+	if remove {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// entry returns a pointer to the entry for t, creating it if create is true
+// and it does not already exist. The caller must hold cp.mu.
+func (cp *checkpoint) entry(t *Target, create bool) *checkpointTarget {
+	instance := t.Instance.String()
+	for n := range cp.Targets {
+		if cp.Targets[n].Instance == instance && cp.Targets[n].Schema == t.SchemaName {
+			return &cp.Targets[n]
+		}
+	}
+	if !create {
+		return nil
+	}
+	cp.Targets = append(cp.Targets, checkpointTarget{Instance: instance, Schema: t.SchemaName})
+	return &cp.Targets[len(cp.Targets)-1]
+}
+
+// done returns true if a previous push already completed t. It is nil-safe.
+func (cp *checkpoint) done(t *Target) bool {
+	if cp == nil {
+		return false
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	entry := cp.entry(t, false)
+	return entry != nil && entry.Done
+}
+
+// completed returns the statements already executed for t by a previous push
+// which did not finish t. It is nil-safe.
+func (cp *checkpoint) completed(t *Target) []string {
+	if cp == nil {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if entry := cp.entry(t, false); entry != nil && !entry.Done {
+		return entry.Completed
+	}
+	return nil
+}
+
+// record notes that stmt was successfully executed on t, and persists the
+// state file. It is nil-safe.
+func (cp *checkpoint) record(t *Target, stmt PlannedStatement) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	entry := cp.entry(t, true)
+	entry.Completed = append(entry.Completed, stmt.Statement())
+	cp.save()
+}
+
+// finish notes that all statements for t have been executed, and persists the
+// state file. It is nil-safe.
+func (cp *checkpoint) finish(t *Target) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	entry := cp.entry(t, true)
+	entry.Done = true
+	entry.Completed = nil
+	cp.save()
+}
+
+// save writes the state file, replacing it atomically so that an interruption
+// mid-write cannot corrupt it. Failure to save is logged, but otherwise does
+// not affect the push. The caller must hold cp.mu.
+func (cp *checkpoint) save() {
+	sort.Slice(cp.Targets, func(i, j int) bool {
+		if cp.Targets[i].Instance != cp.Targets[j].Instance {
+			return cp.Targets[i].Instance < cp.Targets[j].Instance
+		}
+		return cp.Targets[i].Schema < cp.Targets[j].Schema
+	})
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err == nil {
+		tmpPath := cp.path + ".tmp"
+		if err = os.WriteFile(tmpPath, append(b, '\n'), 0666); err == nil {
+			err = os.Rename(tmpPath, cp.path)
+		}
+	}
+	if err != nil {
+		log.Warnf("Unable to save push state file %s: %s", cp.path, err)
+	}
+}
@@ -0,0 +1,82 @@
+package applier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestCheckpoint(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	statePath := filepath.Join(t.TempDir(), "push-state.json")
+	makePlan := func(resume bool, statements ...PlannedStatement) *Plan {
+		settings := fakeRunSettings(map[string]string{"state-file": statePath})
+		if resume {
+			settings["resume"] = "1"
+		}
+		target := &Target{
+			Instance:   inst,
+			Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(settings)},
+			SchemaName: "product",
+		}
+		return &Plan{Target: target, Statements: statements}
+	}
+	stmtA := &fakeStatement{stmt: "CREATE TABLE a (id int)"}
+	stmtB := &fakeStatement{stmt: "CREATE TABLE b (id int)"}
+	stmtC := &fakeStatement{stmt: "CREATE TABLE c (id int)"}
+
+	// Interrupted push: only the first statement should be recorded as completed
+	plan := makePlan(false, stmtA, &fakeStatement{stmt: stmtB.stmt, err: errors.New("connection lost")}, stmtC)
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 2 {
+		t.Errorf("Expected skip count of 2, instead found %d", skipCount)
+	}
+	if err := CloseCheckpoint(statePath, false); err != nil {
+		t.Fatalf("Unexpected error from CloseCheckpoint: %v", err)
+	}
+
+	// Resuming should load the completed statement from the state file, and
+	// permit verifying the remaining statements against a plan file
+	plan = makePlan(true, stmtB, stmtC)
+	cp, err := openCheckpoint(plan.Target.Dir.Config)
+	if err != nil {
+		t.Fatalf("Unexpected error from openCheckpoint: %v", err)
+	} else if cp.done(plan.Target) {
+		t.Error("Expected target to not be done yet, but it was")
+	} else if completed := cp.completed(plan.Target); !slices.Equal(completed, []string{stmtA.stmt}) {
+		t.Errorf("Unexpected completed statements: %v", completed)
+	}
+	pf := NewPlanFile([]*Plan{makePlan(false, stmtA, stmtB, stmtC)})
+	if err := pf.verify(plan, cp.completed(plan.Target)); err != nil {
+		t.Errorf("Unexpected error from verify with completed statements: %v", err)
+	}
+	if err := pf.verify(plan, []string{stmtC.stmt}); err == nil {
+		t.Error("Expected error from verify with mismatched completed statements, but err was nil")
+	}
+
+	// Completing the push should mark the target as done
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 0 {
+		t.Errorf("Expected skip count of 0, instead found %d", skipCount)
+	}
+	if !cp.done(plan.Target) || cp.completed(plan.Target) != nil {
+		t.Error("Expected target to be done, but it was not")
+	}
+	if err := CloseCheckpoint(statePath, true); err != nil {
+		t.Fatalf("Unexpected error from CloseCheckpoint: %v", err)
+	} else if _, err := os.Stat(statePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected state file to be removed, but Stat returned %v", err)
+	}
+
+	// Resuming without a state file is an error
+	if _, err := openCheckpoint(makePlan(true).Target.Dir.Config); err == nil {
+		t.Error("Expected error from openCheckpoint with missing state file, but err was nil")
+	}
+}
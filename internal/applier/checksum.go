@@ -0,0 +1,36 @@
+package applier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// SchemaChecksum returns a hex-encoded SHA-256 checksum of schema's normalized
+// definition, suitable for determining whether two copies of a schema are
+// identical. This is the same value used as a target fingerprint in plan
+// files. A nil schema, representing a schema that does not exist, has a blank
+// checksum.
+func SchemaChecksum(schema *tengo.Schema) string {
+	return schemaFingerprint(schema)
+}
+
+// ObjectChecksums returns a hex-encoded SHA-256 checksum of the normalized
+// definition of each object in schema. Table checksums exclude the next
+// AUTO_INCREMENT value. The schema's default character set and collation are
+// included under the schema's own key, which has type tengo.ObjectTypeDatabase.
+// A nil schema returns an empty map.
+func ObjectChecksums(schema *tengo.Schema) map[tengo.ObjectKey]string {
+	if schema == nil {
+		return map[tengo.ObjectKey]string{}
+	}
+	objects := schema.Objects()
+	checksums := make(map[tengo.ObjectKey]string, len(objects)+1)
+	sum := sha256.Sum256([]byte(schema.CharSet + " " + schema.Collation))
+	checksums[tengo.ObjectKey{Type: tengo.ObjectTypeDatabase, Name: schema.Name}] = hex.EncodeToString(sum[:])
+	for key, obj := range objects {
+		checksums[key] = objectFingerprint(obj)
+	}
+	return checksums
+}
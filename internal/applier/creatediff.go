@@ -0,0 +1,72 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// createDiffText returns a line diff of the live vs filesystem CREATE TABLE
+// statements underlying stmt, formatted as SQL comments, for display prior to
+// the generated ALTER. mode must be "unified" or "side-by-side". A blank
+// string is returned if stmt is not an ALTER TABLE.
+func createDiffText(stmt PlannedStatement, mode string) string {
+	ddl, ok := stmt.(*DDLStatement)
+	if !ok {
+		return ""
+	}
+	td, ok := ddl.diff.(*tengo.TableDiff)
+	if !ok || td.Type != tengo.DiffTypeAlter {
+		return ""
+	}
+	from, to := createStatementsForDiff(td)
+	var lines []string
+	if mode == "side-by-side" {
+		lines = sideBySideText(sideBySideDiff(from, to))
+	} else {
+		ud := difflib.UnifiedDiff{
+			A:        difflib.SplitLines(from),
+			B:        difflib.SplitLines(to),
+			FromFile: "database server",
+			ToFile:   "filesystem",
+			Context:  2,
+		}
+		text, err := difflib.GetUnifiedDiffString(ud)
+		if err != nil || text == "" {
+			return ""
+		}
+		lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight("-- "+line, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// sideBySideText renders rows in two columns, in the style of `diff -y`: the
+// gutter between columns is "<" for removed lines, ">" for added lines, and
+// "|" for changed lines.
+func sideBySideText(rows []htmlDiffRow) []string {
+	width := len("database server")
+	for _, row := range rows {
+		width = max(width, len(row.Left))
+	}
+	lines := []string{fmt.Sprintf("%-*s   %s", width, "database server", "filesystem")}
+	for _, row := range rows {
+		gutter := " "
+		switch row.Class {
+		case "del":
+			gutter = "<"
+		case "add":
+			gutter = ">"
+		case "chg":
+			gutter = "|"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s %s", width, row.Left, gutter, row.Right))
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestCreateDiffText(t *testing.T) {
+	from := &tengo.Table{Name: "users", CreateStatement: "CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(20) DEFAULT NULL\n) ENGINE=InnoDB"}
+	to := &tengo.Table{Name: "users", CreateStatement: "CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(40) DEFAULT NULL\n) ENGINE=InnoDB"}
+	alter := &DDLStatement{diff: tengo.NewAlterTable(from, to), stmt: "ALTER TABLE `users` MODIFY COLUMN `name` varchar(40) DEFAULT NULL"}
+
+	unified := createDiffText(alter, "unified")
+	expected := []string{
+		"-- --- database server\n-- +++ filesystem\n",
+		"\n--    `id` int NOT NULL,\n",
+		"\n-- -  `name` varchar(20) DEFAULT NULL\n-- +  `name` varchar(40) DEFAULT NULL\n",
+	}
+	for _, substr := range expected {
+		if !strings.Contains(unified, substr) {
+			t.Errorf("Expected unified diff to contain %q, but it did not. Full output:\n%s", substr, unified)
+		}
+	}
+
+	sideBySide := createDiffText(alter, "side-by-side")
+	lines := strings.Split(strings.TrimSuffix(sideBySide, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines of side-by-side output, instead found %d:\n%s", len(lines), sideBySide)
+	}
+	if !strings.HasPrefix(lines[0], "-- database server ") || !strings.HasSuffix(lines[0], " filesystem") {
+		t.Errorf("Unexpected header line %q", lines[0])
+	}
+	if lines[3] != "--   `name` varchar(20) DEFAULT NULL |   `name` varchar(40) DEFAULT NULL" {
+		t.Errorf("Unexpected changed line %q", lines[3])
+	}
+	if strings.Index(lines[0], "filesystem") != strings.Index(lines[3], "|")+2 {
+		t.Errorf("Columns are not aligned:\n%s", sideBySide)
+	}
+
+	// Only ALTER TABLE should have a diff
+	drop := &DDLStatement{diff: tengo.NewDropTable(from), stmt: "DROP TABLE `users`"}
+	if text := createDiffText(drop, "unified"); text != "" {
+		t.Errorf("Expected no diff for DROP TABLE, instead found %q", text)
+	}
+	if text := createDiffText(&fakeStatement{stmt: "SELECT 1"}, "side-by-side"); text != "" {
+		t.Errorf("Expected no diff for non-DDL statement, instead found %q", text)
+	}
+}
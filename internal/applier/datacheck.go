@@ -0,0 +1,158 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// dataCheckPolicy examines existing rows of the live table before permitting
+// column modifications which narrow a column's data type or make it NOT NULL.
+// Depending on the data-check option, affected rows either block the
+// statement or just cause a warning to be logged. If the data-check-rows
+// option is non-zero, only that many rows of each table are examined, rather
+// than scanning the entire table.
+type dataCheckPolicy struct {
+	block bool
+	limit int64
+}
+
+// newDataCheckPolicy returns a dataCheckPolicy based on config. If data-check
+// is "none", nil is returned.
+func newDataCheckPolicy(config *mybase.Config) (*dataCheckPolicy, error) {
+	mode, err := config.GetEnum("data-check", "none", "warn", "block")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := config.GetInt("data-check-rows")
+	if err != nil || limit < 0 {
+		return nil, errors.New("option data-check-rows has been configured to an invalid value")
+	}
+	if mode == "none" {
+		return nil, nil
+	}
+	return &dataCheckPolicy{block: (mode == "block"), limit: int64(limit)}, nil
+}
+
+// problems returns a description of each column modification in diff which
+// would affect existing rows of the table on the target's instance. It is
+// nil-safe.
+func (dc *dataCheckPolicy) problems(t *Target, diff tengo.ObjectDiff, mods tengo.StatementModifiers) (problems []string, err error) {
+	td, ok := diff.(*tengo.TableDiff)
+	if dc == nil || !ok || td.DiffType() != tengo.DiffTypeAlter {
+		return nil, nil
+	}
+	var db *sqlx.DB
+	tableName := td.ObjectKey().Name
+	for _, clause := range td.AlterClauses(mods) {
+		mc, ok := clause.(tengo.ModifyColumn)
+		if !ok {
+			continue
+		}
+		conditions := narrowingConditions(mc)
+		if len(conditions) == 0 {
+			continue
+		}
+		if db == nil {
+			if db, err = t.Instance.CachedConnectionPool(t.SchemaName, ""); err != nil {
+				return nil, err
+			}
+		}
+		var count int64
+		query := dc.query(tableName, mc.OldColumn.Name, conditions)
+		if err := db.Get(&count, query); err != nil {
+			return nil, fmt.Errorf("Error checking existing rows of table %s: %w", tengo.EscapeIdentifier(tableName), err)
+		}
+		if count == 0 {
+			continue
+		}
+		var examined string
+		if dc.limit > 0 {
+			examined = fmt.Sprintf(" among the first %d examined", dc.limit)
+		}
+		problems = append(problems, fmt.Sprintf("Modifying column %s of table %s would affect %s%s.", tengo.EscapeIdentifier(mc.OldColumn.Name), tengo.EscapeIdentifier(tableName), countAndNoun(int(count), "existing row"), examined))
+	}
+	return problems, nil
+}
+
+// query returns a query counting rows of the table where the named column
+// satisfies any of the supplied conditions.
+func (dc *dataCheckPolicy) query(tableName, columnName string, conditions []string) string {
+	where := strings.Join(conditions, " OR ")
+	if dc.limit == 0 {
+		return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tengo.EscapeIdentifier(tableName), where)
+	}
+	return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s FROM %s LIMIT %d) AS sample WHERE %s",
+		tengo.EscapeIdentifier(columnName), tengo.EscapeIdentifier(tableName), dc.limit, where)
+}
+
+var (
+	charLengthTypes  = []string{"char", "varchar"}
+	byteLengthTypes  = []string{"binary", "varbinary"}
+	textTypes        = []string{"tinytext", "text", "mediumtext", "longtext"}
+	blobTypes        = []string{"tinyblob", "blob", "mediumblob", "longblob"}
+	largeObjectBytes = []uint64{255, 65535, 16777215, 4294967295}
+)
+
+// narrowingConditions returns SQL conditions matching values of the column
+// modified by mc which would be rejected or lossily converted by mc: NULLs if
+// the column becomes NOT NULL, or values outside the range or length of the
+// new data type. Only modifications within the same family of data types are
+// considered, and changes to character sets are ignored.
+func narrowingConditions(mc tengo.ModifyColumn) (conditions []string) {
+	col := tengo.EscapeIdentifier(mc.OldColumn.Name)
+	oldType, newType := mc.OldColumn.Type, mc.NewColumn.Type
+	if mc.OldColumn.Nullable && !mc.NewColumn.Nullable {
+		conditions = append(conditions, col+" IS NULL")
+	}
+	if mc.OldColumn.Virtual || mc.NewColumn.Virtual || oldType.Equivalent(newType) {
+		return conditions
+	}
+
+	if oldMin, oldMax, ok := oldType.IntegerRange(); ok {
+		if newMin, newMax, ok := newType.IntegerRange(); ok {
+			if newMin > oldMin {
+				conditions = append(conditions, fmt.Sprintf("%s < %d", col, newMin))
+			}
+			if newMax < oldMax {
+				conditions = append(conditions, fmt.Sprintf("%s > %d", col, newMax))
+			}
+		}
+		return conditions
+	}
+
+	switch {
+	case oldType.Base == "decimal" && newType.Base == "decimal":
+		if newType.Size < oldType.Size || newType.Scale < oldType.Scale {
+			limit := "1" + strings.Repeat("0", int(newType.Size)-int(newType.Scale))
+			conditions = append(conditions, fmt.Sprintf("ABS(%s) >= %s", col, limit))
+		}
+		if newType.Scale < oldType.Scale {
+			conditions = append(conditions, fmt.Sprintf("%s <> ROUND(%s, %d)", col, col, newType.Scale))
+		}
+		if newType.Unsigned && !oldType.Unsigned {
+			conditions = append(conditions, col+" < 0")
+		}
+	case slices.Contains(charLengthTypes, newType.Base) && (slices.Contains(charLengthTypes, oldType.Base) || slices.Contains(textTypes, oldType.Base)):
+		if newType.Size < oldType.Size || slices.Contains(textTypes, oldType.Base) {
+			conditions = append(conditions, fmt.Sprintf("CHAR_LENGTH(%s) > %d", col, newType.Size))
+		}
+	case slices.Contains(byteLengthTypes, newType.Base) && (slices.Contains(byteLengthTypes, oldType.Base) || slices.Contains(blobTypes, oldType.Base)):
+		if newType.Size < oldType.Size || slices.Contains(blobTypes, oldType.Base) {
+			conditions = append(conditions, fmt.Sprintf("LENGTH(%s) > %d", col, newType.Size))
+		}
+	default:
+		for _, family := range [][]string{textTypes, blobTypes} {
+			oldIndex, newIndex := slices.Index(family, oldType.Base), slices.Index(family, newType.Base)
+			if oldIndex > newIndex && newIndex >= 0 {
+				conditions = append(conditions, fmt.Sprintf("LENGTH(%s) > %d", col, largeObjectBytes[newIndex]))
+			}
+		}
+	}
+	return conditions
+}
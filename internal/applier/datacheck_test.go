@@ -0,0 +1,55 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestNarrowingConditions(t *testing.T) {
+	cases := []struct {
+		oldType, newType string
+		oldNull, newNull bool
+		expected         string
+	}{
+		{"int", "int", false, false, ""},
+		{"int", "bigint", false, false, ""},
+		{"int", "int", true, false, "`c` IS NULL"},
+		{"int", "smallint", false, false, "`c` < -32768 OR `c` > 32767"},
+		{"int", "int unsigned", false, false, "`c` < 0"},
+		{"int unsigned", "int", false, false, "`c` > 2147483647"},
+		{"int unsigned", "mediumint unsigned", true, false, "`c` IS NULL OR `c` > 16777215"},
+		{"decimal(10,2)", "decimal(12,2)", false, false, ""},
+		{"decimal(10,2)", "decimal(8,2)", false, false, "ABS(`c`) >= 1000000"},
+		{"decimal(10,2)", "decimal(10,1)", false, false, "ABS(`c`) >= 1000000000 OR `c` <> ROUND(`c`, 1)"},
+		{"varchar(100)", "varchar(200)", false, false, ""},
+		{"varchar(100)", "varchar(50)", false, false, "CHAR_LENGTH(`c`) > 50"},
+		{"text", "varchar(500)", false, false, "CHAR_LENGTH(`c`) > 500"},
+		{"varbinary(16)", "binary(8)", false, false, "LENGTH(`c`) > 8"},
+		{"mediumtext", "text", false, false, "LENGTH(`c`) > 65535"},
+		{"text", "mediumtext", false, false, ""},
+		{"longblob", "tinyblob", false, false, "LENGTH(`c`) > 255"},
+		{"datetime", "date", false, false, ""},
+	}
+	for _, c := range cases {
+		mc := tengo.ModifyColumn{
+			OldColumn: &tengo.Column{Name: "c", Type: tengo.ParseColumnType(c.oldType), Nullable: c.oldNull},
+			NewColumn: &tengo.Column{Name: "c", Type: tengo.ParseColumnType(c.newType), Nullable: c.newNull},
+		}
+		if actual := strings.Join(narrowingConditions(mc), " OR "); actual != c.expected {
+			t.Errorf("Unexpected conditions for %s (nullable=%t) to %s (nullable=%t): expected %q, found %q", c.oldType, c.oldNull, c.newType, c.newNull, c.expected, actual)
+		}
+	}
+
+	dc := &dataCheckPolicy{limit: 1000}
+	expected := "SELECT COUNT(*) FROM (SELECT `c` FROM `t` LIMIT 1000) AS sample WHERE `c` IS NULL OR `c` > 5"
+	if actual := dc.query("t", "c", []string{"`c` IS NULL", "`c` > 5"}); actual != expected {
+		t.Errorf("Unexpected query: expected %q, found %q", expected, actual)
+	}
+	dc.limit = 0
+	expected = "SELECT COUNT(*) FROM `t` WHERE `c` IS NULL"
+	if actual := dc.query("t", "c", []string{"`c` IS NULL"}); actual != expected {
+		t.Errorf("Unexpected query: expected %q, found %q", expected, actual)
+	}
+}
@@ -1,11 +1,14 @@
 package applier
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/jmoiron/sqlx"
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/shellout"
@@ -20,6 +23,17 @@ type DDLStatement struct {
 	stmt     string
 	compound bool
 	shellOut *shellout.Command
+	osc      oscTool
+	fallback *algorithmFallback
+	progress *progressReporter
+	session  *sessionSetup
+	mdl      *mdlMonitor
+	diff     tengo.ObjectDiff
+	mods     tengo.StatementModifiers
+
+	largeTable bool        // table size is at least large-table-size
+	stats      *tableStats // only populated if a size-related option is in use
+	source     string      // location of the object's definition in the filesystem, if any
 
 	instance      *tengo.Instance
 	schemaName    string
@@ -35,6 +49,7 @@ type DDLStatement struct {
 // or log the ddl despite the error.
 func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, target *Target) (ddl *DDLStatement, err error) {
 	ddl = &DDLStatement{
+		diff:       diff,
 		instance:   target.Instance,
 		schemaName: target.SchemaName,
 	}
@@ -44,14 +59,18 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 	if diff.ObjectKey().Type == tengo.ObjectTypeDatabase {
 		ddl.schemaName = ""
 	}
+	ddl.source = sourceLocation(target, diff.ObjectKey())
 
 	// Get table size, but only if actually needed; apply --safe-below-size if
 	// specified
 	var tableSize int64
 	if needTableSize(diff, target.Dir.Config) {
-		if tableSize, err = getTableSize(target, diff.ObjectKey().Name); err != nil {
+		stats, err := getTableStats(target, diff.ObjectKey().Name, needTableRows(target.Dir.Config))
+		if err != nil {
 			return nil, err
 		}
+		ddl.stats = &stats
+		tableSize = stats.size
 
 		// If --safe-below-size option in use, enable additional statement modifier
 		// if the table's size is less than the supplied option value
@@ -61,14 +80,47 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 			mods.AllowUnsafe = true
 			log.Debugf("Allowing unsafe operations for %s: size=%d < safe-below-size=%d", diff.ObjectKey(), tableSize, safeBelowSize)
 		}
+		if largeTableSize, err := target.Dir.Config.GetBytes("large-table-size"); err != nil {
+			return nil, ConfigError("option large-table-size has been configured to an invalid value")
+		} else if largeTableSize > 0 && tableSize >= int64(largeTableSize) {
+			ddl.largeTable = true
+		}
 	}
 
 	// Options may indicate some/all DDL gets executed by shelling out to another program.
+	fallbackMods := mods
 	wrapper, mods, err := getWrapper(target.Dir.Config, diff, tableSize, mods)
 	if err != nil {
 		return nil, ConfigError(err.Error())
 	}
 
+	// Options may instead indicate ALTER TABLE gets executed by a natively-
+	// integrated online schema change tool.
+	if ddl.osc, mods, err = getOSCTool(target.Dir.Config, diff, tableSize, mods); err != nil {
+		return nil, ConfigError(err.Error())
+	} else if ddl.osc != nil {
+		if cmdLine := ddl.osc.commandLine(target.Dir.Config); cmdLine != "" {
+			if ddl.instance.SocketPath != "" {
+				return nil, ConfigError(ddl.osc.name() + " cannot be used with a socket connection")
+			}
+			wrapper = cmdLine
+		}
+	}
+
+	// With alter-algorithm=auto, ALTER TABLE is first attempted directly using
+	// progressively less efficient ALGORITHM clauses. If a wrapper or OSC tool
+	// is available, only ALGORITHM=INSTANT is attempted before handing off.
+	if useAlgorithmFallback(target.Dir.Config, diff) {
+		ddl.fallback = newAlgorithmFallback(fallbackMods, wrapper != "" || ddl.osc != nil)
+	}
+
+	// Progress reporting is only possible for ALTER TABLE run directly by Skeema
+	if diff.ObjectKey().Type == tengo.ObjectTypeTable && diff.DiffType() == tengo.DiffTypeAlter && ((wrapper == "" && ddl.osc == nil) || ddl.fallback != nil) {
+		if ddl.progress, err = newProgressReporter(target.Dir.Config); err != nil {
+			return nil, ConfigError(err.Error())
+		}
+	}
+
 	// Determine if the statement is a compound statement, requiring special
 	// delimiter handling in output. Only stored program diffs (e.g. procs, funcs)
 	// implement this interface; others never generate compound statements.
@@ -83,6 +135,7 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 	// However for e.g. unsafe statement errors, we have a non-blank statement,
 	// which we intentionally return as a non-nil DDLStatement alongside the error,
 	// so that the caller can log the offending statement.
+	ddl.mods = mods
 	ddl.stmt, err = diff.Statement(mods)
 	if ddl.stmt == "" {
 		return nil, err
@@ -90,9 +143,16 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		return ddl, err
 	}
 
-	if wrapper == "" {
+	if wrapper == "" || ddl.fallback != nil {
 		ddl.connectParams = getConnectParams(diff, target.Dir.Config)
-	} else {
+		if ddl.session, err = newSessionSetup(target.Dir.Config); err != nil {
+			return nil, ConfigError(err.Error())
+		}
+		if ddl.mdl, err = newMDLMonitor(target.Dir.Config); err != nil {
+			return nil, ConfigError(err.Error())
+		}
+	}
+	if wrapper != "" {
 		var socket, port, connOpts string
 		if ddl.instance.SocketPath != "" {
 			socket = ddl.instance.SocketPath
@@ -102,13 +162,20 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		if connOpts, err = util.RealConnectOptions(target.Dir.Config.Get("connect-options")); err != nil {
 			return nil, ConfigError(err.Error())
 		}
+		// The instance's password may differ from the configured password option,
+		// for example an auth token with aws-iam-auth, or a value obtained from an
+		// external secret store or credential-helper
+		password, err := ddl.instance.CurrentPassword()
+		if err != nil {
+			return nil, err
+		}
 		variables := map[string]string{
 			"HOST":        ddl.instance.Host,
 			"PORT":        port,
 			"SOCKET":      socket,
 			"SCHEMA":      ddl.schemaName,
-			"USER":        target.Dir.Config.GetAllowEnvVar("user"),
-			"PASSWORD":    target.Dir.Config.GetAllowEnvVar("password"),
+			"USER":        ddl.instance.User,
+			"PASSWORD":    password,
 			"ENVIRONMENT": target.Dir.Config.Get("environment"),
 			"DDL":         ddl.stmt,
 			"CLAUSES":     "", // filled in below only for tables
@@ -126,6 +193,11 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 			variables["CLAUSES"], _ = td.Clauses(mods)
 			variables["TABLE"] = variables["NAME"]
 		}
+		if ddl.osc != nil {
+			for k, v := range ddl.osc.variables(ddl) {
+				variables[k] = v
+			}
+		}
 
 		if ddl.shellOut, err = shellout.New(wrapper).WithVariables(variables); err != nil {
 			return nil, fmt.Errorf("A fatal error occurred with pre-processing a DDL statement: %w", err)
@@ -146,12 +218,16 @@ func needTableSize(diff tengo.ObjectDiff, config *mybase.Config) bool {
 		return false
 	}
 
-	// If safe-below-size or alter-wrapper-min-size options in use, size is needed
-	for _, opt := range []string{"safe-below-size", "alter-wrapper-min-size"} {
+	// If safe-below-size, alter-wrapper-min-size, large-table-size, or any
+	// require-osc option is in use, size is needed
+	for _, opt := range []string{"safe-below-size", "alter-wrapper-min-size", "large-table-size", "require-osc-size", "require-osc-rows"} {
 		if config.Changed(opt) {
 			return true
 		}
 	}
+	if config.GetBool("table-stats") {
+		return true
+	}
 
 	// If any wrapper option uses the {SIZE} variable placeholder, size is needed
 	for _, opt := range []string{"alter-wrapper", "ddl-wrapper"} {
@@ -163,15 +239,24 @@ func needTableSize(diff tengo.ObjectDiff, config *mybase.Config) bool {
 	return false
 }
 
-// getTableSize returns the size of the table on the instance corresponding to
-// the target. If the table has no rows, this method always returns a size of 0,
-// even though information_schema normally indicates at least 16kb in this case.
-func getTableSize(target *Target, tableName string) (int64, error) {
-	hasRows, err := target.Instance.TableHasRows(target.SchemaName, tableName)
-	if !hasRows || err != nil {
-		return 0, err
+// sourceLocation returns the path (relative to the repo root) and line range of
+// the CREATE statement defining key in the target's dir, for example
+// "mydb/users.sql:3-12". A blank string is returned if the object is not
+// defined in the filesystem, which is the case for DROPs.
+func sourceLocation(target *Target, key tengo.ObjectKey) string {
+	if target.DesiredSchema == nil || target.DesiredSchema.LogicalSchema == nil {
+		return ""
+	}
+	stmt := target.DesiredSchema.LogicalSchema.Creates[key]
+	if stmt == nil || stmt.File == "" {
+		return ""
+	}
+	file := filepath.Join(target.Dir.RelPath(), filepath.Base(stmt.File))
+	lastLineNo := stmt.LineNo + strings.Count(strings.TrimRight(stmt.Text, "\n"), "\n")
+	if lastLineNo > stmt.LineNo {
+		return fmt.Sprintf("%s:%d-%d", file, stmt.LineNo, lastLineNo)
 	}
-	return target.Instance.TableSize(target.SchemaName, tableName)
+	return fmt.Sprintf("%s:%d", file, stmt.LineNo)
 }
 
 // getWrapper returns the command-line for executing diff as a shell-out, if
@@ -232,22 +317,55 @@ func getConnectParams(diff tengo.ObjectDiff, config *mybase.Config) string {
 // Execute runs the DDL statement, either by running a SQL query against a DB,
 // or shelling out to an external program, as appropriate.
 func (ddl *DDLStatement) Execute() error {
-	if ddl.shellOut != nil {
+	if ddl.fallback != nil {
+		return ddl.executeWithFallback()
+	} else if ddl.osc != nil {
+		return ddl.runOSC()
+	} else if ddl.shellOut != nil {
 		return ddl.shellOut.Run()
 	}
 	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, ddl.connectParams)
 	if err != nil {
 		return err
 	}
-	_, err = db.Exec(ddl.stmt)
-	return err
+	return ddl.execSQL(db, ddl.stmt)
+}
+
+// execSQL runs stmt using db, reporting its progress, applying session setup,
+// and monitoring metadata locks if configured to do so.
+func (ddl *DDLStatement) execSQL(db *sqlx.DB, stmt string) error {
+	if ddl.progress == nil && ddl.session == nil && ddl.mdl == nil {
+		_, err := db.Exec(stmt)
+		return err
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var connID int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connID); err != nil {
+		return err
+	}
+	stopMDLMonitor := ddl.mdl.watch(ddl, connID)
+	defer stopMDLMonitor()
+	return ddl.session.run(ctx, db, conn, connID, func() error {
+		if ddl.progress != nil {
+			return ddl.progress.exec(ctx, ddl, db, conn, connID, stmt)
+		}
+		_, err := conn.ExecContext(ctx, stmt)
+		return err
+	})
 }
 
 // Statement returns a string representation of ddl. If an external command is
 // in use, the returned string will be prefixed with "\!", the MySQL CLI command
-// shortcut for "system" shellout.
+// shortcut for "system" shellout. With alter-algorithm=auto, the SQL statement
+// is returned even if a wrapper is configured, since the wrapper is only used
+// as a last resort.
 func (ddl *DDLStatement) Statement() string {
-	if ddl.shellOut != nil {
+	if ddl.shellOut != nil && ddl.fallback == nil {
 		return "\\! " + ddl.shellOut.String()
 	}
 	return ddl.stmt
@@ -261,7 +379,7 @@ func (ddl *DDLStatement) ClientState() ClientState {
 		SchemaName:   ddl.schemaName,
 		Delimiter:    ";",
 	}
-	if ddl.shellOut != nil {
+	if ddl.shellOut != nil && ddl.fallback == nil {
 		cs.Delimiter = ""
 	} else if ddl.compound {
 		cs.Delimiter = "//"
@@ -58,9 +58,14 @@ func (s ApplierIntegrationSuite) TestNewDDLStatement(t *testing.T) {
 		"ddl-wrapper":            "/bin/echo ddl-wrapper {SCHEMA}.{NAME} {TYPE} {CLASS}",
 		"alter-wrapper":          "/bin/echo alter-wrapper {SCHEMA}.{TABLE} {TYPE} {CLAUSES}",
 		"alter-wrapper-min-size": "1",
+		"osc-tool":               "none",
 		"alter-algorithm":        "inplace",
 		"alter-lock":             "none",
 		"safe-below-size":        "0",
+		"large-table-size":       "0",
+		"require-osc-size":       "0",
+		"require-osc-rows":       "0",
+		"table-stats":            "false",
 		"connect-options":        "",
 		"environment":            "production",
 	}
@@ -169,3 +174,38 @@ func objectDiffExpected(t *testing.T, diff tengo.ObjectDiff, ddl *DDLStatement,
 	}
 	return
 }
+
+func TestSourceLocation(t *testing.T) {
+	usersKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "users"}
+	postsKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "posts"}
+	missingKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "missing"}
+	logicalSchema := fs.NewLogicalSchema()
+	logicalSchema.Creates[usersKey] = &tengo.Statement{
+		File:   "/var/repo/mydb/users.sql",
+		LineNo: 3,
+		Text:   "CREATE TABLE users (\n  id int unsigned NOT NULL,\n  PRIMARY KEY (id)\n);\n",
+	}
+	logicalSchema.Creates[postsKey] = &tengo.Statement{
+		File:   "/var/repo/mydb/posts.sql",
+		LineNo: 1,
+		Text:   "CREATE TABLE posts (id int);\n",
+	}
+	target := &Target{
+		Dir:           &fs.Dir{Path: "/var/repo/mydb"},
+		DesiredSchema: &workspace.Schema{LogicalSchema: logicalSchema},
+	}
+	cases := map[tengo.ObjectKey]string{
+		usersKey:   "mydb/users.sql:3-6",
+		postsKey:   "mydb/posts.sql:1",
+		missingKey: "",
+	}
+	for key, expected := range cases {
+		if actual := sourceLocation(target, key); actual != expected {
+			t.Errorf("Expected sourceLocation for %s to return %q, instead found %q", key, expected, actual)
+		}
+	}
+	target.DesiredSchema.LogicalSchema = nil
+	if actual := sourceLocation(target, usersKey); actual != "" {
+		t.Errorf("Expected sourceLocation to return blank string with no LogicalSchema, instead found %q", actual)
+	}
+}
@@ -0,0 +1,99 @@
+package applier
+
+import (
+	"database/sql"
+	"slices"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// sharedIntrospection permits many targets on the same instance to avoid
+// redundant introspection of schemas with identical definitions, which is
+// typical of schema-per-tenant designs. The first time any of its targets is
+// introspected, metadata fingerprints of all of the schemas are obtained in a
+// batch. Only one schema per distinct fingerprint is then fully introspected,
+// and the others reuse a renamed copy of its result.
+//
+// Since next AUTO_INCREMENT values are excluded from fingerprints, reused
+// schemas report the representative schema's values. This only matters if the
+// *.sql files specify an AUTO_INCREMENT value greater than some tenants'
+// current value but not the representative's, in which case those tenants do
+// not receive the AUTO_INCREMENT change. Table objects are shared between
+// reused schemas and must not be modified.
+type sharedIntrospection struct {
+	instance     *tengo.Instance
+	names        []string
+	once         sync.Once
+	fingerprints map[string]string
+	err          error
+	m            sync.Mutex
+	schemas      map[string]*introspectedSchema // keyed by fingerprint
+}
+
+// introspectedSchema is the result of introspecting a single representative
+// schema for a fingerprint.
+type introspectedSchema struct {
+	once   sync.Once
+	schema *tengo.Schema
+	err    error
+}
+
+func newSharedIntrospection(instance *tengo.Instance, names []string) *sharedIntrospection {
+	return &sharedIntrospection{
+		instance: instance,
+		names:    names,
+		schemas:  make(map[string]*introspectedSchema),
+	}
+}
+
+// Schema returns the introspected schema with the supplied name, or nil and a
+// nil error if the schema does not exist. If fingerprints could not be
+// obtained, each schema is introspected separately.
+func (si *sharedIntrospection) Schema(name string) (*tengo.Schema, error) {
+	si.once.Do(func() {
+		si.fingerprints, si.err = si.instance.SchemaFingerprints(si.names...)
+		if si.err != nil {
+			log.Warnf("Unable to obtain schema fingerprints on %s; introspecting each schema separately: %s", si.instance, si.err)
+		}
+	})
+	fingerprint, ok := si.fingerprints[name]
+	if si.err != nil || !ok {
+		return introspectSchema(si.instance, name)
+	}
+
+	si.m.Lock()
+	entry := si.schemas[fingerprint]
+	if entry == nil {
+		entry = &introspectedSchema{}
+		si.schemas[fingerprint] = entry
+	}
+	si.m.Unlock()
+
+	entry.once.Do(func() {
+		entry.schema, entry.err = introspectSchema(si.instance, name)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	} else if entry.schema == nil {
+		// Representative was dropped after fingerprinting; fall back to
+		// introspecting this schema directly
+		return introspectSchema(si.instance, name)
+	}
+	schemaCopy := *entry.schema
+	schemaCopy.Name = name
+	schemaCopy.Tables = slices.Clone(entry.schema.Tables)
+	schemaCopy.Routines = slices.Clone(entry.schema.Routines)
+	return &schemaCopy, nil
+}
+
+// introspectSchema returns the named schema from instance, or nil and a nil
+// error if it does not exist.
+func introspectSchema(instance *tengo.Instance, name string) (*tengo.Schema, error) {
+	schema, err := instance.Schema(name)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return schema, err
+}
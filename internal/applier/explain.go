@@ -0,0 +1,194 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// Explainer is an interface for PlannedStatements which can describe their
+// effect in plain language.
+type Explainer interface {
+	PlannedStatement
+	Explain() Explanation
+}
+
+// Explanation is a deterministic, structured description of a single planned
+// statement. It is intended for feeding into review tooling or change tickets.
+type Explanation struct {
+	Key      tengo.ObjectKey
+	Action   string // "create", "alter", or "drop"
+	Impact   Impact
+	Changes  []string // one entry per alteration, or attributes of the new object for a create
+	External bool     // true if executed via an external command (ddl-wrapper or alter-wrapper)
+}
+
+// String returns a compact single-line representation of the explanation.
+func (e Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s %s", e.Impact, e.Action, e.Key)
+	if len(e.Changes) > 0 {
+		b.WriteString(": ")
+		b.WriteString(strings.Join(e.Changes, "; "))
+	}
+	if e.External {
+		b.WriteString(" (via external command)")
+	}
+	return b.String()
+}
+
+// Explain returns a structured description of the DDL statement.
+func (ddl *DDLStatement) Explain() Explanation {
+	e := Explanation{
+		Key:      ddl.diff.ObjectKey(),
+		Action:   strings.ToLower(ddl.diff.DiffType().String()),
+		Impact:   ddl.Impact(),
+		External: ddl.shellOut != nil,
+	}
+	switch diff := ddl.diff.(type) {
+	case *tengo.TableDiff:
+		switch diff.Type {
+		case tengo.DiffTypeCreate:
+			e.Changes = describeNewTable(diff.To)
+		case tengo.DiffTypeAlter:
+			for _, clause := range diff.AlterClauses(ddl.mods) {
+				e.Changes = append(e.Changes, describeClause(clause, ddl.mods))
+			}
+		}
+	case *tengo.RoutineDiff:
+		if diff.Type == tengo.DiffTypeAlter {
+			e.Changes = []string{"replaces definition"}
+		}
+	}
+	return e
+}
+
+func describeNewTable(table *tengo.Table) []string {
+	var indexCount int
+	if table.PrimaryKey != nil {
+		indexCount++
+	}
+	indexCount += len(table.SecondaryIndexes)
+	changes := []string{
+		countAndNoun(len(table.Columns), "column"),
+		countAndNoun(indexCount, "index", "indexes"),
+	}
+	if len(table.ForeignKeys) > 0 {
+		changes = append(changes, countAndNoun(len(table.ForeignKeys), "foreign key"))
+	}
+	if table.Partitioning != nil {
+		changes = append(changes, "partitioned")
+	}
+	return changes
+}
+
+// describeClause returns a plain-language description of a single ALTER TABLE
+// clause. Clause types without specialized handling fall back to a lowercased
+// rendering of their SQL.
+func describeClause(clause tengo.TableAlterClause, mods tengo.StatementModifiers) string {
+	switch clause := clause.(type) {
+	case tengo.AddColumn:
+		return "adds column " + tengo.EscapeIdentifier(clause.Column.Name) + " " + clause.Column.Type.String()
+	case tengo.DropColumn:
+		return "drops column " + tengo.EscapeIdentifier(clause.Column.Name)
+	case tengo.ModifyColumn:
+		if clause.VisibilityOnly(mods) && !clause.PositionFirst && clause.PositionAfter == nil {
+			if clause.NewColumn.Invisible {
+				return "makes column " + tengo.EscapeIdentifier(clause.NewColumn.Name) + " invisible (metadata only)"
+			}
+			return "makes column " + tengo.EscapeIdentifier(clause.NewColumn.Name) + " visible (metadata only)"
+		}
+		desc := "modifies column " + tengo.EscapeIdentifier(clause.NewColumn.Name)
+		if oldType, newType := clause.OldColumn.Type.String(), clause.NewColumn.Type.String(); oldType != newType {
+			desc += fmt.Sprintf(" (type %s to %s)", oldType, newType)
+		}
+		return desc
+	case tengo.RenameColumn:
+		return fmt.Sprintf("renames column %s to %s", tengo.EscapeIdentifier(clause.OldColumn.Name), tengo.EscapeIdentifier(clause.NewName))
+	case tengo.AddIndex:
+		return "adds " + describeIndex(clause.Index)
+	case tengo.DropIndex:
+		return "drops " + describeIndex(clause.Index)
+	case tengo.ModifyIndex:
+		return "modifies " + describeIndex(clause.ToIndex)
+	case tengo.AlterIndex:
+		if clause.Invisible {
+			return "makes index " + tengo.EscapeIdentifier(clause.Name) + " invisible"
+		}
+		return "makes index " + tengo.EscapeIdentifier(clause.Name) + " visible"
+	case tengo.AddForeignKey:
+		return fmt.Sprintf("adds foreign key %s referencing %s", tengo.EscapeIdentifier(clause.ForeignKey.Name), tengo.EscapeIdentifier(clause.ForeignKey.ReferencedTableName))
+	case tengo.DropForeignKey:
+		return "drops foreign key " + tengo.EscapeIdentifier(clause.ForeignKey.Name)
+	case tengo.AddCheck:
+		return "adds check constraint " + tengo.EscapeIdentifier(clause.Check.Name)
+	case tengo.DropCheck:
+		return "drops check constraint " + tengo.EscapeIdentifier(clause.Check.Name)
+	case tengo.AlterCheck:
+		return "alters enforcement of check constraint " + tengo.EscapeIdentifier(clause.Check.Name)
+	case tengo.ChangeAutoIncrement:
+		return fmt.Sprintf("changes next auto-increment value to %d", clause.NewNextAutoIncrement)
+	case tengo.ChangeCharSet:
+		return fmt.Sprintf("changes default character set to %s (collation %s)", clause.ToCharSet, clause.ToCollation)
+	case tengo.ChangeCreateOptions:
+		return "changes table options"
+	case tengo.ChangeComment:
+		return "changes table comment"
+	case tengo.ChangeTablespace:
+		return "changes tablespace to " + clause.NewTablespace
+	case tengo.ChangeStorageEngine:
+		return "changes storage engine to " + clause.NewStorageEngine
+	case tengo.PartitionBy:
+		return "partitions table"
+	case tengo.RemovePartitioning:
+		return "removes partitioning"
+	case tengo.ModifyPartitions:
+		return fmt.Sprintf("adds %s, drops %s", countAndNoun(len(clause.Add), "partition"), countAndNoun(len(clause.Drop), "partition"))
+	}
+	return strings.ToLower(clause.Clause(tengo.StatementModifiers{}))
+}
+
+func describeIndex(idx *tengo.Index) string {
+	if idx.PrimaryKey {
+		return "primary key"
+	}
+	var kind string
+	if idx.Unique {
+		kind = "unique "
+	} else if idx.Type != "BTREE" && idx.Type != "" {
+		kind = strings.ToLower(idx.Type) + " "
+	}
+	return kind + "index " + tengo.EscapeIdentifier(idx.Name)
+}
+
+// explainPrinter displays a plain-language explanation of each statement,
+// instead of the statement's DDL.
+type explainPrinter struct {
+	lastInstance string
+	lastSchema   string
+	m            sync.Mutex
+}
+
+// Print outputs an explanation of stmt to STDOUT, in a way that prevents
+// interleaving of output from multiple goroutines.
+func (p *explainPrinter) Print(stmt PlannedStatement) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	cs := stmt.ClientState()
+	if cs.InstanceName != p.lastInstance {
+		fmt.Printf("-- instance: %s\n", cs.InstanceName)
+		p.lastInstance = cs.InstanceName
+		p.lastSchema = ""
+	}
+	if cs.SchemaName != p.lastSchema && cs.SchemaName != "" {
+		fmt.Printf("-- schema: %s\n", cs.SchemaName)
+		p.lastSchema = cs.SchemaName
+	}
+	if explainer, ok := stmt.(Explainer); ok {
+		fmt.Println(explainer.Explain().String())
+	} else {
+		fmt.Println(stmt.Statement())
+	}
+}
@@ -0,0 +1,52 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestDDLStatementExplain(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	emailCol := &tengo.Column{Name: "email", Type: tengo.ParseColumnType("varchar(100)"), Nullable: true, Default: "NULL"}
+	pk := &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	makeTable := func(cols []*tengo.Column, secondaryIndexes ...*tengo.Index) *tengo.Table {
+		table := &tengo.Table{
+			Name:             "users",
+			Engine:           "InnoDB",
+			CharSet:          "latin1",
+			Collation:        "latin1_swedish_ci",
+			Columns:          cols,
+			PrimaryKey:       pk,
+			SecondaryIndexes: secondaryIndexes,
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	from := makeTable([]*tengo.Column{idCol, nameCol})
+	emailIdx := &tengo.Index{Name: "email", Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "email"}}}
+	toAdditive := makeTable([]*tengo.Column{idCol, nameCol, emailCol}, emailIdx)
+	toDestructive := makeTable([]*tengo.Column{idCol, emailCol})
+	invisNameCol := *nameCol
+	invisNameCol.Invisible = true
+	toInvisible := makeTable([]*tengo.Column{idCol, &invisNameCol})
+	toInvisible.CreateStatement = toInvisible.GeneratedCreateStatement(tengo.ParseFlavor("mysql:8.0"))
+
+	cases := []struct {
+		diff     tengo.ObjectDiff
+		expected string
+	}{
+		{tengo.NewCreateTable(from), "[metadata-only] create table `users`: 2 columns; 1 index"},
+		{tengo.NewDropTable(from), "[data-destructive] drop table `users`"},
+		{tengo.NewAlterTable(from, toAdditive), "[table-rebuild] alter table `users`: adds column `email` varchar(100); adds unique index `email`"},
+		{tengo.NewAlterTable(from, toDestructive), "[data-destructive] alter table `users`: drops column `name`; adds column `email` varchar(100)"},
+		{tengo.NewAlterTable(from, toInvisible), "[metadata-only] alter table `users`: makes column `name` invisible (metadata only)"},
+	}
+	for _, c := range cases {
+		ddl := &DDLStatement{diff: c.diff}
+		if actual := ddl.Explain().String(); actual != c.expected {
+			t.Errorf("Unexpected explanation for %s %s:\nexpected: %s\nfound:    %s", c.diff.DiffType(), c.diff.ObjectKey(), c.expected, actual)
+		}
+	}
+}
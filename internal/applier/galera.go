@@ -0,0 +1,106 @@
+package applier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// wsrepMonitor guards pushes to Galera-based clusters (Percona XtraDB Cluster,
+// MariaDB Galera Cluster). Schema changes are refused if the node is not part
+// of the primary component or is not synced, and are optionally paused while
+// the node's receive queue is long enough to trigger flow control.
+type wsrepMonitor struct {
+	db           *sqlx.DB
+	maxRecvQueue int // 0 means no limit
+	pollInterval time.Duration
+}
+
+// newWsrepMonitor returns a wsrepMonitor for t, or nil if t's instance is not
+// a Galera cluster node. An error is returned if Galera-specific options are
+// configured but the instance is not a Galera cluster node.
+func newWsrepMonitor(t *Target) (*wsrepMonitor, error) {
+	config := t.Dir.Config
+	maxRecvQueue, err := config.GetInt("wsrep-max-recv-queue")
+	if err != nil || maxRecvQueue < 0 {
+		return nil, fmt.Errorf("option wsrep-max-recv-queue must be a non-negative integer")
+	}
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		log.Debugf("Unable to determine if %s is a Galera cluster node: %s", t.Instance, err)
+		return nil, nil
+	}
+	status, err := wsrepStatus(db)
+	if err != nil {
+		log.Debugf("Unable to determine if %s is a Galera cluster node: %s", t.Instance, err)
+		return nil, nil
+	} else if _, ok := status["wsrep_cluster_status"]; !ok {
+		if config.Get("wsrep-osu-method") != "" || maxRecvQueue > 0 {
+			return nil, fmt.Errorf("options wsrep-osu-method and wsrep-max-recv-queue may only be used with Galera cluster nodes, but %s is not one", t.Instance)
+		}
+		return nil, nil
+	}
+	if strings.EqualFold(config.Get("wsrep-osu-method"), "RSU") {
+		log.Warnf("%s: using wsrep-osu-method=RSU, so DDL is only applied to this node; push to each cluster node separately", t)
+	}
+	return &wsrepMonitor{
+		db:           db,
+		maxRecvQueue: maxRecvQueue,
+		pollInterval: time.Second,
+	}, nil
+}
+
+// wait returns an error if the node is not in a state suitable for schema
+// changes. Otherwise, it blocks while the node's receive queue exceeds the
+// configured maximum. It is safe to call on a nil receiver.
+func (w *wsrepMonitor) wait(t *Target) error {
+	if w == nil {
+		return nil
+	}
+	var pausedSince time.Time
+	for {
+		status, err := wsrepStatus(w.db)
+		if err != nil {
+			return fmt.Errorf("Unable to check Galera cluster status: %w", err)
+		}
+		if clusterStatus := status["wsrep_cluster_status"]; clusterStatus != "Primary" {
+			return fmt.Errorf("Refusing to run schema changes: Galera node %s has wsrep_cluster_status %s, indicating it is not part of the primary component", t.Instance, clusterStatus)
+		}
+		if state := status["wsrep_local_state_comment"]; state != "Synced" {
+			return fmt.Errorf("Refusing to run schema changes: Galera node %s has wsrep_local_state_comment %s, indicating it is desynced", t.Instance, state)
+		}
+		recvQueue, _ := strconv.Atoi(status["wsrep_local_recv_queue"])
+		if w.maxRecvQueue == 0 || recvQueue <= w.maxRecvQueue {
+			if !pausedSince.IsZero() {
+				log.Infof("Resuming push on %s after pausing for %s", t.Instance, time.Since(pausedSince).Round(time.Second))
+			}
+			return nil
+		}
+		if pausedSince.IsZero() {
+			pausedSince = time.Now()
+			log.Infof("Pausing push on %s: wsrep_local_recv_queue %d exceeds wsrep-max-recv-queue=%d", t.Instance, recvQueue, w.maxRecvQueue)
+		}
+		time.Sleep(w.pollInterval)
+	}
+}
+
+// wsrepStatus returns a map of Galera-related global status variable names to
+// values. The map will be empty if the server is not a Galera cluster node.
+func wsrepStatus(db *sqlx.DB) (map[string]string, error) {
+	var rows []struct {
+		Name  string `db:"Variable_name"`
+		Value string `db:"Value"`
+	}
+	if err := db.Select(&rows, "SHOW GLOBAL STATUS LIKE 'wsrep\\_%'"); err != nil {
+		return nil, err
+	}
+	status := make(map[string]string, len(rows))
+	for _, row := range rows {
+		status[strings.ToLower(row.Name)] = row.Value
+	}
+	return status, nil
+}
@@ -0,0 +1,41 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+func (s ApplierIntegrationSuite) TestNewWsrepMonitor(t *testing.T) {
+	// The test instances are not Galera cluster nodes
+	getMonitor := func(osuMethod, maxRecvQueue string) (*wsrepMonitor, error) {
+		target := &Target{
+			Instance: s.d[0].Instance,
+			Dir: &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{
+				"wsrep-osu-method":     osuMethod,
+				"wsrep-max-recv-queue": maxRecvQueue,
+			})},
+			SchemaName: "product",
+		}
+		return newWsrepMonitor(target)
+	}
+	if w, err := getMonitor("", "0"); w != nil || err != nil {
+		t.Errorf("Expected nil, nil from newWsrepMonitor on non-Galera instance; instead found %+v, %v", w, err)
+	}
+	if _, err := getMonitor("RSU", "0"); err == nil {
+		t.Error("Expected error from newWsrepMonitor with wsrep-osu-method on non-Galera instance, but err was nil")
+	}
+	if _, err := getMonitor("", "100"); err == nil {
+		t.Error("Expected error from newWsrepMonitor with wsrep-max-recv-queue on non-Galera instance, but err was nil")
+	}
+	if _, err := getMonitor("", "-1"); err == nil {
+		t.Error("Expected error from newWsrepMonitor with invalid wsrep-max-recv-queue, but err was nil")
+	}
+
+	// wait is a no-op on a nil monitor
+	var w *wsrepMonitor
+	if err := w.wait(nil); err != nil {
+		t.Errorf("Unexpected error from wait on nil wsrepMonitor: %v", err)
+	}
+}
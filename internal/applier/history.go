@@ -0,0 +1,142 @@
+package applier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// historyTableName is the name of the table which stores a record of each
+// statement executed by push, within the schema specified by the
+// history-schema option.
+const historyTableName = "_skeema_history"
+
+// history records each statement executed by push, along with its checksum,
+// duration, the operating system user running Skeema, and the git commit of
+// the schema repo. This provides an audit trail without requiring a
+// migration-based workflow.
+type history struct {
+	db     *sqlx.DB
+	table  string // escaped schema-qualified name of the history table
+	target *Target
+	user   string
+	gitSHA string
+}
+
+// HistoryEntry represents a single row of the history table.
+type HistoryEntry struct {
+	ID         uint64    `db:"id"`
+	SchemaName string    `db:"schema_name"`
+	ObjectType string    `db:"object_type"`
+	ObjectName string    `db:"object_name"`
+	Statement  string    `db:"statement"`
+	Checksum   string    `db:"checksum"`
+	DurationMS uint64    `db:"duration_ms"`
+	User       string    `db:"os_user"`
+	GitSHA     string    `db:"git_sha"`
+	Success    bool      `db:"success"`
+	Error      string    `db:"error"`
+	ExecutedAt time.Time `db:"executed_at"`
+}
+
+// newHistory returns a history for t, creating the history schema and table
+// if they do not already exist. If the history-schema option is not set, nil
+// is returned.
+func newHistory(t *Target) (*history, error) {
+	schemaName := t.Dir.Config.Get("history-schema")
+	if schemaName == "" {
+		return nil, nil
+	} else if schemaName == t.SchemaName {
+		return nil, fmt.Errorf("option history-schema cannot be set to %s, since this schema is being pushed", schemaName)
+	}
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	h := &history{
+		db:     db,
+		table:  historyTable(schemaName),
+		target: t,
+	}
+	if u, err := user.Current(); err == nil {
+		h.user = u.Username
+	}
+	if h.gitSHA, err = util.GitHeadSHA(t.Dir.Path); err != nil {
+		log.Debug(err)
+	}
+	create := `CREATE TABLE IF NOT EXISTS ` + h.table + ` (
+		id bigint unsigned NOT NULL AUTO_INCREMENT,
+		schema_name varchar(64) NOT NULL,
+		object_type varchar(20) NOT NULL,
+		object_name varchar(64) NOT NULL,
+		statement longtext NOT NULL,
+		checksum char(64) CHARACTER SET ascii NOT NULL,
+		duration_ms bigint unsigned NOT NULL,
+		os_user varchar(128) NOT NULL,
+		git_sha varchar(64) CHARACTER SET ascii NOT NULL,
+		success tinyint(1) NOT NULL,
+		error text NOT NULL,
+		executed_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY schema_executed (schema_name, executed_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+	statements := []string{
+		"CREATE DATABASE IF NOT EXISTS " + tengo.EscapeIdentifier(schemaName),
+		create,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("Unable to create push history table: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// record inserts a row for stmt, which began executing at start and returned
+// execErr. Failure to record is logged, but otherwise does not affect the push.
+func (h *history) record(stmt PlannedStatement, start time.Time, execErr error) {
+	var objectType, objectName, errText string
+	if ddl, ok := stmt.(*DDLStatement); ok {
+		key := ddl.diff.ObjectKey()
+		objectType, objectName = string(key.Type), key.Name
+	}
+	if execErr != nil {
+		errText = execErr.Error()
+	}
+	sum := sha256.Sum256([]byte(stmt.Statement()))
+	query := "INSERT INTO " + h.table + ` (schema_name, object_type, object_name, statement, checksum, duration_ms, os_user, git_sha, success, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := h.db.Exec(query, h.target.SchemaName, objectType, objectName, stmt.Statement(), hex.EncodeToString(sum[:]),
+		time.Since(start).Milliseconds(), h.user, h.gitSHA, execErr == nil, errText)
+	if err != nil {
+		log.Warnf("Unable to record statement in push history for %s: %s", h.target, err)
+	}
+}
+
+// QueryHistory returns up to limit of the most recent entries in the history
+// table in historySchema on inst, for the supplied schemaName. Entries are
+// returned in reverse chronological order.
+func QueryHistory(inst *tengo.Instance, historySchema, schemaName string, limit int) ([]HistoryEntry, error) {
+	db, err := inst.CachedConnectionPool("", "parseTime=true")
+	if err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	query := "SELECT id, schema_name, object_type, object_name, statement, checksum, duration_ms, os_user, git_sha, success, error, executed_at FROM " +
+		historyTable(historySchema) + " WHERE schema_name = ? ORDER BY id DESC LIMIT ?"
+	if err := db.Select(&entries, query, schemaName, limit); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func historyTable(schemaName string) string {
+	return tengo.EscapeIdentifier(schemaName) + "." + tengo.EscapeIdentifier(historyTableName)
+}
@@ -0,0 +1,118 @@
+package applier
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/shellout"
+)
+
+// hooks runs the commands configured by the before-push, after-push,
+// before-statement, and after-statement options for a single Target. Hooks are
+// only run when statements are actually executed, never in dry-run mode.
+//
+// Rather than using {VARIABLE} placeholders like the wrapper options, hook
+// commands receive information about the target and statement via environment
+// variables prefixed with SKEEMA_. This avoids any need to escape statement
+// text for use on a shell command-line.
+//
+// A before-push or before-statement hook which returns a non-zero exit code
+// prevents execution, which permits hooks to implement approval workflows.
+// Failures in after-push or after-statement hooks are logged, but do not
+// otherwise affect the push.
+type hooks struct {
+	target         *Target
+	statementCount int
+}
+
+func newHooks(t *Target, statementCount int) *hooks {
+	return &hooks{
+		target:         t,
+		statementCount: statementCount,
+	}
+}
+
+// beforePush runs the before-push hook, if configured.
+func (h *hooks) beforePush() error {
+	return h.run("before-push", nil, nil)
+}
+
+// afterPush runs the after-push hook, if configured. pushErr should be the
+// error which halted the push, or nil if all statements executed successfully.
+func (h *hooks) afterPush(pushErr error) {
+	if err := h.run("after-push", nil, pushErr); err != nil {
+		log.Warn(err)
+	}
+}
+
+// beforeStatement runs the before-statement hook for stmt, if configured.
+func (h *hooks) beforeStatement(stmt PlannedStatement) error {
+	return h.run("before-statement", stmt, nil)
+}
+
+// afterStatement runs the after-statement hook for stmt, if configured.
+// execErr should be the error returned by executing stmt.
+func (h *hooks) afterStatement(stmt PlannedStatement, execErr error) {
+	if err := h.run("after-statement", stmt, execErr); err != nil {
+		log.Warn(err)
+	}
+}
+
+// run executes the command configured in option, if any, from the target dir's
+// path.
+func (h *hooks) run(option string, stmt PlannedStatement, result error) error {
+	commandLine := h.target.Dir.Config.Get(option)
+	if commandLine == "" {
+		return nil
+	}
+	log.Debugf("Running %s hook for %s", option, h.target)
+	cmd := shellout.New(commandLine).WithWorkingDir(h.target.Dir.Path).WithEnv(h.env(option, stmt, result)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook for %s failed: %w", option, h.target, err)
+	}
+	return nil
+}
+
+// env returns the environment variables supplied to a hook command. The
+// statement-related variables are only set if stmt is non-nil, and the result
+// variables are only set for after-push and after-statement hooks.
+func (h *hooks) env(option string, stmt PlannedStatement, result error) []string {
+	inst := h.target.Instance
+	var port string
+	if inst.SocketPath == "" {
+		port = strconv.Itoa(inst.Port)
+	}
+	vars := map[string]string{
+		"SKEEMA_HOOK":            option,
+		"SKEEMA_HOST":            inst.Host,
+		"SKEEMA_PORT":            port,
+		"SKEEMA_SOCKET":          inst.SocketPath,
+		"SKEEMA_SCHEMA":          h.target.SchemaName,
+		"SKEEMA_ENVIRONMENT":     h.target.Dir.Config.Get("environment"),
+		"SKEEMA_DIRPATH":         h.target.Dir.Path,
+		"SKEEMA_STATEMENT_COUNT": strconv.Itoa(h.statementCount),
+	}
+	if stmt != nil {
+		vars["SKEEMA_STATEMENT"] = stmt.Statement()
+		if ddl, ok := stmt.(*DDLStatement); ok {
+			key := ddl.diff.ObjectKey()
+			vars["SKEEMA_OBJECT_TYPE"] = key.Type.Caps()
+			vars["SKEEMA_OBJECT_NAME"] = key.Name
+			vars["SKEEMA_DIFF_TYPE"] = ddl.diff.DiffType().String()
+		}
+	}
+	if option == "after-push" || option == "after-statement" {
+		if result == nil {
+			vars["SKEEMA_STATUS"] = "success"
+		} else {
+			vars["SKEEMA_STATUS"] = "failure"
+			vars["SKEEMA_ERROR"] = result.Error()
+		}
+	}
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
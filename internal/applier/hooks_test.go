@@ -0,0 +1,151 @@
+package applier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// fakeStatement is a PlannedStatement which doesn't interact with a database.
+type fakeStatement struct {
+	stmt string
+	err  error
+}
+
+func (s *fakeStatement) Execute() error           { return s.err }
+func (s *fakeStatement) Statement() string        { return s.stmt }
+func (s *fakeStatement) ClientState() ClientState { return ClientState{Delimiter: ";"} }
+
+type fakePrinter struct{}
+
+func (fakePrinter) Print(PlannedStatement) {}
+
+// fakeRunSettings returns a map of all options used by Plan.Run, suitable for
+// use with mybase.SimpleConfig. Options in overrides replace the defaults.
+func fakeRunSettings(overrides map[string]string) map[string]string {
+	settings := map[string]string{
+		"dry-run":              "0",
+		"environment":          "production",
+		"journal-schema":       "",
+		"history-schema":       "",
+		"push-lock":            "0",
+		"replicas":             "",
+		"max-replica-lag":      "0",
+		"retry-attempts":       "0",
+		"retry-backoff":        "1s",
+		"mdl-report-after":     "0",
+		"mdl-kill-idle":        "0",
+		"mdl-kill-users":       "",
+		"wsrep-osu-method":     "",
+		"wsrep-max-recv-queue": "0",
+		"discover-primary":     "0",
+		"verify-writable":      "0",
+		"state-file":           "",
+		"resume":               "0",
+		"before-push":          "",
+		"after-push":           "",
+		"before-statement":     "",
+		"after-statement":      "",
+	}
+	for k, v := range overrides {
+		settings[k] = v
+	}
+	return settings
+}
+
+func TestPlanRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses POSIX shell commands")
+	}
+	tempDir := t.TempDir()
+	logFile := filepath.Join(tempDir, "hooks.log")
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	makePlan := func(options map[string]string, statements ...PlannedStatement) *Plan {
+		settings := fakeRunSettings(options)
+		target := &Target{
+			Instance:   inst,
+			Dir:        &fs.Dir{Path: tempDir, Config: mybase.SimpleConfig(settings)},
+			SchemaName: "product",
+		}
+		return &Plan{Target: target, Statements: statements}
+	}
+	readLog := func() []string {
+		t.Helper()
+		contents, err := os.ReadFile(logFile)
+		os.Remove(logFile)
+		if err != nil {
+			t.Fatalf("Unable to read hook log: %v", err)
+		}
+		return strings.Split(strings.TrimSpace(string(contents)), "\n")
+	}
+
+	// Confirm hooks are run in the expected order, with expected env vars
+	options := map[string]string{
+		"before-push":      `echo "$SKEEMA_HOOK $SKEEMA_HOST:$SKEEMA_PORT $SKEEMA_SCHEMA $SKEEMA_STATEMENT_COUNT" >> hooks.log`,
+		"after-push":       `echo "$SKEEMA_HOOK $SKEEMA_STATUS" >> hooks.log`,
+		"before-statement": `echo "$SKEEMA_HOOK $SKEEMA_STATEMENT" >> hooks.log`,
+		"after-statement":  `echo "$SKEEMA_HOOK $SKEEMA_STATUS${SKEEMA_ERROR:+ $SKEEMA_ERROR}" >> hooks.log`,
+	}
+	plan := makePlan(options, &fakeStatement{stmt: "CREATE TABLE foo (id int)"}, &fakeStatement{stmt: "DROP TABLE bar", err: errors.New("boom")}, &fakeStatement{stmt: "DROP TABLE baz"})
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 2 {
+		t.Errorf("Expected skipCount of 2, instead found %d", skipCount)
+	}
+	expected := []string{
+		"before-push 127.0.0.1:3306 product 3",
+		"before-statement CREATE TABLE foo (id int)",
+		"after-statement success",
+		"before-statement DROP TABLE bar",
+		"after-statement failure boom",
+		"after-push failure",
+	}
+	if actual := readLog(); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected hook output:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+
+	// Confirm a failing before-push hook skips the target entirely
+	options = map[string]string{
+		"before-push":     "echo before >> hooks.log; false",
+		"after-statement": "echo after >> hooks.log",
+	}
+	plan = makePlan(options, &fakeStatement{stmt: "CREATE TABLE foo (id int)"})
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 1 {
+		t.Errorf("Expected skipCount of 1, instead found %d", skipCount)
+	}
+	if actual := readLog(); len(actual) != 1 || actual[0] != "before" {
+		t.Errorf("Unexpected hook output: %q", actual)
+	}
+
+	// Confirm a failing before-statement hook halts execution
+	options = map[string]string{
+		"before-statement": `echo "$SKEEMA_STATEMENT" >> hooks.log; [ "$SKEEMA_STATEMENT" != "DROP TABLE bar" ]`,
+		"after-push":       `echo "after-push $SKEEMA_STATUS" >> hooks.log`,
+	}
+	plan = makePlan(options, &fakeStatement{stmt: "CREATE TABLE foo (id int)"}, &fakeStatement{stmt: "DROP TABLE bar"}, &fakeStatement{stmt: "DROP TABLE baz"})
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 2 {
+		t.Errorf("Expected skipCount of 2, instead found %d", skipCount)
+	}
+	expected = []string{"CREATE TABLE foo (id int)", "DROP TABLE bar", "after-push failure"}
+	if actual := readLog(); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected hook output:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+
+	// Confirm hooks are not run in dry-run mode
+	options["dry-run"] = "1"
+	plan = makePlan(options, &fakeStatement{stmt: "CREATE TABLE foo (id int)"})
+	if skipCount := plan.Run(fakePrinter{}); skipCount != 0 {
+		t.Errorf("Expected skipCount of 0, instead found %d", skipCount)
+	}
+	if _, err := os.Stat(logFile); err == nil {
+		t.Error("Expected hooks to not run with dry-run, but hook log exists")
+	}
+}
@@ -0,0 +1,265 @@
+package applier
+
+import (
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// htmlReport is the data rendered by htmlReportTemplate.
+type htmlReport struct {
+	Generated        string
+	Targets          []htmlTargetReport
+	TargetDiff       int            // number of targets with at least one difference
+	Actions          map[string]int // statement count by action
+	Impacts          map[string]int // statement count by impact class
+	Blocked          int            // statements which are unsafe, protected, or high-impact
+	UnsupportedCount int
+}
+
+type htmlTargetReport struct {
+	Name        string
+	Objects     []htmlObjectReport
+	Unsupported []string
+}
+
+type htmlObjectReport struct {
+	Key          string
+	Action       string
+	Impact       Impact
+	Changes      []string
+	Problems     []string
+	Statement    string
+	Rows         []htmlDiffRow
+	NoDefinition bool // true if neither side has a CREATE statement to compare
+}
+
+// htmlDiffRow is a single row of a side-by-side diff. Class is one of "same",
+// "del", "add", or "chg".
+type htmlDiffRow struct {
+	Class       string
+	Left, Right string
+	LeftNo      int
+	RightNo     int
+}
+
+// WriteHTMLReport writes a self-contained HTML document to path, containing a
+// summary of the supplied plans followed by a side-by-side comparison of each
+// changed object's CREATE statement on the target vs the filesystem.
+func WriteHTMLReport(path string, plans []*Plan) error {
+	plans = slices.Clone(plans)
+	slices.SortFunc(plans, func(a, b *Plan) int {
+		if a.Target.Instance.String() != b.Target.Instance.String() {
+			return strings.Compare(a.Target.Instance.String(), b.Target.Instance.String())
+		}
+		return strings.Compare(a.Target.SchemaName, b.Target.SchemaName)
+	})
+	report := &htmlReport{
+		Generated: time.Now().UTC().Format("2006-01-02 15:04:05 MST"),
+		Actions:   make(map[string]int),
+		Impacts:   make(map[string]int),
+	}
+	for _, plan := range plans {
+		if len(plan.Statements) == 0 && len(plan.Unsupported) == 0 {
+			continue
+		}
+		report.TargetDiff++
+		report.Targets = append(report.Targets, newHTMLTargetReport(plan, report))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := htmlReportTemplate.Execute(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func newHTMLTargetReport(plan *Plan, report *htmlReport) htmlTargetReport {
+	tr := htmlTargetReport{Name: plan.Target.String()}
+	problems := make(map[string][]string) // statement text -> reasons blocked
+	for _, list := range [][]UnsafeStatement{plan.Unsafe, plan.Protected, plan.HighImpact, plan.DataLoss} {
+		for _, us := range list {
+			problems[us.Statement] = append(problems[us.Statement], us.Reason)
+		}
+	}
+	for _, stmt := range plan.Statements {
+		or := htmlObjectReport{Statement: stmt.Statement()}
+		var from, to string
+		if ddl, ok := stmt.(*DDLStatement); ok {
+			or.Problems = problems[ddl.stmt]
+			from, to = createStatementsForDiff(ddl.diff)
+		}
+		if explainer, ok := stmt.(Explainer); ok {
+			e := explainer.Explain()
+			or.Key, or.Action, or.Impact, or.Changes = e.Key.String(), e.Action, e.Impact, e.Changes
+			report.Actions[e.Action]++
+			report.Impacts[string(e.Impact)]++
+		}
+		if len(or.Problems) > 0 {
+			report.Blocked++
+		}
+		if from == "" && to == "" {
+			or.NoDefinition = true
+		} else {
+			or.Rows = sideBySideDiff(from, to)
+		}
+		tr.Objects = append(tr.Objects, or)
+	}
+	for key := range plan.Unsupported {
+		tr.Unsupported = append(tr.Unsupported, key.String())
+	}
+	slices.Sort(tr.Unsupported)
+	report.UnsupportedCount += len(tr.Unsupported)
+	return tr
+}
+
+// createStatementsForDiff returns the CREATE statements of the "from" (target)
+// and "to" (filesystem) sides of diff. Either may be blank if the object does
+// not exist on that side, and both are blank for diff types which do not
+// correspond to a single CREATE statement.
+func createStatementsForDiff(diff tengo.ObjectDiff) (from, to string) {
+	switch diff := diff.(type) {
+	case *tengo.TableDiff:
+		if diff.From != nil {
+			from = diff.From.CreateStatement
+		}
+		if diff.To != nil {
+			to = diff.To.CreateStatement
+		}
+	case *tengo.RoutineDiff:
+		if diff.From != nil {
+			from = diff.From.CreateStatement
+		}
+		if diff.To != nil {
+			to = diff.To.CreateStatement
+		}
+	}
+	return from, to
+}
+
+// sideBySideDiff compares from and to line-by-line, returning rows suitable
+// for a two-column display. Replaced lines are paired up where possible.
+func sideBySideDiff(from, to string) []htmlDiffRow {
+	var a, b []string
+	if from != "" {
+		a = strings.Split(from, "\n")
+	}
+	if to != "" {
+		b = strings.Split(to, "\n")
+	}
+	var rows []htmlDiffRow
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			for n := 0; n < op.I2-op.I1; n++ {
+				rows = append(rows, htmlDiffRow{Class: "same", Left: a[op.I1+n], LeftNo: op.I1 + n + 1, Right: b[op.J1+n], RightNo: op.J1 + n + 1})
+			}
+		case 'd':
+			for i := op.I1; i < op.I2; i++ {
+				rows = append(rows, htmlDiffRow{Class: "del", Left: a[i], LeftNo: i + 1})
+			}
+		case 'i':
+			for j := op.J1; j < op.J2; j++ {
+				rows = append(rows, htmlDiffRow{Class: "add", Right: b[j], RightNo: j + 1})
+			}
+		case 'r':
+			for n := 0; n < max(op.I2-op.I1, op.J2-op.J1); n++ {
+				row := htmlDiffRow{Class: "chg"}
+				if i := op.I1 + n; i < op.I2 {
+					row.Left, row.LeftNo = a[i], i+1
+				}
+				if j := op.J1 + n; j < op.J2 {
+					row.Right, row.RightNo = b[j], j+1
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Skeema diff report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { margin-bottom: 0; }
+.generated { color: #57606a; margin-top: 0.25em; }
+.dashboard { display: flex; flex-wrap: wrap; gap: 1em; margin: 1.5em 0; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: 0.75em 1.25em; min-width: 8em; }
+.card .n { font-size: 2em; font-weight: bold; }
+.card.warn .n { color: #cf222e; }
+.target { margin-top: 2.5em; border-top: 2px solid #d0d7de; }
+.object { margin: 1.5em 0; }
+.tag { display: inline-block; border-radius: 4px; padding: 0 0.4em; font-size: 0.85em; background: #eaeef2; }
+.tag.data-destructive, .tag.lock-heavy { background: #ffebe9; color: #cf222e; }
+.tag.table-rebuild { background: #fff8c5; color: #7d4e00; }
+.tag.metadata-only, .tag.online-capable { background: #dafbe1; color: #116329; }
+.problem { color: #cf222e; font-weight: bold; }
+pre.sql { background: #f6f8fa; padding: 0.75em; overflow-x: auto; }
+table.diff { border-collapse: collapse; width: 100%; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 0.85em; table-layout: fixed; }
+table.diff th { text-align: left; background: #f6f8fa; padding: 0.25em 0.5em; }
+table.diff td { padding: 0 0.5em; white-space: pre-wrap; word-break: break-all; vertical-align: top; }
+table.diff td.no { width: 3em; color: #57606a; text-align: right; user-select: none; }
+tr.del td.l, tr.chg td.l { background: #ffebe9; }
+tr.add td.r, tr.chg td.r { background: #dafbe1; }
+</style>
+</head>
+<body>
+<h1>Skeema diff report</h1>
+<p class="generated">Generated {{.Generated}}</p>
+<div class="dashboard">
+<div class="card"><div class="n">{{.TargetDiff}}</div>targets with differences</div>
+<div class="card"><div class="n">{{index .Actions "create"}}</div>created</div>
+<div class="card"><div class="n">{{index .Actions "alter"}}</div>altered</div>
+<div class="card"><div class="n">{{index .Actions "drop"}}</div>dropped</div>
+<div class="card{{if index .Impacts "data-destructive"}} warn{{end}}"><div class="n">{{index .Impacts "data-destructive"}}</div>destructive</div>
+<div class="card{{if .Blocked}} warn{{end}}"><div class="n">{{.Blocked}}</div>blocked</div>
+<div class="card{{if .UnsupportedCount}} warn{{end}}"><div class="n">{{.UnsupportedCount}}</div>unsupported</div>
+</div>
+{{- if not .Targets}}
+<p>No differences found.</p>
+{{- end}}
+{{- range .Targets}}
+<div class="target">
+<h2>{{.Name}}</h2>
+{{- range .Objects}}
+<div class="object">
+<h3>{{if .Key}}{{.Action}} {{.Key}}{{else}}Statement{{end}}
+{{- if .Impact}} <span class="tag {{.Impact}}">{{.Impact}}</span>{{end}}</h3>
+{{- range .Problems}}
+<p class="problem">{{.}}</p>
+{{- end}}
+{{- if .Changes}}
+<ul>{{range .Changes}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+<pre class="sql">{{.Statement}}</pre>
+{{- if not .NoDefinition}}
+<table class="diff">
+<tr><th colspan="2">Database server</th><th colspan="2">Filesystem</th></tr>
+{{- range .Rows}}
+<tr class="{{.Class}}"><td class="no">{{if .LeftNo}}{{.LeftNo}}{{end}}</td><td class="l">{{.Left}}</td><td class="no">{{if .RightNo}}{{.RightNo}}{{end}}</td><td class="r">{{.Right}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</div>
+{{- end}}
+{{- range .Unsupported}}
+<p class="problem">Unsupported: {{.}}</p>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
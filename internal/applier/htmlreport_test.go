@@ -0,0 +1,91 @@
+package applier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestSideBySideDiff(t *testing.T) {
+	from := "CREATE TABLE `t` (\n  `id` int,\n  `name` varchar(20)\n)"
+	to := "CREATE TABLE `t` (\n  `id` int,\n  `name` varchar(40),\n  `email` varchar(80)\n)"
+	rows := sideBySideDiff(from, to)
+	expectClasses := []string{"same", "same", "chg", "chg", "same"}
+	if len(rows) != len(expectClasses) {
+		t.Fatalf("Expected %d rows, instead found %d: %+v", len(expectClasses), len(rows), rows)
+	}
+	for n, row := range rows {
+		if row.Class != expectClasses[n] {
+			t.Errorf("Row %d: expected class %q, instead found %q", n, expectClasses[n], row.Class)
+		}
+	}
+	if rows[3].Left != "" || rows[3].LeftNo != 0 || rows[3].Right != "  `email` varchar(80)" || rows[3].RightNo != 4 {
+		t.Errorf("Unexpected fields in row 3: %+v", rows[3])
+	}
+	if rows[4].LeftNo != 4 || rows[4].RightNo != 5 {
+		t.Errorf("Unexpected line numbers in row 4: %+v", rows[4])
+	}
+
+	// Creates and drops should be entirely one-sided
+	for _, row := range sideBySideDiff("", to) {
+		if row.Class != "add" || row.Left != "" {
+			t.Errorf("Unexpected row for create: %+v", row)
+		}
+	}
+	for _, row := range sideBySideDiff(from, "") {
+		if row.Class != "del" || row.Right != "" {
+			t.Errorf("Unexpected row for drop: %+v", row)
+		}
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: []*tengo.Column{idCol}}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+	dropDDL := &DDLStatement{diff: tengo.NewDropTable(table), stmt: "DROP TABLE `users`"}
+	dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{"dry-run": "1"})}
+	plans := []*Plan{
+		{
+			Target:     &Target{Instance: inst, Dir: dir, SchemaName: "product"},
+			Statements: []PlannedStatement{dropDDL},
+			Unsafe:     []UnsafeStatement{{Key: table.ObjectKey(), Statement: dropDDL.stmt, Reason: "DROP TABLE is unsafe <careful>"}},
+		},
+		{Target: &Target{Instance: inst, Dir: dir, SchemaName: "analytics"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := WriteHTMLReport(path, plans); err != nil {
+		t.Fatalf("Unexpected error from WriteHTMLReport: %v", err)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read report: %v", err)
+	}
+	html := string(contents)
+	expected := []string{
+		"<!DOCTYPE html>",
+		`<div class="n">1</div>targets with differences`,
+		`<div class="card warn"><div class="n">1</div>destructive`,
+		`<span class="tag data-destructive">data-destructive</span>`,
+		"DROP TABLE is unsafe &lt;careful&gt;",
+		`<tr class="del">`,
+	}
+	for _, substr := range expected {
+		if !strings.Contains(html, substr) {
+			t.Errorf("Expected report to contain %q, but it did not. Full report:\n%s", substr, html)
+		}
+	}
+	if strings.Contains(html, "analytics") || strings.Contains(html, `<tr class="add">`) {
+		t.Errorf("Report contains unexpected content:\n%s", html)
+	}
+}
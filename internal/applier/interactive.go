@@ -0,0 +1,128 @@
+package applier
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Approval represents an operator's response to an interactive prompt.
+type Approval int
+
+// Constants enumerating approval responses
+const (
+	ApprovalYes  Approval = iota // execute the statement, or review the target's statements individually
+	ApprovalNo                   // skip the statement or target
+	ApprovalAll                  // execute the statement and all remaining statements for the target
+	ApprovalQuit                 // skip everything remaining in the push
+)
+
+// Approver is an interface for printers that also require an operator to
+// approve execution of each target and statement.
+type Approver interface {
+	Printer
+	ApproveTarget(plan *Plan) Approval
+	ApproveStatement(stmt PlannedStatement) Approval
+}
+
+// InteractivePrinter wraps another Printer, additionally prompting for
+// approval before each target and statement is executed. Once the operator
+// quits, all further prompts are automatically answered with ApprovalQuit.
+type InteractivePrinter struct {
+	Printer
+	in   *bufio.Reader
+	out  io.Writer
+	quit bool
+	m    sync.Mutex
+}
+
+// NewInteractivePrinter returns an InteractivePrinter wrapping p, reading
+// responses from in and writing prompts to out.
+func NewInteractivePrinter(p Printer, in io.Reader, out io.Writer) *InteractivePrinter {
+	return &InteractivePrinter{
+		Printer: p,
+		in:      bufio.NewReader(in),
+		out:     out,
+	}
+}
+
+// ApproveTarget prompts for whether to proceed with plan's target. The prompt
+// summarizes the impact of the target's statements, most severe first.
+func (ip *InteractivePrinter) ApproveTarget(plan *Plan) Approval {
+	impacts := make(map[Impact]int)
+	for _, stmt := range plan.Statements {
+		if explainer, ok := stmt.(Explainer); ok {
+			impacts[explainer.Explain().Impact]++
+		}
+	}
+	var impactSummary []string
+	for _, impact := range slices.Backward(impactLevels) {
+		if impacts[impact] > 0 {
+			impactSummary = append(impactSummary, fmt.Sprintf("%d %s", impacts[impact], impact))
+		}
+	}
+	prompt := fmt.Sprintf("Push %s to %s", countAndNoun(len(plan.Statements), "statement"), plan.Target)
+	if len(impactSummary) > 0 {
+		prompt += " (" + strings.Join(impactSummary, ", ") + ")"
+	}
+	return ip.prompt(prompt + "? y=review each, n=skip target, all=push all, quit")
+}
+
+// ApproveStatement prompts for whether to execute stmt, which has already been
+// printed. Statements which can explain themselves are annotated with their
+// impact class and a plain-language description.
+func (ip *InteractivePrinter) ApproveStatement(stmt PlannedStatement) Approval {
+	prompt := "Execute this statement?"
+	if explainer, ok := stmt.(Explainer); ok {
+		prompt = "-- " + explainer.Explain().String() + "\n" + prompt
+	}
+	return ip.prompt(prompt)
+}
+
+func (ip *InteractivePrinter) prompt(prompt string) Approval {
+	ip.m.Lock()
+	defer ip.m.Unlock()
+	for !ip.quit {
+		fmt.Fprintf(ip.out, "%s [y/n/all/quit] ", prompt)
+		answer, err := ip.in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return ApprovalYes
+		case "n", "no":
+			return ApprovalNo
+		case "a", "all":
+			return ApprovalAll
+		case "q", "quit":
+			ip.quit = true
+		default:
+			if err != nil { // EOF or read error: treat as quit
+				ip.quit = true
+			}
+		}
+	}
+	return ApprovalQuit
+}
+
+// Finish calls the wrapped printer's Finish method, if it has one.
+func (ip *InteractivePrinter) Finish(t *Target) {
+	if finisher, ok := ip.Printer.(Finisher); ok {
+		finisher.Finish(t)
+	}
+}
+
+// RecordPlan calls the wrapped printer's RecordPlan method, if it has one.
+func (ip *InteractivePrinter) RecordPlan(plan *Plan) {
+	if recorder, ok := ip.Printer.(PlanRecorder); ok {
+		recorder.RecordPlan(plan)
+	}
+}
+
+// ReportResult calls the wrapped printer's ReportResult method, if it has one.
+func (ip *InteractivePrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	if reporter, ok := ip.Printer.(ResultReporter); ok {
+		reporter.ReportResult(t, plan, result, err)
+	}
+}
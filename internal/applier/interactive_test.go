@@ -0,0 +1,83 @@
+package applier
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// countingStatement is a fakeStatement which tracks whether it was executed.
+type countingStatement struct {
+	fakeStatement
+	executed bool
+}
+
+func (s *countingStatement) Execute() error {
+	s.executed = true
+	return s.err
+}
+
+func TestPlanRunInteractive(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	makePlan := func(count int) (*Plan, []*countingStatement) {
+		target := &Target{
+			Instance:   inst,
+			Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(fakeRunSettings(nil))},
+			SchemaName: "product",
+		}
+		plan := &Plan{Target: target}
+		statements := make([]*countingStatement, count)
+		for n := range statements {
+			statements[n] = &countingStatement{fakeStatement: fakeStatement{stmt: "CREATE TABLE foo (id int)"}}
+			plan.Statements = append(plan.Statements, statements[n])
+		}
+		return plan, statements
+	}
+
+	cases := []struct {
+		input           string
+		expectExecuted  []bool
+		expectSkipCount int
+	}{
+		{"y\ny\nn\ny\n", []bool{true, false, true}, 1}, // review each, decline the second
+		{"y\nbogus\ny\nall\n", []bool{true, true, true}, 0},
+		{"all\n", []bool{true, true, true}, 0},
+		{"n\n", []bool{false, false, false}, 3},
+		{"y\ny\nquit\n", []bool{true, false, false}, 2},
+		{"y\nn\n", []bool{false, false, false}, 3}, // EOF is treated as quit
+	}
+	for n, c := range cases {
+		plan, statements := makePlan(len(c.expectExecuted))
+		var out bytes.Buffer
+		printer := NewInteractivePrinter(fakePrinter{}, strings.NewReader(c.input), &out)
+		if skipCount := plan.Run(printer); skipCount != c.expectSkipCount {
+			t.Errorf("Case %d: Expected skip count %d, instead found %d", n, c.expectSkipCount, skipCount)
+		}
+		for i, stmt := range statements {
+			if stmt.executed != c.expectExecuted[i] {
+				t.Errorf("Case %d: Expected statement %d executed=%t, instead found %t", n, i, c.expectExecuted[i], stmt.executed)
+			}
+		}
+		if !strings.Contains(out.String(), "Push 3 statements to") {
+			t.Errorf("Case %d: Target prompt not found in output %q", n, out.String())
+		}
+	}
+
+	// After quitting, subsequent targets should be skipped without prompting
+	var out bytes.Buffer
+	printer := NewInteractivePrinter(fakePrinter{}, strings.NewReader("quit\n"), &out)
+	plan, _ := makePlan(1)
+	plan.Run(printer)
+	out.Reset()
+	plan, statements := makePlan(2)
+	if skipCount := plan.Run(printer); skipCount != 2 || statements[0].executed || out.Len() > 0 {
+		t.Errorf("Unexpected result after quit: skipCount=%d, executed=%t, output=%q", skipCount, statements[0].executed, out.String())
+	}
+}
@@ -0,0 +1,173 @@
+package applier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// journalTableName is the name of the table which stores push journal entries,
+// within the schema specified by the journal-schema option.
+const journalTableName = "push_journal"
+
+// journal records the intent and completion of each statement executed by
+// push, in a table on the target instance. If a statement returns an error,
+// for example due to a network failure, the journal permits detecting whether
+// its effects are nonetheless present, in which case the error is ignored
+// instead of aborting the push. The journal table also records which
+// statements were pending when a push was interrupted, for operators to
+// inspect.
+//
+// Whether a statement's effects are present is determined by comparing a
+// fingerprint of the object's current definition to a fingerprint of its
+// desired definition, which is recorded in the journal prior to executing the
+// statement.
+type journal struct {
+	db     *sqlx.DB
+	table  string // escaped schema-qualified name of the journal table
+	target *Target
+}
+
+// newJournal returns a journal for t, creating the journal schema and table if
+// they do not already exist. If the journal-schema option is not set, nil is
+// returned.
+func newJournal(t *Target) (*journal, error) {
+	schemaName := t.Dir.Config.Get("journal-schema")
+	if schemaName == "" {
+		return nil, nil
+	} else if schemaName == t.SchemaName {
+		return nil, fmt.Errorf("option journal-schema cannot be set to %s, since this schema is being pushed", schemaName)
+	}
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	j := &journal{
+		db:     db,
+		table:  tengo.EscapeIdentifier(schemaName) + "." + tengo.EscapeIdentifier(journalTableName),
+		target: t,
+	}
+	create := `CREATE TABLE IF NOT EXISTS ` + j.table + ` (
+		schema_name varchar(64) NOT NULL,
+		stmt_hash char(64) CHARACTER SET ascii NOT NULL,
+		object_type varchar(20) NOT NULL,
+		object_name varchar(64) NOT NULL,
+		statement longtext NOT NULL,
+		fingerprint char(64) CHARACTER SET ascii NOT NULL,
+		status enum('pending','done') NOT NULL,
+		started_at timestamp NULL DEFAULT NULL,
+		completed_at timestamp NULL DEFAULT NULL,
+		PRIMARY KEY (schema_name, stmt_hash)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+	statements := []string{
+		"CREATE DATABASE IF NOT EXISTS " + tengo.EscapeIdentifier(schemaName),
+		create,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("Unable to create push journal table: %w", err)
+		}
+	}
+	return j, nil
+}
+
+// execute runs stmt, journaling it before and after execution. If stmt returns
+// an error, but its effects are nonetheless present (for example if the
+// connection was lost after the server completed the statement), the error is
+// ignored. Statements whose effects were fully applied by an interrupted push
+// need no special handling here, since a subsequent push's diff won't include
+// them at all.
+func (j *journal) execute(stmt PlannedStatement) error {
+	ddl, ok := stmt.(*DDLStatement)
+	if !ok {
+		return stmt.Execute()
+	}
+	hash := statementHash(ddl)
+	key := ddl.diff.ObjectKey()
+
+	fingerprint := j.desiredFingerprint(ddl)
+	query := "INSERT INTO " + j.table + ` (schema_name, stmt_hash, object_type, object_name, statement, fingerprint, status, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, 'pending', CURRENT_TIMESTAMP)
+		ON DUPLICATE KEY UPDATE fingerprint = VALUES(fingerprint), status = 'pending', started_at = CURRENT_TIMESTAMP, completed_at = NULL`
+	if _, err := j.db.Exec(query, j.target.SchemaName, hash, string(key.Type), key.Name, ddl.Statement(), fingerprint); err != nil {
+		return fmt.Errorf("Unable to write to push journal: %w", err)
+	}
+
+	if err := ddl.Execute(); err != nil {
+		if !j.effectsPresent(ddl, fingerprint) {
+			return err
+		}
+		log.Warnf("Statement for %s returned an error, but its effects are present, so it is treated as successful: %s", key, err)
+	}
+	return j.complete(hash)
+}
+
+// complete marks the journal entry for hash as done.
+func (j *journal) complete(hash string) error {
+	query := "UPDATE " + j.table + " SET status = 'done', completed_at = CURRENT_TIMESTAMP WHERE schema_name = ? AND stmt_hash = ?"
+	if _, err := j.db.Exec(query, j.target.SchemaName, hash); err != nil {
+		return fmt.Errorf("Unable to write to push journal: %w", err)
+	}
+	return nil
+}
+
+// effectsPresent returns true if the current definition of the object affected
+// by ddl matches fingerprint. If fingerprint is blank, or the current
+// definition cannot be obtained, false is returned.
+func (j *journal) effectsPresent(ddl *DDLStatement, fingerprint string) bool {
+	if fingerprint == "" {
+		return false
+	}
+	// Introspect directly, bypassing any shared introspection, since the schema
+	// may have changed since the start of the push
+	current, err := introspectSchema(j.target.Instance, j.target.SchemaName)
+	if err != nil {
+		log.Debugf("Unable to introspect %s to verify push journal entry: %s", j.target, err)
+		return false
+	}
+	current.StripMatches(j.target.Dir.IgnorePatterns)
+	return objectFingerprint(current.Objects()[ddl.diff.ObjectKey()]) == fingerprint
+}
+
+// desiredFingerprint returns the fingerprint of the object affected by ddl, in
+// its desired state after ddl has been executed. A blank string is returned if
+// the desired state cannot be verified via fingerprinting, which is the case
+// for database-level DDL.
+func (j *journal) desiredFingerprint(ddl *DDLStatement) string {
+	key := ddl.diff.ObjectKey()
+	if key.Type == tengo.ObjectTypeDatabase {
+		return ""
+	} else if ddl.diff.DiffType() == tengo.DiffTypeDrop {
+		return objectFingerprint(nil)
+	}
+	desired := j.target.DesiredSchema.Objects()[key]
+	if desired == nil {
+		return ""
+	}
+	return objectFingerprint(desired)
+}
+
+// objectFingerprint returns a hex-encoded SHA-256 hash of obj's definition,
+// ignoring any table-level AUTO_INCREMENT clause. A nil obj, representing an
+// object that does not exist, has a fingerprint of all zeroes.
+func objectFingerprint(obj tengo.DefKeyer) string {
+	var sum [sha256.Size]byte
+	if obj != nil {
+		def := obj.Def()
+		if obj.ObjectKey().Type == tengo.ObjectTypeTable {
+			def, _ = tengo.ParseCreateAutoInc(def)
+		}
+		sum = sha256.Sum256([]byte(def))
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+// statementHash returns a hex-encoded SHA-256 hash identifying stmt.
+func statementHash(stmt PlannedStatement) string {
+	sum := sha256.Sum256([]byte(stmt.Statement()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package applier
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestObjectFingerprint(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned"), AutoIncrement: true}
+	pk := &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: []*tengo.Column{idCol}, PrimaryKey: pk}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+	fp := objectFingerprint(table)
+	if len(fp) != 64 {
+		t.Fatalf("Expected 64-character fingerprint, instead found %q", fp)
+	}
+
+	// Next auto-increment value should not affect the fingerprint
+	table2 := *table
+	table2.NextAutoIncrement = 123
+	table2.CreateStatement = table2.GeneratedCreateStatement(tengo.FlavorUnknown)
+	if !strings.Contains(table2.CreateStatement, "AUTO_INCREMENT=123") {
+		t.Fatalf("Test setup problem: CREATE statement lacks AUTO_INCREMENT clause: %s", table2.CreateStatement)
+	}
+	if fp2 := objectFingerprint(&table2); fp2 != fp {
+		t.Errorf("Expected fingerprint to ignore next auto-increment, but %s != %s", fp2, fp)
+	}
+
+	// Nonexistent object should have a fingerprint that differs from any table
+	if fpNil := objectFingerprint(nil); fpNil != strings.Repeat("0", 64) {
+		t.Errorf("Unexpected fingerprint for nil object: %s", fpNil)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestJournal(t *testing.T) {
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	from, err := s.d[0].Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %s", err)
+	}
+	if _, err := db.Exec("CREATE TABLE journaltest (id int unsigned NOT NULL PRIMARY KEY)"); err != nil {
+		t.Fatalf("Unexpected error creating table: %s", err)
+	}
+	to, err := s.d[0].Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %s", err)
+	}
+	if _, err := db.Exec("DROP TABLE journaltest"); err != nil {
+		t.Fatalf("Unexpected error dropping table: %s", err)
+	}
+
+	cfg := mybase.SimpleConfig(map[string]string{
+		"journal-schema":         "_skeema_journal",
+		"alter-algorithm":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "0",
+		"ddl-wrapper":            "",
+		"osc-tool":               "none",
+		"safe-below-size":        "0",
+		"large-table-size":       "0",
+		"require-osc-size":       "0",
+		"require-osc-rows":       "0",
+		"table-stats":            "false",
+		"foreign-key-checks":     "",
+		"init-sql":               "",
+		"mdl-report-after":       "0",
+		"mdl-kill-idle":          "0",
+		"mdl-kill-users":         "",
+		"statement-timeout":      "0",
+		"wsrep-osu-method":       "",
+	})
+	target := &Target{
+		Instance:      s.d[0].Instance,
+		Dir:           &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName:    "product",
+		DesiredSchema: &workspace.Schema{Schema: to},
+	}
+	j, err := newJournal(target)
+	if err != nil {
+		t.Fatalf("Unexpected error from newJournal: %s", err)
+	}
+	var diff tengo.ObjectDiff
+	for _, od := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		if od.ObjectKey().Name == "journaltest" {
+			diff = od
+		}
+	}
+	ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{Flavor: s.d[0].Flavor()}, target)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewDDLStatement: %s", err)
+	}
+	if err := j.execute(ddl); err != nil {
+		t.Fatalf("Unexpected error from journal.execute: %s", err)
+	}
+	var status string
+	if err := db.QueryRow("SELECT status FROM _skeema_journal.push_journal WHERE schema_name = 'product' AND stmt_hash = ?", statementHash(ddl)).Scan(&status); err != nil {
+		t.Fatalf("Unexpected error querying journal: %s", err)
+	} else if status != "done" {
+		t.Errorf("Expected journal entry to have status done, instead found %s", status)
+	}
+
+	// Re-running the same CREATE TABLE fails since the table now exists, but the
+	// journal should detect that its effects are present and ignore the error
+	if err := j.execute(ddl); err != nil {
+		t.Errorf("Expected journal to ignore error from statement whose effects are present, but it returned error: %s", err)
+	}
+
+	// journal-schema cannot be the schema being pushed
+	target.Dir.Config = mybase.SimpleConfig(map[string]string{"journal-schema": "product"})
+	if _, err := newJournal(target); err == nil {
+		t.Error("Expected error from newJournal with journal-schema equal to target schema, but it was nil")
+	}
+}
@@ -0,0 +1,127 @@
+package applier
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// TargetReport is the JSON representation of the outcome of a single Target,
+// as emitted by --output-format=json.
+type TargetReport struct {
+	ResourceID  string            `json:"resource_id"`
+	Instance    string            `json:"instance"`
+	Schema      string            `json:"schema"`
+	Dir         string            `json:"dir"`
+	DryRun      bool              `json:"dry_run"`
+	Status      string            `json:"status"` // "no-differences", "differences", "pushed", "skipped", or "error"
+	Statements  []StatementReport `json:"statements"`
+	Unsupported []string          `json:"unsupported,omitempty"`
+	SkipCount   int               `json:"skip_count"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// StatementReport is the JSON representation of a single statement within a
+// TargetReport.
+type StatementReport struct {
+	ResourceID string   `json:"resource_id,omitempty"`
+	ObjectType string   `json:"object_type,omitempty"`
+	ObjectName string   `json:"object_name,omitempty"`
+	Action     string   `json:"action,omitempty"`
+	Statement  string   `json:"statement"`
+	Source     string   `json:"source,omitempty"` // file and line range defining the object, e.g. "mydb/users.sql:3-12"
+	Impact     Impact   `json:"impact,omitempty"`
+	Problems   []string `json:"problems,omitempty"`   // reasons the statement was blocked, e.g. unsafe
+	TableSize  *int64   `json:"table_size,omitempty"` // only with table-stats, for ALTER or DROP TABLE
+	TableRows  *int64   `json:"table_rows,omitempty"` // only with table-stats, for ALTER or DROP TABLE
+	Executed   bool     `json:"executed"`
+	Duration   float64  `json:"duration_seconds,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// jsonPrinter emits a JSON document for each target to w, one per line, once
+// the target has been applied. Individual statements are not output as they
+// are printed, since they are included in the target's document.
+type jsonPrinter struct {
+	w io.Writer
+	m sync.Mutex
+}
+
+// Print satisfies the Printer interface, but does not output anything.
+func (jp *jsonPrinter) Print(stmt PlannedStatement) {}
+
+// ReportResult outputs a JSON document describing t.
+func (jp *jsonPrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	report := NewTargetReport(t, plan, result, err)
+	jp.m.Lock()
+	defer jp.m.Unlock()
+	json.NewEncoder(jp.w).Encode(report)
+}
+
+// NewTargetReport returns a TargetReport describing the outcome of applying t.
+func NewTargetReport(t *Target, plan *Plan, result Result, err error) *TargetReport {
+	instance := t.Instance.String()
+	report := &TargetReport{
+		ResourceID: ResourceID(instance, t.SchemaName, tengo.ObjectKey{}),
+		Instance:   instance,
+		Schema:     t.SchemaName,
+		Dir:        t.Dir.RelPath(),
+		DryRun:     t.Dir.Config.GetBool("dry-run"),
+		Statements: []StatementReport{},
+		SkipCount:  result.SkipCount,
+	}
+	switch {
+	case err != nil:
+		report.Status = "error"
+		report.Error = err.Error()
+	case result.SkipCount > 0:
+		report.Status = "skipped"
+	case !result.Differences:
+		report.Status = "no-differences"
+	case report.DryRun:
+		report.Status = "differences"
+	default:
+		report.Status = "pushed"
+	}
+	if plan == nil {
+		return report
+	}
+
+	problems := make(map[string][]string) // statement text -> reasons blocked
+	for _, list := range [][]UnsafeStatement{plan.Unsafe, plan.Protected, plan.HighImpact, plan.DataLoss} {
+		for _, us := range list {
+			problems[us.Statement] = append(problems[us.Statement], us.Reason)
+		}
+	}
+	for n, stmt := range plan.Statements {
+		sr := StatementReport{Statement: stmt.Statement()}
+		if ddl, ok := stmt.(*DDLStatement); ok {
+			sr.Problems, sr.Source = problems[ddl.stmt], ddl.source
+			if ddl.stats != nil && t.Dir.Config.GetBool("table-stats") {
+				sr.TableSize, sr.TableRows = &ddl.stats.size, &ddl.stats.rows
+			}
+		}
+		if explainer, ok := stmt.(Explainer); ok {
+			e := explainer.Explain()
+			sr.ResourceID = ResourceID(instance, t.SchemaName, e.Key)
+			sr.ObjectType, sr.ObjectName = string(e.Key.Type), e.Key.Name
+			sr.Action, sr.Impact = e.Action, e.Impact
+		}
+		if n < len(plan.Results) {
+			sr.Executed = plan.Results[n].Executed
+			sr.Duration = plan.Results[n].Duration.Seconds()
+			if plan.Results[n].Err != nil {
+				sr.Error = plan.Results[n].Err.Error()
+			}
+		}
+		report.Statements = append(report.Statements, sr)
+	}
+	for key := range plan.Unsupported {
+		report.Unsupported = append(report.Unsupported, key.String())
+	}
+	sort.Strings(report.Unsupported)
+	return report
+}
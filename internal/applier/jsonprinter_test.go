@@ -0,0 +1,69 @@
+package applier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestJSONPrinter(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: []*tengo.Column{idCol}}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+	dropDDL := &DDLStatement{diff: tengo.NewDropTable(table), stmt: "DROP TABLE `users`"}
+
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{"dry-run": "0"})},
+		SchemaName: "product",
+	}
+	plan := &Plan{
+		Target:     target,
+		Statements: []PlannedStatement{&fakeStatement{stmt: "CREATE TABLE foo (id int)"}, dropDDL},
+		Unsafe:     []UnsafeStatement{{Key: table.ObjectKey(), Statement: dropDDL.stmt, Reason: "DROP TABLE is unsafe"}},
+		Results:    []StatementResult{{Executed: true}, {Err: errors.New("access denied")}},
+	}
+
+	var out bytes.Buffer
+	jp := &jsonPrinter{w: &out}
+	jp.Print(plan.Statements[0])
+	if out.Len() > 0 {
+		t.Errorf("Expected Print to not output anything, instead found %q", out.String())
+	}
+	jp.ReportResult(target, plan, Result{Differences: true, SkipCount: 1}, nil)
+	jp.ReportResult(target, nil, Result{SkipCount: 1}, errors.New("connection refused"))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines of output, instead found %d: %s", len(lines), out.String())
+	}
+
+	var report TargetReport
+	if err := json.Unmarshal([]byte(lines[0]), &report); err != nil {
+		t.Fatalf("Unable to parse output: %v", err)
+	}
+	if report.Schema != "product" || report.ResourceID != "127.0.0.1:3306/product" || report.Status != "skipped" || report.DryRun || report.SkipCount != 1 || len(report.Statements) != 2 {
+		t.Errorf("Unexpected report: %+v", report)
+	} else if sr := report.Statements[0]; !sr.Executed || sr.Error != "" || sr.Statement != "CREATE TABLE foo (id int)" || sr.Impact != "" || sr.ResourceID != "" {
+		t.Errorf("Unexpected first statement in report: %+v", sr)
+	} else if sr := report.Statements[1]; sr.Executed || sr.Error != "access denied" || sr.ObjectName != "users" || sr.ResourceID != "127.0.0.1:3306/product/table/users" || sr.Action != "drop" || sr.Impact != ImpactDataDestructive || len(sr.Problems) != 1 {
+		t.Errorf("Unexpected second statement in report: %+v", sr)
+	}
+
+	report = TargetReport{}
+	if err := json.Unmarshal([]byte(lines[1]), &report); err != nil {
+		t.Fatalf("Unable to parse output: %v", err)
+	}
+	if report.Status != "error" || report.Error != "connection refused" || report.Statements == nil || len(report.Statements) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,100 @@
+package applier
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// targetLock is an advisory lock, obtained using GET_LOCK(), which prevents
+// multiple concurrent pushes to the same schema on the same instance. Since
+// GET_LOCK() locks are tied to a session, the lock holds a dedicated connection
+// until released.
+type targetLock struct {
+	conn   *sql.Conn
+	name   string
+	target *Target
+}
+
+// acquireTargetLock obtains the advisory lock for t, waiting up to the number
+// of seconds specified by the push-lock-timeout option. If the push-lock option
+// is not enabled, nil is returned. If the lock is held by another session,
+// the returned error describes the lock holder.
+func acquireTargetLock(t *Target) (*targetLock, error) {
+	if !t.Dir.Config.GetBool("push-lock") {
+		return nil, nil
+	}
+	timeout, err := t.Dir.Config.GetInt("push-lock-timeout")
+	if err != nil {
+		return nil, err
+	}
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tl := &targetLock{
+		conn:   conn,
+		name:   targetLockName(t.SchemaName),
+		target: t,
+	}
+	var result sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", tl.name, timeout).Scan(&result); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Unable to obtain push lock: %w", err)
+	} else if result.Int64 != 1 {
+		conn.Close()
+		return nil, fmt.Errorf("Unable to obtain push lock within %d seconds: lock is held by %s", timeout, tl.owner())
+	}
+	log.Debugf("Obtained push lock %s on %s", tl.name, t.Instance)
+	return tl, nil
+}
+
+// owner returns a description of the session currently holding the lock.
+func (tl *targetLock) owner() string {
+	db, err := tl.target.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return "another session"
+	}
+	var connID sql.NullInt64
+	if err := db.QueryRow("SELECT IS_USED_LOCK(?)", tl.name).Scan(&connID); err != nil || !connID.Valid {
+		return "another session"
+	}
+	var user, host string
+	query := "SELECT user, host FROM information_schema.processlist WHERE id = ?"
+	if err := db.QueryRow(query, connID.Int64).Scan(&user, &host); err != nil {
+		return fmt.Sprintf("connection %d", connID.Int64)
+	}
+	return fmt.Sprintf("connection %d (%s@%s)", connID.Int64, user, host)
+}
+
+// release releases the lock and its connection. It is safe to call on a nil
+// receiver.
+func (tl *targetLock) release() {
+	if tl == nil {
+		return
+	}
+	if _, err := tl.conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", tl.name); err != nil {
+		log.Warnf("Unable to release push lock on %s: %s", tl.target.Instance, err)
+	}
+	tl.conn.Close()
+}
+
+// targetLockName returns the GET_LOCK() name used for schemaName. Lock names
+// are limited to 64 characters, so long schema names are hashed.
+func targetLockName(schemaName string) string {
+	name := "skeema.push." + schemaName
+	if len(name) > 64 {
+		sum := sha256.Sum256([]byte(schemaName))
+		name = "skeema.push." + hex.EncodeToString(sum[:])[:52]
+	}
+	return name
+}
@@ -0,0 +1,64 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+func TestTargetLockName(t *testing.T) {
+	if name := targetLockName("product"); name != "skeema.push.product" {
+		t.Errorf("Unexpected lock name %q", name)
+	}
+	longName := strings.Repeat("x", 64)
+	if name := targetLockName(longName); len(name) != 64 || name == targetLockName(longName[1:]) {
+		t.Errorf("Unexpected lock name %q for long schema name", name)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestTargetLock(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{
+		"push-lock":         "1",
+		"push-lock-timeout": "0",
+	})
+	target := &Target{
+		Instance:   s.d[0].Instance,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName: "product",
+	}
+	lock, err := acquireTargetLock(target)
+	if err != nil || lock == nil {
+		t.Fatalf("Unexpected return from acquireTargetLock: %v, %v", lock, err)
+	}
+
+	// A second attempt should fail, and report the lock owner
+	if lock2, err := acquireTargetLock(target); err == nil {
+		lock2.release()
+		t.Error("Expected second acquireTargetLock to fail, but it succeeded")
+	} else if !strings.Contains(err.Error(), "held by connection") {
+		t.Errorf("Expected error to describe lock holder, instead found: %s", err)
+	}
+
+	// Locks on other schemas are independent
+	otherTarget := *target
+	otherTarget.SchemaName = "analytics"
+	if lock2, err := acquireTargetLock(&otherTarget); err != nil {
+		t.Errorf("Unexpected error from acquireTargetLock on another schema: %s", err)
+	} else {
+		lock2.release()
+	}
+
+	lock.release()
+	if lock, err = acquireTargetLock(target); err != nil {
+		t.Errorf("Unexpected error from acquireTargetLock after release: %s", err)
+	}
+	lock.release()
+
+	// Nothing is locked if push-lock is disabled
+	target.Dir.Config = mybase.SimpleConfig(map[string]string{"push-lock": "0"})
+	if lock, err := acquireTargetLock(target); lock != nil || err != nil {
+		t.Errorf("Expected acquireTargetLock to return nil, nil with push-lock disabled; instead found %v, %v", lock, err)
+	}
+}
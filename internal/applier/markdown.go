@@ -0,0 +1,176 @@
+package applier
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Summarizer is an interface for printers that output a summary once all
+// targets have been applied.
+type Summarizer interface {
+	Printer
+	Summarize() error
+}
+
+// markdownPrinter collects a TargetReport for each target, and then outputs a
+// compact Markdown summary of all targets to w, suitable for posting as a
+// pull request comment. Individual statements are not output as they are
+// printed.
+type markdownPrinter struct {
+	w       io.Writer
+	reports []*TargetReport
+	m       sync.Mutex
+}
+
+// Print satisfies the Printer interface, but does not output anything.
+func (mp *markdownPrinter) Print(stmt PlannedStatement) {}
+
+// ReportResult records the outcome of t, for inclusion in the summary.
+func (mp *markdownPrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	report := NewTargetReport(t, plan, result, err)
+	mp.m.Lock()
+	defer mp.m.Unlock()
+	mp.reports = append(mp.reports, report)
+}
+
+// Summarize outputs the Markdown summary. Targets without differences are
+// omitted from the per-target details, but are still counted.
+func (mp *markdownPrinter) Summarize() error {
+	mp.m.Lock()
+	defer mp.m.Unlock()
+	sort.Slice(mp.reports, func(i, j int) bool {
+		if mp.reports[i].Instance != mp.reports[j].Instance {
+			return mp.reports[i].Instance < mp.reports[j].Instance
+		}
+		return mp.reports[i].Schema < mp.reports[j].Schema
+	})
+	var b strings.Builder
+	title := "push"
+	if len(mp.reports) > 0 && mp.reports[0].DryRun {
+		title = "diff"
+	}
+	fmt.Fprintf(&b, "## Skeema %s summary\n\n", title)
+
+	var unchanged int
+	var changed []*TargetReport
+	for _, report := range mp.reports {
+		if report.Status == "no-differences" {
+			unchanged++
+		} else {
+			changed = append(changed, report)
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Fprintf(&b, "No differences found in %s.\n", countAndNoun(unchanged, "target"))
+		_, err := io.WriteString(mp.w, b.String())
+		return err
+	}
+
+	// Targets with identical outcomes, such as identically-defined tenant
+	// schemas, are grouped together to keep the summary compact
+	groups := groupTargetReports(changed)
+	b.WriteString("| Target | Status | Added | Altered | Dropped | Problems |\n")
+	b.WriteString("|---|---|--:|--:|--:|--:|\n")
+	for _, group := range groups {
+		report := group[0]
+		counts := make(map[string]int)
+		var problemCount int
+		for _, sr := range report.Statements {
+			counts[sr.Action]++
+			if len(sr.Problems) > 0 {
+				problemCount++
+			}
+		}
+		problems := "0"
+		if problemCount > 0 {
+			problems = fmt.Sprintf("**%d**", problemCount)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %s |\n", markdownGroupName(group), report.Status, counts["create"], counts["alter"], counts["drop"], problems)
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(&b, "\n%s had no differences.\n", countAndNoun(unchanged, "other target"))
+	}
+
+	for _, group := range groups {
+		report := group[0]
+		fmt.Fprintf(&b, "\n### %s\n\n", markdownGroupName(group))
+		if report.Error != "" {
+			fmt.Fprintf(&b, "**Error:** %s\n\n", markdownEscape(report.Error))
+		}
+		for _, sr := range report.Statements {
+			line := sr.Statement
+			if sr.Action != "" {
+				line = fmt.Sprintf("%s %s `%s`", sr.Action, sr.ObjectType, sr.ObjectName)
+				if sr.Impact != "" {
+					line += fmt.Sprintf(" _(%s)_", sr.Impact)
+				}
+			}
+			if len(sr.Problems) > 0 {
+				fmt.Fprintf(&b, "- :warning: **%s**: %s\n", line, markdownEscape(strings.Join(sr.Problems, " ")))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", line)
+			}
+			if sr.Error != "" {
+				fmt.Fprintf(&b, "  - **Failed:** %s\n", markdownEscape(sr.Error))
+			}
+		}
+		for _, key := range report.Unsupported {
+			fmt.Fprintf(&b, "- :warning: **unsupported** %s\n", key)
+		}
+		if len(report.Statements) > 0 {
+			b.WriteString("\n<details><summary>SQL</summary>\n\n```sql\n")
+			for _, sr := range report.Statements {
+				b.WriteString(sr.Statement)
+				b.WriteString(";\n")
+			}
+			b.WriteString("```\n\n</details>\n")
+		}
+	}
+	_, err := io.WriteString(mp.w, b.String())
+	return err
+}
+
+func markdownTargetName(report *TargetReport) string {
+	return fmt.Sprintf("%s `%s`", report.Instance, report.Schema)
+}
+
+// markdownGroupName returns a name for a group of reports from
+// groupTargetReports, based on its first target.
+func markdownGroupName(group []*TargetReport) string {
+	if len(group) == 1 {
+		return markdownTargetName(group[0])
+	}
+	return markdownTargetName(group[0]) + " and " + countAndNoun(len(group)-1, "other target")
+}
+
+// groupTargetReports groups reports which have the same status, error, and
+// statements, preserving the order of each group's first report.
+func groupTargetReports(reports []*TargetReport) [][]*TargetReport {
+	var groups [][]*TargetReport
+	indexByKey := make(map[string]int)
+	for _, report := range reports {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\x00%s\x00%q\x00", report.Status, report.Error, report.Unsupported)
+		for _, sr := range report.Statements {
+			fmt.Fprintf(&b, "%s\x00%s\x00%s\x00%s\x00%s\x00%q\x00%s\x00", sr.Action, sr.ObjectType, sr.ObjectName, sr.Statement, sr.Impact, sr.Problems, sr.Error)
+		}
+		key := b.String()
+		if n, ok := indexByKey[key]; ok {
+			groups[n] = append(groups[n], report)
+		} else {
+			indexByKey[key] = len(groups)
+			groups = append(groups, []*TargetReport{report})
+		}
+	}
+	return groups
+}
+
+// markdownEscape replaces newlines and escapes characters which would
+// otherwise be interpreted as Markdown table or inline formatting syntax.
+func markdownEscape(s string) string {
+	replacer := strings.NewReplacer("\n", " ", "|", "\\|", "*", "\\*", "_", "\\_", "<", "&lt;")
+	return replacer.Replace(s)
+}
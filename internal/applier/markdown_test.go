@@ -0,0 +1,102 @@
+package applier
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestMarkdownPrinter(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: []*tengo.Column{idCol}}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+	dropDDL := &DDLStatement{diff: tengo.NewDropTable(table), stmt: "DROP TABLE `users`"}
+	dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{"dry-run": "1"})}
+
+	target := &Target{Instance: inst, Dir: dir, SchemaName: "product"}
+	plan := &Plan{
+		Target:     target,
+		Statements: []PlannedStatement{dropDDL},
+		Unsafe:     []UnsafeStatement{{Key: table.ObjectKey(), Statement: dropDDL.stmt, Reason: "DROP TABLE is unsafe"}},
+	}
+
+	var out bytes.Buffer
+	mp := &markdownPrinter{w: &out}
+	mp.Print(dropDDL)
+	mp.ReportResult(target, plan, Result{Differences: true, SkipCount: 1}, nil)
+	mp.ReportResult(&Target{Instance: inst, Dir: dir, SchemaName: "analytics"}, nil, Result{}, nil)
+	mp.ReportResult(&Target{Instance: inst, Dir: dir, SchemaName: "billing"}, nil, Result{SkipCount: 1}, errors.New("connection refused"))
+	if out.Len() > 0 {
+		t.Fatalf("Expected no output prior to Summarize, instead found %q", out.String())
+	}
+	if err := mp.Summarize(); err != nil {
+		t.Fatalf("Unexpected error from Summarize: %v", err)
+	}
+	md := out.String()
+	expected := []string{
+		"## Skeema diff summary",
+		"| 127.0.0.1:3306 `product` | skipped | 0 | 0 | 1 | **1** |",
+		"| 127.0.0.1:3306 `billing` | error | 0 | 0 | 0 | 0 |",
+		"1 other target had no differences.",
+		"- :warning: **drop table `users`",
+		"**Error:** connection refused",
+		"DROP TABLE `users`;\n",
+	}
+	for _, substr := range expected {
+		if !strings.Contains(md, substr) {
+			t.Errorf("Expected output to contain %q, but it did not. Full output:\n%s", substr, md)
+		}
+	}
+	if strings.Contains(md, "analytics") {
+		t.Errorf("Expected target without differences to be omitted, but it was present. Full output:\n%s", md)
+	}
+	if strings.Index(md, "`billing`") > strings.Index(md, "`product`") {
+		t.Errorf("Expected targets to be sorted by schema name. Full output:\n%s", md)
+	}
+
+	// Summary with no differences at all
+	out.Reset()
+	mp = &markdownPrinter{w: &out}
+	mp.ReportResult(target, nil, Result{}, nil)
+	if err := mp.Summarize(); err != nil {
+		t.Fatalf("Unexpected error from Summarize: %v", err)
+	}
+	if md := out.String(); !strings.Contains(md, "No differences found in 1 target.") {
+		t.Errorf("Unexpected output: %s", md)
+	}
+
+	// Targets with identical statements and outcomes are grouped together
+	out.Reset()
+	mp = &markdownPrinter{w: &out}
+	for _, name := range []string{"tenant_3", "tenant_1", "tenant_2"} {
+		tenant := &Target{Instance: inst, Dir: dir, SchemaName: name}
+		mp.ReportResult(tenant, &Plan{Target: tenant, Statements: []PlannedStatement{dropDDL}}, Result{Differences: true}, nil)
+	}
+	mp.ReportResult(&Target{Instance: inst, Dir: dir, SchemaName: "tenant_4"}, nil, Result{SkipCount: 1}, errors.New("connection refused"))
+	if err := mp.Summarize(); err != nil {
+		t.Fatalf("Unexpected error from Summarize: %v", err)
+	}
+	md = out.String()
+	expected = []string{
+		"| 127.0.0.1:3306 `tenant_1` and 2 other targets | differences | 0 | 0 | 1 | 0 |",
+		"| 127.0.0.1:3306 `tenant_4` | error | 0 | 0 | 0 | 0 |",
+		"### 127.0.0.1:3306 `tenant_1` and 2 other targets",
+	}
+	for _, substr := range expected {
+		if !strings.Contains(md, substr) {
+			t.Errorf("Expected output to contain %q, but it did not. Full output:\n%s", substr, md)
+		}
+	}
+	if strings.Count(md, "DROP TABLE `users`;") != 1 {
+		t.Errorf("Expected grouped targets' SQL to only be output once. Full output:\n%s", md)
+	}
+}
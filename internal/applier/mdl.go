@@ -0,0 +1,156 @@
+package applier
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+)
+
+// mdlMonitor watches for a statement blocking on a metadata lock (MDL), and
+// reports which sessions are holding the conflicting locks. Optionally, it can
+// also kill blocking sessions which have been idle for too long, which is a
+// common situation when an application leaves a transaction open.
+type mdlMonitor struct {
+	reportAfter  time.Duration // 0 disables monitoring
+	killIdle     time.Duration // 0 disables killing blockers
+	killUsers    []string      // if non-empty, only kill blockers from these users
+	pollInterval time.Duration
+}
+
+// mdlBlocker represents a session holding a metadata lock which another
+// session is waiting on.
+type mdlBlocker struct {
+	ID      uint64 `db:"id"`
+	User    string `db:"user"`
+	Host    string `db:"host"`
+	Command string `db:"command"`
+	Time    int64  `db:"time"`
+	Info    string `db:"info"`
+}
+
+func (b mdlBlocker) String() string {
+	desc := fmt.Sprintf("connection %d (%s@%s), %s for %ds", b.ID, b.User, b.Host, strings.ToLower(b.Command), b.Time)
+	if b.Info != "" {
+		desc += ": " + b.Info
+	}
+	return desc
+}
+
+// newMDLMonitor returns an mdlMonitor configured using the mdl-report-after,
+// mdl-kill-idle, and mdl-kill-users options. If mdl-report-after is 0, nil is
+// returned.
+func newMDLMonitor(config *mybase.Config) (*mdlMonitor, error) {
+	reportAfter, err := time.ParseDuration(config.Get("mdl-report-after"))
+	if err != nil || reportAfter < 0 {
+		return nil, fmt.Errorf("option mdl-report-after must be a non-negative duration, such as \"10s\"")
+	}
+	killIdle, err := time.ParseDuration(config.Get("mdl-kill-idle"))
+	if err != nil || killIdle < 0 {
+		return nil, fmt.Errorf("option mdl-kill-idle must be a non-negative duration, such as \"60s\"")
+	} else if killIdle > 0 && reportAfter == 0 {
+		return nil, fmt.Errorf("option mdl-kill-idle requires mdl-report-after to be enabled")
+	}
+	if reportAfter == 0 {
+		return nil, nil
+	}
+	return &mdlMonitor{
+		reportAfter:  reportAfter,
+		killIdle:     killIdle,
+		killUsers:    config.GetSlice("mdl-kill-users", ',', true),
+		pollInterval: 2 * time.Second,
+	}, nil
+}
+
+// watch begins monitoring the statement about to be executed by ddl on the
+// connection with ID connID, in a separate goroutine. The returned function
+// must be called once the statement has completed, to stop monitoring. It is
+// safe to call on a nil receiver.
+func (m *mdlMonitor) watch(ddl *DDLStatement, connID int64) (stop func()) {
+	if m == nil || m.reportAfter == 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		reported := make(map[uint64]bool)
+		timer := time.NewTimer(m.reportAfter)
+		defer timer.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+				m.check(ddl, connID, reported)
+				timer.Reset(m.pollInterval)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// check determines whether the connection with ID connID is currently waiting
+// on a metadata lock, and if so, logs any blocking sessions not already in
+// reported. Idle blockers are killed if permitted by the configuration.
+func (m *mdlMonitor) check(ddl *DDLStatement, connID int64, reported map[uint64]bool) {
+	where := ddl.instance.String() + " " + ddl.schemaName
+	db, err := ddl.instance.CachedConnectionPool("", "")
+	if err != nil {
+		log.Debugf("Unable to check metadata locks on %s: %s", ddl.instance, err)
+		return
+	}
+	var waiting bool
+	query := `SELECT 1 FROM information_schema.processlist
+		WHERE id = ? AND state LIKE 'Waiting for %metadata lock'`
+	if err := db.Get(&waiting, query, connID); err != nil {
+		return // not waiting on MDL, or unable to determine; either way nothing to report
+	}
+	var blockers []mdlBlocker
+	query = `SELECT DISTINCT COALESCE(t.processlist_id, 0) AS id, COALESCE(t.processlist_user, '') AS user,
+		       COALESCE(t.processlist_host, '') AS host, COALESCE(t.processlist_command, '') AS command,
+		       COALESCE(t.processlist_time, 0) AS time, COALESCE(t.processlist_info, '') AS info
+		FROM performance_schema.metadata_locks w
+		JOIN performance_schema.threads wt ON wt.thread_id = w.owner_thread_id
+		JOIN performance_schema.metadata_locks g ON g.object_type = w.object_type
+		     AND g.object_schema <=> w.object_schema AND g.object_name <=> w.object_name
+		JOIN performance_schema.threads t ON t.thread_id = g.owner_thread_id
+		WHERE wt.processlist_id = ? AND w.lock_status = 'PENDING'
+		      AND g.lock_status = 'GRANTED' AND t.processlist_id <> ?
+		ORDER BY t.processlist_id`
+	if err := db.Select(&blockers, query, connID, connID); err != nil {
+		if !reported[0] {
+			log.Warnf("%s: statement is waiting on a metadata lock, but blocking sessions cannot be determined: %s", where, err)
+			reported[0] = true
+		}
+		return
+	}
+	for _, b := range blockers {
+		// Background threads have no processlist ID, and cannot be reported or
+		// killed. ID 0 is also used as the key for query errors in reported.
+		if b.ID == 0 {
+			continue
+		}
+		if !reported[b.ID] {
+			log.Warnf("%s: statement is waiting on a metadata lock held by %s", where, b)
+			reported[b.ID] = true
+		}
+		if m.shouldKill(b) {
+			if _, err := db.Exec(fmt.Sprintf("KILL %d", b.ID)); err != nil {
+				log.Errorf("%s: unable to kill idle metadata lock blocker %s: %s", where, b, err)
+			} else {
+				log.Warnf("%s: killed idle metadata lock blocker %s", where, b)
+			}
+		}
+	}
+}
+
+// shouldKill returns true if b has been idle for at least the mdl-kill-idle
+// threshold, and its user is permitted by mdl-kill-users.
+func (m *mdlMonitor) shouldKill(b mdlBlocker) bool {
+	if m.killIdle == 0 || b.Command != "Sleep" || time.Duration(b.Time)*time.Second < m.killIdle {
+		return false
+	}
+	return len(m.killUsers) == 0 || slices.Contains(m.killUsers, b.User)
+}
@@ -0,0 +1,111 @@
+package applier
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestNewMDLMonitor(t *testing.T) {
+	cases := []struct {
+		reportAfter string
+		killIdle    string
+		expectErr   bool
+	}{
+		{"10s", "0", false},
+		{"0", "0", false},
+		{"10s", "1m", false},
+		{"0", "1m", true},
+		{"10", "0", true},
+		{"-1s", "0", true},
+		{"10s", "x", true},
+	}
+	for n, c := range cases {
+		cfg := mybase.SimpleConfig(map[string]string{
+			"mdl-report-after": c.reportAfter,
+			"mdl-kill-idle":    c.killIdle,
+			"mdl-kill-users":   "",
+		})
+		if _, err := newMDLMonitor(cfg); (err != nil) != c.expectErr {
+			t.Errorf("Case %d: Unexpected error return from newMDLMonitor: %v", n, err)
+		}
+	}
+
+	// A nil monitor should return a usable stop function
+	var m *mdlMonitor
+	m.watch(nil, 0)()
+}
+
+func TestMDLMonitorShouldKill(t *testing.T) {
+	m := &mdlMonitor{killIdle: time.Minute}
+	cases := []struct {
+		blocker   mdlBlocker
+		killUsers []string
+		expected  bool
+	}{
+		{mdlBlocker{User: "app", Command: "Sleep", Time: 120}, nil, true},
+		{mdlBlocker{User: "app", Command: "Sleep", Time: 30}, nil, false},
+		{mdlBlocker{User: "app", Command: "Query", Time: 120}, nil, false},
+		{mdlBlocker{User: "app", Command: "Sleep", Time: 120}, []string{"app", "batch"}, true},
+		{mdlBlocker{User: "root", Command: "Sleep", Time: 120}, []string{"app", "batch"}, false},
+	}
+	for n, c := range cases {
+		m.killUsers = c.killUsers
+		if actual := m.shouldKill(c.blocker); actual != c.expected {
+			t.Errorf("Case %d: Expected shouldKill to return %t, instead found %t", n, c.expected, actual)
+		}
+	}
+	m.killIdle = 0
+	if m.shouldKill(mdlBlocker{User: "app", Command: "Sleep", Time: 120}) {
+		t.Error("Expected shouldKill to return false with mdl-kill-idle disabled")
+	}
+}
+
+func (s ApplierIntegrationSuite) TestMDLMonitor(t *testing.T) {
+	// Requires performance_schema metadata lock instrumentation, enabled by
+	// default only in MySQL 8+
+	tengo.SkipUnlessFlavor(t, "mysql:8+")
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+
+	// Hold a metadata lock on users by leaving a transaction open and idle
+	ctx := context.Background()
+	blocker, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Unable to obtain connection: %v", err)
+	}
+	defer blocker.Close()
+	if _, err := blocker.ExecContext(ctx, "BEGIN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := blocker.ExecContext(ctx, "SELECT * FROM users"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ddl := &DDLStatement{
+		stmt:       "ALTER TABLE users ADD COLUMN mdl_test int",
+		instance:   s.d[0].Instance,
+		schemaName: "product",
+		mdl: &mdlMonitor{
+			reportAfter:  100 * time.Millisecond,
+			killIdle:     time.Second,
+			pollInterval: 250 * time.Millisecond,
+		},
+	}
+	err = ddl.Execute()
+	if err != nil {
+		t.Errorf("Expected statement to succeed once blocker was killed, instead found error: %v", err)
+	}
+	if err := blocker.PingContext(ctx); err == nil {
+		t.Error("Expected blocking connection to be killed, but it is still alive")
+	}
+}
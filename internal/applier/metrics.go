@@ -0,0 +1,182 @@
+package applier
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics accumulates statistics about the outcome of applying targets, for
+// exposition in Prometheus text format. Counters accumulate across all
+// recorded reports, whereas gauges reflect the most recent report of each
+// target. A Metrics value is safe for concurrent use.
+type Metrics struct {
+	targets map[metricsLabels]*targetMetrics
+	m       sync.Mutex
+}
+
+// metricsLabels identifies a single time series group. Operation is "diff" or
+// "push".
+type metricsLabels struct {
+	Instance  string
+	Schema    string
+	Operation string
+}
+
+func (ml metricsLabels) String() string {
+	return fmt.Sprintf("instance=%q,schema=%q,operation=%q", ml.Instance, ml.Schema, ml.Operation)
+}
+
+type targetMetrics struct {
+	runs             int
+	failures         int
+	executed         int
+	statementErrors  int
+	durationSum      float64
+	durationCount    int
+	differingObjects int
+	lastRun          time.Time
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{targets: make(map[metricsLabels]*targetMetrics)}
+}
+
+// Record adds the outcome described by report to the metrics.
+func (m *Metrics) Record(report *TargetReport) {
+	labels := metricsLabels{Instance: report.Instance, Schema: report.Schema, Operation: "push"}
+	if report.DryRun {
+		labels.Operation = "diff"
+	}
+	objects := make(map[string]bool, len(report.Statements))
+	for n, sr := range report.Statements {
+		key := fmt.Sprintf("%s %s", sr.ObjectType, sr.ObjectName)
+		if sr.ObjectName == "" {
+			key = fmt.Sprintf("statement %d", n)
+		}
+		objects[key] = true
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	tm := m.targets[labels]
+	if tm == nil {
+		tm = &targetMetrics{}
+		m.targets[labels] = tm
+	}
+	tm.runs++
+	if report.Status == "error" || report.Status == "skipped" {
+		tm.failures++
+	}
+	for _, sr := range report.Statements {
+		if sr.Executed {
+			tm.executed++
+			tm.durationSum += sr.Duration
+			tm.durationCount++
+		} else if sr.Error != "" {
+			tm.statementErrors++
+		}
+	}
+	tm.differingObjects = len(objects)
+	tm.lastRun = time.Now()
+}
+
+// String returns the metrics in Prometheus text exposition format.
+func (m *Metrics) String() string {
+	m.m.Lock()
+	defer m.m.Unlock()
+	allLabels := make([]metricsLabels, 0, len(m.targets))
+	for labels := range m.targets {
+		allLabels = append(allLabels, labels)
+	}
+	sort.Slice(allLabels, func(i, j int) bool {
+		return allLabels[i].String() < allLabels[j].String()
+	})
+
+	var b strings.Builder
+	metric := func(name, metricType, help string, value func(tm *targetMetrics) string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+		for _, labels := range allLabels {
+			fmt.Fprintf(&b, "%s{%s} %s\n", name, labels, value(m.targets[labels]))
+		}
+	}
+	metric("skeema_target_runs_total", "counter", "Number of times the target was diffed or pushed.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.runs)
+	})
+	metric("skeema_target_failures_total", "counter", "Number of runs in which the target had errors or skipped operations.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.failures)
+	})
+	metric("skeema_statements_executed_total", "counter", "Number of statements executed successfully.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.executed)
+	})
+	metric("skeema_statement_errors_total", "counter", "Number of statements which returned an error.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.statementErrors)
+	})
+	name := "skeema_statement_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Time spent executing statements successfully.\n# TYPE %s summary\n", name, name)
+	for _, labels := range allLabels {
+		tm := m.targets[labels]
+		fmt.Fprintf(&b, "%s_sum{%s} %g\n%s_count{%s} %d\n", name, labels, tm.durationSum, name, labels, tm.durationCount)
+	}
+	metric("skeema_target_differing_objects", "gauge", "Number of objects which differed from the filesystem in the most recent run.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.differingObjects)
+	})
+	metric("skeema_target_last_run_timestamp_seconds", "gauge", "Time of the most recent run, in seconds since the Unix epoch.", func(tm *targetMetrics) string {
+		return fmt.Sprint(tm.lastRun.Unix())
+	})
+	return b.String()
+}
+
+// PushToGateway sends the metrics to a Prometheus Pushgateway at gatewayURL,
+// replacing any metrics previously pushed for the same job.
+func (m *Metrics) PushToGateway(gatewayURL, job string) error {
+	target := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequest(http.MethodPut, target, strings.NewReader(m.String()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, target)
+	}
+	return nil
+}
+
+// MetricsPrinter wraps another Printer, additionally recording the outcome of
+// each Target in a Metrics.
+type MetricsPrinter struct {
+	Printer
+	Metrics *Metrics
+}
+
+// NewMetricsPrinter returns a MetricsPrinter wrapping p.
+func NewMetricsPrinter(p Printer) *MetricsPrinter {
+	return &MetricsPrinter{Printer: p, Metrics: NewMetrics()}
+}
+
+// Finish calls the wrapped printer's Finish method, if it has one.
+func (mp *MetricsPrinter) Finish(t *Target) {
+	if finisher, ok := mp.Printer.(Finisher); ok {
+		finisher.Finish(t)
+	}
+}
+
+// ReportResult records the outcome of t, and then calls the wrapped printer's
+// ReportResult method, if it has one.
+func (mp *MetricsPrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	mp.Metrics.Record(NewTargetReport(t, plan, result, err))
+	if reporter, ok := mp.Printer.(ResultReporter); ok {
+		reporter.ReportResult(t, plan, result, err)
+	}
+}
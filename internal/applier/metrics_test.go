@@ -0,0 +1,70 @@
+package applier
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.Record(&TargetReport{
+		Instance: "127.0.0.1:3306",
+		Schema:   "product",
+		DryRun:   true,
+		Status:   "differences",
+		Statements: []StatementReport{
+			{ObjectType: "table", ObjectName: "users", Statement: "ALTER TABLE `users` ADD COLUMN `x` int"},
+			{ObjectType: "table", ObjectName: "users", Statement: "ALTER TABLE `users` DROP KEY `y`"},
+			{ObjectType: "table", ObjectName: "posts", Statement: "DROP TABLE `posts`"},
+		},
+	})
+	m.Record(&TargetReport{
+		Instance: "127.0.0.1:3306",
+		Schema:   "product",
+		Status:   "skipped",
+		Statements: []StatementReport{
+			{ObjectType: "table", ObjectName: "users", Executed: true, Duration: 1.5},
+			{ObjectType: "table", ObjectName: "posts", Error: "access denied"},
+		},
+	})
+	m.Record(&TargetReport{Instance: "127.0.0.1:3306", Schema: "product", Status: "pushed"})
+
+	diffLabels := `{instance="127.0.0.1:3306",schema="product",operation="diff"}`
+	pushLabels := `{instance="127.0.0.1:3306",schema="product",operation="push"}`
+	expected := []string{
+		"# TYPE skeema_target_runs_total counter\n",
+		"skeema_target_runs_total" + diffLabels + " 1\n",
+		"skeema_target_runs_total" + pushLabels + " 2\n",
+		"skeema_target_failures_total" + pushLabels + " 1\n",
+		"skeema_statements_executed_total" + pushLabels + " 1\n",
+		"skeema_statement_errors_total" + pushLabels + " 1\n",
+		"skeema_statement_duration_seconds_sum" + pushLabels + " 1.5\n",
+		"skeema_statement_duration_seconds_count" + pushLabels + " 1\n",
+		"skeema_target_differing_objects" + diffLabels + " 2\n",
+		"skeema_target_differing_objects" + pushLabels + " 0\n",
+	}
+	actual := m.String()
+	for _, line := range expected {
+		if !strings.Contains(actual, line) {
+			t.Errorf("Expected metrics to contain %q, but it did not. Full output:\n%s", line, actual)
+		}
+	}
+
+	var gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.Method+" "+r.URL.EscapedPath(), string(body)
+	}))
+	defer server.Close()
+	if err := m.PushToGateway(server.URL+"/", "schema changes"); err != nil {
+		t.Fatalf("Unexpected error from PushToGateway: %v", err)
+	} else if gotPath != "PUT /metrics/job/schema%20changes" || gotBody != actual {
+		t.Errorf("Unexpected request to gateway: %s\n%s", gotPath, gotBody)
+	}
+	if err := m.PushToGateway(server.URL+"/doesnt-exist\x7f", "skeema"); err == nil {
+		t.Error("Expected error from PushToGateway with invalid URL, but err was nil")
+	}
+}
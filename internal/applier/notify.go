@@ -0,0 +1,257 @@
+package applier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// notifyChannel identifies a chat webhook which should receive a summary of
+// results. Service is "slack" or "teams". On is the value of the notify-on
+// option in effect for the targets reporting to this channel.
+type notifyChannel struct {
+	Service string
+	URL     string
+	On      string
+}
+
+// NotifyPrinter wraps another Printer, additionally collecting the outcome of
+// each Target, so that a summary can be posted to Slack or Microsoft Teams
+// once all targets have been applied. The webhook URLs are obtained from each
+// target's dir configuration, which permits different environments or dirs to
+// notify different channels.
+type NotifyPrinter struct {
+	Printer
+	start    time.Time
+	reports  map[notifyChannel][]*TargetReport
+	channels []notifyChannel // in order first seen
+	m        sync.Mutex
+}
+
+// NewNotifyPrinter returns a NotifyPrinter wrapping p.
+func NewNotifyPrinter(p Printer) *NotifyPrinter {
+	return &NotifyPrinter{
+		Printer: p,
+		start:   time.Now(),
+		reports: make(map[notifyChannel][]*TargetReport),
+	}
+}
+
+// Finish calls the wrapped printer's Finish method, if it has one.
+func (np *NotifyPrinter) Finish(t *Target) {
+	if finisher, ok := np.Printer.(Finisher); ok {
+		finisher.Finish(t)
+	}
+}
+
+// ReportResult records the outcome of t for each webhook configured for t's
+// dir, and then calls the wrapped printer's ReportResult method, if it has one.
+func (np *NotifyPrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	on, _ := t.Dir.Config.GetEnum("notify-on", "changes", "failures", "always")
+	var report *TargetReport
+	for _, service := range []string{"slack", "teams"} {
+		url := t.Dir.Config.Get("notify-" + service + "-webhook")
+		if url == "" {
+			continue
+		}
+		if report == nil {
+			report = NewTargetReport(t, plan, result, err)
+		}
+		channel := notifyChannel{Service: service, URL: url, On: on}
+		np.m.Lock()
+		if _, seen := np.reports[channel]; !seen {
+			np.channels = append(np.channels, channel)
+		}
+		np.reports[channel] = append(np.reports[channel], report)
+		np.m.Unlock()
+	}
+	if reporter, ok := np.Printer.(ResultReporter); ok {
+		reporter.ReportResult(t, plan, result, err)
+	}
+}
+
+// Notify posts a summary to each webhook which received at least one target
+// report, subject to the notify-on option. The environment name is included in
+// the summary. Errors from individual webhooks are combined in the return
+// value, but do not prevent the other webhooks from being notified.
+func (np *NotifyPrinter) Notify(environment string) error {
+	np.m.Lock()
+	defer np.m.Unlock()
+	elapsed := time.Since(np.start)
+	client := &http.Client{Timeout: 10 * time.Second}
+	var errs []error
+	for _, channel := range np.channels {
+		summary := newNotifySummary(np.reports[channel], environment, elapsed)
+		if !summary.wanted(channel.On) {
+			continue
+		}
+		var payload interface{}
+		if channel.Service == "teams" {
+			payload = summary.teamsPayload()
+		} else {
+			payload = summary.slackPayload()
+		}
+		if err := postWebhook(client, channel.URL, payload); err != nil {
+			errs = append(errs, fmt.Errorf("Unable to notify %s: %w", channel.Service, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifySummary describes the outcome of a diff or push, in a form suitable
+// for posting to a chat channel.
+type notifySummary struct {
+	Operation   string // "diff" or "push"
+	Environment string
+	Targets     int
+	Changed     int // number of statements executed (push) or generated (diff)
+	Failures    int // number of targets with errors or skipped statements
+	Elapsed     time.Duration
+	Lines       []string // one line per target with differences or failures
+}
+
+// maxNotifyLines is the maximum number of per-target lines in a notification,
+// to avoid exceeding message size limits of chat services.
+const maxNotifyLines = 20
+
+func newNotifySummary(reports []*TargetReport, environment string, elapsed time.Duration) *notifySummary {
+	summary := &notifySummary{
+		Operation:   "push",
+		Environment: environment,
+		Targets:     len(reports),
+		Elapsed:     elapsed.Round(100 * time.Millisecond),
+	}
+	sorted := make([]*TargetReport, len(reports))
+	copy(sorted, reports)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Instance != sorted[j].Instance {
+			return sorted[i].Instance < sorted[j].Instance
+		}
+		return sorted[i].Schema < sorted[j].Schema
+	})
+	var omitted int
+	for _, report := range sorted {
+		if report.DryRun {
+			summary.Operation = "diff"
+		}
+		var objects []string
+		for _, sr := range report.Statements {
+			if !report.DryRun && !sr.Executed {
+				continue
+			}
+			summary.Changed++
+			if sr.Action != "" {
+				objects = append(objects, fmt.Sprintf("%s %s %s", sr.Action, sr.ObjectType, sr.ObjectName))
+			}
+		}
+		failed := report.Status == "error" || report.Status == "skipped"
+		if failed {
+			summary.Failures++
+		}
+		if len(objects) == 0 && !failed {
+			continue
+		} else if len(summary.Lines) >= maxNotifyLines {
+			omitted++
+			continue
+		}
+		line := report.Instance + " " + report.Schema + ":"
+		if len(objects) > 0 {
+			line += " " + strings.Join(objects, ", ")
+		}
+		if report.Error != "" {
+			line += " (error: " + report.Error + ")"
+		} else if failed {
+			line += fmt.Sprintf(" (%s skipped)", countAndNoun(report.SkipCount, "operation"))
+		}
+		summary.Lines = append(summary.Lines, line)
+	}
+	if omitted > 0 {
+		summary.Lines = append(summary.Lines, fmt.Sprintf("...and %s", countAndNoun(omitted, "more target")))
+	}
+	return summary
+}
+
+// wanted returns true if the summary should be sent, based on the supplied
+// value of the notify-on option.
+func (summary *notifySummary) wanted(on string) bool {
+	switch on {
+	case "always":
+		return true
+	case "failures":
+		return summary.Failures > 0
+	default:
+		return summary.Failures > 0 || summary.Changed > 0
+	}
+}
+
+// Title returns a one-line description of the summary.
+func (summary *notifySummary) Title() string {
+	verb := "Skeema push to"
+	changed := "statement executed"
+	if summary.Operation == "diff" {
+		verb, changed = "Skeema diff of", "statement generated"
+	}
+	title := fmt.Sprintf("%s %s: %s, %s", verb, summary.Environment, countAndNoun(summary.Targets, "target"), countAndNoun(summary.Changed, changed))
+	if summary.Failures > 0 {
+		title += fmt.Sprintf(", %s", countAndNoun(summary.Failures, "target failed", "targets failed"))
+	}
+	return title + fmt.Sprintf(" (%s)", summary.Elapsed)
+}
+
+// slackPayload returns the JSON document for a Slack incoming webhook.
+func (summary *notifySummary) slackPayload() interface{} {
+	escaper := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	var b strings.Builder
+	icon := ":white_check_mark:"
+	if summary.Failures > 0 {
+		icon = ":x:"
+	}
+	fmt.Fprintf(&b, "%s *%s*", icon, escaper.Replace(summary.Title()))
+	for _, line := range summary.Lines {
+		b.WriteString("\n• " + escaper.Replace(line))
+	}
+	return map[string]string{"text": b.String()}
+}
+
+// teamsPayload returns the JSON document for a Microsoft Teams incoming
+// webhook, using the MessageCard format.
+func (summary *notifySummary) teamsPayload() interface{} {
+	color := "2EB67D"
+	if summary.Failures > 0 {
+		color = "E01E5A"
+	}
+	lines := make([]string, len(summary.Lines))
+	for n, line := range summary.Lines {
+		lines[n] = "- " + markdownEscape(line)
+	}
+	return map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    summary.Title(),
+		"themeColor": color,
+		"title":      summary.Title(),
+		"text":       strings.Join(lines, "\n"),
+	}
+}
+
+func postWebhook(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d from webhook", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package applier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifySummary(t *testing.T) {
+	reports := []*TargetReport{
+		{Instance: "db2:3306", Schema: "product", Status: "no-differences"},
+		{
+			Instance: "db1:3306",
+			Schema:   "product",
+			Status:   "skipped",
+			Statements: []StatementReport{
+				{Action: "alter", ObjectType: "table", ObjectName: "users", Executed: true},
+				{Action: "drop", ObjectType: "table", ObjectName: "posts", Error: "access denied"},
+			},
+			SkipCount: 1,
+		},
+		{Instance: "db3:3306", Schema: "product", Status: "error", Error: "connection refused"},
+	}
+	summary := newNotifySummary(reports, "production", 2345*time.Millisecond)
+	if summary.Operation != "push" || summary.Targets != 3 || summary.Changed != 1 || summary.Failures != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	expectedLines := []string{
+		"db1:3306 product: alter table users (1 operation skipped)",
+		"db3:3306 product: (error: connection refused)",
+	}
+	if strings.Join(summary.Lines, "\n") != strings.Join(expectedLines, "\n") {
+		t.Errorf("Unexpected summary lines: %q", summary.Lines)
+	}
+	if expected := "Skeema push to production: 3 targets, 1 statement executed, 2 targets failed (2.3s)"; summary.Title() != expected {
+		t.Errorf("Unexpected title %q", summary.Title())
+	}
+	for _, on := range []string{"changes", "failures", "always"} {
+		if !summary.wanted(on) {
+			t.Errorf("Expected summary with failures to be wanted for notify-on=%s, but it was not", on)
+		}
+	}
+
+	// Diff without differences: only wanted for notify-on=always
+	summary = newNotifySummary([]*TargetReport{{Instance: "db1:3306", Schema: "product", DryRun: true, Status: "no-differences"}}, "staging", time.Second)
+	if summary.Operation != "diff" || len(summary.Lines) != 0 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if summary.wanted("changes") || summary.wanted("failures") || !summary.wanted("always") {
+		t.Error("Unexpected result from wanted for summary without differences")
+	}
+
+	// Lines are capped at maxNotifyLines
+	reports = nil
+	for n := 0; n < maxNotifyLines+5; n++ {
+		reports = append(reports, &TargetReport{Instance: "db1:3306", Schema: "product", DryRun: true, Status: "differences",
+			Statements: []StatementReport{{Action: "create", ObjectType: "table", ObjectName: "foo"}},
+		})
+	}
+	summary = newNotifySummary(reports, "production", time.Second)
+	if len(summary.Lines) != maxNotifyLines+1 || summary.Lines[maxNotifyLines] != "...and 5 more targets" {
+		t.Errorf("Unexpected summary lines: %q", summary.Lines)
+	}
+}
+
+func TestNotifyPrinterNotify(t *testing.T) {
+	payloads := make(map[string]map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Unable to decode webhook body: %v", err)
+		}
+		payloads[r.URL.Path] = payload
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	changed := &TargetReport{Instance: "db1:3306", Schema: "product", Status: "pushed",
+		Statements: []StatementReport{{Action: "create", ObjectType: "table", ObjectName: "<foo>", Executed: true}},
+	}
+	unchanged := &TargetReport{Instance: "db2:3306", Schema: "product", Status: "no-differences"}
+	np := NewNotifyPrinter(nil)
+	for _, channel := range []notifyChannel{
+		{Service: "slack", URL: server.URL + "/slack", On: "changes"},
+		{Service: "teams", URL: server.URL + "/teams", On: "changes"},
+		{Service: "slack", URL: server.URL + "/quiet", On: "failures"},
+		{Service: "slack", URL: server.URL + "/broken", On: "always"},
+	} {
+		np.channels = append(np.channels, channel)
+		np.reports[channel] = []*TargetReport{changed, unchanged}
+	}
+	if err := np.Notify("production"); err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("Expected error from broken webhook, instead found %v", err)
+	}
+
+	if len(payloads) != 3 {
+		t.Errorf("Expected 3 webhooks to be notified, instead found %d: %v", len(payloads), payloads)
+	}
+	if _, ok := payloads["/quiet"]; ok {
+		t.Error("Expected webhook with notify-on=failures not to be notified, but it was")
+	}
+	slackText := payloads["/slack"]["text"]
+	if !strings.HasPrefix(slackText, ":white_check_mark: *Skeema push to production: 2 targets, 1 statement executed (") || !strings.HasSuffix(slackText, "\n• db1:3306 product: create table &lt;foo&gt;") {
+		t.Errorf("Unexpected Slack payload text %q", slackText)
+	}
+	teams := payloads["/teams"]
+	if teams["@type"] != "MessageCard" || !strings.HasPrefix(teams["title"], "Skeema push to production") || teams["text"] != "- db1:3306 product: create table &lt;foo>" {
+		t.Errorf("Unexpected Teams payload %v", teams)
+	}
+}
@@ -0,0 +1,375 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// oscTool represents an external online schema change tool which Skeema
+// natively integrates with. Unlike the generic alter-wrapper option, native
+// integrations construct the tool's command-line automatically, stream the
+// tool's progress output into Skeema's logging, and clean up after failures.
+type oscTool interface {
+	// name returns the tool's name, for use in log messages.
+	name() string
+
+	// commandLine returns the command-line for running the tool on a single
+	// ALTER TABLE. The command-line may contain variable placeholders, including
+	// any variables supplied by the variables method. Tools which implement
+	// oscRunner return an empty string.
+	commandLine(config *mybase.Config) string
+
+	// variables returns any tool-specific variables, beyond the standard ones
+	// also available to alter-wrapper, for interpolating into commandLine.
+	variables(ddl *DDLStatement) map[string]string
+
+	// handleOutput processes a single line of output from the tool.
+	handleOutput(ddl *DDLStatement, line string)
+
+	// cleanup removes any leftover artifacts from a failed run of the tool. It is
+	// not called if the tool's artifacts already existed prior to the run.
+	cleanup(ddl *DDLStatement) error
+}
+
+// getOSCTool returns the oscTool that should be used to execute diff, or nil if
+// diff should not be executed by a natively-integrated online schema change
+// tool. The returned mods will be adjusted as needed for use with the tool.
+func getOSCTool(config *mybase.Config, diff tengo.ObjectDiff, tableSize int64, mods tengo.StatementModifiers) (oscTool, tengo.StatementModifiers, error) {
+	toolName, err := config.GetEnum("osc-tool", "none", "gh-ost", "pt-osc", "builtin")
+	if err != nil || toolName == "none" {
+		return nil, mods, err
+	} else if config.Changed("alter-wrapper") {
+		return nil, mods, errors.New("options alter-wrapper and osc-tool cannot be used together")
+	} else if toolName != "gh-ost" && config.GetBool("osc-postpone-cut-over") {
+		return nil, mods, fmt.Errorf("option osc-postpone-cut-over is not supported with osc-tool=%s", toolName)
+	}
+	if diff.ObjectKey().Type != tengo.ObjectTypeTable || diff.DiffType() != tengo.DiffTypeAlter {
+		return nil, mods, nil
+	}
+	minSize, err := config.GetBytes("alter-wrapper-min-size")
+	if err != nil {
+		return nil, mods, errors.New("option alter-wrapper-min-size has been configured to an invalid value")
+	} else if tableSize < int64(minSize) {
+		log.Debugf("Skipping osc-tool for %s: size=%d < alter-wrapper-min-size=%d", diff.ObjectKey(), tableSize, minSize)
+		return nil, mods, nil
+	}
+
+	// ALGORITHM and LOCK clauses are not meaningful to external OSC tools, which
+	// copy the table themselves
+	mods.AlgorithmClause = ""
+	mods.LockClause = ""
+	switch toolName {
+	case "pt-osc":
+		return &ptOSCTool{}, mods, nil
+	case "builtin":
+		tool, err := newBuiltinOSC(config)
+		return tool, mods, err
+	default:
+		return &ghostTool{}, mods, nil
+	}
+}
+
+// OSCError is returned when a natively-integrated online schema change tool
+// fails.
+type OSCError struct {
+	Tool   string          // name of the tool, e.g. "gh-ost"
+	Key    tengo.ObjectKey // table being altered
+	Detail string          // most relevant error message from the tool's output, if any
+	Err    error           // underlying error from executing the tool
+}
+
+// Error satisfies the builtin error interface.
+func (oe *OSCError) Error() string {
+	if oe.Detail != "" {
+		return fmt.Sprintf("%s failed on %s: %s", oe.Tool, oe.Key, oe.Detail)
+	}
+	return fmt.Sprintf("%s failed on %s: %s", oe.Tool, oe.Key, oe.Err)
+}
+
+// Unwrap returns the underlying error from executing the tool.
+func (oe *OSCError) Unwrap() error {
+	return oe.Err
+}
+
+// oscRunner may optionally be implemented by oscTools which execute the schema
+// change themselves, instead of shelling out to an external command.
+type oscRunner interface {
+	run(ddl *DDLStatement) error
+}
+
+// oscErrorReporter may optionally be implemented by oscTools which can extract
+// a more specific error message from the tool's output.
+type oscErrorReporter interface {
+	errorDetail() string
+}
+
+// oscArtifactLister may optionally be implemented by oscTools which create
+// tables or triggers while running. These are checked for prior to running the
+// tool: if any already exist, they may belong to another run of the tool which
+// is still in progress, so cleanup is skipped if this run fails.
+type oscArtifactLister interface {
+	artifacts(ddl *DDLStatement) (tables, triggers []string)
+}
+
+// runOSC executes ddl using its oscTool, cleaning up if an error occurs.
+func (ddl *DDLStatement) runOSC() error {
+	skipCleanupReason := ddl.existingOSCArtifact()
+	var err error
+	if runner, ok := ddl.osc.(oscRunner); ok {
+		err = runner.run(ddl)
+	} else {
+		err = ddl.shellOut.RunLineHandler(func(line string) {
+			ddl.osc.handleOutput(ddl, line)
+		})
+	}
+	if err != nil {
+		oscErr := &OSCError{
+			Tool: ddl.osc.name(),
+			Key:  ddl.diff.ObjectKey(),
+			Err:  err,
+		}
+		if reporter, ok := ddl.osc.(oscErrorReporter); ok {
+			oscErr.Detail = reporter.errorDetail()
+		}
+		if skipCleanupReason == "" && strings.Contains(oscErr.Error(), "already exists") {
+			skipCleanupReason = "the tool reported that an object already exists"
+		}
+		if skipCleanupReason != "" {
+			log.Warnf("Not cleaning up after %s failure on %s: %s", ddl.osc.name(), ddl.diff.ObjectKey(), skipCleanupReason)
+		} else if cleanupErr := ddl.osc.cleanup(ddl); cleanupErr != nil {
+			log.Warnf("Unable to clean up after %s failure on %s: %s", ddl.osc.name(), ddl.diff.ObjectKey(), cleanupErr)
+		}
+		return oscErr
+	}
+	return nil
+}
+
+// existingOSCArtifact checks whether any tables or triggers created by ddl's
+// oscTool already exist, prior to running the tool. If so, or if this cannot
+// be determined, a description of the reason to skip cleanup is returned.
+// Otherwise, an empty string is returned.
+func (ddl *DDLStatement) existingOSCArtifact() string {
+	lister, ok := ddl.osc.(oscArtifactLister)
+	if !ok {
+		return ""
+	}
+	tables, triggers := lister.artifacts(ddl)
+	db, err := ddl.instance.CachedConnectionPool("", "")
+	if err != nil {
+		return "unable to check for pre-existing artifacts: " + err.Error()
+	}
+	checks := []struct {
+		query string
+		names []string
+	}{
+		{"SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_name IN (?)", tables},
+		{"SELECT trigger_name FROM information_schema.triggers WHERE trigger_schema = ? AND trigger_name IN (?)", triggers},
+	}
+	for _, check := range checks {
+		if len(check.names) == 0 {
+			continue
+		}
+		query, args, err := sqlx.In(check.query, ddl.schemaName, check.names)
+		if err != nil {
+			return "unable to check for pre-existing artifacts: " + err.Error()
+		}
+		var existing []string
+		if err := db.Select(&existing, query, args...); err != nil {
+			return "unable to check for pre-existing artifacts: " + err.Error()
+		} else if len(existing) > 0 {
+			return fmt.Sprintf("%s already existed before this run, and may belong to another run which is still in progress", tengo.EscapeIdentifier(existing[0]))
+		}
+	}
+	return ""
+}
+
+// execCleanup runs the supplied cleanup statements in ddl's schema, stopping
+// at the first error.
+func (ddl *DDLStatement) execCleanup(statements ...string) error {
+	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, "")
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+///// gh-ost ///////////////////////////////////////////////////////////////////
+
+// ghostTool provides native integration with GitHub's gh-ost.
+type ghostTool struct {
+	lastError string
+}
+
+func (*ghostTool) name() string {
+	return "gh-ost"
+}
+
+func (*ghostTool) commandLine(config *mybase.Config) string {
+	bin := config.Get("osc-tool-bin")
+	if bin == "" {
+		bin = "gh-ost"
+	}
+	args := []string{
+		bin,
+		"--host={HOST}",
+		"--port={PORT}",
+		"--user={USER}",
+		"--password={PASSWORDX}",
+		"--database={SCHEMA}",
+		"--table={TABLE}",
+		"--alter={CLAUSES}",
+	}
+	if config.GetBool("osc-postpone-cut-over") {
+		args = append(args, "--postpone-cut-over-flag-file={FLAGFILE}")
+	}
+	if extra := config.Get("osc-tool-options"); extra != "" {
+		args = append(args, extra)
+	}
+	args = append(args, "--execute")
+	return strings.Join(args, " ")
+}
+
+func (*ghostTool) variables(ddl *DDLStatement) map[string]string {
+	flagFileName := fmt.Sprintf("skeema-ghost-%s-%s.postpone", ddl.schemaName, ddl.diff.ObjectKey().Name)
+	return map[string]string{
+		"FLAGFILE": filepath.Join(os.TempDir(), flagFileName),
+	}
+}
+
+// handleOutput logs gh-ost's periodic status lines at INFO level, errors at
+// ERROR level, and everything else at DEBUG level. Error messages are retained
+// for use in the OSCError returned upon failure.
+func (gh *ghostTool) handleOutput(ddl *DDLStatement, line string) {
+	key := ddl.diff.ObjectKey()
+	switch {
+	case strings.HasPrefix(line, "Copy:"):
+		log.Infof("gh-ost progress for %s: %s", key, line)
+	case strings.Contains(line, "FATAL") || strings.Contains(line, "ERROR"):
+		log.Errorf("gh-ost error for %s: %s", key, line)
+		gh.lastError = line
+	case strings.Contains(line, "postpone-cut-over-flag-file"):
+		log.Infof("gh-ost for %s: %s", key, line)
+	default:
+		log.Debugf("gh-ost for %s: %s", key, line)
+	}
+}
+
+// artifacts returns the names of gh-ost's ghost table and changelog table. The
+// old table (with _del suffix) is intentionally excluded, since it only exists
+// after a successful cut-over.
+func (*ghostTool) artifacts(ddl *DDLStatement) (tables, triggers []string) {
+	name := ddl.diff.ObjectKey().Name
+	return []string{"_" + name + "_gho", "_" + name + "_ghc"}, nil
+}
+
+// cleanup drops gh-ost's ghost table and changelog table.
+func (gh *ghostTool) cleanup(ddl *DDLStatement) error {
+	tables, _ := gh.artifacts(ddl)
+	statements := make([]string, len(tables))
+	for n, table := range tables {
+		statements[n] = "DROP TABLE IF EXISTS " + tengo.EscapeIdentifier(table)
+	}
+	return ddl.execCleanup(statements...)
+}
+
+func (gh *ghostTool) errorDetail() string {
+	return gh.lastError
+}
+
+///// pt-online-schema-change //////////////////////////////////////////////////
+
+// ptOSCTool provides native integration with Percona Toolkit's
+// pt-online-schema-change. The tool is first run with --dry-run, which creates
+// and alters the new table without copying any rows; only if this succeeds is
+// it then run with --execute.
+type ptOSCTool struct {
+	lastError string
+}
+
+func (*ptOSCTool) name() string {
+	return "pt-online-schema-change"
+}
+
+func (*ptOSCTool) commandLine(config *mybase.Config) string {
+	bin := config.Get("osc-tool-bin")
+	if bin == "" {
+		bin = "pt-online-schema-change"
+	}
+	args := []string{bin, "--alter={CLAUSES}"}
+	if extra := config.Get("osc-tool-options"); extra != "" {
+		args = append(args, extra)
+	}
+	args = append(args, "D={SCHEMA},t={TABLE},h={HOST},P={PORT},u={USER},p={PASSWORDX}")
+	base := strings.Join(args, " ")
+	return base + " --dry-run && " + base + " --execute"
+}
+
+func (*ptOSCTool) variables(ddl *DDLStatement) map[string]string {
+	return nil
+}
+
+// handleOutput logs pt-online-schema-change's copy progress and phase changes
+// at INFO level, and everything else at DEBUG level. Error messages are
+// retained for use in the OSCError returned upon failure.
+func (pt *ptOSCTool) handleOutput(ddl *DDLStatement, line string) {
+	key := ddl.diff.ObjectKey()
+	switch {
+	case strings.HasPrefix(line, "Copying "):
+		// Progress lines have the form "Copying `db`.`tbl`:  45% 01:23 remain"
+		if _, progress, ok := strings.Cut(line, ": "); ok {
+			log.Infof("pt-online-schema-change progress for %s: %s", key, strings.TrimSpace(progress))
+			return
+		}
+		log.Infof("pt-online-schema-change for %s: %s", key, line)
+	case strings.HasPrefix(line, "Error") || strings.Contains(line, "DBD::mysql") || strings.HasSuffix(line, "was not altered."):
+		log.Errorf("pt-online-schema-change error for %s: %s", key, line)
+		if !strings.HasSuffix(line, "was not altered.") {
+			pt.lastError = line
+		}
+	case strings.HasPrefix(line, "Starting a dry run"), strings.HasPrefix(line, "Dry run complete"),
+		strings.HasPrefix(line, "Altering "), strings.HasPrefix(line, "Successfully altered"):
+		log.Infof("pt-online-schema-change for %s: %s", key, line)
+	default:
+		log.Debugf("pt-online-schema-change for %s: %s", key, line)
+	}
+}
+
+// artifacts returns the names of pt-online-schema-change's new table and
+// triggers.
+func (*ptOSCTool) artifacts(ddl *DDLStatement) (tables, triggers []string) {
+	name := ddl.diff.ObjectKey().Name
+	triggerPrefix := "pt_osc_" + ddl.schemaName + "_" + name + "_"
+	return []string{"_" + name + "_new"}, []string{triggerPrefix + "ins", triggerPrefix + "upd", triggerPrefix + "del"}
+}
+
+// cleanup drops pt-online-schema-change's new table and triggers. Normally the
+// tool cleans these up itself, but they can be left behind if the tool is
+// killed.
+func (pt *ptOSCTool) cleanup(ddl *DDLStatement) error {
+	tables, triggers := pt.artifacts(ddl)
+	var statements []string
+	for _, trigger := range triggers {
+		statements = append(statements, "DROP TRIGGER IF EXISTS "+tengo.EscapeIdentifier(trigger))
+	}
+	for _, table := range tables {
+		statements = append(statements, "DROP TABLE IF EXISTS "+tengo.EscapeIdentifier(table))
+	}
+	return ddl.execCleanup(statements...)
+}
+
+func (pt *ptOSCTool) errorDetail() string {
+	return pt.lastError
+}
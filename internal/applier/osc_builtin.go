@@ -0,0 +1,492 @@
+package applier
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// builtinOSC is an online schema change engine which runs within Skeema
+// itself, without requiring any external binaries. It uses the same general
+// approach as pt-online-schema-change: a shadow table is created and altered,
+// triggers on the original table propagate ongoing writes to the shadow table,
+// existing rows are copied over in primary key order in chunks, and finally
+// the two tables are atomically swapped.
+//
+// Copy progress is persisted in a state table. If the copy is interrupted, the
+// shadow table, triggers, and state table are intentionally left in place, and
+// the next push of the same ALTER resumes the copy where it left off.
+type builtinOSC struct {
+	chunkSize int
+	throttler *lagThrottler
+}
+
+// newBuiltinOSC returns a builtinOSC configured using config.
+func newBuiltinOSC(config *mybase.Config) (*builtinOSC, error) {
+	chunkSize, err := config.GetInt("osc-chunk-size")
+	if err != nil || chunkSize < 1 {
+		return nil, errors.New("option osc-chunk-size must be a positive integer")
+	}
+	// If no OSC-specific replicas are configured, fall back to the replicas and
+	// lag threshold used for throttling push as a whole
+	lagOption, replicasOption := "osc-max-replica-lag", "osc-replicas"
+	if config.Get("osc-replicas") == "" {
+		lagOption, replicasOption = "max-replica-lag", "replicas"
+	}
+	throttler, err := newLagThrottler(config, lagOption, replicasOption)
+	if err != nil {
+		return nil, err
+	}
+	return &builtinOSC{
+		chunkSize: chunkSize,
+		throttler: throttler,
+	}, nil
+}
+
+func (*builtinOSC) name() string {
+	return "built-in online schema change"
+}
+
+// commandLine returns an empty string, since builtinOSC does not shell out.
+func (*builtinOSC) commandLine(config *mybase.Config) string {
+	return ""
+}
+
+func (*builtinOSC) variables(ddl *DDLStatement) map[string]string {
+	return nil
+}
+
+func (*builtinOSC) handleOutput(ddl *DDLStatement, line string) {}
+
+// cleanup leaves all artifacts in place, so that a subsequent run may resume.
+func (*builtinOSC) cleanup(ddl *DDLStatement) error {
+	log.Warnf("Leaving online schema change state for %s in place. Re-running the same push will resume copying rows.", ddl.diff.ObjectKey())
+	return nil
+}
+
+// run performs the online schema change for ddl.
+func (o *builtinOSC) run(ddl *DDLStatement) error {
+	td, ok := ddl.diff.(*tengo.TableDiff)
+	if !ok || td.Type != tengo.DiffTypeAlter {
+		return errors.New("only ALTER TABLE is supported")
+	}
+	oa, err := newOnlineAlter(td, ddl.mods)
+	if err != nil {
+		return err
+	}
+	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, "readTimeout=0")
+	if err != nil {
+		return err
+	}
+	if err := oa.checkReferencingForeignKeys(db); err != nil {
+		return err
+	}
+
+	lastKey, resumed, err := oa.resumeState(db)
+	if err != nil {
+		return err
+	} else if resumed {
+		log.Infof("Resuming online schema change of %s from previous run", ddl.diff.ObjectKey())
+	} else {
+		if err := oa.checkShadowOwnership(db); err != nil {
+			return err
+		}
+		for _, stmt := range oa.cleanupStatements() {
+			if _, err := db.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		for _, stmt := range oa.setupStatements() {
+			if _, err := db.Exec(stmt); err != nil {
+				return fmt.Errorf("Unable to set up shadow table: %w", err)
+			}
+		}
+		if err := oa.initState(db); err != nil {
+			return err
+		}
+	}
+
+	var rowsCopied int64
+	for done := false; !done; {
+		if err := o.throttler.wait(ddl.instance, "online schema change"); err != nil {
+			return err
+		}
+		var upperKey []interface{}
+		upperKey, err = oa.chunkUpperBound(db, lastKey, o.chunkSize)
+		if err != nil {
+			return err
+		}
+		query, args := oa.copyChunkQuery(lastKey, upperKey)
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("Unable to copy rows: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		rowsCopied += n
+		done = (upperKey == nil)
+		if !done {
+			lastKey = upperKey
+			if err := oa.saveState(db, lastKey); err != nil {
+				return err
+			}
+			log.Infof("Online schema change progress for %s: copied %d rows", ddl.diff.ObjectKey(), rowsCopied)
+		}
+	}
+
+	if _, err := db.Exec(oa.swapStatement()); err != nil {
+		return fmt.Errorf("Unable to swap tables: %w", err)
+	}
+	for _, stmt := range oa.finishStatements() {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Warnf("Unable to clean up after online schema change of %s: %s", ddl.diff.ObjectKey(), err)
+			break
+		}
+	}
+	log.Infof("Online schema change of %s complete: copied %d rows", ddl.diff.ObjectKey(), rowsCopied)
+	return nil
+}
+
+// onlineAlter generates the SQL used by builtinOSC for altering a single table.
+type onlineAlter struct {
+	table       string   // original table name
+	shadow      string   // shadow table name, which gets altered
+	old         string   // name of original table after swap
+	state       string   // state table name, for resuming
+	triggers    []string // trigger names, in order of insert, update, delete
+	clauses     string   // ALTER TABLE clauses applied to shadow table
+	keyCols     []string // escaped primary key column names of original table
+	copyCols    []string // escaped names of columns copied to shadow table
+	newKeyCheck string   // WHERE condition matching shadow table rows by OLD primary key values
+	lockClause  string   // locking clause for reading rows from the original table
+}
+
+func newOnlineAlter(td *tengo.TableDiff, mods tengo.StatementModifiers) (*onlineAlter, error) {
+	from, to := td.From, td.To
+	oa := &onlineAlter{
+		table:      from.Name,
+		shadow:     "_" + from.Name + "_skosc",
+		old:        "_" + from.Name + "_old",
+		state:      "_" + from.Name + "_osc",
+		lockClause: "LOCK IN SHARE MODE",
+		triggers: []string{
+			"_" + from.Name + "_osc_ins",
+			"_" + from.Name + "_osc_upd",
+			"_" + from.Name + "_osc_del",
+		},
+	}
+	if mods.Flavor.MinMySQL(8) {
+		oa.lockClause = "FOR SHARE"
+	}
+	if len(oa.triggers[0]) > 64 {
+		return nil, fmt.Errorf("table name %s is too long for online schema change", tengo.EscapeIdentifier(from.Name))
+	}
+	if from.PrimaryKey == nil {
+		return nil, fmt.Errorf("table %s has no primary key, which is required for online schema change", tengo.EscapeIdentifier(from.Name))
+	}
+	for _, clause := range td.AlterClauses(mods) {
+		if _, ok := clause.(tengo.RenameColumn); ok {
+			return nil, errors.New("renaming columns is not supported by online schema change")
+		}
+	}
+	var err error
+	if oa.clauses, err = td.Clauses(mods); err != nil {
+		return nil, err
+	}
+
+	toCols := to.ColumnsByName()
+	var keyConds []string
+	for _, part := range from.PrimaryKey.Parts {
+		if part.ColumnName == "" || part.PrefixLength > 0 {
+			return nil, fmt.Errorf("primary key of table %s must consist of full columns for online schema change", tengo.EscapeIdentifier(from.Name))
+		} else if toCols[part.ColumnName] == nil {
+			return nil, fmt.Errorf("online schema change cannot drop primary key column %s", tengo.EscapeIdentifier(part.ColumnName))
+		}
+		col := tengo.EscapeIdentifier(part.ColumnName)
+		oa.keyCols = append(oa.keyCols, col)
+		keyConds = append(keyConds, fmt.Sprintf("%s.%s <=> OLD.%s", tengo.EscapeIdentifier(oa.shadow), col, col))
+	}
+	oa.newKeyCheck = strings.Join(keyConds, " AND ")
+	for _, col := range from.Columns {
+		if toCol := toCols[col.Name]; toCol != nil && col.GenerationExpr == "" && toCol.GenerationExpr == "" {
+			oa.copyCols = append(oa.copyCols, tengo.EscapeIdentifier(col.Name))
+		}
+	}
+	return oa, nil
+}
+
+// setupStatements returns the statements for creating the shadow table, state
+// table, and triggers.
+func (oa *onlineAlter) setupStatements() []string {
+	table, shadow := tengo.EscapeIdentifier(oa.table), tengo.EscapeIdentifier(oa.shadow)
+	cols := strings.Join(oa.copyCols, ", ")
+	newVals := "NEW." + strings.Join(oa.copyCols, ", NEW.")
+	var keyChanged []string
+	for _, col := range oa.keyCols {
+		keyChanged = append(keyChanged, fmt.Sprintf("NOT (OLD.%s <=> NEW.%s)", col, col))
+	}
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s (id tinyint unsigned NOT NULL PRIMARY KEY, clauses longtext NOT NULL, last_key longtext)", tengo.EscapeIdentifier(oa.state)),
+		fmt.Sprintf("CREATE TABLE %s LIKE %s", shadow, table),
+		fmt.Sprintf("ALTER TABLE %s %s", shadow, oa.clauses),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s FOR EACH ROW REPLACE INTO %s (%s) VALUES (%s)",
+			tengo.EscapeIdentifier(oa.triggers[0]), table, shadow, cols, newVals),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s FOR EACH ROW BEGIN DELETE IGNORE FROM %s WHERE (%s) AND %s; REPLACE INTO %s (%s) VALUES (%s); END",
+			tengo.EscapeIdentifier(oa.triggers[1]), table, shadow, strings.Join(keyChanged, " OR "), oa.newKeyCheck, shadow, cols, newVals),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s FOR EACH ROW DELETE IGNORE FROM %s WHERE %s",
+			tengo.EscapeIdentifier(oa.triggers[2]), table, shadow, oa.newKeyCheck),
+	}
+}
+
+// cleanupStatements returns statements for removing any leftovers from a
+// previous run which cannot be resumed.
+func (oa *onlineAlter) cleanupStatements() []string {
+	stmts := make([]string, 0, len(oa.triggers)+2)
+	for _, trigger := range oa.triggers {
+		stmts = append(stmts, "DROP TRIGGER IF EXISTS "+tengo.EscapeIdentifier(trigger))
+	}
+	return append(stmts,
+		"DROP TABLE IF EXISTS "+tengo.EscapeIdentifier(oa.shadow),
+		"DROP TABLE IF EXISTS "+tengo.EscapeIdentifier(oa.state),
+	)
+}
+
+// resumeState determines whether a previous run for the same ALTER may be
+// resumed, and if so, returns the last copied primary key value. Resuming is
+// only safe if the shadow table and all triggers still exist, since otherwise
+// writes may have been missed.
+func (oa *onlineAlter) resumeState(db *sqlx.DB) (lastKey []interface{}, ok bool, err error) {
+	var clauses string
+	var rawLastKey sql.NullString
+	query := fmt.Sprintf("SELECT clauses, last_key FROM %s WHERE id = 1", tengo.EscapeIdentifier(oa.state))
+	if err := db.QueryRow(query).Scan(&clauses, &rawLastKey); err != nil || clauses != oa.clauses {
+		return nil, false, nil // no state table, or state from a different ALTER
+	}
+	var count int
+	query = "SELECT COUNT(*) FROM information_schema.triggers WHERE trigger_schema = DATABASE() AND event_object_table = ? AND trigger_name IN (?, ?, ?)"
+	if err := db.QueryRow(query, oa.table, oa.triggers[0], oa.triggers[1], oa.triggers[2]).Scan(&count); err != nil {
+		return nil, false, err
+	} else if count < len(oa.triggers) {
+		return nil, false, nil
+	}
+	query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	if err := db.QueryRow(query, oa.shadow).Scan(&count); err != nil || count == 0 {
+		return nil, false, err
+	}
+	if rawLastKey.Valid {
+		if lastKey, err = decodeKey(rawLastKey.String); err != nil {
+			return nil, false, err
+		}
+	}
+	return lastKey, true, nil
+}
+
+// checkShadowOwnership returns an error if the shadow table already exists but
+// the state table has no record of it, in which case the shadow table was not
+// created by a previous run of builtinOSC, and must not be dropped.
+func (oa *onlineAlter) checkShadowOwnership(db *sqlx.DB) error {
+	var count int
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	if err := db.QueryRow(query, oa.shadow).Scan(&count); err != nil {
+		return err
+	} else if count == 0 {
+		return nil
+	}
+	var clauses string
+	query = fmt.Sprintf("SELECT clauses FROM %s WHERE id = 1", tengo.EscapeIdentifier(oa.state))
+	if err := db.QueryRow(query).Scan(&clauses); err != nil {
+		return fmt.Errorf("table %s already exists, but was not created by a previous online schema change of %s; it must be dropped or renamed manually", tengo.EscapeIdentifier(oa.shadow), tengo.EscapeIdentifier(oa.table))
+	}
+	return nil
+}
+
+// initState records the ALTER clauses in the state table, so that a later
+// run can determine whether it may resume.
+func (oa *onlineAlter) initState(db *sqlx.DB) error {
+	_, err := db.Exec(fmt.Sprintf("INSERT INTO %s (id, clauses) VALUES (1, ?)", tengo.EscapeIdentifier(oa.state)), oa.clauses)
+	return err
+}
+
+// saveState persists lastKey in the state table.
+func (oa *onlineAlter) saveState(db *sqlx.DB, lastKey []interface{}) error {
+	encoded, err := encodeKey(lastKey)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("UPDATE %s SET last_key = ? WHERE id = 1", tengo.EscapeIdentifier(oa.state)), encoded)
+	return err
+}
+
+// checkReferencingForeignKeys returns an error if any foreign keys reference
+// the original table. After the swap, such foreign keys would follow the
+// original table to its new name, leaving the child tables referencing the
+// old table instead of the altered one.
+func (oa *onlineAlter) checkReferencingForeignKeys(db *sqlx.DB) error {
+	var children []string
+	query := `
+		SELECT   DISTINCT CONCAT(constraint_schema, '.', table_name)
+		FROM     information_schema.referential_constraints
+		WHERE    unique_constraint_schema = DATABASE() AND referenced_table_name = ?
+		AND      NOT (constraint_schema = DATABASE() AND table_name = ?)
+		ORDER BY 1`
+	if err := db.Select(&children, query, oa.table, oa.table); err != nil {
+		return err
+	} else if len(children) > 0 {
+		return fmt.Errorf("table %s is referenced by foreign keys in %s, which is not supported by online schema change", tengo.EscapeIdentifier(oa.table), strings.Join(children, ", "))
+	}
+	return nil
+}
+
+// keyValue is the persisted representation of one column of a primary key
+// value in the state table. Integers are stored as such, rather than as
+// strings, so that they are compared as integers when bound as query args;
+// all other values are stored as raw bytes.
+type keyValue struct {
+	Int   *int64  `json:"int,omitempty"`
+	Uint  *uint64 `json:"uint,omitempty"`
+	Bytes []byte  `json:"bytes,omitempty"`
+}
+
+// encodeKey returns a JSON representation of a primary key value obtained
+// from chunkUpperBound.
+func encodeKey(key []interface{}) (string, error) {
+	values := make([]keyValue, len(key))
+	for n := range key {
+		switch v := key[n].(type) {
+		case int64:
+			values[n].Int = &v
+		case uint64:
+			values[n].Uint = &v
+		case []byte:
+			values[n].Bytes = v
+		default:
+			return "", fmt.Errorf("unexpected primary key value type %T", v)
+		}
+	}
+	encoded, err := json.Marshal(values)
+	return string(encoded), err
+}
+
+// decodeKey reverses encodeKey.
+func decodeKey(encoded string) ([]interface{}, error) {
+	var values []keyValue
+	if err := json.Unmarshal([]byte(encoded), &values); err != nil {
+		return nil, err
+	}
+	key := make([]interface{}, len(values))
+	for n, v := range values {
+		if v.Int != nil {
+			key[n] = *v.Int
+		} else if v.Uint != nil {
+			key[n] = *v.Uint
+		} else if v.Bytes != nil {
+			key[n] = v.Bytes
+		} else {
+			key[n] = []byte{}
+		}
+	}
+	return key, nil
+}
+
+// keyAfter returns a WHERE condition and args for rows with a primary key
+// greater than lastKey. If lastKey is nil, the condition matches all rows.
+func (oa *onlineAlter) keyAfter(lastKey []interface{}) (string, []interface{}) {
+	if lastKey == nil {
+		return "1=1", nil
+	}
+	return "(" + strings.Join(oa.keyCols, ", ") + ") > (" + placeholders(len(lastKey)) + ")", slices.Clone(lastKey)
+}
+
+// chunkUpperBound returns the primary key value of the last row in the chunk
+// starting after lastKey, or nil if fewer than chunkSize rows remain. Integer
+// key columns are returned as int64 or uint64, and all others as []byte, so
+// that binding them as args does not change how they compare: binding an
+// integer as a string would cause MySQL to compare it as a double, losing
+// precision for large BIGINT values.
+func (oa *onlineAlter) chunkUpperBound(db *sqlx.DB, lastKey []interface{}, chunkSize int) ([]interface{}, error) {
+	where, args := oa.keyAfter(lastKey)
+	keys := strings.Join(oa.keyCols, ", ")
+	query := fmt.Sprintf("SELECT %s FROM %s FORCE INDEX (PRIMARY) WHERE %s ORDER BY %s LIMIT 1 OFFSET %d",
+		keys, tengo.EscapeIdentifier(oa.table), where, keys, chunkSize-1)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	dest := make([]interface{}, len(colTypes))
+	for n, ct := range colTypes {
+		if typeName := ct.DatabaseTypeName(); !strings.HasSuffix(typeName, "INT") {
+			dest[n] = new([]byte)
+		} else if strings.HasPrefix(typeName, "UNSIGNED") {
+			dest[n] = new(uint64)
+		} else {
+			dest[n] = new(int64)
+		}
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	upperKey := make([]interface{}, len(dest))
+	for n := range dest {
+		switch d := dest[n].(type) {
+		case *[]byte:
+			upperKey[n] = *d
+		case *uint64:
+			upperKey[n] = *d
+		case *int64:
+			upperKey[n] = *d
+		}
+	}
+	return upperKey, nil
+}
+
+// copyChunkQuery returns a query and args for copying rows with primary key
+// greater than lastKey, up to and including upperKey. If upperKey is nil, all
+// remaining rows are copied. The rows are read with a shared lock: otherwise,
+// under READ COMMITTED, a concurrently-deleted row could be copied after the
+// delete trigger already ran, resurrecting the row in the shadow table.
+func (oa *onlineAlter) copyChunkQuery(lastKey, upperKey []interface{}) (string, []interface{}) {
+	where, args := oa.keyAfter(lastKey)
+	if upperKey != nil {
+		where += " AND (" + strings.Join(oa.keyCols, ", ") + ") <= (" + placeholders(len(upperKey)) + ")"
+		args = append(args, upperKey...)
+	}
+	cols := strings.Join(oa.copyCols, ", ")
+	return fmt.Sprintf("INSERT IGNORE INTO %s (%s) SELECT %s FROM %s FORCE INDEX (PRIMARY) WHERE %s %s",
+		tengo.EscapeIdentifier(oa.shadow), cols, cols, tengo.EscapeIdentifier(oa.table), where, oa.lockClause), args
+}
+
+// swapStatement returns a statement which atomically swaps the original and
+// shadow tables.
+func (oa *onlineAlter) swapStatement() string {
+	return fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s",
+		tengo.EscapeIdentifier(oa.table), tengo.EscapeIdentifier(oa.old),
+		tengo.EscapeIdentifier(oa.shadow), tengo.EscapeIdentifier(oa.table))
+}
+
+// finishStatements returns statements for removing the original table (along
+// with its triggers) and the state table after a successful swap.
+func (oa *onlineAlter) finishStatements() []string {
+	return []string{
+		"DROP TABLE " + tengo.EscapeIdentifier(oa.old),
+		"DROP TABLE IF EXISTS " + tengo.EscapeIdentifier(oa.state),
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,308 @@
+package applier
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestOnlineAlterStatements(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	emailCol := &tengo.Column{Name: "email", Type: tengo.ParseColumnType("varchar(100)"), Nullable: true, Default: "NULL"}
+	pk := &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	makeTable := func(cols ...*tengo.Column) *tengo.Table {
+		table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: cols, PrimaryKey: pk}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	td := tengo.NewAlterTable(makeTable(idCol, nameCol), makeTable(idCol, nameCol, emailCol))
+	oa, err := newOnlineAlter(td, tengo.StatementModifiers{})
+	if err != nil {
+		t.Fatalf("Unexpected error from newOnlineAlter: %v", err)
+	}
+
+	setup := oa.setupStatements()
+	expectSetup := []string{
+		"CREATE TABLE `_users_skosc` LIKE `users`",
+		"ALTER TABLE `_users_skosc` ADD COLUMN `email` varchar(100) DEFAULT NULL",
+		"CREATE TRIGGER `_users_osc_ins` AFTER INSERT ON `users` FOR EACH ROW REPLACE INTO `_users_skosc` (`id`, `name`) VALUES (NEW.`id`, NEW.`name`)",
+		"CREATE TRIGGER `_users_osc_upd` AFTER UPDATE ON `users` FOR EACH ROW BEGIN DELETE IGNORE FROM `_users_skosc` WHERE (NOT (OLD.`id` <=> NEW.`id`)) AND `_users_skosc`.`id` <=> OLD.`id`; REPLACE INTO `_users_skosc` (`id`, `name`) VALUES (NEW.`id`, NEW.`name`); END",
+		"CREATE TRIGGER `_users_osc_del` AFTER DELETE ON `users` FOR EACH ROW DELETE IGNORE FROM `_users_skosc` WHERE `_users_skosc`.`id` <=> OLD.`id`",
+	}
+	if len(setup) != len(expectSetup)+1 {
+		t.Fatalf("Expected %d setup statements, instead found %d: %v", len(expectSetup)+1, len(setup), setup)
+	}
+	for n, expected := range expectSetup {
+		if setup[n+1] != expected {
+			t.Errorf("Unexpected setup statement[%d]:\nexpected: %s\nfound:    %s", n+1, expected, setup[n+1])
+		}
+	}
+
+	query, args := oa.copyChunkQuery(nil, []interface{}{uint64(1000)})
+	expected := "INSERT IGNORE INTO `_users_skosc` (`id`, `name`) SELECT `id`, `name` FROM `users` FORCE INDEX (PRIMARY) WHERE 1=1 AND (`id`) <= (?) LOCK IN SHARE MODE"
+	if query != expected || len(args) != 1 {
+		t.Errorf("Unexpected first chunk query:\nexpected: %s\nfound:    %s (args %v)", expected, query, args)
+	}
+	query, args = oa.copyChunkQuery([]interface{}{uint64(1000)}, nil)
+	expected = "INSERT IGNORE INTO `_users_skosc` (`id`, `name`) SELECT `id`, `name` FROM `users` FORCE INDEX (PRIMARY) WHERE (`id`) > (?) LOCK IN SHARE MODE"
+	if query != expected || len(args) != 1 || args[0] != uint64(1000) {
+		t.Errorf("Unexpected last chunk query:\nexpected: %s\nfound:    %s (args %v)", expected, query, args)
+	}
+	oa8, err := newOnlineAlter(td, tengo.StatementModifiers{Flavor: tengo.ParseFlavor("mysql:8.0")})
+	if err != nil {
+		t.Fatalf("Unexpected error from newOnlineAlter: %v", err)
+	}
+	if query, _ := oa8.copyChunkQuery(nil, nil); !strings.HasSuffix(query, " FOR SHARE") {
+		t.Errorf("Expected MySQL 8 chunk query to use FOR SHARE, instead found %s", query)
+	}
+	if expected := "RENAME TABLE `users` TO `_users_old`, `_users_skosc` TO `users`"; oa.swapStatement() != expected {
+		t.Errorf("Unexpected swap statement:\nexpected: %s\nfound:    %s", expected, oa.swapStatement())
+	}
+
+	// Tables without a primary key are not supported
+	noPK := makeTable(idCol, nameCol)
+	noPK.PrimaryKey = nil
+	noPK.CreateStatement = noPK.GeneratedCreateStatement(tengo.FlavorUnknown)
+	if _, err := newOnlineAlter(tengo.NewAlterTable(noPK, makeTable(idCol, nameCol, emailCol)), tengo.StatementModifiers{}); err == nil {
+		t.Error("Expected error from newOnlineAlter on table without primary key, but it was nil")
+	}
+}
+
+func TestOnlineAlterKeyEncoding(t *testing.T) {
+	key := []interface{}{int64(-5), uint64(18446744073709551615), []byte("abc\x00\xff"), []byte{}}
+	encoded, err := encodeKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error from encodeKey: %v", err)
+	}
+	decoded, err := decodeKey(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error from decodeKey: %v", err)
+	}
+	if !reflect.DeepEqual(key, decoded) {
+		t.Errorf("Key did not round-trip: expected %#v, instead found %#v", key, decoded)
+	}
+	if _, err := encodeKey([]interface{}{"string"}); err == nil {
+		t.Error("Expected error from encodeKey with unexpected type, but it was nil")
+	}
+}
+
+func (s ApplierIntegrationSuite) TestBuiltinOSC(t *testing.T) {
+	db := s.builtinOSCSetup(t)
+	exec := func(query string) {
+		t.Helper()
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	exec("CREATE TABLE osctest (id int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(30))")
+	for n := 0; n < 5; n++ {
+		exec("INSERT INTO osctest (name) VALUES ('a'), ('b'), ('c')")
+	}
+	ddl := s.builtinOSCStatement(t, "osctest")
+	if err := ddl.Execute(); err != nil {
+		t.Fatalf("Unexpected error from Execute: %s", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM osctest WHERE email IS NULL").Scan(&count); err != nil {
+		t.Fatalf("Unexpected error querying altered table: %s", err)
+	} else if count != 15 {
+		t.Errorf("Expected 15 rows in altered table, instead found %d", count)
+	}
+	var leftovers []string
+	if err := db.Select(&leftovers, "SELECT table_name FROM information_schema.tables WHERE table_schema = 'product' AND table_name LIKE '\\_osctest\\_%'"); err != nil {
+		t.Fatalf("Unexpected error querying information_schema: %s", err)
+	} else if len(leftovers) > 0 {
+		t.Errorf("Expected no leftover tables, instead found %s", strings.Join(leftovers, ", "))
+	}
+}
+
+// TestBuiltinOSCLargeKeys confirms that rows are not skipped at chunk
+// boundaries when primary key values exceed the precision of a double.
+func (s ApplierIntegrationSuite) TestBuiltinOSCLargeKeys(t *testing.T) {
+	db := s.builtinOSCSetup(t)
+	if _, err := db.Exec("CREATE TABLE osclarge (id bigint unsigned NOT NULL PRIMARY KEY, name varchar(30))"); err != nil {
+		t.Fatalf("Unexpected error creating table: %s", err)
+	}
+	// Consecutive values above 2^53 are indistinguishable when compared as
+	// doubles, so every chunk boundary would be ambiguous
+	const base = uint64(1) << 62
+	for n := uint64(0); n < 15; n++ {
+		if _, err := db.Exec("INSERT INTO osclarge (id, name) VALUES (?, 'x')", base+n); err != nil {
+			t.Fatalf("Unexpected error inserting row: %s", err)
+		}
+	}
+	ddl := s.builtinOSCStatement(t, "osclarge")
+	if err := ddl.Execute(); err != nil {
+		t.Fatalf("Unexpected error from Execute: %s", err)
+	}
+	var ids []uint64
+	if err := db.Select(&ids, "SELECT id FROM osclarge ORDER BY id"); err != nil {
+		t.Fatalf("Unexpected error querying altered table: %s", err)
+	} else if len(ids) != 15 {
+		t.Fatalf("Expected 15 rows in altered table, instead found %d", len(ids))
+	}
+	for n, id := range ids {
+		if id != base+uint64(n) {
+			t.Errorf("Expected row %d to have id %d, instead found %d", n, base+uint64(n), id)
+		}
+	}
+}
+
+// TestBuiltinOSCReferencedTable confirms that tables referenced by foreign
+// keys are rejected, rather than leaving the child tables referencing the
+// original table after the swap.
+func (s ApplierIntegrationSuite) TestBuiltinOSCReferencedTable(t *testing.T) {
+	db := s.builtinOSCSetup(t)
+	for _, query := range []string{
+		"CREATE TABLE oscparent (id int unsigned NOT NULL PRIMARY KEY, name varchar(30))",
+		"CREATE TABLE oscchild (id int unsigned NOT NULL PRIMARY KEY, parent_id int unsigned, CONSTRAINT oscfk FOREIGN KEY (parent_id) REFERENCES oscparent (id))",
+		"INSERT INTO oscparent (id, name) VALUES (1, 'a')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	ddl := s.builtinOSCStatement(t, "oscparent")
+	if err := ddl.Execute(); err == nil || !strings.Contains(err.Error(), "oscchild") {
+		t.Errorf("Expected error mentioning child table from Execute, instead found %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'product' AND table_name = '_oscparent_skosc'").Scan(&count); err != nil {
+		t.Fatalf("Unexpected error querying information_schema: %s", err)
+	} else if count > 0 {
+		t.Error("Expected shadow table to not be created for referenced table")
+	}
+}
+
+// TestBuiltinOSCExistingShadow confirms that a pre-existing table with the
+// shadow table's name is not dropped unless a previous run created it.
+func (s ApplierIntegrationSuite) TestBuiltinOSCExistingShadow(t *testing.T) {
+	db := s.builtinOSCSetup(t)
+	for _, query := range []string{
+		"CREATE TABLE oscshadow (id int unsigned NOT NULL PRIMARY KEY, name varchar(30))",
+		"CREATE TABLE _oscshadow_skosc (id int unsigned NOT NULL PRIMARY KEY)",
+		"INSERT INTO oscshadow (id, name) VALUES (1, 'a')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	ddl := s.builtinOSCStatement(t, "oscshadow")
+	if err := ddl.Execute(); err == nil || !strings.Contains(err.Error(), "_oscshadow_skosc") {
+		t.Errorf("Expected error mentioning shadow table from Execute, instead found %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'product' AND table_name = '_oscshadow_skosc'").Scan(&count); err != nil {
+		t.Fatalf("Unexpected error querying information_schema: %s", err)
+	} else if count != 1 {
+		t.Error("Expected pre-existing shadow table to be left in place")
+	}
+
+	// Once the state table records a previous run, the shadow table may be
+	// replaced
+	for _, query := range []string{
+		"CREATE TABLE _oscshadow_osc (id tinyint unsigned NOT NULL PRIMARY KEY, clauses longtext NOT NULL, last_key longtext)",
+		"INSERT INTO _oscshadow_osc (id, clauses) VALUES (1, 'DROP COLUMN bogus')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	if err := ddl.Execute(); err != nil {
+		t.Errorf("Unexpected error from Execute: %v", err)
+	}
+}
+
+// builtinOSCSetup sources the standard test schema and returns a connection
+// pool for it.
+func (s ApplierIntegrationSuite) builtinOSCSetup(t *testing.T) *sqlx.DB {
+	t.Helper()
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	return db
+}
+
+// builtinOSCStatement returns a DDLStatement which uses the built-in online
+// schema change tool, with a small chunk size, to add a column to the supplied
+// table in the product schema.
+func (s ApplierIntegrationSuite) builtinOSCStatement(t *testing.T, tableName string) *DDLStatement {
+	t.Helper()
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	exec := func(query string) {
+		t.Helper()
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	from, err := s.d[0].Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %s", err)
+	}
+	exec("ALTER TABLE " + tengo.EscapeIdentifier(tableName) + " ADD COLUMN email varchar(100)")
+	to, err := s.d[0].Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %s", err)
+	}
+	exec("ALTER TABLE " + tengo.EscapeIdentifier(tableName) + " DROP COLUMN email")
+
+	cfg := mybase.SimpleConfig(map[string]string{
+		"osc-tool":               "builtin",
+		"osc-chunk-size":         "4",
+		"osc-max-replica-lag":    "0",
+		"osc-replicas":           "",
+		"max-replica-lag":        "0",
+		"replicas":               "",
+		"init-sql":               "",
+		"mdl-report-after":       "0",
+		"mdl-kill-idle":          "0",
+		"mdl-kill-users":         "",
+		"statement-timeout":      "0",
+		"wsrep-osu-method":       "",
+		"osc-postpone-cut-over":  "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "0",
+		"alter-algorithm":        "",
+		"ddl-wrapper":            "",
+		"safe-below-size":        "0",
+		"large-table-size":       "0",
+		"require-osc-size":       "0",
+		"require-osc-rows":       "0",
+		"table-stats":            "false",
+		"foreign-key-checks":     "",
+	})
+	target := &Target{
+		Instance:      s.d[0].Instance,
+		Dir:           &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName:    "product",
+		DesiredSchema: &workspace.Schema{Schema: to},
+	}
+	var ddl *DDLStatement
+	for _, diff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		if diff.ObjectKey().Name == tableName {
+			if ddl, err = NewDDLStatement(diff, tengo.StatementModifiers{Flavor: s.d[0].Flavor()}, target); err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %s", err)
+			}
+		}
+	}
+	if ddl == nil || ddl.osc == nil {
+		t.Fatalf("Expected DDLStatement using built-in OSC, instead found %+v", ddl)
+	}
+	return ddl
+}
@@ -0,0 +1,163 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestGetOSCTool(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	makeTable := func(cols ...*tengo.Column) *tengo.Table {
+		table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: cols}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	from, to := makeTable(idCol), makeTable(idCol, nameCol)
+	alter, create := tengo.NewAlterTable(from, to), tengo.NewCreateTable(to)
+	getConfig := func(overrides map[string]string) *mybase.Config {
+		values := map[string]string{
+			"osc-tool":               "gh-ost",
+			"alter-wrapper":          "",
+			"alter-wrapper-min-size": "1k",
+			"osc-postpone-cut-over":  "",
+		}
+		for k, v := range overrides {
+			values[k] = v
+		}
+		return mybase.SimpleConfig(values)
+	}
+	inMods := tengo.StatementModifiers{AlgorithmClause: "inplace", LockClause: "none"}
+
+	osc, mods, err := getOSCTool(getConfig(nil), alter, 2048, inMods)
+	if err != nil || osc == nil {
+		t.Fatalf("Expected getOSCTool to return gh-ost tool without error; instead found %v, %v", osc, err)
+	} else if osc.name() != "gh-ost" {
+		t.Errorf("Unexpected tool name %q", osc.name())
+	} else if mods.AlgorithmClause != "" || mods.LockClause != "" {
+		t.Errorf("Expected ALGORITHM and LOCK clauses to be cleared, instead found %+v", mods)
+	}
+
+	cases := []struct {
+		overrides map[string]string
+		diff      tengo.ObjectDiff
+		tableSize int64
+	}{
+		{map[string]string{"osc-tool": "none"}, alter, 2048},
+		{nil, create, 2048},
+		{nil, alter, 512},
+	}
+	for n, c := range cases {
+		if osc, mods, err := getOSCTool(getConfig(c.overrides), c.diff, c.tableSize, inMods); osc != nil || err != nil {
+			t.Errorf("Case %d: expected nil tool and nil error, instead found %v, %v", n, osc, err)
+		} else if mods != inMods {
+			t.Errorf("Case %d: expected mods to be unchanged, instead found %+v", n, mods)
+		}
+	}
+
+	for _, overrides := range []map[string]string{{"osc-tool": "bogus"}, {"alter-wrapper": "/bin/echo {TABLE}"}, {"osc-tool": "pt-osc", "osc-postpone-cut-over": "1"}} {
+		if _, _, err := getOSCTool(getConfig(overrides), alter, 2048, inMods); err == nil {
+			t.Errorf("Expected error from getOSCTool with config %v, but it was nil", overrides)
+		}
+	}
+}
+
+func TestGhostToolCommandLine(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{
+		"osc-tool-bin":          "",
+		"osc-tool-options":      "",
+		"osc-postpone-cut-over": "",
+	})
+	expected := "gh-ost --host={HOST} --port={PORT} --user={USER} --password={PASSWORDX} --database={SCHEMA} --table={TABLE} --alter={CLAUSES} --execute"
+	if actual := (&ghostTool{}).commandLine(cfg); actual != expected {
+		t.Errorf("Unexpected command-line:\nexpected: %s\nfound:    %s", expected, actual)
+	}
+
+	cfg = mybase.SimpleConfig(map[string]string{
+		"osc-tool-bin":          "/opt/bin/gh-ost",
+		"osc-tool-options":      "--allow-on-master --max-load=Threads_running=25",
+		"osc-postpone-cut-over": "1",
+	})
+	expected = "/opt/bin/gh-ost --host={HOST} --port={PORT} --user={USER} --password={PASSWORDX} --database={SCHEMA} --table={TABLE} --alter={CLAUSES} --postpone-cut-over-flag-file={FLAGFILE} --allow-on-master --max-load=Threads_running=25 --execute"
+	if actual := (&ghostTool{}).commandLine(cfg); actual != expected {
+		t.Errorf("Unexpected command-line:\nexpected: %s\nfound:    %s", expected, actual)
+	}
+}
+
+func TestPTOSCTool(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{
+		"osc-tool-bin":     "",
+		"osc-tool-options": "--max-lag=5",
+	})
+	pt := &ptOSCTool{}
+	base := "pt-online-schema-change --alter={CLAUSES} --max-lag=5 D={SCHEMA},t={TABLE},h={HOST},P={PORT},u={USER},p={PASSWORDX}"
+	expected := base + " --dry-run && " + base + " --execute"
+	if actual := pt.commandLine(cfg); actual != expected {
+		t.Errorf("Unexpected command-line:\nexpected: %s\nfound:    %s", expected, actual)
+	}
+
+	ddl := &DDLStatement{diff: tengo.NewDropTable(&tengo.Table{Name: "users"})}
+	lines := []string{
+		"Starting a dry run.  `analytics`.`users` will not be altered.  Specify --execute instead of --dry-run to alter the table.",
+		"Copying `analytics`.`users`:  45% 01:23 remain",
+		"Error altering new table `analytics`.`_users_new`: DBD::mysql::db do failed: Duplicate column name 'email'",
+		"`analytics`.`users` was not altered.",
+	}
+	for _, line := range lines {
+		pt.handleOutput(ddl, line)
+	}
+	if expected := lines[2]; pt.errorDetail() != expected {
+		t.Errorf("Unexpected error detail:\nexpected: %s\nfound:    %s", expected, pt.errorDetail())
+	}
+}
+
+func TestGhostToolHandleOutput(t *testing.T) {
+	gh := &ghostTool{}
+	ddl := &DDLStatement{diff: tengo.NewDropTable(&tengo.Table{Name: "users"})}
+	lines := []string{
+		"Copy: 0/100 0.0%; Applied: 0; Backlog: 0/1000; Time: 1s(total), 0s(copy)",
+		"2024-01-01 00:00:00 FATAL Table `_users_gho` already exists. Panicking. Use --initially-drop-ghost-table to force dropping it, though I really prefer that you drop it or rename it away",
+	}
+	for _, line := range lines {
+		gh.handleOutput(ddl, line)
+	}
+	if expected := lines[1]; gh.errorDetail() != expected {
+		t.Errorf("Unexpected error detail:\nexpected: %s\nfound:    %s", expected, gh.errorDetail())
+	}
+}
+
+// TestExistingOSCArtifact confirms that pre-existing tables and triggers
+// created by an OSC tool are detected, so that cleanup does not interfere with another run.
+func (s ApplierIntegrationSuite) TestExistingOSCArtifact(t *testing.T) {
+	db := s.builtinOSCSetup(t)
+	ddl := &DDLStatement{
+		diff:       tengo.NewDropTable(&tengo.Table{Name: "users"}),
+		osc:        &ghostTool{},
+		instance:   s.d[0].Instance,
+		schemaName: "product",
+	}
+	if reason := ddl.existingOSCArtifact(); reason != "" {
+		t.Errorf("Expected no pre-existing artifacts, instead found reason %q", reason)
+	}
+	if _, err := db.Exec("CREATE TABLE _users_gho (id int)"); err != nil {
+		t.Fatalf("Unexpected error creating table: %v", err)
+	}
+	if reason := ddl.existingOSCArtifact(); !strings.Contains(reason, "_users_gho") {
+		t.Errorf("Expected pre-existing ghost table to be detected, instead found reason %q", reason)
+	}
+
+	// pt-online-schema-change triggers are detected as well
+	ddl.osc = &ptOSCTool{}
+	if reason := ddl.existingOSCArtifact(); reason != "" {
+		t.Errorf("Expected no pre-existing artifacts, instead found reason %q", reason)
+	}
+	if _, err := db.Exec("CREATE TRIGGER pt_osc_product_users_del AFTER DELETE ON users FOR EACH ROW SET @foo = 1"); err != nil {
+		t.Fatalf("Unexpected error creating trigger: %v", err)
+	}
+	if reason := ddl.existingOSCArtifact(); !strings.Contains(reason, "pt_osc_product_users_del") {
+		t.Errorf("Expected pre-existing trigger to be detected, instead found reason %q", reason)
+	}
+}
@@ -0,0 +1,190 @@
+package applier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// planFileVersion is the current version of the plan file format. Plan files
+// with a different version cannot be applied.
+const planFileVersion = 2
+
+// PlanFile is a serializable record of the statements generated for one or more
+// Targets, along with a fingerprint of each target schema at the time that the
+// statements were generated. It is written by `skeema diff --save-plan`, and
+// applied by `skeema push --plan`, which refuses to execute any statements for
+// a target whose schema has drifted since the plan was created. The format is
+// also intended to be consumed by external tooling, such as a Terraform
+// provider, so any incompatible change must increment planFileVersion.
+type PlanFile struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Targets   []PlanFileTarget `json:"targets"`
+}
+
+// PlanFileTarget is the portion of a PlanFile for a single Target.
+type PlanFileTarget struct {
+	ResourceID  string              `json:"resource_id"`
+	Instance    string              `json:"instance"`
+	Schema      string              `json:"schema"`
+	Dir         string              `json:"dir"`
+	Fingerprint string              `json:"fingerprint"`
+	Statements  []PlanFileStatement `json:"statements"`
+}
+
+// PlanFileStatement is a single statement within a PlanFileTarget, along with
+// a description of the object it affects.
+type PlanFileStatement struct {
+	ResourceID string `json:"resource_id,omitempty"`
+	ObjectType string `json:"object_type,omitempty"`
+	ObjectName string `json:"object_name,omitempty"`
+	Action     string `json:"action,omitempty"` // "create", "alter", or "drop"
+	Statement  string `json:"statement"`
+}
+
+// statementTexts returns the text of each statement in pft.
+func (pft *PlanFileTarget) statementTexts() []string {
+	texts := make([]string, len(pft.Statements))
+	for n, stmt := range pft.Statements {
+		texts[n] = stmt.Statement
+	}
+	return texts
+}
+
+// NewPlanFile returns a PlanFile containing the supplied plans. Plans without
+// any statements are omitted.
+func NewPlanFile(plans []*Plan) *PlanFile {
+	pf := &PlanFile{
+		Version:   planFileVersion,
+		CreatedAt: time.Now().UTC(),
+		Targets:   make([]PlanFileTarget, 0, len(plans)),
+	}
+	for _, plan := range plans {
+		if len(plan.Statements) == 0 {
+			continue
+		}
+		instance := plan.Target.Instance.String()
+		pft := PlanFileTarget{
+			ResourceID:  ResourceID(instance, plan.Target.SchemaName, tengo.ObjectKey{}),
+			Instance:    instance,
+			Schema:      plan.Target.SchemaName,
+			Dir:         plan.Target.Dir.RelPath(),
+			Fingerprint: plan.Fingerprint,
+			Statements:  make([]PlanFileStatement, len(plan.Statements)),
+		}
+		for n, stmt := range plan.Statements {
+			pft.Statements[n].Statement = stmt.Statement()
+			if explainer, ok := stmt.(Explainer); ok {
+				e := explainer.Explain()
+				pft.Statements[n].ResourceID = ResourceID(instance, plan.Target.SchemaName, e.Key)
+				pft.Statements[n].ObjectType, pft.Statements[n].ObjectName = string(e.Key.Type), e.Key.Name
+				pft.Statements[n].Action = e.Action
+			}
+		}
+		pf.Targets = append(pf.Targets, pft)
+	}
+	sort.Slice(pf.Targets, func(i, j int) bool {
+		if pf.Targets[i].Instance != pf.Targets[j].Instance {
+			return pf.Targets[i].Instance < pf.Targets[j].Instance
+		}
+		return pf.Targets[i].Schema < pf.Targets[j].Schema
+	})
+	return pf
+}
+
+// Write saves the plan file as JSON to path.
+func (pf *PlanFile) Write(path string) error {
+	b, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0666)
+}
+
+// ReadPlanFile reads and parses the plan file at path.
+func ReadPlanFile(path string) (*PlanFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pf := &PlanFile{}
+	if err := json.Unmarshal(b, pf); err != nil {
+		return nil, fmt.Errorf("Unable to parse plan file %s: %w", path, err)
+	} else if pf.Version != planFileVersion {
+		return nil, fmt.Errorf("Plan file %s has unsupported version %d", path, pf.Version)
+	}
+	return pf, nil
+}
+
+// Verify returns an error if plan differs from the corresponding target in the
+// plan file, either because the target's schema has drifted since the plan
+// file was created, or because the generated statements differ. A plan without
+// any statements is only permitted if the plan file lacks the target.
+func (pf *PlanFile) Verify(plan *Plan) error {
+	return pf.verify(plan, nil)
+}
+
+// verify is like Verify, but also handles resuming a partially-applied plan,
+// in which case completed is the leading portion of the target's statements
+// which were already executed. Since these statements have changed the
+// target's schema, the fingerprint is not compared in this situation; instead
+// the remaining statements must match the rest of the plan file.
+func (pf *PlanFile) verify(plan *Plan, completed []string) error {
+	var saved *PlanFileTarget
+	for n := range pf.Targets {
+		if pf.Targets[n].Instance == plan.Target.Instance.String() && pf.Targets[n].Schema == plan.Target.SchemaName {
+			saved = &pf.Targets[n]
+			break
+		}
+	}
+	if saved == nil {
+		if len(plan.Statements) > 0 {
+			return fmt.Errorf("plan file does not contain %s, but %s now generated", plan.Target, countAndNoun(len(plan.Statements), "statement is", "statements are"))
+		}
+		return nil
+	}
+	savedStatements := saved.statementTexts()
+	if len(completed) > 0 {
+		if len(completed) > len(savedStatements) || !slices.Equal(completed, savedStatements[:len(completed)]) {
+			return fmt.Errorf("statements already executed for %s differ from the plan file; the state file does not correspond to this plan file", plan.Target)
+		}
+		savedStatements = savedStatements[len(completed):]
+	} else if saved.Fingerprint != plan.Fingerprint {
+		return fmt.Errorf("schema %s has changed since the plan file was created", plan.Target)
+	}
+	statements := make([]string, len(plan.Statements))
+	for n, stmt := range plan.Statements {
+		statements[n] = stmt.Statement()
+	}
+	if !slices.Equal(statements, savedStatements) {
+		return fmt.Errorf("statements generated for %s differ from the plan file; the filesystem or configuration may have changed since the plan file was created", plan.Target)
+	}
+	return nil
+}
+
+// schemaFingerprint returns a hex-encoded SHA-256 hash of schema's default
+// character set, collation, and the fingerprints of all its objects. A nil
+// schema, representing a schema that does not exist, has a blank fingerprint.
+func schemaFingerprint(schema *tengo.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	objects := schema.Objects()
+	lines := make([]string, 0, len(objects)+1)
+	lines = append(lines, schema.CharSet+" "+schema.Collation)
+	for key, obj := range objects {
+		lines = append(lines, key.String()+" "+objectFingerprint(obj))
+	}
+	sort.Strings(lines[1:])
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
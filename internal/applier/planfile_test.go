@@ -0,0 +1,107 @@
+package applier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestPlanFile(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	makePlan := func(schemaName, fingerprint string, statements ...string) *Plan {
+		plan := &Plan{
+			Target:      &Target{Instance: inst, Dir: &fs.Dir{Path: "/var/tmp/fakedir"}, SchemaName: schemaName},
+			Fingerprint: fingerprint,
+		}
+		for _, stmt := range statements {
+			plan.Statements = append(plan.Statements, &fakeStatement{stmt: stmt})
+		}
+		return plan
+	}
+	plans := []*Plan{
+		makePlan("product", "abc", "CREATE TABLE foo (id int)", "DROP TABLE bar"),
+		makePlan("analytics", "def"),
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := NewPlanFile(plans).Write(path); err != nil {
+		t.Fatalf("Unexpected error from Write: %v", err)
+	}
+	pf, err := ReadPlanFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadPlanFile: %v", err)
+	} else if len(pf.Targets) != 1 || pf.Targets[0].Schema != "product" || pf.Targets[0].ResourceID != "127.0.0.1:3306/product" || len(pf.Targets[0].Statements) != 2 {
+		t.Fatalf("Unexpected plan file contents: %+v", pf)
+	} else if stmt := pf.Targets[0].Statements[1]; stmt.Statement != "DROP TABLE bar" || stmt.ResourceID != "" {
+		t.Errorf("Unexpected plan file statement: %+v", stmt)
+	}
+
+	cases := []struct {
+		plan      *Plan
+		expectErr bool
+	}{
+		{makePlan("product", "abc", "CREATE TABLE foo (id int)", "DROP TABLE bar"), false},
+		{makePlan("product", "xyz", "CREATE TABLE foo (id int)", "DROP TABLE bar"), true}, // drifted
+		{makePlan("product", "abc", "CREATE TABLE foo (id int)"), true},                   // statements differ
+		{makePlan("product", "abc"), true},                                                // no statements now
+		{makePlan("analytics", "def"), false},                                             // not in plan, no statements
+		{makePlan("analytics", "def", "ALTER TABLE pageviews DROP COLUMN domain"), true},  // not in plan, has statements
+	}
+	for n, c := range cases {
+		if err := pf.Verify(c.plan); (err != nil) != c.expectErr {
+			t.Errorf("Case %d: Unexpected return value from Verify: %v", n, err)
+		}
+	}
+
+	// Plan files with unknown version should be rejected
+	if err := os.WriteFile(path, []byte(`{"version": 999}`), 0666); err != nil {
+		t.Fatalf("Unable to write plan file: %v", err)
+	}
+	if _, err := ReadPlanFile(path); err == nil {
+		t.Error("Expected error from ReadPlanFile with unsupported version, but it was nil")
+	}
+}
+
+func TestResourceID(t *testing.T) {
+	cases := []struct {
+		instance string
+		schema   string
+		key      tengo.ObjectKey
+		expected string
+	}{
+		{"127.0.0.1:3306", "product", tengo.ObjectKey{}, "127.0.0.1:3306/product"},
+		{"127.0.0.1:3306", "product", tengo.ObjectKey{Type: tengo.ObjectTypeDatabase, Name: "product"}, "127.0.0.1:3306/product"},
+		{"127.0.0.1:3306", "product", tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "users"}, "127.0.0.1:3306/product/table/users"},
+		{"localhost:/var/lib/mysql/mysql.sock", "my db", tengo.ObjectKey{Type: tengo.ObjectTypeProc, Name: "a/b"}, "localhost:%2Fvar%2Flib%2Fmysql%2Fmysql.sock/my%20db/procedure/a%2Fb"},
+	}
+	for _, c := range cases {
+		if actual := ResourceID(c.instance, c.schema, c.key); actual != c.expected {
+			t.Errorf("Unexpected result from ResourceID(%q, %q, %s): expected %q, found %q", c.instance, c.schema, c.key, c.expected, actual)
+		}
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	if fp := schemaFingerprint(nil); fp != "" {
+		t.Errorf("Expected blank fingerprint for nil schema, instead found %q", fp)
+	}
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	table := &tengo.Table{Name: "users", Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci", Columns: []*tengo.Column{idCol}}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+	schema := &tengo.Schema{Name: "product", CharSet: "latin1", Collation: "latin1_swedish_ci"}
+	emptyFP := schemaFingerprint(schema)
+	schema.Tables = []*tengo.Table{table}
+	if fp := schemaFingerprint(schema); fp == emptyFP || len(fp) != 64 {
+		t.Errorf("Unexpected fingerprint %q for schema with table", fp)
+	}
+	schema.Tables = nil
+	schema.CharSet, schema.Collation = "utf8mb4", "utf8mb4_general_ci"
+	if fp := schemaFingerprint(schema); fp == emptyFP {
+		t.Error("Expected schema fingerprint to change along with default collation")
+	}
+}
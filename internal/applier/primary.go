@@ -0,0 +1,78 @@
+package applier
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// groupPrimary returns the current primary of the group replication topology
+// containing instance. If instance is not a member of a group, or is itself a
+// primary (including in multi-primary mode), instance is returned as-is. This
+// also permits connecting via a MySQL Router read-only port, since the group
+// membership is visible from any member.
+func groupPrimary(instance *tengo.Instance) (*tengo.Instance, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	var primaries []struct {
+		ID   string `db:"MEMBER_ID"`
+		Host string `db:"MEMBER_HOST"`
+		Port int    `db:"MEMBER_PORT"`
+	}
+	query := `SELECT MEMBER_ID, MEMBER_HOST, MEMBER_PORT
+	          FROM performance_schema.replication_group_members
+	          WHERE MEMBER_ROLE = 'PRIMARY' AND MEMBER_STATE = 'ONLINE'`
+	if err := db.Select(&primaries, query); err != nil {
+		// Flavors without group replication lack this table, or its MEMBER_ROLE
+		// column; either way, the instance isn't in a group
+		log.Debugf("Unable to query group replication members on %s: %s", instance, err)
+		return instance, nil
+	} else if len(primaries) == 0 {
+		return instance, nil
+	}
+	var serverUUID string
+	if err := db.Get(&serverUUID, "SELECT @@server_uuid"); err != nil {
+		return nil, err
+	}
+	for _, primary := range primaries {
+		if primary.ID == serverUUID {
+			return instance, nil
+		}
+	}
+	if len(primaries) > 1 {
+		return instance, nil // multi-primary, but this member is somehow not a primary; leave as-is
+	}
+	addr := net.JoinHostPort(primaries[0].Host, strconv.Itoa(primaries[0].Port))
+	primary, err := instanceForAddress(instance, addr)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to connect to group replication primary %s: %w", addr, err)
+	}
+	log.Infof("Using group replication primary %s instead of %s", primary, instance)
+	return primary, nil
+}
+
+// verifyWritable returns an error if instance has read_only or super_read_only
+// enabled.
+func verifyWritable(instance *tengo.Instance) error {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	var readOnly, superReadOnly bool
+	if err := db.QueryRow("SELECT @@global.read_only").Scan(&readOnly); err != nil {
+		return err
+	}
+	if err := db.QueryRow("SELECT @@global.super_read_only").Scan(&superReadOnly); err != nil && !tengo.IsSessionVarNameError(err) {
+		// super_read_only does not exist in MariaDB
+		return err
+	}
+	if readOnly || superReadOnly {
+		return fmt.Errorf("Refusing to run schema changes: %s has read_only or super_read_only enabled, so it is likely not a primary", instance)
+	}
+	return nil
+}
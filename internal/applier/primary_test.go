@@ -0,0 +1,30 @@
+package applier
+
+import (
+	"testing"
+)
+
+func (s ApplierIntegrationSuite) TestGroupPrimary(t *testing.T) {
+	// The test instance is not a group replication member, so it should be
+	// returned as-is
+	if primary, err := groupPrimary(s.d[0].Instance); err != nil || primary != s.d[0].Instance {
+		t.Errorf("Unexpected return from groupPrimary: %v, %v", primary, err)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestVerifyWritable(t *testing.T) {
+	if err := verifyWritable(s.d[0].Instance); err != nil {
+		t.Errorf("Unexpected error from verifyWritable: %v", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	if _, err := db.Exec("SET GLOBAL read_only = 1"); err != nil {
+		t.Fatalf("Unable to enable read_only: %v", err)
+	}
+	defer db.Exec("SET GLOBAL read_only = 0")
+	if err := verifyWritable(s.d[0].Instance); err == nil {
+		t.Error("Expected error from verifyWritable with read_only enabled, but err was nil")
+	}
+}
@@ -2,6 +2,8 @@ package applier
 
 import (
 	"fmt"
+	"os"
+	"slices"
 	"sync"
 
 	"github.com/skeema/mybase"
@@ -21,11 +23,29 @@ type Finisher interface {
 	Finish(*Target)
 }
 
+// PlanRecorder is an interface for printers that also retain each Plan after
+// it has been run.
+type PlanRecorder interface {
+	Printer
+	RecordPlan(*Plan)
+}
+
+// ResultReporter is an interface for printers that also report the outcome of
+// each Target, after it has been applied. The plan may be nil if an error
+// occurred before the target could be planned.
+type ResultReporter interface {
+	Printer
+	ReportResult(t *Target, plan *Plan, result Result, err error)
+}
+
 // standardPrinter displays full output for each statement.
 type standardPrinter struct {
 	lastStdoutInstance  string
 	lastStdoutSchema    string
 	lastStdoutDelimiter string
+	createDiff          string // "unified" or "side-by-side" to also show CREATE TABLE line diffs
+	tableStats          bool   // if true, show size and row estimates of altered or dropped tables
+	annotateSource      bool   // if true, show the file and line range defining each object
 	m                   sync.Mutex
 }
 
@@ -40,14 +60,32 @@ type instanceDiffPrinter struct {
 
 // NewPrinter returns a standard printer (displaying all generated SQL), unless
 // the supplied configuration requests only outputting names of instances that
-// have differences.
+// have differences, plain-language explanations of each statement, JSON, or a
+// Markdown summary. The standard printer optionally also displays a line diff
+// of the CREATE TABLE statements underlying each ALTER TABLE, the size and row
+// estimates of each altered or dropped table, and the location of each
+// object's definition in the filesystem.
 func NewPrinter(cfg *mybase.Config) Printer {
-	if cfg.GetBool("brief") {
+	switch cfg.Get("output-format") {
+	case "json":
+		return &jsonPrinter{w: os.Stdout}
+	case "markdown":
+		return &markdownPrinter{w: os.Stdout}
+	}
+	if cfg.GetBool("explain") {
+		return &explainPrinter{}
+	} else if cfg.GetBool("brief") {
 		return &instanceDiffPrinter{
 			seenInstance: make(map[string]bool),
 		}
 	}
-	return &standardPrinter{lastStdoutDelimiter: ";"}
+	createDiff, _ := cfg.GetEnum("create-diff", "unified", "side-by-side")
+	return &standardPrinter{
+		lastStdoutDelimiter: ";",
+		createDiff:          createDiff,
+		tableStats:          cfg.GetBool("table-stats"),
+		annotateSource:      cfg.GetBool("annotate-source"),
+	}
 }
 
 // Print outputs stmt to STDOUT, in a way that prevents interleaving of output
@@ -78,6 +116,17 @@ func (p *standardPrinter) Print(stmt PlannedStatement) {
 		fmt.Printf("DELIMITER %s\n", cs.Delimiter)
 		p.lastStdoutDelimiter = cs.Delimiter
 	}
+	if p.createDiff != "" {
+		fmt.Print(createDiffText(stmt, p.createDiff))
+	}
+	if ddl, ok := stmt.(*DDLStatement); ok {
+		if p.annotateSource && ddl.source != "" {
+			fmt.Printf("-- source: %s\n", ddl.source)
+		}
+		if p.tableStats && ddl.stats != nil {
+			fmt.Printf("-- table %s: %s\n", tengo.EscapeIdentifier(ddl.diff.ObjectKey().Name), ddl.stats)
+		}
+	}
 	fmt.Print(stmt.Statement(), cs.Delimiter, "\n")
 }
 
@@ -102,3 +151,52 @@ func (idp *instanceDiffPrinter) Print(stmt PlannedStatement) {
 		idp.seenInstance[instString] = true
 	}
 }
+
+// RecordingPrinter wraps another Printer, additionally recording each Plan
+// that is run, so that a PlanFile or rollback script can be generated
+// afterwards.
+type RecordingPrinter struct {
+	Printer
+	plans []*Plan
+	m     sync.Mutex
+}
+
+// NewRecordingPrinter returns a RecordingPrinter wrapping p.
+func NewRecordingPrinter(p Printer) *RecordingPrinter {
+	return &RecordingPrinter{Printer: p}
+}
+
+// RecordPlan satisfies the PlanRecorder interface.
+func (rp *RecordingPrinter) RecordPlan(plan *Plan) {
+	rp.m.Lock()
+	defer rp.m.Unlock()
+	rp.plans = append(rp.plans, plan)
+}
+
+// Finish calls the wrapped printer's Finish method, if it has one.
+func (rp *RecordingPrinter) Finish(t *Target) {
+	if finisher, ok := rp.Printer.(Finisher); ok {
+		finisher.Finish(t)
+	}
+}
+
+// ReportResult calls the wrapped printer's ReportResult method, if it has one.
+func (rp *RecordingPrinter) ReportResult(t *Target, plan *Plan, result Result, err error) {
+	if reporter, ok := rp.Printer.(ResultReporter); ok {
+		reporter.ReportResult(t, plan, result, err)
+	}
+}
+
+// PlanFile returns a PlanFile containing all recorded plans.
+func (rp *RecordingPrinter) PlanFile() *PlanFile {
+	rp.m.Lock()
+	defer rp.m.Unlock()
+	return NewPlanFile(rp.plans)
+}
+
+// Plans returns all recorded plans.
+func (rp *RecordingPrinter) Plans() []*Plan {
+	rp.m.Lock()
+	defer rp.m.Unlock()
+	return slices.Clone(rp.plans)
+}
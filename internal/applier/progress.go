@@ -0,0 +1,273 @@
+package applier
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// alterProgress represents a single progress observation for an ALTER TABLE.
+// It is also the format of each line of JSON written to alter-progress-stream.
+type alterProgress struct {
+	Time       time.Time `json:"time"`
+	Target     string    `json:"target"`
+	Table      string    `json:"table"`
+	Stage      string    `json:"stage,omitempty"`
+	Percent    float64   `json:"percent"`
+	ETASeconds int64     `json:"eta_seconds"` // -1 if unknown
+	Done       bool      `json:"done"`
+	Success    bool      `json:"success,omitempty"` // only meaningful if Done is true
+}
+
+// progressReporter polls the database server for the progress of ALTER TABLE
+// statements executed directly by Skeema, and renders it as a progress bar on
+// STDERR and/or a stream of JSON lines.
+//
+// MySQL 5.7+ reports progress via performance_schema stage events; this
+// requires the stage/innodb/alter% instruments and the events_stages_current
+// consumer to be enabled. MariaDB reports progress via the PROGRESS column of
+// information_schema.processlist. If the server does not report any progress,
+// nothing is rendered.
+type progressReporter struct {
+	bar      bool
+	stream   *progressStream
+	interval time.Duration
+}
+
+// newProgressReporter returns a progressReporter configured using config, or
+// nil if progress reporting is not enabled.
+func newProgressReporter(config *mybase.Config) (*progressReporter, error) {
+	bar := config.GetBool("alter-progress")
+	streamPath := config.Get("alter-progress-stream")
+	if !bar && streamPath == "" {
+		return nil, nil
+	}
+	pr := &progressReporter{
+		bar:      bar,
+		interval: time.Second,
+	}
+	if streamPath != "" {
+		var err error
+		if pr.stream, err = getProgressStream(streamPath); err != nil {
+			return nil, fmt.Errorf("Unable to open alter-progress-stream: %w", err)
+		}
+	}
+	return pr, nil
+}
+
+// exec runs stmt on conn, which has connection ID connID, while polling for
+// its progress on a separate connection from db.
+func (pr *progressReporter) exec(ctx context.Context, ddl *DDLStatement, db *sqlx.DB, conn *sql.Conn, connID int64, stmt string) error {
+	tracker := &progressTracker{
+		reporter: pr,
+		start:    time.Now(),
+		base: alterProgress{
+			Target:     ddl.instance.String() + " " + ddl.schemaName,
+			Table:      ddl.diff.ObjectKey().Name,
+			ETASeconds: -1,
+		},
+	}
+	done := make(chan struct{})
+	pollerDone := make(chan struct{})
+	go func() {
+		defer close(pollerDone)
+		ticker := time.NewTicker(pr.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if stage, percent, ok := queryProgress(db, ddl.instance.Flavor(), connID); ok {
+					tracker.update(stage, percent)
+				}
+			}
+		}
+	}()
+	_, err := conn.ExecContext(ctx, stmt)
+	close(done)
+	<-pollerDone
+	tracker.finish(err == nil)
+	return err
+}
+
+// queryProgress returns the current stage and completion percentage of the
+// statement running on connection connID. The final return value is false if
+// the server did not report any progress.
+func queryProgress(db *sqlx.DB, flavor tengo.Flavor, connID int64) (stage string, percent float64, ok bool) {
+	var err error
+	if flavor.IsMariaDB() {
+		var result struct {
+			Stage    int     `db:"STAGE"`
+			MaxStage int     `db:"MAX_STAGE"`
+			Progress float64 `db:"PROGRESS"`
+		}
+		query := "SELECT STAGE, MAX_STAGE, PROGRESS FROM information_schema.processlist WHERE ID = ?"
+		if err = db.Get(&result, query, connID); err == nil && result.MaxStage > 0 {
+			return fmt.Sprintf("stage %d of %d", result.Stage, result.MaxStage), result.Progress, true
+		}
+	} else if flavor.MinMySQL(5, 7) {
+		var result struct {
+			EventName string  `db:"EVENT_NAME"`
+			Completed float64 `db:"WORK_COMPLETED"`
+			Estimated float64 `db:"WORK_ESTIMATED"`
+		}
+		query := `SELECT esc.EVENT_NAME, IFNULL(esc.WORK_COMPLETED, 0) AS WORK_COMPLETED, IFNULL(esc.WORK_ESTIMATED, 0) AS WORK_ESTIMATED
+		          FROM performance_schema.events_stages_current esc
+		          JOIN performance_schema.threads t ON t.THREAD_ID = esc.THREAD_ID
+		          WHERE t.PROCESSLIST_ID = ?`
+		if err = db.Get(&result, query, connID); err == nil && result.Estimated > 0 {
+			return strings.TrimPrefix(result.EventName, "stage/innodb/"), min(100*result.Completed/result.Estimated, 100), true
+		}
+	}
+	if err != nil {
+		log.Debugf("Unable to obtain ALTER TABLE progress: %s", err)
+	}
+	return "", 0, false
+}
+
+// progressTracker tracks the progress of a single ALTER TABLE.
+type progressTracker struct {
+	reporter    *progressReporter
+	start       time.Time
+	base        alterProgress
+	rendered    bool // true if a progress bar has been written to STDERR
+	lastDecile  int  // last 10% increment logged, when STDERR isn't a terminal
+	lastPercent float64
+}
+
+func (tracker *progressTracker) update(stage string, percent float64) {
+	p := tracker.base
+	p.Time = time.Now()
+	p.Stage = stage
+	p.Percent = percent
+	p.ETASeconds = estimateETA(p.Time.Sub(tracker.start), percent)
+	tracker.lastPercent = percent
+	tracker.emit(p)
+}
+
+func (tracker *progressTracker) finish(success bool) {
+	p := tracker.base
+	p.Time = time.Now()
+	p.Done = true
+	p.Success = success
+	if success {
+		p.Percent, p.ETASeconds = 100, 0
+	} else {
+		p.Percent = tracker.lastPercent
+	}
+	tracker.emit(p)
+	if tracker.rendered {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func (tracker *progressTracker) emit(p alterProgress) {
+	if tracker.reporter.stream != nil {
+		tracker.reporter.stream.write(p)
+	}
+	if !tracker.reporter.bar {
+		return
+	} else if util.StderrIsTerminal() {
+		width, _ := util.TerminalWidth(int(os.Stderr.Fd()))
+		fmt.Fprint(os.Stderr, "\r"+renderProgressBar(p, width))
+		tracker.rendered = true
+	} else if decile := int(p.Percent) / 10; !p.Done && decile > tracker.lastDecile {
+		tracker.lastDecile = decile
+		log.Infof("ALTER TABLE %s on %s: %.0f%% complete%s", tengo.EscapeIdentifier(p.Table), p.Target, p.Percent, formatETA(p.ETASeconds))
+	}
+}
+
+// estimateETA returns the estimated number of seconds remaining, based on the
+// elapsed time and completion percentage. If no estimate is possible yet, -1
+// is returned.
+func estimateETA(elapsed time.Duration, percent float64) int64 {
+	if percent <= 0 {
+		return -1
+	} else if percent >= 100 {
+		return 0
+	}
+	return int64(elapsed.Seconds() * (100 - percent) / percent)
+}
+
+// formatETA returns a human-readable suffix describing the supplied ETA, or a
+// blank string if the ETA is unknown.
+func formatETA(seconds int64) string {
+	if seconds < 0 {
+		return ""
+	}
+	return ", ETA " + (time.Duration(seconds) * time.Second).String()
+}
+
+// renderProgressBar returns a single-line progress bar for p, fitting within
+// the supplied terminal width if it is positive.
+func renderProgressBar(p alterProgress, width int) string {
+	const barWidth = 30
+	filled := int(p.Percent * barWidth / 100)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	if filled > 0 && filled < barWidth {
+		bar = bar[:filled-1] + ">" + bar[filled:]
+	}
+	line := fmt.Sprintf("%s [%s] %5.1f%%%s", tengo.EscapeIdentifier(p.Table), bar, p.Percent, formatETA(p.ETASeconds))
+	if p.Stage != "" && !p.Done {
+		line += " (" + p.Stage + ")"
+	}
+	if width > 0 && len(line) >= width {
+		line = line[:width-1]
+	} else if width > 0 {
+		line += strings.Repeat(" ", width-1-len(line)) // overwrite any longer previous line
+	}
+	return line
+}
+
+// progressStream writes alterProgress values as JSON lines. Since statements
+// may be executed concurrently for multiple instances, streams are shared
+// process-wide by path, and writes are serialized.
+type progressStream struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+var (
+	progressStreams     = make(map[string]*progressStream)
+	progressStreamsLock sync.Mutex
+)
+
+// getProgressStream returns the progressStream for path, opening it in append
+// mode if not already open. A path of "-" refers to STDOUT.
+func getProgressStream(path string) (*progressStream, error) {
+	progressStreamsLock.Lock()
+	defer progressStreamsLock.Unlock()
+	if ps, ok := progressStreams[path]; ok {
+		return ps, nil
+	}
+	ps := &progressStream{w: os.Stdout}
+	if path != "-" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+		if err != nil {
+			return nil, err
+		}
+		ps.w = f
+	}
+	progressStreams[path] = ps
+	return ps, nil
+}
+
+func (ps *progressStream) write(p alterProgress) {
+	b, _ := json.Marshal(p)
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.w.Write(append(b, '\n'))
+}
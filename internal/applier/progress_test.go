@@ -0,0 +1,73 @@
+package applier
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateETA(t *testing.T) {
+	cases := []struct {
+		elapsed  time.Duration
+		percent  float64
+		expected int64
+	}{
+		{10 * time.Second, 0, -1},
+		{10 * time.Second, 25, 30},
+		{90 * time.Second, 50, 90},
+		{90 * time.Second, 100, 0},
+	}
+	for _, c := range cases {
+		if actual := estimateETA(c.elapsed, c.percent); actual != c.expected {
+			t.Errorf("Expected estimateETA(%s, %v) to return %d, instead found %d", c.elapsed, c.percent, c.expected, actual)
+		}
+	}
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	p := alterProgress{Table: "users", Stage: "alter table (read PK and internal sort)", Percent: 50, ETASeconds: 83}
+	expected := "`users` [==============>               ]  50.0%, ETA 1m23s (alter table (read PK and internal sort))"
+	if actual := renderProgressBar(p, 0); actual != expected {
+		t.Errorf("Unexpected progress bar:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+	if actual := renderProgressBar(p, 40); len(actual) != 39 {
+		t.Errorf("Expected progress bar to be truncated to 39 chars, instead found %d: %q", len(actual), actual)
+	}
+
+	p = alterProgress{Table: "users", Percent: 100, Done: true, ETASeconds: 0}
+	expected = "`users` [==============================] 100.0%, ETA 0s"
+	if actual := renderProgressBar(p, 0); actual != expected {
+		t.Errorf("Unexpected progress bar:\nexpected: %q\nfound:    %q", expected, actual)
+	}
+}
+
+func TestProgressStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	ps, err := getProgressStream(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from getProgressStream: %v", err)
+	}
+	if ps2, _ := getProgressStream(path); ps2 != ps {
+		t.Error("Expected getProgressStream to return the same stream for the same path")
+	}
+	ps.write(alterProgress{Target: "localhost:3306 product", Table: "users", Percent: 42.5, ETASeconds: -1})
+	ps.write(alterProgress{Target: "localhost:3306 product", Table: "users", Percent: 100, Done: true, Success: true})
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reading progress stream: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines in progress stream, instead found %d: %s", len(lines), contents)
+	}
+	var p alterProgress
+	if err := json.Unmarshal([]byte(lines[1]), &p); err != nil {
+		t.Fatalf("Unexpected error unmarshaling progress line: %v", err)
+	} else if !p.Done || !p.Success || p.Percent != 100 || p.Table != "users" {
+		t.Errorf("Unexpected progress values: %+v", p)
+	}
+}
@@ -0,0 +1,80 @@
+package applier
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// protectionPolicy blocks destructive changes to tables matching the
+// protect-table option, and to columns matching the protect-column option.
+// Unlike other unsafe statements, these cannot be permitted by --allow-unsafe
+// or --safe-below-size.
+type protectionPolicy struct {
+	tables  *regexp.Regexp
+	columns *regexp.Regexp
+}
+
+// newProtectionPolicy returns a protectionPolicy based on config. If neither
+// protect-table nor protect-column are set, nil is returned.
+func newProtectionPolicy(config *mybase.Config) (*protectionPolicy, error) {
+	tables, err := config.GetRegexp("protect-table")
+	if err != nil {
+		return nil, err
+	}
+	columns, err := config.GetRegexp("protect-column")
+	if err != nil {
+		return nil, err
+	}
+	if tables == nil && columns == nil {
+		return nil, nil
+	}
+	return &protectionPolicy{tables: tables, columns: columns}, nil
+}
+
+// violation returns a description of why diff is not permitted, or an empty
+// string if diff does not destroy any protected data. Any unsafe change to a
+// protected table is a violation, as is dropping, renaming, or lossily
+// modifying a protected column in any table. It is nil-safe.
+func (pp *protectionPolicy) violation(diff tengo.ObjectDiff, mods tengo.StatementModifiers) string {
+	td, ok := diff.(*tengo.TableDiff)
+	if pp == nil || !ok {
+		return ""
+	}
+	tableName := td.ObjectKey().Name
+	protectedTable := pp.tables != nil && pp.tables.MatchString(tableName)
+	if td.DiffType() == tengo.DiffTypeDrop && protectedTable {
+		return fmt.Sprintf("Table %s matches protect-table, and cannot be dropped.", tengo.EscapeIdentifier(tableName))
+	} else if td.DiffType() != tengo.DiffTypeAlter {
+		return ""
+	}
+	mods.AllowUnsafe = false
+	for _, clause := range td.AlterClauses(mods) {
+		unsafer, ok := clause.(tengo.Unsafer)
+		if !ok {
+			continue
+		}
+		unsafe, reason := unsafer.Unsafe(mods)
+		if !unsafe {
+			continue
+		}
+		if protectedTable {
+			return fmt.Sprintf("Table %s matches protect-table, and cannot be modified destructively: %s.", tengo.EscapeIdentifier(tableName), reason)
+		}
+		var columnName string
+		switch clause := clause.(type) {
+		case tengo.DropColumn:
+			columnName = clause.Column.Name
+		case tengo.ModifyColumn:
+			columnName = clause.OldColumn.Name
+		case tengo.RenameColumn:
+			columnName = clause.OldColumn.Name
+		}
+		if columnName != "" && pp.columns != nil && pp.columns.MatchString(columnName) {
+			return fmt.Sprintf("Column %s of table %s matches protect-column, and cannot be modified destructively: %s.", tengo.EscapeIdentifier(columnName), tengo.EscapeIdentifier(tableName), reason)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestProtectionPolicy(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	ssnCol := &tengo.Column{Name: "ssn", Type: tengo.ParseColumnType("varchar(20)"), Nullable: true, Default: "NULL"}
+	pk := &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	makeTable := func(name string, cols ...*tengo.Column) *tengo.Table {
+		table := &tengo.Table{
+			Name:       name,
+			Engine:     "InnoDB",
+			CharSet:    "latin1",
+			Collation:  "latin1_swedish_ci",
+			Columns:    cols,
+			PrimaryKey: pk,
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	narrowSSNCol := *ssnCol
+	narrowSSNCol.Type = tengo.ParseColumnType("varchar(9)")
+
+	// Nil policy if neither option is set
+	pp, err := newProtectionPolicy(mybase.SimpleConfig(map[string]string{"protect-table": "", "protect-column": ""}))
+	if pp != nil || err != nil {
+		t.Errorf("Expected nil policy and nil error, instead found %+v, %v", pp, err)
+	}
+	if reason := pp.violation(tengo.NewDropTable(makeTable("audit_log", idCol)), tengo.StatementModifiers{}); reason != "" {
+		t.Errorf("Expected nil policy to permit everything, instead found %q", reason)
+	}
+
+	// Invalid regex is an error
+	if _, err := newProtectionPolicy(mybase.SimpleConfig(map[string]string{"protect-table": "(", "protect-column": ""})); err == nil {
+		t.Error("Expected error from invalid regex, but err was nil")
+	}
+
+	pp, err = newProtectionPolicy(mybase.SimpleConfig(map[string]string{"protect-table": "^audit_", "protect-column": "^ssn$"}))
+	if err != nil {
+		t.Fatalf("Unexpected error from newProtectionPolicy: %v", err)
+	}
+	cases := []struct {
+		diff            tengo.ObjectDiff
+		expectViolation bool
+	}{
+		{tengo.NewDropTable(makeTable("audit_log", idCol)), true},
+		{tengo.NewDropTable(makeTable("users", idCol)), false},
+		{tengo.NewCreateTable(makeTable("audit_log", idCol)), false},
+		{tengo.NewAlterTable(makeTable("audit_log", idCol, nameCol), makeTable("audit_log", idCol)), true},        // drop col in protected table
+		{tengo.NewAlterTable(makeTable("audit_log", idCol), makeTable("audit_log", idCol, nameCol)), false},       // add col in protected table
+		{tengo.NewAlterTable(makeTable("users", idCol, nameCol), makeTable("users", idCol)), false},               // drop unprotected col
+		{tengo.NewAlterTable(makeTable("users", idCol, ssnCol), makeTable("users", idCol)), true},                 // drop protected col
+		{tengo.NewAlterTable(makeTable("users", idCol, ssnCol), makeTable("users", idCol, &narrowSSNCol)), true},  // lossy change to protected col
+		{tengo.NewAlterTable(makeTable("users", idCol, &narrowSSNCol), makeTable("users", idCol, ssnCol)), false}, // widening protected col
+	}
+	for n, c := range cases {
+		// Violations must be detected regardless of allow-unsafe
+		mods := tengo.StatementModifiers{AllowUnsafe: true}
+		if reason := pp.violation(c.diff, mods); (reason != "") != c.expectViolation {
+			t.Errorf("Case %d: Unexpected return from violation: %q", n, reason)
+		}
+	}
+}
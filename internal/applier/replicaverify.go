@@ -0,0 +1,93 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// verifyReplicas compares the schema on each replica of t to desired, after a
+// push has completed. Replicas are polled until they all match or the
+// verify-replicas-timeout elapses, to allow for replication delay. Replicas
+// which still differ are logged, distinguishing ones which have not yet
+// applied the changes (still matching before) from ones which have drifted.
+// The number of mismatched replicas is returned.
+func verifyReplicas(t *Target, before, desired *tengo.Schema, mods tengo.StatementModifiers) (mismatchCount int, err error) {
+	timeout, err := time.ParseDuration(t.Dir.Config.Get("verify-replicas-timeout"))
+	if err != nil || timeout < 0 {
+		return 0, ConfigError("option verify-replicas-timeout must be a non-negative duration, such as \"30s\"")
+	}
+	replicas := t.Dir.Config.GetSlice("replicas", ',', true)
+	if len(replicas) == 1 && strings.EqualFold(replicas[0], "auto") {
+		if replicas, err = discoverReplicas(t.Instance); err != nil {
+			return 0, fmt.Errorf("Unable to discover replicas of %s: %w", t.Instance, err)
+		}
+	} else if len(replicas) == 0 {
+		return 0, ConfigError("option verify-replicas requires the replicas option to also be set")
+	}
+
+	mods.AllowUnsafe = true
+	total := len(replicas)
+	pending := make(map[string][]tengo.ObjectKey) // replica addr -> keys of differing objects
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, replica := range replicas {
+			keys, err := replicaDiffKeys(t, replica, desired, mods)
+			if err != nil {
+				return 0, fmt.Errorf("Unable to verify schema on replica %s: %w", replica, err)
+			} else if len(keys) > 0 {
+				pending[replica] = keys
+			} else {
+				delete(pending, replica)
+			}
+		}
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		replicas = replicas[:0]
+		for replica := range pending {
+			replicas = append(replicas, replica)
+		}
+		time.Sleep(time.Second)
+	}
+
+	for replica := range pending {
+		if beforeKeys, _ := replicaDiffKeys(t, replica, before, mods); len(beforeKeys) == 0 {
+			log.Errorf("%s: replica %s has not applied the pushed changes after %s", t, replica, timeout)
+		} else {
+			keyStrings := make([]string, len(pending[replica]))
+			for n, key := range pending[replica] {
+				keyStrings[n] = key.String()
+			}
+			log.Errorf("%s: replica %s has drifted from the desired schema; differences found in %s", t, replica, strings.Join(keyStrings, ", "))
+		}
+	}
+	if len(pending) == 0 {
+		log.Infof("%s: verified schema on %s", t, countAndNoun(total, "replica"))
+	}
+	return len(pending), nil
+}
+
+// replicaDiffKeys returns the keys of objects which differ between expected and
+// the schema on replica, which should be a host[:port] string.
+func replicaDiffKeys(t *Target, replica string, expected *tengo.Schema, mods tengo.StatementModifiers) ([]tengo.ObjectKey, error) {
+	replicaInst, err := instanceForAddress(t.Instance, replica)
+	if err != nil {
+		return nil, err
+	}
+	replicaTarget := &Target{Instance: replicaInst, Dir: t.Dir, SchemaName: t.SchemaName}
+	actual, err := replicaTarget.SchemaFromInstance()
+	if err != nil {
+		return nil, err
+	}
+	var keys []tengo.ObjectKey
+	for _, objDiff := range tengo.NewSchemaDiff(actual, expected).ObjectDiffs() {
+		if stmt, _ := objDiff.Statement(mods); stmt != "" {
+			keys = append(keys, objDiff.ObjectKey())
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,65 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func (s ApplierIntegrationSuite) TestVerifyReplicas(t *testing.T) {
+	setupHostList(t, s.d[0].Instance)
+
+	// Treat s.d[1] as a replica of s.d[0]. Use a timeout of 0 so that mismatches
+	// are reported immediately.
+	dir := getDir(t, "testdata/simple", "--replicas="+s.d[1].Instance.String()+" --verify-replicas-timeout=0")
+	targets, skipCount := TargetsForDir(dir, 1)
+	if len(targets) != 2 || skipCount != 0 {
+		t.Fatalf("Unexpected result from TargetsForDir: %+v, %d", targets, skipCount)
+	}
+	var target *Target
+	for _, tgt := range targets {
+		if tgt.SchemaName == "one" {
+			target = tgt
+		}
+	}
+	if target == nil {
+		t.Fatalf("Unable to find target for schema one in %+v", targets)
+	}
+	desired := target.SchemaFromDir()
+	var mods tengo.StatementModifiers
+
+	// Replica does not have the schema at all yet
+	if count, err := verifyReplicas(target, nil, desired, mods); count != 1 || err != nil {
+		t.Errorf("Unexpected return from verifyReplicas: %d, %v", count, err)
+	}
+
+	// Replica matches after creating the schema and table there
+	if _, err := s.d[1].CreateSchema("one", tengo.SchemaCreationOptions{}); err != nil {
+		t.Fatalf("Unable to create schema: %v", err)
+	}
+	db, err := s.d[1].CachedConnectionPool("one", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	if _, err := db.Exec(desired.Tables[0].CreateStatement); err != nil {
+		t.Fatalf("Unable to create table: %v", err)
+	}
+	if count, err := verifyReplicas(target, nil, desired, mods); count != 0 || err != nil {
+		t.Errorf("Unexpected return from verifyReplicas: %d, %v", count, err)
+	}
+
+	// Replica has drifted from the desired schema
+	if _, err := db.Exec("CREATE TABLE drifted (id int unsigned NOT NULL PRIMARY KEY)"); err != nil {
+		t.Fatalf("Unable to create table: %v", err)
+	}
+	if count, err := verifyReplicas(target, nil, desired, mods); count != 1 || err != nil {
+		t.Errorf("Unexpected return from verifyReplicas: %d, %v", count, err)
+	}
+
+	// Omitting replicas is a config error
+	dir = getDir(t, "testdata/simple", "")
+	target.Dir = dir
+	if _, err := verifyReplicas(target, nil, desired, mods); err == nil {
+		t.Error("Expected error from verifyReplicas without replicas configured, but err was nil")
+	}
+}
@@ -0,0 +1,23 @@
+package applier
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// ResourceID returns a stable identifier for a schema or object on a database
+// instance, suitable for use as the ID of a resource managed by an
+// infrastructure-as-code tool, such as a Terraform provider. The format is
+// "instance/schema" for a schema itself, or "instance/schema/type/name" for an
+// object within a schema, for example "db1.example.com:3306/product/table/users".
+// Each component is escaped as a URL path segment, so that IDs may be split on
+// "/" unambiguously.
+func ResourceID(instance, schemaName string, key tengo.ObjectKey) string {
+	parts := []string{url.PathEscape(instance), url.PathEscape(schemaName)}
+	if key.Type != "" && key.Type != tengo.ObjectTypeDatabase {
+		parts = append(parts, url.PathEscape(string(key.Type)), url.PathEscape(key.Name))
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,62 @@
+package applier
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// maxRetryBackoff is the upper limit on the wait between retries, regardless
+// of how many retries have occurred.
+const maxRetryBackoff = 5 * time.Minute
+
+// retryPolicy controls re-execution of statements which fail due to transient
+// lock errors: lock wait timeouts (including metadata lock waits), deadlocks,
+// and aborted metadata lock waits. The wait between attempts begins at backoff
+// and doubles after each retry.
+type retryPolicy struct {
+	attempts int // max number of retries after the initial failure; 0 disables retries
+	backoff  time.Duration
+}
+
+// newRetryPolicy returns a retryPolicy configured using the retry-attempts and
+// retry-backoff options.
+func newRetryPolicy(config *mybase.Config) (*retryPolicy, error) {
+	attempts, err := config.GetInt("retry-attempts")
+	if err != nil || attempts < 0 {
+		return nil, fmt.Errorf("option retry-attempts must be a non-negative integer")
+	}
+	backoff, err := time.ParseDuration(config.Get("retry-backoff"))
+	if err != nil || backoff < 0 {
+		return nil, fmt.Errorf("option retry-backoff must be a non-negative duration, such as \"2s\"")
+	}
+	return &retryPolicy{
+		attempts: attempts,
+		backoff:  backoff,
+	}, nil
+}
+
+// run calls exec, and then calls it again if it returned a transient lock
+// error, up to the policy's maximum number of retries. The error from the final
+// call is returned. It is safe to call on a nil receiver, in which case exec is
+// only called once.
+func (rp *retryPolicy) run(t *Target, stmt PlannedStatement, exec func() error) error {
+	err := exec()
+	if rp == nil {
+		return err
+	}
+	wait := rp.backoff
+	for retry := 1; retry <= rp.attempts && tengo.IsTransientLockError(err); retry++ {
+		log.Warnf("Statement on %s failed with a transient error; retrying in %s (retry %d of %d): %s\nFull SQL statement: %s%s",
+			t, wait, retry, rp.attempts, err, stmt.Statement(), stmt.ClientState().Delimiter)
+		time.Sleep(wait)
+		wait = min(wait*2, maxRetryBackoff)
+		if err = exec(); err == nil {
+			log.Infof("Statement on %s succeeded after %s", t, countAndNoun(retry, "retry", "retries"))
+		}
+	}
+	return err
+}
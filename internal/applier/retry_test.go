@@ -0,0 +1,83 @@
+package applier
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	cases := []struct {
+		attempts  string
+		backoff   string
+		expectErr bool
+	}{
+		{"0", "1s", false},
+		{"3", "250ms", false},
+		{"3", "0", false},
+		{"-1", "1s", true},
+		{"x", "1s", true},
+		{"3", "1", true},
+		{"3", "-1s", true},
+	}
+	for n, c := range cases {
+		cfg := mybase.SimpleConfig(map[string]string{
+			"retry-attempts": c.attempts,
+			"retry-backoff":  c.backoff,
+		})
+		if _, err := newRetryPolicy(cfg); (err != nil) != c.expectErr {
+			t.Errorf("Case %d: Unexpected error return from newRetryPolicy: %v", n, err)
+		}
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	target := &Target{Dir: &fs.Dir{Path: "/var/tmp/fakedir"}, SchemaName: "product"}
+	stmt := &fakeStatement{stmt: "ALTER TABLE foo ADD COLUMN bar int"}
+	lockErr := &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"}
+
+	// execFailing returns an exec func which fails with err for the first
+	// failures calls, and then succeeds
+	var calls int
+	execFailing := func(failures int, err error) func() error {
+		calls = 0
+		return func() error {
+			calls++
+			if calls <= failures {
+				return err
+			}
+			return nil
+		}
+	}
+
+	rp := &retryPolicy{attempts: 3}
+	if err := rp.run(target, stmt, execFailing(2, lockErr)); err != nil || calls != 3 {
+		t.Errorf("Expected success after 3 calls; instead found err=%v after %d calls", err, calls)
+	}
+	if err := rp.run(target, stmt, execFailing(5, lockErr)); err != lockErr || calls != 4 {
+		t.Errorf("Expected lock error after 4 calls; instead found err=%v after %d calls", err, calls)
+	}
+	deadlockErr := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}
+	if err := rp.run(target, stmt, execFailing(1, deadlockErr)); err != nil || calls != 2 {
+		t.Errorf("Expected success after 2 calls; instead found err=%v after %d calls", err, calls)
+	}
+
+	// Non-transient errors should not be retried
+	otherErr := errors.New("some other error")
+	if err := rp.run(target, stmt, execFailing(1, otherErr)); err != otherErr || calls != 1 {
+		t.Errorf("Expected non-transient error after 1 call; instead found err=%v after %d calls", err, calls)
+	}
+
+	// Retries are disabled with 0 attempts or a nil policy
+	rp.attempts = 0
+	if err := rp.run(target, stmt, execFailing(1, lockErr)); err != lockErr || calls != 1 {
+		t.Errorf("Expected lock error after 1 call; instead found err=%v after %d calls", err, calls)
+	}
+	rp = nil
+	if err := rp.run(target, stmt, execFailing(1, lockErr)); err != lockErr || calls != 1 {
+		t.Errorf("Expected lock error after 1 call; instead found err=%v after %d calls", err, calls)
+	}
+}
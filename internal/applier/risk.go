@@ -0,0 +1,159 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// Impact classifies the operational impact of executing a DDL statement,
+// taking into account the database flavor's capabilities, how the statement
+// will be executed, and optionally the table's size.
+type Impact string
+
+// Constants enumerating impact classes, in ascending order of severity
+const (
+	ImpactMetadataOnly    Impact = "metadata-only"    // only changes metadata, e.g. ALGORITHM=INSTANT
+	ImpactOnlineCapable   Impact = "online-capable"   // permits concurrent DML without rebuilding the table
+	ImpactTableRebuild    Impact = "table-rebuild"    // rebuilds the table, but permits concurrent DML
+	ImpactLockHeavy       Impact = "lock-heavy"       // blocks concurrent writes for the duration
+	ImpactDataDestructive Impact = "data-destructive" // potentially destroys data
+)
+
+var impactLevels = []Impact{ImpactMetadataOnly, ImpactOnlineCapable, ImpactTableRebuild, ImpactLockHeavy, ImpactDataDestructive}
+
+// level returns the severity of the impact, for comparison purposes.
+func (i Impact) level() int {
+	for n, impact := range impactLevels {
+		if i == impact {
+			return n
+		}
+	}
+	return len(impactLevels)
+}
+
+// maxImpactForConfig returns the value of the max-impact option, or a blank
+// string if no limit has been configured.
+func maxImpactForConfig(config *mybase.Config) (Impact, error) {
+	value := strings.ToLower(config.Get("max-impact"))
+	if value == "" {
+		return "", nil
+	}
+	for _, impact := range impactLevels {
+		if value == string(impact) {
+			return impact, nil
+		}
+	}
+	return "", fmt.Errorf("option max-impact has been configured to an invalid value %q; valid values are %s", value, joinImpacts(impactLevels))
+}
+
+func joinImpacts(impacts []Impact) string {
+	values := make([]string, len(impacts))
+	for n, impact := range impacts {
+		values[n] = string(impact)
+	}
+	return strings.Join(values, ", ")
+}
+
+// Impact returns the operational impact class of the DDL statement. This is
+// the single classification used by max-impact, explain output, and all
+// reports. Dropping any object is considered data-destructive. ALTER
+// TABLEs are classified based on their most severe clause. Rebuilds executed
+// by an external command or an online schema change tool are considered
+// online-capable, whereas rebuilds of tables at least as large as
+// the large-table-size option are considered lock-heavy.
+func (ddl *DDLStatement) Impact() Impact {
+	if ddl.diff.DiffType() == tengo.DiffTypeDrop {
+		return ImpactDataDestructive
+	}
+	td, ok := ddl.diff.(*tengo.TableDiff)
+	if !ok || td.DiffType() != tengo.DiffTypeAlter {
+		return ImpactMetadataOnly
+	}
+	impact := ImpactMetadataOnly
+	mods := ddl.mods
+	mods.AllowUnsafe = false
+	for _, clause := range td.AlterClauses(mods) {
+		if clauseImpact := alterClauseImpact(clause, td.From, mods); clauseImpact.level() > impact.level() {
+			impact = clauseImpact
+		}
+	}
+	if impact == ImpactTableRebuild || impact == ImpactLockHeavy {
+		if ddl.shellOut != nil || ddl.osc != nil {
+			return ImpactOnlineCapable
+		} else if ddl.largeTable {
+			return ImpactLockHeavy
+		}
+	}
+	return impact
+}
+
+// alterClauseImpact returns the impact class of a single ALTER TABLE clause
+// applied to table, based on the online DDL capabilities of mods.Flavor.
+func alterClauseImpact(clause tengo.TableAlterClause, table *tengo.Table, mods tengo.StatementModifiers) Impact {
+	if unsafer, ok := clause.(tengo.Unsafer); ok {
+		if unsafe, _ := unsafer.Unsafe(mods); unsafe {
+			return ImpactDataDestructive
+		}
+	}
+	switch clause := clause.(type) {
+	case tengo.AddColumn:
+		if instantAddColumn(clause, table, mods.Flavor) {
+			return ImpactMetadataOnly
+		}
+		return ImpactTableRebuild
+	case tengo.ModifyColumn:
+		if clause.VisibilityOnly(mods) && !clause.PositionFirst && clause.PositionAfter == nil {
+			return ImpactMetadataOnly
+		} else if clause.OldColumn.Type.String() != clause.NewColumn.Type.String() {
+			return ImpactLockHeavy // changing a column's type requires ALGORITHM=COPY
+		}
+		return ImpactTableRebuild
+	case tengo.AddIndex:
+		if clause.Index.Type == "FULLTEXT" || clause.Index.Type == "SPATIAL" {
+			return ImpactLockHeavy
+		}
+		return ImpactOnlineCapable
+	case tengo.ModifyIndex, tengo.AddForeignKey, tengo.ModifyPartitions:
+		return ImpactOnlineCapable
+	case tengo.RenameColumn, tengo.DropIndex, tengo.AlterIndex, tengo.DropForeignKey, tengo.DropCheck,
+		tengo.ChangeAutoIncrement, tengo.ChangeComment, tengo.ChangeCharSet:
+		return ImpactMetadataOnly
+	case tengo.AddCheck, tengo.AlterCheck, tengo.ChangeStorageEngine, tengo.PartitionBy, tengo.RemovePartitioning:
+		return ImpactLockHeavy
+	}
+	return ImpactTableRebuild
+}
+
+// instantAddColumn returns true if clause can be executed using
+// ALGORITHM=INSTANT on table in flavor.
+func instantAddColumn(clause tengo.AddColumn, table *tengo.Table, flavor tengo.Flavor) bool {
+	// Adding a column anywhere other than last requires MySQL 8.0.29+ or
+	// MariaDB 10.4+; adding it last requires MySQL 8.0.12+ or MariaDB 10.3+
+	if clause.PositionFirst || clause.PositionAfter != nil {
+		if !flavor.MinMySQL(8, 0, 29) && !flavor.MinMariaDB(10, 4) {
+			return false
+		}
+	} else if !flavor.MinMySQL(8, 0, 12) && !flavor.MinMariaDB(10, 3) {
+		return false
+	}
+	// Stored generated columns must be computed for every existing row
+	if clause.Column.GenerationExpr != "" && !clause.Column.Virtual {
+		return false
+	}
+	// Instant ADD COLUMN is not supported on compressed tables or tables with
+	// FULLTEXT indexes
+	if table == nil {
+		return true
+	} else if strings.Contains(table.CreateOptions, "ROW_FORMAT=COMPRESSED") || strings.Contains(table.CreateOptions, "KEY_BLOCK_SIZE=") {
+		return false
+	}
+	for _, idx := range table.SecondaryIndexes {
+		if idx.Type == "FULLTEXT" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestDDLStatementImpact(t *testing.T) {
+	idCol := &tengo.Column{Name: "id", Type: tengo.ParseColumnType("int unsigned")}
+	nameCol := &tengo.Column{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true, Default: "NULL"}
+	pk := &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	makeTable := func(cols []*tengo.Column, secondaryIndexes ...*tengo.Index) *tengo.Table {
+		table := &tengo.Table{
+			Name:             "users",
+			Engine:           "InnoDB",
+			CharSet:          "latin1",
+			Collation:        "latin1_swedish_ci",
+			Columns:          cols,
+			PrimaryKey:       pk,
+			SecondaryIndexes: secondaryIndexes,
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	widerNameCol := *nameCol
+	widerNameCol.Type = tengo.ParseColumnType("varchar(60)")
+	nameIdx := &tengo.Index{Name: "name", Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "name"}}}
+	ftIdx := &tengo.Index{Name: "ft_name", Type: "FULLTEXT", Parts: []tengo.IndexPart{{ColumnName: "name"}}}
+	from := makeTable([]*tengo.Column{idCol})
+	withName := makeTable([]*tengo.Column{idCol, nameCol})
+	withNameFirst := makeTable([]*tengo.Column{nameCol, idCol})
+	storedCol := &tengo.Column{Name: "doubled", Type: tengo.ParseColumnType("int unsigned"), Nullable: true, GenerationExpr: "(`id` * 2)"}
+	virtualCol := *storedCol
+	virtualCol.Virtual = true
+	withFT, withNameAndFT := makeTable([]*tengo.Column{idCol, nameCol}, ftIdx), makeTable([]*tengo.Column{idCol, nameCol, &virtualCol}, ftIdx)
+	compressed, compressedWithName := makeTable([]*tengo.Column{idCol}), makeTable([]*tengo.Column{idCol, nameCol})
+	compressed.CreateOptions, compressedWithName.CreateOptions = "ROW_FORMAT=COMPRESSED", "ROW_FORMAT=COMPRESSED"
+
+	mysql57 := tengo.StatementModifiers{Flavor: tengo.ParseFlavor("mysql:5.7")}
+	mysql80 := tengo.StatementModifiers{Flavor: tengo.ParseFlavor("mysql:8.0.30")}
+	mysql8020 := tengo.StatementModifiers{Flavor: tengo.ParseFlavor("mysql:8.0.20")}
+	mariadb103 := tengo.StatementModifiers{Flavor: tengo.ParseFlavor("mariadb:10.3")}
+	external := shellout.New("echo")
+	cases := []struct {
+		ddl      *DDLStatement
+		expected Impact
+	}{
+		{&DDLStatement{diff: tengo.NewCreateTable(from)}, ImpactMetadataOnly},
+		{&DDLStatement{diff: tengo.NewDropTable(from)}, ImpactDataDestructive},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql80}, ImpactMetadataOnly},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql57}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql57, shellOut: external}, ImpactOnlineCapable},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql57, osc: &builtinOSC{}}, ImpactOnlineCapable},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql8020}, ImpactMetadataOnly},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withNameFirst), mods: mysql8020}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withNameFirst), mods: mysql80}, ImpactMetadataOnly},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withNameFirst), mods: mariadb103}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, makeTable([]*tengo.Column{idCol, storedCol})), mods: mysql80}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, makeTable([]*tengo.Column{idCol, &virtualCol})), mods: mysql80}, ImpactMetadataOnly},
+		{&DDLStatement{diff: tengo.NewAlterTable(withFT, withNameAndFT), mods: mysql80}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(compressed, compressedWithName), mods: mysql80}, ImpactTableRebuild},
+		{&DDLStatement{diff: tengo.NewAlterTable(from, withName), mods: mysql57, largeTable: true}, ImpactLockHeavy},
+		{&DDLStatement{diff: tengo.NewAlterTable(withName, from), mods: mysql80}, ImpactDataDestructive},
+		{&DDLStatement{diff: tengo.NewAlterTable(withName, makeTable([]*tengo.Column{idCol, nameCol}, nameIdx)), mods: mysql80}, ImpactOnlineCapable},
+		{&DDLStatement{diff: tengo.NewAlterTable(withName, makeTable([]*tengo.Column{idCol, nameCol}, ftIdx)), mods: mysql80}, ImpactLockHeavy},
+		{&DDLStatement{diff: tengo.NewAlterTable(withName, makeTable([]*tengo.Column{idCol, &widerNameCol})), mods: mysql80}, ImpactLockHeavy},
+	}
+	for n, c := range cases {
+		if actual := c.ddl.Impact(); actual != c.expected {
+			t.Errorf("Case %d: Expected impact %s, instead found %s", n, c.expected, actual)
+		}
+	}
+}
+
+func TestMaxImpactForConfig(t *testing.T) {
+	cases := map[string]Impact{
+		"":              "",
+		"table-rebuild": ImpactTableRebuild,
+		"Lock-Heavy":    ImpactLockHeavy,
+	}
+	for value, expected := range cases {
+		cfg := mybase.SimpleConfig(map[string]string{"max-impact": value})
+		if actual, err := maxImpactForConfig(cfg); actual != expected || err != nil {
+			t.Errorf("Unexpected return from maxImpactForConfig with value %q: %q, %v", value, actual, err)
+		}
+	}
+	cfg := mybase.SimpleConfig(map[string]string{"max-impact": "scary"})
+	if _, err := maxImpactForConfig(cfg); err == nil {
+		t.Error("Expected error from maxImpactForConfig with invalid value, but err was nil")
+	}
+}
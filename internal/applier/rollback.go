@@ -0,0 +1,96 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// RollbackStatement is a statement which reverses one object's changes in a
+// Plan. Some changes cannot be fully reversed: for example, re-creating a
+// dropped table or column restores its definition but not its data. In this
+// situation, Irreversible describes what is lost.
+type RollbackStatement struct {
+	Key          tengo.ObjectKey
+	Statement    string
+	Compound     bool
+	Irreversible string
+}
+
+// rollbackStatements returns statements which would revert the objects in keys
+// from their desired state back to their state on the target, as described by
+// the supplied forward diff. Irreversibility is determined by whether the
+// forward statement is considered unsafe, ignoring any --allow-unsafe setting.
+func rollbackStatements(forward *tengo.SchemaDiff, keys []tengo.ObjectKey, mods tengo.StatementModifiers) []RollbackStatement {
+	reverseMods, safeMods := mods, mods
+	reverseMods.AllowUnsafe = true
+	safeMods.AllowUnsafe = false
+	irreversible := make(map[tengo.ObjectKey]string)
+	for _, objDiff := range forward.ObjectDiffs() {
+		if _, err := objDiff.Statement(safeMods); tengo.IsUnsafeDiff(err) {
+			irreversible[objDiff.ObjectKey()] = err.Error()
+		}
+	}
+
+	var result []RollbackStatement
+	reverse := tengo.NewSchemaDiff(forward.ToSchema, forward.FromSchema)
+	for _, objDiff := range reverse.ObjectDiffs() {
+		key := objDiff.ObjectKey()
+		if !slices.Contains(keys, key) {
+			continue
+		}
+		stmt, err := objDiff.Statement(reverseMods)
+		if stmt == "" || err != nil {
+			log.Debugf("Unable to generate rollback statement for %s: %v", key, err)
+			continue
+		}
+		rs := RollbackStatement{
+			Key:          key,
+			Statement:    stmt,
+			Irreversible: irreversible[key],
+		}
+		if compounder, ok := objDiff.(tengo.Compounder); ok && compounder.IsCompoundStatement() {
+			rs.Compound = true
+		}
+		result = append(result, rs)
+	}
+	return result
+}
+
+// WriteRollbackScript writes a SQL script to path containing the rollback
+// statements of each of the supplied plans. A warning is logged for each
+// irreversible operation, and it is also noted in a comment in the script.
+func WriteRollbackScript(path string, plans []*Plan) error {
+	plans = slices.Clone(plans)
+	slices.SortFunc(plans, func(a, b *Plan) int {
+		if a.Target.Instance.String() != b.Target.Instance.String() {
+			return strings.Compare(a.Target.Instance.String(), b.Target.Instance.String())
+		}
+		return strings.Compare(a.Target.SchemaName, b.Target.SchemaName)
+	})
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Rollback script generated by Skeema at %s\n", time.Now().UTC().Format("2006-01-02 15:04:05 MST"))
+	for _, plan := range plans {
+		if len(plan.Rollback) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n-- instance: %s\nUSE %s;\n", plan.Target.Instance, tengo.EscapeIdentifier(plan.Target.SchemaName))
+		for _, rs := range plan.Rollback {
+			if rs.Irreversible != "" {
+				log.Warnf("%s: rollback of %s is irreversible; its definition can be restored, but not its data. %s", plan.Target, rs.Key, rs.Irreversible)
+				fmt.Fprintf(&b, "-- IRREVERSIBLE: data will not be restored. %s\n", rs.Irreversible)
+			}
+			if rs.Compound {
+				fmt.Fprintf(&b, "DELIMITER //\n%s//\nDELIMITER ;\n", rs.Statement)
+			} else {
+				fmt.Fprintf(&b, "%s;\n", rs.Statement)
+			}
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0666)
+}
@@ -0,0 +1,76 @@
+package applier
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestRollbackStatements(t *testing.T) {
+	makeTable := func(name string, colNames ...string) *tengo.Table {
+		table := &tengo.Table{Name: name, Engine: "InnoDB", CharSet: "latin1", Collation: "latin1_swedish_ci"}
+		for _, colName := range colNames {
+			table.Columns = append(table.Columns, &tengo.Column{Name: colName, Type: tengo.ParseColumnType("int"), Nullable: true})
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	from := &tengo.Schema{Name: "product", CharSet: "latin1", Collation: "latin1_swedish_ci", Tables: []*tengo.Table{
+		makeTable("users", "id", "name"),
+		makeTable("widgets", "id"),
+	}}
+	to := &tengo.Schema{Name: "product", CharSet: "latin1", Collation: "latin1_swedish_ci", Tables: []*tengo.Table{
+		makeTable("users", "id"),
+		makeTable("gadgets", "id"),
+		makeTable("widgets", "id"),
+	}}
+	diff := tengo.NewSchemaDiff(from, to)
+	var keys []tengo.ObjectKey
+	for _, objDiff := range diff.ObjectDiffs() {
+		keys = append(keys, objDiff.ObjectKey())
+	}
+	rollback := rollbackStatements(diff, keys, tengo.StatementModifiers{AllowUnsafe: true})
+	if len(rollback) != 2 {
+		t.Fatalf("Expected 2 rollback statements, instead found %d: %+v", len(rollback), rollback)
+	}
+	for _, rs := range rollback {
+		switch rs.Key.Name {
+		case "users":
+			if !strings.Contains(rs.Statement, "ADD COLUMN `name`") || rs.Irreversible == "" {
+				t.Errorf("Unexpected rollback statement for users: %+v", rs)
+			}
+		case "gadgets":
+			if !strings.HasPrefix(rs.Statement, "DROP TABLE") || rs.Irreversible != "" {
+				t.Errorf("Unexpected rollback statement for gadgets: %+v", rs)
+			}
+		default:
+			t.Errorf("Unexpected rollback statement for %s: %+v", rs.Key, rs)
+		}
+	}
+
+	// Objects not in keys should be excluded
+	if rollback := rollbackStatements(diff, keys[:0], tengo.StatementModifiers{}); len(rollback) != 0 {
+		t.Errorf("Expected no rollback statements, instead found %+v", rollback)
+	}
+
+	// Confirm script output
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	plan := &Plan{
+		Target:   &Target{Instance: inst, Dir: &fs.Dir{Path: "/var/tmp/fakedir"}, SchemaName: "product"},
+		Rollback: rollback,
+	}
+	path := filepath.Join(t.TempDir(), "down.sql")
+	if err := WriteRollbackScript(path, []*Plan{plan}); err != nil {
+		t.Fatalf("Unexpected error from WriteRollbackScript: %v", err)
+	}
+	contents := fs.ReadTestFile(t, path)
+	if !strings.Contains(contents, "USE `product`;\n") || strings.Count(contents, "-- IRREVERSIBLE") != 1 || !strings.Contains(contents, "DROP TABLE `gadgets`;\n") {
+		t.Errorf("Unexpected rollback script contents:\n%s", contents)
+	}
+}
@@ -0,0 +1,203 @@
+package applier
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+// ConcurrencyLimits controls how many Targets may be applied at once. A limit
+// of 0 means unlimited.
+type ConcurrencyLimits struct {
+	Instances   int // max instances with in-progress targets
+	PerInstance int // max concurrent targets on a single instance
+	PerCluster  int // max concurrent targets within a cluster, as named by the cluster option
+	Total       int // max concurrent targets overall
+}
+
+// ConcurrencyLimitsForDir returns ConcurrencyLimits based on the directory's
+// configuration.
+func ConcurrencyLimitsForDir(dir *fs.Dir) (limits ConcurrencyLimits, err error) {
+	options := []struct {
+		name  string
+		dest  *int
+		floor int
+	}{
+		{"concurrent-instances", &limits.Instances, 1},
+		{"concurrent-per-instance", &limits.PerInstance, 1},
+		{"concurrent-per-cluster", &limits.PerCluster, 0},
+		{"concurrent-total", &limits.Total, 0},
+	}
+	for _, opt := range options {
+		if *opt.dest, err = dir.Config.GetInt(opt.name); err != nil {
+			return limits, ConfigError(err.Error())
+		} else if *opt.dest < opt.floor {
+			return limits, ConfigError(fmt.Sprintf("%s cannot be less than %d", opt.name, opt.floor))
+		}
+	}
+	return limits, nil
+}
+
+// Scheduler is a work queue which applies Targets concurrently, subject to
+// ConcurrencyLimits. Targets on the same instance are started in their original
+// order. Once any target on an instance has been started, the instance remains
+// in progress until all of its targets are complete, so that the Instances
+// limit behaves like a limit on concurrent database servers.
+type Scheduler struct {
+	limits           ConcurrencyLimits
+	queue            []*Target
+	running          int
+	runningByInst    map[string]int
+	runningByCluster map[string]int
+	remainingByInst  map[string]int // queued + running, only for instances which have been started
+	unstarted        []*Target      // targets removed from the queue by Stop
+	m                sync.Mutex
+	cond             *sync.Cond
+}
+
+// NewScheduler returns a Scheduler which enforces limits.
+func NewScheduler(limits ConcurrencyLimits) *Scheduler {
+	s := &Scheduler{
+		limits:           limits,
+		runningByInst:    make(map[string]int),
+		runningByCluster: make(map[string]int),
+		remainingByInst:  make(map[string]int),
+	}
+	s.cond = sync.NewCond(&s.m)
+	return s
+}
+
+// Run calls apply for every Target in groups, and blocks until all calls have
+// completed. If apply returns an error, it is treated as fatal: no further
+// queued Targets are started, although Targets which are already running are
+// permitted to complete. The first such error is returned, and any subsequent
+// ones are logged. Failures which should be isolated to a single Target should
+// be tracked by apply without returning an error.
+func (s *Scheduler) Run(groups []TargetGroup, apply func(*Target) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, tg := range groups {
+		s.queue = append(s.queue, tg...)
+	}
+	var firstErr error
+	for len(s.queue) > 0 {
+		n := s.nextRunnable()
+		if n < 0 {
+			s.cond.Wait()
+			continue
+		}
+		t := s.queue[n]
+		s.queue = append(s.queue[:n], s.queue[n+1:]...)
+		s.start(t)
+		log.Debugf("Starting %s (%d running, %d queued)", t, s.running, len(s.queue))
+		go func() {
+			err := apply(t)
+			s.m.Lock()
+			defer s.m.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+				s.stop()
+			} else if err != nil {
+				log.Errorf("%s: %s", t, err)
+			}
+			s.finish(t)
+			s.cond.Broadcast()
+		}()
+	}
+	for s.running > 0 {
+		s.cond.Wait()
+	}
+	return firstErr
+}
+
+// Stop prevents any further queued Targets from being started by Run. Targets
+// which are already running are unaffected, and Run still waits for them to
+// complete. Stop may be called from within the apply function passed to Run.
+func (s *Scheduler) Stop() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.stop()
+}
+
+// stop implements Stop. The caller must hold the lock.
+func (s *Scheduler) stop() {
+	s.unstarted = append(s.unstarted, s.queue...)
+	s.queue = nil
+	s.cond.Broadcast()
+}
+
+// Unstarted returns the Targets which were never started due to a call to
+// Stop or a fatal error. It should only be called after Run has returned.
+func (s *Scheduler) Unstarted() []*Target {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.unstarted
+}
+
+// nextRunnable returns the index of the first queued Target which may be
+// started without exceeding any limit, or -1 if none. The caller must hold the
+// lock.
+func (s *Scheduler) nextRunnable() int {
+	if s.limits.Total > 0 && s.running >= s.limits.Total {
+		return -1
+	}
+	seenInst := make(map[string]bool)
+	for n, t := range s.queue {
+		inst := t.Instance.String()
+		if seenInst[inst] {
+			continue // only the first queued target of each instance may start
+		}
+		seenInst[inst] = true
+		if _, started := s.remainingByInst[inst]; !started && s.limits.Instances > 0 && len(s.remainingByInst) >= s.limits.Instances {
+			continue
+		}
+		if s.limits.PerInstance > 0 && s.runningByInst[inst] >= s.limits.PerInstance {
+			continue
+		}
+		if s.limits.PerCluster > 0 && s.runningByCluster[targetCluster(t)] >= s.limits.PerCluster {
+			continue
+		}
+		return n
+	}
+	return -1
+}
+
+// start updates counters for t beginning. The caller must hold the lock.
+func (s *Scheduler) start(t *Target) {
+	inst := t.Instance.String()
+	if _, started := s.remainingByInst[inst]; !started {
+		remaining := 1
+		for _, queued := range s.queue {
+			if queued.Instance.String() == inst {
+				remaining++
+			}
+		}
+		s.remainingByInst[inst] = remaining
+	}
+	s.running++
+	s.runningByInst[inst]++
+	s.runningByCluster[targetCluster(t)]++
+}
+
+// finish updates counters for t completing. The caller must hold the lock.
+func (s *Scheduler) finish(t *Target) {
+	inst := t.Instance.String()
+	s.running--
+	s.runningByInst[inst]--
+	s.runningByCluster[targetCluster(t)]--
+	if s.remainingByInst[inst]--; s.remainingByInst[inst] == 0 {
+		delete(s.remainingByInst, inst)
+	}
+}
+
+// targetCluster returns the name of the cluster containing t's instance. If
+// the cluster option is not set, each instance is considered to be its own
+// cluster.
+func targetCluster(t *Target) string {
+	if cluster := t.Dir.Config.Get("cluster"); cluster != "" {
+		return "cluster:" + cluster
+	}
+	return "instance:" + t.Instance.String()
+}
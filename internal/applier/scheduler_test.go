@@ -0,0 +1,136 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestConcurrencyLimitsForDir(t *testing.T) {
+	makeDir := func(instances, perInstance, perCluster, total string) *fs.Dir {
+		return &fs.Dir{Config: mybase.SimpleConfig(map[string]string{
+			"concurrent-instances":    instances,
+			"concurrent-per-instance": perInstance,
+			"concurrent-per-cluster":  perCluster,
+			"concurrent-total":        total,
+		})}
+	}
+	limits, err := ConcurrencyLimitsForDir(makeDir("4", "2", "3", "0"))
+	if expected := (ConcurrencyLimits{Instances: 4, PerInstance: 2, PerCluster: 3}); err != nil || limits != expected {
+		t.Errorf("Unexpected return from ConcurrencyLimitsForDir: %+v, %v", limits, err)
+	}
+	for _, dir := range []*fs.Dir{makeDir("0", "1", "0", "0"), makeDir("1", "0", "0", "0"), makeDir("1", "1", "-1", "0"), makeDir("1", "1", "0", "x")} {
+		if _, err := ConcurrencyLimitsForDir(dir); err == nil {
+			t.Errorf("Expected error from ConcurrencyLimitsForDir with config %v, but it was nil", dir.Config)
+		}
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	// Build 4 instances with 3 schemas each. The first 2 instances are in the
+	// same cluster.
+	var groups []TargetGroup
+	for n := range 4 {
+		inst, err := tengo.NewInstance("mysql", fmt.Sprintf("root:@tcp(127.0.0.%d:3306)/", n+1))
+		if err != nil {
+			t.Fatalf("Unexpected error from NewInstance: %v", err)
+		}
+		cluster := ""
+		if n < 2 {
+			cluster = "alpha"
+		}
+		dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{"cluster": cluster})}
+		var tg TargetGroup
+		for _, schemaName := range []string{"one", "two", "three"} {
+			tg = append(tg, &Target{Instance: inst, Dir: dir, SchemaName: schemaName})
+		}
+		groups = append(groups, tg)
+	}
+
+	runWithLimits := func(limits ConcurrencyLimits, failTarget *Target) (maxInstances, maxPerInstance, maxPerCluster, maxTotal, count int, err error) {
+		var m sync.Mutex
+		runningByInst := make(map[string]int)
+		runningByCluster := make(map[string]int)
+		var running int
+		err = NewScheduler(limits).Run(groups, func(target *Target) error {
+			inst, cluster := target.Instance.String(), targetCluster(target)
+			m.Lock()
+			count++
+			running++
+			runningByInst[inst]++
+			runningByCluster[cluster]++
+			maxTotal = max(maxTotal, running)
+			maxPerInstance = max(maxPerInstance, runningByInst[inst])
+			maxPerCluster = max(maxPerCluster, runningByCluster[cluster])
+			var activeInstances int
+			for _, n := range runningByInst {
+				if n > 0 {
+					activeInstances++
+				}
+			}
+			maxInstances = max(maxInstances, activeInstances)
+			m.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			m.Lock()
+			running--
+			runningByInst[inst]--
+			runningByCluster[cluster]--
+			m.Unlock()
+			if target == failTarget {
+				return errors.New("fake failure")
+			}
+			return nil
+		})
+		return
+	}
+
+	cases := []struct {
+		limits      ConcurrencyLimits
+		maxInst     int
+		maxPerInst  int
+		maxPerClust int
+		maxTotal    int
+	}{
+		{ConcurrencyLimits{Instances: 1, PerInstance: 1}, 1, 1, 1, 1},
+		{ConcurrencyLimits{Instances: 4, PerInstance: 1}, 4, 1, 2, 4},
+		{ConcurrencyLimits{Instances: 4, PerInstance: 1, PerCluster: 1}, 3, 1, 1, 3},
+		{ConcurrencyLimits{Instances: 4, PerInstance: 3, Total: 5}, 4, 3, 5, 5},
+		{ConcurrencyLimits{Instances: 2, PerInstance: 2}, 2, 2, 4, 4},
+	}
+	for n, c := range cases {
+		maxInst, maxPerInst, maxPerClust, maxTotal, count, err := runWithLimits(c.limits, nil)
+		if err != nil || count != 12 {
+			t.Errorf("Case %d: Unexpected return from Run: count=%d err=%v", n, count, err)
+		}
+		if maxInst > c.maxInst || maxPerInst > c.maxPerInst || maxPerClust > c.maxPerClust || maxTotal > c.maxTotal {
+			t.Errorf("Case %d: Limits exceeded: instances=%d perInstance=%d perCluster=%d total=%d", n, maxInst, maxPerInst, maxPerClust, maxTotal)
+		}
+	}
+
+	// A fatal error on one target should prevent queued targets from starting
+	_, _, _, _, count, err := runWithLimits(ConcurrencyLimits{Instances: 2, PerInstance: 1}, groups[0][0])
+	if err == nil || count >= 12 {
+		t.Errorf("Unexpected return from Run with failing target: count=%d err=%v", count, err)
+	}
+
+	// Stop prevents queued targets from starting, but running ones complete
+	scheduler := NewScheduler(ConcurrencyLimits{Instances: 1, PerInstance: 1})
+	var started []*Target
+	err = scheduler.Run(groups, func(target *Target) error {
+		started = append(started, target)
+		if len(started) == 2 {
+			scheduler.Stop()
+			return errors.New("fake failure")
+		}
+		return nil
+	})
+	if unstarted := scheduler.Unstarted(); err == nil || len(started) != 2 || len(unstarted) != 10 {
+		t.Errorf("Unexpected return from Run with Stop: started=%d unstarted=%d err=%v", len(started), len(unstarted), err)
+	}
+}
@@ -0,0 +1,96 @@
+package applier
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// sessionSetup controls session initialization and execution time limits for
+// DDL run directly by Skeema.
+type sessionSetup struct {
+	initStatements []string      // run on the connection prior to each DDL statement
+	timeout        time.Duration // 0 means no limit
+}
+
+// newSessionSetup returns a sessionSetup configured using the init-sql,
+// statement-timeout, and wsrep-osu-method options. If none of these options are
+// set, nil is returned.
+func newSessionSetup(config *mybase.Config) (*sessionSetup, error) {
+	ss := &sessionSetup{}
+	if method := strings.ToUpper(config.Get("wsrep-osu-method")); method == "TOI" || method == "RSU" {
+		ss.initStatements = append(ss.initStatements, "SET SESSION wsrep_OSU_method = '"+method+"'")
+	} else if method != "" {
+		return nil, fmt.Errorf(`option wsrep-osu-method must be one of "TOI" or "RSU"`)
+	}
+	if initSQL := config.Get("init-sql"); initSQL != "" {
+		statements, err := tengo.ParseStatementsInString(initSQL)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse option init-sql: %w", err)
+		}
+		for _, stmt := range statements {
+			if stmt.Type != tengo.StatementTypeNoop {
+				ss.initStatements = append(ss.initStatements, stmt.Body())
+			}
+		}
+	}
+	var err error
+	if ss.timeout, err = time.ParseDuration(config.Get("statement-timeout")); err != nil || ss.timeout < 0 {
+		return nil, fmt.Errorf("option statement-timeout must be a non-negative duration, such as \"30m\"")
+	}
+	if len(ss.initStatements) == 0 && ss.timeout == 0 {
+		return nil, nil
+	}
+	return ss, nil
+}
+
+// run executes the init statements on conn, and then calls exec. If exec does
+// not complete within the timeout, the statement running on conn is killed
+// using KILL QUERY via db, and an error is returned once exec returns. It is
+// safe to call on a nil receiver, in which case exec is simply called.
+func (ss *sessionSetup) run(ctx context.Context, db *sqlx.DB, conn *sql.Conn, connID int64, exec func() error) error {
+	if ss == nil {
+		return exec()
+	}
+	if len(ss.initStatements) > 0 {
+		// Session state should not leak to other users of the connection pool, so
+		// discard this connection once done
+		defer conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	for _, stmt := range ss.initStatements {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("Error running init-sql statement %q: %w", stmt, err)
+		}
+	}
+	if ss.timeout == 0 {
+		return exec()
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- exec()
+	}()
+	timer := time.NewTimer(ss.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+	}
+	log.Warnf("Statement exceeded statement-timeout of %s; killing it", ss.timeout)
+	if _, err := db.Exec(fmt.Sprintf("KILL QUERY %d", connID)); err != nil {
+		log.Errorf("Unable to kill statement which exceeded statement-timeout: %s", err)
+	}
+	if err := <-result; err != nil {
+		return fmt.Errorf("Statement exceeded statement-timeout of %s and was cancelled: %w", ss.timeout, err)
+	}
+	return nil // statement completed just as the timeout elapsed
+}
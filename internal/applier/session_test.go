@@ -0,0 +1,104 @@
+package applier
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skeema/mybase"
+)
+
+func TestNewSessionSetup(t *testing.T) {
+	getSetup := func(initSQL, timeout string) (*sessionSetup, error) {
+		return newSessionSetup(mybase.SimpleConfig(map[string]string{
+			"init-sql":          initSQL,
+			"statement-timeout": timeout,
+			"wsrep-osu-method":  "",
+		}))
+	}
+	if ss, err := getSetup("", "0"); ss != nil || err != nil {
+		t.Errorf("Expected nil, nil with no options set; instead found %+v, %v", ss, err)
+	}
+	ss, err := getSetup("SET SESSION lock_wait_timeout = 5; SET SESSION innodb_lock_wait_timeout = 3;\n-- comment\n", "0")
+	expected := []string{"SET SESSION lock_wait_timeout = 5", "SET SESSION innodb_lock_wait_timeout = 3"}
+	if err != nil || !slices.Equal(ss.initStatements, expected) || ss.timeout != 0 {
+		t.Errorf("Unexpected return from newSessionSetup: %+v, %v", ss, err)
+	}
+	if ss, err = getSetup("", "90s"); err != nil || len(ss.initStatements) != 0 || ss.timeout != 90*time.Second {
+		t.Errorf("Unexpected return from newSessionSetup: %+v, %v", ss, err)
+	}
+	for _, badTimeout := range []string{"90", "-1s", "soon"} {
+		if _, err := getSetup("", badTimeout); err == nil {
+			t.Errorf("Expected error from statement-timeout %q, but err was nil", badTimeout)
+		}
+	}
+
+	// wsrep-osu-method should be applied before init-sql
+	ss, err = newSessionSetup(mybase.SimpleConfig(map[string]string{
+		"init-sql":          "SET SESSION lock_wait_timeout = 5",
+		"statement-timeout": "0",
+		"wsrep-osu-method":  "rsu",
+	}))
+	expected = []string{"SET SESSION wsrep_OSU_method = 'RSU'", "SET SESSION lock_wait_timeout = 5"}
+	if err != nil || !slices.Equal(ss.initStatements, expected) {
+		t.Errorf("Unexpected return from newSessionSetup: %+v, %v", ss, err)
+	}
+	if _, err := newSessionSetup(mybase.SimpleConfig(map[string]string{"init-sql": "", "statement-timeout": "0", "wsrep-osu-method": "NBO"})); err == nil {
+		t.Error("Expected error from invalid wsrep-osu-method, but err was nil")
+	}
+
+	// run on a nil sessionSetup should just call exec
+	var called bool
+	ss = nil
+	if err := ss.run(context.Background(), nil, nil, 0, func() error { called = true; return nil }); err != nil || !called {
+		t.Errorf("Unexpected behavior from run on nil sessionSetup: called=%t err=%v", called, err)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestSessionSetup(t *testing.T) {
+	db, err := s.d[0].CachedConnectionPool("", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Unable to obtain connection: %v", err)
+	}
+	var connID int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ss := &sessionSetup{
+		initStatements: []string{"SET SESSION lock_wait_timeout = 7"},
+		timeout:        250 * time.Millisecond,
+	}
+
+	// Confirm init-sql is run on the connection, and statement-timeout kills a
+	// long-running statement
+	var lockWaitTimeout int
+	start := time.Now()
+	ss.run(ctx, db, conn, connID, func() error {
+		if err := conn.QueryRowContext(ctx, "SELECT @@lock_wait_timeout").Scan(&lockWaitTimeout); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(ctx, "DO SLEEP(10)")
+		return err
+	})
+	conn.Close()
+	if lockWaitTimeout != 7 {
+		t.Errorf("Expected init-sql to set lock_wait_timeout to 7, instead found %d", lockWaitTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected statement-timeout to kill statement, but run took %s", elapsed)
+	}
+
+	// The connection should have been discarded rather than returned to the
+	// pool, so new connections should not have the init-sql session state
+	if err := db.QueryRow("SELECT @@lock_wait_timeout").Scan(&lockWaitTimeout); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	} else if lockWaitTimeout == 7 {
+		t.Error("Session state from init-sql leaked into connection pool")
+	}
+}
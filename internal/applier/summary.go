@@ -0,0 +1,144 @@
+package applier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Possible target outcomes tracked by RunSummary.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+)
+
+// RunSummary tallies the outcome of each target across a push or diff, so that
+// a summary can be logged once all targets are complete. Targets are applied
+// concurrently and independently, so the outcome of each one is recorded
+// separately. A nil *RunSummary ignores all calls. It is safe for concurrent
+// use.
+type RunSummary struct {
+	m       sync.Mutex
+	entries []summaryEntry
+}
+
+// summaryEntry is the outcome of a single target.
+type summaryEntry struct {
+	target  string
+	outcome string
+	reason  string
+}
+
+// NewRunSummary returns an empty RunSummary.
+func NewRunSummary() *RunSummary {
+	return &RunSummary{}
+}
+
+// Record tracks the outcome of a target which was applied. A target is
+// considered to have failed if err is non-nil or if any of its operations were
+// skipped due to problems.
+func (rs *RunSummary) Record(t *Target, result Result, err error) {
+	if rs == nil {
+		return
+	}
+	entry := summaryEntry{target: t.String(), outcome: OutcomeSucceeded}
+	if err != nil {
+		entry.outcome, entry.reason = OutcomeFailed, err.Error()
+	} else if result.SkipCount > 0 {
+		entry.outcome, entry.reason = OutcomeFailed, result.Error().Error()
+	}
+	rs.add(entry)
+}
+
+// RecordSkipped tracks targets which were not applied at all, for the
+// supplied reason.
+func (rs *RunSummary) RecordSkipped(targets []*Target, reason string) {
+	if rs == nil {
+		return
+	}
+	for _, t := range targets {
+		rs.add(summaryEntry{target: t.String(), outcome: OutcomeSkipped, reason: reason})
+	}
+}
+
+func (rs *RunSummary) add(entry summaryEntry) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+	rs.entries = append(rs.entries, entry)
+}
+
+// Counts returns the number of targets with each outcome.
+func (rs *RunSummary) Counts() map[string]int {
+	counts := make(map[string]int, 3)
+	if rs == nil {
+		return counts
+	}
+	rs.m.Lock()
+	defer rs.m.Unlock()
+	for _, entry := range rs.entries {
+		counts[entry.outcome]++
+	}
+	return counts
+}
+
+// Lines returns a description of each target which did not succeed, sorted by
+// outcome and then by target.
+func (rs *RunSummary) Lines() []string {
+	if rs == nil {
+		return nil
+	}
+	rs.m.Lock()
+	entries := make([]summaryEntry, 0, len(rs.entries))
+	for _, entry := range rs.entries {
+		if entry.outcome != OutcomeSucceeded {
+			entries = append(entries, entry)
+		}
+	}
+	rs.m.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].outcome != entries[j].outcome {
+			return entries[i].outcome < entries[j].outcome
+		}
+		return entries[i].target < entries[j].target
+	})
+	lines := make([]string, len(entries))
+	for n, entry := range entries {
+		lines[n] = strings.ToUpper(entry.outcome) + " " + entry.target + ": " + entry.reason
+	}
+	return lines
+}
+
+// String returns a one-line description of the outcome counts, for example
+// "12 targets: 10 succeeded, 1 failed, 1 skipped".
+func (rs *RunSummary) String() string {
+	counts := rs.Counts()
+	total := counts[OutcomeSucceeded] + counts[OutcomeFailed] + counts[OutcomeSkipped]
+	parts := make([]string, 0, 3)
+	for _, outcome := range []string{OutcomeSucceeded, OutcomeFailed, OutcomeSkipped} {
+		if counts[outcome] > 0 || outcome == OutcomeSucceeded {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[outcome], outcome))
+		}
+	}
+	return countAndNoun(total, "target", "targets") + ": " + strings.Join(parts, ", ")
+}
+
+// Log outputs the summary, if more than one target was tracked or any target
+// did not succeed. The operation should be "push" or "diff".
+func (rs *RunSummary) Log(operation string) {
+	counts := rs.Counts()
+	if counts[OutcomeSucceeded] <= 1 && counts[OutcomeFailed]+counts[OutcomeSkipped] == 0 {
+		return
+	}
+	if counts[OutcomeFailed]+counts[OutcomeSkipped] == 0 {
+		log.Infof("Summary of %s: %s", operation, rs)
+		return
+	}
+	log.Warnf("Summary of %s: %s", operation, rs)
+	for _, line := range rs.Lines() {
+		log.Warn("  " + line)
+	}
+}
@@ -0,0 +1,53 @@
+package applier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestRunSummary(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(map[string]string{})}
+	target := func(schemaName string) *Target {
+		return &Target{Instance: inst, Dir: dir, SchemaName: schemaName}
+	}
+
+	// A nil summary should ignore all calls
+	var rs *RunSummary
+	rs.Record(target("one"), Result{}, nil)
+	rs.RecordSkipped([]*Target{target("two")}, "irrelevant")
+	if len(rs.Counts()) != 0 || len(rs.Lines()) != 0 {
+		t.Error("Expected nil RunSummary to be empty")
+	}
+
+	rs = NewRunSummary()
+	rs.Record(target("one"), Result{Differences: true}, nil)
+	rs.Record(target("two"), Result{}, nil)
+	if expected := "2 targets: 2 succeeded"; rs.String() != expected || len(rs.Lines()) != 0 {
+		t.Errorf("Expected %q with no lines, instead found %q with lines %q", expected, rs.String(), rs.Lines())
+	}
+
+	rs.Record(target("three"), Result{SkipCount: 2}, nil)
+	rs.Record(target("four"), Result{}, errors.New("connection refused"))
+	rs.RecordSkipped([]*Target{target("six"), target("five")}, "not started due to stop-on-failure")
+	if expected := "6 targets: 2 succeeded, 2 failed, 2 skipped"; rs.String() != expected {
+		t.Errorf("Expected %q, instead found %q", expected, rs.String())
+	}
+	expectedLines := []string{
+		"FAILED 127.0.0.1:3306 four: connection refused",
+		"FAILED 127.0.0.1:3306 three: Skipped 2 operations due to problems",
+		"SKIPPED 127.0.0.1:3306 five: not started due to stop-on-failure",
+		"SKIPPED 127.0.0.1:3306 six: not started due to stop-on-failure",
+	}
+	if lines := rs.Lines(); strings.Join(lines, "\n") != strings.Join(expectedLines, "\n") {
+		t.Errorf("Unexpected lines:\n%s", strings.Join(lines, "\n"))
+	}
+}
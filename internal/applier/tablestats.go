@@ -0,0 +1,107 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// tableStats contains information_schema estimates of a table's size in bytes
+// and its number of rows.
+type tableStats struct {
+	size int64
+	rows int64
+}
+
+// String returns a human-readable description of the stats, for display in
+// SQL comments.
+func (ts tableStats) String() string {
+	return fmt.Sprintf("size %s, approx. %s", formatBytes(ts.size), countAndNoun(int(ts.rows), "row"))
+}
+
+// needTableRows returns true if any option using the table's estimated row
+// count is in use.
+func needTableRows(config *mybase.Config) bool {
+	return config.GetBool("table-stats") || config.Changed("require-osc-rows")
+}
+
+// getTableStats returns the size and estimated row count of the table on the
+// instance corresponding to the target. If the table has no rows, both values
+// are always 0, even though information_schema normally indicates at least
+// 16kb in this case. The row estimate is only queried if withRows is true.
+func getTableStats(target *Target, tableName string, withRows bool) (stats tableStats, err error) {
+	hasRows, err := target.Instance.TableHasRows(target.SchemaName, tableName)
+	if !hasRows || err != nil {
+		return stats, err
+	}
+	if stats.size, err = target.Instance.TableSize(target.SchemaName, tableName); err != nil || !withRows {
+		return stats, err
+	}
+	stats.rows, err = target.Instance.TableRowEstimate(target.SchemaName, tableName)
+	return stats, err
+}
+
+// formatBytes returns n in human-readable form using binary units, e.g.
+// "512 bytes", "16.0 KiB", or "1.5 GiB".
+func formatBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	value := float64(n)
+	var unit string
+	for _, unit = range []string{"KiB", "MiB", "GiB", "TiB", "PiB"} {
+		value /= 1024
+		if value < 1024 {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, unit)
+}
+
+// oscRequirement blocks ALTER TABLE statements which would be executed
+// directly by Skeema, rather than by alter-wrapper or osc-tool, if the table's
+// size or estimated row count is at least the require-osc-size or
+// require-osc-rows option value, respectively.
+type oscRequirement struct {
+	minSize int64
+	minRows int64
+}
+
+// newOSCRequirement returns an oscRequirement based on config. If neither
+// require-osc-size nor require-osc-rows are set, nil is returned.
+func newOSCRequirement(config *mybase.Config) (*oscRequirement, error) {
+	minSize, err := config.GetBytes("require-osc-size")
+	if err != nil {
+		return nil, errors.New("option require-osc-size has been configured to an invalid value")
+	}
+	minRows, err := config.GetInt("require-osc-rows")
+	if err != nil || minRows < 0 {
+		return nil, errors.New("option require-osc-rows has been configured to an invalid value")
+	}
+	if minSize == 0 && minRows == 0 {
+		return nil, nil
+	}
+	return &oscRequirement{minSize: int64(minSize), minRows: int64(minRows)}, nil
+}
+
+// violation returns a description of why ddl is not permitted, or an empty
+// string if ddl is not a direct ALTER TABLE on a table exceeding the
+// configured thresholds. It is nil-safe.
+func (req *oscRequirement) violation(ddl *DDLStatement) string {
+	if req == nil || ddl.stats == nil || ddl.shellOut != nil || ddl.osc != nil {
+		return ""
+	}
+	key := ddl.diff.ObjectKey()
+	if key.Type != tengo.ObjectTypeTable || ddl.diff.DiffType() != tengo.DiffTypeAlter {
+		return ""
+	}
+	if req.minSize > 0 && ddl.stats.size >= req.minSize {
+		return fmt.Sprintf("Table %s has size %s, so require-osc-size=%d only permits altering it using alter-wrapper or osc-tool.", tengo.EscapeIdentifier(key.Name), formatBytes(ddl.stats.size), req.minSize)
+	}
+	if req.minRows > 0 && ddl.stats.rows >= req.minRows {
+		return fmt.Sprintf("Table %s has approximately %s, so require-osc-rows=%d only permits altering it using alter-wrapper or osc-tool.", tengo.EscapeIdentifier(key.Name), countAndNoun(int(ddl.stats.rows), "row"), req.minRows)
+	}
+	return ""
+}
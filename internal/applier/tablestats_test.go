@@ -0,0 +1,74 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		0:                  "0 bytes",
+		1023:               "1023 bytes",
+		1024:               "1.0 KiB",
+		16384:              "16.0 KiB",
+		1536 * 1024 * 1024: "1.5 GiB",
+		3 << 50:            "3.0 PiB",
+	}
+	for input, expected := range cases {
+		if actual := formatBytes(input); actual != expected {
+			t.Errorf("Expected formatBytes(%d) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestNewOSCRequirement(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{"require-osc-size": "0", "require-osc-rows": "0"})
+	if req, err := newOSCRequirement(cfg); req != nil || err != nil {
+		t.Errorf("Expected nil requirement and nil error with default options, instead found %+v, %v", req, err)
+	}
+	cfg = mybase.SimpleConfig(map[string]string{"require-osc-size": "10m", "require-osc-rows": "5000"})
+	if req, err := newOSCRequirement(cfg); err != nil || req.minSize != 10*1024*1024 || req.minRows != 5000 {
+		t.Errorf("Unexpected return from newOSCRequirement: %+v, %v", req, err)
+	}
+	for _, values := range [][2]string{{"potato", "0"}, {"0", "potato"}, {"0", "-5"}} {
+		cfg = mybase.SimpleConfig(map[string]string{"require-osc-size": values[0], "require-osc-rows": values[1]})
+		if _, err := newOSCRequirement(cfg); err == nil {
+			t.Errorf("Expected error from newOSCRequirement with values %v, but err was nil", values)
+		}
+	}
+}
+
+func TestOSCRequirementViolation(t *testing.T) {
+	from := &tengo.Table{Name: "users", Engine: "InnoDB"}
+	to := &tengo.Table{Name: "users", Engine: "InnoDB", Comment: "hello"}
+	alter := tengo.NewAlterTable(from, to)
+	big := &tableStats{size: 20 * 1024 * 1024, rows: 100}
+	many := &tableStats{size: 1024, rows: 10000}
+	req := &oscRequirement{minSize: 10 * 1024 * 1024, minRows: 5000}
+
+	cases := []struct {
+		req      *oscRequirement
+		ddl      *DDLStatement
+		expected string // substring of violation, or blank if none expected
+	}{
+		{nil, &DDLStatement{diff: alter, stats: big}, ""},
+		{req, &DDLStatement{diff: alter}, ""},
+		{req, &DDLStatement{diff: alter, stats: &tableStats{size: 1024, rows: 10}}, ""},
+		{req, &DDLStatement{diff: alter, stats: big}, "require-osc-size"},
+		{req, &DDLStatement{diff: alter, stats: many}, "require-osc-rows"},
+		{req, &DDLStatement{diff: alter, stats: big, shellOut: shellout.New("echo")}, ""},
+		{req, &DDLStatement{diff: tengo.NewDropTable(from), stats: big}, ""},
+	}
+	for n, c := range cases {
+		actual := c.req.violation(c.ddl)
+		if c.expected == "" && actual != "" {
+			t.Errorf("Case %d: Expected no violation, instead found %q", n, actual)
+		} else if !strings.Contains(actual, c.expected) {
+			t.Errorf("Case %d: Expected violation to mention %q, instead found %q", n, c.expected, actual)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package applier
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/tracing"
 	"github.com/skeema/skeema/internal/workspace"
 )
 
@@ -20,26 +21,53 @@ type Target struct {
 	Dir           *fs.Dir
 	SchemaName    string
 	DesiredSchema *workspace.Schema
+	span          *tracing.Span        // set while the target is being applied, if tracing is enabled
+	shared        *sharedIntrospection // set if dedupe-schemas is enabled and the dir maps to multiple schemas
 }
 
 func (t *Target) String() string {
 	return t.Instance.String() + " " + t.SchemaName
 }
 
+// logEntry returns a log entry with structured fields identifying t. These
+// fields are only displayed with log-format=json.
+func (t *Target) logEntry() *log.Entry {
+	return log.WithFields(log.Fields{
+		"instance": t.Instance.String(),
+		"schema":   t.SchemaName,
+	})
+}
+
+// statementLogEntry returns a log entry with structured fields identifying t,
+// and the object and statement hash of stmt.
+func (t *Target) statementLogEntry(stmt PlannedStatement) *log.Entry {
+	entry := t.logEntry().WithField("statement_hash", statementHash(stmt))
+	if ddl, ok := stmt.(*DDLStatement); ok && ddl.diff != nil {
+		entry = entry.WithField("object", ddl.diff.ObjectKey().String())
+	}
+	return entry
+}
+
 // SchemaFromInstance introspects and returns the instance's version of the
-// schema, if it exists.
-func (t *Target) SchemaFromInstance() (*tengo.Schema, error) {
-	schema, err := t.Instance.Schema(t.SchemaName)
-	if err == sql.ErrNoRows {
-		err = nil
+// schema, if it exists. With dedupe-schemas, the result may be a renamed copy
+// of another schema with an identical definition, introspected earlier in the
+// same run.
+func (t *Target) SchemaFromInstance() (schema *tengo.Schema, err error) {
+	if t.shared != nil {
+		schema, err = t.shared.Schema(t.SchemaName)
+	} else {
+		schema, err = introspectSchema(t.Instance, t.SchemaName)
 	}
 	schema.StripMatches(t.Dir.IgnorePatterns)
 	return schema, err
 }
 
-// SchemaFromDir returns the desired schema expressed in the filesystem.
+// SchemaFromDir returns the desired schema expressed in the filesystem. Any
+// cross-schema foreign key references are adjusted to use physical schema
+// names, if the schema-map option is in use.
 func (t *Target) SchemaFromDir() *tengo.Schema {
-	schemaCopy := *t.DesiredSchema.Schema
+	mapping, _ := t.Dir.SchemaNameMap() // errors already surfaced by TargetsForDir
+	schemaCopy := *t.DesiredSchema.Schema.RenameReferences(mapping)
 	schemaCopy.Name = t.SchemaName
 	return &schemaCopy
 }
@@ -65,6 +93,9 @@ func TargetsForDir(dir *fs.Dir, maxDepth int) (targets []*Target, skipCount int)
 	if dir.Config.Changed("host") && dir.HasSchema() {
 		var instances []*tengo.Instance
 		instances, skipCount = instancesForDir(dir)
+		var primarySkipCount int
+		instances, primarySkipCount = primaryInstances(dir, instances)
+		skipCount += primarySkipCount
 
 		// For each LogicalSchema, obtain a *tengo.Schema representation and then
 		// create a Target for each instance x schema combination
@@ -157,6 +188,30 @@ func instancesForDir(dir *fs.Dir) (instances []*tengo.Instance, skipCount int) {
 	return
 }
 
+// primaryInstances replaces each group replication member in instances with
+// its group's current primary, if the discover-primary option is enabled.
+// Duplicates are removed, in case multiple members of the same group were
+// configured.
+func primaryInstances(dir *fs.Dir, instances []*tengo.Instance) (result []*tengo.Instance, skipCount int) {
+	if !dir.Config.GetBool("discover-primary") {
+		return instances, 0
+	}
+	seen := make(map[string]bool)
+	for _, inst := range instances {
+		primary, err := groupPrimary(inst)
+		if err != nil {
+			log.Errorf("Skipping %s for %s: %s\n", inst, dir, err)
+			skipCount++
+			continue
+		}
+		if !seen[primary.String()] {
+			seen[primary.String()] = true
+			result = append(result, primary)
+		}
+	}
+	return result, skipCount
+}
+
 func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, instances []*tengo.Instance) (targets []*Target, skipCount int) {
 	// If there are multiple logical schemas defined in this directory, prohibit
 	// mixing configuration styles. Either all CREATEs should be in a single
@@ -184,6 +239,9 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 	// Obtain a *tengo.Schema representation of the dir's *.sql files from a
 	// workspace
 	opts, err := workspace.OptionsForDir(dir, instances[0])
+	if err == nil && opts.Type == workspace.TypeParse {
+		err = workspace.ErrParseWorkspace
+	}
 	if err != nil {
 		log.Errorf("Skipping %s: %s\n", dir, err)
 		return nil, len(instances)
@@ -198,6 +256,33 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 		log.Errorf("Skipping %s: %s\n", dir, err)
 		return nil, len(instances)
 	}
+	// Statements may behave differently on a target whose sql_mode differs from
+	// the workspace's, for example if the workspace fell back to a portable
+	// sql_mode, or if the target instances' sql_modes differ from each other
+	parity, err := dir.Config.GetEnum("sql-mode-parity", "ignore", "warn", "error")
+	if err != nil {
+		log.Errorf("Skipping %s: %s\n", dir, err)
+		return nil, len(instances)
+	}
+	if parity != "ignore" {
+		var problemCount int
+		for _, inst := range instances {
+			problems := wsSchema.SQLModeProblems(inst.SQLMode())
+			if len(problems) == 0 {
+				continue
+			}
+			problemCount += len(problems)
+			onlyWorkspace, onlyTarget := workspace.SQLModeDifferences(wsSchema.SQLMode, inst.SQLMode())
+			log.Warnf("Workspace sql_mode for %s differs from %s: only in workspace: %q; only on %s: %q", dir, inst, strings.Join(onlyWorkspace, ","), inst, strings.Join(onlyTarget, ","))
+			for _, problem := range problems {
+				log.Warnf("%s: %s", inst, problem)
+			}
+		}
+		if problemCount > 0 && parity == "error" {
+			log.Warnf("Skipping %s due to %s, and sql-mode-parity=error\n", dir, countAndNoun(problemCount, "sql_mode parity problem"))
+			return nil, len(instances)
+		}
+	}
 	if len(wsSchema.Failures) > 0 {
 		for _, stmtErr := range wsSchema.Failures {
 			log.Error(stmtErr.Error())
@@ -206,6 +291,14 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 		return nil, len(instances)
 	}
 
+	// Schema names in *.sql files are logical names, which may be mapped to
+	// different physical names in the current environment
+	mapping, err := dir.SchemaNameMap()
+	if err != nil {
+		log.Errorf("Skipping %s: %s\n", dir, err)
+		return nil, len(instances)
+	}
+
 	// Create a Target for each instance x schema combination
 	for _, inst := range instances {
 		// Obtain the list of schema names configured in .skeema
@@ -230,14 +323,22 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 			// The only allowed cases are either NO schema name in .skeema, OR a single
 			// schema name in .skeema which exactly matches a single schema name used
 			// consistently throughout this dir's *.sql files.
+			physicalName := logicalSchema.Name
+			if mapped, ok := mapping[physicalName]; ok {
+				physicalName = mapped
+			}
 			if len(schemaNames) > 0 {
-				if len(schemaNames) > 1 || len(dir.LogicalSchemas) > 1 || schemaNames[0] != logicalSchema.Name {
+				if len(schemaNames) > 1 || len(dir.LogicalSchemas) > 1 || schemaNames[0] != physicalName {
 					log.Errorf("Skipping %s: This directory's .skeema file configures a different schema name than its *.sql files.", dir)
 					log.Error("When configuring a schema name in .skeema, exclude schema names entirely from *.sql files.\n")
 					return nil, len(instances)
 				}
 			}
-			schemaNames = []string{logicalSchema.Name}
+			schemaNames = []string{physicalName}
+		}
+		var shared *sharedIntrospection
+		if len(schemaNames) > 1 && dir.Config.GetBool("dedupe-schemas") {
+			shared = newSharedIntrospection(inst, schemaNames)
 		}
 		for _, schemaName := range schemaNames {
 			t := &Target{
@@ -245,6 +346,7 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 				Dir:           dir,
 				SchemaName:    schemaName,
 				DesiredSchema: wsSchema,
+				shared:        shared,
 			}
 			targets = append(targets, t)
 		}
@@ -282,15 +282,68 @@ func getBaseConfig(t *testing.T, cliFlags string) *mybase.Config {
 	cmd.AddOption(mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"))
 	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple instances or schemas, just run against the first per dir"))
 	cmd.AddOption(mybase.BoolOption("exact-match", 0, false, "Follow *.sql table definitions exactly, even for differences with no functional impact"))
+	cmd.AddOption(mybase.BoolOption("staged-charset-conversion", 0, false, "Convert columns to utf8mb4 one ALTER TABLE at a time, warning about index length and row size limits"))
 	cmd.AddOption(mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"))
+	cmd.AddOption(mybase.StringOption("sql-mode-parity", 0, "warn", `Handling of *.sql statements affected by sql_mode differences between workspace and target (valid values: "ignore", "warn", "error")`))
 	cmd.AddOption(mybase.BoolOption("brief", 'q', false, "<overridden by diff command>").Hidden())
 	cmd.AddOption(mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE; see manual for template vars"))
 	cmd.AddOption(mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore --alter-wrapper for tables smaller than this size in bytes"))
 	cmd.AddOption(mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`))
-	cmd.AddOption(mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy")`))
+	cmd.AddOption(mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy", "auto")`))
+	cmd.AddOption(mybase.StringOption("alter-granularity", 0, "table", `Combine all clauses per table into one ALTER TABLE, or emit one ALTER TABLE per clause (valid values: "table", "clause")`))
 	cmd.AddOption(mybase.StringOption("ddl-wrapper", 'X', "", "Like --alter-wrapper, but applies to all DDL types (CREATE, DROP, ALTER)"))
+	cmd.AddOption(mybase.StringOption("osc-tool", 0, "none", `Natively run ALTER TABLE via an online schema change tool (valid values: "none", "gh-ost", "pt-osc", "builtin")`))
+	cmd.AddOption(mybase.StringOption("osc-tool-bin", 0, "", "Path to binary for --osc-tool, if not on PATH under its standard name"))
+	cmd.AddOption(mybase.StringOption("osc-tool-options", 0, "", "Additional command-line options to pass to --osc-tool"))
+	cmd.AddOption(mybase.BoolOption("osc-postpone-cut-over", 0, false, "With --osc-tool, postpone table cut-over until a flag file is removed"))
+	cmd.AddOption(mybase.StringOption("osc-chunk-size", 0, "1000", "With --osc-tool=builtin, number of rows to copy per chunk"))
+	cmd.AddOption(mybase.StringOption("osc-max-replica-lag", 0, "10", "With --osc-tool=builtin, pause copying while any --osc-replicas lag exceeds this many seconds"))
+	cmd.AddOption(mybase.StringOption("osc-replicas", 0, "", "With --osc-tool=builtin, comma-separated list of replica host[:port] to monitor for lag, instead of --replicas"))
 	cmd.AddOption(mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"))
+	cmd.AddOption(mybase.StringOption("protect-table", 0, "", "Never permit dropping or destructively altering tables matching this regex, even with --allow-unsafe"))
+	cmd.AddOption(mybase.StringOption("protect-column", 0, "", "Never permit dropping or destructively modifying columns matching this regex, even with --allow-unsafe"))
+	cmd.AddOption(mybase.StringOption("large-table-size", 0, "0", "Classify table rebuilds as lock-heavy for tables at least this size in bytes (0 to disable)"))
+	cmd.AddOption(mybase.StringOption("require-osc-size", 0, "0", "Refuse ALTER TABLE without --alter-wrapper or --osc-tool for tables at least this size in bytes (0 to disable)"))
+	cmd.AddOption(mybase.StringOption("require-osc-rows", 0, "0", "Refuse ALTER TABLE without --alter-wrapper or --osc-tool for tables with at least this many estimated rows (0 to disable)"))
+	cmd.AddOption(mybase.BoolOption("table-stats", 0, false, "Also output the size and estimated row count of each altered or dropped table"))
+	cmd.AddOption(mybase.StringOption("max-impact", 0, "", "Refuse statements with impact above this class: metadata-only, online-capable, table-rebuild, or lock-heavy"))
+	cmd.AddOption(mybase.StringOption("data-check", 0, "none", `Scan existing rows before narrowing or NOT NULL column changes (valid values: "none", "warn", "block")`))
+	cmd.AddOption(mybase.StringOption("data-check-rows", 0, "0", "With --data-check, only examine this many rows per table (0 to scan all rows)"))
+	cmd.AddOption(mybase.BoolOption("alter-progress", 0, false, "Display progress of ALTER TABLE statements run directly by Skeema"))
+	cmd.AddOption(mybase.StringOption("alter-progress-stream", 0, "", `Write ALTER TABLE progress as JSON lines to this file path ("-" for STDOUT)`))
+	cmd.AddOption(mybase.StringOption("journal-schema", 0, "", "Journal each statement in a table in this schema, so that an interrupted push can be safely re-run"))
+	cmd.AddOption(mybase.StringOption("before-push", 0, "", "Shell command to run before executing statements on each target; non-zero exit skips the target"))
+	cmd.AddOption(mybase.StringOption("after-push", 0, "", "Shell command to run after executing statements on each target"))
+	cmd.AddOption(mybase.StringOption("before-statement", 0, "", "Shell command to run before each statement; non-zero exit halts the target"))
+	cmd.AddOption(mybase.StringOption("after-statement", 0, "", "Shell command to run after each statement"))
+	cmd.AddOption(mybase.StringOption("history-schema", 0, "", "Record each executed statement in a _skeema_history table in this schema, for use with `skeema history`"))
+	cmd.AddOption(mybase.BoolOption("push-lock", 0, false, "Hold an advisory lock on each target schema while executing statements, to prevent concurrent pushes"))
+	cmd.AddOption(mybase.StringOption("push-lock-timeout", 0, "30", "With --push-lock, maximum number of seconds to wait for another push to release its lock"))
+	cmd.AddOption(mybase.StringOption("replicas", 0, "", `Comma-separated list of replica host[:port] to monitor for lag, or "auto" to discover via SHOW REPLICAS`))
+	cmd.AddOption(mybase.StringOption("max-replica-lag", 0, "0", "Pause before each statement while any --replicas lag exceeds this many seconds (0 to disable)"))
+	cmd.AddOption(mybase.StringOption("retry-attempts", 0, "0", "Retry statements failing due to lock wait timeouts or deadlocks up to this many times"))
+	cmd.AddOption(mybase.StringOption("retry-backoff", 0, "1s", "With --retry-attempts, initial wait before retrying, doubling after each retry"))
+	cmd.AddOption(mybase.StringOption("mdl-report-after", 0, "0", "Report sessions blocking a statement waiting on a metadata lock for this long (0 to disable)"))
+	cmd.AddOption(mybase.StringOption("mdl-kill-idle", 0, "0", "Kill sessions blocking a statement's metadata lock if idle for at least this long (0 to disable)"))
+	cmd.AddOption(mybase.StringOption("mdl-kill-users", 0, "", "With --mdl-kill-idle, only kill blocking sessions from this comma-separated list of users"))
+	cmd.AddOption(mybase.StringOption("init-sql", 0, "", "SQL statements to run on each connection before executing DDL, e.g. to set lock_wait_timeout"))
+	cmd.AddOption(mybase.StringOption("statement-timeout", 0, "0", "Kill any DDL run directly by Skeema which takes longer than this duration (0 for no limit)"))
+	cmd.AddOption(mybase.StringOption("wsrep-osu-method", 0, "", `For Galera clusters, set wsrep_OSU_method for DDL (valid values: "TOI", "RSU")`))
+	cmd.AddOption(mybase.StringOption("wsrep-max-recv-queue", 0, "0", "For Galera clusters, pause before each statement while wsrep_local_recv_queue exceeds this length (0 to disable)"))
+	cmd.AddOption(mybase.BoolOption("discover-primary", 0, false, "For group replication members, operate on the group's current primary instead; implies --verify-writable"))
+	cmd.AddOption(mybase.BoolOption("verify-writable", 0, false, "Refuse to execute statements on servers with read_only or super_read_only enabled"))
+	cmd.AddOption(mybase.StringOption("state-file", 0, "", "Record push progress to this file, so that an interrupted push can be continued with --resume"))
+	cmd.AddOption(mybase.BoolOption("resume", 0, false, "Continue an interrupted push from the progress recorded in --state-file"))
+	cmd.AddOption(mybase.BoolOption("interactive", 0, false, "Prompt for approval before pushing each target and executing each statement"))
+	cmd.AddOption(mybase.BoolOption("verify-replicas", 0, false, "After pushing, confirm the schema on each of --replicas matches the desired schema"))
+	cmd.AddOption(mybase.StringOption("verify-replicas-timeout", 0, "30s", "With --verify-replicas, maximum time to wait for replicas to apply the changes"))
+	cmd.AddOption(mybase.StringOption("plan", 0, "", "Only execute statements exactly matching this plan file from `skeema diff --save-plan`"))
+	cmd.AddOption(mybase.StringOption("save-rollback", 0, "", "Write a SQL script to this file which reverts the executed statements, where possible"))
 	cmd.AddOption(mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of instances concurrently"))
+	cmd.AddOption(mybase.StringOption("concurrent-per-instance", 0, "1", "Perform operations on this number of schemas concurrently on each database server"))
+	cmd.AddOption(mybase.StringOption("concurrent-per-cluster", 0, "0", "Limit concurrent operations on schemas sharing the same --cluster name (0 for no limit)"))
+	cmd.AddOption(mybase.StringOption("concurrent-total", 0, "0", "Limit concurrent operations on schemas across all database servers (0 for no limit)"))
+	cmd.AddOption(mybase.StringOption("cluster", 0, "", "Name of the cluster containing this dir's host, for use with --concurrent-per-cluster"))
 	cmd.AddArg("environment", "production", false)
 	util.AddGlobalOptions(cmd)
 	workspace.AddCommandOptions(cmd)
@@ -0,0 +1,206 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// lagThrottler pauses work while replication lag on any monitored replica
+// exceeds a threshold. It is used between statements of a push, as well as
+// between row copy chunks of the built-in online schema change tool.
+type lagThrottler struct {
+	maxLag       int // seconds; 0 means no limit
+	replicas     []string
+	discover     bool // if true, replicas are discovered from the source the first time wait is called
+	lagOption    string
+	pollInterval time.Duration
+}
+
+// newLagThrottler returns a lagThrottler configured using the supplied option
+// names. The replicas option may be a comma-separated list of host[:port], or
+// "auto" to discover replicas using SHOW REPLICAS on the source. If either
+// option is empty or 0, the returned lagThrottler never pauses.
+func newLagThrottler(config *mybase.Config, lagOption, replicasOption string) (*lagThrottler, error) {
+	maxLag, err := config.GetInt(lagOption)
+	if err != nil || maxLag < 0 {
+		return nil, fmt.Errorf("option %s must be a non-negative integer", lagOption)
+	}
+	lt := &lagThrottler{
+		maxLag:       maxLag,
+		lagOption:    lagOption,
+		pollInterval: time.Second,
+	}
+	replicas := config.GetSlice(replicasOption, ',', true)
+	if len(replicas) == 1 && strings.EqualFold(replicas[0], "auto") {
+		lt.discover = true
+	} else {
+		lt.replicas = replicas
+	}
+	return lt, nil
+}
+
+// enabled returns true if lt may pause.
+func (lt *lagThrottler) enabled() bool {
+	return lt != nil && lt.maxLag > 0 && (lt.discover || len(lt.replicas) > 0)
+}
+
+// wait blocks while any replica of instance has lag exceeding the configured
+// maximum. The supplied description of the paused work is used in log
+// messages. An error is returned if a replica cannot be checked, or if
+// replication is not running on it.
+func (lt *lagThrottler) wait(instance *tengo.Instance, what string) error {
+	if !lt.enabled() {
+		return nil
+	}
+	if lt.discover {
+		replicas, err := discoverReplicas(instance)
+		if err != nil {
+			return fmt.Errorf("Unable to discover replicas of %s: %w", instance, err)
+		}
+		log.Debugf("Discovered %s of %s for lag monitoring: %s", countAndNoun(len(replicas), "replica"), instance, strings.Join(replicas, ", "))
+		lt.replicas, lt.discover = replicas, false
+	}
+	var pausedSince time.Time
+	for {
+		lagging := ""
+		for _, replica := range lt.replicas {
+			lag, err := replicaLag(instance, replica)
+			if err != nil {
+				return fmt.Errorf("Unable to check replication lag on %s: %w", replica, err)
+			} else if lag > lt.maxLag {
+				lagging = fmt.Sprintf("%s (%ds)", replica, lag)
+				break
+			}
+		}
+		if lagging == "" {
+			if !pausedSince.IsZero() {
+				log.Infof("Resuming %s on %s after pausing for %s", what, instance, time.Since(pausedSince).Round(time.Second))
+			}
+			return nil
+		}
+		if pausedSince.IsZero() {
+			pausedSince = time.Now()
+			log.Infof("Pausing %s on %s: replica %s exceeds %s=%d", what, instance, lagging, lt.lagOption, lt.maxLag)
+		} else {
+			log.Debugf("Still pausing %s on %s: replica %s exceeds %s=%d", what, instance, lagging, lt.lagOption, lt.maxLag)
+		}
+		time.Sleep(lt.pollInterval)
+	}
+}
+
+// discoverReplicas returns host:port strings for each replica registered with
+// instance. Replicas which do not set report_host cannot be discovered.
+func discoverReplicas(instance *tengo.Instance) ([]string, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	query := "SHOW SLAVE HOSTS"
+	if flavor := instance.Flavor(); flavor.MinMySQL(8, 0, 22) {
+		query = "SHOW REPLICAS"
+	} else if flavor.MinMariaDB(10, 5, 1) {
+		query = "SHOW REPLICA HOSTS"
+	}
+	rows, err := db.Queryx(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var replicas []string
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := rows.MapScan(row); err != nil {
+			return nil, err
+		}
+		host, port := rowString(row["Host"]), rowString(row["Port"])
+		if host == "" {
+			log.Warnf("Ignoring a replica of %s for lag monitoring, since it does not set report_host", instance)
+			continue
+		}
+		replicas = append(replicas, net.JoinHostPort(host, port))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(replicas) == 0 {
+		return nil, errors.New("no replicas found")
+	}
+	return replicas, nil
+}
+
+func rowString(val interface{}) string {
+	switch val := val.(type) {
+	case []byte:
+		return string(val)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// instanceForAddress returns an Instance for addr, which should be a
+// host[:port] string, using the same credentials and connection parameters as
+// instance.
+func instanceForAddress(instance *tengo.Instance, addr string) (*tengo.Instance, error) {
+	host, port, err := tengo.SplitHostOptionalPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if port == 0 {
+		port = 3306
+	}
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/?%s", instance.User, instance.Password, net.JoinHostPort(host, strconv.Itoa(port)), instance.BuildParamString(""))
+	other, err := util.NewInstance("mysql", dsn)
+	if err == nil {
+		util.InheritIAMAuth(instance, other)
+	}
+	return other, err
+}
+
+// replicaLag returns the replication lag in seconds of replica, which should
+// be a host[:port] string. The same credentials as instance are used.
+func replicaLag(instance *tengo.Instance, replica string) (int, error) {
+	replicaInst, err := instanceForAddress(instance, replica)
+	if err != nil {
+		return 0, err
+	}
+	db, err := replicaInst.CachedConnectionPool("", "")
+	if err != nil {
+		return 0, err
+	}
+	query := "SHOW SLAVE STATUS"
+	if flavor := replicaInst.Flavor(); flavor.MinMySQL(8, 0, 22) || flavor.MinMariaDB(10, 5, 1) {
+		query = "SHOW REPLICA STATUS"
+	}
+	rows, err := db.Queryx(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, errors.New("server is not a replica")
+	}
+	status := make(map[string]interface{})
+	if err := rows.MapScan(status); err != nil {
+		return 0, err
+	}
+	for _, col := range []string{"Seconds_Behind_Source", "Seconds_Behind_Master"} {
+		if val, ok := status[col]; ok {
+			if raw, ok := val.([]byte); ok {
+				return strconv.Atoi(string(raw))
+			}
+			return 0, errors.New("replication is not running")
+		}
+	}
+	return 0, errors.New("unable to determine replication lag")
+}
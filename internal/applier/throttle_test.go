@@ -0,0 +1,72 @@
+package applier
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+func TestNewLagThrottler(t *testing.T) {
+	cases := []struct {
+		maxLag         string
+		replicas       string
+		expectErr      bool
+		expectEnabled  bool
+		expectDiscover bool
+		expectReplicas int
+	}{
+		{"0", "", false, false, false, 0},
+		{"10", "", false, false, false, 0},
+		{"0", "replica1,replica2:3307", false, false, false, 2},
+		{"10", "replica1,replica2:3307", false, true, false, 2},
+		{"10", "auto", false, true, true, 0},
+		{"10", "AUTO", false, true, true, 0},
+		{"-1", "replica1", true, false, false, 0},
+		{"ten", "replica1", true, false, false, 0},
+	}
+	for n, c := range cases {
+		cfg := mybase.SimpleConfig(map[string]string{
+			"max-replica-lag": c.maxLag,
+			"replicas":        c.replicas,
+		})
+		lt, err := newLagThrottler(cfg, "max-replica-lag", "replicas")
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("Case %d: Expected error, but err was nil", n)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Case %d: Unexpected error: %v", n, err)
+			continue
+		}
+		if lt.enabled() != c.expectEnabled || lt.discover != c.expectDiscover || len(lt.replicas) != c.expectReplicas {
+			t.Errorf("Case %d: Unexpected result %+v", n, *lt)
+		}
+		if !lt.enabled() {
+			if err := lt.wait(nil, "push"); err != nil {
+				t.Errorf("Case %d: Unexpected error from wait on disabled throttler: %v", n, err)
+			}
+		}
+	}
+
+	// A nil throttler is never enabled
+	var lt *lagThrottler
+	if lt.enabled() {
+		t.Error("Expected nil lagThrottler to not be enabled")
+	} else if err := lt.wait(nil, "push"); err != nil {
+		t.Errorf("Unexpected error from wait on nil throttler: %v", err)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestLagThrottlerErrors(t *testing.T) {
+	// The test instance has no replicas, and is not a replica itself
+	if _, err := discoverReplicas(s.d[0].Instance); err == nil {
+		t.Error("Expected error from discoverReplicas, but it was nil")
+	}
+	lt := &lagThrottler{maxLag: 1, replicas: []string{net.JoinHostPort(s.d[0].Instance.Host, strconv.Itoa(s.d[0].Instance.Port))}}
+	if err := lt.wait(s.d[0].Instance, "push"); err == nil {
+		t.Error("Expected error from wait when replica is not actually a replica, but it was nil")
+	}
+}
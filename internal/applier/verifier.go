@@ -1,9 +1,13 @@
 package applier
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
@@ -32,6 +36,16 @@ func VerifierOptionsForTarget(t *Target) (opts VerifierOptions, err error) {
 	return
 }
 
+// verifiedDiffs caches successful VerifyDiff results for the duration of the
+// process. In schema-per-tenant environments, many targets typically have
+// identical diffs, which only need to be verified once. Keys are a hash of the
+// verification inputs, and values are the names of tables which had an
+// unsupported diff marked as supported by verification.
+var verifiedDiffs = struct {
+	sync.Mutex
+	m map[string][]string
+}{m: make(map[string][]string)}
+
 // VerifyDiff verifies the result of AlterTable values found in diff.TableDiffs,
 // confirming that applying the corresponding ALTER would bring a table from the
 // version currently in the instance to the version specified in the filesystem.
@@ -70,6 +84,7 @@ func VerifyDiff(altersInDiff []*tengo.TableDiff, vopts VerifierOptions) error {
 	logicalSchema.Collation = vopts.DefaultCollation
 	desiredTables := make(map[string]*tengo.Table)
 	unsupportedTables := make(map[string]*tengo.TableDiff)
+	var keyParts []string
 	for _, td := range altersInDiff {
 		stmt, err := td.Statement(mods)
 		if stmt == "" {
@@ -77,6 +92,7 @@ func VerifyDiff(altersInDiff []*tengo.TableDiff, vopts VerifierOptions) error {
 		} else if tengo.IsUnsupportedDiff(err) {
 			unsupportedTables[td.From.Name] = td
 		}
+		keyParts = append(keyParts, td.From.CreateStatement+"\x00"+td.To.CreateStatement)
 
 		// Note: sometimes a table's diff gets split into multiple ALTERs, but this
 		// logic can ignore that fact. If there are redundant AddStatement calls for
@@ -97,10 +113,21 @@ func VerifyDiff(altersInDiff []*tengo.TableDiff, vopts VerifierOptions) error {
 		desiredTables[td.From.Name] = td.To
 	}
 
-	// Return early if nothing to verify
+	// Return early if nothing to verify, or if an identical diff was already
+	// verified successfully
 	if len(desiredTables) == 0 {
 		return nil
 	}
+	key := verifyCacheKey(keyParts, vopts)
+	verifiedDiffs.Lock()
+	supported, alreadyVerified := verifiedDiffs.m[key]
+	verifiedDiffs.Unlock()
+	if alreadyVerified {
+		for _, name := range supported {
+			unsupportedTables[name].MarkSupported()
+		}
+		return nil
+	}
 
 	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, vopts.WorkspaceOptions)
 	if err == nil && len(wsSchema.Failures) > 0 {
@@ -122,19 +149,55 @@ func VerifyDiff(altersInDiff []*tengo.TableDiff, vopts VerifierOptions) error {
 	mods.StrictColumnDefinition = false
 	mods.AlgorithmClause = ""
 	actualTables := wsSchema.TablesByName()
+	supported = []string{}
 	for name, desiredTable := range desiredTables {
 		// If an unsupported diff passes verification, mark it as supported, but
 		// otherwise we can just ignore any error from an unsupported diff.
 		td, wasUnsupported := unsupportedTables[name]
 		if err := verifyTable(actualTables[name], desiredTable, mods); err == nil && wasUnsupported {
 			td.MarkSupported()
+			supported = append(supported, name)
 		} else if err != nil && !wasUnsupported {
 			return err
 		}
 	}
+	verifiedDiffs.Lock()
+	verifiedDiffs.m[key] = supported
+	verifiedDiffs.Unlock()
 	return nil
 }
 
+// verifyCacheKey returns a key for verifiedDiffs. The supplied parts describe
+// the CREATE statements on each side of each table diff being verified, and
+// are sorted in-place, since diffs of identical schemas may be ordered
+// differently. The key also covers the workspace options, since settings such as sql_mode (via DefaultConnParams),
+// lower_case_table_names, or Docker server args can affect the outcome of
+// verification. Options which only affect workspace lifecycle or performance
+// are excluded, to keep caching effective across targets.
+func verifyCacheKey(parts []string, vopts VerifierOptions) string {
+	slices.Sort(parts)
+	wsOpts := vopts.WorkspaceOptions
+	var wsInstance string
+	if wsOpts.Instance != nil {
+		wsInstance = wsOpts.Instance.String()
+		wsOpts.Instance = nil
+	}
+	wsOpts.CleanupAction = 0
+	wsOpts.IdleExpiry = 0
+	wsOpts.KubeStartTimeout = 0
+	wsOpts.KubeMaxLifetime = 0
+	wsOpts.LockTimeout = 0
+	wsOpts.Concurrency = 0
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", vopts.Flavor, vopts.DefaultCharacterSet, vopts.DefaultCollation)
+	fmt.Fprintf(h, "%s\x00%+v\x00", wsInstance, wsOpts)
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // verifyTable confirms that a table has the expected structure by doing an
 // additional diff. Typically this diff will return quickly based on SHOW CREATE
 // TABLE matching, but if they don't match (as happens with some MySQL 8 edge-
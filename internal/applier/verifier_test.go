@@ -0,0 +1,51 @@
+package applier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestVerifyCacheKey(t *testing.T) {
+	base := VerifierOptions{
+		Flavor:              tengo.ParseFlavor("mysql:8.0"),
+		DefaultCharacterSet: "utf8mb4",
+		DefaultCollation:    "utf8mb4_0900_ai_ci",
+		WorkspaceOptions: workspace.Options{
+			Type:              workspace.TypeLocalDocker,
+			Flavor:            tengo.ParseFlavor("mysql:8.0"),
+			DefaultConnParams: "sql_mode=%27STRICT_ALL_TABLES%27",
+			NameCaseMode:      tengo.NameCaseAsIs,
+		},
+	}
+	parts := []string{"b", "a"}
+	key := verifyCacheKey(parts, base)
+	if parts[0] != "a" {
+		t.Errorf("Expected parts to be sorted in-place, instead found %v", parts)
+	}
+
+	// Options which only affect workspace lifecycle should not change the key
+	vopts := base
+	vopts.WorkspaceOptions.LockTimeout = 30 * time.Second
+	vopts.WorkspaceOptions.Concurrency = 10
+	if newKey := verifyCacheKey([]string{"a", "b"}, vopts); newKey != key {
+		t.Error("Expected lifecycle-related workspace options to be excluded from cache key, but key changed")
+	}
+
+	// Options which can affect verification results should change the key
+	mutators := map[string]func(*workspace.Options){
+		"DefaultConnParams": func(o *workspace.Options) { o.DefaultConnParams = "sql_mode=%27%27" },
+		"NameCaseMode":      func(o *workspace.Options) { o.NameCaseMode = tengo.NameCaseLower },
+		"ServerArgs":        func(o *workspace.Options) { o.ServerArgs = []string{"--innodb-strict-mode=0"} },
+		"Type":              func(o *workspace.Options) { o.Type = workspace.TypeTempSchema },
+	}
+	for name, mutate := range mutators {
+		vopts := base
+		mutate(&vopts.WorkspaceOptions)
+		if newKey := verifyCacheKey([]string{"a", "b"}, vopts); newKey == key {
+			t.Errorf("Expected change to workspace option %s to change cache key, but it did not", name)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package dumper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// ObjectChange describes an object whose normalized definition differs
+// between a schema and its filesystem representation.
+type ObjectChange struct {
+	Key      tengo.ObjectKey
+	Type     tengo.DiffType // DiffTypeCreate if only in schema; DiffTypeDrop if only in filesystem; DiffTypeAlter otherwise
+	FilePath string         // path of the file which contains, or would contain, the object's CREATE
+}
+
+// ChangedObjects compares per-object checksums of the normalized definitions
+// in schema to those of the CREATE statements in dir, returning the objects
+// which differ, sorted by key. Objects ignored by opts are excluded. The
+// schema's definitions are adjusted for opts in the same manner as DumpSchema.
+// Definitions from both sources are then normalized to ignore line endings and
+// trailing whitespace, so that a file which merely differs in these respects
+// (for example due to a checkout with CRLF line endings) is not considered
+// changed. No filesystem writes occur.
+func ChangedObjects(schema *tengo.Schema, dir *fs.Dir, opts Options) []ObjectChange {
+	// TODO: handle dirs that contain multiple logical schemas by name
+	logicalSchema := dir.LogicalSchemas[0]
+
+	var changes []ObjectChange
+	dbObjects := schema.Objects()
+	for key, object := range dbObjects {
+		if opts.shouldIgnore(object) {
+			continue
+		}
+		stmt := logicalSchema.Creates[key]
+		if stmt == nil {
+			changes = append(changes, ObjectChange{Key: key, Type: tengo.DiffTypeCreate, FilePath: dir.FileFor(object).FilePath})
+			continue
+		}
+		fsCreate, _ := stmt.SplitTextBody()
+		if definitionChecksum(fsCreate) != definitionChecksum(canonicalCreateFor(object, fsCreate, opts)) {
+			changes = append(changes, ObjectChange{Key: key, Type: tengo.DiffTypeAlter, FilePath: stmt.File})
+		}
+	}
+	for key, stmt := range logicalSchema.Creates {
+		if _, inDB := dbObjects[key]; !inDB && !opts.shouldIgnore(key) {
+			changes = append(changes, ObjectChange{Key: key, Type: tengo.DiffTypeDrop, FilePath: stmt.File})
+		}
+	}
+	slices.SortFunc(changes, func(a, b ObjectChange) int {
+		return strings.Compare(a.Key.String(), b.Key.String())
+	})
+	return changes
+}
+
+// definitionChecksum returns a hex-encoded SHA-256 checksum of def, after
+// normalizing line endings and removing trailing whitespace from each line.
+func definitionChecksum(def string) string {
+	lines := strings.Split(strings.ReplaceAll(def, "\r\n", "\n"), "\n")
+	for n := range lines {
+		lines[n] = strings.TrimRight(lines[n], " \t")
+	}
+	sum := sha256.Sum256([]byte(strings.TrimRight(strings.Join(lines, "\n"), "\n")))
+	return hex.EncodeToString(sum[:])
+}
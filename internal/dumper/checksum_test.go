@@ -0,0 +1,69 @@
+package dumper
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestChangedObjects(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.36")
+	creates := map[string]string{
+		"unchanged": "CREATE TABLE `unchanged` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+		"crlf":      "CREATE TABLE `crlf` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+		"modified":  "CREATE TABLE `modified` (\n  `id` int NOT NULL,\n  `name` varchar(30) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+		"autoinc":   "CREATE TABLE `autoinc` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB AUTO_INCREMENT=123 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+		"added":     "CREATE TABLE `added` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+	}
+	schema := &tengo.Schema{Name: "checksumtest", CharSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}
+	for _, create := range creates {
+		table, err := tengo.ParseCreateTable(create, flavor, "", "")
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+		}
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	// Write the filesystem representation: "crlf" differs only in line endings;
+	// "modified" lacks a column; "autoinc" lacks the AUTO_INCREMENT clause, which
+	// is ignored without IncludeAutoInc; "added" does not exist; and "removed"
+	// only exists in the filesystem.
+	dirPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=checksumtest\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "unchanged.sql"), creates["unchanged"]+";\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "crlf.sql"), strings.ReplaceAll(creates["crlf"], "\n", "\r\n")+";\r\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "modified.sql"), strings.Replace(creates["modified"], "  `name` varchar(30) DEFAULT NULL,\n", "", 1)+";\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "autoinc.sql"), strings.Replace(creates["autoinc"], " AUTO_INCREMENT=123", "", 1)+";\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "removed.sql"), "CREATE TABLE removed (id int);\n")
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+
+	changes := ChangedObjects(schema, dir, Options{})
+	expected := []ObjectChange{
+		{Key: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "added"}, Type: tengo.DiffTypeCreate, FilePath: filepath.Join(dirPath, "added.sql")},
+		{Key: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "modified"}, Type: tengo.DiffTypeAlter, FilePath: filepath.Join(dirPath, "modified.sql")},
+		{Key: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "removed"}, Type: tengo.DiffTypeDrop, FilePath: filepath.Join(dirPath, "removed.sql")},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, instead found %d: %+v", len(expected), len(changes), changes)
+	}
+	for n := range changes {
+		if changes[n] != expected[n] {
+			t.Errorf("changes[%d]: expected %+v, instead found %+v", n, expected[n], changes[n])
+		}
+	}
+
+	// With IncludeAutoInc, the autoinc table is also changed. Ignored keys are
+	// excluded from the result.
+	opts := Options{IncludeAutoInc: true}
+	opts.IgnoreKeys([]tengo.ObjectKey{{Type: tengo.ObjectTypeTable, Name: "added"}})
+	changes = ChangedObjects(schema, dir, opts)
+	if len(changes) != 3 || changes[0].Key.Name != "autoinc" || changes[1].Key.Name != "modified" || changes[2].Key.Name != "removed" {
+		t.Errorf("Unexpected result from ChangedObjects with IncludeAutoInc: %+v", changes)
+	}
+}
@@ -71,33 +71,12 @@ func updateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) err
 		if opts.shouldIgnore(object) {
 			continue
 		}
-		canonicalCreate := object.Def()
 		var fsCreate string
 		stmt := logicalSchema.Creates[key]
 		if stmt != nil {
 			fsCreate, _ = stmt.SplitTextBody()
 		}
-
-		// Include or strip auto_increment clause. (Note that if fs representation
-		// already exists and explicitly had an autoinc value > 1, we keep and update
-		// it regardless.)
-		if key.Type == tengo.ObjectTypeTable && !opts.IncludeAutoInc {
-			if _, fsAutoInc := tengo.ParseCreateAutoInc(fsCreate); fsAutoInc <= 1 {
-				canonicalCreate, _ = tengo.ParseCreateAutoInc(canonicalCreate)
-			}
-		}
-
-		// If requested, adjust the canonical create to add the partitioning clause
-		// from the filesystem create, or remove it
-		if key.Type == tengo.ObjectTypeTable && opts.Partitioning != tengo.PartitioningPermissive {
-			dbCreateBase, _ := tengo.ParseCreatePartitioning(canonicalCreate)
-			if opts.Partitioning == tengo.PartitioningKeep && fsCreate != "" {
-				_, fsCreatePart := tengo.ParseCreatePartitioning(fsCreate)
-				canonicalCreate = dbCreateBase + fsCreatePart
-			} else if opts.Partitioning == tengo.PartitioningRemove {
-				canonicalCreate = dbCreateBase
-			}
-		}
+		canonicalCreate := canonicalCreateFor(object, fsCreate, opts)
 
 		newStmt := tengo.ParseStatementInString(canonicalCreate)
 		if newStmt.Type != tengo.StatementTypeCreate || newStmt.ObjectKey() != key {
@@ -141,3 +120,35 @@ func updateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) err
 
 	return nil
 }
+
+// canonicalCreateFor returns the CREATE statement which should be dumped for
+// object, given its current filesystem representation fsCreate (which may be
+// blank if the object does not exist in the filesystem yet) and opts.
+func canonicalCreateFor(object tengo.DefKeyer, fsCreate string, opts Options) string {
+	canonicalCreate := object.Def()
+	if object.ObjectKey().Type != tengo.ObjectTypeTable {
+		return canonicalCreate
+	}
+
+	// Include or strip auto_increment clause. (Note that if fs representation
+	// already exists and explicitly had an autoinc value > 1, we keep and update
+	// it regardless.)
+	if !opts.IncludeAutoInc {
+		if _, fsAutoInc := tengo.ParseCreateAutoInc(fsCreate); fsAutoInc <= 1 {
+			canonicalCreate, _ = tengo.ParseCreateAutoInc(canonicalCreate)
+		}
+	}
+
+	// If requested, adjust the canonical create to add the partitioning clause
+	// from the filesystem create, or remove it
+	if opts.Partitioning != tengo.PartitioningPermissive {
+		dbCreateBase, _ := tengo.ParseCreatePartitioning(canonicalCreate)
+		if opts.Partitioning == tengo.PartitioningKeep && fsCreate != "" {
+			_, fsCreatePart := tengo.ParseCreatePartitioning(fsCreate)
+			canonicalCreate = dbCreateBase + fsCreatePart
+		} else if opts.Partitioning == tengo.PartitioningRemove {
+			canonicalCreate = dbCreateBase
+		}
+	}
+	return canonicalCreate
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -181,8 +182,12 @@ func (dir *Dir) CreateOptionFile(optionFile *mybase.File) (err error) {
 // Hostnames returns 0 or more hosts that the directory maps to. This properly
 // handles the host option being set to a comma-separated list of multiple
 // hosts, or the host-wrapper option being used to shell out to an external
-// script to obtain hosts.
+// script to obtain hosts. Any resulting host values which refer to a service
+// discovery source, such as "srv:_mysql._tcp.example.com", are replaced with
+// the hosts obtained from that source; an error is returned if a source yields
+// no hosts, unless the host-discovery-allow-empty option is enabled.
 func (dir *Dir) Hostnames() ([]string, error) {
+	var hosts []string
 	if dir.Config.Changed("host-wrapper") {
 		variables := map[string]string{
 			"HOST":        dir.Config.GetAllowEnvVar("host"),
@@ -195,9 +200,80 @@ func (dir *Dir) Hostnames() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		return shellOut.RunCaptureSplit()
+		if hosts, err = dir.runHostWrapper(shellOut); err != nil {
+			return nil, err
+		}
+	} else {
+		hosts = dir.Config.GetSliceAllowEnvVar("host", ',', true)
+	}
+
+	var result []string
+	for _, host := range hosts {
+		if !util.IsDiscoveryReference(host) {
+			result = append(result, host)
+			continue
+		}
+		ttl, err := time.ParseDuration(dir.Config.Get("host-discovery-ttl"))
+		if err != nil {
+			return nil, ConfigErrorf("Invalid value for option host-discovery-ttl: %w", err)
+		}
+		discovered, err := util.DiscoverHosts(host, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if len(discovered) == 0 && !dir.Config.GetBool("host-discovery-allow-empty") {
+			return nil, fmt.Errorf("No hosts found for %s. If this is expected, enable option host-discovery-allow-empty to permit this.", host)
+		}
+		log.Debugf("Discovered %d hosts from %s: %s", len(discovered), host, strings.Join(discovered, ", "))
+		result = append(result, discovered...)
+	}
+	return result, nil
+}
+
+// runHostWrapper executes the host-wrapper command, or obtains its output from
+// the cache if the host-wrapper-ttl option is set, and returns the resulting
+// hosts. The output may be a delimited list of hosts, or a JSON array of
+// objects which may include labels; in the latter case, entries are filtered
+// by the host-filter option.
+func (dir *Dir) runHostWrapper(shellOut *shellout.Command) ([]string, error) {
+	ttl, err := time.ParseDuration(dir.Config.Get("host-wrapper-ttl"))
+	if err != nil {
+		return nil, ConfigErrorf("Invalid value for option host-wrapper-ttl: %w", err)
+	}
+	filter, err := util.ParseHostFilter(dir.Config.Get("host-filter"))
+	if err != nil {
+		return nil, ConfigErrorf("Invalid value for option host-filter: %w", err)
+	}
+
+	commandLine := shellOut.String()
+	output, cached := "", false
+	if ttl > 0 {
+		output, cached = util.ReadHostWrapperCache(commandLine, ttl)
+	}
+	if cached {
+		log.Debugf("Using cached host-wrapper output for %s", dir)
+	} else if output, err = shellOut.RunCapture(); err != nil {
+		return nil, err
+	}
+	entries, isJSON, err := util.ParseHostWrapperOutput(output)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid output from host-wrapper: %w", err)
+	} else if len(filter) > 0 && !isJSON {
+		return nil, ConfigErrorf("Option host-filter requires host-wrapper to output a JSON array of host objects")
+	}
+	if ttl > 0 && !cached {
+		if err := util.WriteHostWrapperCache(commandLine, output); err != nil {
+			log.Warn(err)
+		}
 	}
-	return dir.Config.GetSliceAllowEnvVar("host", ',', true), nil
+
+	hosts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Matches(filter) {
+			hosts = append(hosts, entry.String())
+		}
+	}
+	return hosts, nil
 }
 
 // Port returns the port number in the directory's configuration (often the
@@ -281,21 +357,53 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 
 	// Before looping over hostnames, do a single lookup of user, password,
 	// connect-options, port, socket.
-	user := dir.Config.GetAllowEnvVar("user")
-	password, err := dir.Password(hosts...)
+	// With aws-iam-auth, gcp-iam-auth, or azure-ad-auth, no static password is
+	// used; instead each Instance generates auth tokens as needed.
+	// With credential-helper, the user and password are obtained separately for
+	// each host.
+	user, err := dir.User()
 	if err != nil {
-		return nil, err // for example, need interactive password but STDIN isn't a TTY
+		return nil, err
 	}
-	var userAndPass string
-	if password == "" {
-		userAndPass = user
-	} else {
-		userAndPass = user + ":" + password
+	var iamAuth string // name of the IAM auth option in use, if any
+	for _, optionName := range []string{"aws-iam-auth", "gcp-iam-auth", "azure-ad-auth"} {
+		if !dir.Config.GetBool(optionName) {
+			continue
+		} else if iamAuth != "" {
+			return nil, ConfigErrorf("Options %s and %s cannot be used together", iamAuth, optionName)
+		}
+		iamAuth = optionName
+	}
+	credentialHelper := dir.Config.Get("credential-helper")
+	if credentialHelper != "" && iamAuth != "" {
+		return nil, ConfigErrorf("Options credential-helper and %s cannot be used together", iamAuth)
+	}
+	var password string
+	if iamAuth == "" && credentialHelper == "" {
+		password, err = dir.Password(hosts...)
+		if err != nil {
+			return nil, err // for example, need interactive password but STDIN isn't a TTY
+		}
 	}
 	params, err := dir.InstanceDefaultParams()
 	if err != nil {
 		return nil, ConfigErrorf("Invalid connection options: %w", err)
 	}
+	poolOpts, err := dir.InstancePoolOptions()
+	if err != nil {
+		return nil, err
+	}
+	if iamAuth != "" {
+		if params, err = tlsRequiredParams(params, "Option "+iamAuth, true); err != nil {
+			return nil, err
+		}
+	}
+	var azureCredential string
+	if iamAuth == "azure-ad-auth" {
+		if azureCredential, err = dir.Config.GetEnum("azure-credential", util.AzureCredentialTypes...); err != nil {
+			return nil, ConfigError{err}
+		}
+	}
 	portValue, portWasSupplied := dir.Port()
 	socketValue := dir.Config.GetAllowEnvVar("socket")
 	socketWasSupplied := dir.Config.Supplied("socket")
@@ -305,8 +413,28 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 	for _, host := range hosts {
 		var net, addr string
 		thisPortValue := portValue
+		hostParams := params
 		if host == "localhost" && (socketWasSupplied || !portWasSupplied) {
+			if iamAuth != "" {
+				return nil, ConfigErrorf("Option %s cannot be used with a UNIX domain socket connection", iamAuth)
+			}
 			net, addr = "unix", socketValue
+		} else if util.IsCloudSQLInstanceName(host) {
+			// Cloud SQL instance connection names are resolved to an IP address, and
+			// connections to them always use TLS
+			ipType, err := dir.Config.GetEnum("gcp-ip-type", "public", "private")
+			if err != nil {
+				return nil, ConfigError{err}
+			}
+			ip, err := util.CloudSQLAddress(host, ipType == "private")
+			if err != nil {
+				return nil, err
+			}
+			log.Debugf("Resolved Cloud SQL instance %s to %s IP address %s", host, ipType, ip)
+			if hostParams, err = tlsRequiredParams(params, "Cloud SQL instance connection name "+host, false); err != nil {
+				return nil, err
+			}
+			net, addr = "tcp", fmt.Sprintf("%s:%d", ip, thisPortValue)
 		} else {
 			splitHost, splitPort, err := tengo.SplitHostOptionalPort(host)
 			if err != nil {
@@ -321,15 +449,46 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 			}
 			net, addr = "tcp", fmt.Sprintf("%s:%d", host, thisPortValue)
 		}
-		dsn := fmt.Sprintf("%s@%s(%s)/?%s", userAndPass, net, addr, params)
+		hostUser, hostPassword := user, password
+		if credentialHelper != "" {
+			variables := map[string]string{
+				"HOST":        host,
+				"PORT":        strconv.Itoa(thisPortValue),
+				"USER":        user,
+				"ENVIRONMENT": dir.Config.Get("environment"),
+				"DIRNAME":     dir.BaseName(),
+				"DIRPATH":     dir.Path,
+			}
+			helperUser, helperPassword, err := util.CredentialHelper(credentialHelper, variables)
+			if err != nil {
+				return nil, err
+			}
+			if helperUser != "" {
+				hostUser = helperUser
+			}
+			hostPassword = helperPassword
+		}
+		userAndPass := hostUser
+		if hostPassword != "" {
+			userAndPass += ":" + hostPassword
+		}
+		dsn := fmt.Sprintf("%s@%s(%s)/?%s", userAndPass, net, addr, hostParams)
 		instance, err := util.NewInstance("mysql", dsn)
 		if err != nil {
-			if password != "" {
-				safeUserPass := user + ":*****"
+			if hostPassword != "" {
+				safeUserPass := hostUser + ":*****"
 				dsn = strings.Replace(dsn, userAndPass, safeUserPass, 1)
 			}
 			return nil, ConfigErrorf("Invalid connection information for %s (DSN=%s): %w", dir, dsn, err)
 		}
+		if iamAuth == "aws-iam-auth" {
+			util.EnableRDSAuth(instance, dir.Config.Get("aws-region"), dir.Config.Get("aws-profile"))
+		} else if iamAuth == "gcp-iam-auth" {
+			util.EnableCloudSQLAuth(instance)
+		} else if iamAuth == "azure-ad-auth" {
+			util.EnableAzureADAuth(instance, azureCredential)
+		}
+		instance.SetPoolOptions(poolOpts)
 		instances = append(instances, instance)
 	}
 	return instances, nil
@@ -367,7 +526,13 @@ func (dir *Dir) FirstInstance() (*tengo.Instance, error) {
 func (dir *Dir) ValidateInstance(instance *tengo.Instance) error {
 	ok, err := instance.Valid()
 	if !ok {
-		if instance.Password == "" && tengo.IsAccessDeniedError(err) {
+		if instance.Password == "" && tengo.IsAccessDeniedError(err) && dir.Config.GetBool("aws-iam-auth") {
+			err = fmt.Errorf("%w\nThis login attempt used an RDS IAM auth token. Confirm that IAM database authentication is enabled on the server, the database user was created with the AWSAuthenticationPlugin, and your AWS identity has rds-db:connect permission for this user.", err)
+		} else if instance.Password == "" && tengo.IsAccessDeniedError(err) && dir.Config.GetBool("gcp-iam-auth") {
+			err = fmt.Errorf("%w\nThis login attempt used a Cloud SQL IAM auth token. Confirm that the cloudsql_iam_authentication flag is enabled on the instance, the database user is an IAM user or service account, and the active gcloud account matches this user.", err)
+		} else if instance.Password == "" && tengo.IsAccessDeniedError(err) && dir.Config.GetBool("azure-ad-auth") {
+			err = fmt.Errorf("%w\nThis login attempt used a Microsoft Entra ID access token. Confirm that the server has a Microsoft Entra admin configured, and that the user option matches the Entra ID user, group, or managed identity name granted access on the server.", err)
+		} else if instance.Password == "" && tengo.IsAccessDeniedError(err) {
 			err = fmt.Errorf("%w\nNo password was supplied for this login attempt, but the server likely requires a password. For information on how to use Skeema's password option, see https://www.skeema.io/docs/options/#password", err)
 		} else if dir.Config.Changed("connect-options") {
 			if tengo.IsAccessPrivilegeError(err) {
@@ -432,11 +597,17 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 
 	rawSchemaValue := dir.Config.GetRaw("schema")                  // Does not strip quotes
 	if rawSchemaValue != schemaValue && rawSchemaValue[0] == '`' { // no need to check len: since non-raw value isn't empty, raw value can't be empty
+		// The instance's user and password reflect any values obtained from an
+		// external secret store, credential helper, or IAM auth token
+		password, err := instance.CurrentPassword()
+		if err != nil {
+			return nil, err
+		}
 		variables := map[string]string{
 			"HOST":        instance.Host,
 			"PORT":        strconv.Itoa(instance.Port),
-			"USER":        dir.Config.GetAllowEnvVar("user"),
-			"PASSWORD":    dir.Config.GetAllowEnvVar("password"),
+			"USER":        instance.User,
+			"PASSWORD":    password,
 			"ENVIRONMENT": dir.Config.Get("environment"),
 			"DIRNAME":     dir.BaseName(),
 			"DIRPATH":     dir.Path,
@@ -473,7 +644,25 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 			names = keepNames
 		}
 	} else {
-		names = dir.Config.GetSliceAllowEnvVar("schema", ',', true)
+		if isSchemaTemplate(schemaValue) {
+			if names, err = expandSchemaTemplate(schemaValue, dir.Config.GetAllowEnvVar("schema-range")); err != nil {
+				return nil, ConfigError{err}
+			}
+		} else {
+			names = dir.Config.GetSliceAllowEnvVar("schema", ',', true)
+		}
+		// Names configured explicitly are logical names, which may be mapped to
+		// different physical names in the current environment. (Names obtained
+		// from the instance or a shellout are already physical names.)
+		mapping, err := dir.SchemaNameMap()
+		if err != nil {
+			return nil, err
+		}
+		for n, name := range names {
+			if physical, ok := mapping[name]; ok {
+				names[n] = physical
+			}
+		}
 	}
 
 	// Remove ignored schemas and system schemas. (tengo removes the latter from
@@ -496,6 +685,31 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 	return names, nil
 }
 
+// SchemaNameMap returns a map of logical schema names to physical schema names,
+// as configured by the schema-map option. This option is typically set in
+// environment-specific sections of option files, for example to map logical
+// schema billing to physical schema billing_stage in a staging environment.
+// Logical names are those used in the schema option and in *.sql files; any
+// name not present in the map is the same in both forms.
+func (dir *Dir) SchemaNameMap() (map[string]string, error) {
+	mapping := make(map[string]string)
+	seenPhysical := make(map[string]bool)
+	for _, pair := range dir.Config.GetSlice("schema-map", ',', true) {
+		logical, physical, ok := strings.Cut(pair, "=")
+		logical, physical = strings.TrimSpace(logical), strings.TrimSpace(physical)
+		if !ok || logical == "" || physical == "" {
+			return nil, ConfigErrorf("Invalid value for option schema-map: %q is not in logical=physical format", pair)
+		} else if _, already := mapping[logical]; already {
+			return nil, ConfigErrorf("Invalid value for option schema-map: schema %s is mapped more than once", logical)
+		} else if seenPhysical[physical] {
+			return nil, ConfigErrorf("Invalid value for option schema-map: multiple schemas are mapped to %s", physical)
+		}
+		mapping[logical] = physical
+		seenPhysical[physical] = true
+	}
+	return mapping, nil
+}
+
 func looksLikeRegex(input string) bool {
 	return len(input) > 2 && input[0] == '/' && input[len(input)-1] == '/'
 }
@@ -577,8 +791,13 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	v := url.Values{}
 
 	// Set overridable options
-	v.Set("timeout", "5s")
-	v.Set("readTimeout", "20s")
+	for optionName, paramName := range map[string]string{"connect-timeout": "timeout", "read-timeout": "readTimeout"} {
+		value := dir.Config.Get(optionName)
+		if _, err := time.ParseDuration(value); err != nil {
+			return "", ConfigErrorf("Invalid value for option %s: %w", optionName, err)
+		}
+		v.Set(paramName, value)
+	}
 	v.Set("writeTimeout", "5s")
 
 	// Prefer TLS, but not during integration testing
@@ -648,6 +867,48 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	return v.Encode(), nil
 }
 
+// InstancePoolOptions returns connection pool tuning options for Instances
+// of this dir, based on the max-open-conns, max-idle-conns, and
+// conn-max-lifetime options. An error is returned if any option value is
+// invalid.
+func (dir *Dir) InstancePoolOptions() (opts tengo.PoolOptions, err error) {
+	for optionName, dest := range map[string]*int{"max-open-conns": &opts.MaxOpenConns, "max-idle-conns": &opts.MaxIdleConns} {
+		if *dest, err = dir.Config.GetInt(optionName); err != nil {
+			return opts, ConfigError{err}
+		} else if *dest < 0 {
+			return opts, ConfigErrorf("Invalid value for option %s: must not be negative", optionName)
+		}
+	}
+	if opts.ConnMaxLifetime, err = time.ParseDuration(dir.Config.Get("conn-max-lifetime")); err != nil {
+		return opts, ConfigErrorf("Invalid value for option conn-max-lifetime: %w", err)
+	}
+	return opts, nil
+}
+
+// tlsRequiredParams adjusts a param string from InstanceDefaultParams to
+// require TLS, returning an error if TLS was explicitly disabled. The supplied
+// reason describes why TLS is required, for use in the error message. If
+// cleartextPasswords is true, the cleartext client plugin is also permitted;
+// this is used by cloud IAM authentication to send auth tokens, and is only
+// safe over TLS.
+func tlsRequiredParams(params, reason string, cleartextPasswords bool) (string, error) {
+	v, err := url.ParseQuery(params)
+	if err != nil {
+		return "", ConfigError{err}
+	}
+	switch v.Get("tls") {
+	case "false":
+		return "", ConfigErrorf("%s requires TLS, so it cannot be used with ssl-mode=disabled", reason)
+	case "preferred":
+		v.Set("tls", "skip-verify") // driver uses "skip-verify" to mean mysql ssl-mode=required
+	}
+	v.Del("allowFallbackToPlaintext")
+	if cleartextPasswords {
+		v.Set("allowCleartextPasswords", "true")
+	}
+	return v.Encode(), nil
+}
+
 // Generator returns the version and edition of Skeema used to init or most
 // most recently pull this dir's contents. If this cannot be determined, all
 // results will be zero values.
@@ -661,15 +922,26 @@ func (dir *Dir) Generator() (major, minor, patch int, edition string) {
 	return int(version.Major()), int(version.Minor()), int(version.Patch()), edition
 }
 
+// User returns the configured user in this dir. If the value refers to a
+// secret in an external secret store, such as "vault:path#key" or
+// "aws-sm:id#key", the secret is fetched. If "#key" is omitted from a reference
+// to a structured secret, key "username" is used.
+func (dir *Dir) User() (string, error) {
+	return util.ResolveSecret(dir.Config, dir.Config.GetAllowEnvVar("user"), "username")
+}
+
 // Package-level user@host interactive password cache, used by Dir.Password()
 var cachedInteractivePasswords = make(map[string]string)
 
 // Password returns the configured password in this dir, a cached password
 // from a previous interactive password check, or an interactively-prompted
 // password from STDIN if one should be obtained based on the directory's
-// configuration. If interactive input is requested and successful, the password
-// will be returned and also cached, so that subsequent identical requests
-// return the password without prompting.
+// configuration. If the configured password refers to a secret in an external
+// secret store, such as "vault:path#key" or "aws-sm:id#key", the secret is
+// fetched; if "#key" is omitted from a reference to a structured secret, key
+// "password" is used. If interactive input is requested and successful, the
+// password will be returned and also cached, so that subsequent identical
+// requests return the password without prompting.
 //
 // Optionally supply one or more hostnames to affect the behavior of interactive
 // password prompts and caching: with no hosts, the prompt text will mention the
@@ -686,7 +958,7 @@ func (dir *Dir) Password(hosts ...string) (string, error) {
 	// like other Config getters. This allows us to differentiate between "prompt
 	// on STDIN" and "intentionally no/blank password" situations.
 	if dir.Config.GetRaw("password") != "" {
-		return dir.Config.GetAllowEnvVar("password"), nil
+		return util.ResolveSecret(dir.Config, dir.Config.GetAllowEnvVar("password"), "password")
 	}
 
 	cacheKeys := make([]string, len(hosts))
@@ -696,7 +968,10 @@ func (dir *Dir) Password(hosts ...string) (string, error) {
 		// check will already have managed a previously-prompted password
 		promptArg = "directory " + dir.RelPath()
 	} else {
-		user := dir.Config.GetAllowEnvVar("user")
+		user, err := dir.User()
+		if err != nil {
+			return "", err
+		}
 		for n, host := range hosts {
 			cacheKeys[n] = user + "@" + host
 			if cachedPassword, ok := cachedInteractivePasswords[cacheKeys[n]]; ok {
@@ -1031,7 +1306,7 @@ func parseOptionFile(dirPath, repoBase string, baseConfig *mybase.Config) (*myba
 	if err := f.Parse(baseConfig); err != nil {
 		return nil, ConfigError{err}
 	}
-	_ = f.UseSection(baseConfig.Get("environment")) // we don't care if the section doesn't exist
+	_ = f.UseSection(util.OptionFileSections(baseConfig)...) // we don't care if the sections don't exist
 	return f, nil
 }
 
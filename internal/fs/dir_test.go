@@ -3,6 +3,8 @@ package fs
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/tengo"
@@ -65,6 +68,79 @@ func TestParseDir(t *testing.T) {
 	}
 }
 
+func TestParseDirProfiles(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoDir, ".git"), 0700); err != nil {
+		t.Fatalf("Unexpected error from Mkdir: %v", err)
+	}
+	WriteTestFile(t, filepath.Join(repoDir, ".skeema"), "lint=1\n[production]\nosc-tool=none\n[profile:osc]\nosc-tool=gh-ost\n[profile:fast]\nlint=0\nosc-tool=pt-osc\n")
+	WriteTestFile(t, filepath.Join(repoDir, "db", ".skeema"), "schema=product\n[production]\nhost=127.0.0.1\n[profile:fast]\nworkspace=docker\n")
+
+	cases := []struct {
+		cliOptions string
+		lint       bool
+		oscTool    string
+		workspace  string
+	}{
+		{"", true, "none", "temp-schema"},
+		{"--profile=osc", true, "gh-ost", "temp-schema"},
+		{"--profile=fast", false, "pt-osc", "docker"},
+		{"--profile=osc,fast", false, "gh-ost", "docker"},
+		{"--profile=fast,osc", false, "pt-osc", "docker"},
+		{"--profile=nonexistent", true, "none", "temp-schema"},
+		{"--profile=osc --osc-tool=builtin", true, "builtin", "temp-schema"},
+	}
+	for _, c := range cases {
+		cmd := mybase.NewCommand("fstest", "", "", nil)
+		util.AddGlobalOptions(cmd)
+		cmd.AddOption(mybase.BoolOption("lint", 0, true, "dummy"))
+		cmd.AddOption(mybase.StringOption("osc-tool", 0, "none", "dummy"))
+		cmd.AddOption(mybase.StringOption("workspace", 0, "temp-schema", "dummy"))
+		cmd.AddArg("environment", "production", false)
+		dir, err := ParseDir(filepath.Join(repoDir, "db"), mybase.ParseFakeCLI(t, cmd, "fstest "+c.cliOptions))
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDir with %q: %v", c.cliOptions, err)
+		}
+		if lint, oscTool, workspace := dir.Config.GetBool("lint"), dir.Config.Get("osc-tool"), dir.Config.Get("workspace"); lint != c.lint || oscTool != c.oscTool || workspace != c.workspace {
+			t.Errorf("With %q: expected lint=%t osc-tool=%s workspace=%s, instead found lint=%t osc-tool=%s workspace=%s", c.cliOptions, c.lint, c.oscTool, c.workspace, lint, oscTool, workspace)
+		}
+		if host := dir.Config.Get("host"); host != "127.0.0.1" {
+			t.Errorf("With %q: expected environment section to still apply, but host=%q", c.cliOptions, host)
+		}
+	}
+}
+
+func TestDirSchemaNameMap(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoDir, ".git"), 0700); err != nil {
+		t.Fatalf("Unexpected error from Mkdir: %v", err)
+	}
+	WriteTestFile(t, filepath.Join(repoDir, ".skeema"), "[staging]\nschema-map=billing=billing_stage, orders = orders_stage\n")
+	WriteTestFile(t, filepath.Join(repoDir, "db", ".skeema"), "schema=billing\n")
+
+	cases := map[string]string{
+		"production": "",
+		"staging":    "billing_stage orders_stage",
+	}
+	for environment, expected := range cases {
+		dir := getDirWithCLI(t, filepath.Join(repoDir, "db"), environment)
+		mapping, err := dir.SchemaNameMap()
+		if err != nil {
+			t.Fatalf("Unexpected error from SchemaNameMap in %s: %v", environment, err)
+		}
+		if actual := strings.TrimSpace(mapping["billing"] + " " + mapping["orders"]); actual != expected {
+			t.Errorf("Unexpected result from SchemaNameMap in %s: %v", environment, mapping)
+		}
+	}
+
+	for _, value := range []string{"billing", "billing=", "=billing_stage", "billing=a,billing=b", "billing=x,orders=x"} {
+		dir := getDirWithCLI(t, filepath.Join(repoDir, "db"), "--schema-map="+value)
+		if _, err := dir.SchemaNameMap(); err == nil {
+			t.Errorf("Expected error from SchemaNameMap with value %q, but err was nil", value)
+		}
+	}
+}
+
 func TestParseDirErrors(t *testing.T) {
 	// Confirm error cases: nonexistent dir; non-dir file; dir with *.sql files
 	// creating same table multiple times
@@ -633,6 +709,77 @@ func TestDirInstances(t *testing.T) {
 	assertInstances(map[string]string{"host": `"some.db.host, other.db.host"`, "port": "3307"}, false, "some.db.host:3307", "other.db.host:3307")
 	assertInstances(map[string]string{"host": "'some.db.host:3308', 'other.db.host'"}, false, "some.db.host:3308", "other.db.host:3306")
 
+	// aws-iam-auth requires TLS over TCP, and uses cleartext password plugin
+	iamOpts := map[string]string{"host": "mydb.abc123.us-east-1.rds.amazonaws.com", "aws-iam-auth": "1", "ssl-mode": "preferred", "flavor": "mysql:8.0"}
+	if instances := assertInstances(iamOpts, false, "mydb.abc123.us-east-1.rds.amazonaws.com:3306"); len(instances) == 1 {
+		params := instances[0].BuildParamString("")
+		if !strings.Contains(params, "allowCleartextPasswords=true") || !strings.Contains(params, "tls=skip-verify") {
+			t.Errorf("Unexpected params with aws-iam-auth: %s", params)
+		}
+	}
+	assertInstances(map[string]string{"host": "some.db.host", "aws-iam-auth": "1"}, true) // ssl-mode defaults to disabled in tests
+	assertInstances(map[string]string{"host": "localhost", "aws-iam-auth": "1", "ssl-mode": "required"}, true)
+	assertInstances(map[string]string{"host": "some.db.host", "aws-iam-auth": "1", "gcp-iam-auth": "1", "ssl-mode": "required"}, true)
+	iamOpts = map[string]string{"host": "10.1.2.3", "gcp-iam-auth": "1", "ssl-mode": "required", "flavor": "mysql:8.0"}
+	if instances := assertInstances(iamOpts, false, "10.1.2.3:3306"); len(instances) == 1 {
+		if params := instances[0].BuildParamString(""); !strings.Contains(params, "allowCleartextPasswords=true") {
+			t.Errorf("Unexpected params with gcp-iam-auth: %s", params)
+		}
+	}
+
+	// user and password may be fetched from Vault
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/database/creds/dirtest" {
+			w.Write([]byte(`{"data": {"username": "v-dirtest", "password": "s3cret"}}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+	vaultOpts := map[string]string{"host": "some.db.host", "user": "vault:database/creds/dirtest", "password": "vault:database/creds/dirtest"}
+	if instances := assertInstances(vaultOpts, false, "some.db.host:3306"); len(instances) == 1 {
+		if instances[0].User != "v-dirtest" || instances[0].Password != "s3cret" {
+			t.Errorf("Unexpected credentials from Vault: user=%q password=%q", instances[0].User, instances[0].Password)
+		}
+	}
+	assertInstances(map[string]string{"host": "some.db.host", "password": "vault:database/creds/missing"}, true)
+
+	// hosts may be obtained from service discovery, mixed with static hosts
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/health/service/mysql-shard" {
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.2"}, "Service": {"Address": "", "Port": 3307}}, {"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "shard1.db", "Port": 3306}}]`))
+		} else {
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer consul.Close()
+	t.Setenv("CONSUL_HTTP_ADDR", consul.URL)
+	assertInstances(map[string]string{"host": "consul:mysql-shard,other.db.host"}, false, "10.0.0.2:3307", "shard1.db:3306", "other.db.host:3306")
+	assertInstances(map[string]string{"host": "consul:mysql-shard", "host-discovery-ttl": "bogus"}, true)
+	assertInstances(map[string]string{"host": "consul:mysql-empty"}, true)
+	assertInstances(map[string]string{"host": "consul:mysql-empty", "host-discovery-allow-empty": "1"}, false)
+
+	// user and password may be obtained from credential-helper, separately for
+	// each host
+	if runtime.GOOS != "windows" {
+		helperDir := t.TempDir()
+		os.WriteFile(filepath.Join(helperDir, "db1.json"), []byte(`{"user": "app", "password": "pw1"}`), 0600)
+		os.WriteFile(filepath.Join(helperDir, "db2.json"), []byte(`{"password": "pw2"}`), 0600)
+		helperOpts := map[string]string{"host": "db1,db2", "user": "fallback", "credential-helper": "cat " + helperDir + "/{HOST}.json"}
+		if instances := assertInstances(helperOpts, false, "db1:3306", "db2:3306"); len(instances) == 2 {
+			if instances[0].User != "app" || instances[0].Password != "pw1" || instances[1].User != "fallback" || instances[1].Password != "pw2" {
+				t.Errorf("Unexpected credentials from credential-helper: %s:%s, %s:%s", instances[0].User, instances[0].Password, instances[1].User, instances[1].Password)
+			}
+		}
+		helperOpts["host"] = "db3"
+		assertInstances(helperOpts, true)
+		helperOpts["host"], helperOpts["aws-iam-auth"], helperOpts["ssl-mode"] = "db1", "1", "required"
+		assertInstances(helperOpts, true)
+	}
+
 	// invalid option values or combinations
 	assertInstances(map[string]string{"host": "some.db.host", "connect-options": ","}, true)
 	assertInstances(map[string]string{"host": "some.db.host:3306", "port": "3307"}, true)
@@ -654,6 +801,33 @@ func TestDirInstances(t *testing.T) {
 		assertInstances(map[string]string{"host-wrapper": "/usr/bin/printf 'some.db.host\tother.db.host:3316'", "host": "ignored", "port": "3316"}, false, "some.db.host:3316", "other.db.host:3316")
 		assertInstances(map[string]string{"host-wrapper": "/usr/bin/printf 'localhost,remote.host:3307,other.host'", "host": "ignored", "socket": "/var/lib/mysql/mysql.sock"}, false, "localhost:/var/lib/mysql/mysql.sock", "remote.host:3307", "other.host:3306")
 		assertInstances(map[string]string{"host-wrapper": "/bin/echo -n", "host": "ignored"}, false)
+		assertInstances(map[string]string{"host-wrapper": "/usr/bin/printf 'db1\ndb1'", "host": "ignored"}, false, "db1:3306")
+		assertInstances(map[string]string{"host-wrapper": "/usr/bin/printf 'db1\ndb2'", "host": "ignored", "host-filter": "role=primary"}, true)
+
+		// JSON output with labels, optionally filtered
+		tempDir := t.TempDir()
+		jsonFile := filepath.Join(tempDir, "hosts.json")
+		WriteTestFile(t, jsonFile, `[{"host": "db1", "port": 3307, "role": "primary", "shard": "1"}, {"host": "db2", "role": "replica", "shard": "1", "labels": {"region": "east"}}]`)
+		jsonWrapper := "cat " + jsonFile
+		assertInstances(map[string]string{"host-wrapper": jsonWrapper, "host": "ignored"}, false, "db1:3307", "db2:3306")
+		assertInstances(map[string]string{"host-wrapper": jsonWrapper, "host": "ignored", "host-filter": "role=replica"}, false, "db2:3306")
+		assertInstances(map[string]string{"host-wrapper": jsonWrapper, "host": "ignored", "host-filter": "shard=1,region=west"}, false)
+		assertInstances(map[string]string{"host-wrapper": jsonWrapper, "host": "ignored", "host-filter": "role"}, true)
+		WriteTestFile(t, jsonFile, `[{"port": 3306}]`)
+		assertInstances(map[string]string{"host-wrapper": jsonWrapper, "host": "ignored"}, true)
+
+		// Output cached across calls with host-wrapper-ttl
+		t.Setenv("XDG_CACHE_HOME", tempDir)
+		t.Setenv("HOME", tempDir)
+		countFile := filepath.Join(tempDir, "count")
+		cachedOpts := map[string]string{"host-wrapper": "echo x >> " + countFile + "; /usr/bin/printf 'db1,db2'", "host": "ignored", "host-wrapper-ttl": "1m"}
+		assertInstances(cachedOpts, false, "db1:3306", "db2:3306")
+		assertInstances(cachedOpts, false, "db1:3306", "db2:3306")
+		if contents, err := os.ReadFile(countFile); err != nil || string(contents) != "x\n" {
+			t.Errorf("Expected host-wrapper to be executed once, instead found %q, err=%v", contents, err)
+		}
+		cachedOpts["host-wrapper-ttl"] = "bogus"
+		assertInstances(cachedOpts, true)
 	}
 }
 
@@ -661,7 +835,7 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 	getFakeDir := func(connectOptions string) *Dir {
 		return &Dir{
 			Path:   "/tmp/dummydir",
-			Config: mybase.SimpleConfig(map[string]string{"connect-options": connectOptions, "ssl-mode": "preferred", "flavor": "mysql:8.0"}),
+			Config: mybase.SimpleConfig(map[string]string{"connect-options": connectOptions, "ssl-mode": "preferred", "flavor": "mysql:8.0", "connect-timeout": "5s", "read-timeout": "20s"}),
 		}
 	}
 	assertDefaultParams := func(connectOptions, expected string) {
@@ -730,7 +904,7 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 	dir := &Dir{Path: "/tmp/dummydir"}
 	for input, expected := range expectTLS {
 		sslMode, flavorString, _ := strings.Cut(input, " ")
-		dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": sslMode, "flavor": flavorString})
+		dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": sslMode, "flavor": flavorString, "connect-timeout": "5s", "read-timeout": "20s"})
 		if parsed, err := url.ParseQuery(expected); err != nil {
 			t.Fatalf("Bad expected value %q: %s", expected, err)
 		} else {
@@ -745,14 +919,58 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 	}
 
 	// Test invalid TLS-related values
-	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": "invalid-enum", "flavor": ""})
+	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": "invalid-enum", "flavor": "", "connect-timeout": "5s", "read-timeout": "20s"})
 	if _, err := dir.InstanceDefaultParams(); err == nil {
 		t.Error("Expected an error from dir.InstanceDefaultParams() with invalid ssl-mode, but err was nil")
 	}
-	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "tls=preferred", "ssl-mode": "required", "flavor": ""})
+	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "tls=preferred", "ssl-mode": "required", "flavor": "", "connect-timeout": "5s", "read-timeout": "20s"})
 	if _, err := dir.InstanceDefaultParams(); err == nil {
 		t.Error("Expected an error from dir.InstanceDefaultParams() with tls in connect-options while also setting ssl-mode, but err was nil")
 	}
+
+	// Test timeout options, which may still be overridden by connect-options
+	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": "disabled", "flavor": "", "connect-timeout": "2s", "read-timeout": "0"})
+	if actual, err := dir.InstanceDefaultParams(); err != nil {
+		t.Errorf("Unexpected error from InstanceDefaultParams: %v", err)
+	} else if !strings.Contains(actual, "&timeout=2s") || !strings.Contains(actual, "&readTimeout=0&") {
+		t.Errorf("Timeout options not reflected in default params: %s", actual)
+	}
+	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "timeout=3s", "ssl-mode": "disabled", "flavor": "", "connect-timeout": "2s", "read-timeout": "20s"})
+	if actual, err := dir.InstanceDefaultParams(); err != nil {
+		t.Errorf("Unexpected error from InstanceDefaultParams: %v", err)
+	} else if !strings.Contains(actual, "&timeout=3s") {
+		t.Errorf("Expected connect-options to override connect-timeout, instead found %s", actual)
+	}
+	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": "disabled", "flavor": "", "connect-timeout": "5s", "read-timeout": "forever"})
+	if _, err := dir.InstanceDefaultParams(); err == nil {
+		t.Error("Expected an error from dir.InstanceDefaultParams() with invalid read-timeout, but err was nil")
+	}
+}
+
+func TestDirInstancePoolOptions(t *testing.T) {
+	dir := &Dir{Path: "/tmp/dummydir"}
+	dir.Config = mybase.SimpleConfig(map[string]string{"max-open-conns": "0", "max-idle-conns": "0", "conn-max-lifetime": "1m"})
+	if opts, err := dir.InstancePoolOptions(); err != nil {
+		t.Errorf("Unexpected error from InstancePoolOptions: %v", err)
+	} else if expected := (tengo.PoolOptions{ConnMaxLifetime: time.Minute}); opts != expected {
+		t.Errorf("Expected %+v, instead found %+v", expected, opts)
+	}
+	dir.Config = mybase.SimpleConfig(map[string]string{"max-open-conns": "8", "max-idle-conns": "4", "conn-max-lifetime": "30s"})
+	if opts, err := dir.InstancePoolOptions(); err != nil {
+		t.Errorf("Unexpected error from InstancePoolOptions: %v", err)
+	} else if expected := (tengo.PoolOptions{MaxOpenConns: 8, MaxIdleConns: 4, ConnMaxLifetime: 30 * time.Second}); opts != expected {
+		t.Errorf("Expected %+v, instead found %+v", expected, opts)
+	}
+	for _, bad := range []map[string]string{
+		{"max-open-conns": "lots", "max-idle-conns": "0", "conn-max-lifetime": "1m"},
+		{"max-open-conns": "0", "max-idle-conns": "-1", "conn-max-lifetime": "1m"},
+		{"max-open-conns": "0", "max-idle-conns": "0", "conn-max-lifetime": "1 minute"},
+	} {
+		dir.Config = mybase.SimpleConfig(bad)
+		if _, err := dir.InstancePoolOptions(); err == nil {
+			t.Errorf("Expected an error from InstancePoolOptions with options %v, but err was nil", bad)
+		}
+	}
 }
 
 func TestHostDefaultDirName(t *testing.T) {
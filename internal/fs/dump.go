@@ -0,0 +1,210 @@
+package fs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// ParseDump parses a schema-only dump into LogicalSchemas, keyed by schema
+// name. The path may refer to a file produced by mysqldump, or a directory
+// produced by mydumper or MySQL Shell's dump utilities. Files may optionally be
+// gzip-compressed.
+//
+// Only CREATE statements for supported object types are retained. All other
+// statements, including any that Skeema cannot parse, are ignored. For a
+// mysqldump file, each statement's schema is determined by any preceding USE
+// statement, so a dump of a single database without --databases results in a
+// LogicalSchema with a blank name. For a dump directory, the schema name is
+// obtained from each file name. In either case, if the dump includes a CREATE
+// DATABASE for a schema, its default character set and collation (if
+// specified) are used for the LogicalSchema's CharSet and Collation.
+func ParseDump(path string) (map[string]*LogicalSchema, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*LogicalSchema)
+	dbDefaults := make(map[string][2]string) // schema name -> charset, collation
+	if !fi.IsDir() {
+		statements, err := parseDumpFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range statements {
+			if err := addDumpStatement(result, dbDefaults, stmt.Schema(), stmt); err != nil {
+				return nil, err
+			}
+		}
+		applyDumpDefaults(result, dbDefaults)
+		return result, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	fileNames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			fileNames = append(fileNames, entry.Name())
+		}
+	}
+	sort.Strings(fileNames)
+	schemaNameForFile := mydumperSchemaName
+	if slices.Contains(fileNames, "@.json") {
+		schemaNameForFile = mysqlshSchemaName
+	}
+	for _, fileName := range fileNames {
+		schemaName, ok := schemaNameForFile(fileName)
+		if !ok {
+			continue
+		}
+		statements, err := parseDumpFile(filepath.Join(path, fileName))
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range statements {
+			if err := addDumpStatement(result, dbDefaults, schemaName, stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	applyDumpDefaults(result, dbDefaults)
+	return result, nil
+}
+
+// mydumperSchemaName returns the schema name for a file in a mydumper output
+// directory, based on the mydumper file naming conventions. The second return
+// value is false if the file does not contain relevant object definitions:
+// this includes data files, metadata files, and view or trigger definition
+// files.
+func mydumperSchemaName(fileName string) (string, bool) {
+	name := strings.TrimSuffix(fileName, ".gz")
+	if strings.HasSuffix(name, "-schema-post.sql") { // procs and funcs (and events)
+		return strings.TrimSuffix(name, "-schema-post.sql"), true
+	} else if strings.HasSuffix(name, "-schema-create.sql") { // CREATE DATABASE
+		return strings.TrimSuffix(name, "-schema-create.sql"), true
+	} else if strings.HasSuffix(name, "-schema.sql") { // tables
+		schemaName, _, ok := strings.Cut(strings.TrimSuffix(name, "-schema.sql"), ".")
+		return schemaName, ok
+	}
+	return "", false
+}
+
+// mysqlshSchemaName returns the schema name for a file in a MySQL Shell dump
+// directory, from util.dumpInstance or util.dumpSchemas. Table definitions are
+// in files named "schema@table.sql", and routines are in "schema.sql" along
+// with the CREATE DATABASE. Special characters in file names are
+// percent-encoded. The second return value is false for files which do not
+// contain relevant object definitions, including data files, metadata files,
+// instance-level files beginning with "@.", and view or trigger files.
+func mysqlshSchemaName(fileName string) (string, bool) {
+	if strings.HasPrefix(fileName, "@.") || !strings.HasSuffix(fileName, ".sql") {
+		return "", false
+	}
+	name := strings.TrimSuffix(fileName, ".sql")
+	for _, suffix := range []string{".pre", ".post", ".triggers"} {
+		if strings.HasSuffix(name, suffix) {
+			return "", false
+		}
+	}
+	name, _, _ = strings.Cut(name, "@")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name, name != ""
+}
+
+// parseDumpFile parses the statements in the file at path, transparently
+// decompressing it if it is gzipped.
+func parseDumpFile(path string) ([]*tengo.Statement, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to decompress %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return tengo.ParseStatements(r, path)
+}
+
+// addDumpStatement adds stmt to the LogicalSchema in schemas with the supplied
+// name, creating it if necessary. For CREATE DATABASE statements, the default
+// character set and collation are instead tracked in dbDefaults. All other
+// statements besides CREATEs of supported object types are ignored.
+func addDumpStatement(schemas map[string]*LogicalSchema, dbDefaults map[string][2]string, schemaName string, stmt *tengo.Statement) error {
+	if stmt.Type == tengo.StatementTypeUnknown {
+		if name, charSet, collation, ok := parseCreateDatabase(stmt.Text); ok {
+			dbDefaults[name] = [2]string{charSet, collation}
+		}
+		return nil
+	} else if stmt.Type != tengo.StatementTypeCreate {
+		return nil
+	}
+	if _, ok := schemas[schemaName]; !ok {
+		schemas[schemaName] = NewLogicalSchema()
+		schemas[schemaName].Name = schemaName
+	}
+	return schemas[schemaName].AddStatement(stmt)
+}
+
+// applyDumpDefaults sets the CharSet and Collation of each LogicalSchema in
+// schemas, based on the CREATE DATABASE statements tracked in dbDefaults.
+// Schemas without any supported object definitions are not added.
+func applyDumpDefaults(schemas map[string]*LogicalSchema, dbDefaults map[string][2]string) {
+	for name, defaults := range dbDefaults {
+		if ls := schemas[name]; ls != nil {
+			ls.CharSet, ls.Collation = defaults[0], defaults[1]
+		}
+	}
+}
+
+var (
+	reVersionComment    = regexp.MustCompile(`/\*!\d*|\*/`)
+	reCreateDatabase    = regexp.MustCompile("(?is)^CREATE\\s+(?:DATABASE|SCHEMA)\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?(`(?:[^`]|``)+`|\\w+)(.*)$")
+	reDatabaseCharSet   = regexp.MustCompile(`(?i)(?:CHARACTER\s+SET|CHARSET)\s*=?\s*'?(\w+)`)
+	reDatabaseCollation = regexp.MustCompile(`(?i)COLLATE\s*=?\s*'?(\w+)`)
+)
+
+// parseCreateDatabase parses a CREATE DATABASE statement, as typically found
+// in dumps. MySQL-specific "executable comments" wrapping parts of the
+// statement are permitted. The returned charSet and collation are blank if not
+// specified in the statement. The final return value is false if text is not a
+// CREATE DATABASE statement.
+func parseCreateDatabase(text string) (name, charSet, collation string, ok bool) {
+	text = strings.TrimSpace(reVersionComment.ReplaceAllString(text, " "))
+	matches := reCreateDatabase.FindStringSubmatch(text)
+	if matches == nil {
+		return "", "", "", false
+	}
+	name = matches[1]
+	if name[0] == '`' {
+		name = strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+	}
+	if m := reDatabaseCharSet.FindStringSubmatch(matches[2]); m != nil {
+		charSet = strings.ToLower(m[1])
+	}
+	if m := reDatabaseCollation.FindStringSubmatch(matches[2]); m != nil {
+		collation = strings.ToLower(m[1])
+	}
+	return name, charSet, collation, true
+}
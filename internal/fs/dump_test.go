@@ -0,0 +1,154 @@
+package fs
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+const mysqldumpContents = "-- MySQL dump 10.13\n" +
+	"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+	"CREATE DATABASE /*!32312 IF NOT EXISTS*/ `product` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;\n" +
+	"USE `product`;\n" +
+	"DROP TABLE IF EXISTS `users`;\n" +
+	"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+	"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+	"/*!50001 CREATE VIEW `v` AS SELECT 1 AS `1`*/;\n" +
+	"DELIMITER ;;\n" +
+	"CREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\nBEGIN\n  SELECT 1;\nEND ;;\n" +
+	"DELIMITER ;\n" +
+	"USE `analytics`;\n" +
+	"CREATE TABLE `pageviews` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n"
+
+func TestParseDump(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, contents string, compress bool) string {
+		t.Helper()
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatalf("Unexpected error from MkdirAll: %v", err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Unexpected error from Create: %v", err)
+		}
+		defer f.Close()
+		if compress {
+			gz := gzip.NewWriter(f)
+			gz.Write([]byte(contents))
+			err = gz.Close()
+		} else {
+			_, err = f.Write([]byte(contents))
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error writing %s: %v", path, err)
+		}
+		return path
+	}
+	assertObjects := func(schemas map[string]*LogicalSchema, schemaName string, expected ...tengo.ObjectKey) {
+		t.Helper()
+		ls := schemas[schemaName]
+		if ls == nil {
+			t.Fatalf("Expected schema %q to be present, but it was not", schemaName)
+		}
+		if len(ls.Creates) != len(expected) {
+			t.Errorf("Expected schema %q to have %d objects, instead found %d", schemaName, len(expected), len(ls.Creates))
+		}
+		for _, key := range expected {
+			if ls.Creates[key] == nil {
+				t.Errorf("Expected schema %q to contain %s, but it did not", schemaName, key)
+			}
+		}
+	}
+	users := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "users"}
+	pageviews := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "pageviews"}
+	proc := tengo.ObjectKey{Type: tengo.ObjectTypeProc, Name: "p"}
+
+	// mysqldump output, both plain and gzipped (regardless of file extension)
+	for _, path := range []string{
+		writeFile("dump.sql", mysqldumpContents, false),
+		writeFile("dump.sql.gz", mysqldumpContents, true),
+		writeFile("dump-misnamed.sql", mysqldumpContents, true),
+	} {
+		schemas, err := ParseDump(path)
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDump(%q): %v", path, err)
+		} else if len(schemas) != 2 {
+			t.Errorf("Expected ParseDump(%q) to return 2 schemas, instead found %d", path, len(schemas))
+		}
+		assertObjects(schemas, "product", users, proc)
+		assertObjects(schemas, "analytics", pageviews)
+		if ls := schemas["product"]; ls.CharSet != "utf8mb4" || ls.Collation != "" {
+			t.Errorf("Unexpected CharSet %q or Collation %q for schema product", ls.CharSet, ls.Collation)
+		}
+	}
+
+	// mydumper output directory
+	writeFile("mydumper/metadata", "Started dump at: 2026-01-01 00:00:00\n", false)
+	writeFile("mydumper/product-schema-create.sql", "CREATE DATABASE `product` /*!40100 DEFAULT CHARACTER SET latin1 COLLATE latin1_bin */;\n", false)
+	writeFile("mydumper/product.users-schema.sql.gz", "/*!40101 SET NAMES binary*/;\nCREATE TABLE `users` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n", true)
+	writeFile("mydumper/product.users.00000.sql", "INSERT INTO `users` VALUES (1);\n", false)
+	writeFile("mydumper/product.v-schema-view.sql", "CREATE VIEW `v` AS SELECT 1;\n", false)
+	writeFile("mydumper/product-schema-post.sql", "DELIMITER ;;\nCREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\nBEGIN\n  SELECT 1;\nEND ;;\nDELIMITER ;\n", false)
+	writeFile("mydumper/analytics.pageviews-schema.sql", "CREATE TABLE `pageviews` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n", false)
+	schemas, err := ParseDump(filepath.Join(tempDir, "mydumper"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDump on mydumper dir: %v", err)
+	} else if len(schemas) != 2 {
+		t.Errorf("Expected ParseDump on mydumper dir to return 2 schemas, instead found %d", len(schemas))
+	}
+	assertObjects(schemas, "product", users, proc)
+	assertObjects(schemas, "analytics", pageviews)
+	if ls := schemas["product"]; ls.CharSet != "latin1" || ls.Collation != "latin1_bin" {
+		t.Errorf("Unexpected CharSet %q or Collation %q for schema product", ls.CharSet, ls.Collation)
+	}
+
+	// MySQL Shell dump directory
+	writeFile("mysqlsh/@.json", `{"dumper": "mysqlsh Ver 8.0.36"}`, false)
+	writeFile("mysqlsh/@.sql", "SET NAMES utf8mb4;\n", false)
+	writeFile("mysqlsh/@.post.sql", "SET NAMES utf8mb4;\n", false)
+	writeFile("mysqlsh/product.json", `{"tables": ["users"]}`, false)
+	writeFile("mysqlsh/product.sql", "CREATE DATABASE IF NOT EXISTS `product`;\nUSE `product`;\nDELIMITER ;;\nCREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\nBEGIN\n  SELECT 1;\nEND;;\nDELIMITER ;\n", false)
+	writeFile("mysqlsh/product@users.sql", "CREATE TABLE IF NOT EXISTS `users` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n", false)
+	writeFile("mysqlsh/product@users@@0.tsv.zst", "not really zstd", false)
+	writeFile("mysqlsh/product@v.pre.sql", "CREATE TABLE `v` (`1` tinyint NOT NULL);\n", false)
+	writeFile("mysqlsh/product@v.sql", "DROP TABLE IF EXISTS `v`;\nCREATE VIEW `v` AS SELECT 1;\n", false)
+	writeFile("mysqlsh/my%40analytics.sql", "CREATE DATABASE IF NOT EXISTS `my@analytics`;\n", false)
+	writeFile("mysqlsh/my%40analytics@pageviews.sql", "CREATE TABLE IF NOT EXISTS `pageviews` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n", false)
+	if schemas, err = ParseDump(filepath.Join(tempDir, "mysqlsh")); err != nil {
+		t.Fatalf("Unexpected error from ParseDump on mysqlsh dir: %v", err)
+	} else if len(schemas) != 2 {
+		t.Errorf("Expected ParseDump on mysqlsh dir to return 2 schemas, instead found %d", len(schemas))
+	}
+	assertObjects(schemas, "product", users, proc)
+	assertObjects(schemas, "my@analytics", pageviews)
+
+	if _, err := ParseDump(filepath.Join(tempDir, "does-not-exist.sql")); err == nil {
+		t.Error("Expected error from ParseDump on nonexistent path, but it was nil")
+	}
+}
+
+func TestParseCreateDatabase(t *testing.T) {
+	cases := []struct {
+		text      string
+		name      string
+		charSet   string
+		collation string
+		ok        bool
+	}{
+		{"CREATE DATABASE foo;\n", "foo", "", "", true},
+		{"create schema if not exists `my``db` CHARSET=utf8mb4 COLLATE = utf8mb4_bin", "my`db", "utf8mb4", "utf8mb4_bin", true},
+		{"CREATE DATABASE /*!32312 IF NOT EXISTS*/ `product` /*!40100 DEFAULT CHARACTER SET utf8mb3 */ /*!80016 DEFAULT ENCRYPTION='N' */;\n", "product", "utf8mb3", "", true},
+		{"CREATE TABLE foo (id int)", "", "", "", false},
+		{"DROP DATABASE foo", "", "", "", false},
+	}
+	for _, c := range cases {
+		name, charSet, collation, ok := parseCreateDatabase(c.text)
+		if name != c.name || charSet != c.charSet || collation != c.collation || ok != c.ok {
+			t.Errorf("Unexpected return from parseCreateDatabase(%q): %q, %q, %q, %t", c.text, name, charSet, collation, ok)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// ParseGitRevision returns a LogicalSchema containing the CREATE statements
+// from the *.sql files in dir as of the supplied git ref, corresponding to the
+// first of dir's current LogicalSchemas. The dir's current configuration is
+// used for determining which objects to ignore and the default character set
+// and collation; any changes to its .skeema file since ref are not taken into
+// account. If dir did not exist at ref, the returned LogicalSchema is empty.
+func ParseGitRevision(dir *Dir, ref string) (*LogicalSchema, error) {
+	files, err := util.GitFilesAtRef(dir.Path, ref)
+	if err != nil {
+		return nil, err
+	}
+	fileNames := make([]string, 0, len(files))
+	for name := range files {
+		if strings.HasSuffix(strings.ToLower(name), ".sql") {
+			fileNames = append(fileNames, name)
+		}
+	}
+	sort.Strings(fileNames)
+
+	ls := NewLogicalSchema()
+	if len(dir.LogicalSchemas) > 0 {
+		ls.Name = dir.LogicalSchemas[0].Name
+	}
+	if ls.Name == "" {
+		ls.CharSet = dir.Config.Get("default-character-set")
+		ls.Collation = dir.Config.Get("default-collation")
+	}
+	for _, name := range fileNames {
+		filePath := fmt.Sprintf("%s:%s", ref, filepath.Join(dir.RelPath(), name))
+		statements, err := tengo.ParseStatements(strings.NewReader(files[name]), filePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range statements {
+			if stmt.Type != tengo.StatementTypeCreate || stmt.Schema() != ls.Name || dir.ShouldIgnore(stmt) {
+				continue
+			}
+			if err := ls.AddStatement(stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ls, nil
+}
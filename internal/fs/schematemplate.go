@@ -0,0 +1,192 @@
+package fs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxSchemaTemplateNames limits how many schema names a single schema option
+// value may expand to, to guard against accidental runaway ranges.
+const maxSchemaTemplateNames = 100000
+
+// reSchemaTemplateVerb matches the printf-style integer verb which may be used
+// in a schema name, in conjunction with the schema-range option.
+var reSchemaTemplateVerb = regexp.MustCompile(`%(0?[1-9][0-9]*)?d`)
+
+// isSchemaTemplate returns true if the supplied schema option value uses
+// brace expressions or printf-style verbs to describe multiple schema names.
+func isSchemaTemplate(value string) bool {
+	return strings.ContainsRune(value, '{') || reSchemaTemplateVerb.MatchString(value)
+}
+
+// expandSchemaTemplate converts a schema option value into a list of schema
+// names. The value is a comma-separated list, where each element may contain
+// brace expressions in the style of shell brace expansion: numeric ranges such
+// as "app_shard_{0..255}" (zero-padded if either bound has a leading zero, as
+// in "{000..255}"), or lists such as "app_{us,eu}". Alternatively, an element
+// may contain a single printf-style integer verb such as "tenant_%d" or
+// "tenant_%04d", in which case rangeValue supplies the numbers to substitute,
+// as a comma-separated list of integers and inclusive ranges such as
+// "1..500,750". Names are returned in the order described.
+func expandSchemaTemplate(value, rangeValue string) ([]string, error) {
+	var names []string
+	for _, element := range splitOutsideBraces(value) {
+		if element = strings.TrimSpace(element); element == "" {
+			continue
+		}
+		if verbs := reSchemaTemplateVerb.FindAllStringIndex(element, -1); len(verbs) > 1 {
+			return nil, fmt.Errorf("Schema name %q may only contain one numeric placeholder", element)
+		} else if len(verbs) == 1 {
+			if rangeValue == "" {
+				return nil, fmt.Errorf("Schema name %q requires the schema-range option to be set", element)
+			}
+			numbers, err := parseSchemaRange(rangeValue)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid value for option schema-range: %w", err)
+			}
+			verb := element[verbs[0][0]:verbs[0][1]]
+			for _, n := range numbers {
+				names = append(names, element[:verbs[0][0]]+fmt.Sprintf(verb, n)+element[verbs[0][1]:])
+			}
+		} else {
+			expanded, err := expandBraces(element)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, expanded...)
+		}
+		if len(names) > maxSchemaTemplateNames {
+			return nil, fmt.Errorf("Schema option value %q expands to more than %d schema names", value, maxSchemaTemplateNames)
+		}
+	}
+	return names, nil
+}
+
+// splitOutsideBraces splits value on commas which are not inside a brace
+// expression.
+func splitOutsideBraces(value string) []string {
+	var result []string
+	var depth, start int
+	for n, r := range value {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			result = append(result, value[start:n])
+			start = n + 1
+		}
+	}
+	return append(result, value[start:])
+}
+
+// expandBraces performs brace expansion on a single schema name, returning
+// the cartesian product of all brace expressions it contains.
+func expandBraces(name string) ([]string, error) {
+	start := strings.IndexByte(name, '{')
+	if start < 0 {
+		if strings.ContainsRune(name, '}') {
+			return nil, fmt.Errorf("Schema name %q has unbalanced braces", name)
+		}
+		return []string{name}, nil
+	}
+	end := strings.IndexByte(name[start:], '}') + start
+	if end < start {
+		return nil, fmt.Errorf("Schema name %q has unbalanced braces", name)
+	}
+	expr := name[start+1 : end]
+	if strings.ContainsRune(expr, '{') {
+		return nil, fmt.Errorf("Schema name %q has nested braces, which are not supported", name)
+	}
+
+	var values []string
+	if lo, hi, ok := strings.Cut(expr, ".."); ok {
+		numbers, err := parseNumericRange(lo, hi)
+		if err != nil {
+			return nil, fmt.Errorf("Schema name %q has invalid range: %w", name, err)
+		}
+		width := 0
+		if (len(lo) > 1 && lo[0] == '0') || (len(hi) > 1 && hi[0] == '0') {
+			width = max(len(lo), len(hi))
+		}
+		for _, n := range numbers {
+			values = append(values, fmt.Sprintf("%0*d", width, n))
+		}
+	} else if strings.ContainsRune(expr, ',') {
+		values = strings.Split(expr, ",")
+	} else {
+		return nil, fmt.Errorf("Schema name %q has invalid brace expression {%s}: must be a range such as {1..10} or a list such as {a,b}", name, expr)
+	}
+
+	suffixes, err := expandBraces(name[end+1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(values)*len(suffixes) > maxSchemaTemplateNames {
+		return nil, fmt.Errorf("Schema name %q expands to more than %d schema names", name, maxSchemaTemplateNames)
+	}
+	result := make([]string, 0, len(values)*len(suffixes))
+	for _, value := range values {
+		for _, suffix := range suffixes {
+			result = append(result, name[:start]+value+suffix)
+		}
+	}
+	return result, nil
+}
+
+// parseSchemaRange parses a comma-separated list of integers and inclusive
+// ranges, such as "0..15,20,30..31".
+func parseSchemaRange(value string) ([]int, error) {
+	var result []int
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		lo, hi, ok := strings.Cut(part, "..")
+		if !ok {
+			hi = lo
+		}
+		numbers, err := parseNumericRange(lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, numbers...)
+		if len(result) > maxSchemaTemplateNames {
+			return nil, fmt.Errorf("range %q includes more than %d numbers", value, maxSchemaTemplateNames)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("range %q does not include any numbers", value)
+	}
+	return result, nil
+}
+
+// parseNumericRange returns the integers from lo to hi inclusive. If lo is
+// greater than hi, the integers are returned in descending order.
+func parseNumericRange(lo, hi string) ([]int, error) {
+	from, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an integer", lo)
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an integer", hi)
+	}
+	// Compute the distance using unsigned arithmetic, which cannot overflow even
+	// for ranges spanning most of the int range
+	step, distance := 1, uint64(to)-uint64(from)
+	if from > to {
+		step, distance = -1, uint64(from)-uint64(to)
+	}
+	if distance >= maxSchemaTemplateNames {
+		return nil, fmt.Errorf("range %s..%s includes more than %d numbers", lo, hi, maxSchemaTemplateNames)
+	}
+	result := make([]int, distance+1)
+	for n := range result {
+		result[n] = from + n*step
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package fs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandSchemaTemplate(t *testing.T) {
+	cases := []struct {
+		value      string
+		rangeValue string
+		expected   string // comma-separated, or blank if an error is expected
+	}{
+		{"app_shard_{0..3}", "", "app_shard_0,app_shard_1,app_shard_2,app_shard_3"},
+		{"app_shard_{08..10}", "", "app_shard_08,app_shard_09,app_shard_10"},
+		{"app_{2..0}", "", "app_2,app_1,app_0"},
+		{"app_{us,eu}_{1..2}", "", "app_us_1,app_us_2,app_eu_1,app_eu_2"},
+		{"main, app_{us,eu}", "", "main,app_us,app_eu"},
+		{"tenant_%d", "1..3,7", "tenant_1,tenant_2,tenant_3,tenant_7"},
+		{"tenant_%03d_data", "9..10", "tenant_009_data,tenant_010_data"},
+		{"tenant_%d", "", ""},
+		{"tenant_%d", "1..x", ""},
+		{"tenant_%d", ",", ""},
+		{"tenant_%d_%d", "1", ""},
+		{"app_{1..3", "", ""},
+		{"app_1..3}", "", ""},
+		{"app_{a}", "", ""},
+		{"app_{{1..2}}", "", ""},
+		{"app_{1..b}", "", ""},
+		{"app_{0..100000}", "", ""},
+		{"app_{-9223372036854775808..9223372036854775807}", "", ""},
+		{"app_{9223372036854775807..-9223372036854775808}", "", ""},
+		{"tenant_%d", "-9223372036854775808..9223372036854775807", ""},
+		{"app_{9223372036854775806..9223372036854775807}", "", "app_9223372036854775806,app_9223372036854775807"},
+		{"app_{-9223372036854775807..-9223372036854775808}", "", "app_-9223372036854775807,app_-9223372036854775808"},
+		{"app_{0..999}_{0..999}", "", ""},
+	}
+	for _, c := range cases {
+		names, err := expandSchemaTemplate(c.value, c.rangeValue)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("Expected error expanding %q with range %q, but err was nil", c.value, c.rangeValue)
+			}
+		} else if err != nil {
+			t.Errorf("Unexpected error expanding %q with range %q: %v", c.value, c.rangeValue, err)
+		} else if actual := strings.Join(names, ","); actual != c.expected {
+			t.Errorf("Expected %q with range %q to expand to %q, instead found %q", c.value, c.rangeValue, c.expected, actual)
+		}
+	}
+
+	// Large ranges within the limit are permitted
+	if names, err := expandSchemaTemplate("app_shard_{000..255}", ""); err != nil || len(names) != 256 || names[255] != "app_shard_255" {
+		t.Errorf("Unexpected result from large range: len=%d, err=%v", len(names), err)
+	}
+
+	for value, expected := range map[string]bool{
+		"app":               false,
+		"app,other":         false,
+		"app_{0..3}":        true,
+		"tenant_%d":         true,
+		"tenant_%04d":       true,
+		"100%_done":         false,
+		"`/bin/schemas {}`": true, // shellouts are handled before templates are considered
+	} {
+		if actual := isSchemaTemplate(value); actual != expected {
+			t.Errorf("Expected isSchemaTemplate(%q) to return %t, instead found %t", value, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package golden provides helpers for tests which compare generated output,
+// such as CREATE or ALTER statements, against the contents of golden files.
+// When tests are run with the -update flag, golden files are rewritten with
+// the actual output instead of being compared.
+//
+// This package intentionally does not depend on any other package in this
+// module, so that it may be used by tests of any package, including tengo.
+package golden
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with actual output instead of comparing")
+
+// Updating returns true if the -update flag was supplied, indicating that
+// golden files should be rewritten rather than compared.
+func Updating() bool {
+	return *update
+}
+
+// Normalizer transforms output before comparison, typically to remove values
+// which vary between runs or environments. Normalizers are applied to both the
+// actual output and the golden file's contents, and should be idempotent.
+type Normalizer func(string) string
+
+// NormalizeNewlines converts Windows-style line endings to Unix-style, so that
+// golden files remain valid if checked out with CRLF line endings.
+func NormalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// TrimTrailingSpace removes trailing whitespace from each line, as well as
+// any trailing blank lines.
+func TrimTrailingSpace(s string) string {
+	lines := strings.Split(s, "\n")
+	for n := range lines {
+		lines[n] = strings.TrimRight(lines[n], " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+var reAutoIncrement = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// StripAutoIncrement removes next-AUTO_INCREMENT table options, which depend
+// on the table's data rather than its definition.
+func StripAutoIncrement(s string) string {
+	return reAutoIncrement.ReplaceAllString(s, "")
+}
+
+// Assert compares actual to the contents of the golden file at path, after
+// applying NormalizeNewlines and then any supplied normalizers to both. If
+// they differ, the test fails. If the -update flag was supplied, the golden
+// file is instead rewritten with the normalized actual output.
+func Assert(t testing.TB, path, actual string, normalizers ...Normalizer) {
+	t.Helper()
+	compare(t, path, actual, normalizers)
+}
+
+// AssertFlavor behaves like Assert, but permits per-flavor variants of the
+// golden file. The flavor should be a string in "name:major.minor.patch"
+// format, with any number of version components. The most specific existing
+// variant is used, by inserting the flavor name and decreasing amounts of its
+// version before path's extension. For example, with path "golden/foo.sql" and
+// flavor "mysql:8.0.32", the files "golden/foo.mysql-8.0.32.sql",
+// "golden/foo.mysql-8.0.sql", "golden/foo.mysql-8.sql", "golden/foo.mysql.sql",
+// and "golden/foo.sql" are checked in that order.
+//
+// With the -update flag, the most specific existing variant is rewritten, or
+// path itself if no variants exist. To introduce a new variant, first create
+// it (for example as a copy of path) and then run tests with -update.
+func AssertFlavor(t testing.TB, path, flavor, actual string, normalizers ...Normalizer) {
+	t.Helper()
+	for _, candidate := range FlavorPaths(path, flavor) {
+		if _, err := os.Stat(candidate); err == nil {
+			path = candidate
+			break
+		}
+	}
+	compare(t, path, actual, normalizers)
+}
+
+// FlavorPaths returns the paths of possible per-flavor variants of a golden
+// file, in order from most specific to least specific, as described in
+// AssertFlavor. The last element is always path itself.
+func FlavorPaths(path, flavor string) []string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	name, version, _ := strings.Cut(flavor, ":")
+	var versionParts []string
+	if version != "" {
+		versionParts = strings.Split(version, ".")
+	}
+	paths := make([]string, 0, len(versionParts)+2)
+	if name != "" {
+		for n := len(versionParts); n > 0; n-- {
+			paths = append(paths, fmt.Sprintf("%s.%s-%s%s", base, name, strings.Join(versionParts[:n], "."), ext))
+		}
+		paths = append(paths, base+"."+name+ext)
+	}
+	return append(paths, path)
+}
+
+func compare(t testing.TB, path, actual string, normalizers []Normalizer) {
+	t.Helper()
+	normalize := func(s string) string {
+		s = NormalizeNewlines(s)
+		for _, normalizer := range normalizers {
+			s = normalizer(s)
+		}
+		return s
+	}
+	actual = normalize(actual)
+
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatalf("Unable to create directory for golden file %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0666); err != nil {
+			t.Fatalf("Unable to update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file %s: %v (run tests with -update to create it)", path, err)
+	}
+	expected := normalize(string(contents))
+	if actual != expected {
+		t.Errorf("Output does not match golden file %s (run tests with -update to rewrite it)\n%s", path, describeDifference(expected, actual))
+	}
+}
+
+// describeDifference returns a description of the first line which differs
+// between expected and actual.
+func describeDifference(expected, actual string) string {
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for n := 0; n < len(expectedLines) || n < len(actualLines); n++ {
+		var expectedLine, actualLine string
+		if n < len(expectedLines) {
+			expectedLine = expectedLines[n]
+		}
+		if n < len(actualLines) {
+			actualLine = actualLines[n]
+		}
+		if expectedLine != actualLine || n >= len(expectedLines) || n >= len(actualLines) {
+			return fmt.Sprintf("First difference at line %d:\n  expected: %q\n  actual:   %q\nFull actual output:\n%s", n+1, expectedLine, actualLine, actual)
+		}
+	}
+	return ""
+}
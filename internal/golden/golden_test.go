@@ -0,0 +1,85 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFlavorPaths(t *testing.T) {
+	actual := FlavorPaths("golden/foo.sql", "mysql:8.0.32")
+	expected := []string{
+		"golden/foo.mysql-8.0.32.sql",
+		"golden/foo.mysql-8.0.sql",
+		"golden/foo.mysql-8.sql",
+		"golden/foo.mysql.sql",
+		"golden/foo.sql",
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Unexpected result from FlavorPaths: %v", actual)
+	}
+	actual = FlavorPaths("foo.sql", "mariadb")
+	if expected = []string{"foo.mariadb.sql", "foo.sql"}; !slices.Equal(actual, expected) {
+		t.Errorf("Unexpected result from FlavorPaths: %v", actual)
+	}
+	actual = FlavorPaths("foo.sql", "")
+	if expected = []string{"foo.sql"}; !slices.Equal(actual, expected) {
+		t.Errorf("Unexpected result from FlavorPaths: %v", actual)
+	}
+}
+
+func TestNormalizers(t *testing.T) {
+	input := "CREATE TABLE `t` (\r\n  `id` int  \r\n) ENGINE=InnoDB AUTO_INCREMENT=123 DEFAULT CHARSET=latin1\r\n\r\n"
+	actual := StripAutoIncrement(TrimTrailingSpace(NormalizeNewlines(input)))
+	expected := "CREATE TABLE `t` (\n  `id` int\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	if actual != expected {
+		t.Errorf("Unexpected result from normalizers: %q", actual)
+	}
+}
+
+func TestAssert(t *testing.T) {
+	dir := t.TempDir()
+	generic := filepath.Join(dir, "create.sql")
+	variant := filepath.Join(dir, "create.mysql-8.0.sql")
+	if err := os.WriteFile(generic, []byte("CREATE TABLE `t` (\r\n  `id` int(11)\r\n)\r\n"), 0666); err != nil {
+		t.Fatalf("Unable to write golden file: %v", err)
+	}
+	if err := os.WriteFile(variant, []byte("CREATE TABLE `t` (\n  `id` int\n) AUTO_INCREMENT=5\n"), 0666); err != nil {
+		t.Fatalf("Unable to write golden file: %v", err)
+	}
+
+	Assert(t, generic, "CREATE TABLE `t` (\n  `id` int(11)\n)\n")
+	AssertFlavor(t, generic, "mysql:5.7.44", "CREATE TABLE `t` (\n  `id` int(11)\n)\n")
+	AssertFlavor(t, generic, "mysql:8.0.36", "CREATE TABLE `t` (\n  `id` int\n) AUTO_INCREMENT=123\n", StripAutoIncrement)
+
+	// Confirm mismatches are reported, using a stub testing.TB
+	mock := &mockTB{TB: t}
+	AssertFlavor(mock, generic, "mariadb:10.6", "CREATE TABLE `t` (\n  `id` int\n)\n")
+	if !strings.Contains(mock.failure, "First difference at line 2") {
+		t.Errorf("Expected mismatch to be reported, instead found %q", mock.failure)
+	}
+	mock = &mockTB{TB: t}
+	Assert(mock, filepath.Join(dir, "missing.sql"), "")
+	if !strings.Contains(mock.failure, "Unable to read golden file") {
+		t.Errorf("Expected missing golden file to be reported, instead found %q", mock.failure)
+	}
+}
+
+// mockTB records failures instead of reporting them.
+type mockTB struct {
+	testing.TB
+	failure string
+}
+
+func (m *mockTB) Helper() {}
+
+func (m *mockTB) Errorf(format string, args ...any) {
+	m.failure += fmt.Sprintf(format, args...)
+}
+
+func (m *mockTB) Fatalf(format string, args ...any) {
+	m.Errorf(format, args...)
+}
@@ -3,6 +3,7 @@ package linter
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/skeema/mybase"
@@ -25,6 +26,7 @@ const (
 func AddCommandOptions(cmd *mybase.Command) {
 	cmd.AddOptions("linter rule", mybase.StringOption("warnings", 0, "", "Deprecated method of setting multiple linter options to warning level").Hidden())
 	cmd.AddOptions("linter rule", mybase.StringOption("errors", 0, "", "Deprecated method of setting multiple linter options to error level").Hidden())
+	cmd.AddOptions("linter rule", mybase.StringOption("lint-ignore-objects", 0, "", "Comma-separated list of rule:regex pairs; skip the rule for objects with names matching the regex"))
 	for _, r := range rulesByName {
 		opt := mybase.StringOption(r.optionName(), 0, string(r.DefaultSeverity), r.optionDescription())
 		if r.hidden() {
@@ -41,9 +43,10 @@ func AddCommandOptions(cmd *mybase.Command) {
 type Options struct {
 	RuleSeverity            map[string]Severity
 	RuleConfig              map[string]interface{}
-	StripAnnotationNewlines bool                     // if true, remove newlines inside annotation messages
-	flavor                  tengo.Flavor             // actual workspace flavor; set automatically by CheckSchema
-	onlyKeys                map[tengo.ObjectKey]bool // if map is non-nil, only format objects with true values
+	RuleIgnorePatterns      map[string][]*regexp.Regexp // rule name ("*" for all rules) => object name patterns to skip
+	StripAnnotationNewlines bool                        // if true, remove newlines inside annotation messages
+	flavor                  tengo.Flavor                // actual workspace flavor; set automatically by CheckSchema
+	onlyKeys                map[tengo.ObjectKey]bool    // if map is non-nil, only format objects with true values
 }
 
 // AllowList returns a slice of configured allowed values for the given rule.
@@ -85,6 +88,9 @@ func (opts *Options) Equals(other *Options) bool {
 	if !reflect.DeepEqual(opts.RuleConfig, other.RuleConfig) {
 		return false
 	}
+	if !reflect.DeepEqual(opts.RuleIgnorePatterns, other.RuleIgnorePatterns) {
+		return false
+	}
 	if !reflect.DeepEqual(opts.onlyKeys, other.onlyKeys) {
 		return false
 	}
@@ -100,6 +106,40 @@ func (opts *Options) shouldIgnore(keyer tengo.ObjectKeyer) bool {
 	return opts.onlyKeys != nil && !opts.onlyKeys[keyer.ObjectKey()]
 }
 
+// shouldIgnoreRule returns true if the lint-ignore-objects configuration
+// indicates that the supplied rule should not be run on the supplied object.
+func (opts *Options) shouldIgnoreRule(ruleName string, keyer tengo.ObjectKeyer) bool {
+	name := keyer.ObjectKey().Name
+	for _, patternRuleName := range []string{ruleName, "*"} {
+		for _, re := range opts.RuleIgnorePatterns[patternRuleName] {
+			if re.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var reIgnoreObjectsDelimiter = regexp.MustCompile(`,\s*(?:\*|[\w-]+)\s*:`)
+
+// splitIgnoreObjects splits the value of the lint-ignore-objects option into
+// its rule:regex entries. Only commas followed by another rule name and colon
+// delimit entries, so that commas within regular expressions, for example in
+// a repetition such as {1,3}, are preserved.
+func splitIgnoreObjects(value string) (entries []string) {
+	var start int
+	for _, loc := range reIgnoreObjectsDelimiter.FindAllStringIndex(value, -1) {
+		if entry := strings.TrimSpace(value[start:loc[0]]); entry != "" {
+			entries = append(entries, entry)
+		}
+		start = loc[0] + 1
+	}
+	if entry := strings.TrimSpace(value[start:]); entry != "" {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 // OptionsForDir returns Options based on the configuration in an fs.Dir,
 // effectively converting between mybase options and linter options.
 func OptionsForDir(dir *fs.Dir) (*Options, error) {
@@ -145,6 +185,27 @@ func OptionsForDir(dir *fs.Dir) (*Options, error) {
 		}
 	}
 
+	// Process per-object rule exclusions. Each entry has form rule:regex, where
+	// the rule may be specified with or without its "lint-" prefix, or as "*" to
+	// apply to all rules.
+	for _, entry := range splitIgnoreObjects(dir.Config.Get("lint-ignore-objects")) {
+		ruleName, pattern, ok := strings.Cut(entry, ":")
+		ruleName = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ruleName)), "lint-")
+		if !ok || pattern == "" {
+			return nil, NewConfigError(dir, "Option lint-ignore-objects has invalid entry %q: each entry must be of form rule:regex", entry)
+		} else if _, exists := rulesByName[ruleName]; !exists && ruleName != "*" {
+			return nil, NewConfigError(dir, "Option lint-ignore-objects references unknown linter rule %q", ruleName)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, NewConfigError(dir, "Option lint-ignore-objects has invalid regular expression for rule %s: %s", ruleName, err)
+		}
+		if opts.RuleIgnorePatterns == nil {
+			opts.RuleIgnorePatterns = make(map[string][]*regexp.Regexp)
+		}
+		opts.RuleIgnorePatterns[ruleName] = append(opts.RuleIgnorePatterns[ruleName], re)
+	}
+
 	// Process supplemental configuration of rules where needed
 	for name, rule := range rulesByName {
 		// No need to configure rules that are disabled, or rules that have no
@@ -143,3 +143,82 @@ func TestOptionsEquals(t *testing.T) {
 		t.Error("Equals returning wrong value with different flavor")
 	}
 }
+
+func TestOptionsForDirEnvironment(t *testing.T) {
+	assertOptions := func(environment string, expectedPK Severity, expectedIgnores map[string]int) {
+		t.Helper()
+		dir := getDir(t, "testdata/envcfg", environment)
+		opts, err := OptionsForDir(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error from OptionsForDir with environment %q: %v", environment, err)
+		}
+		if actual := opts.RuleSeverity["pk"]; actual != expectedPK {
+			t.Errorf("With environment %q, expected severity %q for pk, instead found %q", environment, expectedPK, actual)
+		}
+		if len(opts.RuleIgnorePatterns) != len(expectedIgnores) {
+			t.Errorf("With environment %q, expected %d rules with ignore patterns, instead found %d", environment, len(expectedIgnores), len(opts.RuleIgnorePatterns))
+		}
+		for ruleName, expectedCount := range expectedIgnores {
+			if actualCount := len(opts.RuleIgnorePatterns[ruleName]); actualCount != expectedCount {
+				t.Errorf("With environment %q, expected %d ignore patterns for rule %q, instead found %d", environment, expectedCount, ruleName, actualCount)
+			}
+		}
+	}
+	assertOptions("production", SeverityError, map[string]int{"has-float": 1})
+	assertOptions("staging", SeverityWarning, map[string]int{"*": 1, "has-float": 1})
+
+	badOptions := []string{
+		"--lint-ignore-objects=has-float",
+		"--lint-ignore-objects=has-float:",
+		"--lint-ignore-objects=made-up-rule:^foo",
+		"--lint-ignore-objects='pk:^foo,has-float:[a-'",
+	}
+	for _, badOpt := range badOptions {
+		dir := getDir(t, "testdata/envcfg", badOpt)
+		if _, err := OptionsForDir(dir); err == nil {
+			t.Errorf("Expected an error from OptionsForDir with CLI %s, but it was nil", badOpt)
+		} else if _, ok := err.(ConfigError); !ok {
+			t.Errorf("Expected error to be a ConfigError, but instead type is %T", err)
+		}
+	}
+}
+
+func TestSplitIgnoreObjects(t *testing.T) {
+	cases := map[string][]string{
+		"":                                 nil,
+		"pk:^foo":                          {"pk:^foo"},
+		"*:^tmp_, lint-has-float:^legacy_": {"*:^tmp_", "lint-has-float:^legacy_"},
+		"pk:^a{1,3}$,has-float:^b":         {"pk:^a{1,3}$", "has-float:^b"},
+		"pk:^(x|y){2,}_[a-z,]+":            {"pk:^(x|y){2,}_[a-z,]+"},
+	}
+	for input, expected := range cases {
+		if actual := splitIgnoreObjects(input); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Unexpected result from splitIgnoreObjects(%q): expected %q, found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestOptionsShouldIgnoreRule(t *testing.T) {
+	dir := getDir(t, "testdata/envcfg", "staging")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	cases := []struct {
+		ruleName string
+		name     string
+		expected bool
+	}{
+		{"has-float", "legacy_orders", true},
+		{"pk", "legacy_orders", false},
+		{"pk", "tmp_import", true},
+		{"has-float", "tmp_import", true},
+		{"has-float", "orders", false},
+	}
+	for _, c := range cases {
+		key := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: c.name}
+		if actual := opts.shouldIgnoreRule(c.ruleName, key); actual != c.expected {
+			t.Errorf("Unexpected result from shouldIgnoreRule(%q, %s): expected %t, found %t", c.ruleName, key, c.expected, actual)
+		}
+	}
+}
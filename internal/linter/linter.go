@@ -32,7 +32,7 @@ func CheckSchema(wsSchema *workspace.Schema, opts *Options) *Result {
 			continue
 		}
 		for ruleName, severity := range opts.RuleSeverity {
-			if severity == SeverityIgnore {
+			if severity == SeverityIgnore || opts.shouldIgnoreRule(ruleName, object) {
 				continue
 			}
 			r := rulesByName[ruleName]
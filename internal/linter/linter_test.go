@@ -321,9 +321,7 @@ func (s IntegrationSuite) TestCheckSchemaStripAnnotationNewlines(t *testing.T) {
 // TestCheckSchemaSpatialIndexSRID confirms that the dupe-index checker will
 // flag SPATIAL indexes in MySQL 8 if their column lacks an SRID.
 func (s IntegrationSuite) TestCheckSchemaSpatialIndexSRID(t *testing.T) {
-	if !s.d.Flavor().MinMySQL(8) {
-		t.Skip("Test only relevant for MySQL 8.0+")
-	}
+	tengo.SkipUnlessFlavor(t, "mysql:8+")
 	dir := getDir(t, "testdata/spatialmysql8")
 	forceOnlyRulesWarning(dir.Config, "dupe-index")
 	opts, err := OptionsForDir(dir)
@@ -410,6 +408,10 @@ func (s *IntegrationSuite) Teardown(backend string) error {
 	return tengo.SkeemaTestContainerCleanup(s.d)
 }
 
+func (s *IntegrationSuite) BackendFlavor(backend string) tengo.Flavor {
+	return s.d.Flavor()
+}
+
 func (s *IntegrationSuite) BeforeTest(backend string) error {
 	return s.d.NukeData()
 }
@@ -0,0 +1,82 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Markdown returns a compact Markdown summary of the result, suitable for
+// posting as a pull request comment. Annotations are grouped by rule, with
+// rules emitting errors listed before rules only emitting warnings.
+func (r *Result) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Skeema lint summary\n\n")
+	if r.ErrorCount+r.WarningCount+len(r.Exceptions) == 0 {
+		b.WriteString("No problems found.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Found %s and %s.\n", countAndNoun(r.ErrorCount, "error"), countAndNoun(r.WarningCount, "warning"))
+
+	if len(r.Exceptions) > 0 {
+		b.WriteString("\n### Fatal errors\n\n")
+		for _, err := range r.Exceptions {
+			fmt.Fprintf(&b, "- %s\n", markdownEscape(err.Error()))
+		}
+	}
+
+	groups := make(map[string][]*Annotation)
+	var ruleNames []string
+	for _, a := range r.Annotations {
+		if a.Severity != SeverityError && a.Severity != SeverityWarning {
+			continue
+		}
+		if groups[a.RuleName] == nil {
+			ruleNames = append(ruleNames, a.RuleName)
+		}
+		groups[a.RuleName] = append(groups[a.RuleName], a)
+	}
+	hasError := func(ruleName string) bool {
+		for _, a := range groups[ruleName] {
+			if a.Severity == SeverityError {
+				return true
+			}
+		}
+		return false
+	}
+	sort.Slice(ruleNames, func(i, j int) bool {
+		if ei, ej := hasError(ruleNames[i]), hasError(ruleNames[j]); ei != ej {
+			return ei
+		}
+		return ruleNames[i] < ruleNames[j]
+	})
+	for _, ruleName := range ruleNames {
+		annotations := groups[ruleName]
+		sort.Sort(sortByFile(annotations))
+		fmt.Fprintf(&b, "\n### %s (%d)\n\n", ruleName, len(annotations))
+		for _, a := range annotations {
+			severity := "warning"
+			if a.Severity == SeverityError {
+				severity = "**error**"
+			}
+			fmt.Fprintf(&b, "- %s `%s`: %s\n", severity, a.Location(), markdownEscape(a.Message))
+		}
+	}
+	return b.String()
+}
+
+// countAndNoun returns n followed by noun, pluralized by adding "s" if n is not
+// 1.
+func countAndNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// markdownEscape replaces newlines and escapes characters which would
+// otherwise be interpreted as Markdown inline formatting syntax.
+func markdownEscape(s string) string {
+	replacer := strings.NewReplacer("\n", " ", "*", "\\*", "_", "\\_", "<", "&lt;")
+	return replacer.Replace(s)
+}
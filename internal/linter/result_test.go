@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/skeema/skeema/internal/fs"
@@ -185,3 +186,32 @@ func (s IntegrationSuite) TestResultAnnotateMixedSchemaNames(t *testing.T) {
 		}
 	}
 }
+
+func TestResultMarkdown(t *testing.T) {
+	r := &Result{}
+	if md := r.Markdown(); !strings.Contains(md, "No problems found.") {
+		t.Errorf("Unexpected output for empty result: %s", md)
+	}
+
+	r.Annotate(&tengo.Statement{File: "bbb.sql", LineNo: 1, CharNo: 1}, SeverityWarning, "pk", Note{Message: "Table `bbb` does not define a PRIMARY KEY"})
+	r.Annotate(&tengo.Statement{File: "aaa.sql", LineNo: 1, CharNo: 1}, SeverityWarning, "pk", Note{Message: "Table `aaa` does not define a PRIMARY KEY"})
+	r.Annotate(&tengo.Statement{File: "aaa.sql", LineNo: 5}, SeverityError, "charset", Note{LineOffset: 2, Message: "Column `name` is using character set latin1"})
+	r.Annotate(&tengo.Statement{File: "aaa.sql", LineNo: 9}, SeverityIgnore, "engine", Note{Message: "ignored"})
+	md := r.Markdown()
+	expected := []string{
+		"Found 1 error and 2 warnings.",
+		"### charset (1)\n\n- **error** `aaa.sql:7`: Column `name` is using character set latin1\n",
+		"### pk (2)\n\n- warning `aaa.sql:1:1`: Table `aaa` does not define a PRIMARY KEY\n- warning `bbb.sql:1:1`: Table `bbb`",
+	}
+	for _, substr := range expected {
+		if !strings.Contains(md, substr) {
+			t.Errorf("Expected output to contain %q, but it did not. Full output:\n%s", substr, md)
+		}
+	}
+	if strings.Contains(md, "engine") {
+		t.Errorf("Expected annotations with SeverityIgnore to be omitted. Full output:\n%s", md)
+	}
+	if strings.Index(md, "### charset") > strings.Index(md, "### pk") {
+		t.Errorf("Expected rules with errors to be listed first. Full output:\n%s", md)
+	}
+}
@@ -0,0 +1,3 @@
+CREATE TABLE nopk (
+  name varchar(30)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
@@ -1,6 +1,7 @@
 package shellout
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -196,6 +197,53 @@ func (c *Command) Run() error {
 	return cmd.Run()
 }
 
+// RunLineHandler shells out to the external command and blocks until it
+// completes. Each line of the command's combined STDOUT and STDERR output is
+// passed to handler as soon as it is available, without its trailing newline.
+// STDIN is redirected from the parent process, unless c.WithStdin was called.
+// If c.WithStderr was called, an error is returned.
+func (c *Command) RunLineHandler(handler func(line string)) error {
+	if c.command == "" {
+		return errors.New("Attempted to shell out to an empty command string")
+	} else if c.stderr != nil && c.stderr != os.Stderr {
+		return errors.New("Attempted to call RunLineHandler on a Command that already has STDERR redirection enabled")
+	}
+	cmd, err := c.cmd()
+	if err != nil {
+		return err
+	}
+	if c.cancelFunc != nil {
+		defer c.cancelFunc()
+	}
+	cmd.Dir = c.workingDir
+	if c.stdin != nil {
+		cmd.Stdin = c.stdin
+	} else {
+		cmd.Stdin = os.Stdin
+	}
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			handler(scanner.Text())
+		}
+		io.Copy(io.Discard, pr) // drain any remainder, e.g. after an overly long line
+	}()
+	err = cmd.Wait()
+	pw.Close()
+	<-done
+	return err
+}
+
 // RunCapture shells out to the external command and blocks until it completes.
 // It returns the command's STDOUT output as a single string. Behavior of
 // STDIN and STDERR depend on whether WithStdin and/or WithStderr have been
@@ -82,6 +82,34 @@ func TestRunCaptureSeparate(t *testing.T) {
 	}
 }
 
+func TestRunLineHandler(t *testing.T) {
+	var lines []string
+	handler := func(line string) {
+		lines = append(lines, line)
+	}
+	c := New("echo hello; echo there 1>&2; printf 'no newline'")
+	if err := c.RunLineHandler(handler); err != nil {
+		t.Errorf("Unexpected error from RunLineHandler(): %v", err)
+	} else if expected := []string{"hello", "there", "no newline"}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Unexpected lines from RunLineHandler(): expected %q, found %q", expected, lines)
+	}
+
+	// Confirm errors are returned for failing commands, empty commands, or when
+	// WithStderr was used
+	lines = nil
+	if err := New("echo failing; false").RunLineHandler(handler); err == nil {
+		t.Error("Expected RunLineHandler to return an error for failing command, but err was nil")
+	} else if len(lines) != 1 || lines[0] != "failing" {
+		t.Errorf("Unexpected lines from RunLineHandler(): %q", lines)
+	}
+	if err := New("").RunLineHandler(handler); err == nil {
+		t.Error("Expected RunLineHandler to return an error for empty command, but err was nil")
+	}
+	if err := c.WithStderr(&strings.Builder{}).RunLineHandler(handler); err == nil {
+		t.Error("Expected RunLineHandler to return an error when WithStderr was used, but err was nil")
+	}
+}
+
 func TestRunCaptureSplit(t *testing.T) {
 	assertResult := func(command string, expectedTokens ...string) {
 		c := New(command)
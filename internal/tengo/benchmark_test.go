@@ -0,0 +1,212 @@
+package tengo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// This file contains benchmarks of schema introspection and diff computation,
+// using synthetic schemas of several sizes. To run only these benchmarks:
+//
+//	go test -run '^$' -bench 'SchemaDiff|PartitionedTable|InstanceSchema' -benchmem ./internal/tengo
+//
+// BenchmarkInstanceSchema requires SKEEMA_TEST_IMAGES, and is skipped
+// otherwise. To compare performance before and after a change, run each
+// version several times with -count and compare the results with benchstat.
+
+// benchmarkSize describes the dimensions of a synthetic schema: the number of
+// tables, and the number of columns and secondary indexes in each table.
+type benchmarkSize struct {
+	tables  int
+	columns int
+	indexes int
+}
+
+func (size benchmarkSize) String() string {
+	return fmt.Sprintf("%dt-%dc-%di", size.tables, size.columns, size.indexes)
+}
+
+var benchmarkSizes = []benchmarkSize{
+	{tables: 10, columns: 10, indexes: 2},
+	{tables: 100, columns: 20, indexes: 4},
+	{tables: 500, columns: 40, indexes: 6},
+}
+
+// benchmarkColumnTypes are the data types of non-PK columns in synthetic
+// tables, used in rotation. The final type is never indexed.
+var benchmarkColumnTypes = []string{
+	"int unsigned NOT NULL DEFAULT 0",
+	"varchar(100) DEFAULT NULL",
+	"datetime NOT NULL DEFAULT '2000-01-01 00:00:00'",
+	"decimal(10,2) DEFAULT NULL",
+	"text",
+}
+
+// benchmarkTableStatements returns CREATE TABLE statements for a synthetic
+// schema of the supplied size. If altered is true, the statements differ from
+// the unaltered version in ways typical of schema changes: every table gains a
+// column, and every other table also drops an index and modifies a column.
+func benchmarkTableStatements(size benchmarkSize, altered bool) []string {
+	statements := make([]string, 0, size.tables)
+	for t := 1; t <= size.tables; t++ {
+		defs := []string{"id bigint unsigned NOT NULL AUTO_INCREMENT"}
+		var indexable []string
+		for c := 1; c <= size.columns; c++ {
+			name := fmt.Sprintf("col%d", c)
+			typeIndex := (t + c) % len(benchmarkColumnTypes)
+			colType := benchmarkColumnTypes[typeIndex]
+			if altered && t%2 == 0 && c == 1 {
+				colType = "bigint unsigned NOT NULL DEFAULT 0"
+			}
+			defs = append(defs, name+" "+colType)
+			if typeIndex < len(benchmarkColumnTypes)-1 {
+				indexable = append(indexable, name)
+			}
+		}
+		if altered {
+			defs = append(defs, "added_col varchar(40) DEFAULT NULL")
+		}
+		defs = append(defs, "PRIMARY KEY (id)")
+		for i := 1; i <= size.indexes && len(indexable) > 0; i++ {
+			if altered && t%2 == 0 && i == size.indexes {
+				continue
+			}
+			first := indexable[(i-1)%len(indexable)]
+			second := indexable[i%len(indexable)]
+			defs = append(defs, fmt.Sprintf("KEY idx%d (%s, %s)", i, first, second))
+		}
+		statements = append(statements, fmt.Sprintf("CREATE TABLE bench_table%d (\n  %s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", t, strings.Join(defs, ",\n  ")))
+	}
+	return statements
+}
+
+// benchmarkSchema returns a synthetic schema of the supplied size, for use in
+// benchmarks which do not require a database server.
+func benchmarkSchema(tb testing.TB, flavor Flavor, size benchmarkSize, altered bool) *Schema {
+	tb.Helper()
+	schema := &Schema{Name: "bench", CharSet: "utf8mb4"}
+	for _, stmt := range benchmarkTableStatements(size, altered) {
+		table, err := ParseCreateTable(stmt, flavor, "", "")
+		if err != nil {
+			tb.Fatalf("Unable to parse synthetic table: %v\n%s", err, stmt)
+		}
+		schema.Tables = append(schema.Tables, table)
+		schema.Collation = table.Collation
+	}
+	return schema
+}
+
+// TestBenchmarkSchema confirms that the synthetic schemas used in benchmarks
+// have the expected differences.
+func TestBenchmarkSchema(t *testing.T) {
+	flavor := ParseFlavor("mysql:8.0.36")
+	size := benchmarkSizes[0]
+	from := benchmarkSchema(t, flavor, size, false)
+	to := benchmarkSchema(t, flavor, size, true)
+	if len(from.Tables) != size.tables || len(from.Tables[0].Columns) != size.columns+1 || len(from.Tables[0].SecondaryIndexes) != size.indexes {
+		t.Fatalf("Synthetic schema does not have expected size %s", size)
+	}
+	diffs := from.Diff(to).ObjectDiffs()
+	if len(diffs) != size.tables {
+		t.Fatalf("Expected %d object diffs, instead found %d", size.tables, len(diffs))
+	}
+	for _, diff := range diffs {
+		td := diff.(*TableDiff)
+		var tableNum int
+		fmt.Sscanf(td.ObjectKey().Name, "bench_table%d", &tableNum)
+		expectClauses := 1 // every table gains a column
+		if tableNum%2 == 0 {
+			expectClauses = 3 // even-numbered tables also drop an index and modify a column
+		}
+		if clauses := td.alterClauses; len(clauses) != expectClauses {
+			t.Errorf("Expected %d clauses for %s, instead found %d", expectClauses, td.ObjectKey(), len(clauses))
+		}
+	}
+}
+
+// BenchmarkSchemaDiff measures computing the difference between two synthetic
+// schemas, along with generating the DDL for each object difference.
+func BenchmarkSchemaDiff(b *testing.B) {
+	flavor := ParseFlavor("mysql:8.0.36")
+	mods := StatementModifiers{Flavor: flavor, AllowUnsafe: true}
+	for _, size := range benchmarkSizes {
+		from := benchmarkSchema(b, flavor, size, false)
+		to := benchmarkSchema(b, flavor, size, true)
+		b.Run(size.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				_, errs := ObjectDiffStatements(from.Diff(to).ObjectDiffs(), mods)
+				for _, err := range errs {
+					if err != nil {
+						b.Fatalf("Unexpected error from Statement: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPartitionedTable measures generating the CREATE TABLE for a table
+// with many partitions, along with processing its partitioning edge cases.
+func BenchmarkPartitionedTable(b *testing.B) {
+	for _, count := range []int{100, 1000, 10000} {
+		table := manyPartitionsTable(count)
+		table.CreateStatement = strings.Replace(table.CreateStatement, "LESS THAN (10)", "LESS THAN (10) DATA DIRECTORY = '/some/dir'", 1)
+		b.Run(fmt.Sprintf("%dp", count), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				table.GeneratedCreateStatement(FlavorUnknown)
+				fixPartitioningEdgeCases(&table)
+			}
+		})
+	}
+}
+
+// BenchmarkInstanceSchema measures introspection of synthetic schemas on each
+// image in SKEEMA_TEST_IMAGES.
+func BenchmarkInstanceSchema(b *testing.B) {
+	for _, image := range SkeemaTestImages(b) {
+		d, err := GetOrCreateDockerizedInstance(DockerizedInstanceOptions{
+			Name:         fmt.Sprintf("skeema-test-%s", ContainerNameForImage(image)),
+			Image:        image,
+			RootPassword: "fakepw",
+			DataTmpfs:    true,
+		})
+		if err != nil {
+			b.Fatalf("Unable to set up container for %s: %v", image, err)
+		}
+		if err := d.NukeData(); err != nil {
+			b.Fatalf("Unable to clear data on %s: %v", image, err)
+		}
+		for _, size := range benchmarkSizes {
+			schemaName := "bench_" + strings.ReplaceAll(size.String(), "-", "_")
+			if _, err := d.CreateSchema(schemaName, SchemaCreationOptions{}); err != nil {
+				b.Fatalf("Unable to create schema %s on %s: %v", schemaName, image, err)
+			}
+			db, err := d.CachedConnectionPool(schemaName, "")
+			if err != nil {
+				b.Fatalf("Unable to connect to %s: %v", image, err)
+			}
+			for _, stmt := range benchmarkTableStatements(size, false) {
+				if _, err := db.Exec(stmt); err != nil {
+					b.Fatalf("Unable to create synthetic table on %s: %v", image, err)
+				}
+			}
+			b.Run(fmt.Sprintf("%s/%s", ContainerNameForImage(image), size), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					schema, err := d.Schema(schemaName)
+					if err != nil {
+						b.Fatalf("Unexpected error from Schema: %v", err)
+					} else if len(schema.Tables) != size.tables {
+						b.Fatalf("Expected %d tables, instead found %d", size.tables, len(schema.Tables))
+					}
+				}
+			})
+		}
+		if err := SkeemaTestContainerCleanup(d); err != nil {
+			b.Errorf("Unable to clean up container for %s: %v", image, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package tengo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InnoDB limits which are relevant when converting columns to a character set
+// with a larger maximum number of bytes per character.
+const (
+	maxIndexBytes        = 3072  // total key length, with DYNAMIC or COMPRESSED row format
+	maxIndexPartBytesOld = 767   // per-column key length, with COMPACT or REDUNDANT row format
+	maxRowBytes          = 65535 // total of all non-BLOB/TEXT columns
+)
+
+// isUTF8MB4Conversion returns true if toCharSet is utf8mb4 and fromCharSet is
+// a character set which is commonly converted to utf8mb4.
+func isUTF8MB4Conversion(fromCharSet, toCharSet string) bool {
+	if toCharSet != "utf8mb4" {
+		return false
+	}
+	return fromCharSet == "utf8" || fromCharSet == "utf8mb3" || fromCharSet == "latin1"
+}
+
+// charSetConversion returns true if clause converts a column or the table's
+// default character set to utf8mb4 from utf8mb3 or latin1.
+func charSetConversion(clause TableAlterClause) bool {
+	switch clause := clause.(type) {
+	case ModifyColumn:
+		return isUTF8MB4Conversion(clause.OldColumn.CharSet, clause.NewColumn.CharSet)
+	case ChangeCharSet:
+		return isUTF8MB4Conversion(clause.FromCharSet, clause.ToCharSet)
+	}
+	return false
+}
+
+// SplitCharSetConversions separates a TableDiff which converts columns to the
+// utf8mb4 character set into a staged series of TableDiffs: first one
+// containing any unrelated clauses; then one per converted column; and finally
+// one changing the table's default character set and collation, if needed.
+// This way, each conversion runs in a separate ALTER TABLE, and a failure
+// (for example due to index length limits) only affects a single column. If
+// td does not involve any utf8mb4 conversions, the result contains only td.
+func (td *TableDiff) SplitCharSetConversions() (result []*TableDiff) {
+	if td == nil {
+		return nil
+	} else if td.Type != DiffTypeAlter || !td.supported || len(td.alterClauses) == 0 {
+		return []*TableDiff{td}
+	}
+
+	otherClauses := make([]TableAlterClause, 0, len(td.alterClauses))
+	var columnClauses, tableClauses []TableAlterClause
+	for _, clause := range td.alterClauses {
+		if !charSetConversion(clause) {
+			otherClauses = append(otherClauses, clause)
+		} else if _, ok := clause.(ChangeCharSet); ok {
+			tableClauses = append(tableClauses, clause)
+		} else {
+			columnClauses = append(columnClauses, clause)
+		}
+	}
+	if len(columnClauses)+len(tableClauses) == 0 {
+		return []*TableDiff{td}
+	}
+
+	stages := make([][]TableAlterClause, 0, len(columnClauses)+2)
+	if len(otherClauses) > 0 {
+		stages = append(stages, otherClauses)
+	}
+	for n := range columnClauses {
+		stages = append(stages, columnClauses[n:n+1])
+	}
+	if len(tableClauses) > 0 {
+		stages = append(stages, tableClauses)
+	}
+	for _, clauses := range stages {
+		result = append(result, &TableDiff{
+			Type:         DiffTypeAlter,
+			From:         td.From,
+			To:           td.To,
+			alterClauses: clauses,
+			supported:    true,
+		})
+	}
+	return result
+}
+
+// CharSetConversionWarnings returns descriptions of potential problems with
+// converting columns of td to utf8mb4: indexes which would exceed InnoDB's
+// key length limits, TEXT columns whose existing values could exceed the
+// column's maximum length in bytes, and rows which would exceed the maximum
+// row size. The result is nil if td does not convert any columns to utf8mb4,
+// or if no problems were detected. These checks are based only on the table
+// definition, so they may report problems which would not occur with the
+// table's actual data.
+func (td *TableDiff) CharSetConversionWarnings() (warnings []string) {
+	if td == nil || td.Type != DiffTypeAlter || td.To == nil {
+		return nil
+	}
+	converted := make(map[string]*Column)
+	for _, clause := range td.alterClauses {
+		if mc, ok := clause.(ModifyColumn); ok && charSetConversion(mc) {
+			converted[mc.NewColumn.Name] = mc.OldColumn
+		}
+	}
+	if len(converted) == 0 {
+		return nil
+	}
+
+	columnsByName := td.To.ColumnsByName()
+	rowFormat := strings.ToUpper(td.To.RowFormat())
+	oldRowFormat := (rowFormat == "COMPACT" || rowFormat == "REDUNDANT")
+	indexes := td.To.SecondaryIndexes
+	if td.To.PrimaryKey != nil {
+		indexes = append([]*Index{td.To.PrimaryKey}, indexes...)
+	}
+	for _, index := range indexes {
+		if index.Type == "FULLTEXT" || index.Type == "SPATIAL" {
+			continue
+		}
+		var totalBytes uint64
+		var affected bool
+		for _, part := range index.Parts {
+			col := columnsByName[part.ColumnName]
+			if col == nil || col.CharSet == "" {
+				continue
+			}
+			partBytes, ok := col.Type.StringMaxBytes(col.CharSet)
+			if !ok {
+				continue
+			}
+			if part.PrefixLength > 0 {
+				partBytes = uint64(part.PrefixLength) * uint64(characterMaxBytes(col.CharSet))
+			}
+			totalBytes += partBytes
+			if converted[col.Name] == nil {
+				continue
+			}
+			affected = true
+			if oldRowFormat && partBytes > maxIndexPartBytesOld {
+				warnings = append(warnings, fmt.Sprintf("index %s: column %s would require up to %d bytes, exceeding the %d byte limit for ROW_FORMAT=%s", EscapeIdentifier(index.Name), EscapeIdentifier(col.Name), partBytes, maxIndexPartBytesOld, rowFormat))
+			}
+		}
+		if affected && totalBytes > maxIndexBytes {
+			warnings = append(warnings, fmt.Sprintf("index %s would require up to %d bytes, exceeding the %d byte limit", EscapeIdentifier(index.Name), totalBytes, maxIndexBytes))
+		}
+	}
+
+	var rowBytes uint64
+	for _, col := range td.To.Columns {
+		if oldCol := converted[col.Name]; oldCol != nil && oldCol.CharSet == "latin1" && strings.HasSuffix(col.Type.Base, "text") {
+			maxBytes, _ := col.Type.StringMaxBytes(col.CharSet)
+			warnings = append(warnings, fmt.Sprintf("column %s: existing values containing non-ASCII characters may exceed its maximum length of %d bytes once converted from latin1", EscapeIdentifier(col.Name), maxBytes))
+		}
+		if col.Type.Base == "varchar" || col.Type.Base == "char" {
+			maxBytes, _ := col.Type.StringMaxBytes(col.CharSet)
+			rowBytes += maxBytes
+		}
+	}
+	if rowBytes > maxRowBytes {
+		warnings = append(warnings, fmt.Sprintf("CHAR and VARCHAR columns would require up to %d bytes per row, exceeding the %d byte row size limit", rowBytes, maxRowBytes))
+	}
+	return warnings
+}
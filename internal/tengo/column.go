@@ -186,6 +186,9 @@ func (c *Column) Equivalent(other *Column) bool {
 	if collationsEquivalent(c.Collation, other.Collation) {
 		selfCopy.Collation = other.Collation
 	}
+	if defaultsEquivalent(c.Default, other.Default) {
+		selfCopy.Default = other.Default
+	}
 	return selfCopy == *other
 }
 
@@ -206,3 +209,101 @@ func collationsEquivalent(a, b string) bool {
 	}
 	return (aCharset == "utf8" && bCharset == "utf8mb3") || (aCharset == "utf8mb3" && bCharset == "utf8")
 }
+
+// defaultsEquivalent returns true if two column default values are identical,
+// or only differ in ways that don't affect the resulting default. This
+// accounts for flavor differences in how the same default is expressed: for
+// example MariaDB omits the outer parentheses around default expressions,
+// displays function names in lowercase, and does not quote numeric literals;
+// whereas MySQL 8 adds charset introducers to string literals in expressions.
+func defaultsEquivalent(a, b string) bool {
+	if a == b {
+		return true
+	} else if a == "" || b == "" {
+		return false
+	}
+	return canonicalDefault(a) == canonicalDefault(b)
+}
+
+// canonicalDefault returns a normalized representation of a column default
+// value, for use in comparing defaults expressed in different ways. The
+// return value is not valid SQL, and should only be used for comparisons.
+func canonicalDefault(def string) string {
+	var tokens []string
+	var types []TokenType
+	lexer := NewLexer(strings.NewReader(def), "\000", 1024)
+	for {
+		val, typ, err := lexer.Scan()
+		if err != nil {
+			break
+		} else if typ != TokenFiller {
+			tokens = append(tokens, string(val))
+			types = append(types, typ)
+		}
+	}
+
+	// Strip any parentheses which wrap the entire expression
+	for len(tokens) > 2 && tokens[0] == "(" && types[0] == TokenSymbol && closingParenPos(tokens, types) == len(tokens)-1 {
+		tokens, types = tokens[1:len(tokens)-1], types[1:len(types)-1]
+	}
+
+	// A numeric literal, with an optional sign, is equivalent to a string literal
+	// containing the same number
+	if len(tokens) > 0 && types[len(types)-1] == TokenNumeric {
+		signed := true
+		for n := range len(tokens) - 1 {
+			if types[n] != TokenSymbol || (tokens[n] != "-" && tokens[n] != "+") {
+				signed = false
+				break
+			}
+		}
+		if signed {
+			return "'" + strings.Join(tokens, "") + "'"
+		}
+	}
+
+	result := make([]string, 0, len(tokens))
+	for n := 0; n < len(tokens); n++ {
+		switch types[n] {
+		case TokenWord:
+			word := strings.ToUpper(tokens[n])
+			if word[0] == '_' && n+1 < len(tokens) && types[n+1] == TokenString {
+				continue // charset introducer
+			}
+			switch word {
+			case "NOW", "LOCALTIME", "LOCALTIMESTAMP": // synonyms
+				word = "CURRENT_TIMESTAMP"
+			}
+			if word == "CURRENT_TIMESTAMP" && n+2 < len(tokens) && tokens[n+1] == "(" && tokens[n+2] == ")" {
+				n += 2 // empty parens are optional for CURRENT_TIMESTAMP
+			}
+			result = append(result, word)
+		case TokenIdent:
+			result = append(result, strings.ToUpper(stripBackticks(tokens[n])))
+		case TokenString:
+			result = append(result, "'"+EscapeValueForCreateTable(stripAnyQuote(tokens[n]))+"'")
+		default:
+			result = append(result, tokens[n])
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+// closingParenPos returns the position of the closing parenthesis which
+// matches the opening parenthesis at position 0 of tokens, or -1 if there is
+// no match.
+func closingParenPos(tokens []string, types []TokenType) int {
+	var depth int
+	for n := range tokens {
+		if types[n] != TokenSymbol {
+			continue
+		} else if tokens[n] == "(" {
+			depth++
+		} else if tokens[n] == ")" {
+			if depth--; depth == 0 {
+				return n
+			}
+		}
+	}
+	return -1
+}
@@ -101,4 +101,52 @@ func TestColumnEquivalent(t *testing.T) {
 	assertEquivalent(true)
 	*a, *b = *b, *a
 	assertEquivalent(true)
+
+	// Test situations involving default expressions expressed differently
+	// between flavors
+	a = &Column{
+		Name:    "col",
+		Type:    ParseColumnType("varchar(20)"),
+		Default: "(concat(_utf8mb4'a',`other`))",
+	}
+	*b = *a
+	b.Default = "concat('a',`other`)"
+	assertEquivalent(true)
+	b.Default = "CONCAT('b',`other`)"
+	assertEquivalent(false)
+}
+
+func TestDefaultsEquivalent(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"", "", true},
+		{"NULL", "", false},
+		{"'0'", "0", true},
+		{"'-1'", "-1", true},
+		{"'1.5'", "1.50", false},
+		{"'abc'", "_utf8mb4'abc'", true},
+		{"'abc'", "'ABC'", false},
+		{"'it''s'", "'it\\'s'", true},
+		{"'1'", "'1 '", false},
+		{"(now())", "current_timestamp()", true},
+		{"CURRENT_TIMESTAMP", "current_timestamp()", true},
+		{"CURRENT_TIMESTAMP(3)", "current_timestamp(3)", true},
+		{"CURRENT_TIMESTAMP(3)", "current_timestamp()", false},
+		{"(rand() * rand())", "rand() * rand()", true},
+		{"(rand()) * (rand())", "rand() * rand()", false},
+		{"(((1 + 2)))", "1 + 2", true},
+		{"(`a` + 1)", "A+1", true},
+		{"b'1'", "B'1'", true},
+		{"0x01", "'0x01'", false},
+	}
+	for _, c := range cases {
+		if actual := defaultsEquivalent(c.a, c.b); actual != c.expected {
+			t.Errorf("Expected defaultsEquivalent(%q, %q) to return %t, instead found %t", c.a, c.b, c.expected, actual)
+		}
+		if actual := defaultsEquivalent(c.b, c.a); actual != c.expected {
+			t.Errorf("Expected defaultsEquivalent(%q, %q) to return %t, instead found %t", c.b, c.a, c.expected, actual)
+		}
+	}
 }
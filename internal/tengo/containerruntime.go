@@ -0,0 +1,167 @@
+package tengo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/shellout"
+)
+
+// ContainerRuntimes lists the supported container runtime command-line clients,
+// in the order that they are tried when auto-detecting.
+var ContainerRuntimes = []string{"docker", "podman", "nerdctl"}
+
+var (
+	containerRuntimeSetting string   // requested runtime; blank means auto-detect
+	containerRuntime        string   // runtime actually in use, once detected
+	containerRuntimeEnv     []string // extra env vars for runtime commands, e.g. DOCKER_HOST
+	containerHostSetting    string   // requested engine host URL; blank means use env or context
+	containerHost           string   // engine host URL actually in use, once detected; blank if local default
+)
+
+// SetContainerRuntime selects the container runtime command-line client used by
+// DockerizedInstance and related functions. name should be one of the values
+// in ContainerRuntimes, or "auto" or blank to auto-detect the runtime upon
+// first use. Changing the runtime clears any memoized engine information.
+func SetContainerRuntime(name string) error {
+	if name == "auto" {
+		name = ""
+	}
+	if name != "" && !slices.Contains(ContainerRuntimes, name) {
+		return fmt.Errorf("unsupported container runtime %q; valid values are auto, %s", name, strings.Join(ContainerRuntimes, ", "))
+	}
+	if name != containerRuntimeSetting {
+		containerRuntimeSetting = name
+		containerRuntime = ""
+		containerRuntimeEnv = nil
+		dockerEngineArch = ""
+	}
+	return nil
+}
+
+// SetContainerHost selects the container engine host, for example
+// "ssh://user@example.com" to run containers on a remote Docker host. A blank
+// value means to use the runtime client's default, which typically comes from
+// the DOCKER_HOST env variable or the current docker context. Changing the host
+// clears any memoized engine information.
+func SetContainerHost(host string) error {
+	if _, _, err := sshDestination(host); err != nil {
+		return err
+	}
+	if host != containerHostSetting {
+		containerHostSetting = host
+		containerRuntime = ""
+		containerRuntimeEnv = nil
+		containerHost = ""
+		dockerEngineArch = ""
+	}
+	return nil
+}
+
+// hostEnvVar returns the name of the env variable used by runtime to specify
+// its engine host.
+func hostEnvVar(runtime string) string {
+	if runtime == "podman" {
+		return "CONTAINER_HOST"
+	}
+	return "DOCKER_HOST"
+}
+
+// resolveContainerHost returns the engine host URL in use by the current
+// runtime, or a blank string if it cannot be determined or is the default
+// local socket.
+func resolveContainerHost() string {
+	envName := hostEnvVar(containerRuntime)
+	for _, kv := range containerRuntimeEnv {
+		if value, ok := strings.CutPrefix(kv, envName+"="); ok {
+			return value
+		}
+	}
+	if value := os.Getenv(envName); value != "" {
+		return value
+	}
+	if containerRuntime == "docker" {
+		out, err := containerCommand(`context inspect --format "{{.Endpoints.docker.Host}}"`).RunCapture()
+		if err == nil {
+			return strings.TrimSpace(out)
+		}
+	}
+	return ""
+}
+
+// ContainerRuntime returns the name of the container runtime command-line
+// client in use, or a blank string if none has been successfully detected yet.
+func ContainerRuntime() string {
+	return containerRuntime
+}
+
+// containerCommand returns a shellout.Command which runs the container runtime
+// client with the supplied args, which may contain variable placeholders.
+func containerCommand(args string) *shellout.Command {
+	runtime := containerRuntime
+	if runtime == "" {
+		runtime = "docker"
+	}
+	if runtime == "podman" && containerHostSetting != "" {
+		runtime += " --remote"
+	}
+	c := shellout.New(runtime + " " + args)
+	if len(containerRuntimeEnv) > 0 {
+		c = c.WithEnv(containerRuntimeEnv...)
+	}
+	return c
+}
+
+// containerRuntimeCandidates returns the runtimes to try, based on the
+// configured setting and which clients are present on the PATH.
+func containerRuntimeCandidates() []string {
+	if containerRuntimeSetting != "" {
+		return []string{containerRuntimeSetting}
+	}
+	var candidates []string
+	for _, name := range ContainerRuntimes {
+		if _, err := exec.LookPath(name); err == nil {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// podmanSocketPath returns the path to a Podman API socket which is compatible
+// with the Docker engine API, or a blank string if none is found. The rootless
+// socket is preferred over the system one.
+func podmanSocketPath() string {
+	var paths []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		paths = append(paths, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	paths = append(paths, "/run/podman/podman.sock")
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			return path
+		}
+	}
+	return ""
+}
+
+// qualifiedImageName returns image in a form which the current runtime can
+// pull without ambiguity. Podman may refuse to resolve short names such as
+// "mysql:8.0" non-interactively, so images without a registry are qualified
+// with docker.io. Other runtimes already default to docker.io, so image is
+// returned as-is for them.
+func qualifiedImageName(image string) string {
+	if containerRuntime != "podman" {
+		return image
+	}
+	first, _, hasSlash := strings.Cut(image, "/")
+	if hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return image // already includes a registry host
+	} else if !hasSlash {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + image
+}
@@ -3,7 +3,11 @@ package tengo
 import (
 	"errors"
 	"fmt"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pmezard/go-difflib/difflib"
 )
@@ -81,7 +85,9 @@ type StatementModifiers struct {
 	StrictForeignKeyNaming bool             // If true, maintain foreign key definition even if differences are cosmetic (name change, RESTRICT vs NO ACTION, etc)
 	StrictColumnDefinition bool             // If true, maintain column properties that are purely cosmetic (only affects MySQL 8)
 	LaxColumnOrder         bool             // If true, don't modify columns if they only differ by position
+	OmitColumnPosition     bool             // If true, never emit FIRST or AFTER clauses, so new columns are added at the end of the table; implies LaxColumnOrder
 	LaxComments            bool             // If true, don't modify tables/columns/indexes/routines if they only differ by comment clauses
+	IgnoreTableOptions     string           // Comma-separated uppercase names of table options (e.g. "ROW_FORMAT,COMMENT") whose differences are ignored
 	CompareMetadata        bool             // If true, compare creation-time sql_mode and db collation for stored programs
 	VirtualColValidation   bool             // If true, add WITH VALIDATION clause for ALTER TABLE affecting virtual columns
 	SkipPreDropAlters      bool             // If true, skip ALTERs that were only generated to make DROP TABLE faster
@@ -115,34 +121,95 @@ func NewSchemaDiff(from, to *Schema) *SchemaDiff {
 	return result
 }
 
+// diffConcurrencyThreshold is the minimum number of objects for which
+// per-object comparisons or statement generation are spread across multiple
+// goroutines. Below this, the goroutine overhead outweighs any benefit.
+const diffConcurrencyThreshold = 100
+
+// forEachIndex calls f once for each index in [0, count). If count is at least
+// diffConcurrencyThreshold, the calls are made by a pool of up to GOMAXPROCS
+// worker goroutines; otherwise, they are made sequentially. Since calls may be
+// concurrent and in any order, f should only write to its own index of any
+// result slice, so that callers can assemble results in a deterministic order.
+func forEachIndex(count int, f func(n int)) {
+	workers := min(runtime.GOMAXPROCS(0), count)
+	if count < diffConcurrencyThreshold || workers < 2 {
+		for n := range count {
+			f(n)
+		}
+		return
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for n := int(next.Add(1) - 1); n < count; n = int(next.Add(1) - 1) {
+				f(n)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// compareTables returns the table diffs needed to turn from into to. Diffs are
+// ordered based on dependencies between tables: dropped tables come first,
+// with child tables dropped before the parent tables they reference via
+// foreign keys; then other alterations; then created tables, with parent
+// tables created before their children; and finally any ALTER TABLEs adding
+// foreign keys. Within each group, tables are ordered by name. Tables which
+// exist on both sides are compared concurrently in large schemas.
 func compareTables(from, to *Schema) []*TableDiff {
 	var tableDiffs, addFKAlters []*TableDiff
+	var dropped, created []*Table
 	fromByName := from.TablesByName()
 	toByName := to.TablesByName()
 
-	for name, fromTable := range fromByName {
-		toTable, stillExists := toByName[name]
-		if !stillExists {
-			tableDiffs = append(tableDiffs, PreDropAlters(fromTable)...)
-			tableDiffs = append(tableDiffs, NewDropTable(fromTable))
+	fromTables := from.tablesSortedByName()
+	alters := make([][]*TableDiff, len(fromTables))
+	addFKAlterByIndex := make([]*TableDiff, len(fromTables))
+	forEachIndex(len(fromTables), func(n int) {
+		if toTable, stillExists := toByName[fromTables[n].Name]; stillExists {
+			if td := NewAlterTable(fromTables[n], toTable); td != nil {
+				otherAlter, addFKAlter := td.SplitAddForeignKeys()
+				alters[n], addFKAlterByIndex[n] = otherAlter.SplitConflicts(), addFKAlter
+			}
+		}
+	})
+	for n, fromTable := range fromTables {
+		if _, stillExists := toByName[fromTable.Name]; !stillExists {
+			dropped = append(dropped, fromTable)
 			continue
 		}
-		td := NewAlterTable(fromTable, toTable)
-		if td != nil {
-			otherAlter, addFKAlter := td.SplitAddForeignKeys()
-			alters := otherAlter.SplitConflicts()
-			tableDiffs = append(tableDiffs, alters...)
-			if addFKAlter != nil {
-				addFKAlters = append(addFKAlters, addFKAlter)
-			}
+		tableDiffs = append(tableDiffs, alters[n]...)
+		if addFKAlterByIndex[n] != nil {
+			addFKAlters = append(addFKAlters, addFKAlterByIndex[n])
 		}
 	}
-	for name, toTable := range toByName {
-		if _, alreadyExists := fromByName[name]; !alreadyExists {
-			tableDiffs = append(tableDiffs, NewCreateTable(toTable))
+	for _, toTable := range to.tablesSortedByName() {
+		if _, alreadyExists := fromByName[toTable.Name]; !alreadyExists {
+			created = append(created, toTable)
 		}
 	}
 
+	// Drop children before parents, so that the drops are valid even with
+	// foreign_key_checks=1
+	dropped = orderByForeignKeyDependencies(dropped)
+	slices.Reverse(dropped)
+	drops := make([]*TableDiff, 0, len(dropped))
+	for _, table := range dropped {
+		drops = append(drops, PreDropAlters(table)...)
+		drops = append(drops, NewDropTable(table))
+	}
+	tableDiffs = append(drops, tableDiffs...)
+
+	// Create parents before children, so that the creates are valid even with
+	// foreign_key_checks=1
+	for _, table := range orderByForeignKeyDependencies(created) {
+		tableDiffs = append(tableDiffs, NewCreateTable(table))
+	}
+
 	// We put ALTER TABLEs containing ADD FOREIGN KEY last, since the FKs may rely
 	// on tables, columns, or indexes that are being newly created earlier in the
 	// diff. (This is not a comprehensive solution yet though, since FKs can refer
@@ -151,6 +218,50 @@ func compareTables(from, to *Schema) []*TableDiff {
 	return tableDiffs
 }
 
+// tablesSortedByName returns s's tables, ordered by name. It returns nil if s
+// is nil.
+func (s *Schema) tablesSortedByName() []*Table {
+	if s == nil {
+		return nil
+	}
+	tables := slices.Clone(s.Tables)
+	slices.SortFunc(tables, func(a, b *Table) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tables
+}
+
+// orderByForeignKeyDependencies returns tables in an order where each table
+// appears after any other tables in the slice which it references via foreign
+// keys within the same schema. Otherwise, the original relative order is
+// preserved. Cyclic references and self-references are permitted, in which
+// case the order among the tables in the cycle is arbitrary but deterministic.
+func orderByForeignKeyDependencies(tables []*Table) []*Table {
+	byName := make(map[string]*Table, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+	result := make([]*Table, 0, len(tables))
+	visited := make(map[string]bool, len(tables))
+	var visit func(table *Table)
+	visit = func(table *Table) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+		for _, fk := range table.ForeignKeys {
+			if parent := byName[fk.ReferencedTableName]; parent != nil && fk.ReferencedSchemaName == "" {
+				visit(parent)
+			}
+		}
+		result = append(result, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return result
+}
+
 // DatabaseDiff returns an object representing database-level DDL (CREATE
 // DATABASE, ALTER DATABASE, DROP DATABASE), or nil if no database-level DDL
 // is necessary.
@@ -181,16 +292,26 @@ func (sd *SchemaDiff) ObjectDiffs() []ObjectDiff {
 	return result
 }
 
+// ObjectDiffStatements returns the result of calling Statement(mods) on each
+// of diffs. The returned slices are in the same order as diffs, regardless of
+// the fact that statements are generated concurrently for large diffs.
+func ObjectDiffStatements(diffs []ObjectDiff, mods StatementModifiers) (stmts []string, errs []error) {
+	stmts = make([]string, len(diffs))
+	errs = make([]error, len(diffs))
+	forEachIndex(len(diffs), func(n int) {
+		stmts[n], errs[n] = diffs[n].Statement(mods)
+	})
+	return stmts, errs
+}
+
 // String returns the set of differences between two schemas as a single string.
 // In building this string representation, note that no statement modifiers are
 // applied, and any errors from Statement() are ignored. This means the returned
 // string may contain destructive statements, and should only be used for
 // display purposes, not for DDL execution.
 func (sd *SchemaDiff) String() string {
-	allDiffs := sd.ObjectDiffs()
-	diffStatements := make([]string, len(allDiffs))
-	for n, diff := range allDiffs {
-		stmt, _ := diff.Statement(StatementModifiers{})
+	diffStatements, _ := ObjectDiffStatements(sd.ObjectDiffs(), StatementModifiers{})
+	for n, stmt := range diffStatements {
 		diffStatements[n] = fmt.Sprintf("%s;\n", stmt)
 	}
 	return strings.Join(diffStatements, "")
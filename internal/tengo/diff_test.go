@@ -1,6 +1,7 @@
 package tengo
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -40,6 +41,51 @@ func TestSchemaDiffEmpty(t *testing.T) {
 	}
 }
 
+func TestSchemaDiffTableOrder(t *testing.T) {
+	makeTable := func(name string, referencedSchema, referencedTable string) *Table {
+		table := anotherTable()
+		table.Name = name
+		if referencedTable != "" {
+			table.ForeignKeys = []*ForeignKey{{
+				Name:                  name + "_fk",
+				ColumnNames:           []string{"actor_id"},
+				ReferencedSchemaName:  referencedSchema,
+				ReferencedTableName:   referencedTable,
+				ReferencedColumnNames: []string{"actor_id"},
+				UpdateRule:            "RESTRICT",
+				DeleteRule:            "RESTRICT",
+			}}
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(FlavorUnknown)
+		return &table
+	}
+	tables := []*Table{
+		makeTable("m_other", "purchasing", "z_parent"), // cross-schema reference has no effect on order
+		makeTable("a_child", "", "z_parent"),
+		makeTable("z_parent", "", ""),
+		makeTable("b_grandchild", "", "a_child"),
+		makeTable("c_self", "", "c_self"),
+	}
+	empty := aSchema("s1")
+	full := aSchema("s1", tables...)
+
+	diffNames := func(sd *SchemaDiff) []string {
+		var names []string
+		for _, td := range sd.TableDiffs {
+			names = append(names, td.ObjectKey().Name)
+		}
+		return names
+	}
+	creates := diffNames(empty.Diff(&full))
+	if expected := []string{"z_parent", "a_child", "b_grandchild", "c_self", "m_other"}; !slices.Equal(creates, expected) {
+		t.Errorf("Expected CREATE order %v, instead found %v", expected, creates)
+	}
+	drops := diffNames(full.Diff(&empty))
+	if expected := []string{"m_other", "c_self", "b_grandchild", "a_child", "z_parent"}; !slices.Equal(drops, expected) {
+		t.Errorf("Expected DROP order %v, instead found %v", expected, drops)
+	}
+}
+
 func TestSchemaDiffDatabaseDiff(t *testing.T) {
 	assertDiffSchemaDDL := func(a, b *Schema, expectedSchemaDDL string) {
 		sd := NewSchemaDiff(a, b)
@@ -115,3 +161,41 @@ func TestNilObjectDiff(t *testing.T) {
 		t.Errorf("Unexpected return from Statement: %s / %v", stmt, err)
 	}
 }
+
+func TestSchemaDiffConcurrent(t *testing.T) {
+	// Use enough tables to exceed diffConcurrencyThreshold, so that comparisons
+	// and statement generation use multiple goroutines
+	flavor := ParseFlavor("mysql:8.0.36")
+	size := benchmarkSize{tables: diffConcurrencyThreshold * 3, columns: 5, indexes: 2}
+	from := benchmarkSchema(t, flavor, size, false)
+	to := benchmarkSchema(t, flavor, size, true)
+	mods := StatementModifiers{Flavor: flavor, AllowUnsafe: true}
+
+	diffs := from.Diff(to).ObjectDiffs()
+	if len(diffs) != size.tables {
+		t.Fatalf("Expected %d object diffs, instead found %d", size.tables, len(diffs))
+	}
+	names := make([]string, len(diffs))
+	for n, diff := range diffs {
+		names[n] = diff.ObjectKey().Name
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Expected object diffs to be sorted by name, instead found %v", names)
+	}
+
+	stmts, errs := ObjectDiffStatements(diffs, mods)
+	for n, diff := range diffs {
+		expectStmt, expectErr := diff.Statement(mods)
+		if stmts[n] != expectStmt || errs[n] != expectErr {
+			t.Errorf("Mismatch at position %d: expected %q / %v, instead found %q / %v", n, expectStmt, expectErr, stmts[n], errs[n])
+		}
+	}
+
+	// Output should be identical across repeated runs
+	expected := from.Diff(to).String()
+	for range 5 {
+		if actual := from.Diff(to).String(); actual != expected {
+			t.Fatal("SchemaDiff.String returned different output across runs")
+		}
+	}
+}
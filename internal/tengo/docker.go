@@ -13,18 +13,18 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
-	"github.com/skeema/skeema/internal/shellout"
 	terminal "golang.org/x/term"
 )
 
 var dockerEngineArch string
 
-var ErrNoDockerCLI = errors.New("unable to find `docker` command-line client among directories in PATH")
+var ErrNoDockerCLI = errors.New("unable to find a container runtime command-line client (docker, podman, or nerdctl) among directories in PATH")
 
-// checkDockerCLI confirms that we have a working `docker` command-line client
-// binary on the PATH, and it can communicate with a Docker Engine server and
-// fetch the engine's architecture. If successful, this result is memoized so
-// that subsequent calls have no effect.
+// checkDockerCLI confirms that we have a working container runtime command-line
+// client binary on the PATH (typically `docker`, but alternatively `podman` or
+// `nerdctl`; see SetContainerRuntime), and it can communicate with its engine
+// and fetch the engine's architecture. If successful, this result is memoized
+// so that subsequent calls have no effect.
 // This should be called at the start of any exported function that interacts
 // with Docker. It does not need to be called from DockerizedInstance methods
 // though, since if we already have a DockerizedInstance value, it means we've
@@ -33,32 +33,83 @@ func checkDockerCLI() error {
 	if dockerEngineArch != "" {
 		return nil
 	}
-	out, errOut, err := shellout.New(`docker info --format "{{json .}}"`).RunCaptureSeparate()
+	candidates := containerRuntimeCandidates()
+	if len(candidates) == 0 {
+		return ErrNoDockerCLI
+	}
+	var firstErr error
+	for _, runtime := range candidates {
+		containerRuntime, containerRuntimeEnv = runtime, nil
+		if containerHostSetting != "" {
+			containerRuntimeEnv = []string{hostEnvVar(runtime) + "=" + containerHostSetting}
+		}
+		err := fetchEngineArchitecture()
+
+		// The docker client may be installed without a Docker engine, but with a
+		// Podman API socket (typically rootless) available instead
+		if err != nil && runtime == "docker" && containerRuntimeSetting == "" && os.Getenv("DOCKER_HOST") == "" {
+			if socketPath := podmanSocketPath(); socketPath != "" {
+				containerRuntimeEnv = []string{"DOCKER_HOST=unix://" + socketPath}
+				if fetchEngineArchitecture() == nil {
+					err = nil
+				}
+			}
+		}
+		if err == nil {
+			containerHost = resolveContainerHost()
+			if _, _, err = sshDestination(containerHost); err != nil {
+				dockerEngineArch = ""
+			}
+		}
+		if err == nil {
+			return nil
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	containerRuntime, containerRuntimeEnv, containerHost = "", nil, ""
+	return firstErr
+}
+
+// fetchEngineArchitecture queries the current container runtime for its
+// engine's architecture, storing the result in dockerEngineArch.
+func fetchEngineArchitecture() error {
+	out, errOut, err := containerCommand(`info --format "{{json .}}"`).RunCaptureSeparate()
 	if err != nil {
-		if _, pathErr := exec.LookPath("docker"); pathErr != nil && out == "" {
+		if _, pathErr := exec.LookPath(containerRuntime); pathErr != nil && out == "" {
 			return ErrNoDockerCLI
 		}
-		return fmt.Errorf("error invoking `docker` command-line client: %w: %s", err, errOut)
+		return fmt.Errorf("error invoking `%s` command-line client: %w: %s", containerRuntime, err, errOut)
 	}
 	result := struct {
 		ServerErrors []string
-		Architecture string
+		Architecture string // docker and nerdctl
+		Host         struct {
+			Arch string // podman
+		}
 	}{}
 	if err := json.Unmarshal([]byte(out), &result); err != nil {
-		return fmt.Errorf("error decoding JSON response from `docker` command-line client: %w", err)
+		return fmt.Errorf("error decoding JSON response from `%s` command-line client: %w", containerRuntime, err)
 	}
 	if len(result.ServerErrors) > 0 {
-		return fmt.Errorf("error response from Docker engine: %s", strings.Join(result.ServerErrors, "; "))
+		return fmt.Errorf("error response from %s engine: %s", containerRuntime, strings.Join(result.ServerErrors, "; "))
 	}
 
-	dockerEngineArch = result.Architecture
+	arch := result.Architecture
+	if arch == "" {
+		arch = result.Host.Arch
+	}
 	conversions := map[string]string{
 		"x86_64":  "amd64",
 		"aarch64": "arm64",
 	}
-	if converted, ok := conversions[dockerEngineArch]; ok {
-		dockerEngineArch = converted
+	if converted, ok := conversions[arch]; ok {
+		arch = converted
+	}
+	if arch == "" {
+		return fmt.Errorf("unable to determine engine architecture from `%s` command-line client", containerRuntime)
 	}
+	dockerEngineArch = arch
 	return nil
 }
 
@@ -80,10 +131,11 @@ type DockerizedInstanceOptions struct {
 	DefaultConnParams string // Options formatted as URL query string, used for conns to new or existing instance
 
 	// Options that only affect new container creation:
-	DataBindMount       string // Host path to bind-mount as /var/lib/mysql in container
-	DataTmpfs           bool   // Use tmpfs for /var/lib/mysql. Only used if no DataBindMount, and image is from a top-level repo (e.g. "foo" but not "foo/bar")
-	EnableBinlog        bool   // Enable or disable binary log in database server
-	LowerCaseTableNames uint8  // lower_case_table_names setting (0, 1, or 2) in database server
+	DataBindMount       string   // Host path to bind-mount as /var/lib/mysql in container
+	DataTmpfs           bool     // Use tmpfs for /var/lib/mysql. Only used if no DataBindMount, and image is from a top-level repo (e.g. "foo" but not "foo/bar")
+	EnableBinlog        bool     // Enable or disable binary log in database server
+	LowerCaseTableNames uint8    // lower_case_table_names setting (0, 1, or 2) in database server
+	ServerArgs          []string // Additional database server args, e.g. "--sql-mode=...", which take precedence over the defaults
 }
 
 // DockerizedInstance is a database instance running in a local Docker
@@ -93,6 +145,7 @@ type DockerizedInstance struct {
 	containerName    string
 	portMap          map[int]int // keys are container ports, values are host ports
 	hasDataBindMount bool
+	tunnel           *sshTunnel // only used with a remote engine host reached via ssh
 }
 
 // CreateDockerizedInstance attempts to create a database instance inside a
@@ -121,18 +174,51 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 		dflags = append(dflags, "--name {NAME}")
 	}
 	if opts.DataBindMount != "" {
+		if dest, _, _ := sshDestination(containerHost); dest != "" {
+			return nil, errors.New("CreateDockerizedInstance: DataBindMount cannot be used with a remote container engine host")
+		}
 		dflags = append(dflags, "-v {DATABINDMOUNT}")
-	} else if opts.DataTmpfs && (strings.HasPrefix(opts.Image, "mysql:") || strings.HasPrefix(opts.Image, "mariadb:")) {
-		// tmpfs cannot be used with some images, such as Percona Server. For this
-		// reason we only enable tmpfs for mysql and mariadb images from the top-level
-		// (Docker Inc maintained) namespace, since these are known to support it.
+	}
+	dataTmpfs := opts.DataBindMount == "" && opts.DataTmpfs && ImageSupportsDataTmpfs(opts.Image)
+	if dataTmpfs {
 		dflags = append(dflags, "--tmpfs /var/lib/mysql")
 	}
 	flagString := strings.Join(dflags, " ")
 
-	// Because DockerizedInstance is designed for creating special-purpose
-	// instances used only for schema management, we can configure the server in a
-	// way that reduces resource usage and improves performance for this workload
+	serverArgs := EphemeralServerArgs(opts.EnableBinlog, opts.LowerCaseTableNames, dataTmpfs)
+	argString := " " + strings.Join(serverArgs, " ")
+
+	vars := map[string]string{
+		"ROOTPWDENV":    "MYSQL_ROOT_PASSWORD=" + opts.RootPassword,
+		"NAME":          opts.Name,
+		"DATABINDMOUNT": opts.DataBindMount + ":/var/lib/mysql",
+	}
+	// User-supplied args come last, so that they override the defaults. They are
+	// interpolated as variables to ensure proper escaping.
+	for n, arg := range opts.ServerArgs {
+		varName := fmt.Sprintf("SERVERARG%d", n)
+		argString += " {" + varName + "}"
+		vars[varName] = arg
+	}
+	dockerRunArgs := "run " + flagString + " " + qualifiedImageName(opts.Image) + argString
+	c := containerCommand(dockerRunArgs).WithVariablesStrict(vars)
+	out, errOut, err := c.RunCaptureSeparate()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Docker container using `%s`: %w: %s", c, err, errOut)
+	}
+	if opts.Name == "" {
+		opts.Name = strings.TrimSpace(out)
+	}
+	return newDockerizedInstance(opts)
+}
+
+// EphemeralServerArgs returns command-line arguments for a database server
+// process used only for schema management, such as in a DockerizedInstance or
+// other workspace container. Since this is a special-purpose workload, the
+// server can be configured in a way that reduces resource usage and improves
+// performance. inMemory should be true if the data directory is on tmpfs or
+// another non-durable filesystem.
+func EphemeralServerArgs(enableBinlog bool, lowerCaseTableNames uint8, inMemory bool) []string {
 	serverArgs := []string{
 		"--loose-innodb-redo-log-capacity=8388608", // use 8MB total redo log capacity (loose- prefix since only in MySQL 8.0.30+)
 		"--loose-innodb-log-file-size=4194304",     // ditto but for flavors without innodb-redo-log-capacity (loose- prefix since no longer in MySQL 9.3+)
@@ -143,31 +229,29 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 		"--loose-query-cache-size=0",               // ensure query cache completely disabled (loose- prefix since no longer in MySQL 8+)
 		"--skip-innodb-doublewrite",                // not needed for an ephemeral DB; perf impact for data dictionary in MySQL 8.0+
 	}
-	if opts.EnableBinlog {
+	if inMemory {
+		// Durability is meaningless if the data directory is in memory, so skip
+		// flushing the redo log and binary log on each commit
+		serverArgs = append(serverArgs, "--innodb-flush-log-at-trx-commit=0", "--sync-binlog=0")
+	}
+	if enableBinlog {
 		serverArgs = append(serverArgs, "--log-bin", "--server-id=1")
 	} else {
 		serverArgs = append(serverArgs, "--skip-log-bin")
 	}
-	if opts.LowerCaseTableNames > 0 {
-		serverArgs = append(serverArgs, fmt.Sprintf("--lower-case-table-names=%d", opts.LowerCaseTableNames))
+	if lowerCaseTableNames > 0 {
+		serverArgs = append(serverArgs, fmt.Sprintf("--lower-case-table-names=%d", lowerCaseTableNames))
 	}
-	argString := " " + strings.Join(serverArgs, " ")
+	return serverArgs
+}
 
-	vars := map[string]string{
-		"ROOTPWDENV":    "MYSQL_ROOT_PASSWORD=" + opts.RootPassword,
-		"NAME":          opts.Name,
-		"DATABINDMOUNT": opts.DataBindMount + ":/var/lib/mysql",
-	}
-	dockerRunCmd := "docker run " + flagString + " " + opts.Image + argString
-	c := shellout.New(dockerRunCmd).WithVariablesStrict(vars)
-	out, errOut, err := c.RunCaptureSeparate()
-	if err != nil {
-		return nil, fmt.Errorf("unable to create Docker container using `%s`: %w: %s", c, err, errOut)
-	}
-	if opts.Name == "" {
-		opts.Name = strings.TrimSpace(out)
-	}
-	return newDockerizedInstance(opts)
+// ImageSupportsDataTmpfs returns true if containers using image may store their
+// data directory on tmpfs. tmpfs cannot be used with some images, such as
+// Percona Server. For this reason we only enable tmpfs for mysql and mariadb
+// images from the top-level (Docker Inc maintained) namespace, since these are
+// known to support it.
+func ImageSupportsDataTmpfs(image string) bool {
+	return strings.HasPrefix(image, "mysql:") || strings.HasPrefix(image, "mariadb:")
 }
 
 // GetInstance attempts to find an existing container with name equal to
@@ -234,7 +318,7 @@ func newDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance,
 		vars := map[string]string{
 			"NAME": opts.Name,
 		}
-		s := shellout.New("docker logs --tail 100 {NAME}").WithVariablesStrict(vars)
+		s := containerCommand("logs --tail 100 {NAME}").WithVariablesStrict(vars)
 		if logs, logErr := s.RunCaptureCombined(); logErr == nil {
 			err = fmt.Errorf("%w\nLast 100 lines of container logs:\n%s", err, logs)
 		}
@@ -250,6 +334,9 @@ func newDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance,
 
 // hydratePortMap populates di.portMap, if this hasn't already happened.
 func (di *DockerizedInstance) hydratePortMap() (err error) {
+	if di.tunnel != nil && len(di.portMap) > 0 {
+		return nil // already mapped through a tunnel which is still open
+	}
 	if di.portMap == nil {
 		di.portMap = make(map[int]int)
 	}
@@ -257,8 +344,8 @@ func (di *DockerizedInstance) hydratePortMap() (err error) {
 	vars := map[string]string{
 		"NAME": di.containerName,
 	}
-	inspectCommand := `docker inspect --type container --format="{{json .NetworkSettings.Ports}}" {NAME}`
-	c := shellout.New(inspectCommand).WithVariablesStrict(vars)
+	inspectArgs := `inspect --type container --format="{{json .NetworkSettings.Ports}}" {NAME}`
+	c := containerCommand(inspectArgs).WithVariablesStrict(vars)
 
 	// Attempt this up to 5 times, since the port mapping often isn't immediately
 	// available right after starting the container.
@@ -288,10 +375,48 @@ func (di *DockerizedInstance) hydratePortMap() (err error) {
 		err = fmt.Errorf("Unable to find port mapping for container %s: %w", di.containerName, err)
 	} else if di.portMap[3306] == 0 {
 		err = fmt.Errorf("Unable to find port mapping for container %s", di.containerName)
+	} else {
+		err = di.tunnelPortMap()
 	}
 	return err
 }
 
+// tunnelPortMap handles containers on a remote engine host reached via ssh.
+// Containers only publish ports on the engine host's loopback interface, so an
+// ssh tunnel is started and di.portMap is rewritten to use the local end of the
+// tunnel. This is a no-op for local engine hosts.
+func (di *DockerizedInstance) tunnelPortMap() error {
+	dest, sshPort, err := sshDestination(containerHost)
+	if dest == "" || err != nil {
+		return err
+	}
+	remotePorts := make([]int, 0, len(di.portMap))
+	for _, hostPort := range di.portMap {
+		remotePorts = append(remotePorts, hostPort)
+	}
+	tunnel, localPorts, err := newSSHTunnel(dest, sshPort, remotePorts)
+	if err != nil {
+		return fmt.Errorf("Unable to reach container %s on %s: %w", di.containerName, containerHost, err)
+	}
+	di.tunnel = tunnel
+	for containerPort, hostPort := range di.portMap {
+		di.portMap[containerPort] = localPorts[hostPort]
+	}
+	return nil
+}
+
+// CloseTunnel terminates the ssh tunnel used to reach a container on a remote
+// engine host, if any. Callers leaving a container running should call this
+// before exiting, since Stop and Destroy only close the tunnel automatically.
+// The tunnel is reopened as needed by a subsequent call to Start.
+func (di *DockerizedInstance) CloseTunnel() {
+	if di.tunnel != nil {
+		di.tunnel.Close()
+		di.tunnel = nil
+		di.portMap = nil // values referred to the local end of the tunnel
+	}
+}
+
 // GetOrCreateDockerizedInstance attempts to fetch an existing Docker container
 // with name equal to opts.Name. If it exists and its image (or flavor) matches
 // opts.Image, and there are no errors starting or connecting to the instance,
@@ -352,7 +477,7 @@ func StartDockerContainer(name string) error {
 	vars := map[string]string{
 		"NAME": name,
 	}
-	s := shellout.New("docker start {NAME}").WithVariablesStrict(vars)
+	s := containerCommand("start {NAME}").WithVariablesStrict(vars)
 	out, err := s.RunCaptureCombined()
 	if err != nil {
 		err = fmt.Errorf("%w: %s", err, out)
@@ -366,6 +491,7 @@ func StartDockerContainer(name string) error {
 func (di *DockerizedInstance) Stop() error {
 	di.CloseAll()
 	di.portMap = nil
+	di.CloseTunnel()
 	return StopDockerContainer(di.containerName)
 }
 
@@ -377,7 +503,7 @@ func StopDockerContainer(name string) error {
 	vars := map[string]string{
 		"NAME": name,
 	}
-	s := shellout.New("docker stop {NAME}").WithVariablesStrict(vars)
+	s := containerCommand("stop {NAME}").WithVariablesStrict(vars)
 	out, err := s.RunCaptureCombined()
 	if err != nil {
 		err = fmt.Errorf("%w: %s", err, out)
@@ -389,10 +515,17 @@ func StopDockerContainer(name string) error {
 func (di *DockerizedInstance) Destroy() error {
 	di.CloseAll()
 	di.portMap = nil
+	di.CloseTunnel()
+	return DestroyDockerContainer(di.containerName)
+}
+
+// DestroyDockerContainer shells out to `docker rm -v -f` for the supplied
+// container name, stopping and deleting it along with its anonymous volumes.
+func DestroyDockerContainer(name string) error {
 	vars := map[string]string{
-		"NAME": di.containerName,
+		"NAME": name,
 	}
-	s := shellout.New("docker rm -v -f {NAME}").WithVariablesStrict(vars)
+	s := containerCommand("rm -v -f {NAME}").WithVariablesStrict(vars)
 	_, err := s.RunCaptureCombined()
 	return err
 }
@@ -493,7 +626,8 @@ func (di *DockerizedInstance) NukeData() error {
 // the containerized mysql-server. Each file should contain one or more valid
 // SQL instructions, typically a mix of DML and/or DDL statements. This is
 // useful as a per-test setup method in implementations of
-// IntegrationTestSuite.BeforeTest.
+// IntegrationTestSuite.BeforeTest. The files are streamed to the container
+// over STDIN, so this also works with a remote container engine host.
 func (di *DockerizedInstance) SourceSQL(filePaths ...string) (string, error) {
 	readers := make([]io.Reader, len(filePaths))
 	for n := range filePaths {
@@ -551,20 +685,23 @@ func (di *DockerizedInstance) Exec(cmd []string, stdin io.Reader) (stdoutStr str
 		cmdPlaceholders[n] = "{" + varKey + "}"
 	}
 
-	commandString := "docker exec " + strings.Join(dflags, " ") + " {NAME} " + strings.Join(cmdPlaceholders, " ")
-	s := shellout.New(commandString).WithStdin(stdin).WithVariablesStrict(vars)
+	execArgs := "exec " + strings.Join(dflags, " ") + " {NAME} " + strings.Join(cmdPlaceholders, " ")
+	s := containerCommand(execArgs).WithStdin(stdin).WithVariablesStrict(vars)
 	return s.RunCaptureSeparate()
 }
 
 // PutFile copies a file or directory from the host to the container by shelling
 // out to `docker cp`. For edge cases involving directories, nonexistent paths,
 // etc refer to https://docs.docker.com/reference/cli/docker/container/cp/.
+// The file is transferred through the engine API, so src need only exist on the
+// local host even when using a remote container engine host, for example to
+// install TLS certificates.
 func (di *DockerizedInstance) PutFile(src, dest string) error {
 	vars := map[string]string{
 		"SRC":  src,
 		"DEST": di.containerName + ":" + dest,
 	}
-	out, err := shellout.New("docker cp {SRC} {DEST}").WithVariablesStrict(vars).RunCaptureCombined()
+	out, err := containerCommand("cp {SRC} {DEST}").WithVariablesStrict(vars).RunCaptureCombined()
 	if err != nil {
 		return fmt.Errorf("%w: %s", err, out)
 	}
@@ -174,3 +174,97 @@ func TestContainerNameForImage(t *testing.T) {
 		}
 	}
 }
+
+func TestImageSupportsDataTmpfs(t *testing.T) {
+	testcases := map[string]bool{
+		"mysql:8.0":                  true,
+		"mariadb:11.4":               true,
+		"percona:8.0":                false,
+		"percona/percona-server:8.0": false,
+		"mysql/mysql-server:8.0":     false,
+	}
+	for input, expected := range testcases {
+		if actual := ImageSupportsDataTmpfs(input); actual != expected {
+			t.Errorf("Expected ImageSupportsDataTmpfs(%q) to return %t, instead found %t", input, expected, actual)
+		}
+	}
+}
+
+func TestSetContainerRuntime(t *testing.T) {
+	defer SetContainerRuntime("auto")
+	if err := SetContainerRuntime("lxc"); err == nil {
+		t.Error("Expected error from SetContainerRuntime with unsupported runtime, but err was nil")
+	}
+	dockerEngineArch = "amd64"
+	if err := SetContainerRuntime("podman"); err != nil {
+		t.Fatalf("Unexpected error from SetContainerRuntime: %v", err)
+	} else if dockerEngineArch != "" {
+		t.Error("Expected SetContainerRuntime to clear memoized engine architecture")
+	}
+
+	// With a blank PATH, an explicitly-configured runtime cannot be found
+	t.Setenv("PATH", "")
+	if err := checkDockerCLI(); err == nil {
+		t.Error("Expected checkDockerCLI to fail with blank PATH, but err was nil")
+	} else if ContainerRuntime() != "" {
+		t.Errorf("Expected ContainerRuntime to be blank after failure, instead found %q", ContainerRuntime())
+	}
+}
+
+func TestQualifiedImageName(t *testing.T) {
+	defer func() { containerRuntime = "" }()
+	testcases := map[string]string{
+		"mysql:8.0":                           "docker.io/library/mysql:8.0",
+		"percona/percona-server:8.0":          "docker.io/percona/percona-server:8.0",
+		"container-registry.oracle.com/mysql": "container-registry.oracle.com/mysql",
+		"localhost/mysql:8.0":                 "localhost/mysql:8.0",
+		"localhost:5000/mysql:8.0":            "localhost:5000/mysql:8.0",
+	}
+	containerRuntime = "podman"
+	for input, expected := range testcases {
+		if actual := qualifiedImageName(input); actual != expected {
+			t.Errorf("Expected qualifiedImageName(%q) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+	containerRuntime = "docker"
+	if actual := qualifiedImageName("mysql:8.0"); actual != "mysql:8.0" {
+		t.Errorf("Expected qualifiedImageName to leave image as-is for docker, instead found %q", actual)
+	}
+}
+
+func TestSSHDestination(t *testing.T) {
+	testcases := []struct {
+		engineHost   string
+		expectedDest string
+		expectedPort string
+		expectErr    bool
+	}{
+		{"", "", "", false},
+		{"unix:///var/run/docker.sock", "", "", false},
+		{"tcp://127.0.0.1:2375", "", "", false},
+		{"tcp://localhost:2375", "", "", false},
+		{"ssh://docker.example.com", "docker.example.com", "", false},
+		{"ssh://deploy@docker.example.com:2222", "deploy@docker.example.com", "2222", false},
+		{"tcp://docker.example.com:2376", "", "", true},
+		{"ssh://%zz", "", "", true},
+	}
+	for _, tc := range testcases {
+		dest, port, err := sshDestination(tc.engineHost)
+		if dest != tc.expectedDest || port != tc.expectedPort || (err != nil) != tc.expectErr {
+			t.Errorf("Unexpected return from sshDestination(%q): %q, %q, %v", tc.engineHost, dest, port, err)
+		}
+	}
+}
+
+func TestSetContainerHost(t *testing.T) {
+	defer SetContainerHost("")
+	if err := SetContainerHost("tcp://docker.example.com:2376"); err == nil {
+		t.Error("Expected error from SetContainerHost with remote tcp host, but err was nil")
+	}
+	dockerEngineArch = "amd64"
+	if err := SetContainerHost("ssh://docker.example.com"); err != nil {
+		t.Fatalf("Unexpected error from SetContainerHost: %v", err)
+	} else if dockerEngineArch != "" {
+		t.Error("Expected SetContainerHost to clear memoized engine architecture")
+	}
+}
@@ -19,6 +19,7 @@ const (
 
 	ER_LOCK_DEADLOCK     = 1213
 	ER_LOCK_WAIT_TIMEOUT = 1205
+	ER_LOCK_ABORTED      = 1689
 
 	ER_UNKNOWN_SYSTEM_VARIABLE    = 1193
 	ER_INCORRECT_GLOBAL_LOCAL_VAR = 1238
@@ -28,6 +29,9 @@ const (
 
 	ER_ACCESS_DENIED_ERROR          = 1045
 	ER_SPECIFIC_ACCESS_DENIED_ERROR = 1227
+
+	ER_ALTER_OPERATION_NOT_SUPPORTED        = 1845
+	ER_ALTER_OPERATION_NOT_SUPPORTED_REASON = 1846
 )
 
 // IsDatabaseError returns true if err came from a database server, typically
@@ -69,6 +73,14 @@ func IsLockConflictError(err error) bool {
 	return IsDatabaseError(err, ER_LOCK_DEADLOCK, ER_LOCK_WAIT_TIMEOUT)
 }
 
+// IsTransientLockError returns true if err is a lock conflict error as per
+// IsLockConflictError, or indicates that a metadata lock wait was aborted due
+// to a pending exclusive lock. Statements failing with these errors may succeed
+// if simply retried later.
+func IsTransientLockError(err error) bool {
+	return IsDatabaseError(err, ER_LOCK_DEADLOCK, ER_LOCK_WAIT_TIMEOUT, ER_LOCK_ABORTED)
+}
+
 // IsSessionVarNameError returns true if err indicates a session variable name
 // does not exist, or is read-only, or only exists at the global scope.
 func IsSessionVarNameError(err error) bool {
@@ -92,3 +104,9 @@ func IsAccessDeniedError(err error) bool {
 func IsAccessPrivilegeError(err error) bool {
 	return IsDatabaseError(err, ER_SPECIFIC_ACCESS_DENIED_ERROR)
 }
+
+// IsAlterAlgorithmError returns true if err indicates that the requested
+// ALGORITHM or LOCK clause is not supported for an ALTER TABLE.
+func IsAlterAlgorithmError(err error) bool {
+	return IsDatabaseError(err, ER_ALTER_OPERATION_NOT_SUPPORTED, ER_ALTER_OPERATION_NOT_SUPPORTED_REASON)
+}
@@ -0,0 +1,147 @@
+package tengo
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// metadataSource describes an information_schema table which contributes to
+// schema metadata fingerprints. Columns whose values vary without any change
+// to object definitions, such as row counts or timestamps, are excluded.
+type metadataSource struct {
+	table     string
+	schemaCol string
+	exclude   []string
+	optional  bool // true if the table does not exist in all supported flavors
+}
+
+var volatileTableColumns = []string{
+	"table_rows", "avg_row_length", "data_length", "max_data_length", "index_length",
+	"data_free", "auto_increment", "create_time", "update_time", "check_time", "checksum",
+}
+
+var metadataSources = []metadataSource{
+	{table: "schemata", schemaCol: "schema_name"},
+	{table: "tables", schemaCol: "table_schema", exclude: volatileTableColumns},
+	{table: "columns", schemaCol: "table_schema"},
+	{table: "statistics", schemaCol: "table_schema", exclude: []string{"cardinality"}},
+	{table: "table_constraints", schemaCol: "table_schema"},
+	{table: "key_column_usage", schemaCol: "table_schema"},
+	{table: "referential_constraints", schemaCol: "constraint_schema"},
+	{table: "check_constraints", schemaCol: "constraint_schema", optional: true},
+	{table: "partitions", schemaCol: "table_schema", exclude: volatileTableColumns},
+	{table: "routines", schemaCol: "routine_schema", exclude: []string{"created", "last_altered"}},
+	{table: "parameters", schemaCol: "specific_schema", optional: true},
+}
+
+// fingerprintBatchSize limits how many schema names are supplied to a single
+// information_schema query by SchemaFingerprints.
+const fingerprintBatchSize = 500
+
+// SchemaFingerprints returns a map of schema name to a checksum of the
+// information_schema metadata describing that schema's default character set
+// and collation, tables, and routines. Schemas with equal fingerprints have
+// identical object definitions, apart from next AUTO_INCREMENT values, and
+// therefore introspect identically apart from their names. Nonexistent
+// schemas are omitted from the result.
+//
+// This is far less expensive than full introspection when many schemas are
+// involved, since only a fixed number of queries is run per batch of schema
+// names, rather than per schema or per table. The converse is not guaranteed:
+// identical schemas may have different fingerprints, for example if their
+// objects were created in a different order.
+func (instance *Instance) SchemaFingerprints(names ...string) (map[string]string, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(names))
+	for len(names) > 0 {
+		batch := names[:min(len(names), fingerprintBatchSize)]
+		names = names[len(batch):]
+		hashes := make(map[string]hash.Hash, len(batch))
+		for _, source := range metadataSources {
+			if err := hashMetadataSource(db, source, batch, hashes); err != nil {
+				return nil, err
+			}
+		}
+		for name, h := range hashes {
+			result[name] = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	return result, nil
+}
+
+// hashMetadataSource queries source for the supplied schema names, writing the
+// contents of each row to the corresponding schema's hash. Values of columns
+// containing schema names are omitted, so that schemas with identical
+// definitions but different names yield identical hashes.
+func hashMetadataSource(db *sqlx.DB, source metadataSource, names []string, hashes map[string]hash.Hash) error {
+	query, args, err := sqlx.In(fmt.Sprintf("SELECT * FROM information_schema.%s WHERE %s IN (?)", source.table, source.schemaCol), names)
+	if err != nil {
+		return err
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		if source.optional {
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	schemaIndex := -1
+	include := make([]bool, len(cols))
+	for n, col := range cols {
+		col = strings.ToLower(col)
+		if col == source.schemaCol {
+			schemaIndex = n
+		}
+		include[n] = !strings.HasSuffix(col, "_schema") && col != "schema_name" && !strings.HasSuffix(col, "_catalog") && col != "catalog_name"
+		for _, exclude := range source.exclude {
+			if col == exclude {
+				include[n] = false
+			}
+		}
+	}
+	if schemaIndex < 0 {
+		return fmt.Errorf("column %s not found in information_schema.%s", source.schemaCol, source.table)
+	}
+
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for n := range values {
+		dest[n] = &values[n]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		h := hashes[values[schemaIndex].String]
+		if h == nil {
+			h = sha256.New()
+			hashes[values[schemaIndex].String] = h
+		}
+		h.Write([]byte(source.table))
+		for n, value := range values {
+			if !include[n] {
+				continue
+			}
+			if value.Valid {
+				h.Write([]byte{1})
+				h.Write([]byte(value.String))
+			}
+			h.Write([]byte{0})
+		}
+	}
+	return rows.Err()
+}
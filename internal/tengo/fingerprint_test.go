@@ -0,0 +1,49 @@
+package tengo
+
+import (
+	"testing"
+)
+
+func (s TengoIntegrationSuite) TestInstanceSchemaFingerprints(t *testing.T) {
+	opts := SchemaCreationOptions{
+		DefaultCharSet:   "utf8mb4",
+		DefaultCollation: "utf8mb4_unicode_ci",
+	}
+	names := []string{"tenant1", "tenant2", "tenant3", "tenant4"}
+	for _, name := range names {
+		if _, err := s.d.CreateSchema(name, opts); err != nil {
+			t.Fatalf("Unexpected error from CreateSchema: %v", err)
+		}
+	}
+	db, err := s.d.CachedConnectionPool("", "")
+	if err != nil {
+		t.Fatalf("Unable to obtain connection pool: %v", err)
+	}
+	for _, query := range []string{
+		"CREATE TABLE tenant1.users (id int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(40)) AUTO_INCREMENT=100",
+		"CREATE TABLE tenant2.users (id int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(40))",
+		"CREATE TABLE tenant3.users (id int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(50))",
+		"INSERT INTO tenant2.users (name) VALUES ('alice'), ('bob')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Unexpected error from query %q: %v", query, err)
+		}
+	}
+
+	fingerprints, err := s.d.SchemaFingerprints(append(names, "doesnt_exist")...)
+	if err != nil {
+		t.Fatalf("Unexpected error from SchemaFingerprints: %v", err)
+	}
+	if len(fingerprints) != len(names) {
+		t.Fatalf("Expected %d fingerprints, instead found %d: %v", len(names), len(fingerprints), fingerprints)
+	}
+	if fingerprints["tenant1"] != fingerprints["tenant2"] {
+		t.Error("Expected schemas differing only by row data and AUTO_INCREMENT to have equal fingerprints, but they differed")
+	}
+	if fingerprints["tenant1"] == fingerprints["tenant3"] {
+		t.Error("Expected schemas with different column definitions to have different fingerprints, but they were equal")
+	}
+	if fingerprints["tenant1"] == fingerprints["tenant4"] {
+		t.Error("Expected empty schema to have a different fingerprint than a schema with tables, but they were equal")
+	}
+}
@@ -0,0 +1,109 @@
+package tengo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// This file contains native Go fuzz targets for the statement parser and the
+// CREATE TABLE parser. Without the -fuzz flag, these only run their seed
+// corpus, along with any regression inputs in testdata/fuzz. To fuzz, run for
+// example:
+//
+//	go test -run '^$' -fuzz FuzzParseCreateTable -fuzztime 5m ./internal/tengo
+//
+// Any failing input is saved by the fuzzer to testdata/fuzz/<FuzzTargetName>,
+// and should be committed along with the fix, to serve as a regression test.
+
+// fuzzFlavors are the flavors used by FuzzParseCreateTable.
+var fuzzFlavors = []Flavor{
+	FlavorUnknown,
+	ParseFlavor("mysql:5.7"),
+	ParseFlavor("mysql:8.0.35"),
+	ParseFlavor("mariadb:10.6"),
+	ParseFlavor("mariadb:11.4"),
+}
+
+// addFuzzSeedStatements adds the text of every statement in testdata/*.sql to
+// the seed corpus of f. If keep is non-nil, only statements for which it
+// returns true are added.
+func addFuzzSeedStatements(f *testing.F, keep func(*Statement) bool) {
+	f.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "*.sql"))
+	if err != nil {
+		f.Fatalf("Unable to list testdata: %v", err)
+	}
+	for _, file := range files {
+		// Files may intentionally contain errors, so use whatever parses
+		statements, _ := ParseStatementsInFile(file)
+		for _, stmt := range statements {
+			if keep == nil || keep(stmt) {
+				f.Add(stmt.Text)
+			}
+		}
+	}
+}
+
+// FuzzParseStatements confirms the statement parser does not panic on any
+// input, and that successfully-parsed statements exactly represent their
+// input, as documented by ParseStatements.
+func FuzzParseStatements(f *testing.F) {
+	addFuzzSeedStatements(f, nil)
+	f.Add("DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\nDELIMITER ;\n")
+	f.Add("\uFEFFUSE `db`;\nCREATE TABLE t (id int) /* trailing */;")
+	f.Add("CREATE TABLE `unterminated (id int")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		statements, err := ParseStatementsInString(input)
+		if err != nil {
+			return
+		}
+		var b strings.Builder
+		for _, stmt := range statements {
+			if stmt == nil {
+				t.Fatalf("ParseStatementsInString returned a nil statement for input %q", input)
+			}
+			b.WriteString(stmt.Text)
+		}
+		if b.String() != input {
+			t.Errorf("Parsed statements do not represent input\nInput:  %q\nParsed: %q", input, b.String())
+		}
+	})
+}
+
+// FuzzParseCreateTable confirms ParseCreateTable does not panic on any input,
+// and that its output round-trips: if a statement parses successfully, then
+// the generated CREATE TABLE must also parse successfully, yielding an
+// identical statement and a table with no differences from the first.
+func FuzzParseCreateTable(f *testing.F) {
+	addFuzzSeedStatements(f, func(stmt *Statement) bool {
+		return stmt.Type == StatementTypeCreate && stmt.ObjectType == ObjectTypeTable
+	})
+	for _, flavor := range fuzzFlavors {
+		for _, table := range []Table{aTableForFlavor(flavor, 1), anotherTableForFlavor(flavor), supportedTableForFlavor(flavor)} {
+			f.Add(table.CreateStatement)
+		}
+	}
+	f.Add(foreignKeyTable().CreateStatement)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, flavor := range fuzzFlavors {
+			table, err := ParseCreateTable(input, flavor, "", "")
+			if err != nil {
+				continue
+			} else if table == nil {
+				t.Fatalf("Flavor %s: ParseCreateTable returned nil table without an error for input %q", flavor, input)
+			}
+			reparsed, err := ParseCreateTable(table.CreateStatement, flavor, "", "")
+			if err != nil {
+				t.Fatalf("Flavor %s: Unable to re-parse generated CREATE TABLE: %v\nInput:\n%s\nGenerated:\n%s", flavor, err, input, table.CreateStatement)
+			}
+			if reparsed.CreateStatement != table.CreateStatement {
+				t.Errorf("Flavor %s: Re-parsed CREATE TABLE does not match\nInput:\n%s\nGenerated:\n%s\nRe-parsed:\n%s", flavor, input, table.CreateStatement, reparsed.CreateStatement)
+			} else if clauses, supported := table.Diff(reparsed); len(clauses) > 0 || !supported {
+				t.Errorf("Flavor %s: Re-parsed table unexpectedly differs: %+v\nInput:\n%s", flavor, clauses, input)
+			}
+		}
+	})
+}
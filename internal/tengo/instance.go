@@ -38,6 +38,8 @@ type Instance struct {
 	lowerCaseNames  int
 	sqlMode         []string
 	valid           bool // true if any conn has ever successfully been made yet
+	passwordFunc    func() (string, error)
+	poolOptions     PoolOptions
 }
 
 // NewInstance returns a pointer to a new Instance corresponding to the
@@ -95,6 +97,48 @@ func (instance *Instance) String() string {
 	}
 }
 
+// SetPasswordFunc configures the instance to obtain a password from f each time
+// a new connection is established, instead of using the static password from
+// its DSN. This is intended for short-lived credentials, such as cloud IAM
+// authentication tokens. It should be called before any connection pools are
+// created for the instance.
+func (instance *Instance) SetPasswordFunc(f func() (string, error)) {
+	instance.m.Lock()
+	defer instance.m.Unlock()
+	instance.passwordFunc = f
+}
+
+// CurrentPassword returns the password which would be used for a new
+// connection to the instance. This is useful for passing credentials to
+// external programs.
+func (instance *Instance) CurrentPassword() (string, error) {
+	instance.m.Lock()
+	f := instance.passwordFunc
+	instance.m.Unlock()
+	if f == nil {
+		return instance.Password, nil
+	}
+	return f()
+}
+
+// PoolOptions overrides the automatic tuning of an Instance's connection
+// pools. Any zero-valued field retains the automatic behavior.
+type PoolOptions struct {
+	MaxOpenConns    int           // max open conns per pool; default based on server's connection limits
+	MaxIdleConns    int           // max idle conns per pool; default 2, or 20 for introspection
+	ConnMaxLifetime time.Duration // max reuse lifetime of a conn; default 1 minute
+	ConnMaxIdleTime time.Duration // max idle time of a conn; default based on server's wait_timeout, up to 10s
+}
+
+// SetPoolOptions configures the instance to use opts when creating connection
+// pools. It should be called before any connection pools are created for the
+// instance, as pre-existing pools are not affected.
+func (instance *Instance) SetPoolOptions(opts PoolOptions) {
+	instance.m.Lock()
+	defer instance.m.Unlock()
+	instance.poolOptions = opts
+}
+
 // BuildParamString returns a DB connection parameter string, which first takes
 // the instance's default params and then applies overrides on top.
 // The arg should be a URL query string formatted value, for example
@@ -183,7 +227,13 @@ func (instance *Instance) CachedConnectionPool(defaultSchema, params string) (*s
 
 func (instance *Instance) rawConnectionPool(defaultSchema, fullParams string, alreadyLocked bool) (*sqlx.DB, error) {
 	fullDSN := instance.BaseDSN + defaultSchema + "?" + fullParams
-	db, err := sqlx.Connect(instance.Driver, fullDSN)
+	var db *sqlx.DB
+	var err error
+	if instance.passwordFunc == nil {
+		db, err = sqlx.Connect(instance.Driver, fullDSN)
+	} else {
+		db, err = connectWithPasswordFunc(instance.Driver, fullDSN, instance.passwordFunc)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -195,16 +245,29 @@ func (instance *Instance) rawConnectionPool(defaultSchema, fullParams string, al
 	// the database side either globally or for this user. This does not completely
 	// eliminate max-conn problems, because each Instance can have many separate
 	// connection pools, but it may help.
-	if instance.maxUserConns < 12 {
+	// Any of these may be overridden by the instance's PoolOptions.
+	opts := instance.poolOptions
+	if opts.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+	} else if instance.maxUserConns < 12 {
 		db.SetMaxOpenConns(2)
 	} else {
 		db.SetMaxOpenConns(instance.maxUserConns - 10)
 	}
+	if opts.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(opts.MaxIdleConns)
+	}
 
 	// Set max conn reuse lifetime to 1 minute, and set max idle time based on
 	// the session wait_timeout or 10s max.
-	db.SetConnMaxLifetime(time.Minute)
-	if instance.waitTimeout <= 10 {
+	if opts.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	} else {
+		db.SetConnMaxLifetime(time.Minute)
+	}
+	if opts.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	} else if instance.waitTimeout <= 10 {
 		db.SetConnMaxIdleTime((time.Duration(instance.waitTimeout) * time.Second) - (250 * time.Millisecond))
 	} else {
 		db.SetConnMaxIdleTime(10 * time.Second)
@@ -213,6 +276,32 @@ func (instance *Instance) rawConnectionPool(defaultSchema, fullParams string, al
 	return db.Unsafe(), nil
 }
 
+// connectWithPasswordFunc behaves like sqlx.Connect, except the password is
+// obtained from passwordFunc whenever the pool establishes a new connection.
+func connectWithPasswordFunc(driver, dsn string, passwordFunc func() (string, error)) (*sqlx.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	err = cfg.Apply(mysql.BeforeConnect(func(_ context.Context, cfg *mysql.Config) (err error) {
+		cfg.Passwd, err = passwordFunc()
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sqlx.NewDb(sql.OpenDB(connector), driver)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 // CanConnect returns true if the Instance can currently be connected to, using
 // its configured User and Password. If a new connection cannot be made, the
 // return value will be false, along with an error expressing the reason.
@@ -447,6 +536,22 @@ func (instance *Instance) SchemaNames() ([]string, error) {
 // more schema names as args to filter the result to just those schemas.
 // Note that the ordering of the resulting slice is not guaranteed.
 func (instance *Instance) Schemas(onlyNames ...string) ([]*Schema, error) {
+	return instance.SchemasWithOptions(IntrospectionOptions{}, onlyNames...)
+}
+
+// IntrospectionOptions controls which parts of a schema are obtained by
+// Instance.SchemasWithOptions. The zero value introspects everything.
+type IntrospectionOptions struct {
+	// SkipRoutines omits stored procedures and functions entirely, leaving
+	// Schema.Routines empty. This is only appropriate for callers which never
+	// examine routines, since the resulting Schema cannot be distinguished from
+	// one which has no routines.
+	SkipRoutines bool
+}
+
+// SchemasWithOptions behaves like Schemas, but permits skipping introspection
+// of some object types or attributes, as controlled by opts.
+func (instance *Instance) SchemasWithOptions(opts IntrospectionOptions, onlyNames ...string) ([]*Schema, error) {
 	db, err := instance.CachedConnectionPool("", "")
 	if err != nil {
 		return nil, err
@@ -504,35 +609,30 @@ func (instance *Instance) Schemas(onlyNames ...string) ([]*Schema, error) {
 			CharSet:   rawSchema.CharSet,
 			Collation: rawSchema.Collation,
 		}
-		// Create a non-cached connection pool with this schema as the default
-		// database. The instance.querySchemaX calls below can establish a lot of
-		// connections, so we will explicitly close the pool afterwards, to avoid
-		// keeping a very large number of conns open. (Although idle conns eventually
-		// get closed automatically, this may take too long.)
-		schemaDB, err := instance.ConnectionPool(rawSchema.Name, instance.introspectionParams())
+		schemaDB, err := instance.introspectionPool(rawSchema.Name)
 		if err != nil {
 			return nil, err
 		}
-		if instance.maxUserConns >= 30 {
-			// Limit concurrency to 20, unless limit is already lower than this due to
-			// having a low maxUserConns (see logic in Instance.rawConnectionPool)
-			schemaDB.SetMaxOpenConns(20)
-
-			// Also increase max idle conns above the Golang default of 2, to ensure
-			// concurrent introspection queries reuse conns more effectively.
-			schemaDB.SetMaxIdleConns(20)
+		batchDB, err := instance.showCreateBatchPool()
+		if err != nil {
+			return nil, err
 		}
 		g, ctx := errgroup.WithContext(context.Background())
 		g.Go(func() (err error) {
-			schemas[n].Tables, err = querySchemaTables(ctx, schemaDB, rawSchema.Name, flavor)
-			return err
-		})
-		g.Go(func() (err error) {
-			schemas[n].Routines, err = querySchemaRoutines(ctx, schemaDB, rawSchema.Name, flavor)
+			schemas[n].Tables, err = querySchemaTables(ctx, schemaDB, batchDB, rawSchema.Name, flavor)
 			return err
 		})
+		if !opts.SkipRoutines {
+			g.Go(func() (err error) {
+				schemas[n].Routines, err = querySchemaRoutines(ctx, schemaDB, batchDB, rawSchema.Name, flavor)
+				return err
+			})
+		}
 		err = g.Wait()
-		schemaDB.Close()
+		// Only retain an idle conn when introspecting a single schema, since
+		// retaining one per schema could otherwise add up to a lot of conns
+		instance.releaseIntrospectionPool(schemaDB, len(rawSchemas) == 1)
+		instance.releaseIntrospectionPool(batchDB, len(rawSchemas) == 1)
 		if err != nil {
 			return nil, err
 		}
@@ -540,6 +640,65 @@ func (instance *Instance) Schemas(onlyNames ...string) ([]*Schema, error) {
 	return schemas, nil
 }
 
+// introspectionPool returns a cached connection pool with the supplied schema
+// as the default database, for use by the querySchemaX functions. The pool is
+// reused by subsequent introspection of the same schema, for example when a
+// command introspects a schema both before and after making changes, avoiding
+// the need to establish new connections. Callers should call
+// releaseIntrospectionPool once finished.
+func (instance *Instance) introspectionPool(schema string) (*sqlx.DB, error) {
+	return instance.limitedIntrospectionPool(schema, instance.introspectionParams())
+}
+
+// showCreateBatchPool returns a cached connection pool which permits multiple
+// statements per query, for use only by showCreateBatch. This allows SHOW
+// CREATE queries to be sent in batches, reducing the number of round trips
+// substantially on high-latency connections. Multiple statements are not
+// permitted in introspectionPool, since its queries may interpolate args.
+// The pool has no default database, so batched queries must use
+// schema-qualified names. Callers should call releaseIntrospectionPool once
+// finished.
+func (instance *Instance) showCreateBatchPool() (*sqlx.DB, error) {
+	return instance.limitedIntrospectionPool("", instance.introspectionParams()+"&multiStatements=true")
+}
+
+// limitedIntrospectionPool returns a cached connection pool with the supplied
+// default database and params, adjusting its concurrency and idle conn limits
+// for use in introspection.
+func (instance *Instance) limitedIntrospectionPool(schema, params string) (*sqlx.DB, error) {
+	db, err := instance.CachedConnectionPool(schema, params)
+	if err != nil {
+		return nil, err
+	}
+	if instance.maxUserConns >= 30 && instance.poolOptions.MaxOpenConns == 0 {
+		// Limit concurrency to 20, unless limit is already lower than this due to
+		// having a low maxUserConns (see logic in Instance.rawConnectionPool)
+		db.SetMaxOpenConns(20)
+	}
+	if instance.poolOptions.MaxIdleConns == 0 {
+		// Increase max idle conns above the Golang default of 2, to ensure
+		// concurrent introspection queries reuse conns more effectively.
+		db.SetMaxIdleConns(20)
+	}
+	return db, nil
+}
+
+// releaseIntrospectionPool closes excess idle conns in a pool obtained from
+// introspectionPool or showCreateBatchPool. The querySchemaX functions can
+// establish a lot of connections, and introspecting many schemas may create
+// many pools, so this avoids keeping a very large number of conns open. (Although idle conns
+// eventually get closed automatically, this may take too long.) If keepIdle is
+// true, a single idle conn is retained for reuse by later introspection.
+func (instance *Instance) releaseIntrospectionPool(db *sqlx.DB, keepIdle bool) {
+	if instance.poolOptions.MaxIdleConns > 0 {
+		return
+	} else if keepIdle {
+		db.SetMaxIdleConns(1)
+	} else {
+		db.SetMaxIdleConns(0)
+	}
+}
+
 // SchemasByName returns a map of schema name string to *Schema.  If
 // called with no args, all non-system schemas will be returned. Or pass one or
 // more schema names as args to filter the result to just those schemas.
@@ -650,6 +809,57 @@ func showCreateTable(ctx context.Context, db *sqlx.DB, table string) (string, er
 	return row.CreateStatement, nil
 }
 
+// showCreateBatchSize is the maximum number of SHOW CREATE queries combined into
+// a single round trip by showCreateBatch.
+const showCreateBatchSize = 50
+
+// showCreateBatch executes the supplied SHOW CREATE queries in a single round
+// trip, returning the CREATE statement from each query's result. This requires
+// db to have been obtained from showCreateBatchPool, or otherwise configured
+// with multiStatements=true. An error is returned
+// if any query fails, in which case callers should fall back to executing the
+// queries individually, in order to obtain a specific error.
+func showCreateBatch(ctx context.Context, db *sqlx.DB, queries []string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, strings.Join(queries, ";\n"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := make([]string, 0, len(queries))
+	for {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		createIndex := slices.IndexFunc(cols, func(col string) bool {
+			return strings.HasPrefix(col, "Create ")
+		})
+		if createIndex < 0 {
+			return nil, fmt.Errorf("Unexpected columns in SHOW CREATE result: %v", cols)
+		}
+		values := make([]sql.NullString, len(cols))
+		dest := make([]interface{}, len(cols))
+		for n := range values {
+			dest[n] = &values[n]
+		}
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				return nil, err
+			}
+			results = append(results, values[createIndex].String)
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	} else if len(results) != len(queries) {
+		return nil, fmt.Errorf("Expected %d SHOW CREATE results, instead found %d", len(queries), len(results))
+	}
+	return results, nil
+}
+
 // TableSize returns an estimate of the table's size on-disk, based on data in
 // information_schema. If the table or schema does not exist on this instance,
 // the error will be sql.ErrNoRows.
@@ -669,6 +879,24 @@ func (instance *Instance) TableSize(schema, table string) (int64, error) {
 	return result, err
 }
 
+// TableRowEstimate returns an estimate of the number of rows in the table,
+// based on data in information_schema. As with TableSize, this may be quite
+// inaccurate, especially for InnoDB tables. If the table or schema does not
+// exist on this instance, the error will be sql.ErrNoRows.
+func (instance *Instance) TableRowEstimate(schema, table string) (int64, error) {
+	var result int64
+	db, err := instance.CachedConnectionPool("", instance.introspectionParams())
+	if err != nil {
+		return 0, err
+	}
+	err = db.Get(&result, `
+		SELECT  IFNULL(table_rows, 0)
+		FROM    information_schema.tables
+		WHERE   table_schema = ? and table_name = ?`,
+		schema, table)
+	return result, err
+}
+
 // TableHasRows returns true if the table has at least one row. If an error
 // occurs in querying, also returns true (along with the error) since a false
 // positive is generally less dangerous in this case than a false negative.
@@ -546,6 +546,20 @@ func (s TengoIntegrationSuite) TestInstanceTableSize(t *testing.T) {
 	}
 }
 
+func (s TengoIntegrationSuite) TestInstanceTableRowEstimate(t *testing.T) {
+	s.SourceTestSQL(t, "rows.sql")
+	if rows, err := s.d.TableRowEstimate("testing", "has_rows"); err != nil {
+		t.Errorf("Error from TableRowEstimate: %s", err)
+	} else if rows < 0 {
+		t.Errorf("TableRowEstimate returned a negative result: %d", rows)
+	}
+
+	// Test nonexistent table
+	if rows, err := s.d.TableRowEstimate("testing", "doesnt_exist"); rows > 0 || err == nil {
+		t.Errorf("Expected TableRowEstimate to return 0 rows and non-nil err for missing table, instead rows=%d and err=%s", rows, err)
+	}
+}
+
 func (s TengoIntegrationSuite) TestInstanceTableHasRows(t *testing.T) {
 	s.SourceTestSQL(t, "rows.sql")
 	if hasRows, err := s.d.TableHasRows("testing", "has_rows"); err != nil {
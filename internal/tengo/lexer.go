@@ -40,6 +40,12 @@ func (mse *MalformedSQLError) Error() string {
 	return strings.Join(parts, "")
 }
 
+// Position returns the line and column number where the problem was detected.
+// Either value may be 0 if unknown.
+func (mse *MalformedSQLError) Position() (lineNumber, colNumber int) {
+	return mse.lineNumber, mse.colNumber
+}
+
 // TokenType represents the category of a lexical token.
 type TokenType uint32
 
@@ -409,6 +409,12 @@ func (p *parser) skipUntilSequence(tokens []Token, wantSequence ...string) (befo
 		}
 	}
 
+	// Input may end before any further tokens, e.g. if an unterminated quote
+	// consumed the rest of the input
+	tokens = p.nextTokens(tokens, 1)
+	if len(tokens) == 0 {
+		return "", nil, false
+	}
 	startPosInBuffer := int(tokens[0].offset)
 	endPosInBuffer := startPosInBuffer
 	var matched bool
@@ -91,6 +91,11 @@ func TestParseStatementsInFileFail(t *testing.T) {
 		t.Error("Expected to get an error about unterminated comment, but err was nil")
 	}
 
+	// Test unterminated quote in object name at the end of input
+	if _, err := ParseStatementsInString("CREATE TABLE `foo (id int);\n"); err == nil {
+		t.Error("Expected to get an error about unterminated quote, but err was nil")
+	}
+
 	// Test error return for nonexistent file
 	filePath = filepath.Join(tempDir, "not-here.sql")
 	if _, err := ParseStatementsInFile(filePath); err == nil {
@@ -2,6 +2,7 @@ package tengo
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -41,7 +42,7 @@ func (tp *TablePartitioning) Definition(flavor Flavor) string {
 	if plMode == PartitionListDefault {
 		plMode = PartitionListCount
 		for n, p := range tp.Partitions {
-			if p.Values != "" || p.Comment != "" || p.DataDir != "" || p.Name != fmt.Sprintf("p%d", n) {
+			if p.Values != "" || p.Comment != "" || p.DataDir != "" || !isDefaultPartitionName(p.Name, n) {
 				plMode = PartitionListExplicit
 				break
 			}
@@ -49,13 +50,21 @@ func (tp *TablePartitioning) Definition(flavor Flavor) string {
 	}
 	var partitionsClause string
 	if plMode == PartitionListExplicit {
-		pdefs := make([]string, len(tp.Partitions))
+		// Tables may have many thousands of partitions, so build the list in a
+		// single buffer rather than formatting and joining each definition
+		var b strings.Builder
+		b.Grow(len(tp.Partitions) * 64)
+		b.WriteString("\n(")
 		for n, p := range tp.Partitions {
-			pdefs[n] = p.Definition(flavor, tp.Method)
+			if n > 0 {
+				b.WriteString(",\n ")
+			}
+			p.writeDefinition(&b, flavor, tp.Method)
 		}
-		partitionsClause = fmt.Sprintf("\n(%s)", strings.Join(pdefs, ",\n "))
+		b.WriteByte(')')
+		partitionsClause = b.String()
 	} else if plMode == PartitionListCount {
-		partitionsClause = fmt.Sprintf("\nPARTITIONS %d", len(tp.Partitions))
+		partitionsClause = "\nPARTITIONS " + strconv.Itoa(len(tp.Partitions))
 	}
 
 	opener, closer := "/*!50100", " */"
@@ -157,33 +166,47 @@ type Partition struct {
 // Definition returns this partition's definition clause, for use as part of a
 // DDL statement.
 func (p *Partition) Definition(flavor Flavor, method string) string {
+	var b strings.Builder
+	p.writeDefinition(&b, flavor, method)
+	return b.String()
+}
+
+// writeDefinition writes this partition's definition clause to b.
+func (p *Partition) writeDefinition(b *strings.Builder, flavor Flavor, method string) {
 	// MariaDB 10.2+ wraps partition names in backticks.
 	// TODO MySQL (any version) and MariaDB 10.1 will also wrap a partition name in
 	// backticks if the name is a keyword (even if not a *reserved* word) or has
 	// special characters. See https://github.com/skeema/skeema/issues/175
-	name := p.Name
+	b.WriteString("PARTITION ")
 	if flavor.MinMariaDB(10, 2) {
-		name = EscapeIdentifier(name)
+		b.WriteString(EscapeIdentifier(p.Name))
+	} else {
+		b.WriteString(p.Name)
 	}
+	b.WriteByte(' ')
 
-	var values string
 	if method == "RANGE" && p.Values == "MAXVALUE" {
-		values = "VALUES LESS THAN MAXVALUE "
+		b.WriteString("VALUES LESS THAN MAXVALUE ")
 	} else if strings.Contains(method, "RANGE") {
-		values = fmt.Sprintf("VALUES LESS THAN (%s) ", p.Values)
+		b.WriteString("VALUES LESS THAN (" + p.Values + ") ")
 	} else if strings.Contains(method, "LIST") {
-		values = fmt.Sprintf("VALUES IN (%s) ", p.Values)
+		b.WriteString("VALUES IN (" + p.Values + ") ")
 	}
 
-	var dataDir string
 	if p.DataDir != "" {
-		dataDir = fmt.Sprintf("DATA DIRECTORY = '%s' ", p.DataDir) // any necessary escaping is already present in p.DataDir
+		b.WriteString("DATA DIRECTORY = '" + p.DataDir + "' ") // any necessary escaping is already present in p.DataDir
 	}
 
-	var comment string
 	if p.Comment != "" {
-		comment = fmt.Sprintf("COMMENT = '%s' ", EscapeValueForCreateTable(p.Comment))
+		b.WriteString("COMMENT = '" + EscapeValueForCreateTable(p.Comment) + "' ")
 	}
 
-	return fmt.Sprintf("PARTITION %s %s%s%sENGINE = %s", name, values, dataDir, comment, p.Engine)
+	b.WriteString("ENGINE = ")
+	b.WriteString(p.Engine)
+}
+
+// isDefaultPartitionName returns true if name is the name automatically
+// assigned to the partition at position n, for example "p3" for n=3.
+func isDefaultPartitionName(name string, n int) bool {
+	return len(name) > 1 && name[0] == 'p' && name[1:] == strconv.Itoa(n)
 }
@@ -3,6 +3,7 @@ package tengo
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -237,15 +238,45 @@ func TestSchemaDiffDropPartitionedTable(t *testing.T) {
 // fixPartitioningEdgeCases relating to data directory parsing. This isn't
 // handled by integration tests due to complexity of setup in containers.
 func TestPartitioningDataDirectory(t *testing.T) {
-	table := partitionedTable(FlavorUnknown)
-	table.CreateStatement = strings.Replace(table.CreateStatement, "LESS THAN (123)", "LESS THAN (123) DATA DIRECTORY = '/some/weird/dir'", 1)
-	table.CreateStatement = strings.Replace(table.CreateStatement, "LESS THAN MAXVALUE", "LESS THAN MAXVALUE DATA DIRECTORY = '/some/weirder/dir'", 1)
-	if table.CreateStatement == table.GeneratedCreateStatement(FlavorUnknown) {
-		t.Fatal("Failed to set up test properly: string replacements did not match")
+	for _, flavor := range []Flavor{FlavorUnknown, ParseFlavor("mariadb:10.6")} {
+		table := partitionedTable(flavor)
+		table.CreateStatement = strings.Replace(table.CreateStatement, "LESS THAN (123)", "LESS THAN (123) DATA DIRECTORY = '/some/weird/dir'", 1)
+		table.CreateStatement = strings.Replace(table.CreateStatement, "LESS THAN MAXVALUE", "LESS THAN MAXVALUE DATA DIRECTORY = '/some/weirder/dir'", 1)
+		if table.CreateStatement == table.GeneratedCreateStatement(flavor) {
+			t.Fatal("Failed to set up test properly: string replacements did not match")
+		}
+		fixPartitioningEdgeCases(&table)
+		if table.CreateStatement != table.GeneratedCreateStatement(flavor) {
+			t.Errorf("Flavor %s: Failed to extract data directories; post-fix partitioning statement generated as %s", flavor, table.Partitioning.Definition(flavor))
+		}
+	}
+}
+
+func TestPartitioningManyPartitions(t *testing.T) {
+	table := manyPartitionsTable(10000)
+	def := table.Partitioning.Definition(FlavorUnknown)
+	if count := strings.Count(def, "PARTITION p"); count != 10000 {
+		t.Errorf("Expected 10000 partitions in definition, instead found %d", count)
+	}
+	if !strings.HasSuffix(def, "\n PARTITION p9999 VALUES LESS THAN (99990) ENGINE = InnoDB) */") {
+		t.Errorf("Unexpected end of partitioning definition: %q", def[len(def)-80:])
+	}
+	if table.UnpartitionedCreateStatement(FlavorUnknown) != unpartitionedTable(FlavorUnknown).CreateStatement {
+		t.Error("Unexpected result from UnpartitionedCreateStatement")
 	}
-	fixPartitioningEdgeCases(&table, FlavorUnknown)
-	if table.CreateStatement != table.GeneratedCreateStatement(FlavorUnknown) {
-		t.Errorf("Failed to extract data directories; post-fix partitioning statement generated as %s", table.Partitioning.Definition(FlavorUnknown))
+
+	// Default partition names without values are expressed as a count, rather
+	// than an explicit list
+	table.Partitioning.Method = "HASH"
+	for _, p := range table.Partitioning.Partitions {
+		p.Values = ""
+	}
+	if def := table.Partitioning.Definition(FlavorUnknown); !strings.Contains(def, "\nPARTITIONS 10000 */") {
+		t.Errorf("Expected partition count in definition, instead found %q", def)
+	}
+	table.Partitioning.Partitions[5000].Name = "p5000x"
+	if def := table.Partitioning.Definition(FlavorUnknown); strings.Contains(def, "PARTITIONS 10000") {
+		t.Error("Expected explicit partition list for non-default partition name, but found count")
 	}
 }
 
@@ -453,6 +484,22 @@ func partitionedTable(flavor Flavor) Table {
 	return t
 }
 
+// manyPartitionsTable returns a RANGE-partitioned version of
+// unpartitionedTable(FlavorUnknown) with the supplied number of partitions.
+func manyPartitionsTable(count int) Table {
+	t := unpartitionedTable(FlavorUnknown)
+	t.Partitioning = &TablePartitioning{
+		Method:     "RANGE",
+		Expression: "customer_id",
+		Partitions: make([]*Partition, count),
+	}
+	for n := range count {
+		t.Partitioning.Partitions[n] = &Partition{Name: fmt.Sprintf("p%d", n), Values: strconv.Itoa(n * 10), Engine: "InnoDB"}
+	}
+	t.CreateStatement = t.GeneratedCreateStatement(FlavorUnknown)
+	return t
+}
+
 func unpartitionedTable(flavor Flavor) Table {
 	columns := []*Column{
 		{
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -350,9 +352,11 @@ func compareRoutines(from, to *Schema) []*RoutineDiff {
 // compareRoutinesByName is a helper function for comparing maps of procs or
 // funcs, keyed by name. Both maps should only contain the same type of routine.
 // In other words, both fromByName and toByName should only contain procs, or
-// both only contain funcs. No validation of this is performed here.
+// both only contain funcs. No validation of this is performed here. Drops and
+// alters are returned first, followed by creates, each ordered by name.
 func compareRoutinesByName(fromByName map[string]*Routine, toByName map[string]*Routine) (routineDiffs []*RoutineDiff) {
-	for name, from := range fromByName {
+	for _, name := range slices.Sorted(maps.Keys(fromByName)) {
+		from := fromByName[name]
 		to, stillExists := toByName[name]
 		if !stillExists {
 			routineDiffs = append(routineDiffs, &RoutineDiff{Type: DiffTypeDrop, From: from})
@@ -372,9 +376,9 @@ func compareRoutinesByName(fromByName map[string]*Routine, toByName map[string]*
 			}
 		}
 	}
-	for name, to := range toByName {
+	for _, name := range slices.Sorted(maps.Keys(toByName)) {
 		if _, alreadyExists := fromByName[name]; !alreadyExists {
-			routineDiffs = append(routineDiffs, &RoutineDiff{Type: DiffTypeCreate, To: to})
+			routineDiffs = append(routineDiffs, &RoutineDiff{Type: DiffTypeCreate, To: toByName[name]})
 		}
 	}
 	return
@@ -382,7 +386,19 @@ func compareRoutinesByName(fromByName map[string]*Routine, toByName map[string]*
 
 ///// Introspection logic //////////////////////////////////////////////////////
 
-func querySchemaRoutines(ctx context.Context, db *sqlx.DB, schema string, flavor Flavor) ([]*Routine, error) {
+func querySchemaRoutines(ctx context.Context, db, batchDB *sqlx.DB, schema string, flavor Flavor) ([]*Routine, error) {
+	routines, err := querySchemaRoutineMetadata(ctx, db, schema)
+	if err != nil || len(routines) == 0 {
+		return routines, err
+	}
+	err = loadRoutineBodies(ctx, db, batchDB, schema, routines, flavor)
+	return routines, err
+}
+
+// querySchemaRoutineMetadata returns the routines in schema, populating only
+// the fields which are available from information_schema.routines. The
+// remaining fields are populated by loadRoutineBodies.
+func querySchemaRoutineMetadata(ctx context.Context, db *sqlx.DB, schema string) ([]*Routine, error) {
 	// Obtain the routines in the schema
 	// We completely exclude routines that the user can call, but not examine --
 	// e.g. user has EXECUTE priv but missing other vital privs. In this case
@@ -424,7 +440,6 @@ func querySchemaRoutines(ctx context.Context, db *sqlx.DB, schema string, flavor
 		return []*Routine{}, nil
 	}
 	routines := make([]*Routine, len(rawRoutines))
-	dict := make(map[ObjectKey]*Routine, len(rawRoutines))
 	for n, rawRoutine := range rawRoutines {
 		routines[n] = &Routine{
 			Name:              rawRoutine.Name,
@@ -440,8 +455,17 @@ func querySchemaRoutines(ctx context.Context, db *sqlx.DB, schema string, flavor
 		if routines[n].Type != ObjectTypeProc && routines[n].Type != ObjectTypeFunc {
 			return nil, fmt.Errorf("Unsupported routine type %s found in %s.%s", rawRoutine.Type, schema, rawRoutine.Name)
 		}
-		key := ObjectKey{Type: routines[n].Type, Name: routines[n].Name}
-		dict[key] = routines[n]
+	}
+	return routines, nil
+}
+
+// loadRoutineBodies populates the Body, ParamString, ReturnDataType, and
+// CreateStatement fields of the supplied routines, all of which must be in
+// schema. If batchDB is non-nil, it is used to batch SHOW CREATE queries.
+func loadRoutineBodies(ctx context.Context, db, batchDB *sqlx.DB, schema string, routines []*Routine, flavor Flavor) error {
+	dict := make(map[ObjectKey]*Routine, len(routines))
+	for _, r := range routines {
+		dict[r.ObjectKey()] = r
 	}
 
 	// Obtain param string, return type string, and full create statement:
@@ -452,7 +476,8 @@ func querySchemaRoutines(ctx context.Context, db *sqlx.DB, schema string, flavor
 	// to bulk-fetch sufficient info to rebuild the CREATE without needing to run
 	// a SHOW CREATE per routine.
 	// If mysql.proc doesn't exist or that query fails, we then run a SHOW CREATE
-	// per routine, using multiple goroutines for performance reasons.
+	// per routine, combined into batches per round trip, using multiple
+	// goroutines for performance reasons.
 	var alreadyObtained int
 	if !flavor.MinMySQL(8) {
 		var rawRoutineMeta []struct {
@@ -482,25 +507,52 @@ func querySchemaRoutines(ctx context.Context, db *sqlx.DB, schema string, flavor
 
 	var err error
 	if alreadyObtained < len(routines) {
-		g, subCtx := errgroup.WithContext(ctx)
+		remaining := make([]*Routine, 0, len(routines)-alreadyObtained)
 		for _, r := range routines {
 			if r.CreateStatement == "" {
-				g.Go(func() (err error) {
-					r.CreateStatement, err = showCreateRoutine(subCtx, db, r.Name, r.Type)
-					if err == nil {
-						r.CreateStatement = strings.ReplaceAll(r.CreateStatement, "\r\n", "\n")
-						err = r.parseCreateStatement(flavor, schema)
-					} else {
-						err = fmt.Errorf("Error executing SHOW CREATE %s for %s.%s: %w", r.Type.Caps(), EscapeIdentifier(schema), EscapeIdentifier(r.Name), err)
-					}
-					return err
-				})
+				remaining = append(remaining, r)
 			}
 		}
+		g, subCtx := errgroup.WithContext(ctx)
+		for batch := range slices.Chunk(remaining, showCreateBatchSize) {
+			g.Go(func() error {
+				return showCreateRoutines(subCtx, db, batchDB, schema, batch, flavor)
+			})
+		}
 		err = g.Wait()
 	}
+	return err
+}
 
-	return routines, err
+// showCreateRoutines sets the CreateStatement field of each of the supplied
+// routines, and then parses it to populate other fields. A single round trip
+// via batchDB is used if non-nil. Otherwise, or if the batch fails for any
+// reason, each routine is queried separately using db. Batched queries use
+// schema-qualified names, since batchDB has no default database.
+func showCreateRoutines(ctx context.Context, db, batchDB *sqlx.DB, schema string, routines []*Routine, flavor Flavor) error {
+	queries := make([]string, len(routines))
+	for n, r := range routines {
+		queries[n] = fmt.Sprintf("SHOW CREATE %s %s.%s", r.Type.Caps(), EscapeIdentifier(schema), EscapeIdentifier(r.Name))
+	}
+	var creates []string
+	if batchDB != nil {
+		creates, _ = showCreateBatch(ctx, batchDB, queries)
+	}
+	for n, r := range routines {
+		if creates != nil {
+			r.CreateStatement = creates[n]
+		} else {
+			var err error
+			if r.CreateStatement, err = showCreateRoutine(ctx, db, r.Name, r.Type); err != nil {
+				return fmt.Errorf("Error executing SHOW CREATE %s for %s.%s: %w", r.Type.Caps(), EscapeIdentifier(schema), EscapeIdentifier(r.Name), err)
+			}
+		}
+		r.CreateStatement = strings.ReplaceAll(r.CreateStatement, "\r\n", "\n")
+		if err := r.parseCreateStatement(flavor, schema); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func showCreateRoutine(ctx context.Context, db *sqlx.DB, routine string, ot ObjectType) (create string, err error) {
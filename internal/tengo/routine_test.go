@@ -51,13 +51,13 @@ func (s TengoIntegrationSuite) TestInstanceRoutineIntrospection(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Unexpected error from ConnectionPool: %v", err)
 		}
-		fastResults, err := querySchemaRoutines(context.Background(), db, "testing", s.d.Flavor())
+		fastResults, err := querySchemaRoutines(context.Background(), db, nil, "testing", s.d.Flavor())
 		if err != nil {
 			t.Fatalf("Unexpected error from querySchemaRoutines: %v", err)
 		}
 		oldFlavor := s.d.Flavor()
 		s.d.ForceFlavor(ParseFlavor("mysql:8.0"))
-		slowResults, err := querySchemaRoutines(context.Background(), db, "testing", s.d.Flavor())
+		slowResults, err := querySchemaRoutines(context.Background(), db, nil, "testing", s.d.Flavor())
 		s.d.ForceFlavor(oldFlavor)
 		if err != nil {
 			t.Fatalf("Unexpected error from querySchemaRoutines: %v", err)
@@ -69,6 +69,12 @@ func (s TengoIntegrationSuite) TestInstanceRoutineIntrospection(t *testing.T) {
 		}
 	}
 
+	if noRoutineSchemas, err := s.d.SchemasWithOptions(IntrospectionOptions{SkipRoutines: true}, "testing"); err != nil {
+		t.Errorf("Unexpected error from SchemasWithOptions: %v", err)
+	} else if len(noRoutineSchemas[0].Routines) > 0 || len(noRoutineSchemas[0].Tables) != len(schema.Tables) {
+		t.Errorf("Unexpected result from SchemasWithOptions with SkipRoutines: %d tables, %d routines", len(noRoutineSchemas[0].Tables), len(noRoutineSchemas[0].Routines))
+	}
+
 	// Coverage for MariaDB 10.8 IN/OUT/INOUT params in funcs
 	if fl := s.d.Flavor(); fl.MinMariaDB(10, 8) {
 		s.SourceTestSQL(t, "maria108.sql")
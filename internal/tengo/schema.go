@@ -1,6 +1,7 @@
 package tengo
 
 import (
+	"slices"
 	"strings"
 )
 
@@ -131,6 +132,52 @@ func stripMatchingObjects[T ObjectKeyer](s []T, pattern ObjectPattern) (result [
 	return
 }
 
+// RenameReferences returns a copy of s in which foreign keys referencing other
+// schemas are adjusted according to renames, a map of old schema names to new
+// schema names. Only tables with affected foreign keys are copied; all other
+// tables are shared with s. If no foreign keys are affected, s itself is
+// returned. This does not affect any actual database instances.
+func (s *Schema) RenameReferences(renames map[string]string) *Schema {
+	if s == nil || len(renames) == 0 {
+		return s
+	}
+	oldnew := make([]string, 0, 2*len(renames))
+	for oldName, newName := range renames {
+		oldnew = append(oldnew, "REFERENCES "+EscapeIdentifier(oldName)+".", "REFERENCES "+EscapeIdentifier(newName)+".")
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	result := s
+	for n, table := range s.Tables {
+		var fks []*ForeignKey
+		for m, fk := range table.ForeignKeys {
+			newName, ok := renames[fk.ReferencedSchemaName]
+			if !ok || fk.ReferencedSchemaName == "" {
+				continue
+			}
+			if fks == nil {
+				fks = slices.Clone(table.ForeignKeys)
+			}
+			fkCopy := *fk
+			fkCopy.ReferencedSchemaName = newName
+			fks[m] = &fkCopy
+		}
+		if fks == nil {
+			continue
+		}
+		if result == s {
+			schemaCopy := *s
+			schemaCopy.Tables = slices.Clone(s.Tables)
+			result = &schemaCopy
+		}
+		tableCopy := *table
+		tableCopy.ForeignKeys = fks
+		tableCopy.CreateStatement = replacer.Replace(table.CreateStatement)
+		result.Tables[n] = &tableCopy
+	}
+	return result
+}
+
 // Diff returns the set of differences between this schema and another schema.
 func (s *Schema) Diff(other *Schema) *SchemaDiff {
 	return NewSchemaDiff(s, other)
@@ -3,6 +3,7 @@ package tengo
 import (
 	"encoding/json"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -146,3 +147,41 @@ func (s TengoIntegrationSuite) TestSchemaStripTablePartitioning(t *testing.T) {
 		}
 	}
 }
+
+func TestSchemaRenameReferences(t *testing.T) {
+	fkTable := foreignKeyTable()
+	other := anotherTable()
+	schema := &Schema{Name: "orders", Tables: []*Table{&fkTable, &other}}
+
+	// No affected foreign keys: same schema returned
+	if renamed := schema.RenameReferences(map[string]string{"billing": "billing_stage"}); renamed != schema {
+		t.Error("Expected RenameReferences to return the original schema when no references are affected")
+	}
+	if renamed := schema.RenameReferences(nil); renamed != schema {
+		t.Error("Expected RenameReferences to return the original schema when renames is empty")
+	}
+
+	renamed := schema.RenameReferences(map[string]string{"purchasing": "purchasing_stage", "purchasing_stage": "purchasing_other"})
+	if renamed == schema {
+		t.Fatal("Expected RenameReferences to return a copy, but original schema returned")
+	}
+	if renamed.Tables[1] != schema.Tables[1] {
+		t.Error("Expected unaffected table to be shared with original schema")
+	}
+	table := renamed.Tables[0]
+	if table == schema.Tables[0] {
+		t.Fatal("Expected affected table to be copied")
+	}
+	if fk := table.ForeignKeys[0]; fk.ReferencedSchemaName != "purchasing_stage" {
+		t.Errorf("Expected ReferencedSchemaName to be purchasing_stage, instead found %q", fk.ReferencedSchemaName)
+	}
+	if table.ForeignKeys[1] != schema.Tables[0].ForeignKeys[1] {
+		t.Error("Expected unaffected foreign key to be shared with original table")
+	}
+	if !strings.Contains(table.CreateStatement, "REFERENCES `purchasing_stage`.`customers`") || strings.Contains(table.CreateStatement, "`purchasing`") || strings.Contains(table.CreateStatement, "purchasing_other") {
+		t.Errorf("CreateStatement not renamed as expected: %s", table.CreateStatement)
+	}
+	if schema.Tables[0].ForeignKeys[0].ReferencedSchemaName != "purchasing" || !strings.Contains(schema.Tables[0].CreateStatement, "REFERENCES `purchasing`.`customers`") {
+		t.Error("Original schema was unexpectedly modified by RenameReferences")
+	}
+}
@@ -0,0 +1,122 @@
+package tengo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sshTunnel is a background `ssh` process which forwards local ports to ports
+// on the loopback interface of a remote container engine host. This permits
+// connecting to containers on a Docker host reached via an ssh:// URL, since
+// containers only publish their ports on the engine host's loopback interface.
+type sshTunnel struct {
+	cmd  *exec.Cmd
+	done chan error
+}
+
+// sshDestination returns the ssh destination ("host" or "user@host") and port
+// (which may be blank) for a container engine host URL. If engineHost is not an
+// ssh:// URL, a blank destination is returned. An error is returned if
+// engineHost is a tcp:// URL for a non-loopback address, since containers on
+// such hosts cannot be reached securely.
+func sshDestination(engineHost string) (dest, port string, err error) {
+	if engineHost == "" {
+		return "", "", nil
+	}
+	u, err := url.Parse(engineHost)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse container engine host %q: %w", engineHost, err)
+	}
+	switch u.Scheme {
+	case "ssh":
+		dest = u.Hostname()
+		if u.User != nil {
+			dest = u.User.Username() + "@" + dest
+		}
+		return dest, u.Port(), nil
+	case "tcp":
+		if host := u.Hostname(); host != "localhost" && !net.ParseIP(host).IsLoopback() {
+			return "", "", fmt.Errorf("remote container engine host %s is only supported via an ssh:// URL", engineHost)
+		}
+	}
+	return "", "", nil
+}
+
+// newSSHTunnel starts an ssh process to dest, forwarding a free local port to
+// each of remotePorts on the remote host's loopback interface. It returns a map
+// of remote port to local port, once the tunnel is accepting connections.
+func newSSHTunnel(dest, sshPort string, remotePorts []int) (*sshTunnel, map[int]int, error) {
+	args := []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes"}
+	if sshPort != "" {
+		args = append(args, "-p", sshPort)
+	}
+	localPorts := make(map[int]int, len(remotePorts))
+	for _, remotePort := range remotePorts {
+		localPort, err := freeLocalPort()
+		if err != nil {
+			return nil, nil, err
+		}
+		localPorts[remotePort] = localPort
+		args = append(args, "-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", localPort, remotePort))
+	}
+	args = append(args, dest)
+
+	t := &sshTunnel{
+		cmd:  exec.Command("ssh", args...),
+		done: make(chan error, 1),
+	}
+	var stderr strings.Builder
+	t.cmd.Stderr = &stderr
+	if err := t.cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("unable to start ssh tunnel to %s: %w", dest, err)
+	}
+	go func() {
+		t.done <- t.cmd.Wait()
+	}()
+
+	// Wait for the first forwarded port to accept connections
+	var checkPort int
+	for _, localPort := range localPorts {
+		checkPort = localPort
+		break
+	}
+	for attempts := 0; attempts < 100; attempts++ {
+		select {
+		case err := <-t.done:
+			return nil, nil, fmt.Errorf("ssh tunnel to %s exited unexpectedly: %v: %s", dest, err, strings.TrimSpace(stderr.String()))
+		default:
+		}
+		if conn, err := net.DialTimeout("tcp", "127.0.0.1:"+strconv.Itoa(checkPort), time.Second); err == nil {
+			conn.Close()
+			return t, localPorts, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Close()
+	return nil, nil, errors.New("timed out waiting for ssh tunnel to " + dest)
+}
+
+// Close terminates the tunnel's ssh process. It is nil-safe.
+func (t *sshTunnel) Close() {
+	if t == nil {
+		return
+	}
+	t.cmd.Process.Kill()
+	<-t.done
+}
+
+// freeLocalPort returns a currently-unused TCP port on the loopback interface.
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
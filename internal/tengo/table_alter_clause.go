@@ -2,6 +2,7 @@ package tengo
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -40,6 +41,9 @@ func (ac AddColumn) Clause(mods StatementModifiers) string {
 	} else if ac.PositionAfter != nil {
 		positionClause = " AFTER " + EscapeIdentifier(ac.PositionAfter.Name)
 	}
+	if mods.OmitColumnPosition {
+		positionClause = "" // add the column at the end of the table
+	}
 	return "ADD COLUMN " + ac.Column.Definition(mods.Flavor) + positionClause
 }
 
@@ -379,6 +383,9 @@ func (mc ModifyColumn) Clause(mods StatementModifiers) string {
 	} else if mc.PositionAfter != nil {
 		positionClause = " AFTER " + EscapeIdentifier(mc.PositionAfter.Name)
 	}
+	if mods.OmitColumnPosition {
+		positionClause = "" // leave the column in its current position
+	}
 
 	// LaxComments means we only emit a MODIFY COLUMN if something OTHER than the
 	// comment differs; but if we do emit a MODIFY COLUMN we still want to use the
@@ -396,9 +403,9 @@ func (mc ModifyColumn) Clause(mods StatementModifiers) string {
 		mc.OldColumn = &oldColumnCopy
 	}
 
-	// If the only difference is a position difference, and LaxColumnOrder is
-	// enabled, emit a no-op.
-	if positionClause != "" && mods.LaxColumnOrder && mc.OldColumn.Equals(mc.NewColumn) {
+	// If the only difference is a position difference, and LaxColumnOrder or
+	// OmitColumnPosition is enabled, emit a no-op.
+	if ((positionClause != "" && mods.LaxColumnOrder) || mods.OmitColumnPosition) && mc.OldColumn.Equals(mc.NewColumn) {
 		return ""
 	}
 
@@ -410,9 +417,38 @@ func (mc ModifyColumn) Clause(mods StatementModifiers) string {
 		return ""
 	}
 
+	// MySQL 8.0.23+ permits changing column visibility without redefining the
+	// rest of the column. MariaDB lacks equivalent syntax, so a full MODIFY
+	// COLUMN is needed there.
+	if (positionClause == "" || mods.LaxColumnOrder) && mc.VisibilityOnly(mods) && mods.Flavor.MinMySQL(8, 0, 23) {
+		if mc.NewColumn.Invisible {
+			return "ALTER COLUMN " + EscapeIdentifier(mc.NewColumn.Name) + " SET INVISIBLE"
+		}
+		return "ALTER COLUMN " + EscapeIdentifier(mc.NewColumn.Name) + " SET VISIBLE"
+	}
+
 	return "MODIFY COLUMN " + mc.NewColumn.Definition(mods.Flavor) + positionClause
 }
 
+// VisibilityOnly returns true if the only functional difference between the
+// old and new column definitions is the column's visibility. Such changes only
+// affect metadata, and never require a table rebuild. Differences in column
+// position are not examined by this method.
+func (mc ModifyColumn) VisibilityOnly(mods StatementModifiers) bool {
+	if mc.OldColumn.Invisible == mc.NewColumn.Invisible {
+		return false
+	}
+	oldColumnCopy := *mc.OldColumn
+	oldColumnCopy.Invisible = mc.NewColumn.Invisible
+	if mods.LaxComments {
+		oldColumnCopy.Comment = mc.NewColumn.Comment
+	}
+	if mods.StrictColumnDefinition {
+		return oldColumnCopy.Equals(mc.NewColumn)
+	}
+	return oldColumnCopy.Equivalent(mc.NewColumn)
+}
+
 // Unsafe returns true if this clause is potentially destroys/corrupts existing
 // data, or restricts the range of data that may be stored. (Although the server
 // can also catch the latter case and prevent the ALTER, this only happens if
@@ -648,31 +684,48 @@ type ChangeCreateOptions struct {
 	NewCreateOptions string
 }
 
-// Clause returns a clause of an ALTER TABLE statement that sets one or more
-// create options.
-func (cco ChangeCreateOptions) Clause(_ StatementModifiers) string {
-	// Map of known defaults that make options no longer show up in create_options
-	// or SHOW CREATE TABLE.
-	knownDefaults := map[string]string{
-		"MIN_ROWS":           "0",
-		"MAX_ROWS":           "0",
-		"AVG_ROW_LENGTH":     "0",
-		"PACK_KEYS":          "DEFAULT",
-		"STATS_PERSISTENT":   "DEFAULT",
-		"STATS_AUTO_RECALC":  "DEFAULT",
-		"STATS_SAMPLE_PAGES": "DEFAULT",
-		"CHECKSUM":           "0",
-		"DELAY_KEY_WRITE":    "0",
-		"ROW_FORMAT":         "DEFAULT",
-		"KEY_BLOCK_SIZE":     "0",
-		"COMPRESSION":        "''", // Undocumented way of removing clause entirely (vs "None" which sticks around)
+// createOptionDefaults maps known create options to the values that make them
+// no longer show up in create_options or SHOW CREATE TABLE.
+var createOptionDefaults = map[string]string{
+	"MIN_ROWS":           "0",
+	"MAX_ROWS":           "0",
+	"AVG_ROW_LENGTH":     "0",
+	"PACK_KEYS":          "DEFAULT",
+	"STATS_PERSISTENT":   "DEFAULT",
+	"STATS_AUTO_RECALC":  "DEFAULT",
+	"STATS_SAMPLE_PAGES": "DEFAULT",
+	"CHECKSUM":           "0",
+	"DELAY_KEY_WRITE":    "0",
+	"ROW_FORMAT":         "DEFAULT",
+	"KEY_BLOCK_SIZE":     "0",
+	"COMPRESSION":        "''", // Undocumented way of removing clause entirely (vs "None" which sticks around)
+}
+
+// IgnorableTableOptions returns the names of table options which may be
+// supplied in StatementModifiers.IgnoreTableOptions.
+func IgnorableTableOptions() []string {
+	names := make([]string, 0, len(createOptionDefaults)+1)
+	for name := range createOptionDefaults {
+		names = append(names, name)
 	}
+	names = append(names, "COMMENT")
+	slices.Sort(names)
+	return names
+}
 
+// ignoresTableOption returns true if name is listed in mods.IgnoreTableOptions.
+func (mods StatementModifiers) ignoresTableOption(name string) bool {
+	return slices.Contains(strings.Split(mods.IgnoreTableOptions, ","), name)
+}
+
+// Clause returns a clause of an ALTER TABLE statement that sets one or more
+// create options. Options listed in mods.IgnoreTableOptions are omitted.
+func (cco ChangeCreateOptions) Clause(mods StatementModifiers) string {
 	splitOpts := func(full string) map[string]string {
 		result := make(map[string]string)
 		for _, kv := range strings.Split(full, " ") {
 			tokens := strings.Split(kv, "=")
-			if len(tokens) == 2 {
+			if len(tokens) == 2 && !mods.ignoresTableOption(tokens[0]) {
 				result[tokens[0]] = tokens[1]
 			}
 		}
@@ -681,14 +734,14 @@ func (cco ChangeCreateOptions) Clause(_ StatementModifiers) string {
 
 	oldOpts := splitOpts(cco.OldCreateOptions)
 	newOpts := splitOpts(cco.NewCreateOptions)
-	subclauses := make([]string, 0, len(knownDefaults))
+	subclauses := make([]string, 0, len(createOptionDefaults))
 
 	// Determine which oldOpts changed in newOpts or are no longer present
 	for k, v := range oldOpts {
 		if newValue, ok := newOpts[k]; ok && newValue != v {
 			subclauses = append(subclauses, fmt.Sprintf("%s=%s", k, newValue))
 		} else if !ok {
-			def, known := knownDefaults[k]
+			def, known := createOptionDefaults[k]
 			if !known {
 				def = "DEFAULT"
 			}
@@ -716,10 +769,13 @@ type ChangeComment struct {
 
 // Clause returns a clause of an ALTER TABLE statement that changes a table's
 // comment.
-func (cc ChangeComment) Clause(_ StatementModifiers) string {
+func (cc ChangeComment) Clause(mods StatementModifiers) string {
 	// Note: mods.LaxComments is handled in TableDiff.alterStatement() rather than
 	// here, since that modifier's effect depends on whether anything else besides
 	// the comment is also changing
+	if mods.ignoresTableOption("COMMENT") {
+		return ""
+	}
 	return fmt.Sprintf("COMMENT '%s'", EscapeValueForCreateTable(cc.NewComment))
 }
 
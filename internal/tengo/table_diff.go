@@ -216,6 +216,30 @@ func (td *TableDiff) SplitConflicts() (result []*TableDiff) {
 	return result
 }
 
+// SplitClauses returns a slice of TableDiffs each consisting of a single
+// clause of td, in the same order as the clauses of td. This permits running
+// each clause in a separate ALTER TABLE, for example to allow finer-grained
+// use of external online schema change tools, or retrying individual clauses
+// upon failure. If td is not a supported ALTER or has at most one clause, the
+// result contains only td.
+func (td *TableDiff) SplitClauses() (result []*TableDiff) {
+	if td == nil {
+		return nil
+	} else if td.Type != DiffTypeAlter || !td.supported || len(td.alterClauses) <= 1 {
+		return []*TableDiff{td}
+	}
+	for n := range td.alterClauses {
+		result = append(result, &TableDiff{
+			Type:         DiffTypeAlter,
+			From:         td.From,
+			To:           td.To,
+			alterClauses: td.alterClauses[n : n+1],
+			supported:    true,
+		})
+	}
+	return result
+}
+
 // Statement returns the full DDL statement corresponding to the TableDiff. A
 // blank string may be returned if the mods indicate the statement should be
 // skipped. If the mods indicate the statement should be disallowed, it will
@@ -401,6 +425,24 @@ func (td *TableDiff) alterStatement(mods StatementModifiers) (string, error) {
 	return td.From.AlterStatement() + " " + strings.Join(clauseStrings, ", ") + spacer + partitionClauseString, err
 }
 
+// AlterClauses returns the individual clauses of an ALTER TABLE diff, omitting
+// any which would not emit any DDL with the supplied mods. Statement-level
+// suppression (such as LaxComments with a table comment change as the only
+// clause) is not reflected here, so callers should also check Statement. The
+// return value is nil for other diff types, as well as for unsupported diffs.
+func (td *TableDiff) AlterClauses(mods StatementModifiers) []TableAlterClause {
+	if td == nil || td.Type != DiffTypeAlter || !td.supported {
+		return nil
+	}
+	clauses := make([]TableAlterClause, 0, len(td.alterClauses))
+	for _, clause := range td.alterClauses {
+		if clause.Clause(mods) != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
 // MarkSupported provides a mechanism for callers to vouch for the correctness
 // of a TableDiff that was automatically marked as unsupported. This should only
 // be used in cases where a table with UnsupportedDDL is being altered in a way
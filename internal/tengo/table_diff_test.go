@@ -2,8 +2,11 @@ package tengo
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/skeema/skeema/internal/golden"
 )
 
 func TestSchemaDiffAddOrDropTable(t *testing.T) {
@@ -409,6 +412,134 @@ func TestSchemaDiffMultiFulltext(t *testing.T) {
 	}
 }
 
+func TestTableDiffSplitClauses(t *testing.T) {
+	t1 := aTable(1)
+	t2 := aTable(1)
+	t2.Columns[6].Comment = "hello"
+	t2.SecondaryIndexes = t2.SecondaryIndexes[:1]
+	t2.CreateStatement = t2.GeneratedCreateStatement(FlavorUnknown)
+	alter := NewAlterTable(&t1, &t2)
+	expected := []string{
+		"ALTER TABLE `actor` MODIFY COLUMN `alive_bit` bit(1) NOT NULL DEFAULT b'1' COMMENT 'hello'",
+		"ALTER TABLE `actor` DROP KEY `idx_actor_name`",
+	}
+	split := alter.SplitClauses()
+	if len(split) != len(expected) {
+		t.Fatalf("Expected %d TableDiffs, instead found %d", len(expected), len(split))
+	}
+	for n, td := range split {
+		if stmt, err := td.Statement(StatementModifiers{}); err != nil || stmt != expected[n] {
+			t.Errorf("Unexpected result from Statement on split %d: expected %q, found %q / %v", n, expected[n], stmt, err)
+		}
+	}
+
+	// Diffs with only one clause, as well as non-ALTERs, are returned as-is
+	t2 = aTable(1)
+	t2.Columns[6].Comment = "hello"
+	t2.CreateStatement = t2.GeneratedCreateStatement(FlavorUnknown)
+	for _, td := range []*TableDiff{NewAlterTable(&t1, &t2), NewCreateTable(&t1)} {
+		if split := td.SplitClauses(); len(split) != 1 || split[0] != td {
+			t.Errorf("Expected SplitClauses to return receiver as-is, instead found %v", split)
+		}
+	}
+}
+
+// TestTableDiffGolden confirms the CREATE and ALTER output for a table matches
+// the golden files in testdata/golden, which vary by flavor due to the name of
+// the utf8mb3 character set and the default collations of each flavor.
+func TestTableDiffGolden(t *testing.T) {
+	for _, flavor := range []Flavor{ParseFlavor("mysql:5.7.44"), ParseFlavor("mysql:8.0.36"), ParseFlavor("mariadb:10.11.6")} {
+		mods := StatementModifiers{Flavor: flavor}
+		t1 := aTableForFlavor(flavor, 1)
+		t2 := aTableForFlavor(flavor, 1)
+		t2.Columns[6].Comment = "hello"
+		t2.SecondaryIndexes = t2.SecondaryIndexes[:1]
+		t2.CreateStatement = t2.GeneratedCreateStatement(flavor)
+		var b strings.Builder
+		for _, td := range []*TableDiff{NewCreateTable(&t1), NewAlterTable(&t1, &t2)} {
+			stmt, err := td.Statement(mods)
+			if err != nil {
+				t.Fatalf("Unexpected error from Statement: %v", err)
+			}
+			fmt.Fprintf(&b, "%s;\n", stmt)
+		}
+		golden.AssertFlavor(t, "testdata/golden/actor.sql", flavor.String(), b.String(), golden.StripAutoIncrement)
+	}
+}
+
+func TestTableDiffSplitCharSetConversions(t *testing.T) {
+	t1 := aTable(1)
+	t2 := aTable(1)
+	for _, table := range []*Table{&t1, &t2} {
+		table.Columns[1].Type = ParseColumnType("varchar(1000)")
+		table.Columns = append(table.Columns, &Column{
+			Name:      "bio",
+			Type:      ParseColumnType("text"),
+			Nullable:  true,
+			Default:   "NULL",
+			CharSet:   "latin1",
+			Collation: "latin1_swedish_ci",
+		})
+		table.SecondaryIndexes = append(table.SecondaryIndexes, &Index{
+			Name:  "idx_first_name",
+			Parts: []IndexPart{{ColumnName: "first_name"}},
+			Type:  "BTREE",
+		})
+		table.CreateOptions = "ROW_FORMAT=COMPACT"
+	}
+	for _, col := range t2.Columns {
+		if col.CharSet != "" {
+			col.CharSet, col.Collation = "utf8mb4", "utf8mb4_general_ci"
+		}
+	}
+	t2.CharSet, t2.Collation = "utf8mb4", "utf8mb4_general_ci"
+	t2.Columns[6].Comment = "unrelated change"
+	t1.CreateStatement = t1.GeneratedCreateStatement(FlavorUnknown)
+	t2.CreateStatement = t2.GeneratedCreateStatement(FlavorUnknown)
+
+	alter := NewAlterTable(&t1, &t2)
+	stages := alter.SplitCharSetConversions()
+	if len(stages) != 6 {
+		t.Fatalf("Expected 6 stages (1 unrelated, 4 columns, 1 table default), instead found %d", len(stages))
+	}
+	if stmt, _ := stages[0].Statement(StatementModifiers{}); !strings.Contains(stmt, "unrelated change") || strings.Contains(stmt, "utf8mb4") {
+		t.Errorf("Unexpected statement for first stage: %s", stmt)
+	}
+	for n, col := range []string{"first_name", "last_name", "ssn", "bio"} {
+		stmt, _ := stages[n+1].Statement(StatementModifiers{})
+		if !strings.HasPrefix(stmt, "ALTER TABLE `actor` MODIFY COLUMN `"+col+"`") || strings.Count(stmt, "MODIFY COLUMN") != 1 {
+			t.Errorf("Unexpected statement for stage %d: %s", n+1, stmt)
+		}
+	}
+	if stmt, _ := stages[5].Statement(StatementModifiers{}); stmt != "ALTER TABLE `actor` DEFAULT CHARACTER SET = utf8mb4 COLLATE = utf8mb4_general_ci" {
+		t.Errorf("Unexpected statement for final stage: %s", stmt)
+	}
+
+	warnings := alter.CharSetConversionWarnings()
+	expected := []string{"`first_name` would require up to 4000 bytes", "index `idx_first_name` would require up to 4000 bytes", "column `bio`"}
+	if len(warnings) != len(expected) {
+		t.Errorf("Expected %d warnings, instead found %d: %v", len(expected), len(warnings), warnings)
+	} else {
+		for n := range expected {
+			if !strings.Contains(warnings[n], expected[n]) {
+				t.Errorf("Expected warning %d to contain %q, instead found %q", n, expected[n], warnings[n])
+			}
+		}
+	}
+
+	// Diffs without any utf8mb4 conversion are not split, and have no warnings
+	t3 := aTable(1)
+	t3.Columns[6].Comment = "unrelated change"
+	t3.CreateStatement = t3.GeneratedCreateStatement(FlavorUnknown)
+	alter = NewAlterTable(&t1, &t3)
+	if stages := alter.SplitCharSetConversions(); len(stages) != 1 || stages[0] != alter {
+		t.Errorf("Expected diff without conversions to be returned as-is, instead found %v", stages)
+	}
+	if warnings := alter.CharSetConversionWarnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, instead found %v", warnings)
+	}
+}
+
 func TestTableDiffUnsupportedAlter(t *testing.T) {
 	t1 := supportedTable()
 	t2 := unsupportedTable()
@@ -524,6 +655,65 @@ func TestTableDiffClauses(t *testing.T) {
 	}
 }
 
+func TestTableDiffAlterClauses(t *testing.T) {
+	t1 := aTable(1)
+	t2 := aTable(5)
+	t2.Comment = "hello world"
+	t2.CreateStatement = t2.GeneratedCreateStatement(FlavorUnknown)
+	alter := NewAlterTable(&t1, &t2)
+
+	// With NextAutoIncIgnore, the auto-inc change is omitted
+	clauses := alter.AlterClauses(StatementModifiers{})
+	if len(clauses) != 1 {
+		t.Fatalf("Expected 1 clause, instead found %d: %+v", len(clauses), clauses)
+	} else if _, ok := clauses[0].(ChangeComment); !ok {
+		t.Errorf("Expected clause to be ChangeComment, instead found %T", clauses[0])
+	}
+	if clauses := alter.AlterClauses(StatementModifiers{NextAutoInc: NextAutoIncAlways}); len(clauses) != 2 {
+		t.Errorf("Expected 2 clauses, instead found %d: %+v", len(clauses), clauses)
+	}
+
+	// Non-ALTER diffs return nil
+	if clauses := NewCreateTable(&t1).AlterClauses(StatementModifiers{}); clauses != nil {
+		t.Errorf("Expected nil clauses for CREATE TABLE, instead found %+v", clauses)
+	}
+	if clauses := NewDropTable(&t1).AlterClauses(StatementModifiers{}); clauses != nil {
+		t.Errorf("Expected nil clauses for DROP TABLE, instead found %+v", clauses)
+	}
+}
+
+func TestTableDiffIgnoreTableOptions(t *testing.T) {
+	t1 := aTable(1)
+	t2 := aTable(1)
+	t2.CreateOptions = "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8"
+	t2.Comment = "tuned by DBA"
+	t2.CreateStatement = t2.GeneratedCreateStatement(FlavorUnknown)
+	alter := NewAlterTable(&t1, &t2)
+
+	cases := map[string]string{
+		"":                                  "ALTER TABLE `actor` ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8, COMMENT 'tuned by DBA'",
+		"COMMENT":                           "ALTER TABLE `actor` ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8",
+		"ROW_FORMAT":                        "ALTER TABLE `actor` KEY_BLOCK_SIZE=8, COMMENT 'tuned by DBA'",
+		"ROW_FORMAT,KEY_BLOCK_SIZE":         "ALTER TABLE `actor` COMMENT 'tuned by DBA'",
+		"ROW_FORMAT,KEY_BLOCK_SIZE,COMMENT": "",
+	}
+	for ignore, expected := range cases {
+		mods := StatementModifiers{IgnoreTableOptions: ignore}
+		stmt, err := alter.Statement(mods)
+		if err != nil {
+			t.Errorf("Unexpected error from Statement with IgnoreTableOptions=%q: %v", ignore, err)
+		}
+		// Order of create options isn't predictable, so normalize it
+		stmt = strings.Replace(stmt, "KEY_BLOCK_SIZE=8 ROW_FORMAT=COMPRESSED", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", 1)
+		if stmt != expected {
+			t.Errorf("Unexpected result from Statement with IgnoreTableOptions=%q: expected %q, found %q", ignore, expected, stmt)
+		}
+	}
+	if names := IgnorableTableOptions(); !slices.Contains(names, "COMMENT") || !slices.Contains(names, "STATS_PERSISTENT") {
+		t.Errorf("Unexpected result from IgnorableTableOptions: %v", names)
+	}
+}
+
 func TestAlterTableStatementAllowUnsafeMods(t *testing.T) {
 	t1 := aTable(1)
 	t2 := aTable(1)
@@ -679,6 +869,50 @@ func TestAlterTableStatementVirtualColValidation(t *testing.T) {
 	assertWithValidation(false)
 }
 
+func TestModifyColumnVisibility(t *testing.T) {
+	oldCol := &Column{Name: "email", Type: ParseColumnType("varchar(100)"), Nullable: true, Default: "NULL"}
+	newCol := *oldCol
+	newCol.Invisible = true
+	mc := ModifyColumn{OldColumn: oldCol, NewColumn: &newCol}
+	if !mc.VisibilityOnly(StatementModifiers{}) {
+		t.Fatal("Expected VisibilityOnly to return true, but it returned false")
+	}
+
+	cases := map[string]string{
+		"mysql:8.0.22":  "MODIFY COLUMN `email` varchar(100) DEFAULT NULL /*!80023 INVISIBLE */",
+		"mysql:8.0.23":  "ALTER COLUMN `email` SET INVISIBLE",
+		"mariadb:10.11": "MODIFY COLUMN `email` varchar(100) INVISIBLE DEFAULT NULL",
+	}
+	for flavorStr, expected := range cases {
+		mods := StatementModifiers{Flavor: ParseFlavor(flavorStr)}
+		if actual := mc.Clause(mods); actual != expected {
+			t.Errorf("Unexpected clause for flavor %s:\n    Expected: %s\n    Found:    %s", flavorStr, expected, actual)
+		}
+	}
+
+	// Making the column visible again
+	reverse := ModifyColumn{OldColumn: &newCol, NewColumn: oldCol}
+	if actual, expected := reverse.Clause(StatementModifiers{Flavor: ParseFlavor("mysql:8.0.23")}), "ALTER COLUMN `email` SET VISIBLE"; actual != expected {
+		t.Errorf("Unexpected clause:\n    Expected: %s\n    Found:    %s", expected, actual)
+	}
+
+	// Changing position alongside visibility requires a full MODIFY COLUMN
+	mc.PositionFirst = true
+	if actual, expected := mc.Clause(StatementModifiers{Flavor: ParseFlavor("mysql:8.0.23")}), "MODIFY COLUMN `email` varchar(100) DEFAULT NULL /*!80023 INVISIBLE */ FIRST"; actual != expected {
+		t.Errorf("Unexpected clause:\n    Expected: %s\n    Found:    %s", expected, actual)
+	}
+	mc.PositionFirst = false
+
+	// Changing anything else alongside visibility requires a full MODIFY COLUMN
+	newCol.Type = ParseColumnType("varchar(200)")
+	if mc.VisibilityOnly(StatementModifiers{}) {
+		t.Error("Expected VisibilityOnly to return false when type also changed, but it returned true")
+	}
+	if actual := mc.Clause(StatementModifiers{Flavor: ParseFlavor("mysql:8.0.23")}); !strings.HasPrefix(actual, "MODIFY COLUMN") {
+		t.Errorf("Expected MODIFY COLUMN clause when type also changed, instead found %s", actual)
+	}
+}
+
 func TestModifyColumnUnsafe(t *testing.T) {
 	assertUnsafeWithMods := func(type1, type2 string, mods StatementModifiers, expected bool) {
 		t.Helper()
@@ -884,13 +1118,11 @@ func TestModifyColumnUnsafe(t *testing.T) {
 }
 
 func (s TengoIntegrationSuite) TestAlterPageCompression(t *testing.T) {
-	flavor := s.d.Flavor()
 	// Skip test if flavor doesn't support page compression
 	// Note that although MariaDB 10.1 supports this feature, we exclude it here
 	// since it does not seem to work out-of-the-box in Docker images
-	if !flavor.MinMySQL(5, 7) && !flavor.MinMariaDB(10, 2) {
-		t.Skipf("InnoDB page compression not supported in flavor %s", flavor)
-	}
+	SkipUnlessFlavor(t, "mysql:5.7+", "mariadb:10.2+")
+	flavor := s.d.Flavor()
 
 	sqlPath := "pagecompression.sql"
 	if flavor.IsMariaDB() {
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -20,7 +21,7 @@ import (
 
 var reExtraOnUpdate = regexp.MustCompile(`(?i)\bon update (current_timestamp(?:\(\d*\))?)`)
 
-func querySchemaTables(ctx context.Context, db *sqlx.DB, schema string, flavor Flavor) ([]*Table, error) {
+func querySchemaTables(ctx context.Context, db, batchDB *sqlx.DB, schema string, flavor Flavor) ([]*Table, error) {
 	tables, havePartitions, err := queryTablesInSchema(ctx, db, schema, flavor)
 	if err != nil {
 		return nil, err
@@ -28,13 +29,9 @@ func querySchemaTables(ctx context.Context, db *sqlx.DB, schema string, flavor F
 
 	g, subCtx := errgroup.WithContext(ctx)
 
-	for _, t := range tables {
-		g.Go(func() (err error) {
-			t.CreateStatement, err = showCreateTable(subCtx, db, t.Name)
-			if err != nil {
-				err = fmt.Errorf("Error executing SHOW CREATE TABLE for %s.%s: %w", EscapeIdentifier(schema), EscapeIdentifier(t.Name), err)
-			}
-			return err
+	for batch := range slices.Chunk(tables, showCreateBatchSize) {
+		g.Go(func() error {
+			return showCreateTables(subCtx, db, batchDB, schema, batch)
 		})
 	}
 
@@ -112,7 +109,7 @@ func querySchemaTables(ctx context.Context, db *sqlx.DB, schema string, flavor F
 				part.Engine = t.Engine
 			}
 			t.Partitioning = p
-			fixPartitioningEdgeCases(t, flavor)
+			fixPartitioningEdgeCases(t)
 		}
 
 		// Obtain TABLESPACE clause from SHOW CREATE TABLE, if present
@@ -189,6 +186,33 @@ func querySchemaTables(ctx context.Context, db *sqlx.DB, schema string, flavor F
 	return tables, nil
 }
 
+// showCreateTables sets the CreateStatement field of each of the supplied
+// tables, using a single round trip via batchDB if non-nil. Otherwise, or if
+// the batch fails for any reason, each table is queried separately using db.
+// Batched queries use schema-qualified names, since batchDB has no default
+// database.
+func showCreateTables(ctx context.Context, db, batchDB *sqlx.DB, schema string, tables []*Table) error {
+	queries := make([]string, len(tables))
+	for n, t := range tables {
+		queries[n] = "SHOW CREATE TABLE " + EscapeIdentifier(schema) + "." + EscapeIdentifier(t.Name)
+	}
+	if batchDB != nil {
+		if creates, err := showCreateBatch(ctx, batchDB, queries); err == nil {
+			for n, t := range tables {
+				t.CreateStatement = creates[n]
+			}
+			return nil
+		}
+	}
+	for _, t := range tables {
+		var err error
+		if t.CreateStatement, err = showCreateTable(ctx, db, t.Name); err != nil {
+			return fmt.Errorf("Error executing SHOW CREATE TABLE for %s.%s: %w", EscapeIdentifier(schema), EscapeIdentifier(t.Name), err)
+		}
+	}
+	return nil
+}
+
 func queryTablesInSchema(ctx context.Context, db *sqlx.DB, schema string, flavor Flavor) ([]*Table, bool, error) {
 	var rawTables []struct {
 		Name           string         `db:"table_name"`
@@ -608,25 +632,41 @@ func queryPartitionsInSchema(ctx context.Context, db *sqlx.DB, schema string, fl
 		return nil, fmt.Errorf("Error querying information_schema.partitions for schema %s: %s", schema, err)
 	}
 
-	partitioningByTableName := make(map[string]*TablePartitioning)
+	// Each table's partitions are stored in a single backing array, rather than
+	// allocating each Partition separately; this reduces allocation overhead for
+	// tables with many partitions.
+	// Rows are ordered by table name, but with a case-insensitive table_name
+	// collation, rows of tables whose names differ only by case may be
+	// interleaved. So the partitions of each exact table name are counted first,
+	// and then each row is appended to its table's partition list.
+	counts := make(map[string]int)
+	for _, rawPart := range rawPartitioning {
+		counts[rawPart.TableName]++
+	}
+	partitioningByTableName := make(map[string]*TablePartitioning, len(counts))
+	backingByTableName := make(map[string][]Partition, len(counts))
 	for _, rawPart := range rawPartitioning {
 		p, ok := partitioningByTableName[rawPart.TableName]
 		if !ok {
+			count := counts[rawPart.TableName]
 			p = &TablePartitioning{
 				Method:        rawPart.Method,
 				SubMethod:     rawPart.SubMethod.String,
 				Expression:    rawPart.Expression.String,
 				SubExpression: rawPart.SubExpression.String,
-				Partitions:    make([]*Partition, 0),
+				Partitions:    make([]*Partition, 0, count),
 			}
 			partitioningByTableName[rawPart.TableName] = p
+			backingByTableName[rawPart.TableName] = make([]Partition, count)
 		}
-		p.Partitions = append(p.Partitions, &Partition{
+		part := &backingByTableName[rawPart.TableName][len(p.Partitions)]
+		*part = Partition{
 			Name:    rawPart.PartitionName,
 			SubName: rawPart.SubName.String,
 			Values:  rawPart.Values.String,
 			Comment: rawPart.Comment,
-		})
+		}
+		p.Partitions = append(p.Partitions, part)
 	}
 	return partitioningByTableName, nil
 }
@@ -768,7 +808,7 @@ func fixGenerationExpr(t *Table, flavor Flavor) {
 
 // fixPartitioningEdgeCases handles situations that are reflected in SHOW CREATE
 // TABLE, but missing (or difficult to obtain) in information_schema.
-func fixPartitioningEdgeCases(t *Table, flavor Flavor) {
+func fixPartitioningEdgeCases(t *Table) {
 	// Handle edge cases for how partitions are expressed in HASH or KEY methods:
 	// typically this will just be a PARTITIONS N clause, but it could also be
 	// nothing at all, or an explicit list of partitions, depending on how the
@@ -794,23 +834,36 @@ func fixPartitioningEdgeCases(t *Table, flavor Flavor) {
 	}
 
 	// Process DATA DIRECTORY clauses, which are easier to parse from SHOW CREATE
-	// TABLE instead of information_schema.innodb_sys_tablespaces.
+	// TABLE instead of information_schema.innodb_sys_tablespaces. Each partition
+	// definition is on its own line, so a single pass over the lines suffices,
+	// avoiding quadratic behavior on tables with many partitions.
 	if (t.Partitioning.ForcePartitionList == PartitionListDefault || t.Partitioning.ForcePartitionList == PartitionListExplicit) &&
 		strings.Contains(t.CreateStatement, " DATA DIRECTORY = ") {
-		for _, p := range t.Partitioning.Partitions {
-			name := p.Name
-			if flavor.MinMariaDB(10, 2) {
-				name = EscapeIdentifier(name)
+		dataDirs := make(map[string]string)
+		for _, line := range strings.Split(t.CreateStatement, "\n") {
+			if !strings.Contains(line, " DATA DIRECTORY = ") {
+				continue
 			}
-			name = regexp.QuoteMeta(name)
-			re := regexp.MustCompile(fmt.Sprintf(`PARTITION %s .*DATA DIRECTORY = '((?:\\\\|\\'|''|[^'])*)'`, name))
-			if matches := re.FindStringSubmatch(t.CreateStatement); matches != nil {
-				p.DataDir = matches[1]
+			if matches := rePartitionDataDirLine.FindStringSubmatch(line); matches != nil {
+				name := matches[1]
+				if name[0] == '`' { // always the case in MariaDB 10.2+
+					name = strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+				}
+				if _, already := dataDirs[name]; !already {
+					dataDirs[name] = matches[2]
+				}
+			}
+		}
+		for _, p := range t.Partitioning.Partitions {
+			if dataDir, ok := dataDirs[p.Name]; ok {
+				p.DataDir = dataDir
 			}
 		}
 	}
 }
 
+var rePartitionDataDirLine = regexp.MustCompile("^\\s*\\(?PARTITION (`(?:[^`]|``)+`|\\S+) .*DATA DIRECTORY = '((?:\\\\\\\\|\\\\'|''|[^'])*)'")
+
 var rePerconaColCompressionLine = regexp.MustCompile("^\\s+`((?:[^`]|``)+)` .* /\\*!50633 COLUMN_FORMAT (COMPRESSED[^*]*) \\*/")
 
 // fixPerconaColCompression parses the table's CREATE string in order to
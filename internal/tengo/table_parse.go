@@ -0,0 +1,1413 @@
+package tengo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseCreateTable converts a CREATE TABLE statement into a Table, without
+// using a database server. The returned Table's CreateStatement is generated
+// from its fields, in an attempt to match what SHOW CREATE TABLE would return
+// in the supplied flavor. The defaultCharSet and defaultCollation are used for
+// tables lacking table-level character set and collation clauses; if both are
+// blank, the flavor's server default is assumed.
+//
+// Fidelity is intentionally limited compared to executing the statement in a
+// real workspace:
+//
+//   - Expressions (DEFAULT expressions, generated columns, CHECK constraints,
+//     and functional index parts) are kept as written, rather than normalized
+//     the way the server would.
+//   - Column type aliases, integer display widths, default values, charsets,
+//     and collations are normalized using common rules, which do not cover
+//     every server version or setting, such as a non-default sql_mode or
+//     @@character_set_collations.
+//   - Version-gated comments are treated as executable, except that /*M! ...*/
+//     comments are only executable with MariaDB flavors.
+//   - Partitioning, CREATE TABLE ... LIKE, CREATE TABLE ... SELECT, and inline
+//     REFERENCES column clauses are not supported, and result in an error.
+//   - Nothing outside of the statement itself is verified; for example, foreign
+//     keys are not checked against the referenced table.
+func ParseCreateTable(body string, flavor Flavor, defaultCharSet, defaultCollation string) (*Table, error) {
+	p, err := newCreateTableParser(body, flavor)
+	if err != nil {
+		return nil, err
+	}
+	t, err := p.parse(defaultCharSet, defaultCollation)
+	if err != nil {
+		return nil, err
+	}
+	t.CreateStatement = t.GeneratedCreateStatement(flavor)
+	return t, nil
+}
+
+// createTableToken is a lexed token along with its byte offset in the
+// statement, which permits extraction of raw expression text.
+type createTableToken struct {
+	val string
+	typ TokenType
+	pos int
+}
+
+// createTableParser is a recursive-descent parser for a single CREATE TABLE
+// statement. Unlike the statement-splitting parser in parser.go, it examines
+// the full body of the statement.
+type createTableParser struct {
+	body   string
+	tokens []createTableToken
+	n      int // position of next token to consume
+	flavor Flavor
+	table  *Table
+	checks int // number of unnamed check constraints encountered so far
+	fks    int // number of unnamed foreign keys encountered so far
+}
+
+func newCreateTableParser(body string, flavor Flavor) (*createTableParser, error) {
+	p := &createTableParser{
+		body:   body,
+		flavor: flavor,
+	}
+	if err := p.lex(body, 0); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// lex appends tokens from input, which begins at byte offset base of p.body.
+// Version-gated comments in filler tokens are lexed recursively, so that their
+// contents are treated as executable.
+func (p *createTableParser) lex(input string, base int) error {
+	lexer := NewLexer(strings.NewReader(input), "\000", 1024)
+	pos := base
+	for {
+		data, typ, err := lexer.Scan()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if typ == TokenFiller {
+			if err := p.lexVersionedComments(string(data), pos); err != nil {
+				return err
+			}
+		} else if typ != TokenDelimiter {
+			p.tokens = append(p.tokens, createTableToken{val: string(data), typ: typ, pos: pos})
+		}
+		pos += len(data)
+	}
+}
+
+// lexVersionedComments finds any executable version-gated comments in filler,
+// which begins at byte offset base of p.body, and lexes their contents.
+func (p *createTableParser) lexVersionedComments(filler string, base int) error {
+	for n := 0; n < len(filler); {
+		rest := filler[n:]
+		if strings.HasPrefix(rest, "--") || rest[0] == '#' {
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				n += end + 1
+				continue
+			}
+			return nil
+		} else if !strings.HasPrefix(rest, "/*") {
+			n++
+			continue
+		}
+		end := strings.Index(rest, "*/")
+		if end < 0 {
+			return nil // lexer already reports unterminated comments
+		}
+		var contentStart int
+		if strings.HasPrefix(rest, "/*!") {
+			contentStart = 3
+		} else if strings.HasPrefix(rest, "/*M!") && p.flavor.IsMariaDB() {
+			contentStart = 4
+		}
+		if contentStart > 0 {
+			for contentStart < end && rest[contentStart] >= '0' && rest[contentStart] <= '9' {
+				contentStart++
+			}
+			if err := p.lex(rest[contentStart:end], base+n+contentStart); err != nil {
+				return err
+			}
+		}
+		n += end + 2
+	}
+	return nil
+}
+
+///// Token helpers ////////////////////////////////////////////////////////////
+
+func (p *createTableParser) done() bool {
+	return p.n >= len(p.tokens)
+}
+
+func (p *createTableParser) peek() createTableToken {
+	if p.done() {
+		return createTableToken{pos: len(p.body)}
+	}
+	return p.tokens[p.n]
+}
+
+func (p *createTableParser) next() createTableToken {
+	t := p.peek()
+	if !p.done() {
+		p.n++
+	}
+	return t
+}
+
+// peekWord returns true if the next tokens are the supplied sequence of
+// keywords, compared case-insensitively.
+func (p *createTableParser) peekWord(words ...string) bool {
+	if p.n+len(words) > len(p.tokens) {
+		return false
+	}
+	for n, word := range words {
+		t := p.tokens[p.n+n]
+		if t.typ != TokenWord || !strings.EqualFold(t.val, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptWord consumes the supplied sequence of keywords if present, returning
+// true if it did so.
+func (p *createTableParser) acceptWord(words ...string) bool {
+	if p.peekWord(words...) {
+		p.n += len(words)
+		return true
+	}
+	return false
+}
+
+func (p *createTableParser) peekSymbol(sym string) bool {
+	t := p.peek()
+	return t.typ == TokenSymbol && t.val == sym
+}
+
+func (p *createTableParser) acceptSymbol(sym string) bool {
+	if p.peekSymbol(sym) {
+		p.n++
+		return true
+	}
+	return false
+}
+
+func (p *createTableParser) expectSymbol(sym string) error {
+	if !p.acceptSymbol(sym) {
+		return p.unexpected("expected " + sym)
+	}
+	return nil
+}
+
+// unexpected returns an error describing the next token, which is not
+// consumed.
+func (p *createTableParser) unexpected(detail string) error {
+	if p.done() {
+		return fmt.Errorf("unexpected end of statement (%s)", detail)
+	}
+	t := p.peek()
+	line := strings.Count(p.body[:t.pos], "\n") + 1
+	return fmt.Errorf("unexpected %q on line %d of statement (%s)", t.val, line, detail)
+}
+
+// unsupported returns an error for valid syntax which this parser does not
+// handle.
+func (p *createTableParser) unsupported(what string) error {
+	return fmt.Errorf("%s is not supported without a database server", what)
+}
+
+// identifier consumes and returns an identifier, either backtick-quoted or
+// bare.
+func (p *createTableParser) identifier() (string, error) {
+	t := p.peek()
+	var name string
+	if t.typ == TokenIdent {
+		name = stripBackticks(t.val)
+	} else if t.typ == TokenWord || (t.typ == TokenString && t.val[0] == '"') {
+		name = stripAnyQuote(t.val)
+	}
+	if name == "" {
+		return "", p.unexpected("expected non-empty identifier")
+	}
+	p.n++
+	return name, nil
+}
+
+// identifierList consumes a parenthesized, comma-separated list of
+// identifiers.
+func (p *createTableParser) identifierList() (names []string, err error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.acceptSymbol(")") {
+			return names, nil
+		} else if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// stringLiteral consumes a quoted string, returning its unescaped value.
+func (p *createTableParser) stringLiteral() (string, error) {
+	t := p.peek()
+	if t.typ != TokenString {
+		return "", p.unexpected("expected quoted string")
+	}
+	p.n++
+	return stripAnyQuote(t.val), nil
+}
+
+// word consumes a bare word or quoted string, returning its value. This is
+// used for values such as charset names, which may optionally be quoted.
+func (p *createTableParser) word() (string, error) {
+	t := p.peek()
+	switch t.typ {
+	case TokenWord, TokenNumeric:
+		p.n++
+		return t.val, nil
+	case TokenString, TokenIdent:
+		p.n++
+		return stripAnyQuote(t.val), nil
+	}
+	return "", p.unexpected("expected value")
+}
+
+// parenthesized consumes a balanced parenthesized group, returning the raw
+// text between the outer parens, with surrounding whitespace trimmed.
+func (p *createTableParser) parenthesized() (string, error) {
+	if !p.peekSymbol("(") {
+		return "", p.unexpected("expected (")
+	}
+	open := p.next()
+	for depth := 1; depth > 0; {
+		if p.done() {
+			return "", p.unexpected("unbalanced parentheses")
+		}
+		t := p.next()
+		if t.typ == TokenSymbol && t.val == "(" {
+			depth++
+		} else if t.typ == TokenSymbol && t.val == ")" {
+			depth--
+		}
+	}
+	closer := p.tokens[p.n-1]
+	return strings.TrimSpace(p.body[open.pos+1 : closer.pos]), nil
+}
+
+// acceptEquals consumes an optional "=", as permitted in table options.
+func (p *createTableParser) acceptEquals() {
+	p.acceptSymbol("=")
+}
+
+///// Grammar //////////////////////////////////////////////////////////////////
+
+func (p *createTableParser) parse(defaultCharSet, defaultCollation string) (*Table, error) {
+	if !p.acceptWord("CREATE") {
+		return nil, p.unexpected("expected CREATE")
+	}
+	if p.peekWord("TEMPORARY") {
+		return nil, p.unsupported("CREATE TEMPORARY TABLE")
+	} else if !p.acceptWord("TABLE") {
+		return nil, p.unexpected("expected TABLE")
+	}
+	p.acceptWord("IF", "NOT", "EXISTS")
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(".") { // schema name qualifier is ignored
+		if name, err = p.identifier(); err != nil {
+			return nil, err
+		}
+	}
+	p.table = &Table{Name: name}
+	if p.peekWord("LIKE") || p.peekSymbol("(") && p.n+1 < len(p.tokens) && strings.EqualFold(p.tokens[p.n+1].val, "LIKE") {
+		return nil, p.unsupported("CREATE TABLE ... LIKE")
+	} else if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.parseDefinition(); err != nil {
+			return nil, err
+		}
+		if p.acceptSymbol(")") {
+			break
+		} else if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.parseTableOptions(); err != nil {
+		return nil, err
+	}
+	p.acceptSymbol(";")
+	if !p.done() {
+		return nil, p.unexpected("expected end of statement")
+	}
+	if len(p.table.Columns) == 0 {
+		return nil, errors.New("table must have at least one column")
+	}
+	if err := p.finish(defaultCharSet, defaultCollation); err != nil {
+		return nil, err
+	}
+	return p.table, nil
+}
+
+// parseDefinition handles one item in the table's parenthesized definition
+// list: a column, index, or constraint.
+func (p *createTableParser) parseDefinition() error {
+	var constraintName string
+	if p.acceptWord("CONSTRAINT") {
+		if !p.peekWord("PRIMARY") && !p.peekWord("UNIQUE") && !p.peekWord("FOREIGN") && !p.peekWord("CHECK") {
+			var err error
+			if constraintName, err = p.identifier(); err != nil {
+				return err
+			}
+		}
+		if !p.peekWord("PRIMARY") && !p.peekWord("UNIQUE") && !p.peekWord("FOREIGN") && !p.peekWord("CHECK") {
+			return p.unexpected("expected PRIMARY KEY, UNIQUE, FOREIGN KEY, or CHECK")
+		}
+	}
+	switch {
+	case p.acceptWord("PRIMARY", "KEY"):
+		return p.parseIndex(&Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE"}, false)
+	case p.acceptWord("UNIQUE"):
+		_ = p.acceptWord("KEY") || p.acceptWord("INDEX")
+		return p.parseIndex(&Index{Name: constraintName, Unique: true, Type: "BTREE"}, true)
+	case p.acceptWord("FULLTEXT"), p.acceptWord("SPATIAL"):
+		typ := strings.ToUpper(p.tokens[p.n-1].val)
+		_ = p.acceptWord("KEY") || p.acceptWord("INDEX")
+		return p.parseIndex(&Index{Type: typ}, true)
+	case p.acceptWord("KEY"), p.acceptWord("INDEX"):
+		return p.parseIndex(&Index{Type: "BTREE"}, true)
+	case p.acceptWord("FOREIGN", "KEY"):
+		return p.parseForeignKey(constraintName)
+	case p.acceptWord("CHECK"):
+		return p.parseCheck(constraintName)
+	}
+	return p.parseColumn()
+}
+
+// parseIndex handles the remainder of an index definition, after its type
+// keywords. If allowName is true, an optional index name may be present.
+func (p *createTableParser) parseIndex(idx *Index, allowName bool) (err error) {
+	if allowName && !p.peekSymbol("(") && !p.peekWord("USING") {
+		if idx.Name, err = p.identifier(); err != nil {
+			return err
+		}
+	}
+	if p.acceptWord("USING") {
+		if _, err := p.word(); err != nil {
+			return err
+		}
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return err
+	}
+	for {
+		var part IndexPart
+		if p.peekSymbol("(") {
+			if part.Expression, err = p.parenthesized(); err != nil {
+				return err
+			}
+		} else {
+			if part.ColumnName, err = p.identifier(); err != nil {
+				return err
+			}
+			if p.acceptSymbol("(") {
+				t := p.next()
+				length, err := strconv.ParseUint(t.val, 10, 16)
+				if t.typ != TokenNumeric || err != nil {
+					return fmt.Errorf("invalid prefix length %q for index part %s", t.val, part.ColumnName)
+				}
+				part.PrefixLength = uint16(length)
+				if err := p.expectSymbol(")"); err != nil {
+					return err
+				}
+			}
+		}
+		if p.acceptWord("DESC") {
+			part.Descending = true
+		} else {
+			p.acceptWord("ASC")
+		}
+		idx.Parts = append(idx.Parts, part)
+		if p.acceptSymbol(")") {
+			break
+		} else if err := p.expectSymbol(","); err != nil {
+			return err
+		}
+	}
+
+	// Index options
+	for {
+		switch {
+		case p.acceptWord("COMMENT"):
+			if idx.Comment, err = p.stringLiteral(); err != nil {
+				return err
+			}
+		case p.acceptWord("USING"), p.acceptWord("KEY_BLOCK_SIZE"):
+			p.acceptEquals()
+			if _, err := p.word(); err != nil {
+				return err
+			}
+		case p.acceptWord("WITH", "PARSER"):
+			if idx.FullTextParser, err = p.identifier(); err != nil {
+				return err
+			}
+		case p.acceptWord("INVISIBLE"), p.acceptWord("IGNORED"):
+			idx.Invisible = true
+		case p.acceptWord("VISIBLE"), p.acceptWord("NOT", "IGNORED"):
+			idx.Invisible = false
+		default:
+			p.table.addIndex(idx)
+			return nil
+		}
+	}
+}
+
+// addIndex adds idx to t, as either the primary key or a secondary index.
+// Names of secondary indexes are assigned later by finish, if omitted.
+func (t *Table) addIndex(idx *Index) {
+	if idx.PrimaryKey {
+		t.PrimaryKey = idx
+	} else {
+		t.SecondaryIndexes = append(t.SecondaryIndexes, idx)
+	}
+}
+
+func (p *createTableParser) parseForeignKey(constraintName string) (err error) {
+	fk := &ForeignKey{Name: constraintName}
+	var indexName string
+	if !p.peekSymbol("(") {
+		if indexName, err = p.identifier(); err != nil {
+			return err
+		}
+	}
+	if fk.ColumnNames, err = p.identifierList(); err != nil {
+		return err
+	}
+	if !p.acceptWord("REFERENCES") {
+		return p.unexpected("expected REFERENCES")
+	}
+	if fk.ReferencedTableName, err = p.identifier(); err != nil {
+		return err
+	}
+	if p.acceptSymbol(".") {
+		fk.ReferencedSchemaName = fk.ReferencedTableName
+		if fk.ReferencedTableName, err = p.identifier(); err != nil {
+			return err
+		}
+	}
+	if fk.ReferencedColumnNames, err = p.identifierList(); err != nil {
+		return err
+	} else if len(fk.ReferencedColumnNames) != len(fk.ColumnNames) {
+		return errors.New("foreign key has mismatched number of referencing and referenced columns")
+	}
+	if p.acceptWord("MATCH") {
+		if _, err := p.word(); err != nil {
+			return err
+		}
+	}
+	defaultRule := "RESTRICT"
+	if p.flavor.MinMySQL(8) {
+		defaultRule = "NO ACTION"
+	}
+	fk.DeleteRule, fk.UpdateRule = defaultRule, defaultRule
+	for p.acceptWord("ON") {
+		var rule *string
+		if p.acceptWord("DELETE") {
+			rule = &fk.DeleteRule
+		} else if p.acceptWord("UPDATE") {
+			rule = &fk.UpdateRule
+		} else {
+			return p.unexpected("expected DELETE or UPDATE")
+		}
+		i := slices.IndexFunc(foreignKeyActions, func(action string) bool {
+			return p.acceptWord(strings.Fields(action)...)
+		})
+		if i < 0 {
+			return p.unexpected("expected foreign key action")
+		}
+		*rule = foreignKeyActions[i]
+	}
+	if fk.Name == "" {
+		p.fks++
+		fk.Name = fmt.Sprintf("%s_ibfk_%d", p.table.Name, p.fks)
+	}
+	p.table.ForeignKeys = append(p.table.ForeignKeys, fk)
+
+	// Servers automatically add an index for the foreign key's columns, unless
+	// one is already present. This is checked later by finish, once all indexes
+	// are known; for now, track the desired name using a placeholder index.
+	if indexName == "" {
+		indexName = fk.Name
+	}
+	idx := &Index{Name: indexName, Type: "BTREE", Attributes: fkIndexPlaceholder}
+	for _, col := range fk.ColumnNames {
+		idx.Parts = append(idx.Parts, IndexPart{ColumnName: col})
+	}
+	p.table.SecondaryIndexes = append(p.table.SecondaryIndexes, idx)
+	return nil
+}
+
+var foreignKeyActions = []string{"RESTRICT", "CASCADE", "SET NULL", "NO ACTION", "SET DEFAULT"}
+
+// fkIndexPlaceholder is temporarily stored in the Attributes field of indexes
+// that were implicitly created for foreign keys. It cannot collide with any
+// real index attributes, since it is not valid SQL.
+const fkIndexPlaceholder = "\000fk"
+
+// binaryCollationPlaceholder is temporarily stored in the Collation field of
+// columns using the BINARY attribute without a character set.
+const binaryCollationPlaceholder = "\000bin"
+
+func (p *createTableParser) parseCheck(constraintName string) error {
+	clause, err := p.parenthesized()
+	if err != nil {
+		return err
+	}
+	cc := &Check{Name: constraintName, Clause: clause, Enforced: true}
+	if p.acceptWord("NOT", "ENFORCED") {
+		cc.Enforced = p.flavor.IsMariaDB() // MariaDB ignores this clause
+	} else {
+		p.acceptWord("ENFORCED")
+	}
+	if cc.Name == "" {
+		p.checks++
+		if p.flavor.IsMariaDB() {
+			cc.Name = fmt.Sprintf("CONSTRAINT_%d", p.checks)
+		} else {
+			cc.Name = fmt.Sprintf("%s_chk_%d", p.table.Name, p.checks)
+		}
+	}
+	p.table.Checks = append(p.table.Checks, cc)
+	return nil
+}
+
+func (p *createTableParser) parseColumn() (err error) {
+	col := &Column{Nullable: true}
+	if col.Name, err = p.identifier(); err != nil {
+		return err
+	}
+	for _, other := range p.table.Columns {
+		if strings.EqualFold(other.Name, col.Name) {
+			return fmt.Errorf("duplicate column name %s", EscapeIdentifier(col.Name))
+		}
+	}
+	typ, err := p.parseColumnType()
+	if err != nil {
+		return err
+	}
+	var explicitNull, binaryCollation bool
+	var rawDefault []createTableToken
+	for !p.done() && !p.peekSymbol(",") && !p.peekSymbol(")") {
+		switch {
+		case p.acceptWord("NOT", "NULL"):
+			col.Nullable, explicitNull = false, true
+		case p.acceptWord("NULL"):
+			col.Nullable, explicitNull = true, true
+		case p.acceptWord("DEFAULT"):
+			if rawDefault, err = p.defaultValueTokens(); err != nil {
+				return err
+			}
+		case p.acceptWord("ON", "UPDATE"):
+			t := p.next()
+			if col.OnUpdate = p.currentTimestamp(t); col.OnUpdate == "" {
+				return fmt.Errorf("unsupported ON UPDATE value %q for column %s", t.val, EscapeIdentifier(col.Name))
+			}
+		case p.acceptWord("AUTO_INCREMENT"):
+			col.AutoIncrement = true
+		case p.acceptWord("PRIMARY", "KEY"), p.acceptWord("KEY"):
+			p.table.addIndex(&Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []IndexPart{{ColumnName: col.Name}}})
+		case p.acceptWord("UNIQUE"):
+			p.acceptWord("KEY")
+			p.table.addIndex(&Index{Unique: true, Type: "BTREE", Parts: []IndexPart{{ColumnName: col.Name}}})
+		case p.acceptWord("COMMENT"):
+			if col.Comment, err = p.stringLiteral(); err != nil {
+				return err
+			}
+		case p.acceptWord("CHARACTER", "SET"), p.acceptWord("CHARSET"):
+			if col.CharSet, err = p.word(); err != nil {
+				return err
+			}
+			col.CharSet = strings.ToLower(col.CharSet)
+		case p.acceptWord("COLLATE"):
+			if col.Collation, err = p.word(); err != nil {
+				return err
+			}
+			col.Collation = strings.ToLower(col.Collation)
+		case p.acceptWord("BINARY"):
+			binaryCollation = true
+		case p.acceptWord("GENERATED", "ALWAYS", "AS"), p.acceptWord("AS"):
+			if col.GenerationExpr, err = p.parenthesized(); err != nil {
+				return err
+			}
+			col.Virtual = true
+		case p.acceptWord("VIRTUAL"):
+			col.Virtual = true
+		case p.acceptWord("STORED"), p.acceptWord("PERSISTENT"):
+			col.Virtual = false
+		case p.acceptWord("INVISIBLE"):
+			col.Invisible = true
+		case p.acceptWord("VISIBLE"):
+			col.Invisible = false
+		case p.acceptWord("SRID"):
+			t := p.next()
+			srid, err := strconv.ParseUint(t.val, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid SRID %q for column %s", t.val, EscapeIdentifier(col.Name))
+			}
+			col.SpatialReferenceID, col.HasSpatialReference = uint32(srid), true
+		case p.acceptWord("COLUMN_FORMAT"), p.acceptWord("STORAGE"):
+			if _, err := p.word(); err != nil {
+				return err
+			}
+		case p.acceptWord("CONSTRAINT"), p.peekWord("CHECK"):
+			var name string
+			if !p.peekWord("CHECK") {
+				if name, err = p.identifier(); err != nil {
+					return err
+				}
+			}
+			if !p.acceptWord("CHECK") {
+				return p.unexpected("expected CHECK")
+			}
+			if p.flavor.IsMariaDB() && name == "" {
+				if col.CheckClause, err = p.parenthesized(); err != nil {
+					return err
+				}
+			} else if err := p.parseCheck(name); err != nil {
+				return err
+			}
+		case p.peekWord("REFERENCES"):
+			return p.unsupported("inline REFERENCES clause")
+		default:
+			return p.unexpected("in definition of column " + EscapeIdentifier(col.Name))
+		}
+	}
+	if col.GenerationExpr != "" && !explicitNull {
+		col.Nullable = true
+	}
+	col.Type = typ
+	if isTextualType(typ.Base) {
+		if binaryCollation && col.Collation == "" && col.CharSet == "" {
+			col.Collation = binaryCollationPlaceholder // resolved in finish, once the table's charset is known
+		} else {
+			if binaryCollation && col.Collation == "" {
+				col.Collation = col.CharSet + "_bin"
+			}
+			col.CharSet, col.Collation = normalizeCharSetCollation(col.CharSet, col.Collation, p.flavor)
+		}
+	}
+	if col.Default, err = p.normalizeDefault(rawDefault, col); err != nil {
+		return err
+	}
+	p.table.Columns = append(p.table.Columns, col)
+	return nil
+}
+
+// parseColumnType consumes a column data type and its modifiers, returning a
+// normalized ColumnType.
+func (p *createTableParser) parseColumnType() (ct ColumnType, err error) {
+	t := p.next()
+	if t.typ != TokenWord {
+		return ct, fmt.Errorf("expected column data type, instead found %q", t.val)
+	}
+	base := strings.ToLower(t.val)
+	var size, scale string
+	switch base {
+	case "double":
+		p.acceptWord("PRECISION")
+	case "character", "char":
+		base = "char"
+		if p.acceptWord("VARYING") {
+			base = "varchar"
+		}
+	case "national", "nchar", "nvarchar", "long", "serial":
+		return ct, p.unsupported("column type " + t.val)
+	}
+	if alias, ok := columnTypeAliases[base]; ok {
+		base = alias
+	}
+	if base == "bool" {
+		base, size = "tinyint", "1"
+	}
+
+	var values []string
+	var numbers int
+	if p.acceptSymbol("(") {
+		for {
+			v := p.next()
+			if base == "enum" || base == "set" {
+				if v.typ != TokenString {
+					return ct, fmt.Errorf("expected quoted value in %s definition, instead found %q", base, v.val)
+				}
+				values = append(values, "'"+EscapeValueForCreateTable(stripAnyQuote(v.val))+"'")
+			} else if v.typ != TokenNumeric {
+				return ct, fmt.Errorf("expected number in %s definition, instead found %q", base, v.val)
+			} else if n, err := strconv.ParseUint(v.val, 10, 16); err != nil {
+				return ct, fmt.Errorf("invalid number %q in %s definition", v.val, base)
+			} else if numbers++; numbers == 1 {
+				size = strconv.FormatUint(n, 10)
+			} else {
+				scale = strconv.FormatUint(n, 10)
+			}
+			if p.acceptSymbol(")") {
+				break
+			} else if !p.acceptSymbol(",") {
+				return ct, p.unexpected("expected , or ) in column type")
+			}
+		}
+	}
+	var unsigned, zerofill bool
+	for {
+		if p.acceptWord("UNSIGNED") {
+			unsigned = true
+		} else if p.acceptWord("ZEROFILL") {
+			unsigned, zerofill = true, true
+		} else if !p.acceptWord("SIGNED") {
+			break
+		}
+	}
+
+	// Normalize sizes, using what SHOW CREATE TABLE displays by default
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "bigint":
+		if size == "" && !p.flavor.OmitIntDisplayWidth() {
+			size = strconv.Itoa(defaultIntDisplayWidth(base, unsigned))
+		}
+	case "year":
+		if !p.flavor.OmitIntDisplayWidth() {
+			size = "4"
+		}
+	case "decimal":
+		if size == "" {
+			size = "10"
+		}
+		if scale == "" {
+			scale = "0"
+		}
+	case "float":
+		if size != "" && scale == "" { // float(p) defines precision in bits
+			if precision, _ := strconv.Atoi(size); precision > 24 {
+				base = "double"
+			}
+			size = ""
+		}
+	case "char", "binary", "bit":
+		if size == "" {
+			size = "1"
+		}
+	case "varchar", "varbinary":
+		if size == "" {
+			return ct, fmt.Errorf("column type %s requires a length", base)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(base)
+	if len(values) > 0 {
+		b.WriteString("(" + strings.Join(values, ",") + ")")
+	} else if size != "" && scale != "" {
+		b.WriteString("(" + size + "," + scale + ")")
+	} else if size != "" && size != "0" || size == "0" && !isTimeType(base) {
+		b.WriteString("(" + size + ")")
+	}
+	if unsigned && (isNumericType(base)) {
+		b.WriteString(" unsigned")
+	}
+	if zerofill && (isNumericType(base)) {
+		b.WriteString(" zerofill")
+	}
+	ct = ParseColumnType(b.String())
+	if p.flavor.OmitIntDisplayWidth() {
+		ct.StripDisplayWidth()
+	}
+	return ct, nil
+}
+
+var columnTypeAliases = map[string]string{
+	"boolean":   "bool",
+	"integer":   "int",
+	"int1":      "tinyint",
+	"int2":      "smallint",
+	"int3":      "mediumint",
+	"middleint": "mediumint",
+	"int4":      "int",
+	"int8":      "bigint",
+	"dec":       "decimal",
+	"numeric":   "decimal",
+	"fixed":     "decimal",
+	"real":      "double",
+	"float4":    "float",
+	"float8":    "double",
+}
+
+func defaultIntDisplayWidth(base string, unsigned bool) int {
+	widths := map[string][2]int{
+		"tinyint":   {4, 3},
+		"smallint":  {6, 5},
+		"mediumint": {9, 8},
+		"int":       {11, 10},
+		"bigint":    {20, 20},
+	}
+	if unsigned {
+		return widths[base][1]
+	}
+	return widths[base][0]
+}
+
+func isTextualType(base string) bool {
+	switch base {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return true
+	}
+	return false
+}
+
+func isNumericType(base string) bool {
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "bigint", "decimal", "float", "double":
+		return true
+	}
+	return false
+}
+
+func isTimeType(base string) bool {
+	return base == "datetime" || base == "timestamp" || base == "time"
+}
+
+// defaultValueTokens consumes the tokens of a DEFAULT clause's value.
+func (p *createTableParser) defaultValueTokens() ([]createTableToken, error) {
+	start := p.n
+	if p.peekSymbol("(") {
+		if _, err := p.parenthesized(); err != nil {
+			return nil, err
+		}
+	} else {
+		for p.acceptSymbol("-") || p.acceptSymbol("+") {
+		}
+		if p.done() {
+			return nil, p.unexpected("expected default value")
+		}
+		t := p.next()
+		if t.typ == TokenWord && p.peekSymbol("(") { // function call such as NOW()
+			if _, err := p.parenthesized(); err != nil {
+				return nil, err
+			}
+		} else if t.typ == TokenWord && (strings.EqualFold(t.val, "b") || strings.EqualFold(t.val, "x")) && p.peek().typ == TokenString {
+			p.n++ // bit-value or hex literal; lexer splits the prefix from the string
+		} else if t.typ == TokenWord && strings.HasPrefix(t.val, "_") && p.peek().typ == TokenString {
+			p.n++ // string with charset introducer
+		}
+	}
+	if p.flavor.IsMariaDB() {
+		if err := p.extendDefaultExpression(); err != nil {
+			return nil, err
+		}
+	}
+	return p.tokens[start:p.n], nil
+}
+
+// columnAttributeWords are keywords which may follow a column's DEFAULT
+// clause, and therefore end an unparenthesized DEFAULT expression.
+var columnAttributeWords = []string{
+	"NOT", "NULL", "DEFAULT", "ON", "AUTO_INCREMENT", "PRIMARY", "KEY", "UNIQUE",
+	"COMMENT", "CHARACTER", "CHARSET", "COLLATE", "GENERATED", "AS", "VIRTUAL",
+	"STORED", "PERSISTENT", "INVISIBLE", "VISIBLE", "SRID", "COLUMN_FORMAT",
+	"STORAGE", "CONSTRAINT", "CHECK", "REFERENCES",
+}
+
+// extendDefaultExpression consumes the remaining tokens of an unparenthesized
+// DEFAULT expression, such as "a * 2" or "rand()", which MariaDB permits and
+// also uses in SHOW CREATE TABLE. The expression ends at the next column
+// attribute keyword, or at the end of the column definition.
+func (p *createTableParser) extendDefaultExpression() error {
+	for !p.done() && !p.peekSymbol(",") && !p.peekSymbol(")") {
+		if t := p.peek(); t.typ == TokenWord && slices.ContainsFunc(columnAttributeWords, func(word string) bool { return strings.EqualFold(t.val, word) }) {
+			// Permit "IS NULL" and "IS NOT NULL" within the expression
+			afterIs := isKeyword(p.tokens[p.n-1], "IS") || (p.n > 1 && isKeyword(p.tokens[p.n-2], "IS") && isKeyword(p.tokens[p.n-1], "NOT"))
+			if !afterIs || (!isKeyword(t, "NOT") && !isKeyword(t, "NULL")) {
+				break
+			}
+		}
+		if p.peekSymbol("(") {
+			if _, err := p.parenthesized(); err != nil {
+				return err
+			}
+		} else {
+			p.n++
+		}
+	}
+	return nil
+}
+
+// isKeyword returns true if t is the supplied keyword, compared
+// case-insensitively.
+func isKeyword(t createTableToken, word string) bool {
+	return t.typ == TokenWord && strings.EqualFold(t.val, word)
+}
+
+// currentTimestamp returns the flavor's canonical form of a CURRENT_TIMESTAMP
+// expression beginning with t, consuming any parenthesized precision. If t is
+// not such an expression, a blank string is returned.
+func (p *createTableParser) currentTimestamp(t createTableToken) string {
+	switch strings.ToLower(t.val) {
+	case "current_timestamp", "now", "localtime", "localtimestamp":
+	default:
+		return ""
+	}
+	var precision string
+	if p.acceptSymbol("(") {
+		if p.peek().typ == TokenNumeric {
+			if precision = p.next().val; precision == "0" {
+				precision = ""
+			}
+		}
+		if !p.acceptSymbol(")") {
+			return ""
+		}
+	}
+	if p.flavor.IsMariaDB() {
+		return "current_timestamp(" + precision + ")"
+	} else if precision != "" {
+		return "CURRENT_TIMESTAMP(" + precision + ")"
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+// normalizeDefault converts the tokens of a DEFAULT clause into the form
+// displayed by SHOW CREATE TABLE for col. If no DEFAULT clause was present,
+// tokens will be empty, and an implicit default is returned if appropriate.
+func (p *createTableParser) normalizeDefault(tokens []createTableToken, col *Column) (string, error) {
+	if len(tokens) == 0 {
+		if !col.Nullable || col.AutoIncrement || col.GenerationExpr != "" {
+			return "", nil
+		}
+		switch col.Type.Base {
+		case "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "json",
+			"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+			if !p.flavor.IsMariaDB() {
+				return "", nil
+			}
+		}
+		return "NULL", nil
+	}
+	first := tokens[0]
+	raw := strings.TrimSpace(p.body[first.pos : tokens[len(tokens)-1].pos+len(tokens[len(tokens)-1].val)])
+	var depth int
+	for _, t := range tokens {
+		if t.typ == TokenSymbol && t.val == "(" {
+			depth++
+		} else if t.typ == TokenSymbol && t.val == ")" {
+			depth--
+		} else if t.typ == TokenSymbol && t.val == "," && depth == 0 {
+			return "", fmt.Errorf("invalid default value %s for column %s", raw, EscapeIdentifier(col.Name))
+		}
+	}
+	if first.typ == TokenSymbol && first.val == "(" {
+		onlyParens := !slices.ContainsFunc(tokens, func(t createTableToken) bool {
+			return t.typ != TokenSymbol || (t.val != "(" && t.val != ")")
+		})
+		if onlyParens {
+			return "", fmt.Errorf("empty default expression for column %s", EscapeIdentifier(col.Name))
+		} else if p.flavor.IsMariaDB() && tokens[len(tokens)-1].pos == matchingParen(tokens).pos {
+			// MariaDB displays expressions without the outer parens, so normalize the
+			// contents as if they were unparenthesized, as long as they would be
+			// parsed identically that way
+			inner := tokens[1 : len(tokens)-1]
+			sub := &createTableParser{body: p.body, tokens: inner, flavor: p.flavor}
+			if _, err := sub.defaultValueTokens(); err != nil || !sub.done() {
+				return "", fmt.Errorf("unsupported default value %s for column %s", raw, EscapeIdentifier(col.Name))
+			}
+			return p.normalizeDefault(inner, col)
+		}
+		return raw, nil
+	} else if first.typ == TokenWord && strings.EqualFold(first.val, "NULL") {
+		return "NULL", nil
+	} else if first.typ == TokenWord {
+		sub := &createTableParser{body: p.body, tokens: tokens[1:], flavor: p.flavor}
+		if ts := sub.currentTimestamp(first); ts != "" && sub.done() {
+			return ts, nil
+		}
+	}
+
+	// Any other multi-token value, besides a signed number or a string with a
+	// prefix, is an unparenthesized expression, which only MariaDB permits
+	literal := len(tokens) == 1 || (len(tokens) == 2 && tokens[1].typ == TokenString && first.typ == TokenWord)
+	if !literal && tokens[len(tokens)-1].typ == TokenNumeric {
+		literal = !slices.ContainsFunc(tokens[:len(tokens)-1], func(t createTableToken) bool {
+			return t.typ != TokenSymbol || (t.val != "-" && t.val != "+")
+		})
+	}
+	if !literal || (len(tokens) == 1 && first.typ == TokenWord && !isKeyword(first, "TRUE") && !isKeyword(first, "FALSE")) {
+		if p.flavor.IsMariaDB() {
+			return raw, nil
+		}
+		return "", fmt.Errorf("unsupported default value %s for column %s", raw, EscapeIdentifier(col.Name))
+	}
+
+	var value string
+	var numeric bool
+	switch {
+	case first.typ == TokenString:
+		value = stripAnyQuote(first.val)
+	case first.typ == TokenWord && len(tokens) == 2 && tokens[1].typ == TokenString:
+		if prefix := strings.ToLower(first.val); prefix == "b" || prefix == "x" {
+			return prefix + tokens[1].val, nil
+		}
+		value = stripAnyQuote(tokens[1].val) // ignore charset introducer
+	case first.typ == TokenWord && (strings.EqualFold(first.val, "TRUE") || strings.EqualFold(first.val, "FALSE")):
+		value, numeric = "0", true
+		if strings.EqualFold(first.val, "TRUE") {
+			value = "1"
+		}
+	case tokens[len(tokens)-1].typ == TokenNumeric:
+		value, numeric = strings.TrimPrefix(strings.ReplaceAll(raw, " ", ""), "+"), true
+		if col.Type.Base == "decimal" {
+			value = formatDecimalDefault(value, col.Type.Scale)
+		}
+	default:
+		return "", fmt.Errorf("unsupported default value %s for column %s", raw, EscapeIdentifier(col.Name))
+	}
+	if numeric && p.flavor.IsMariaDB() && !isTextualType(col.Type.Base) {
+		return value, nil
+	}
+	return "'" + EscapeValueForCreateTable(value) + "'", nil
+}
+
+// matchingParen returns the token closing the parenthesized group opened by
+// tokens[0].
+func matchingParen(tokens []createTableToken) createTableToken {
+	var depth int
+	for _, t := range tokens {
+		if t.typ == TokenSymbol && t.val == "(" {
+			depth++
+		} else if t.typ == TokenSymbol && t.val == ")" {
+			if depth--; depth == 0 {
+				return t
+			}
+		}
+	}
+	return createTableToken{pos: -1}
+}
+
+// formatDecimalDefault formats a numeric literal with the supplied number of
+// digits after the decimal point, as the server does for decimal columns.
+func formatDecimalDefault(value string, scale uint8) string {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(f, 'f', int(scale), 64)
+}
+
+// normalizeCharSetCollation fills in whichever of charSet or collation is
+// missing, if the other one is present. It also converts the deprecated
+// utf8 alias to utf8mb3, in flavors which display it that way.
+func normalizeCharSetCollation(charSet, collation string, flavor Flavor) (string, string) {
+	if flavor.MinMySQL(8, 0, 30) || flavor.MinMariaDB(10, 6) {
+		if charSet == "utf8" {
+			charSet = "utf8mb3"
+		}
+		if rest, ok := strings.CutPrefix(collation, "utf8_"); ok {
+			collation = "utf8mb3_" + rest
+		}
+	}
+	if collation != "" && charSet == "" {
+		charSet, _, _ = strings.Cut(collation, "_")
+	} else if charSet != "" && collation == "" {
+		collation = characterSetsForFlavor(flavor)[charSet].DefaultCollation
+	}
+	return charSet, collation
+}
+
+// collationMatchesCharSet returns true if collation belongs to charSet, based
+// on the naming convention of collations.
+func collationMatchesCharSet(collation, charSet string) bool {
+	return (charSet != "" && strings.HasPrefix(collation, charSet+"_")) || (charSet == "binary" && collation == "binary")
+}
+
+// parseTableOptions handles table options following the definition list.
+func (p *createTableParser) parseTableOptions() (err error) {
+	t := p.table
+	var createOptions []string
+	for !p.done() && !p.peekSymbol(";") {
+		switch {
+		case p.acceptSymbol(","):
+		case p.acceptWord("ENGINE"):
+			p.acceptEquals()
+			if t.Engine, err = p.word(); err != nil {
+				return err
+			}
+			t.Engine = normalizeEngineName(t.Engine)
+		case p.acceptWord("AUTO_INCREMENT"):
+			p.acceptEquals()
+			v := p.next()
+			if t.NextAutoIncrement, err = strconv.ParseUint(v.val, 10, 64); err != nil {
+				return fmt.Errorf("invalid AUTO_INCREMENT value %q", v.val)
+			}
+		case p.acceptWord("DEFAULT", "CHARACTER", "SET"), p.acceptWord("CHARACTER", "SET"), p.acceptWord("DEFAULT", "CHARSET"), p.acceptWord("CHARSET"):
+			p.acceptEquals()
+			if t.CharSet, err = p.word(); err != nil {
+				return err
+			}
+			t.CharSet = strings.ToLower(t.CharSet)
+		case p.acceptWord("DEFAULT", "COLLATE"), p.acceptWord("COLLATE"):
+			p.acceptEquals()
+			if t.Collation, err = p.word(); err != nil {
+				return err
+			}
+			t.Collation = strings.ToLower(t.Collation)
+		case p.acceptWord("COMMENT"):
+			p.acceptEquals()
+			if t.Comment, err = p.stringLiteral(); err != nil {
+				return err
+			}
+		case p.acceptWord("TABLESPACE"):
+			if t.Tablespace, err = p.word(); err != nil {
+				return err
+			}
+		case p.peekWord("PARTITION"):
+			return p.unsupported("partitioning")
+		case p.peekWord("AS"), p.peekWord("SELECT"), p.peekWord("IGNORE"), p.peekWord("REPLACE"):
+			return p.unsupported("CREATE TABLE ... SELECT")
+		case p.peek().typ == TokenWord:
+			name := strings.ToUpper(p.next().val)
+			if !slices.Contains(createOptionNames, name) {
+				p.n--
+				return p.unexpected("expected table option")
+			}
+			p.acceptEquals()
+			v := p.next()
+			value := v.val
+			if v.typ == TokenWord {
+				value = strings.ToUpper(value)
+			}
+			createOptions = append(createOptions, name+"="+value)
+		default:
+			return p.unexpected("expected table option")
+		}
+	}
+	t.CreateOptions = strings.Join(createOptions, " ")
+	return nil
+}
+
+// createOptionNames lists table options which are displayed in SHOW CREATE
+// TABLE as-is, after the table's default charset and collation.
+var createOptionNames = []string{
+	"AVG_ROW_LENGTH", "CHECKSUM", "COMPRESSION", "DELAY_KEY_WRITE", "ENCRYPTED",
+	"ENCRYPTION", "ENCRYPTION_KEY_ID", "KEY_BLOCK_SIZE", "MAX_ROWS", "MIN_ROWS",
+	"PACK_KEYS", "PAGE_CHECKSUM", "PAGE_COMPRESSED", "PAGE_COMPRESSION_LEVEL",
+	"ROW_FORMAT", "STATS_AUTO_RECALC", "STATS_PERSISTENT", "STATS_SAMPLE_PAGES",
+	"TRANSACTIONAL",
+}
+
+func normalizeEngineName(engine string) string {
+	switch strings.ToLower(engine) {
+	case "innodb":
+		return "InnoDB"
+	case "myisam":
+		return "MyISAM"
+	case "memory", "heap":
+		return "MEMORY"
+	case "csv", "archive", "blackhole", "federated":
+		return strings.ToUpper(engine)
+	case "aria":
+		return "Aria"
+	case "rocksdb":
+		return "ROCKSDB"
+	}
+	return engine
+}
+
+// finish fills in defaults and derived fields once the entire statement has
+// been parsed, and validates references between columns and indexes.
+func (p *createTableParser) finish(defaultCharSet, defaultCollation string) error {
+	t, flavor := p.table, p.flavor
+	if t.Engine == "" {
+		t.Engine = "InnoDB"
+	}
+
+	// Table default charset and collation
+	if t.CharSet == "" && t.Collation == "" {
+		t.CharSet, t.Collation = defaultCharSet, defaultCollation
+	}
+	if t.CharSet == "" && t.Collation == "" {
+		if flavor.MinMySQL(8) {
+			t.CharSet = "utf8mb4"
+		} else {
+			t.CharSet = "latin1"
+		}
+	}
+	t.CharSet, t.Collation = normalizeCharSetCollation(t.CharSet, t.Collation, flavor)
+	if t.Collation == "" {
+		return fmt.Errorf("unknown character set %s", t.CharSet)
+	} else if !collationMatchesCharSet(t.Collation, t.CharSet) {
+		return fmt.Errorf("collation %s is not valid for character set %s", t.Collation, t.CharSet)
+	}
+	t.ShowCollation = flavor.AlwaysShowCollate() || !collationIsDefault(t.Collation, t.CharSet, flavor) || (t.CharSet == "utf8mb4" && flavor.MinMySQL(8))
+
+	colsByName := make(map[string]*Column, len(t.Columns))
+	for _, col := range t.Columns {
+		colsByName[strings.ToLower(col.Name)] = col
+		if !isTextualType(col.Type.Base) {
+			continue
+		}
+		if col.CharSet == "" {
+			col.CharSet = t.CharSet
+		}
+		if col.Collation == binaryCollationPlaceholder {
+			col.Collation = col.CharSet + "_bin"
+		} else if col.Collation == "" {
+			if col.CharSet == t.CharSet {
+				col.Collation = t.Collation
+			} else {
+				_, col.Collation = normalizeCharSetCollation(col.CharSet, "", flavor)
+			}
+		}
+		if col.Collation == "" {
+			return fmt.Errorf("unknown character set %s for column %s", col.CharSet, EscapeIdentifier(col.Name))
+		} else if !collationMatchesCharSet(col.Collation, col.CharSet) {
+			return fmt.Errorf("collation %s is not valid for character set %s of column %s", col.Collation, col.CharSet, EscapeIdentifier(col.Name))
+		}
+		col.ShowCharSet = (col.Collation != t.Collation)
+		if flavor.AlwaysShowCollate() {
+			col.ShowCollation = col.ShowCharSet
+		} else {
+			col.ShowCollation = !collationIsDefault(col.Collation, col.CharSet, flavor) || (col.ShowCharSet && flavor.MinMySQL(8))
+		}
+	}
+
+	// Verify index parts refer to real columns, and use the column names'
+	// original capitalization
+	checkParts := func(idx *Index) error {
+		for n, part := range idx.Parts {
+			if part.ColumnName == "" {
+				continue
+			}
+			col := colsByName[strings.ToLower(part.ColumnName)]
+			if col == nil {
+				return fmt.Errorf("key column %s doesn't exist in table", EscapeIdentifier(part.ColumnName))
+			}
+			idx.Parts[n].ColumnName = col.Name
+		}
+		return nil
+	}
+	if t.PrimaryKey != nil {
+		if err := checkParts(t.PrimaryKey); err != nil {
+			return err
+		}
+		for _, part := range t.PrimaryKey.Parts {
+			if col := colsByName[strings.ToLower(part.ColumnName)]; col != nil {
+				col.Nullable = false
+				if col.Default == "NULL" {
+					col.Default = ""
+				}
+			}
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		for n, colName := range fk.ColumnNames {
+			col := colsByName[strings.ToLower(colName)]
+			if col == nil {
+				return fmt.Errorf("foreign key column %s doesn't exist in table", EscapeIdentifier(colName))
+			}
+			fk.ColumnNames[n] = col.Name
+		}
+	}
+
+	// Remove placeholder foreign key indexes that are unnecessary, due to another
+	// index already covering the foreign key's columns
+	indexes := make([]*Index, 0, len(t.SecondaryIndexes))
+	for _, idx := range t.SecondaryIndexes {
+		if err := checkParts(idx); err != nil {
+			return err
+		}
+		if idx.Attributes == fkIndexPlaceholder {
+			idx.Attributes = ""
+			if indexCoversColumns(t.PrimaryKey, idx.Parts) || slices.ContainsFunc(t.SecondaryIndexes, func(other *Index) bool {
+				return other != idx && other.Attributes != fkIndexPlaceholder && indexCoversColumns(other, idx.Parts)
+			}) {
+				continue
+			}
+		}
+		indexes = append(indexes, idx)
+	}
+
+	// Assign names to unnamed secondary indexes, and check for duplicates
+	names := make(map[string]bool, len(indexes))
+	for _, idx := range indexes {
+		if idx.Name == "" {
+			continue
+		} else if lower := strings.ToLower(idx.Name); names[lower] {
+			return fmt.Errorf("duplicate key name %s", EscapeIdentifier(idx.Name))
+		} else {
+			names[lower] = true
+		}
+	}
+	for _, idx := range indexes {
+		if idx.Name != "" {
+			continue
+		}
+		base := "functional_index"
+		if idx.Parts[0].ColumnName != "" {
+			base = idx.Parts[0].ColumnName
+		}
+		idx.Name = base
+		for n := 2; names[strings.ToLower(idx.Name)]; n++ {
+			idx.Name = base + "_" + strconv.Itoa(n)
+		}
+		names[strings.ToLower(idx.Name)] = true
+	}
+
+	// SHOW CREATE TABLE lists unique indexes first, and fulltext indexes last
+	slices.SortStableFunc(indexes, func(a, b *Index) int {
+		return indexSortRank(a) - indexSortRank(b)
+	})
+	t.SecondaryIndexes = indexes
+	if len(t.SecondaryIndexes) == 0 {
+		t.SecondaryIndexes = nil
+	}
+	if flavor.SortedForeignKeys() {
+		slices.SortStableFunc(t.ForeignKeys, func(a, b *ForeignKey) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	return nil
+}
+
+// indexCoversColumns returns true if idx is non-nil and its leading parts are
+// the supplied parts' columns, without prefix lengths.
+func indexCoversColumns(idx *Index, parts []IndexPart) bool {
+	if idx == nil || len(idx.Parts) < len(parts) {
+		return false
+	}
+	for n := range parts {
+		if idx.Parts[n].PrefixLength > 0 || !strings.EqualFold(idx.Parts[n].ColumnName, parts[n].ColumnName) {
+			return false
+		}
+	}
+	return true
+}
+
+func indexSortRank(idx *Index) int {
+	if idx.Unique {
+		return 0
+	} else if idx.Type == "FULLTEXT" {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,169 @@
+package tengo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCreateTableRoundTrip(t *testing.T) {
+	for _, flavorStr := range []string{"mysql:5.7", "mysql:8.0.35", "mariadb:10.6", "mariadb:11.4"} {
+		flavor := ParseFlavor(flavorStr)
+		fixtures := []Table{
+			aTableForFlavor(flavor, 1),
+			aTableForFlavor(flavor, 123),
+			anotherTableForFlavor(flavor),
+			supportedTableForFlavor(flavor),
+		}
+		for _, expected := range fixtures {
+			actual, err := ParseCreateTable(expected.CreateStatement, flavor, "", "")
+			if err != nil {
+				t.Errorf("Flavor %s: Unexpected error parsing table %s: %v", flavor, expected.Name, err)
+			} else if actual.CreateStatement != expected.CreateStatement {
+				t.Errorf("Flavor %s: Parsed CREATE TABLE does not match original\nExpected:\n%s\nFound:\n%s", flavor, expected.CreateStatement, actual.CreateStatement)
+			} else if clauses, supported := expected.Diff(actual); len(clauses) > 0 || !supported {
+				t.Errorf("Flavor %s: Parsed table %s unexpectedly differs from original: %+v", flavor, expected.Name, clauses)
+			}
+		}
+	}
+
+	// Fixture with foreign keys is only usable with FlavorUnknown
+	expected := foreignKeyTable()
+	if actual, err := ParseCreateTable(expected.CreateStatement, FlavorUnknown, "", ""); err != nil {
+		t.Errorf("Unexpected error parsing table %s: %v", expected.Name, err)
+	} else if actual.CreateStatement != expected.CreateStatement {
+		t.Errorf("Parsed CREATE TABLE does not match original\nExpected:\n%s\nFound:\n%s", expected.CreateStatement, actual.CreateStatement)
+	}
+}
+
+func TestParseCreateTableNormalization(t *testing.T) {
+	input := strings.ReplaceAll(`create table if not exists ~Orders~ (
+		id integer unsigned not null auto_increment primary key,
+		customer_id int unsigned,
+		status enum('new', 'shipped') not null default 'new',
+		total decimal(8,2) default 0,
+		is_gift boolean not null default false,
+		note varchar(200) character set latin1,
+		created_at datetime default now(),
+		/*!80023 hidden int invisible, */
+		key (customer_id),
+		key (customer_id, created_at),
+		unique (note(10)),
+		foreign key (customer_id) references customers (id) on delete cascade
+	) engine=innodb row_format=dynamic comment 'all orders'`, "~", "`")
+
+	expected := strings.ReplaceAll(`CREATE TABLE ~Orders~ (
+  ~id~ int unsigned NOT NULL AUTO_INCREMENT,
+  ~customer_id~ int unsigned DEFAULT NULL,
+  ~status~ enum('new','shipped') NOT NULL DEFAULT 'new',
+  ~total~ decimal(8,2) DEFAULT '0.00',
+  ~is_gift~ tinyint(1) NOT NULL DEFAULT '0',
+  ~note~ varchar(200) CHARACTER SET latin1 COLLATE latin1_swedish_ci DEFAULT NULL,
+  ~created_at~ datetime DEFAULT CURRENT_TIMESTAMP,
+  ~hidden~ int DEFAULT NULL /*!80023 INVISIBLE */,
+  PRIMARY KEY (~id~),
+  UNIQUE KEY ~note~ (~note~(10)),
+  KEY ~customer_id~ (~customer_id~),
+  KEY ~customer_id_2~ (~customer_id~,~created_at~),
+  CONSTRAINT ~Orders_ibfk_1~ FOREIGN KEY (~customer_id~) REFERENCES ~customers~ (~id~) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci ROW_FORMAT=DYNAMIC COMMENT='all orders'`, "~", "`")
+
+	table, err := ParseCreateTable(input, ParseFlavor("mysql:8.0.35"), "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+	}
+	if table.CreateStatement != expected {
+		t.Errorf("Unexpected result from ParseCreateTable\nExpected:\n%s\nFound:\n%s", expected, table.CreateStatement)
+	}
+
+	// Same statement with schema-level defaults, in MariaDB
+	table, err = ParseCreateTable(input, ParseFlavor("mariadb:10.11"), "utf8mb4", "utf8mb4_unicode_ci")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+	}
+	if table.Collation != "utf8mb4_unicode_ci" || !table.ShowCollation {
+		t.Errorf("Expected table collation to come from supplied defaults, instead found %s", table.Collation)
+	}
+	if col := table.Columns[4]; col.Default != "0" {
+		t.Errorf("Expected MariaDB to leave numeric default unquoted, instead found %s", col.Default)
+	}
+	if col := table.Columns[6]; col.Default != "current_timestamp()" {
+		t.Errorf("Expected MariaDB to use lowercase current_timestamp(), instead found %s", col.Default)
+	}
+	if len(table.ForeignKeys) != 1 || table.ForeignKeys[0].UpdateRule != "RESTRICT" {
+		t.Errorf("Unexpected foreign keys: %+v", table.ForeignKeys)
+	}
+}
+
+func TestParseCreateTableForeignKeyIndex(t *testing.T) {
+	input := "CREATE TABLE t (a int, b int, CONSTRAINT a_fk FOREIGN KEY (a) REFERENCES p (id), FOREIGN KEY (b) REFERENCES p (id), KEY b_idx (b))"
+	table, err := ParseCreateTable(input, ParseFlavor("mysql:8.0.35"), "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+	}
+	// a_fk requires an implicit index; t_ibfk_1 is covered by b_idx
+	if len(table.SecondaryIndexes) != 2 || table.SecondaryIndexes[0].Name != "a_fk" || table.SecondaryIndexes[1].Name != "b_idx" {
+		t.Errorf("Unexpected secondary indexes: %+v", table.SecondaryIndexes)
+	}
+}
+
+func TestParseCreateTableMariaDBDefaultExpressions(t *testing.T) {
+	input := "CREATE TABLE t (a int, b int DEFAULT a * 2 NOT NULL, c varchar(10) DEFAULT (concat('x', 'y')), d int DEFAULT ((5)), e int DEFAULT a IS NOT NULL COMMENT 'hi')"
+	flavor := ParseFlavor("mariadb:10.11")
+	table, err := ParseCreateTable(input, flavor, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseCreateTable: %v", err)
+	}
+	expected := map[string]string{"b": "a * 2", "c": "concat('x', 'y')", "d": "5", "e": "a IS NOT NULL"}
+	for _, col := range table.Columns[1:] {
+		if col.Default != expected[col.Name] {
+			t.Errorf("Expected column %s to have default %q, instead found %q", col.Name, expected[col.Name], col.Default)
+		}
+	}
+	if cols := table.ColumnsByName(); cols["b"].Nullable || cols["e"].Comment != "hi" {
+		t.Error("Column attributes following an unparenthesized default expression were not parsed as expected")
+	}
+
+	// SHOW CREATE TABLE in MariaDB displays expressions without parens, so the
+	// generated statement must be parseable as well
+	reparsed, err := ParseCreateTable(table.CreateStatement, flavor, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error re-parsing generated CREATE TABLE: %v", err)
+	} else if reparsed.CreateStatement != table.CreateStatement {
+		t.Errorf("Re-parsed CREATE TABLE does not match\nExpected:\n%s\nFound:\n%s", table.CreateStatement, reparsed.CreateStatement)
+	}
+}
+
+func TestParseCreateTableErrors(t *testing.T) {
+	cases := map[string]string{
+		"CREATE TABLE t (a int) PARTITION BY HASH (a)":                  "partitioning",
+		"CREATE TABLE t LIKE other":                                     "LIKE",
+		"CREATE TABLE t (a int) AS SELECT 1":                            "SELECT",
+		"CREATE TABLE t (a int REFERENCES other (id))":                  "REFERENCES",
+		"CREATE TABLE t (a int, a int)":                                 "duplicate column",
+		"CREATE TABLE t (a int, KEY (b))":                               "doesn't exist",
+		"CREATE TABLE t (a int, KEY k (a), KEY k (a))":                  "duplicate key",
+		"CREATE TABLE t (a varchar)":                                    "requires a length",
+		"CREATE TABLE t (a int potato)":                                 "potato",
+		"CREATE TABLE t (a int,)":                                       "identifier",
+		"CREATE TABLE t (a int) ENGINE=InnoDB FOO=1":                    "FOO",
+		"CREATE TABLE t (a int, FOREIGN KEY (a) REFERENCES p (x, y))":   "mismatched",
+		"CREATE TABLE `` (a int)":                                       "non-empty identifier",
+		"CREATE TABLE t (a int DEFAULT (()))":                           "empty default",
+		"CREATE TABLE t (a int) CHARSET=potato":                         "unknown character set",
+		"CREATE TABLE t (a char(1) CHARSET latin1 COLLATE utf8mb4_bin)": "not valid for character set",
+	}
+	for input, expected := range cases {
+		if _, err := ParseCreateTable(input, ParseFlavor("mysql:8.0.35"), "", ""); err == nil {
+			t.Errorf("Expected error parsing %q, but err was nil", input)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error parsing %q to contain %q, instead found %v", input, expected, err)
+		}
+	}
+	// MariaDB permits unparenthesized default expressions, but MySQL does not
+	if _, err := ParseCreateTable("CREATE TABLE t (a int DEFAULT 1 + 1)", ParseFlavor("mysql:8.0.35"), "", ""); err == nil {
+		t.Error("Expected error parsing unparenthesized default expression with MySQL flavor, but err was nil")
+	}
+	if _, err := ParseCreateTable(unsupportedTable().CreateStatement, FlavorUnknown, "", ""); err == nil || !strings.Contains(err.Error(), "partitioning") {
+		t.Errorf("Expected partitioning error for unsupportedTable, instead found %v", err)
+	}
+}
@@ -192,6 +192,9 @@ func TestTableAlterAddOrDropColumn(t *testing.T) {
 	if ta.PositionFirst || ta.PositionAfter != to.Columns[colCount-3] || !strings.Contains(ta.Clause(StatementModifiers{}), " AFTER ") {
 		t.Errorf("Expected new column to be after `%s` / first=false, instead found after `%s` / first=%t", to.Columns[colCount-3].Name, ta.PositionAfter.Name, ta.PositionFirst)
 	}
+	if clause := ta.Clause(StatementModifiers{OmitColumnPosition: true}); clause != "ADD COLUMN "+newCol.Definition(FlavorUnknown) {
+		t.Errorf("Expected Clause to omit position with OmitColumnPosition enabled, instead found: %s", clause)
+	}
 
 	// Reverse comparison should yield a drop-column
 	tableAlters, supported = to.Diff(&from)
@@ -867,6 +870,10 @@ func TestTableAlterModifyColumn(t *testing.T) {
 	if clauseWithMods := ta.Clause(laxColOrderMods); clauseWithMods != "" {
 		t.Errorf("Expected Clause to return a blank string with LaxColumnOrder enabled, instead found: %s", clauseWithMods)
 	}
+	omitPositionMods := StatementModifiers{OmitColumnPosition: true, StrictColumnDefinition: true}
+	if clauseWithMods := ta.Clause(omitPositionMods); clauseWithMods != "" {
+		t.Errorf("Expected Clause to return a blank string with OmitColumnPosition enabled, instead found: %s", clauseWithMods)
+	}
 
 	// Reposition same col to last position
 	to = aTable(1)
@@ -918,6 +925,9 @@ func TestTableAlterModifyColumn(t *testing.T) {
 	if ta.Clause(laxColOrderMods) == "" {
 		t.Error("Since non-positioning changes are present, expected Clause to return a non-blank string even with LaxColumnOrder enabled, but it was blank")
 	}
+	if clause := ta.Clause(omitPositionMods); clause != "MODIFY COLUMN "+movedCol.Definition(FlavorUnknown) {
+		t.Errorf("Expected Clause to omit position with OmitColumnPosition enabled, instead found: %s", clause)
+	}
 	if unsafe, reason := ta.Unsafe(laxColOrderMods); !unsafe || !strings.Contains(reason, movedCol.Name) {
 		t.Errorf("Unexpected return from Unsafe(): %t, %q", unsafe, reason)
 	}
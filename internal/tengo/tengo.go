@@ -64,25 +64,29 @@ type DefKeyer interface {
 }
 
 // ObjectPattern is a regular expression matched against an object name, but
-// only for a specific object type.
+// only for a specific object type. If Negate is true, the pattern instead
+// matches names of that type which the regular expression does NOT match.
 type ObjectPattern struct {
 	Type    ObjectType
 	Pattern *regexp.Regexp
+	Negate  bool
 }
 
 // Match returns true if p's Type equals obj's ObjectKey.Type and p's Pattern
-// matches obj's ObjectKey.Name.
+// matches obj's ObjectKey.Name (or does not match, if p.Negate is true).
 func (p *ObjectPattern) Match(obj ObjectKeyer) bool {
 	if p == nil || p.Pattern == nil {
 		return false
 	}
 	key := obj.ObjectKey()
-	return p.Type == key.Type && key.Name != "" && p.Pattern.MatchString(key.Name)
+	return p.Type == key.Type && key.Name != "" && p.Pattern.MatchString(key.Name) != p.Negate
 }
 
 func (p *ObjectPattern) String() string {
 	if p == nil {
 		return ""
+	} else if p.Negate {
+		return fmt.Sprintf("%s !%s", p.Type, p.Pattern)
 	}
 	return fmt.Sprintf("%s %s", p.Type, p.Pattern)
 }
@@ -38,6 +38,10 @@ func (s *TengoIntegrationSuite) Teardown(backend string) error {
 	return SkeemaTestContainerCleanup(s.d)
 }
 
+func (s *TengoIntegrationSuite) BackendFlavor(backend string) Flavor {
+	return s.d.Flavor()
+}
+
 func (s *TengoIntegrationSuite) BeforeTest(backend string) error {
 	if err := s.d.NukeData(); err != nil {
 		return err
@@ -0,0 +1,13 @@
+CREATE TABLE `actor` (
+  `actor_id` smallint(5) unsigned NOT NULL AUTO_INCREMENT,
+  `first_name` varchar(45) NOT NULL,
+  `last_name` varchar(45) DEFAULT NULL,
+  `last_update` timestamp(2) NOT NULL DEFAULT CURRENT_TIMESTAMP(2) ON UPDATE CURRENT_TIMESTAMP(2),
+  `ssn` char(10) NOT NULL,
+  `alive` tinyint(1) unsigned NOT NULL DEFAULT '1',
+  `alive_bit` bit(1) NOT NULL DEFAULT b'1',
+  PRIMARY KEY (`actor_id`),
+  UNIQUE KEY `idx_ssn` (`ssn`),
+  KEY `idx_actor_name` (`last_name`(10),`first_name`(1))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+ALTER TABLE `actor` MODIFY COLUMN `alive_bit` bit(1) NOT NULL DEFAULT b'1' COMMENT 'hello', DROP KEY `idx_actor_name`;
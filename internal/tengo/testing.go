@@ -2,12 +2,19 @@ package tengo
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path"
 	"reflect"
+	"regexp"
 	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
@@ -33,6 +40,17 @@ type IntegrationTestSuite interface {
 // followed by the test itself. Finally, suite.Teardown(backend) will be run.
 // Backends are just strings, and may contain docker image names or any other
 // string representation that the test suite understands.
+//
+// If the SKEEMA_TEST_PARALLEL env variable is set to a number greater than 1,
+// and multiple backends are supplied, the backends are run concurrently, up to
+// that many at a time. Each backend runs in a separate subprocess re-executing
+// the current test, so that suites may freely use process-wide state such as
+// the working directory, and each backend's output is buffered separately.
+// Within a backend, test methods are still run sequentially.
+//
+// Test methods may call SkipUnlessFlavor to declare which flavors they
+// require. The flavor of each backend is obtained from the suite if it
+// implements FlavorTestSuite, or is otherwise parsed from the backend string.
 func RunSuite(suite IntegrationTestSuite, t *testing.T, backends []string) {
 	var suiteName string
 	suiteType := reflect.TypeOf(suite)
@@ -43,14 +61,28 @@ func RunSuite(suite IntegrationTestSuite, t *testing.T, backends []string) {
 		suiteName = suiteType.Name()
 	}
 
+	// In a subprocess created by runSuiteSubprocesses, only run one backend
+	if backend := os.Getenv(suiteBackendEnv); backend != "" {
+		if !slices.Contains(backends, backend) {
+			t.Fatalf("RunSuite %s: backend %s not found in %v", suiteName, backend, backends)
+		}
+		backends = []string{backend}
+	}
+
 	if len(backends) == 0 {
 		t.Skipf("Skipping integration test suite %s: No backends supplied", suiteName)
 	}
 
+	if parallelism := suiteParallelism(); parallelism > 1 && len(backends) > 1 {
+		runSuiteSubprocesses(t, suiteName, backends, parallelism)
+		return
+	}
+
 	for _, backend := range backends {
 		if err := suite.Setup(backend); err != nil {
 			t.Fatalf("RunSuite %s: Setup(%s) failed: %s", suiteName, backend, err)
 		}
+		flavor := backendFlavor(suite, backend)
 
 		// Run test methods
 		for n := 0; n < suiteType.NumMethod(); n++ {
@@ -58,6 +90,8 @@ func RunSuite(suite IntegrationTestSuite, t *testing.T, backends []string) {
 			if strings.HasPrefix(method.Name, "Test") {
 				subtestName := fmt.Sprintf("%s.%s:%s", suiteName, method.Name, backend)
 				subtest := func(subt *testing.T) {
+					suiteFlavors.Store(subt.Name(), flavor)
+					defer suiteFlavors.Delete(subt.Name())
 					if err := suite.BeforeTest(backend); err != nil {
 						suite.Teardown(backend)
 						t.Fatalf("RunSuite %s: BeforeTest(%s) failed: %s", suiteName, backend, err)
@@ -110,6 +144,220 @@ func RunSuite(suite IntegrationTestSuite, t *testing.T, backends []string) {
 	}
 }
 
+// suiteBackendEnv is the env variable used to tell a subprocess created by
+// runSuiteSubprocesses which backend to run.
+const suiteBackendEnv = "SKEEMA_TEST_SUITE_BACKEND"
+
+// suiteParallelism returns the maximum number of backends to run concurrently,
+// based on the SKEEMA_TEST_PARALLEL env variable. Subprocesses created by
+// runSuiteSubprocesses always return 1.
+func suiteParallelism() int {
+	if os.Getenv(suiteBackendEnv) != "" {
+		return 1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SKEEMA_TEST_PARALLEL")))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// runSuiteSubprocesses runs each backend in a separate subprocess of the
+// current test binary, running only the current top-level test, with up to
+// parallelism subprocesses at once. Each backend is reported as a subtest of t,
+// which fails if the subprocess fails. A subprocess's output is buffered, and
+// only displayed if it fails or if verbose output is enabled; each line is
+// prefixed with the backend name, so that output remains attributable when
+// interleaved with other backends.
+//
+// Test methods within a single backend still run sequentially, since they share
+// one database server and BeforeTest resets its state between methods.
+func runSuiteSubprocesses(t *testing.T, suiteName string, backends []string, parallelism int) {
+	t.Helper()
+	testName, _, _ := strings.Cut(t.Name(), "/")
+	args := []string{"-test.run=^" + regexp.QuoteMeta(testName) + "$", "-test.count=1"}
+	if testing.Verbose() {
+		args = append(args, "-test.v")
+	}
+	if f := flag.Lookup("test.timeout"); f != nil {
+		args = append(args, "-test.timeout="+f.Value.String())
+	}
+
+	sem := make(chan struct{}, parallelism)
+	t.Run(suiteName, func(groupT *testing.T) {
+		for _, backend := range backends {
+			groupT.Run(backend, func(subt *testing.T) {
+				subt.Parallel()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				cmd := exec.Command(os.Args[0], args...)
+				cmd.Env = append(os.Environ(), suiteBackendEnv+"="+backend)
+				output, err := cmd.CombinedOutput()
+				if err != nil || testing.Verbose() {
+					subt.Logf("Output from %s on %s:\n%s", suiteName, backend, prefixLines(output, "["+backend+"] "))
+				}
+				if err != nil {
+					subt.Errorf("RunSuite %s: backend %s failed: %v", suiteName, backend, err)
+				}
+			})
+		}
+	})
+}
+
+// prefixLines returns a copy of output with prefix inserted at the start of
+// each line.
+func prefixLines(output []byte, prefix string) []byte {
+	if len(output) == 0 {
+		return output
+	}
+	lines := bytes.SplitAfter(output, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	result := make([]byte, 0, len(output)+len(lines)*len(prefix))
+	for _, line := range lines {
+		result = append(result, prefix...)
+		result = append(result, line...)
+	}
+	return result
+}
+
+// FlavorTestSuite is an optional interface for an IntegrationTestSuite. If a
+// suite implements it, RunSuite calls BackendFlavor after Setup to determine
+// the flavor used by SkipUnlessFlavor. This is useful when a backend string
+// does not fully describe its flavor, for example a Docker image tag of
+// "mysql:8" could be any 8.x release.
+type FlavorTestSuite interface {
+	BackendFlavor(backend string) Flavor
+}
+
+// suiteFlavors maps names of test method subtests currently being run by
+// RunSuite to the Flavor of their backend.
+var suiteFlavors sync.Map
+
+// backendFlavor returns the Flavor of backend, obtained from suite if it
+// implements FlavorTestSuite, or otherwise by parsing backend as a Docker
+// image name.
+func backendFlavor(suite IntegrationTestSuite, backend string) Flavor {
+	if fs, ok := suite.(FlavorTestSuite); ok {
+		return fs.BackendFlavor(backend)
+	}
+	base, tag, _ := strings.Cut(backend, ":")
+	base = strings.TrimSuffix(path.Base(base), "-server") // e.g. "mysql/mysql-server" or "percona/percona-server"
+	return ParseFlavor(base + ":" + tag)
+}
+
+// SkipUnlessFlavor skips the current test unless its backend's flavor matches
+// at least one of the supplied constraints. It may only be used by test
+// methods run by RunSuite, or subtests of them. Each constraint consists of a
+// vendor or variant name, optionally followed by a colon and a version
+// requirement:
+//
+//   - "mysql" matches any version of MySQL, including variants such as Percona
+//   - "mysql:8.0" matches any MySQL 8.0.x release
+//   - "mysql:8.0+" matches MySQL 8.0 or any later version
+//   - "mariadb:-10.6" matches MariaDB 10.6.x or any earlier version
+//   - "percona:5.7-8.0" matches Percona Server 5.7.x through 8.0.x
+//
+// An invalid constraint causes the test to fail.
+func SkipUnlessFlavor(t testing.TB, constraints ...string) {
+	t.Helper()
+	flavor, ok := testFlavor(t)
+	if !ok {
+		t.Fatalf("SkipUnlessFlavor: unable to determine backend flavor of test %s, which was not run by RunSuite", t.Name())
+	}
+	for _, constraint := range constraints {
+		matches, err := flavorMatchesConstraint(flavor, constraint)
+		if err != nil {
+			t.Fatalf("SkipUnlessFlavor: %s", err)
+		} else if matches {
+			return
+		}
+	}
+	t.Skipf("Test requires flavor %s; backend flavor is %s", strings.Join(constraints, " or "), flavor)
+}
+
+// testFlavor returns the Flavor of the backend running test t, which may be a
+// nested subtest of a test method run by RunSuite.
+func testFlavor(t testing.TB) (Flavor, bool) {
+	for name := t.Name(); name != ""; {
+		if flavor, ok := suiteFlavors.Load(name); ok {
+			return flavor.(Flavor), true
+		}
+		pos := strings.LastIndexByte(name, '/')
+		if pos < 0 {
+			break
+		}
+		name = name[:pos]
+	}
+	return FlavorUnknown, false
+}
+
+// flavorMatchesConstraint returns true if flavor satisfies constraint, using
+// the constraint syntax described in SkipUnlessFlavor. An error is returned if
+// constraint cannot be parsed.
+func flavorMatchesConstraint(flavor Flavor, constraint string) (bool, error) {
+	base, versions, hasVersions := strings.Cut(constraint, ":")
+	want := ParseFlavor(base)
+	if want.Vendor == VendorUnknown {
+		return false, fmt.Errorf("flavor constraint %q has unknown vendor or variant %q", constraint, base)
+	}
+	if flavor.Vendor != want.Vendor || !flavor.HasVariant(want.Variants) {
+		return false, nil
+	} else if !hasVersions {
+		return true, nil
+	}
+
+	minVersion, maxVersion, isRange := strings.Cut(versions, "-")
+	if !isRange {
+		if strings.HasSuffix(versions, "+") {
+			minVersion = strings.TrimSuffix(versions, "+")
+		} else {
+			maxVersion = minVersion
+		}
+	}
+	if minVersion == "" && maxVersion == "" {
+		return false, fmt.Errorf("flavor constraint %q has invalid version requirement", constraint)
+	}
+	if minVersion != "" {
+		lower, _, err := parseConstraintVersion(minVersion)
+		if err != nil {
+			return false, fmt.Errorf("flavor constraint %q has invalid version %q", constraint, minVersion)
+		} else if !flavor.Version.AtLeast(lower) {
+			return false, nil
+		}
+	}
+	if maxVersion != "" {
+		upper, parts, err := parseConstraintVersion(maxVersion)
+		if err != nil {
+			return false, fmt.Errorf("flavor constraint %q has invalid version %q", constraint, maxVersion)
+		}
+		upper[parts-1]++ // maximum is inclusive of all releases in the series
+		if !flavor.Version.Below(upper) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseConstraintVersion parses a version of 1 to 3 dot-separated numbers,
+// returning the Version along with how many parts were supplied. Unlike
+// ParseVersion, no non-digit characters are permitted.
+func parseConstraintVersion(s string) (ver Version, parts int, err error) {
+	strParts := strings.Split(s, ".")
+	if len(strParts) > len(ver) {
+		return ver, 0, fmt.Errorf("too many version components in %q", s)
+	}
+	for n, strPart := range strParts {
+		part, err := strconv.ParseUint(strPart, 10, 16)
+		if err != nil {
+			return ver, 0, err
+		}
+		ver[n] = uint16(part)
+	}
+	return ver, len(strParts), nil
+}
+
 // SkeemaTestImages examines the SKEEMA_TEST_IMAGES env variable (which
 // should be set to a comma-separated list of Docker images) and returns a slice
 // of strings. It may perform some conversions in the process, if the configured
@@ -117,7 +365,7 @@ func RunSuite(suite IntegrationTestSuite, t *testing.T, backends []string) {
 // configured, the test will be marked as skipped. If any configured images are
 // known to be unavailable for the system's architecture, the test is marked as
 // failed.
-func SkeemaTestImages(t *testing.T) []string {
+func SkeemaTestImages(t testing.TB) []string {
 	t.Helper()
 	envString := strings.TrimSpace(os.Getenv("SKEEMA_TEST_IMAGES"))
 	if envString == "" {
@@ -0,0 +1,129 @@
+package tengo
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// parallelTestSuite is a trivial IntegrationTestSuite which does not require
+// any database server, for testing RunSuite itself.
+type parallelTestSuite struct {
+	backend string
+}
+
+func (s *parallelTestSuite) Setup(backend string) error {
+	s.backend = backend
+	return nil
+}
+
+func (s *parallelTestSuite) Teardown(backend string) error {
+	return nil
+}
+
+func (s *parallelTestSuite) BeforeTest(backend string) error {
+	if backend != s.backend {
+		return fmt.Errorf("expected backend %s, instead found %s", s.backend, backend)
+	}
+	return nil
+}
+
+func (s parallelTestSuite) TestBackend(t *testing.T) {
+	if expected := os.Getenv(suiteBackendEnv); expected != "" && s.backend != expected {
+		t.Errorf("Expected subprocess to only run backend %s, instead found %s", expected, s.backend)
+	}
+	t.Logf("Running on %s", s.backend)
+}
+
+func TestRunSuiteParallel(t *testing.T) {
+	if os.Getenv(suiteBackendEnv) == "" {
+		orig, wasSet := os.LookupEnv("SKEEMA_TEST_PARALLEL")
+		os.Setenv("SKEEMA_TEST_PARALLEL", "2")
+		defer func() {
+			if wasSet {
+				os.Setenv("SKEEMA_TEST_PARALLEL", orig)
+			} else {
+				os.Unsetenv("SKEEMA_TEST_PARALLEL")
+			}
+		}()
+		if suiteParallelism() != 2 {
+			t.Fatalf("Expected suiteParallelism to return 2, instead found %d", suiteParallelism())
+		}
+	}
+	RunSuite(&parallelTestSuite{}, t, []string{"alpha", "beta"})
+}
+
+func TestPrefixLines(t *testing.T) {
+	cases := map[string]string{
+		"":                "",
+		"one":             "[b] one",
+		"one\n":           "[b] one\n",
+		"one\ntwo\n":      "[b] one\n[b] two\n",
+		"one\n\nthree":    "[b] one\n[b] \n[b] three",
+		"\tindented\nx\n": "[b] \tindented\n[b] x\n",
+	}
+	for input, expected := range cases {
+		if actual := string(prefixLines([]byte(input), "[b] ")); actual != expected {
+			t.Errorf("prefixLines(%q): expected %q, found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestFlavorMatchesConstraint(t *testing.T) {
+	cases := []struct {
+		flavor     string
+		constraint string
+		expected   bool
+	}{
+		{"mysql:8.0.36", "mysql", true},
+		{"percona:8.0.36", "mysql", true},
+		{"mariadb:10.6", "mysql", false},
+		{"mysql:8.0.36", "percona", false},
+		{"percona:5.7.44", "percona:5.7", true},
+		{"mysql:8.0.36", "mysql:8.0", true},
+		{"mysql:8.4.0", "mysql:8.0", false},
+		{"mysql:8.4.0", "mysql:8", true},
+		{"mysql:5.7.44", "mysql:8.0+", false},
+		{"mysql:8.0.0", "mysql:8.0+", true},
+		{"mysql:9.1.0", "mysql:8.0+", true},
+		{"mysql:8.0.35", "mysql:8.0.36+", false},
+		{"mariadb:10.6.16", "mariadb:-10.6", true},
+		{"mariadb:10.11.6", "mariadb:-10.6", false},
+		{"mysql:5.7.44", "mysql:5.7-8.0", true},
+		{"mysql:8.0.36", "mysql:5.7-8.0", true},
+		{"mysql:5.6.51", "mysql:5.7-8.0", false},
+		{"mysql:8.4.0", "mysql:5.7-8.0", false},
+		{"mysql:8.0.36", "mysql:-8.0.36", true},
+		{"mysql:8.0.37", "mysql:-8.0.36", false},
+	}
+	for _, c := range cases {
+		actual, err := flavorMatchesConstraint(ParseFlavor(c.flavor), c.constraint)
+		if err != nil {
+			t.Errorf("Unexpected error from flavorMatchesConstraint(%s, %q): %v", c.flavor, c.constraint, err)
+		} else if actual != c.expected {
+			t.Errorf("Expected flavorMatchesConstraint(%s, %q) to return %t, instead found %t", c.flavor, c.constraint, c.expected, actual)
+		}
+	}
+
+	for _, constraint := range []string{"oracle:8.0", "mysql:", "mysql:-", "mysql:8.x+", "mysql:8.0.1.2", "mysql:8.0-9.0+"} {
+		if _, err := flavorMatchesConstraint(ParseFlavor("mysql:8.0"), constraint); err == nil {
+			t.Errorf("Expected constraint %q to return an error, but it did not", constraint)
+		}
+	}
+}
+
+func TestBackendFlavor(t *testing.T) {
+	suite := &parallelTestSuite{}
+	cases := map[string]string{
+		"mysql:8.0":                  "mysql:8.0",
+		"mysql/mysql-server:8.0":     "mysql:8.0",
+		"percona/percona-server:5.7": "percona:5.7",
+		"mariadb:10.6":               "mariadb:10.6",
+		"ghcr.io/skeema/percona:8.0": "percona:8.0",
+	}
+	for backend, expected := range cases {
+		if actual := backendFlavor(suite, backend); actual.String() != expected {
+			t.Errorf("Expected backendFlavor(%q) to return %s, instead found %s", backend, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+// Package tracing records OpenTelemetry-compatible trace spans, and exports
+// them to a collector using OTLP over HTTP with JSON encoding. It implements
+// only the small subset of OpenTelemetry needed by Skeema, in order to avoid
+// a large dependency tree.
+//
+// If Configure has not been called with an endpoint, tracing is disabled: Start
+// returns a nil *Span, and all methods of *Span are no-ops on a nil receiver.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attribute is a key/value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{} // string, int64, or bool
+}
+
+// String returns a string-valued Attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer-valued Attribute.
+func Int(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a boolean-valued Attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span represents a single timed operation within a trace.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte // all zeroes for a root span
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	errMsg   string
+	m        sync.Mutex
+}
+
+// Span kinds, as defined by OTLP.
+const (
+	KindInternal = 1
+	KindClient   = 3
+)
+
+// exporter buffers ended spans and sends them to the collector.
+type exporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	version     string
+	client      *http.Client
+	root        *Span
+	pending     []*Span
+	flushes     sync.WaitGroup
+	stop        chan struct{}
+	m           sync.Mutex
+}
+
+// batchSize is the number of ended spans which triggers an export.
+const batchSize = 256
+
+var (
+	exp  *exporter
+	expM sync.RWMutex
+)
+
+// Configure enables tracing, exporting spans to the OTLP/HTTP collector at
+// endpoint, for example "http://localhost:4318". If endpoint is blank, the
+// standard OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT
+// environment variables are used instead; if these are also blank, tracing
+// remains disabled. Headers from OTEL_EXPORTER_OTLP_HEADERS and the service
+// name from OTEL_SERVICE_NAME are also respected.
+//
+// A root span is started with the supplied name. If the TRACEPARENT environment
+// variable contains a valid W3C trace context, for example as set by a CI
+// system, the root span joins that trace as a child of its parent span.
+func Configure(endpoint, rootName, version string) error {
+	tracesURL := endpoint
+	if tracesURL != "" {
+		tracesURL = strings.TrimSuffix(tracesURL, "/") + "/v1/traces"
+	} else if tracesURL = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); tracesURL == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			tracesURL = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if tracesURL == "" {
+		return nil
+	} else if !strings.HasPrefix(tracesURL, "http://") && !strings.HasPrefix(tracesURL, "https://") {
+		return fmt.Errorf("OTLP endpoint %q must begin with http:// or https://", tracesURL)
+	}
+	e := &exporter{
+		endpoint:    tracesURL,
+		headers:     parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		serviceName: os.Getenv("OTEL_SERVICE_NAME"),
+		version:     version,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		stop:        make(chan struct{}),
+	}
+	if e.serviceName == "" {
+		e.serviceName = "skeema"
+	}
+	e.root = newSpan(nil, rootName, KindInternal)
+	if traceID, parentID, ok := parseTraceParent(os.Getenv("TRACEPARENT")); ok {
+		e.root.traceID, e.root.parentID = traceID, parentID
+	}
+
+	expM.Lock()
+	exp = e
+	expM.Unlock()
+
+	// Periodically export spans, for the sake of long-running commands
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.flush()
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown ends the root span, using err to determine its status, and then
+// exports all remaining spans and disables tracing. It is a no-op if tracing
+// is not enabled.
+func Shutdown(err error) error {
+	expM.Lock()
+	e := exp
+	exp = nil
+	expM.Unlock()
+	if e == nil {
+		return nil
+	}
+	close(e.stop)
+	e.root.endWith(err, e)
+	e.flushes.Wait()
+	return e.export(e.takePending())
+}
+
+// Enabled returns true if tracing has been configured.
+func Enabled() bool {
+	expM.RLock()
+	defer expM.RUnlock()
+	return exp != nil
+}
+
+// Start begins a new span with the supplied name, as a child of parent. If
+// parent is nil, the span is a child of the root span. If tracing is not
+// enabled, nil is returned.
+func Start(parent *Span, name string, attrs ...Attribute) *Span {
+	return StartKind(parent, name, KindInternal, attrs...)
+}
+
+// StartKind is like Start, but also permits specifying the span kind, for
+// example KindClient for spans representing database queries.
+func StartKind(parent *Span, name string, kind int, attrs ...Attribute) *Span {
+	expM.RLock()
+	e := exp
+	expM.RUnlock()
+	if e == nil {
+		return nil
+	}
+	if parent == nil {
+		parent = e.root
+	}
+	s := newSpan(parent, name, kind)
+	s.attrs = attrs
+	return s
+}
+
+func newSpan(parent *Span, name string, kind int) *Span {
+	s := &Span{
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+	}
+	rand.Read(s.spanID[:])
+	if parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	return s
+}
+
+// SetAttributes adds attributes to s.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End marks s as complete. If err is non-nil, the span's status is set to
+// error, using err's message. End should only be called once per span.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	expM.RLock()
+	e := exp
+	expM.RUnlock()
+	s.endWith(err, e)
+}
+
+func (s *Span) endWith(err error, e *exporter) {
+	s.m.Lock()
+	s.end = time.Now()
+	if err != nil {
+		s.errMsg = err.Error()
+		if s.errMsg == "" {
+			s.errMsg = "error"
+		}
+	}
+	s.m.Unlock()
+	if e != nil {
+		e.add(s)
+	}
+}
+
+// TraceParent returns a W3C trace context header value identifying s, for
+// propagating the trace to external processes. If s is nil, an empty string is
+// returned.
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(s.traceID[:]) + "-" + hex.EncodeToString(s.spanID[:]) + "-01"
+}
+
+func (e *exporter) add(s *Span) {
+	e.m.Lock()
+	e.pending = append(e.pending, s)
+	full := len(e.pending) >= batchSize
+	e.m.Unlock()
+	if full {
+		e.flushes.Add(1)
+		go func() {
+			defer e.flushes.Done()
+			e.flush()
+		}()
+	}
+}
+
+func (e *exporter) takePending() []*Span {
+	e.m.Lock()
+	defer e.m.Unlock()
+	spans := e.pending
+	e.pending = nil
+	return spans
+}
+
+// flush exports all pending spans, logging nothing upon failure: tracing
+// problems should never interfere with Skeema's operation.
+func (e *exporter) flush() {
+	e.export(e.takePending())
+}
+
+func (e *exporter) export(spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, e.endpoint)
+	}
+	return nil
+}
+
+// otlpValue and the other otlp types below represent the OTLP/JSON encoding
+// of the ExportTraceServiceRequest protobuf message.
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"` // int64 values are encoded as JSON strings
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 0 = unset, 2 = error
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func (e *exporter) payload(spans []*Span) otlpRequest {
+	resourceAttrs := []otlpAttribute{
+		toOTLPAttribute(String("service.name", e.serviceName)),
+		toOTLPAttribute(String("service.version", e.version)),
+	}
+	ss := otlpScopeSpans{
+		Scope: otlpScope{Name: "github.com/skeema/skeema", Version: e.version},
+		Spans: make([]otlpSpan, len(spans)),
+	}
+	var zeroID [8]byte
+	for n, s := range spans {
+		s.m.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        make([]otlpAttribute, len(s.attrs)),
+		}
+		if s.parentID != zeroID {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for i, attr := range s.attrs {
+			span.Attributes[i] = toOTLPAttribute(attr)
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+		s.m.Unlock()
+		ss.Spans[n] = span
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: resourceAttrs},
+			ScopeSpans: []otlpScopeSpans{ss},
+		}},
+	}
+}
+
+func toOTLPAttribute(attr Attribute) otlpAttribute {
+	oa := otlpAttribute{Key: attr.Key}
+	switch v := attr.Value.(type) {
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		oa.Value.IntValue = &s
+	case bool:
+		oa.Value.BoolValue = &v
+	default:
+		s := fmt.Sprint(v)
+		oa.Value.StringValue = &s
+	}
+	return oa
+}
+
+// parseHeaders parses a value in the format of OTEL_EXPORTER_OTLP_HEADERS,
+// which is a comma-separated list of key=value pairs.
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok && strings.TrimSpace(k) != "" {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return headers
+}
+
+// parseTraceParent parses a W3C traceparent value, returning its trace ID and
+// parent span ID.
+func parseTraceParent(value string) (traceID [16]byte, parentID [8]byte, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return traceID, parentID, false
+	}
+	t, err1 := hex.DecodeString(parts[1])
+	p, err2 := hex.DecodeString(parts[2])
+	if err := errors.Join(err1, err2); err != nil || len(t) != 16 || len(p) != 8 {
+		return traceID, parentID, false
+	}
+	copy(traceID[:], t)
+	copy(parentID[:], p)
+	var zeroTrace [16]byte
+	var zeroParent [8]byte
+	return traceID, parentID, traceID != zeroTrace && parentID != zeroParent
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	if err := Configure("", "skeema test", "1.0"); err != nil {
+		t.Fatalf("Unexpected error from Configure: %v", err)
+	}
+	if Enabled() {
+		t.Fatal("Expected tracing to be disabled without an endpoint")
+	}
+	span := Start(nil, "noop", String("a", "b"))
+	if span != nil {
+		t.Fatalf("Expected nil span when tracing disabled, instead found %+v", span)
+	}
+	// Methods on a nil span must not panic
+	span.SetAttributes(Int("n", 1))
+	span.End(errors.New("fail"))
+	if tp := span.TraceParent(); tp != "" {
+		t.Errorf("Expected blank TraceParent from nil span, instead found %q", tp)
+	}
+	if err := Shutdown(nil); err != nil {
+		t.Errorf("Unexpected error from Shutdown: %v", err)
+	}
+
+	if err := Configure("localhost:4318", "skeema test", "1.0"); err == nil {
+		Shutdown(nil)
+		t.Error("Expected error from Configure with an endpoint lacking a scheme, but err was nil")
+	}
+}
+
+func TestExport(t *testing.T) {
+	var requests []otlpRequest
+	var headers []http.Header
+	var m sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if r.URL.Path != "/v1/traces" || r.Method != http.MethodPost {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Unable to decode request body: %v", err)
+		}
+		m.Lock()
+		requests = append(requests, req)
+		headers = append(headers, r.Header)
+		m.Unlock()
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-api-key=secret, x-tenant = skeema")
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if err := Configure(server.URL+"/", "skeema push", "1.2.3"); err != nil {
+		t.Fatalf("Unexpected error from Configure: %v", err)
+	}
+	parent := Start(nil, "apply target", String("db.name", "product"))
+	child := StartKind(parent, "execute statement", KindClient, String("db.statement", "DROP TABLE foo"))
+	child.SetAttributes(Int("attempt", 2), Bool("retried", true))
+	child.End(errors.New("Error 1051: unknown table"))
+	parent.End(nil)
+	if err := Shutdown(nil); err != nil {
+		t.Fatalf("Unexpected error from Shutdown: %v", err)
+	}
+	if Enabled() {
+		t.Error("Expected tracing to be disabled after Shutdown")
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 export request, instead found %d", len(requests))
+	}
+	if headers[0].Get("X-Api-Key") != "secret" || headers[0].Get("X-Tenant") != "skeema" || headers[0].Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected request headers: %v", headers[0])
+	}
+	rs := requests[0].ResourceSpans
+	if len(rs) != 1 || len(rs[0].ScopeSpans) != 1 {
+		t.Fatalf("Unexpected request structure: %+v", requests[0])
+	}
+	if attr := rs[0].Resource.Attributes[0]; attr.Key != "service.name" || *attr.Value.StringValue != "skeema" {
+		t.Errorf("Unexpected resource attribute %+v", attr)
+	}
+	spans := rs[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, instead found %d", len(spans))
+	}
+	childSpan, parentSpan, rootSpan := spans[0], spans[1], spans[2]
+	if rootSpan.Name != "skeema push" || rootSpan.ParentSpanID != "b7ad6b7169203331" {
+		t.Errorf("Unexpected root span %+v", rootSpan)
+	}
+	for _, span := range spans {
+		if span.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+			t.Errorf("Span %s has unexpected trace ID %s", span.Name, span.TraceID)
+		}
+		if span.StartTimeUnixNano == "" || span.EndTimeUnixNano < span.StartTimeUnixNano {
+			t.Errorf("Span %s has unexpected times %s - %s", span.Name, span.StartTimeUnixNano, span.EndTimeUnixNano)
+		}
+	}
+	if parentSpan.ParentSpanID != rootSpan.SpanID || childSpan.ParentSpanID != parentSpan.SpanID {
+		t.Errorf("Unexpected span hierarchy: %+v", spans)
+	}
+	if childSpan.Kind != KindClient || childSpan.Status.Code != 2 || childSpan.Status.Message != "Error 1051: unknown table" {
+		t.Errorf("Unexpected child span %+v", childSpan)
+	}
+	if parentSpan.Status.Code != 0 || rootSpan.Status.Code != 0 {
+		t.Errorf("Expected parent and root spans to have unset status, instead found %+v and %+v", parentSpan.Status, rootSpan.Status)
+	}
+	if len(childSpan.Attributes) != 3 || *childSpan.Attributes[1].Value.IntValue != "2" || !*childSpan.Attributes[2].Value.BoolValue {
+		t.Errorf("Unexpected child span attributes %+v", childSpan.Attributes)
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	cases := map[string]bool{
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01": true,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00": true,
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01": false,
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01": false,
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01": false,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b71692033-01":   false,
+		"00-xyz-b7ad6b7169203331-01":                              false,
+		"":                                                        false,
+	}
+	for input, expected := range cases {
+		if _, _, ok := parseTraceParent(input); ok != expected {
+			t.Errorf("Expected parseTraceParent(%q) to return ok=%t, but it did not", input, expected)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// azureResource is the resource / audience of access tokens used for
+// Microsoft Entra ID (formerly Azure AD) authentication to Azure Database for
+// MySQL.
+const azureResource = "https://ossrdbms-aad.database.windows.net"
+
+// Endpoints and commands used to obtain Azure access tokens. These are package
+// vars so that test logic may substitute stubs.
+var (
+	azureIMDSEndpoint  = "http://169.254.169.254/metadata/identity/oauth2/token"
+	azureLoginEndpoint = "https://login.microsoftonline.com"
+	azureTokenCommand  = "az account get-access-token --resource-type oss-rdbms --query accessToken --output tsv"
+)
+
+// azureCLITokenReuse is how long a token from the Azure CLI is reused for new
+// connections. The CLI does not report token lifetime in the requested output
+// format, but tokens are valid for at least an hour.
+const azureCLITokenReuse = 30 * time.Minute
+
+// AzureCredentialTypes lists the valid values for the azure-credential option.
+var AzureCredentialTypes = []string{"auto", "cli", "managed-identity", "service-principal"}
+
+// EnableAzureADAuth configures instance to authenticate using Microsoft Entra
+// ID access tokens instead of a password. credentialType should be one of
+// AzureCredentialTypes:
+//   - "managed-identity" obtains tokens from the Azure instance metadata service,
+//     or from the App Service identity endpoint if present. Set environment
+//     variable AZURE_CLIENT_ID to use a user-assigned identity.
+//   - "service-principal" obtains tokens using the client secret in environment
+//     variables AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET.
+//   - "cli" obtains tokens by shelling out to the Azure CLI, using its current
+//     login.
+//   - "auto" uses "service-principal" if its environment variables are set, or
+//     "cli" otherwise.
+//
+// Tokens are refreshed automatically as they approach expiration.
+func EnableAzureADAuth(instance *tengo.Instance, credentialType string) {
+	if credentialType == "auto" {
+		credentialType = "cli"
+		if os.Getenv("AZURE_TENANT_ID") != "" && os.Getenv("AZURE_CLIENT_ID") != "" && os.Getenv("AZURE_CLIENT_SECRET") != "" {
+			credentialType = "service-principal"
+		}
+	}
+	enableIAMAuth(instance, func(inst *tengo.Instance) {
+		switch credentialType {
+		case "managed-identity":
+			inst.SetPasswordFunc(cachedTokenFunc(azureManagedIdentityToken))
+		case "service-principal":
+			inst.SetPasswordFunc(cachedTokenFunc(azureServicePrincipalToken))
+		default:
+			inst.SetPasswordFunc(shelloutTokenFunc(azureTokenCommand, nil, azureCLITokenReuse, "Azure access token"))
+		}
+	})
+}
+
+// azureManagedIdentityToken obtains an access token for the managed identity of
+// the Azure resource running this process.
+func azureManagedIdentityToken() (string, time.Duration, error) {
+	v := url.Values{}
+	v.Set("resource", azureResource)
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		v.Set("client_id", clientID) // user-assigned identity
+	}
+	var req *http.Request
+	var err error
+	if endpoint := os.Getenv("IDENTITY_ENDPOINT"); endpoint != "" && os.Getenv("IDENTITY_HEADER") != "" {
+		// App Service, Azure Functions, Container Apps
+		v.Set("api-version", "2019-08-01")
+		req, err = http.NewRequest(http.MethodGet, endpoint+"?"+v.Encode(), nil)
+		if err == nil {
+			req.Header.Set("X-IDENTITY-HEADER", os.Getenv("IDENTITY_HEADER"))
+		}
+	} else {
+		// Virtual machines, AKS, and other services with instance metadata
+		v.Set("api-version", "2018-02-01")
+		req, err = http.NewRequest(http.MethodGet, azureIMDSEndpoint+"?"+v.Encode(), nil)
+		if err == nil {
+			req.Header.Set("Metadata", "true")
+		}
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return azureTokenRequest(req, "managed identity")
+}
+
+// azureServicePrincipalToken obtains an access token for the service principal
+// configured in environment variables.
+func azureServicePrincipalToken() (string, time.Duration, error) {
+	tenantID, clientID, clientSecret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if tenantID == "" || clientID == "" || clientSecret == "" {
+		return "", 0, errors.New("Unable to obtain Azure access token for service principal: environment variables AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET must all be set")
+	}
+	v := url.Values{}
+	v.Set("grant_type", "client_credentials")
+	v.Set("client_id", clientID)
+	v.Set("client_secret", clientSecret)
+	v.Set("scope", azureResource+"/.default")
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureLoginEndpoint, url.PathEscape(tenantID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return azureTokenRequest(req, "service principal "+clientID)
+}
+
+// azureTokenRequest performs req and parses the resulting token response. The
+// returned duration is how long the token may be reused, which is 5 minutes
+// less than its actual lifetime.
+func azureTokenRequest(req *http.Request, description string) (string, time.Duration, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to obtain Azure access token for %s: %w", description, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("Unable to obtain Azure access token for %s: %w", description, err)
+	}
+	var result struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"` // some endpoints return a string, others a number
+		Error       string      `json:"error"`
+		Description string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, fmt.Errorf("Unable to obtain Azure access token for %s: HTTP %d with unexpected response body: %w", description, resp.StatusCode, err)
+	} else if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", 0, fmt.Errorf("Unable to obtain Azure access token for %s: HTTP %d: %s %s", description, resp.StatusCode, result.Error, result.Description)
+	}
+	reuse := azureCLITokenReuse
+	if seconds, err := strconv.Atoi(result.ExpiresIn.String()); err == nil && seconds > 600 {
+		reuse = time.Duration(seconds-300) * time.Second
+	}
+	return result.AccessToken, reuse, nil
+}
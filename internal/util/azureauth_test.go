@@ -0,0 +1,98 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestEnableAzureADAuth(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/metadata/identity/oauth2/token":
+			if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != azureResource || r.URL.Query().Get("client_id") != "my-identity" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_request", "error_description": "bad request"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "mi-token", "expires_in": "86399"}`))
+		case "/my-tenant/oauth2/v2.0/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("client_secret") != "shh" || r.PostForm.Get("scope") != azureResource+"/.default" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_client", "error_description": "bad secret"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "sp-token", "expires_in": 3599}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	origIMDS, origLogin := azureIMDSEndpoint, azureLoginEndpoint
+	defer func() {
+		azureIMDSEndpoint, azureLoginEndpoint = origIMDS, origLogin
+	}()
+	azureIMDSEndpoint = server.URL + "/metadata/identity/oauth2/token"
+	azureLoginEndpoint = server.URL
+	t.Setenv("IDENTITY_ENDPOINT", "")
+	t.Setenv("AZURE_TENANT_ID", "my-tenant")
+	t.Setenv("AZURE_CLIENT_ID", "my-identity")
+	t.Setenv("AZURE_CLIENT_SECRET", "shh")
+
+	getInstance := func() *tengo.Instance {
+		t.Helper()
+		inst, err := tengo.NewInstance("mysql", "app@tcp(mydb.mysql.database.azure.com:3306)/")
+		if err != nil {
+			t.Fatalf("Unexpected error from NewInstance: %v", err)
+		}
+		return inst
+	}
+
+	// Managed identity, with token reused on subsequent calls
+	inst := getInstance()
+	EnableAzureADAuth(inst, "managed-identity")
+	for n := 0; n < 2; n++ {
+		if token, err := inst.CurrentPassword(); err != nil || token != "mi-token" {
+			t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 token request, instead found %d", requests)
+	}
+
+	// Service principal, selected automatically via env vars
+	inst = getInstance()
+	EnableAzureADAuth(inst, "auto")
+	if token, err := inst.CurrentPassword(); err != nil || token != "sp-token" {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+
+	// Error responses should be returned
+	t.Setenv("AZURE_CLIENT_SECRET", "wrong")
+	inst = getInstance()
+	EnableAzureADAuth(inst, "service-principal")
+	if _, err := inst.CurrentPassword(); err == nil {
+		t.Error("Expected error from CurrentPassword with wrong secret, but err was nil")
+	}
+	t.Setenv("AZURE_CLIENT_SECRET", "")
+	inst = getInstance()
+	EnableAzureADAuth(inst, "service-principal")
+	if _, err := inst.CurrentPassword(); err == nil {
+		t.Error("Expected error from CurrentPassword with missing env var, but err was nil")
+	}
+}
+
+func TestAzureTokenReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token": "short", "expires_in": 60}`))
+	}))
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if token, reuse, err := azureTokenRequest(req, "test"); err != nil || token != "short" || reuse != azureCLITokenReuse {
+		t.Errorf("Unexpected return from azureTokenRequest: %q, %s, %v", token, reuse, err)
+	}
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// cloudSQLDescribeCommand and cloudSQLTokenCommand are the command-lines used to
+// look up Cloud SQL instance addresses and generate IAM database auth tokens,
+// respectively. They are package vars so that test logic may substitute stubs.
+var (
+	cloudSQLDescribeCommand = "gcloud sql instances describe {INSTANCE} --project {PROJECT} --format json"
+	cloudSQLTokenCommand    = "gcloud sql generate-login-token"
+)
+
+// cloudSQLTokenReuse is how long a generated token is reused for new
+// connections. Tokens are OAuth2 access tokens, which are typically valid for
+// one hour.
+const cloudSQLTokenReuse = 30 * time.Minute
+
+// Instance connection names have format "project:region:instance". Project IDs
+// may optionally have a domain prefix, e.g. "example.com:project". Since region
+// names always contain a hyphen, this format is distinguishable from IPv6
+// addresses.
+var reCloudSQLInstanceName = regexp.MustCompile(`^((?:[a-z0-9.-]+:)?[a-z][a-z0-9-]*[a-z0-9]):([a-z]+-[a-z]+[0-9]+):([a-z][a-z0-9-]*)$`)
+
+// IsCloudSQLInstanceName returns true if host is a Cloud SQL instance
+// connection name, rather than a hostname or IP address.
+func IsCloudSQLInstanceName(host string) bool {
+	return reCloudSQLInstanceName.MatchString(host)
+}
+
+// CloudSQLAddress returns the IP address of the Cloud SQL instance with the
+// supplied instance connection name, by shelling out to the gcloud CLI. If
+// private is true, the instance's private IP address is returned; otherwise its
+// public IP address is returned.
+func CloudSQLAddress(connectionName string, private bool) (string, error) {
+	matches := reCloudSQLInstanceName.FindStringSubmatch(connectionName)
+	if matches == nil {
+		return "", fmt.Errorf("%q is not a valid Cloud SQL instance connection name", connectionName)
+	}
+	variables := map[string]string{
+		"PROJECT":  matches[1],
+		"INSTANCE": matches[3],
+	}
+	c := shellout.New(cloudSQLDescribeCommand).WithVariablesStrict(variables)
+	out, err := c.RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("Unable to look up Cloud SQL instance %s using `%s`: %w", connectionName, c, err)
+	}
+	addr, err := cloudSQLAddressFromJSON([]byte(out), private)
+	if err != nil {
+		return "", fmt.Errorf("Unable to look up Cloud SQL instance %s: %w", connectionName, err)
+	}
+	return addr, nil
+}
+
+// cloudSQLAddressFromJSON extracts an IP address from the JSON representation
+// of a Cloud SQL instance.
+func cloudSQLAddressFromJSON(data []byte, private bool) (string, error) {
+	var described struct {
+		IPAddresses []struct {
+			IPAddress string `json:"ipAddress"`
+			Type      string `json:"type"`
+		} `json:"ipAddresses"`
+	}
+	if err := json.Unmarshal(data, &described); err != nil {
+		return "", err
+	}
+	wantType := "PRIMARY" // Cloud SQL's name for the public IP address
+	if private {
+		wantType = "PRIVATE"
+	}
+	for _, ip := range described.IPAddresses {
+		if strings.EqualFold(ip.Type, wantType) && ip.IPAddress != "" {
+			return ip.IPAddress, nil
+		}
+	}
+	return "", fmt.Errorf("instance does not have a %s IP address", strings.ToLower(wantType))
+}
+
+// EnableCloudSQLAuth configures instance to authenticate using Cloud SQL IAM
+// database authentication instead of a password. Tokens are generated by
+// shelling out to the gcloud CLI, using its active account or service account.
+// Tokens are regenerated automatically as they approach expiration.
+func EnableCloudSQLAuth(instance *tengo.Instance) {
+	enableIAMAuth(instance, func(inst *tengo.Instance) {
+		inst.SetPasswordFunc(shelloutTokenFunc(cloudSQLTokenCommand, nil, cloudSQLTokenReuse, "Cloud SQL IAM auth token"))
+	})
+}
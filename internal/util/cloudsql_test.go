@@ -0,0 +1,92 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestIsCloudSQLInstanceName(t *testing.T) {
+	cases := map[string]bool{
+		"my-project:us-central1:my-db":            true,
+		"example.com:my-project:europe-west2:db1": true,
+		"my-project:northamerica-northeast1:db":   true,
+		"my-project:us-central1":                  false,
+		"some.db.host":                            false,
+		"some.db.host:3306":                       false,
+		"fe80::1":                                 false,
+		"2001:db8:0:0:1:0:0:1":                    false,
+		"My-Project:us-central1:my-db":            false,
+		"my-project:us-central1:my-db:3306":       false,
+		"localhost":                               false,
+	}
+	for input, expected := range cases {
+		if actual := IsCloudSQLInstanceName(input); actual != expected {
+			t.Errorf("Expected IsCloudSQLInstanceName(%q) to return %t, instead found %t", input, expected, actual)
+		}
+	}
+}
+
+func TestCloudSQLAddress(t *testing.T) {
+	origCommand := cloudSQLDescribeCommand
+	defer func() {
+		cloudSQLDescribeCommand = origCommand
+	}()
+	// The stub command reads from a file, since JSON braces would otherwise be
+	// interpreted as variable placeholders
+	setDescribeOutput := func(output string) {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "describe.json")
+		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %v", path, err)
+		}
+		cloudSQLDescribeCommand = "cat " + path + " # {PROJECT} {INSTANCE}"
+	}
+	setDescribeOutput(`{"name": "my-db", "ipAddresses": [{"ipAddress": "203.0.113.5", "type": "PRIMARY"}, {"ipAddress": "10.1.2.3", "type": "PRIVATE"}]}`)
+
+	if addr, err := CloudSQLAddress("my-project:us-central1:my-db", false); err != nil || addr != "203.0.113.5" {
+		t.Errorf("Unexpected return from CloudSQLAddress: %q, %v", addr, err)
+	}
+	if addr, err := CloudSQLAddress("my-project:us-central1:my-db", true); err != nil || addr != "10.1.2.3" {
+		t.Errorf("Unexpected return from CloudSQLAddress: %q, %v", addr, err)
+	}
+	if _, err := CloudSQLAddress("some.db.host", false); err == nil {
+		t.Error("Expected error from CloudSQLAddress with invalid connection name, but err was nil")
+	}
+
+	setDescribeOutput(`{"ipAddresses": [{"ipAddress": "203.0.113.5", "type": "PRIMARY"}]}`)
+	if _, err := CloudSQLAddress("my-project:us-central1:my-db", true); err == nil {
+		t.Error("Expected error from CloudSQLAddress with no private IP, but err was nil")
+	}
+	cloudSQLDescribeCommand = "false"
+	if _, err := CloudSQLAddress("my-project:us-central1:my-db", false); err == nil {
+		t.Error("Expected error from CloudSQLAddress with failing shellout, but err was nil")
+	}
+}
+
+func TestEnableCloudSQLAuth(t *testing.T) {
+	origCommand := cloudSQLTokenCommand
+	defer func() {
+		cloudSQLTokenCommand = origCommand
+	}()
+	cloudSQLTokenCommand = "echo fake-token"
+
+	inst, err := tengo.NewInstance("mysql", "app@tcp(203.0.113.5:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	EnableCloudSQLAuth(inst)
+	if token, err := inst.CurrentPassword(); err != nil || token != "fake-token" {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+	replica, err := tengo.NewInstance("mysql", "app@tcp(10.1.2.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	InheritIAMAuth(inst, replica)
+	if token, err := replica.CurrentPassword(); err != nil || token != "fake-token" {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+}
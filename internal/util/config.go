@@ -1,6 +1,7 @@
 package util
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -24,6 +26,8 @@ func AddGlobalOptions(cmd *mybase.Command) {
 	cmd.AddOption(mybase.StringOption("port", 'P', "3306", "Port to use for database host").Hidden())
 	cmd.AddOption(mybase.StringOption("socket", 'S', "/tmp/mysql.sock", "Absolute path to Unix socket file used if host is localhost").Hidden())
 	cmd.AddOption(mybase.StringOption("schema", 0, "", "Database schema name").Hidden())
+	cmd.AddOption(mybase.StringOption("schema-range", 0, "", "Comma-separated integers or ranges substituted into a schema name containing %d").Hidden())
+	cmd.AddOption(mybase.StringOption("schema-map", 0, "", "Comma-separated logical=physical pairs renaming schemas in the current environment").Hidden())
 	cmd.AddOption(mybase.StringOption("default-character-set", 0, "", "Schema-level default character set").Hidden())
 	cmd.AddOption(mybase.StringOption("default-collation", 0, "", "Schema-level default collation").Hidden())
 	cmd.AddOption(mybase.StringOption("flavor", 0, "", "Database server expressed in format vendor:major.minor, for use in vendor/version specific syntax").Hidden())
@@ -33,14 +37,36 @@ func AddGlobalOptions(cmd *mybase.Command) {
 	cmd.AddOptions("global",
 		mybase.StringOption("user", 'u', "root", "Username to connect to database host"),
 		mybase.StringOption("password", 'p', "$MYSQL_PWD", "Password for database user; omit value to prompt from TTY").ValueOptional(),
+		mybase.StringOption("credential-helper", 0, "", "External bin to shell out to for obtaining user and password as JSON; see manual for template vars"),
+		mybase.BoolOption("aws-iam-auth", 0, false, "Authenticate using RDS IAM auth tokens generated by the aws CLI, instead of a password"),
+		mybase.StringOption("aws-region", 0, "", "With --aws-iam-auth or aws-sm: / aws-ssm: credentials, AWS region to use (default from RDS hostname or AWS config)"),
+		mybase.StringOption("aws-profile", 0, "", "With --aws-iam-auth or aws-sm: / aws-ssm: credentials, AWS config profile to use"),
+		mybase.BoolOption("gcp-iam-auth", 0, false, "Authenticate using Cloud SQL IAM auth tokens generated by the gcloud CLI, instead of a password"),
+		mybase.BoolOption("azure-ad-auth", 0, false, "Authenticate using Microsoft Entra ID (Azure AD) access tokens, instead of a password"),
+		mybase.StringOption("azure-credential", 0, "auto", `With --azure-ad-auth, how to obtain access tokens (valid values: "auto", "cli", "managed-identity", "service-principal")`),
+		mybase.StringOption("gcp-ip-type", 0, "public", `For hosts given as Cloud SQL instance connection names, which IP address to connect to (valid values: "public", "private")`),
 		mybase.StringOption("host-wrapper", 'H', "", "External bin to shell out to for host lookup; see manual for template vars"),
+		mybase.StringOption("host-wrapper-ttl", 0, "0", "Duration to cache host-wrapper output across invocations; 0 disables caching"),
+		mybase.StringOption("host-filter", 0, "", "Only use hosts whose role, shard, or labels match these comma-separated key=value pairs, from JSON host-wrapper output"),
+		mybase.StringOption("host-discovery-ttl", 0, "30s", "Duration to cache host lists obtained from srv:, consul:, or etcd: host values"),
+		mybase.BoolOption("host-discovery-allow-empty", 0, false, "Permit srv:, consul:, or etcd: host values to resolve to no hosts, instead of returning an error"),
 		mybase.StringOption("connect-options", 'o', "", "Comma-separated session options to set upon connecting to each database server"),
+		mybase.StringOption("connect-timeout", 0, "5s", "Timeout for establishing each connection to a database server"),
+		mybase.StringOption("read-timeout", 0, "20s", "Timeout for reading each query result from a database server; 0 disables"),
+		mybase.StringOption("max-open-conns", 0, "0", "Maximum open connections per pool to each database server; 0 chooses automatically from server limits"),
+		mybase.StringOption("max-idle-conns", 0, "0", "Maximum idle connections retained per pool to each database server; 0 chooses automatically"),
+		mybase.StringOption("conn-max-lifetime", 0, "1m", "Maximum duration to reuse each connection to a database server"),
 		mybase.StringOption("ignore-schema", 0, "", "Ignore schemas that match regex"),
 		mybase.StringOption("ignore-table", 0, "", "Ignore tables that match regex"),
 		mybase.StringOption("ignore-proc", 0, "", "Ignore stored procedures that match regex"),
 		mybase.StringOption("ignore-func", 0, "", "Ignore functions that match regex"),
+		mybase.StringOption("only", 0, "", "Only operate on objects matching these comma-separated type:glob patterns, e.g. table:orders_*"),
+		mybase.StringOption("skip", 0, "", "Ignore objects matching these comma-separated type:glob patterns, e.g. routine:calc_*"),
 		mybase.StringOption("ssl-mode", 0, "", `Specify desired connection security SSL/TLS usage (valid values: "disabled", "preferred", "required")`),
+		mybase.StringOption("profile", 0, "", "Comma-separated names of [profile:name] option file sections to apply, overriding the environment's section"),
 		mybase.BoolOption("debug", 0, false, "Enable debug logging"),
+		mybase.StringOption("log-format", 0, "text", `Format of log output on STDERR (valid values: "text", "json")`),
+		mybase.StringOption("otlp-endpoint", 0, "", "Export OpenTelemetry trace spans to this OTLP/HTTP collector URL, e.g. http://localhost:4318"),
 		mybase.BoolOption("my-cnf", 0, true, "Parse ~/.my.cnf for configuration"),
 	)
 }
@@ -89,7 +115,7 @@ func AddGlobalConfigFiles(cfg *mybase.Config) {
 		if strings.HasSuffix(path, ".my.cnf") {
 			_ = f.UseSection("skeema", "client", "mysql") // safe to ignore error (doesn't matter if section doesn't exist)
 		} else if cfg.CLI.Command.HasArg("environment") { // avoid panic on command without environment arg, such as help command!
-			_ = f.UseSection(cfg.Get("environment")) // safe to ignore error (doesn't matter if section doesn't exist)
+			_ = f.UseSection(OptionFileSections(cfg)...) // safe to ignore error (doesn't matter if sections don't exist)
 		}
 
 		cfg.AddSource(f)
@@ -146,9 +172,45 @@ func ProcessSpecialGlobalOptions(cfg *mybase.Config) error {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	for _, name := range cfg.GetSlice("profile", ',', true) {
+		if !reProfileName.MatchString(name) {
+			return fmt.Errorf("Option profile has invalid profile name %q: names may only contain letters, numbers, underscores, hyphens, and periods", name)
+		}
+	}
+
+	// With log-format=json, emit one JSON object per log record, including any
+	// structured fields (target instance, schema, object, etc) attached to the
+	// record. These fields are omitted by the default text format.
+	if logFormat, err := cfg.GetEnum("log-format", "text", "json"); err != nil {
+		return err
+	} else if logFormat == "json" {
+		log.SetFormatter(&log.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	}
+
 	return nil
 }
 
+// ProfileSectionPrefix is the prefix of option file section names which define
+// a profile, rather than an environment.
+const ProfileSectionPrefix = "profile:"
+
+var reProfileName = regexp.MustCompile(`^[\w.-]+$`)
+
+// OptionFileSections returns the names of the option file sections which apply
+// to cfg, in order of precedence: a "profile:name" section for each profile
+// listed in the profile option, followed by the section named after the
+// environment. Profiles thereby permit reusable option sets, such as one
+// configuring an online schema change tool, to be combined with any
+// environment without being duplicated into each environment's section.
+func OptionFileSections(cfg *mybase.Config) []string {
+	profiles := cfg.GetSlice("profile", ',', true)
+	sections := make([]string, 0, len(profiles)+1)
+	for _, name := range profiles {
+		sections = append(sections, ProfileSectionPrefix+name)
+	}
+	return append(sections, cfg.Get("environment"))
+}
+
 // PasswordInputSource is a function that can be used to obtain a password
 // interactively.
 type PasswordInputSource func() (string, error)
@@ -211,6 +273,27 @@ func PromptPassword(promptArgs ...interface{}) (string, error) {
 	return pw, err
 }
 
+// PromptConfirm displays a yes/no question and reads the answer from STDIN,
+// returning true if the answer was "y" or "yes" (case-insensitive). Requires
+// that STDIN is a TTY. As with PromptPassword, the prompt will be written to
+// STDERR, unless STDERR is a non-terminal and STDOUT is a terminal.
+func PromptConfirm(promptArgs ...interface{}) (bool, error) {
+	if !StdinIsTerminal() {
+		return false, errors.New("STDIN must be a terminal to confirm interactively")
+	}
+	w := os.Stderr
+	if !StderrIsTerminal() && StdoutIsTerminal() {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, promptArgs[0].(string)+" [y/N] ", promptArgs[1:]...)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
 // SplitConnectOptions takes a string containing a comma-separated list of
 // connection options (typically obtained from the "connect-options" option)
 // and splits them into a map of individual key: value strings. This function
@@ -352,9 +435,9 @@ var ignoreOptionToTypes = []struct {
 }
 
 // IgnorePatterns compiles the regexes in the supplied mybase.Config's ignore-*
-// options. If all supplied regex strings were valid, a slice of
-// tengo.ObjectPattern is returned; otherwise, an error with the first invalid
-// regex is returned.
+// options, as well as the globs in its only and skip options. If all supplied
+// values were valid, a slice of tengo.ObjectPattern is returned; otherwise, an
+// error with the first invalid value is returned.
 func IgnorePatterns(cfg *mybase.Config) ([]tengo.ObjectPattern, error) {
 	var patterns []tengo.ObjectPattern
 	for _, opt := range ignoreOptionToTypes {
@@ -367,5 +450,87 @@ func IgnorePatterns(cfg *mybase.Config) ([]tengo.ObjectPattern, error) {
 			}
 		}
 	}
+
+	skip, err := objectGlobPatterns(cfg, "skip")
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, skip...)
+
+	// For only, each object type gets a single negated pattern. Object types
+	// not mentioned at all are ignored entirely.
+	only, err := objectGlobPatterns(cfg, "only")
+	if err != nil || len(only) == 0 {
+		return patterns, err
+	}
+	for _, objType := range []tengo.ObjectType{tengo.ObjectTypeTable, tengo.ObjectTypeProc, tengo.ObjectTypeFunc} {
+		var alternatives []string
+		for _, p := range only {
+			if p.Type == objType {
+				alternatives = append(alternatives, p.Pattern.String())
+			}
+		}
+		if len(alternatives) == 0 {
+			alternatives = append(alternatives, "$^") // only matches empty names, so negation matches every object
+		}
+		re := regexp.MustCompile(strings.Join(alternatives, "|"))
+		patterns = append(patterns, tengo.ObjectPattern{Type: objType, Pattern: re, Negate: true})
+	}
+	return patterns, nil
+}
+
+// objectGlobTypes maps type prefixes permitted in the only and skip options to
+// the object types they refer to.
+var objectGlobTypes = map[string][]tengo.ObjectType{
+	"table":      {tengo.ObjectTypeTable},
+	"tables":     {tengo.ObjectTypeTable},
+	"proc":       {tengo.ObjectTypeProc},
+	"procs":      {tengo.ObjectTypeProc},
+	"procedure":  {tengo.ObjectTypeProc},
+	"procedures": {tengo.ObjectTypeProc},
+	"func":       {tengo.ObjectTypeFunc},
+	"funcs":      {tengo.ObjectTypeFunc},
+	"function":   {tengo.ObjectTypeFunc},
+	"functions":  {tengo.ObjectTypeFunc},
+	"routine":    {tengo.ObjectTypeProc, tengo.ObjectTypeFunc},
+	"routines":   {tengo.ObjectTypeProc, tengo.ObjectTypeFunc},
+}
+
+// objectGlobPatterns converts the comma-separated list of globs in the named
+// option into a slice of tengo.ObjectPattern. Each glob may be prefixed by an
+// object type and a colon, for example "table:orders_*"; otherwise it applies
+// to all object types. In globs, * matches any number of characters and ?
+// matches exactly one character.
+func objectGlobPatterns(cfg *mybase.Config, optionName string) ([]tengo.ObjectPattern, error) {
+	var patterns []tengo.ObjectPattern
+	for _, glob := range cfg.GetSlice(optionName, ',', true) {
+		types := []tengo.ObjectType{tengo.ObjectTypeTable, tengo.ObjectTypeProc, tengo.ObjectTypeFunc}
+		if typeName, name, ok := strings.Cut(glob, ":"); ok {
+			if types, ok = objectGlobTypes[strings.ToLower(typeName)]; !ok {
+				return nil, fmt.Errorf("Option %s: unknown object type %q in %q", optionName, typeName, glob)
+			}
+			glob = name
+		}
+		if glob == "" {
+			return nil, fmt.Errorf("Option %s: missing object name pattern", optionName)
+		}
+		var b strings.Builder
+		b.WriteByte('^')
+		for _, r := range glob {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteByte('.')
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteByte('$')
+		re := regexp.MustCompile(b.String())
+		for _, objType := range types {
+			patterns = append(patterns, tengo.ObjectPattern{Type: objType, Pattern: re})
+		}
+	}
 	return patterns, nil
 }
@@ -1,11 +1,14 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/tengo"
 )
@@ -212,6 +215,60 @@ func TestPasswordOption(t *testing.T) {
 	assertPassword(cfg, "")
 }
 
+func TestLogFormatOption(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+	origFormatter := log.StandardLogger().Formatter
+	defer log.SetFormatter(origFormatter)
+
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --log-format=xml")
+	if err := ProcessSpecialGlobalOptions(cfg); err == nil {
+		t.Error("Expected error from invalid log-format, but err was nil")
+	}
+
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --log-format=json")
+	if err := ProcessSpecialGlobalOptions(cfg); err != nil {
+		t.Fatalf("Unexpected error from ProcessSpecialGlobalOptions: %v", err)
+	}
+	var buf bytes.Buffer
+	origOut := log.StandardLogger().Out
+	log.SetOutput(&buf)
+	defer log.SetOutput(origOut)
+	log.WithFields(log.Fields{"instance": "localhost:3306", "schema": "product"}).Warn("hello world")
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unable to parse log output %q as JSON: %v", buf.String(), err)
+	}
+	if record["level"] != "warning" || record["msg"] != "hello world" || record["instance"] != "localhost:3306" || record["schema"] != "product" || record["time"] == nil {
+		t.Errorf("Unexpected JSON log record: %v", record)
+	}
+}
+
+func TestOptionFileSections(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddArg("environment", "production", false)
+	cmdSuite.AddSubCommand(cmd)
+
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff staging")
+	if sections := OptionFileSections(cfg); len(sections) != 1 || sections[0] != "staging" {
+		t.Errorf("Unexpected sections %v", sections)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --profile=osc,fast")
+	if err := ProcessSpecialGlobalOptions(cfg); err != nil {
+		t.Errorf("Unexpected error from ProcessSpecialGlobalOptions: %v", err)
+	}
+	if sections := OptionFileSections(cfg); strings.Join(sections, " ") != "profile:osc profile:fast production" {
+		t.Errorf("Unexpected sections %v", sections)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --profile='osc,[fast]'")
+	if err := ProcessSpecialGlobalOptions(cfg); err == nil {
+		t.Error("Expected error from invalid profile name, but err was nil")
+	}
+}
+
 func TestSplitConnectOptions(t *testing.T) {
 	assertConnectOpts := func(connectOptions string, expectedPair ...string) {
 		result, err := SplitConnectOptions(connectOptions)
@@ -322,3 +379,55 @@ func TestIgnorePatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestIgnorePatternsOnlySkip(t *testing.T) {
+	cmd := mybase.NewCommand("skeematest", "", "", nil)
+	AddGlobalOptions(cmd)
+	cfg := mybase.ParseFakeCLI(t, cmd, `skeematest --only='tables:orders_*,order?,routine:calc_*' --skip='table:orders_archive,*_tmp'`)
+	ignore, err := IgnorePatterns(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error from IgnorePatterns: %v", err)
+	}
+	shouldIgnore := func(obj tengo.ObjectKeyer) bool {
+		for _, pattern := range ignore {
+			if pattern.Match(obj) {
+				return true
+			}
+		}
+		return false
+	}
+	cases := map[tengo.ObjectKey]bool{
+		{Type: tengo.ObjectTypeTable, Name: "orders_2024"}:    false,
+		{Type: tengo.ObjectTypeTable, Name: "orders"}:         false,
+		{Type: tengo.ObjectTypeTable, Name: "orders_archive"}: true,
+		{Type: tengo.ObjectTypeTable, Name: "orders_tmp"}:     true,
+		{Type: tengo.ObjectTypeTable, Name: "customers"}:      true,
+		{Type: tengo.ObjectTypeTable, Name: "xorders_1"}:      true,
+		{Type: tengo.ObjectTypeProc, Name: "calc_total"}:      false,
+		{Type: tengo.ObjectTypeFunc, Name: "calc_tax"}:        false,
+		{Type: tengo.ObjectTypeFunc, Name: "calc_tax_tmp"}:    true,
+		{Type: tengo.ObjectTypeFunc, Name: "orderx"}:          false,
+		{Type: tengo.ObjectTypeFunc, Name: "format_name"}:     true,
+	}
+	for key, expectIgnored := range cases {
+		if ignored := shouldIgnore(key); ignored != expectIgnored {
+			t.Errorf("For %s, expected ignored %t, instead found %t", key, expectIgnored, ignored)
+		}
+	}
+
+	// Object types not mentioned in --only are ignored entirely
+	cfg = mybase.ParseFakeCLI(t, cmd, `skeematest --only='table:*'`)
+	if ignore, err = IgnorePatterns(cfg); err != nil {
+		t.Fatalf("Unexpected error from IgnorePatterns: %v", err)
+	}
+	if shouldIgnore(tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "anything"}) || !shouldIgnore(tengo.ObjectKey{Type: tengo.ObjectTypeProc, Name: "anything"}) {
+		t.Errorf("Unexpected result from patterns %v", ignore)
+	}
+
+	for _, badValue := range []string{"view:foo", "table:", "tables:a,proc:"} {
+		cfg = mybase.ParseFakeCLI(t, cmd, "skeematest --skip="+badValue)
+		if _, err := IgnorePatterns(cfg); err == nil {
+			t.Errorf("Expected error from IgnorePatterns with --skip=%s, but err was nil", badValue)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule represents a parsed cron expression, consisting of five fields:
+// minute, hour, day of month, month, and day of week.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bitsets of permitted values
+	domStar, dowStar              bool   // true if field was "*", for day-matching semantics
+}
+
+// cronFieldBounds lists the minimum and maximum values of each cron field.
+var cronFieldBounds = [5][2]int{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week; both 0 and 7 mean Sunday
+}
+
+// ParseCronSchedule parses a standard five-field cron expression. Each field
+// may be "*", a number, a range such as "1-5", or a comma-separated list of
+// these, each optionally followed by a step such as "*/15". Month and day of
+// week names are not supported. The descriptors @hourly, @daily, @weekly, and
+// @monthly are also permitted.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	switch strings.TrimSpace(spec) {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Invalid cron schedule %q: expected 5 fields, found %d", spec, len(fields))
+	}
+	var bitsets [5]uint64
+	for n, field := range fields {
+		bits, err := parseCronField(field, cronFieldBounds[n][0], cronFieldBounds[n][1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cron schedule %q: %w", spec, err)
+		}
+		bitsets[n] = bits
+	}
+	// Treat day of week 7 as an alias for 0
+	if bitsets[4]&(1<<7) != 0 {
+		bitsets[4] |= 1
+	}
+	return &CronSchedule{
+		minute:  bitsets[0],
+		hour:    bitsets[1],
+		dom:     bitsets[2],
+		month:   bitsets[3],
+		dow:     bitsets[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns a bitset of the values permitted by field.
+func parseCronField(field string, min, max int) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+		start, end := min, max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startPart)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", endPart)
+				}
+			} else if hasStep {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the earliest time after t, truncated to the minute, which
+// matches the schedule. The result is computed in t's location.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Any valid schedule matches at least once within a few years, accounting
+	// for leap days; stop looking after that point to avoid an infinite loop.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		} else if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		} else if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		} else if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+		} else {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches returns true if t's day is permitted by the schedule. As in
+// standard cron, if both day of month and day of week are restricted, a day
+// matching either field is permitted.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
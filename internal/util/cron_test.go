@@ -0,0 +1,53 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	start := time.Date(2026, 1, 30, 10, 17, 42, 0, time.UTC) // a Friday
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 30, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 2, 2, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"5,10 8-9 31 * *", time.Date(2026, 1, 31, 8, 5, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 1", time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)}, // dom or dow
+		{"0 0 1 */3 *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		sched, err := ParseCronSchedule(c.spec)
+		if err != nil {
+			t.Errorf("Unexpected error from ParseCronSchedule(%q): %v", c.spec, err)
+		} else if actual := sched.Next(start); !actual.Equal(c.expected) {
+			t.Errorf("Unexpected result from Next for %q: expected %s, found %s", c.spec, c.expected, actual)
+		}
+	}
+
+	badSpecs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * jan *",
+	}
+	for _, spec := range badSpecs {
+		if _, err := ParseCronSchedule(spec); err == nil {
+			t.Errorf("Expected error from ParseCronSchedule(%q), but err was nil", spec)
+		}
+	}
+}
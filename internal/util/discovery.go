@@ -0,0 +1,253 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discoveryPrefixes lists the supported prefixes for host values which refer to
+// a service discovery source, rather than a literal hostname.
+var discoveryPrefixes = []string{"srv:", "consul:", "etcd:"}
+
+// lookupSRV is the function used for DNS SRV lookups. It is a package var so
+// that test logic may substitute a stub.
+var lookupSRV = net.LookupSRV
+
+// discoveredHosts caches host lists obtained from service discovery, keyed by
+// the full reference.
+var discoveredHosts = struct {
+	sync.Mutex
+	entries map[string]discoveryEntry
+}{entries: make(map[string]discoveryEntry)}
+
+type discoveryEntry struct {
+	hosts   []string
+	expires time.Time
+}
+
+// IsDiscoveryReference returns true if host refers to a service discovery
+// source, rather than being a literal hostname.
+func IsDiscoveryReference(host string) bool {
+	for _, prefix := range discoveryPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscoverHosts returns the list of host or host:port values referenced by
+// ref, which may have any of these formats:
+//   - "srv:name" for a DNS SRV record, for example "srv:_mysql._tcp.shard.example.com"
+//   - "consul:service" for the healthy instances of a Consul service, optionally
+//     filtered using query parameters, for example "consul:mysql?tag=primary&dc=east"
+//   - "etcd:key" for an etcd key whose value is a comma-separated or newline-
+//     separated host list; or "etcd:prefix/" for all keys under a prefix, each
+//     of which has a single host as its value
+//
+// Consul requests use the CONSUL_HTTP_ADDR and CONSUL_HTTP_TOKEN environment
+// variables, in the same manner as the Consul CLI. Etcd requests use the v3
+// JSON gateway of the first reachable endpoint in the comma-separated
+// ETCDCTL_ENDPOINTS environment variable. If these variables are not set, the
+// default local address of each service is used.
+//
+// Results are cached for the supplied ttl, so that repeated lookups within a
+// single command, or successive checks by long-running commands, do not
+// overwhelm the discovery service. The result is sorted, for determinism.
+func DiscoverHosts(ref string, ttl time.Duration) ([]string, error) {
+	discoveredHosts.Lock()
+	defer discoveredHosts.Unlock()
+	if entry, ok := discoveredHosts.entries[ref]; ok && time.Now().Before(entry.expires) {
+		return entry.hosts, nil
+	}
+
+	var hosts []string
+	var err error
+	prefix, name, _ := strings.Cut(ref, ":")
+	if name == "" {
+		return nil, fmt.Errorf("Invalid host %q: name to look up is required after %s: prefix", ref, prefix)
+	}
+	switch prefix {
+	case "srv":
+		hosts, err = discoverSRV(name)
+	case "consul":
+		hosts, err = discoverConsul(name)
+	case "etcd":
+		hosts, err = discoverEtcd(name)
+	default:
+		return []string{ref}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to look up hosts for %s: %w", ref, err)
+	}
+	sort.Strings(hosts)
+	discoveredHosts.entries[ref] = discoveryEntry{hosts: hosts, expires: time.Now().Add(ttl)}
+	return hosts, nil
+}
+
+// discoverSRV returns host:port values for each target of the DNS SRV record
+// name.
+func discoverSRV(name string) ([]string, error) {
+	_, records, err := lookupSRV("", "", name)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(records))
+	for _, rec := range records {
+		host := strings.TrimSuffix(rec.Target, ".")
+		hosts = append(hosts, net.JoinHostPort(host, strconv.Itoa(int(rec.Port))))
+	}
+	return hosts, nil
+}
+
+// discoverConsul returns host:port values for each instance of a Consul
+// service which is passing its health checks. The spec is a service name,
+// optionally followed by a query string of additional API parameters.
+func discoverConsul(spec string) ([]string, error) {
+	service, query, _ := strings.Cut(spec, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("passing", "true")
+	addr := os.Getenv("CONSUL_HTTP_ADDR")
+	if addr == "" {
+		addr = "http://127.0.0.1:8500"
+	} else if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/health/service/"+url.PathEscape(service)+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	body, err := discoveryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Node struct {
+			Address string
+		}
+		Service struct {
+			Address string
+			Port    int
+		}
+	}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("unexpected response from Consul: %w", err)
+	}
+	hosts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		if entry.Service.Port > 0 {
+			host = net.JoinHostPort(host, strconv.Itoa(entry.Service.Port))
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// discoverEtcd returns the hosts stored in etcd under key. If key ends in a
+// slash, each key under that prefix contributes its value as one host.
+// Otherwise, the value of key itself is split on commas and newlines.
+func discoverEtcd(key string) ([]string, error) {
+	rangeReq := map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(key))}
+	isPrefix := strings.HasSuffix(key, "/")
+	if isPrefix {
+		// The range end for a prefix is the prefix with its last byte incremented
+		end := []byte(key)
+		end[len(end)-1]++
+		rangeReq["range_end"] = base64.StdEncoding.EncodeToString(end)
+	}
+	reqBody, err := json.Marshal(rangeReq)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := strings.Split(os.Getenv("ETCDCTL_ENDPOINTS"), ",")
+	if endpoints[0] == "" {
+		endpoints = []string{"http://127.0.0.1:2379"}
+	}
+	var body []byte
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		var req *http.Request
+		if req, err = http.NewRequest(http.MethodPost, strings.TrimRight(endpoint, "/")+"/v3/kv/range", bytes.NewReader(reqBody)); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if body, err = discoveryRequest(req); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		KVs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unexpected response from etcd: %w", err)
+	} else if len(resp.KVs) == 0 {
+		return nil, errors.New("key not found in etcd")
+	}
+	var hosts []string
+	for _, kv := range resp.KVs {
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected response from etcd: %w", err)
+		}
+		for _, host := range strings.FieldsFunc(string(value), func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+			if isPrefix {
+				break // with a prefix, each key's value is one host
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// discoveryRequest performs req, returning the response body if the status
+// code indicates success.
+func discoveryRequest(req *http.Request) ([]byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return body, nil
+}
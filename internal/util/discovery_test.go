@@ -0,0 +1,158 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetDiscoveryCache clears any cached service discovery results.
+func resetDiscoveryCache() {
+	discoveredHosts.Lock()
+	discoveredHosts.entries = make(map[string]discoveryEntry)
+	discoveredHosts.Unlock()
+}
+
+func TestIsDiscoveryReference(t *testing.T) {
+	cases := map[string]bool{
+		"srv:_mysql._tcp.example.com": true,
+		"consul:mysql":                true,
+		"etcd:/skeema/hosts":          true,
+		"db.example.com":              false,
+		"127.0.0.1:3306":              false,
+		"srv.example.com":             false,
+	}
+	for input, expected := range cases {
+		if actual := IsDiscoveryReference(input); actual != expected {
+			t.Errorf("Expected IsDiscoveryReference(%q) to return %t, instead found %t", input, expected, actual)
+		}
+	}
+}
+
+func TestDiscoverHostsSRV(t *testing.T) {
+	resetDiscoveryCache()
+	defer resetDiscoveryCache()
+	var lookups int
+	origLookupSRV := lookupSRV
+	defer func() { lookupSRV = origLookupSRV }()
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		lookups++
+		if name != "_mysql._tcp.shard.example.com" {
+			return "", nil, errors.New("no such host")
+		}
+		return name, []*net.SRV{
+			{Target: "db2.example.com.", Port: 3307},
+			{Target: "db1.example.com.", Port: 3306},
+		}, nil
+	}
+
+	hosts, err := DiscoverHosts("srv:_mysql._tcp.shard.example.com", time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error from DiscoverHosts: %v", err)
+	}
+	if expected := "db1.example.com:3306,db2.example.com:3307"; strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, instead found %v", expected, hosts)
+	}
+
+	// Subsequent call within TTL should be served from cache
+	if _, err := DiscoverHosts("srv:_mysql._tcp.shard.example.com", time.Minute); err != nil || lookups != 1 {
+		t.Errorf("Expected cached result, instead found err=%v, lookups=%d", err, lookups)
+	}
+
+	// Zero TTL results in a fresh lookup each time
+	DiscoverHosts("srv:_mysql._tcp.other.example.com", 0)
+	if _, err := DiscoverHosts("srv:_mysql._tcp.other.example.com", 0); err == nil || lookups != 3 {
+		t.Errorf("Expected uncached error, instead found err=%v, lookups=%d", err, lookups)
+	}
+
+	if _, err := DiscoverHosts("srv:", time.Minute); err == nil {
+		t.Error("Expected error for blank SRV name, but err was nil")
+	}
+}
+
+func TestDiscoverHostsConsul(t *testing.T) {
+	resetDiscoveryCache()
+	defer resetDiscoveryCache()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/health/service/mysql" || r.URL.Query().Get("passing") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("tag") == "primary" {
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 3306}}]`))
+			return
+		}
+		w.Write([]byte(`[{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 3306}}, {"Node": {"Address": "10.0.0.9"}, "Service": {"Address": "replica.db", "Port": 3307}}]`))
+	}))
+	defer server.Close()
+	t.Setenv("CONSUL_HTTP_ADDR", strings.TrimPrefix(server.URL, "http://"))
+	t.Setenv("CONSUL_HTTP_TOKEN", "test-token")
+
+	hosts, err := DiscoverHosts("consul:mysql", time.Minute)
+	if expected := "10.0.0.1:3306,replica.db:3307"; err != nil || strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, instead found %v, err=%v", expected, hosts, err)
+	}
+	hosts, err = DiscoverHosts("consul:mysql?tag=primary", time.Minute)
+	if expected := "10.0.0.1:3306"; err != nil || strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, instead found %v, err=%v", expected, hosts, err)
+	}
+	if _, err := DiscoverHosts("consul:postgres", time.Minute); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Expected HTTP 404 error, instead found %v", err)
+	}
+	t.Setenv("CONSUL_HTTP_TOKEN", "wrong-token")
+	resetDiscoveryCache()
+	if _, err := DiscoverHosts("consul:mysql", time.Minute); err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("Expected HTTP 403 error, instead found %v", err)
+	}
+}
+
+func TestDiscoverHostsEtcd(t *testing.T) {
+	resetDiscoveryCache()
+	defer resetDiscoveryCache()
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key      string `json:"key"`
+			RangeEnd string `json:"range_end"`
+		}
+		if r.URL.Path != "/v3/kv/range" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case req.Key == encode("/skeema/shards") && req.RangeEnd == "":
+			w.Write([]byte(`{"kvs": [{"value": "` + encode("db1:3306, db2:3306\ndb3:3306\n") + `"}]}`))
+		case req.Key == encode("/skeema/fleet/") && req.RangeEnd == encode("/skeema/fleet0"):
+			w.Write([]byte(`{"kvs": [{"value": "` + encode("db5\n") + `"}, {"value": "` + encode("db4") + `"}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+	// First endpoint is unreachable, and should be skipped in favor of the second
+	t.Setenv("ETCDCTL_ENDPOINTS", "http://127.0.0.1:1,"+server.URL)
+
+	hosts, err := DiscoverHosts("etcd:/skeema/shards", time.Minute)
+	if expected := "db1:3306,db2:3306,db3:3306"; err != nil || strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, instead found %v, err=%v", expected, hosts, err)
+	}
+	hosts, err = DiscoverHosts("etcd:/skeema/fleet/", time.Minute)
+	if expected := "db4,db5"; err != nil || strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, instead found %v, err=%v", expected, hosts, err)
+	}
+	if _, err := DiscoverHosts("etcd:/skeema/missing", time.Minute); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected key not found error, instead found %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/skeema/skeema/internal/shellout"
+)
+
+// GitChangedFiles returns the set of files in the git repository containing
+// dirPath which differ between the supplied git ref and the current working
+// tree. This includes staged changes, unstaged changes, and untracked files
+// which aren't excluded by .gitignore. The returned map is keyed by absolute
+// file path, with any symlinks evaluated for files which still exist.
+func GitChangedFiles(dirPath, ref string) (map[string]bool, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("Invalid git ref %q", ref)
+	}
+	// Output is split on newlines only, since paths may contain other whitespace.
+	// core.quotePath is disabled to prevent git from escaping non-ASCII paths.
+	run := func(commandLine string) ([]string, error) {
+		c := shellout.New(commandLine).WithWorkingDir(dirPath).WithVariablesStrict(map[string]string{"REF": ref})
+		out, err := c.RunCapture()
+		var lines []string
+		for _, line := range strings.Split(out, "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines, err
+	}
+	toplevel, err := run("git rev-parse --show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to determine git repository for %s: %w", dirPath, err)
+	} else if len(toplevel) != 1 {
+		return nil, fmt.Errorf("Unable to determine git repository for %s: unexpected output %q", dirPath, toplevel)
+	}
+	if _, err := run("git rev-parse --verify --quiet {REF}"); err != nil {
+		return nil, fmt.Errorf("Unable to resolve git ref %q: %w", ref, err)
+	}
+	diffFiles, err := run("git -c core.quotePath=false diff --name-only --no-renames {REF} --")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to obtain git diff against %s: %w", ref, err)
+	}
+	untrackedFiles, err := run("git -c core.quotePath=false ls-files --others --exclude-standard --full-name")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list untracked files: %w", err)
+	}
+
+	changed := make(map[string]bool, len(diffFiles)+len(untrackedFiles))
+	for _, relPath := range append(diffFiles, untrackedFiles...) {
+		absPath := filepath.Join(toplevel[0], filepath.FromSlash(relPath))
+		if realPath, err := filepath.EvalSymlinks(absPath); err == nil {
+			absPath = realPath
+		}
+		changed[absPath] = true
+	}
+	return changed, nil
+}
+
+// GitHeadSHA returns the full commit SHA of HEAD, for the git repository
+// containing dirPath.
+func GitHeadSHA(dirPath string) (string, error) {
+	out, err := shellout.New("git rev-parse --verify --quiet HEAD").WithWorkingDir(dirPath).RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("Unable to determine git HEAD for %s: %w", dirPath, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GitFilesAtRef returns the contents of the files directly within dirPath, as
+// of the supplied git ref, keyed by file name. Subdirectories and symlinks are
+// not included. If dirPath did not exist in the repository at that ref, an
+// empty map is returned.
+func GitFilesAtRef(dirPath, ref string) (map[string]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("Invalid git ref %q", ref)
+	}
+	run := func(commandLine string, vars map[string]string) (string, error) {
+		c := shellout.New(commandLine).WithWorkingDir(dirPath).WithVariablesStrict(vars)
+		return c.RunCapture()
+	}
+	vars := map[string]string{"REF": ref}
+	if _, err := run("git rev-parse --verify --quiet {REF}", vars); err != nil {
+		return nil, fmt.Errorf("Unable to resolve git ref %q: %w", ref, err)
+	}
+	prefix, err := run("git rev-parse --show-prefix", vars)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to determine git repository for %s: %w", dirPath, err)
+	}
+	vars["TREE"] = ref + ":" + strings.TrimSpace(prefix)
+	exists := shellout.New("git cat-file -e {TREE}").WithWorkingDir(dirPath).WithVariablesStrict(vars)
+	if _, _, err := exists.RunCaptureSeparate(); err != nil {
+		return map[string]string{}, nil // dir did not exist at this ref
+	}
+
+	// Each line of ls-tree output is "<mode> <type> <object>\t<name>". Without
+	// --full-tree, ls-tree would filter entries by the working dir's prefix.
+	out, err := run("git -c core.quotePath=false ls-tree --full-tree {TREE}", vars)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list files at git ref %q: %w", ref, err)
+	}
+	files := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		meta, name, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		vars["OBJECT"] = fields[2]
+		contents, err := run("git cat-file blob {OBJECT}", vars)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read %s at git ref %q: %w", name, ref, err)
+		}
+		files[name] = contents
+	}
+	return files, nil
+}
+
+// GitCheckout initializes a new git repository in destDir, which must already
+// exist, and checks out the commit at the supplied ref of the repository at
+// repoURL, without fetching any other history. repoURL may be any URL or local
+// path supported by git fetch. The full commit SHA of the checked-out commit is
+// returned.
+func GitCheckout(repoURL, ref, destDir string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("Invalid git ref %q", ref)
+	} else if repoURL == "" || strings.HasPrefix(repoURL, "-") {
+		return "", fmt.Errorf("Invalid git repository %q", repoURL)
+	}
+	vars := map[string]string{"REPO": repoURL, "REF": ref}
+	commandLines := []string{
+		"git init --quiet",
+		"git fetch --quiet --depth 1 --no-tags {REPO} {REF}",
+		"git -c advice.detachedHead=false checkout --quiet FETCH_HEAD",
+	}
+	for _, commandLine := range commandLines {
+		c := shellout.New(commandLine).WithWorkingDir(destDir).WithVariablesStrict(vars)
+		if _, stderr, err := c.RunCaptureSeparate(); err != nil {
+			return "", fmt.Errorf("Unable to check out ref %q of %s: %w: %s", ref, repoURL, err, strings.TrimSpace(stderr))
+		}
+	}
+	return GitHeadSHA(destDir)
+}
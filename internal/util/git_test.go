@@ -0,0 +1,195 @@
+package util
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available on PATH")
+	}
+	repoDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error from EvalSymlinks: %v", err)
+	}
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error from git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	writeFile := func(path, contents string) {
+		t.Helper()
+		path = filepath.Join(repoDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unexpected error from MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Unexpected error from WriteFile: %v", err)
+		}
+	}
+
+	runGit("init", "-q")
+	writeFile("mydb/users.sql", "CREATE TABLE users (id int);\n")
+	writeFile("mydb/posts.sql", "CREATE TABLE posts (id int);\n")
+	writeFile("mydb/.skeema", "schema=mydb\n")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+	if sha, err := GitHeadSHA(filepath.Join(repoDir, "mydb")); err != nil || len(sha) != 40 {
+		t.Errorf("Unexpected return from GitHeadSHA: %q, %v", sha, err)
+	}
+
+	writeFile("mydb/users.sql", "CREATE TABLE users (id bigint);\n")
+	writeFile("mydb/new table.sql", "CREATE TABLE new_table (id int);\n")
+	changed, err := GitChangedFiles(filepath.Join(repoDir, "mydb"), "HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error from GitChangedFiles: %v", err)
+	}
+	expected := []string{"mydb/users.sql", "mydb/new table.sql"}
+	if len(changed) != len(expected) {
+		t.Errorf("Expected %d changed files, instead found %d: %v", len(expected), len(changed), changed)
+	}
+	for _, relPath := range expected {
+		if !changed[filepath.Join(repoDir, relPath)] {
+			t.Errorf("Expected %s to be included in changed files, but it was not: %v", relPath, changed)
+		}
+	}
+
+	for _, badRef := range []string{"", "--output=foo", "no-such-branch"} {
+		if _, err := GitChangedFiles(repoDir, badRef); err == nil {
+			t.Errorf("Expected error from GitChangedFiles with ref %q, but it was nil", badRef)
+		}
+	}
+	if _, err := GitChangedFiles(t.TempDir(), "HEAD"); err == nil {
+		t.Error("Expected error from GitChangedFiles outside of a git repo, but it was nil")
+	}
+	if _, err := GitHeadSHA(t.TempDir()); err == nil {
+		t.Error("Expected error from GitHeadSHA outside of a git repo, but it was nil")
+	}
+}
+
+func TestGitFilesAtRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available on PATH")
+	}
+	repoDir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error from git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	writeFile := func(path, contents string) {
+		t.Helper()
+		path = filepath.Join(repoDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unexpected error from MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Unexpected error from WriteFile: %v", err)
+		}
+	}
+
+	runGit("init", "-q")
+	writeFile("mydb/users.sql", "CREATE TABLE users (id int);\n")
+	writeFile("mydb/my posts.sql", "CREATE TABLE posts (id int);\n")
+	writeFile("mydb/sub/other.sql", "CREATE TABLE other (id int);\n")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+	writeFile("mydb/users.sql", "CREATE TABLE users (id bigint);\n")
+	writeFile("newdb/foo.sql", "CREATE TABLE foo (id int);\n")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "second")
+
+	files, err := GitFilesAtRef(filepath.Join(repoDir, "mydb"), "HEAD~1")
+	if err != nil {
+		t.Fatalf("Unexpected error from GitFilesAtRef: %v", err)
+	}
+	expected := map[string]string{
+		"users.sql":    "CREATE TABLE users (id int);\n",
+		"my posts.sql": "CREATE TABLE posts (id int);\n",
+	}
+	if len(files) != len(expected) {
+		t.Errorf("Expected %d files, instead found %d: %v", len(expected), len(files), files)
+	}
+	for name, contents := range expected {
+		if files[name] != contents {
+			t.Errorf("Expected %s to have contents %q, instead found %q", name, contents, files[name])
+		}
+	}
+
+	// Dir which did not exist at the ref
+	if files, err := GitFilesAtRef(filepath.Join(repoDir, "newdb"), "HEAD~1"); err != nil || len(files) != 0 {
+		t.Errorf("Unexpected result from GitFilesAtRef for new dir: %v, %v", files, err)
+	}
+
+	for _, badRef := range []string{"", "--output=foo", "no-such-branch"} {
+		if _, err := GitFilesAtRef(repoDir, badRef); err == nil {
+			t.Errorf("Expected error from GitFilesAtRef with ref %q, but it was nil", badRef)
+		}
+	}
+}
+
+func TestGitCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available on PATH")
+	}
+	repoDir := t.TempDir()
+	runGit := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Unexpected error from git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	runGit("init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(repoDir, "users.sql"), []byte("CREATE TABLE users (id int);\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error from WriteFile: %v", err)
+	}
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+	firstSHA := runGit("rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repoDir, "users.sql"), []byte("CREATE TABLE users (id bigint);\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error from WriteFile: %v", err)
+	}
+	runGit("commit", "-q", "-a", "-m", "second")
+	secondSHA := runGit("rev-parse", "HEAD")
+
+	cases := map[string]string{
+		"main":   secondSHA,
+		"HEAD":   secondSHA,
+		firstSHA: firstSHA,
+	}
+	for ref, expectedSHA := range cases {
+		destDir := t.TempDir()
+		if sha, err := GitCheckout("file://"+repoDir, ref, destDir); err != nil || sha != expectedSHA {
+			t.Errorf("Unexpected return from GitCheckout with ref %q: %q, %v", ref, sha, err)
+		}
+		contents, err := os.ReadFile(filepath.Join(destDir, "users.sql"))
+		if err != nil {
+			t.Errorf("Unexpected error reading checked-out file: %v", err)
+		} else if expectedSHA == firstSHA && !strings.Contains(string(contents), "id int") {
+			t.Errorf("Unexpected checked-out file contents for ref %q: %s", ref, contents)
+		}
+	}
+
+	for _, badRef := range []string{"", "--upload-pack=foo", "no-such-branch"} {
+		if _, err := GitCheckout("file://"+repoDir, badRef, t.TempDir()); err == nil {
+			t.Errorf("Expected error from GitCheckout with ref %q, but it was nil", badRef)
+		}
+	}
+	if _, err := GitCheckout("--upload-pack=foo", "main", t.TempDir()); err == nil {
+		t.Error("Expected error from GitCheckout with invalid repo, but it was nil")
+	}
+}
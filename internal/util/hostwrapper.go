@@ -0,0 +1,206 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HostEntry represents a single database server returned by a host-wrapper
+// script. Scripts may emit a JSON array of objects with these fields, instead
+// of a plain delimited list of hosts. Role and Shard are conventional labels;
+// any others may be supplied in Labels.
+type HostEntry struct {
+	Host   string            `json:"host"`
+	Port   int               `json:"port,omitempty"`
+	Role   string            `json:"role,omitempty"`
+	Shard  string            `json:"shard,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// String returns the entry in host or host:port format.
+func (entry HostEntry) String() string {
+	if entry.Port == 0 {
+		return entry.Host
+	}
+	return entry.Host + ":" + strconv.Itoa(entry.Port)
+}
+
+// Label returns the value of the supplied label for the entry. The role and
+// shard labels are obtained from the corresponding fields, unless overridden
+// by Labels.
+func (entry HostEntry) Label(name string) string {
+	if value, ok := entry.Labels[name]; ok {
+		return value
+	}
+	switch name {
+	case "role":
+		return entry.Role
+	case "shard":
+		return entry.Shard
+	}
+	return ""
+}
+
+// Matches returns true if the entry's labels are equal to every key=value
+// pair in filter. A nil or empty filter matches all entries.
+func (entry HostEntry) Matches(filter map[string]string) bool {
+	for name, value := range filter {
+		if entry.Label(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseHostFilter parses a comma-separated list of key=value pairs, for use
+// with HostEntry.Matches.
+func ParseHostFilter(value string) (map[string]string, error) {
+	filter := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			return nil, fmt.Errorf("%q is not in key=value format", pair)
+		}
+		filter[k] = strings.TrimSpace(v)
+	}
+	return filter, nil
+}
+
+// ParseHostWrapperOutput converts the STDOUT of a host-wrapper script into
+// host entries. If the output is a JSON array, each element must be an object
+// with at least a host field, and an error is returned if any host is
+// malformed or duplicated; the returned bool is true in this case. Otherwise,
+// the output is treated as a list of hosts delimited by newlines, commas, tabs,
+// or spaces (in that order of precedence), parsed exactly as by
+// shellout.Command.RunCaptureSplit, except that duplicate hosts are removed.
+func ParseHostWrapperOutput(output string) (entries []HostEntry, isJSON bool, err error) {
+	output = strings.TrimSpace(output)
+	if !strings.HasPrefix(output, "[") {
+		var delimiter string
+		for _, candidate := range []string{"\n", ",", "\t", " "} {
+			if strings.Contains(output, candidate) {
+				delimiter = candidate
+				break
+			}
+		}
+		tokens := []string{output}
+		if delimiter != "" {
+			tokens = strings.Split(output, delimiter)
+		}
+		seen := make(map[string]bool, len(tokens))
+		for _, token := range tokens {
+			if token = strings.TrimSpace(token); token != "" && !seen[token] {
+				entries = append(entries, HostEntry{Host: token})
+				seen[token] = true
+			}
+		}
+		return entries, false, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(output))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, true, fmt.Errorf("Unable to parse JSON host list: %w", err)
+	}
+	seen := make(map[string]bool, len(entries))
+	for n, entry := range entries {
+		if entry.Host == "" {
+			return nil, true, fmt.Errorf("Host list entry %d is missing a host", n+1)
+		} else if strings.ContainsAny(entry.Host, " \t\r\n,") {
+			return nil, true, fmt.Errorf("Host list entry %q contains invalid characters", entry.Host)
+		} else if entry.Port < 0 || entry.Port > 65535 {
+			return nil, true, fmt.Errorf("Host list entry %q has invalid port %d", entry.Host, entry.Port)
+		}
+		if seen[entry.String()] {
+			return nil, true, fmt.Errorf("Host list contains duplicate entry %q", entry.String())
+		}
+		seen[entry.String()] = true
+	}
+	return entries, true, nil
+}
+
+// hostWrapperCacheDir returns the directory used for caching host-wrapper
+// output across invocations. It is a package var so that test logic may
+// substitute a temporary directory.
+var hostWrapperCacheDir = func() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "skeema", "host-wrapper"), nil
+}
+
+// hostWrapperCachePath returns the cache file path for the supplied command
+// line, which should have any variables already expanded.
+func hostWrapperCachePath(commandLine string) (string, error) {
+	dir, err := hostWrapperCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(commandLine))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])), nil
+}
+
+// ReadHostWrapperCache returns the cached output of the supplied host-wrapper
+// command line, if it was stored within ttl ago. The boolean return value
+// indicates whether a usable cached result was found.
+func ReadHostWrapperCache(commandLine string, ttl time.Duration) (string, bool) {
+	path, err := hostWrapperCachePath(commandLine)
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return "", false
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	// The first line of the file contains the command line, to guard against
+	// hash collisions
+	header, output, _ := bytes.Cut(contents, []byte("\n"))
+	if string(header) != strconv.Quote(commandLine) {
+		return "", false
+	}
+	return string(output), true
+}
+
+// WriteHostWrapperCache stores output of the supplied host-wrapper command
+// line, for later use by ReadHostWrapperCache.
+func WriteHostWrapperCache(commandLine, output string) error {
+	path, err := hostWrapperCachePath(commandLine)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(strconv.Quote(commandLine) + "\n" + output)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("Unable to write host-wrapper cache: %w", err)
+	}
+	return nil
+}
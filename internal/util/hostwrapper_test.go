@@ -0,0 +1,136 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHostWrapperOutput(t *testing.T) {
+	hostStrings := func(entries []HostEntry) string {
+		strs := make([]string, len(entries))
+		for n, entry := range entries {
+			strs[n] = entry.String()
+		}
+		return strings.Join(strs, ",")
+	}
+
+	// Plain delimited output
+	cases := map[string]string{
+		"":                             "",
+		"db1\n":                        "db1",
+		"db1\ndb2:3307\n":              "db1,db2:3307",
+		"db1, db2,,db3":                "db1,db2,db3",
+		"db1\tdb2":                     "db1,db2",
+		"  db1 db2  ":                  "db1,db2",
+		"db1.example.com:3306\r\n":     "db1.example.com:3306",
+		"db1,db2\ndb3":                 "db1,db2,db3", // newline takes precedence, so "db1,db2" is a single host
+		"db1\ndb1\ndb2":                "db1,db2",     // duplicates removed
+		`[{"host": "db1"}]`:            "db1",
+		`[{"host": "db1", "port": 0}]`: "db1",
+	}
+	for input, expected := range cases {
+		entries, _, err := ParseHostWrapperOutput(input)
+		if expected == "" && input != "" {
+			if err == nil {
+				t.Errorf("Expected error parsing %q, but err was nil", input)
+			}
+		} else if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", input, err)
+		} else if actual := hostStrings(entries); actual != expected {
+			t.Errorf("Expected %q to parse to %q, instead found %q", input, expected, actual)
+		}
+	}
+
+	// JSON output with labels
+	input := `[
+		{"host": "db1", "port": 3307, "role": "primary", "shard": "1"},
+		{"host": "db2", "role": "replica", "shard": "1", "labels": {"region": "east", "role": "backup"}}
+	]`
+	entries, isJSON, err := ParseHostWrapperOutput(input)
+	if err != nil || !isJSON || hostStrings(entries) != "db1:3307,db2" {
+		t.Fatalf("Unexpected result from ParseHostWrapperOutput: %+v, %v", entries, err)
+	}
+	if entries[0].Label("role") != "primary" || entries[1].Label("role") != "backup" || entries[1].Label("region") != "east" || entries[0].Label("region") != "" {
+		t.Errorf("Unexpected label values: %+v", entries)
+	}
+	filterCases := map[string]string{
+		"":                     "db1:3307,db2",
+		"shard=1":              "db1:3307,db2",
+		"role=primary":         "db1:3307",
+		" region = east ":      "db2",
+		"shard=1,region=west":  "",
+		"role=primary,shard=2": "",
+	}
+	for filterValue, expected := range filterCases {
+		filter, err := ParseHostFilter(filterValue)
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseHostFilter(%q): %v", filterValue, err)
+		}
+		var matched []HostEntry
+		for _, entry := range entries {
+			if entry.Matches(filter) {
+				matched = append(matched, entry)
+			}
+		}
+		if actual := hostStrings(matched); actual != expected {
+			t.Errorf("Expected filter %q to match %q, instead found %q", filterValue, expected, actual)
+		}
+	}
+	for _, filterValue := range []string{"role", "=primary", "role=primary,shard"} {
+		if _, err := ParseHostFilter(filterValue); err == nil {
+			t.Errorf("Expected error from ParseHostFilter(%q), but err was nil", filterValue)
+		}
+	}
+
+	// Invalid JSON output
+	for _, input := range []string{
+		`[{"host": "db1"`,
+		`[{"port": 3306}]`,
+		`[{"host": "db1", "hostname": "db2"}]`,
+		`[{"host": "db1", "port": 70000}]`,
+		`[{"host": "db1 db2"}]`,
+		`[{"host": "db1", "port": 3306}, {"host": "db1", "port": 3306}]`,
+	} {
+		if _, _, err := ParseHostWrapperOutput(input); err == nil {
+			t.Errorf("Expected error parsing %q, but err was nil", input)
+		}
+	}
+}
+
+func TestHostWrapperCache(t *testing.T) {
+	tempDir := t.TempDir()
+	origCacheDir := hostWrapperCacheDir
+	defer func() { hostWrapperCacheDir = origCacheDir }()
+	hostWrapperCacheDir = func() (string, error) {
+		return filepath.Join(tempDir, "host-wrapper"), nil
+	}
+
+	if _, ok := ReadHostWrapperCache("/bin/lookup-hosts prod", time.Minute); ok {
+		t.Error("Expected cache miss before any writes, but found hit")
+	}
+	if err := WriteHostWrapperCache("/bin/lookup-hosts prod", "db1\ndb2\n"); err != nil {
+		t.Fatalf("Unexpected error from WriteHostWrapperCache: %v", err)
+	}
+	if output, ok := ReadHostWrapperCache("/bin/lookup-hosts prod", time.Minute); !ok || output != "db1\ndb2\n" {
+		t.Errorf("Unexpected result from ReadHostWrapperCache: %q, %t", output, ok)
+	}
+	if _, ok := ReadHostWrapperCache("/bin/lookup-hosts staging", time.Minute); ok {
+		t.Error("Expected cache miss for different command line, but found hit")
+	}
+
+	// Backdate the cache file to confirm expiration
+	path, _ := hostWrapperCachePath("/bin/lookup-hosts prod")
+	old := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Unexpected error from Chtimes: %v", err)
+	}
+	if _, ok := ReadHostWrapperCache("/bin/lookup-hosts prod", time.Minute); ok {
+		t.Error("Expected cache miss for expired entry, but found hit")
+	}
+	if _, ok := ReadHostWrapperCache("/bin/lookup-hosts prod", time.Hour); !ok {
+		t.Error("Expected cache hit with longer TTL, but found miss")
+	}
+}
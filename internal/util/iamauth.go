@@ -0,0 +1,81 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// iamAuthInstances tracks how each Instance using cloud IAM authentication was
+// configured, so that related Instances may be configured the same way.
+var iamAuthInstances = struct {
+	sync.Mutex
+	enablers map[*tengo.Instance]func(*tengo.Instance)
+}{enablers: make(map[*tengo.Instance]func(*tengo.Instance))}
+
+// enableIAMAuth calls enable on instance, and remembers enable for use by
+// InheritIAMAuth.
+func enableIAMAuth(instance *tengo.Instance, enable func(*tengo.Instance)) {
+	iamAuthInstances.Lock()
+	iamAuthInstances.enablers[instance] = enable
+	iamAuthInstances.Unlock()
+	enable(instance)
+}
+
+// InheritIAMAuth configures dest to use the same type of cloud IAM
+// authentication as source, if source uses any. This is useful when connecting
+// to other members of source's replication topology.
+func InheritIAMAuth(source, dest *tengo.Instance) {
+	iamAuthInstances.Lock()
+	enable, ok := iamAuthInstances.enablers[source]
+	iamAuthInstances.Unlock()
+	if ok {
+		enableIAMAuth(dest, enable)
+	}
+}
+
+// cachedTokenFunc returns a function which obtains auth tokens from fetch,
+// suitable for use with tengo.Instance.SetPasswordFunc. Each token is cached and
+// reused for subsequent connections for the duration returned by fetch, which
+// should be somewhat shorter than the token's validity period, after which a
+// new token is fetched automatically. Token expiration does not affect
+// connections which were already established.
+func cachedTokenFunc(fetch func() (string, time.Duration, error)) func() (string, error) {
+	var m sync.Mutex
+	var token string
+	var expires time.Time
+	return func() (string, error) {
+		m.Lock()
+		defer m.Unlock()
+		if token != "" && time.Now().Before(expires) {
+			return token, nil
+		}
+		newToken, reuse, err := fetch()
+		if err != nil {
+			return "", err
+		}
+		token, expires = newToken, time.Now().Add(reuse)
+		return token, nil
+	}
+}
+
+// shelloutTokenFunc returns a cachedTokenFunc which obtains each auth token
+// from the output of commandLine, reusing it for the supplied duration.
+func shelloutTokenFunc(commandLine string, variables map[string]string, reuse time.Duration, description string) func() (string, error) {
+	return cachedTokenFunc(func() (string, time.Duration, error) {
+		c := shellout.New(commandLine).WithVariablesStrict(variables)
+		out, err := c.RunCapture()
+		if err != nil {
+			return "", 0, fmt.Errorf("Unable to generate %s using `%s`: %w", description, c, err)
+		}
+		token := strings.TrimSpace(out)
+		if token == "" {
+			return "", 0, fmt.Errorf("Unable to generate %s: `%s` returned no output", description, c)
+		}
+		return token, reuse, nil
+	})
+}
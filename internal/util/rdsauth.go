@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// rdsAuthTokenCommand is the command-line used to generate RDS IAM auth tokens.
+// It is a package var so that test logic may substitute a stub.
+var rdsAuthTokenCommand = "aws rds generate-db-auth-token --hostname {HOST} --port {PORT} --username {USER}"
+
+// rdsAuthTokenReuse is how long a generated token is reused for new
+// connections. Tokens are valid for 15 minutes; a shorter reuse period ensures
+// a token never expires mid-handshake. Expiration does not affect connections
+// which were already established.
+const rdsAuthTokenReuse = 10 * time.Minute
+
+// EnableRDSAuth configures instance to authenticate using RDS IAM auth tokens
+// instead of a password. Tokens are generated by shelling out to the AWS CLI,
+// which handles the full AWS credential chain (environment vars, shared config
+// and SSO profiles, instance and container roles, etc). The region and profile
+// args may be blank to use the AWS CLI's defaults, although for standard RDS
+// endpoint hostnames the region is obtained from the hostname. Tokens are
+// regenerated automatically as they approach expiration.
+func EnableRDSAuth(instance *tengo.Instance, region, profile string) {
+	enableIAMAuth(instance, func(inst *tengo.Instance) {
+		inst.SetPasswordFunc(rdsAuthTokenFunc(inst.Host, inst.Port, inst.User, region, profile))
+	})
+}
+
+// rdsAuthTokenFunc returns a function which generates RDS IAM authentication
+// tokens for the supplied host, port, and user.
+func rdsAuthTokenFunc(host string, port int, user, region, profile string) func() (string, error) {
+	if region == "" {
+		region = rdsRegionFromHost(host)
+	}
+	commandLine := rdsAuthTokenCommand
+	if region != "" {
+		commandLine += " --region {REGION}"
+	}
+	if profile != "" {
+		commandLine += " --profile {PROFILE}"
+	}
+	variables := map[string]string{
+		"HOST":    host,
+		"PORT":    strconv.Itoa(port),
+		"USER":    user,
+		"REGION":  region,
+		"PROFILE": profile,
+	}
+	return shelloutTokenFunc(commandLine, variables, rdsAuthTokenReuse, fmt.Sprintf("RDS IAM auth token for %s@%s", user, host))
+}
+
+// rdsRegionFromHost returns the AWS region embedded in a standard RDS endpoint
+// hostname, for example "us-east-1" for "mydb.abc123.us-east-1.rds.amazonaws.com".
+// A blank string is returned for other hostnames.
+func rdsRegionFromHost(host string) string {
+	prefix, ok := strings.CutSuffix(strings.ToLower(host), ".rds.amazonaws.com")
+	if !ok {
+		prefix, ok = strings.CutSuffix(strings.ToLower(host), ".rds.amazonaws.com.cn")
+	}
+	lastDot := strings.LastIndexByte(prefix, '.')
+	if !ok || lastDot < 0 {
+		return ""
+	}
+	return prefix[lastDot+1:]
+}
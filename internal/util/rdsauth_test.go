@@ -0,0 +1,81 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestRDSRegionFromHost(t *testing.T) {
+	cases := map[string]string{
+		"mydb.abc123.us-east-1.rds.amazonaws.com":              "us-east-1",
+		"MyCluster.cluster-ro-xyz.EU-WEST-2.rds.amazonaws.com": "eu-west-2",
+		"mydb.abc123.cn-north-1.rds.amazonaws.com.cn":          "cn-north-1",
+		"rds.amazonaws.com":                                    "",
+		"mydb.example.com":                                     "",
+		"":                                                     "",
+	}
+	for input, expected := range cases {
+		if actual := rdsRegionFromHost(input); actual != expected {
+			t.Errorf("Expected rdsRegionFromHost(%q) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestEnableRDSAuth(t *testing.T) {
+	origCommand := rdsAuthTokenCommand
+	defer func() {
+		rdsAuthTokenCommand = origCommand
+	}()
+	rdsAuthTokenCommand = "echo {HOST}:{PORT}:{USER}"
+
+	inst, err := tengo.NewInstance("mysql", "app@tcp(mydb.abc123.us-east-1.rds.amazonaws.com:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	EnableRDSAuth(inst, "", "ci")
+	expected := "mydb.abc123.us-east-1.rds.amazonaws.com:3306:app --region us-east-1 --profile ci"
+	if token, err := inst.CurrentPassword(); err != nil || token != expected {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+
+	// Tokens should be reused until close to expiring
+	rdsAuthTokenCommand = "false"
+	if token, err := inst.CurrentPassword(); err != nil || token != expected {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+
+	// Related instances should inherit the region override and profile, but
+	// generate tokens specific to their own host
+	rdsAuthTokenCommand = "echo {HOST}:{PORT}:{USER}"
+	replica, err := tengo.NewInstance("mysql", "app@tcp(replica.abc123.us-west-2.rds.amazonaws.com:3307)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	InheritIAMAuth(inst, replica)
+	expected = "replica.abc123.us-west-2.rds.amazonaws.com:3307:app --region us-west-2 --profile ci"
+	if token, err := replica.CurrentPassword(); err != nil || token != expected {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+
+	// Instances without RDS auth should be unaffected
+	other, err := tengo.NewInstance("mysql", "app:pw@tcp(other.db.host:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	InheritIAMAuth(other, replica)
+	if token, err := other.CurrentPassword(); err != nil || token != "pw" {
+		t.Errorf("Unexpected return from CurrentPassword: %q, %v", token, err)
+	}
+
+	// Errors from the shellout should be returned
+	rdsAuthTokenCommand = "false"
+	failing, err := tengo.NewInstance("mysql", "app@tcp(fail.example.com:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	EnableRDSAuth(failing, "us-east-1", "")
+	if _, err := failing.CurrentPassword(); err == nil {
+		t.Error("Expected error from CurrentPassword, but err was nil")
+	}
+}
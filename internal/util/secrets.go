@@ -0,0 +1,156 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/shellout"
+)
+
+// Command-lines used to fetch secrets from AWS Secrets Manager and SSM Parameter
+// Store, respectively. They are package vars so that test logic may substitute
+// stubs.
+var (
+	awsSecretsManagerCommand = "aws secretsmanager get-secret-value --secret-id {ID} --query SecretString --output text"
+	awsParameterStoreCommand = "aws ssm get-parameter --name {ID} --with-decryption --query Parameter.Value --output text"
+)
+
+// awsSecrets caches secret values fetched from AWS, keyed by the full
+// command-line used to fetch them.
+var awsSecrets = struct {
+	sync.Mutex
+	values map[string]string
+}{values: make(map[string]string)}
+
+// secretPrefixes lists the supported prefixes for option values which refer to
+// secrets in an external secret store.
+var secretPrefixes = []string{vaultPrefix, "aws-sm:", "aws-ssm:"}
+
+// IsSecretReference returns true if value refers to a secret stored in an
+// external secret store, rather than being a literal value.
+func IsSecretReference(value string) bool {
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSecret returns the secret value referenced by ref, which may have any
+// of these formats:
+//   - "vault:path#key" for HashiCorp Vault; see vaultSecret
+//   - "aws-sm:id#key" for AWS Secrets Manager, where id is a secret name or ARN
+//   - "aws-ssm:name#key" for AWS Systems Manager Parameter Store
+//
+// If "#key" is omitted, defaultKey is used if the secret is a JSON object;
+// otherwise the entire secret value is returned. For AWS secret stores, values
+// are fetched by shelling out to the AWS CLI, using cfg's aws-region and
+// aws-profile options if set. Secrets are fetched only once per process.
+// If ref does not refer to a secret, it is returned as-is.
+func ResolveSecret(cfg *mybase.Config, ref, defaultKey string) (string, error) {
+	var commandLine string
+	if strings.HasPrefix(ref, vaultPrefix) {
+		return vaultSecret(ref, defaultKey)
+	} else if strings.HasPrefix(ref, "aws-sm:") {
+		commandLine = awsSecretsManagerCommand
+	} else if strings.HasPrefix(ref, "aws-ssm:") {
+		commandLine = awsParameterStoreCommand
+	} else {
+		return ref, nil
+	}
+
+	_, idAndKey, _ := strings.Cut(ref, ":")
+	id, key, hasKey := strings.Cut(idAndKey, "#")
+	if id == "" {
+		return "", fmt.Errorf("Invalid secret reference %q: secret name is required", ref)
+	}
+	variables := map[string]string{
+		"ID":      id,
+		"REGION":  cfg.Get("aws-region"),
+		"PROFILE": cfg.Get("aws-profile"),
+	}
+	if variables["REGION"] != "" {
+		commandLine += " --region {REGION}"
+	}
+	if variables["PROFILE"] != "" {
+		commandLine += " --profile {PROFILE}"
+	}
+	c := shellout.New(commandLine).WithVariablesStrict(variables)
+
+	awsSecrets.Lock()
+	defer awsSecrets.Unlock()
+	value, ok := awsSecrets.values[c.String()]
+	if !ok {
+		out, err := c.RunCapture()
+		if err != nil {
+			return "", fmt.Errorf("Unable to fetch secret %s using `%s`: %w", id, c, err)
+		}
+		value = strings.TrimRight(out, "\r\n")
+		awsSecrets.values[c.String()] = value
+	}
+
+	// Secret values which are JSON objects, such as those managed by RDS, are
+	// treated as a set of keys. Otherwise, the whole value is used.
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		if hasKey {
+			return "", fmt.Errorf("Secret %s is not a JSON object, so key %q cannot be used", id, key)
+		}
+		return value, nil
+	}
+	if !hasKey {
+		key = defaultKey
+	}
+	if str, ok := fields[key].(string); ok {
+		return str, nil
+	} else if _, ok := fields[key]; ok {
+		return "", fmt.Errorf("Secret %s key %q is not a string", id, key)
+	}
+	return "", fmt.Errorf("Secret %s does not contain key %q", id, key)
+}
+
+// credentialHelperResults caches credentials returned by credential helper
+// commands, keyed by the full command-line.
+var credentialHelperResults = struct {
+	sync.Mutex
+	creds map[string][2]string
+}{creds: make(map[string][2]string)}
+
+// CredentialHelper shells out to commandLine, which should print a JSON object
+// containing keys "user" (or "username") and "password" to STDOUT. Variables
+// are substituted into commandLine in the same manner as other external
+// commands. Either returned value may be blank if its key was omitted from the
+// output, in which case the caller should fall back to another source.
+// Each distinct command-line is executed only once per process.
+func CredentialHelper(commandLine string, variables map[string]string) (user, password string, err error) {
+	c, err := shellout.New(commandLine).WithVariables(variables)
+	if err != nil {
+		return "", "", err
+	}
+	credentialHelperResults.Lock()
+	defer credentialHelperResults.Unlock()
+	if creds, ok := credentialHelperResults.creds[c.String()]; ok {
+		return creds[0], creds[1], nil
+	}
+	out, err := c.RunCapture()
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to obtain credentials using credential-helper `%s`: %w", c, err)
+	}
+	var result struct {
+		User     string `json:"user"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		return "", "", fmt.Errorf("Unable to parse output of credential-helper `%s` as JSON: %w", c, err)
+	}
+	if result.User == "" {
+		result.User = result.Username
+	}
+	credentialHelperResults.creds[c.String()] = [2]string{result.User, result.Password}
+	return result.User, result.Password, nil
+}
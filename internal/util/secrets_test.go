@@ -0,0 +1,124 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+func TestResolveSecret(t *testing.T) {
+	origSM, origSSM := awsSecretsManagerCommand, awsParameterStoreCommand
+	defer func() {
+		awsSecretsManagerCommand, awsParameterStoreCommand = origSM, origSSM
+	}()
+
+	// Stub commands read secret values from files in a temp dir, named by secret
+	// ID. (JSON values can't be echoed directly since braces denote variables.)
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"rds-managed": `{"username": "admin", "password": "jsonpass", "port": 3306}`,
+		"plain":       "plainpass\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("Unable to write temp file: %v", err)
+		}
+	}
+	awsSecretsManagerCommand = "cat " + tempDir + "/{ID}"
+	awsParameterStoreCommand = "cat " + tempDir + "/{ID}"
+
+	cfg := mybase.SimpleConfig(map[string]string{"aws-region": "", "aws-profile": ""})
+	cases := []struct {
+		ref        string
+		defaultKey string
+		expected   string
+	}{
+		{"literal", "password", "literal"},
+		{"", "password", ""},
+		{"aws-sm:rds-managed", "username", "admin"},
+		{"aws-sm:rds-managed", "password", "jsonpass"},
+		{"aws-sm:rds-managed#username", "password", "admin"},
+		{"aws-ssm:plain", "password", "plainpass"},
+	}
+	for _, c := range cases {
+		if actual, err := ResolveSecret(cfg, c.ref, c.defaultKey); err != nil || actual != c.expected {
+			t.Errorf("Unexpected return from ResolveSecret(%q, %q): %q, %v", c.ref, c.defaultKey, actual, err)
+		}
+		if IsSecretReference(c.ref) == (c.ref == c.expected) {
+			t.Errorf("Unexpected return from IsSecretReference(%q)", c.ref)
+		}
+	}
+
+	badRefs := []string{
+		"aws-sm:",
+		"aws-sm:rds-managed#host", // missing key
+		"aws-sm:rds-managed#port", // not a string
+		"aws-ssm:plain#password",  // not JSON
+		"aws-ssm:nonexistent",     // command fails
+	}
+	for _, ref := range badRefs {
+		if _, err := ResolveSecret(cfg, ref, "password"); err == nil {
+			t.Errorf("Expected error from ResolveSecret(%q), but err was nil", ref)
+		}
+	}
+
+	// Values are cached: removing the underlying file should have no effect
+	if err := os.Remove(filepath.Join(tempDir, "plain")); err != nil {
+		t.Fatalf("Unable to remove temp file: %v", err)
+	}
+	if actual, err := ResolveSecret(cfg, "aws-ssm:plain", "password"); err != nil || actual != "plainpass" {
+		t.Errorf("Unexpected return from ResolveSecret on cached value: %q, %v", actual, err)
+	}
+
+	// Region and profile are passed to the command when configured
+	awsSecretsManagerCommand = "echo {ID}"
+	cfg = mybase.SimpleConfig(map[string]string{"aws-region": "us-west-2", "aws-profile": "prod"})
+	if actual, err := ResolveSecret(cfg, "aws-sm:mysecret", "password"); err != nil || actual != "mysecret --region us-west-2 --profile prod" {
+		t.Errorf("Unexpected return from ResolveSecret with region and profile: %q, %v", actual, err)
+	}
+}
+
+func TestCredentialHelper(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"db1.json": `{"user": "app", "password": "pw1"}`,
+		"db2.json": `{"username": "svc", "password": "pw2", "expiration": "2030-01-01T00:00:00Z"}`,
+		"db3.json": `{"password": "pw3"}`,
+		"bad.json": `user=app`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("Unable to write temp file: %v", err)
+		}
+	}
+	commandLine := "cat " + tempDir + "/{HOST}.json"
+	cases := map[string][2]string{
+		"db1": {"app", "pw1"},
+		"db2": {"svc", "pw2"},
+		"db3": {"", "pw3"},
+	}
+	for host, expected := range cases {
+		user, password, err := CredentialHelper(commandLine, map[string]string{"HOST": host})
+		if err != nil || user != expected[0] || password != expected[1] {
+			t.Errorf("Unexpected return from CredentialHelper for %s: %q, %q, %v", host, user, password, err)
+		}
+	}
+	for _, host := range []string{"bad", "nonexistent"} {
+		if _, _, err := CredentialHelper(commandLine, map[string]string{"HOST": host}); err == nil {
+			t.Errorf("Expected error from CredentialHelper for %s, but err was nil", host)
+		}
+	}
+	if _, _, err := CredentialHelper("echo {INVALID}", map[string]string{"HOST": "db1"}); err == nil {
+		t.Error("Expected error from CredentialHelper with invalid variable, but err was nil")
+	}
+
+	// Results are cached per command-line
+	if err := os.Remove(filepath.Join(tempDir, "db1.json")); err != nil {
+		t.Fatalf("Unable to remove temp file: %v", err)
+	}
+	if user, password, err := CredentialHelper(commandLine, map[string]string{"HOST": "db1"}); err != nil || user != "app" || password != "pw1" {
+		t.Errorf("Unexpected return from CredentialHelper on cached value: %q, %q, %v", user, password, err)
+	}
+}
@@ -0,0 +1,194 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// vaultPrefix is the prefix of option values which refer to a secret stored in
+// HashiCorp Vault.
+const vaultPrefix = "vault:"
+
+// vaultSecrets caches secrets read from Vault, keyed by path. This ensures that
+// multiple values from the same dynamic secret (for example a username and
+// password generated by the database secrets engine) come from a single read.
+var vaultSecrets = struct {
+	sync.Mutex
+	data map[string]map[string]interface{}
+}{data: make(map[string]map[string]interface{})}
+
+// vaultSecret returns the secret value referenced by ref, which should have
+// format "vault:path#key". The path is the API path of the secret, for example
+// "secret/data/myapp" for a KV version 2 secret, or "database/creds/myrole" for
+// the database secrets engine. If "#key" is omitted, defaultKey is used.
+//
+// The Vault server address and auth token are obtained from the same
+// environment variables used by the Vault CLI: VAULT_ADDR, VAULT_TOKEN, and
+// optionally VAULT_NAMESPACE. If VAULT_TOKEN is not set, the token is read from
+// ~/.vault-token, which is written by `vault login`.
+//
+// Each path is only read once per process. If the secret has a renewable lease,
+// the lease is renewed in the background for the remaining life of the process.
+func vaultSecret(ref, defaultKey string) (string, error) {
+	path, key, _ := strings.Cut(strings.TrimPrefix(ref, vaultPrefix), "#")
+	path = strings.Trim(path, "/")
+	if key == "" {
+		key = defaultKey
+	}
+	if path == "" {
+		return "", fmt.Errorf("Invalid Vault secret reference %q: path is required", ref)
+	}
+
+	vaultSecrets.Lock()
+	defer vaultSecrets.Unlock()
+	data, ok := vaultSecrets.data[path]
+	if !ok {
+		var err error
+		if data, err = vaultRead(path); err != nil {
+			return "", err
+		}
+		vaultSecrets.data[path] = data
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s does not contain key %q", path, key)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s key %q is not a string", path, key)
+	}
+	return str, nil
+}
+
+// vaultResponse represents the relevant parts of a Vault API response.
+type vaultResponse struct {
+	Data          map[string]interface{} `json:"data"`
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Errors        []string               `json:"errors"`
+}
+
+// vaultRead reads the secret at path, returning its data. If the secret has a
+// renewable lease, a goroutine is started to renew it.
+func vaultRead(path string) (map[string]interface{}, error) {
+	resp, err := vaultRequest(http.MethodGet, "/v1/"+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read Vault secret %s: %w", path, err)
+	}
+	data := resp.Data
+	// KV version 2 wraps the secret's data in an additional "data" field,
+	// alongside a "metadata" field
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("Unable to read Vault secret %s: response contained no data", path)
+	}
+	if resp.LeaseID != "" && resp.Renewable && resp.LeaseDuration > 0 {
+		go vaultRenewLease(resp.LeaseID, resp.LeaseDuration)
+	}
+	return data, nil
+}
+
+// vaultRenewLease periodically renews the lease with the supplied ID, until a
+// renewal fails or the lease is no longer renewable. Each renewal occurs after
+// two-thirds of the current lease duration has elapsed.
+func vaultRenewLease(leaseID string, leaseDuration int) {
+	for {
+		time.Sleep(time.Duration(leaseDuration) * time.Second * 2 / 3)
+		body := map[string]interface{}{
+			"lease_id":  leaseID,
+			"increment": leaseDuration,
+		}
+		resp, err := vaultRequest(http.MethodPut, "/v1/sys/leases/renew", body)
+		if err != nil {
+			log.Warnf("Unable to renew Vault lease %s: %s", leaseID, err)
+			return
+		}
+		log.Debugf("Renewed Vault lease %s for %d seconds", leaseID, resp.LeaseDuration)
+		if !resp.Renewable || resp.LeaseDuration <= 0 {
+			return
+		}
+		leaseDuration = resp.LeaseDuration
+	}
+}
+
+// vaultRequest performs a Vault API request, using the server address and token
+// from the environment.
+func vaultRequest(method, path string, body interface{}) (*vaultResponse, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, errors.New("environment variable VAULT_ADDR is not set")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return nil, err
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(addr, "/")+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var resp vaultResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("HTTP %d with unexpected response body: %w", httpResp.StatusCode, err)
+	} else if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, strings.Join(resp.Errors, "; "))
+	}
+	return &resp, nil
+}
+
+// vaultToken returns the Vault auth token from environment variable VAULT_TOKEN,
+// or from the token helper file written by `vault login`.
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("environment variable VAULT_TOKEN is not set")
+	}
+	contents, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return "", errors.New("environment variable VAULT_TOKEN is not set, and no token file found from `vault login`")
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
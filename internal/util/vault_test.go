@@ -0,0 +1,103 @@
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestVaultServer returns a fake Vault server, which serves a KV version 2
+// secret at secret/data/myapp and a database secrets engine secret at
+// database/creds/myrole. The returned counter tracks lease renewals.
+func newTestVaultServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var renewals int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			w.Write([]byte(`{"data": {"data": {"password": "kvpass", "port": 3306}, "metadata": {"version": 3}}}`))
+		case "/v1/database/creds/myrole":
+			w.Write([]byte(`{"lease_id": "database/creds/myrole/abc", "lease_duration": 1, "renewable": true, "data": {"username": "v-myrole-xyz", "password": "dbpass"}}`))
+		case "/v1/sys/leases/renew":
+			var body struct {
+				LeaseID string `json:"lease_id"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.LeaseID != "database/creds/myrole/abc" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors": ["invalid lease ID"]}`))
+				return
+			}
+			atomic.AddInt32(&renewals, 1)
+			w.Write([]byte(`{"lease_id": "database/creds/myrole/abc", "lease_duration": 0, "renewable": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+	return server, &renewals
+}
+
+func TestVaultSecret(t *testing.T) {
+	_, renewals := newTestVaultServer(t)
+
+	cases := []struct {
+		ref        string
+		defaultKey string
+		expected   string
+	}{
+		{"vault:secret/data/myapp#password", "username", "kvpass"},
+		{"vault:/secret/data/myapp", "password", "kvpass"},
+		{"vault:database/creds/myrole", "username", "v-myrole-xyz"},
+		{"vault:database/creds/myrole#password", "username", "dbpass"},
+	}
+	for _, c := range cases {
+		if actual, err := vaultSecret(c.ref, c.defaultKey); err != nil || actual != c.expected {
+			t.Errorf("Unexpected return from vaultSecret(%q, %q): %q, %v", c.ref, c.defaultKey, actual, err)
+		}
+	}
+
+	badRefs := []string{
+		"vault:",
+		"vault:secret/data/myapp#username", // missing key
+		"vault:secret/data/myapp#port",     // not a string
+		"vault:secret/data/other",          // 404
+	}
+	for _, ref := range badRefs {
+		if _, err := vaultSecret(ref, "password"); err == nil {
+			t.Errorf("Expected error from vaultSecret(%q), but err was nil", ref)
+		}
+	}
+
+	// Bad token should return error, but only for paths that haven't already been
+	// read and cached
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := vaultSecret("vault:kv/other#password", "password"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected permission denied error, instead found %v", err)
+	}
+	if _, err := vaultSecret("vault:secret/data/myapp#password", "password"); err != nil {
+		t.Errorf("Unexpected error from cached secret: %v", err)
+	}
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	// The database secret's lease should be renewed after 2/3 of its 1-second
+	// duration; the fake server's renewal response then marks it non-renewable
+	deadline := time.Now().Add(3 * time.Second)
+	for atomic.LoadInt32(renewals) == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if count := atomic.LoadInt32(renewals); count != 1 {
+		t.Errorf("Expected 1 lease renewal, instead found %d", count)
+	}
+}
@@ -0,0 +1,391 @@
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// Kubernetes is a Workspace created inside of a short-lived database pod in a
+// Kubernetes cluster, reached via `kubectl port-forward`. This is useful in CI
+// environments where Docker-in-Docker is not permitted. The schema is dropped
+// when done interacting with the workspace in Cleanup(), and the pod is deleted
+// via Shutdown().
+type Kubernetes struct {
+	schemaName        string
+	pod               *kubePod
+	releaseLock       releaseFunc
+	defaultConnParams string
+}
+
+// kubePod tracks a database pod and the port-forward process used to reach it.
+type kubePod struct {
+	name        string
+	namespace   string
+	inst        *tengo.Instance
+	portForward *exec.Cmd
+}
+
+var kstore struct {
+	pods map[string]*kubePod // keyed by namespace and image
+	sync.Mutex
+}
+
+// NewKubernetes finds or creates a database pod, creates a temporary schema on
+// it, and returns it.
+func NewKubernetes(opts Options) (_ *Kubernetes, retErr error) {
+	if opts.Flavor == tengo.FlavorUnknown && opts.KubeImage == "" {
+		return nil, errors.New("no flavor supplied")
+	}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return nil, errors.New("workspace=kubernetes requires the `kubectl` command-line client, but it was not found among directories in PATH")
+	}
+	image := opts.KubeImage
+	if image == "" {
+		var err error
+		if image, err = DockerImageForFlavor(opts.Flavor, "amd64"); err != nil {
+			return nil, err
+		}
+	}
+
+	kstore.Lock()
+	defer kstore.Unlock()
+	if kstore.pods == nil {
+		kstore.pods = make(map[string]*kubePod)
+		tengo.UseFilteredDriverLogger()
+	}
+
+	k := &Kubernetes{
+		schemaName:        opts.SchemaName,
+		defaultConnParams: opts.DefaultConnParams,
+	}
+	storeKey := opts.KubeNamespace + "/" + image
+	if kstore.pods[storeKey] != nil {
+		k.pod = kstore.pods[storeKey]
+	} else {
+		var err error
+		if k.pod, err = createKubePod(image, opts); err != nil {
+			return nil, err
+		}
+		kstore.pods[storeKey] = k.pod
+		RegisterShutdownFunc(k.pod.shutdown)
+	}
+
+	var err error
+	lockName := fmt.Sprintf("skeema.%s", k.schemaName)
+	if k.releaseLock, err = getLock(k.pod.inst, lockName, opts.LockTimeout); err != nil {
+		return nil, fmt.Errorf("Unable to obtain workspace lock on database pod %s: %w", k.pod.name, err)
+	}
+	// If this function returns an error, don't continue to hold the lock
+	defer func() {
+		if retErr != nil {
+			k.releaseLock()
+		}
+	}()
+
+	if has, err := k.pod.inst.HasSchema(k.schemaName); err != nil {
+		return nil, fmt.Errorf("Unable to check for existence of temp schema on pod %s: %s", k.pod.name, err)
+	} else if has {
+		dropOpts := tengo.BulkDropOptions{
+			ChunkSize:   10,
+			OnlyIfEmpty: true,
+			SkipBinlog:  true,
+		}
+		if err := k.pod.inst.DropSchema(k.schemaName, dropOpts); err != nil {
+			return nil, fmt.Errorf("Cannot drop existing temporary schema on pod %s: %s", k.pod.name, err)
+		}
+	}
+	createOpts := tengo.SchemaCreationOptions{
+		DefaultCharSet:   opts.DefaultCharacterSet,
+		DefaultCollation: opts.DefaultCollation,
+		SkipBinlog:       true,
+	}
+	if _, err := k.pod.inst.CreateSchema(k.schemaName, createOpts); err != nil {
+		return nil, fmt.Errorf("Cannot create temporary schema on pod %s: %s", k.pod.name, err)
+	}
+	return k, nil
+}
+
+// ConnectionPool returns a connection pool (*sqlx.DB) to the temporary
+// workspace schema, using the supplied connection params (which may be blank).
+// As with LocalDocker, the params are merged over top of the workspace's
+// default params, and tls is disabled since the connection is tunneled.
+func (k *Kubernetes) ConnectionPool(params string) (*sqlx.DB, error) {
+	finalParams := tengo.MergeParamStrings(k.defaultConnParams, params, "tls=false")
+	return k.pod.inst.CachedConnectionPool(k.schemaName, finalParams)
+}
+
+// IntrospectSchema introspects and returns the temporary workspace schema.
+func (k *Kubernetes) IntrospectSchema() (IntrospectionResult, error) {
+	schema, err := k.pod.inst.Schema(k.schemaName)
+	result := IntrospectionResult{
+		Schema:  schema,
+		Flavor:  k.pod.inst.Flavor(),
+		SQLMode: k.pod.inst.SQLMode(),
+	}
+	return result, err
+}
+
+// Cleanup drops the temporary schema from the pod's database server. If any
+// tables have any rows in the temp schema, the cleanup aborts and an error is
+// returned. The pod itself is deleted by Shutdown() instead.
+func (k *Kubernetes) Cleanup(schema *tengo.Schema) error {
+	if k.releaseLock == nil {
+		return errors.New("Cleanup() called multiple times on same Kubernetes workspace")
+	}
+	defer func() {
+		k.releaseLock()
+		k.releaseLock = nil
+	}()
+
+	dropOpts := tengo.BulkDropOptions{
+		ChunkSize:   10,
+		OnlyIfEmpty: true,
+		Schema:      schema, // may be nil, not a problem
+	}
+	if err := k.pod.inst.DropSchema(k.schemaName, dropOpts); err != nil {
+		return fmt.Errorf("Cannot drop temporary schema on pod %s: %s", k.pod.name, err)
+	}
+	return nil
+}
+
+// createKubePod creates a database pod using image, waits for it to become
+// ready, and establishes a port-forward to it.
+func createKubePod(image string, opts Options) (*kubePod, error) {
+	pod := &kubePod{
+		name:      kubePodName(image),
+		namespace: opts.KubeNamespace,
+	}
+	manifest, err := kubePodManifest(pod.name, image, opts)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Creating pod %s (image=%s) for workspace operations", pod.name, image)
+	if out, err := pod.kubectl("apply -f -", nil).WithStdin(strings.NewReader(manifest)).RunCaptureCombined(); err != nil {
+		return nil, fmt.Errorf("unable to create Kubernetes pod: %w: %s", err, out)
+	}
+
+	// From this point on, delete the pod if anything goes wrong
+	fail := func(err error) (*kubePod, error) {
+		pod.delete()
+		return nil, err
+	}
+	vars := map[string]string{"TIMEOUT": opts.KubeStartTimeout.String()}
+	if out, err := pod.kubectl("wait --for=condition=Ready pod/{POD} --timeout={TIMEOUT}", vars).RunCaptureCombined(); err != nil {
+		return fail(fmt.Errorf("pod %s did not become ready: %w: %s", pod.name, err, out))
+	}
+	port, err := pod.startPortForward()
+	if err != nil {
+		return fail(err)
+	}
+	dsn := fmt.Sprintf("root@tcp(127.0.0.1:%d)/?", port)
+	if opts.RootPassword != "" {
+		dsn = fmt.Sprintf("root:%s@tcp(127.0.0.1:%d)/?", opts.RootPassword, port)
+	}
+	if pod.inst, err = tengo.NewInstance("mysql", dsn); err != nil {
+		return fail(err)
+	}
+
+	// The server may still be initializing its data directory, even though the
+	// container is running
+	deadline := time.Now().Add(opts.KubeStartTimeout)
+	for {
+		ok, err := pod.inst.CanConnect()
+		if ok {
+			return pod, nil
+		} else if time.Now().After(deadline) {
+			return fail(fmt.Errorf("unable to connect to database in pod %s: %w", pod.name, err))
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// kubePodName returns a unique pod name for image.
+func kubePodName(image string) string {
+	name := strings.ReplaceAll(tengo.ContainerNameForImage(image), ".", "-")
+	return fmt.Sprintf("skeema-%s-%s", name, strconv.FormatInt(time.Now().UnixNano()%(1<<32), 36))
+}
+
+// kubePodManifest returns a JSON pod manifest for running a database server
+// suitable for workspace operations.
+func kubePodManifest(name, image string, opts Options) (string, error) {
+	env := []map[string]string{{"name": "MYSQL_ROOT_HOST", "value": "%"}}
+	if opts.RootPassword == "" {
+		env = append(env, map[string]string{"name": "MYSQL_ALLOW_EMPTY_PASSWORD", "value": "1"})
+	} else {
+		env = append(env, map[string]string{"name": "MYSQL_ROOT_PASSWORD", "value": opts.RootPassword})
+	}
+	var lowerCaseTableNames uint8
+	if opts.NameCaseMode == tengo.NameCaseLower {
+		lowerCaseTableNames = 1
+	}
+	inMemory := tengo.ImageSupportsDataTmpfs(image)
+	dataVolume := map[string]any{"name": "data", "emptyDir": map[string]any{}}
+	if inMemory {
+		dataVolume["emptyDir"] = map[string]any{"medium": "Memory"}
+	}
+	resources := map[string]string{}
+	if opts.KubeCPU != "" {
+		resources["cpu"] = opts.KubeCPU
+	}
+	if opts.KubeMemory != "" {
+		resources["memory"] = opts.KubeMemory
+	}
+	// The owner label permits orphaned pods to be found and deleted, for example
+	// via kubectl delete pods -l skeema.io/owner=<host>. activeDeadlineSeconds
+	// ensures Kubernetes stops the database server even if this process was
+	// killed before it could delete the pod.
+	metadata := map[string]any{
+		"name": name,
+		"labels": map[string]string{
+			"app.kubernetes.io/managed-by": "skeema",
+			"skeema.io/owner":              kubeOwnerLabel(),
+		},
+	}
+	if opts.KubeNamespace != "" {
+		metadata["namespace"] = opts.KubeNamespace
+	}
+	manifest := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   metadata,
+		"spec": map[string]any{
+			"restartPolicy":         "Never",
+			"activeDeadlineSeconds": int64(opts.KubeMaxLifetime.Seconds()),
+			"containers": []map[string]any{{
+				"name":         "db",
+				"image":        image,
+				"args":         tengo.EphemeralServerArgs(false, lowerCaseTableNames, inMemory),
+				"env":          env,
+				"ports":        []map[string]any{{"containerPort": 3306}},
+				"resources":    map[string]any{"requests": resources, "limits": resources},
+				"volumeMounts": []map[string]string{{"name": "data", "mountPath": "/var/lib/mysql"}},
+			}},
+			"volumes": []map[string]any{dataVolume},
+		},
+	}
+	b, err := json.Marshal(manifest)
+	return string(b), err
+}
+
+var kubeLabelInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// kubeOwnerLabel returns a label value identifying the host which created a
+// pod. The hostname is sanitized and truncated to satisfy Kubernetes label
+// value restrictions.
+func kubeOwnerLabel() string {
+	host, _ := os.Hostname()
+	host = kubeLabelInvalidChars.ReplaceAllString(host, "-")
+	if len(host) > 63 {
+		host = host[:63]
+	}
+	if host = strings.Trim(host, "_.-"); host == "" {
+		host = "unknown"
+	}
+	return host
+}
+
+// kubectl returns a shellout.Command for running kubectl with the supplied
+// args, in the pod's namespace. The args may use the {POD} placeholder, as well
+// as any placeholders for keys in vars (which may be nil).
+func (pod *kubePod) kubectl(args string, vars map[string]string) *shellout.Command {
+	allVars := map[string]string{
+		"POD":       pod.name,
+		"NAMESPACE": pod.namespace,
+	}
+	for k, v := range vars {
+		allVars[k] = v
+	}
+	commandLine := "kubectl "
+	if pod.namespace != "" {
+		commandLine += "--namespace {NAMESPACE} "
+	}
+	return shellout.New(commandLine + args).WithVariablesStrict(allVars)
+}
+
+var portForwardRegexp = regexp.MustCompile(`Forwarding from 127\.0\.0\.1:(\d+) ->`)
+
+// startPortForward launches `kubectl port-forward` in the background, mapping
+// a random local port to the pod's port 3306, and returns the local port. The
+// process is not run via package shellout, since it must remain running for
+// the lifetime of the workspace.
+func (pod *kubePod) startPortForward() (int, error) {
+	args := []string{"port-forward", "--address", "127.0.0.1", "pod/" + pod.name, ":3306"}
+	if pod.namespace != "" {
+		args = append([]string{"--namespace", pod.namespace}, args...)
+	}
+	cmd := exec.Command("kubectl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("unable to run kubectl port-forward: %w", err)
+	}
+	pod.portForward = cmd
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if matches := portForwardRegexp.FindStringSubmatch(scanner.Text()); matches != nil {
+			// Continue draining output in the background, so that the process never
+			// blocks on writing to a full pipe
+			go func() {
+				for scanner.Scan() {
+				}
+			}()
+			return strconv.Atoi(matches[1])
+		}
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	return 0, fmt.Errorf("unable to determine local port from kubectl port-forward for pod %s", pod.name)
+}
+
+// delete stops any port-forward and deletes the pod, without waiting for
+// deletion to complete.
+func (pod *kubePod) delete() {
+	if pod.inst != nil {
+		pod.inst.CloseAll()
+	}
+	if pod.portForward != nil {
+		pod.portForward.Process.Kill()
+		pod.portForward.Wait()
+		pod.portForward = nil
+	}
+	if out, err := pod.kubectl("delete pod {POD} --wait=false", nil).RunCaptureCombined(); err != nil {
+		log.Warnf("Failed to delete pod %s: %v: %s", pod.name, err, out)
+	}
+}
+
+// shutdown deletes the pod. A single string arg may optionally be supplied as
+// a pod name prefix: if the pod name does not begin with the prefix, no
+// shutdown occurs.
+func (pod *kubePod) shutdown(args ...interface{}) bool {
+	if len(args) > 0 {
+		if prefix, ok := args[0].(string); !ok || !strings.HasPrefix(pod.name, prefix) {
+			return false
+		}
+	}
+	kstore.Lock()
+	defer kstore.Unlock()
+	log.Infof("Deleting pod %s", pod.name)
+	pod.delete()
+	for key, p := range kstore.pods {
+		if p == pod {
+			delete(kstore.pods, key)
+		}
+	}
+	return true
+}
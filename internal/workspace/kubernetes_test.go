@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestKubePodName(t *testing.T) {
+	re := regexp.MustCompile(`^skeema-mysql-8-0-[0-9a-z]+$`)
+	if name := kubePodName("mysql:8.0"); !re.MatchString(name) {
+		t.Errorf("Unexpected pod name %q", name)
+	}
+}
+
+func TestKubePodManifest(t *testing.T) {
+	opts := Options{
+		KubeNamespace:   "ci",
+		KubeCPU:         "500m",
+		KubeMemory:      "1Gi",
+		NameCaseMode:    tengo.NameCaseLower,
+		KubeMaxLifetime: 2 * time.Hour,
+	}
+	manifestJSON, err := kubePodManifest("skeema-mysql-8-0-abc", "mysql:8.0", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from kubePodManifest: %v", err)
+	}
+	var manifest struct {
+		Kind     string
+		Metadata struct {
+			Name      string
+			Namespace string
+			Labels    map[string]string
+		}
+		Spec struct {
+			ActiveDeadlineSeconds int64
+			Containers            []struct {
+				Image     string
+				Args      []string
+				Env       []map[string]string
+				Resources struct {
+					Limits map[string]string
+				}
+			}
+			Volumes []struct {
+				EmptyDir map[string]string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(manifestJSON), &manifest); err != nil {
+		t.Fatalf("Unable to decode manifest: %v", err)
+	}
+	if manifest.Kind != "Pod" || manifest.Metadata.Name != "skeema-mysql-8-0-abc" || manifest.Metadata.Namespace != "ci" {
+		t.Errorf("Unexpected manifest metadata: %s", manifestJSON)
+	}
+	if owner := manifest.Metadata.Labels["skeema.io/owner"]; owner == "" || owner != kubeOwnerLabel() {
+		t.Errorf("Unexpected owner label %q", owner)
+	}
+	if manifest.Spec.ActiveDeadlineSeconds != 7200 {
+		t.Errorf("Expected activeDeadlineSeconds of 7200, instead found %d", manifest.Spec.ActiveDeadlineSeconds)
+	}
+	if len(manifest.Spec.Containers) != 1 {
+		t.Fatalf("Expected 1 container, instead found %d", len(manifest.Spec.Containers))
+	}
+	c := manifest.Spec.Containers[0]
+	if c.Image != "mysql:8.0" || c.Resources.Limits["cpu"] != "500m" || c.Resources.Limits["memory"] != "1Gi" {
+		t.Errorf("Unexpected container spec: %s", manifestJSON)
+	}
+	if !slices.Contains(c.Args, "--lower-case-table-names=1") || !slices.Contains(c.Args, "--skip-log-bin") {
+		t.Errorf("Unexpected server args: %v", c.Args)
+	}
+	if !slices.ContainsFunc(c.Env, func(env map[string]string) bool { return env["name"] == "MYSQL_ALLOW_EMPTY_PASSWORD" }) {
+		t.Errorf("Expected empty root password to be permitted, instead env is %v", c.Env)
+	}
+	if len(manifest.Spec.Volumes) != 1 || manifest.Spec.Volumes[0].EmptyDir["medium"] != "Memory" {
+		t.Errorf("Expected in-memory data volume, instead found %+v", manifest.Spec.Volumes)
+	}
+
+	// Images which don't support tmpfs should use a standard emptyDir
+	opts.RootPassword = "secret"
+	manifestJSON, _ = kubePodManifest("skeema-percona-8-0-abc", "percona:8.0", opts)
+	manifest.Spec.Volumes = nil
+	if err := json.Unmarshal([]byte(manifestJSON), &manifest); err != nil {
+		t.Fatalf("Unable to decode manifest: %v", err)
+	}
+	if len(manifest.Spec.Volumes) != 1 || manifest.Spec.Volumes[0].EmptyDir["medium"] != "" {
+		t.Errorf("Expected standard data volume, instead found %+v", manifest.Spec.Volumes)
+	}
+}
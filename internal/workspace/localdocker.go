@@ -1,6 +1,8 @@
 package workspace
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/url"
@@ -25,7 +27,8 @@ type LocalDocker struct {
 }
 
 var cstore struct {
-	containers map[string]*tengo.DockerizedInstance
+	containers  map[string]*tengo.DockerizedInstance
+	poolExpired bool // true once idle pooled containers have been checked for expiry
 	sync.Mutex
 }
 
@@ -101,14 +104,20 @@ func NewLocalDocker(opts Options) (_ *LocalDocker, retErr error) {
 	} else {
 		// DefaultConnParams is intentionally not set here; see important comment in
 		// ConnectionPool() for reasoning.
-		// DataTmpfs is enabled automatically here if the container is going to be
-		// destroyed at end-of-process anyway, since this improves perf. It only has
-		// an effect on Linux, and is ignored on other OSes.
+		// DataTmpfs is enabled automatically if the container is going to be
+		// destroyed at end-of-process anyway, since this improves perf; otherwise it
+		// may be requested via the docker-tmpfs option, at the cost of the container
+		// losing its data whenever it is stopped. It only has an effect on Linux, and
+		// is ignored on other OSes.
 		dopts := tengo.DockerizedInstanceOptions{
 			Name:         opts.ContainerName,
 			Image:        image,
 			RootPassword: opts.RootPassword,
-			DataTmpfs:    (ld.cleanupAction == CleanupActionDestroy),
+			DataTmpfs:    opts.DataTmpfs || ld.cleanupAction == CleanupActionDestroy,
+			ServerArgs:   opts.ServerArgs,
+		}
+		if dopts.DataTmpfs && !tengo.ImageSupportsDataTmpfs(image) {
+			log.Debugf("Image %s does not support a tmpfs data directory; using a standard data directory instead", image)
 		}
 		// If real inst had lower_case_table_names=1, use that in the container as
 		// well. (No need for similar logic with lower_case_table_names=2; this cannot
@@ -119,7 +128,11 @@ func NewLocalDocker(opts Options) (_ *LocalDocker, retErr error) {
 		}
 
 		log.Infof("Using container %s (image=%s) for workspace operations", opts.ContainerName, image)
-		ld.d, err = tengo.GetOrCreateDockerizedInstance(dopts)
+		if ld.cleanupAction == CleanupActionPool {
+			ld.d, err = getOrCreatePooledInstance(dopts)
+		} else {
+			ld.d, err = tengo.GetOrCreateDockerizedInstance(dopts)
+		}
 		if ld.d != nil {
 			cstore.containers[opts.ContainerName] = ld.d
 			RegisterShutdownFunc(ld.shutdown)
@@ -129,6 +142,14 @@ func NewLocalDocker(opts Options) (_ *LocalDocker, retErr error) {
 		}
 	}
 
+	if ld.cleanupAction == CleanupActionPool {
+		recordPoolUse(opts.ContainerName)
+		if !cstore.poolExpired {
+			expireIdlePoolContainers(opts.IdleExpiry, cstore.containers)
+			cstore.poolExpired = true
+		}
+	}
+
 	lockName := fmt.Sprintf("skeema.%s", ld.schemaName)
 	if ld.releaseLock, err = getLock(ld.d.Instance, lockName, opts.LockTimeout); err != nil {
 		return nil, fmt.Errorf("Unable to obtain workspace lock on database container %s: %w\n"+
@@ -267,6 +288,9 @@ func (ld *LocalDocker) shutdown(args ...interface{}) bool {
 			log.Warnf("Failed to destroy container %s: %v", ld.d.ContainerName(), err)
 		}
 	} else {
+		if ld.cleanupAction == CleanupActionPool {
+			recordPoolUse(ld.d.ContainerName())
+		}
 		// When tengo.GetOrCreateDockerizedInstance returns a DockerizedInstance, it
 		// will automatically have redo logging disabled if the flavor supports that.
 		// However, since the container is being left in the running state, we attempt
@@ -274,11 +298,20 @@ func (ld *LocalDocker) shutdown(args ...interface{}) bool {
 		// break the containerized DB. Error return of this call is intentionally
 		// ignored, since only some flavors support enabling/disabling the redo log.
 		ld.d.SetRedoLog(true)
+		ld.d.CloseAll()
+		ld.d.CloseTunnel()
 	}
 	delete(cstore.containers, ld.d.ContainerName())
 	return true
 }
 
+// serverArgsHash returns a short hex hash of serverArgs, for use in naming
+// containers which have a non-default server configuration.
+func serverArgsHash(serverArgs []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(serverArgs, " ")))
+	return hex.EncodeToString(sum[:4])
+}
+
 // DockerImageForFlavor attempts to return the name of a Docker image for the
 // supplied flavor and arch. The arch should be supplied in the same format as
 // returned by tengo.DockerEngineArchitecture(), i.e. "amd64" or "arm64".
@@ -0,0 +1,78 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// With workspace=parse, CREATE statements are converted into tengo objects by
+// Skeema's own SQL parser, without executing them in any database server. This
+// is fast and has no external dependencies, but fidelity is limited: see
+// tengo.ParseCreateTable for details. Stored procedures and functions are only
+// checked for their name and type, and ALTER statements are not supported at
+// all. Since the resulting schema is approximate, it is only suitable for
+// commands which do not generate DDL, such as lint and format.
+
+// ErrParseWorkspace is returned when workspace=parse is used in a situation
+// requiring a database server.
+var ErrParseWorkspace = errors.New("workspace=parse cannot be used with this command, since it requires a database server; use workspace=temp-schema, workspace=docker, or workspace=kubernetes instead")
+
+// execParsedLogicalSchema is the TypeParse equivalent of ExecLogicalSchema.
+// Individual statements which cannot be parsed are tracked in the returned
+// Schema's Failures.
+func execParsedLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) *Schema {
+	wsSchema := &Schema{
+		Schema: &tengo.Schema{
+			Name:      opts.SchemaName,
+			CharSet:   opts.DefaultCharacterSet,
+			Collation: opts.DefaultCollation,
+			Tables:    []*tengo.Table{},
+			Routines:  []*tengo.Routine{},
+		},
+		LogicalSchema: logicalSchema,
+		Flavor:        opts.Flavor,
+		Failures:      []*StatementError{},
+	}
+	keys := slices.SortedFunc(maps.Keys(logicalSchema.Creates), func(a, b tengo.ObjectKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, key := range keys {
+		stmt := logicalSchema.Creates[key]
+		switch key.Type {
+		case tengo.ObjectTypeTable:
+			table, err := tengo.ParseCreateTable(stmt.Body(), opts.Flavor, opts.DefaultCharacterSet, opts.DefaultCollation)
+			if err != nil {
+				wsSchema.Failures = append(wsSchema.Failures, &StatementError{
+					Statement: stmt,
+					Err:       fmt.Errorf("Error parsing DDL: %w", err),
+				})
+				continue
+			}
+			wsSchema.Tables = append(wsSchema.Tables, table)
+		case tengo.ObjectTypeProc, tengo.ObjectTypeFunc:
+			wsSchema.Routines = append(wsSchema.Routines, &tengo.Routine{
+				Name:            stmt.ObjectName,
+				Type:            stmt.ObjectType,
+				CreateStatement: stmt.Body(),
+			})
+		default:
+			wsSchema.Failures = append(wsSchema.Failures, &StatementError{
+				Statement: stmt,
+				Err:       fmt.Errorf("Error parsing DDL: %s is not supported with workspace=parse", key.Type.Caps()),
+			})
+		}
+	}
+	for _, stmt := range logicalSchema.Alters {
+		wsSchema.Failures = append(wsSchema.Failures, &StatementError{
+			Statement: stmt,
+			Err:       errors.New("Error parsing DDL: ALTER statements are not supported with workspace=parse"),
+		})
+	}
+	return wsSchema
+}
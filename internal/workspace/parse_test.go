@@ -0,0 +1,48 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestExecParsedLogicalSchema(t *testing.T) {
+	statements, err := tengo.ParseStatementsInString("CREATE TABLE foo (id int unsigned NOT NULL, PRIMARY KEY (id));\n" +
+		"CREATE TABLE bar (id int) PARTITION BY HASH (id) PARTITIONS 4;\n" +
+		"CREATE FUNCTION f() RETURNS int RETURN 1;\n")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseStatementsInString: %v", err)
+	}
+	logicalSchema := fs.NewLogicalSchema()
+	for _, stmt := range statements {
+		if err := logicalSchema.AddStatement(stmt); err != nil {
+			t.Fatalf("Unexpected error from AddStatement: %v", err)
+		}
+	}
+	opts := Options{
+		Type:                TypeParse,
+		Flavor:              tengo.ParseFlavor("mysql:8.0.35"),
+		SchemaName:          "_skeema_tmp",
+		DefaultCharacterSet: "utf8mb4",
+		DefaultCollation:    "utf8mb4_0900_ai_ci",
+	}
+	wsSchema, err := ExecLogicalSchema(logicalSchema, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from ExecLogicalSchema: %v", err)
+	}
+	if len(wsSchema.Tables) != 1 || wsSchema.Tables[0].Name != "foo" {
+		t.Errorf("Unexpected tables in result: %+v", wsSchema.Tables)
+	}
+	if len(wsSchema.Routines) != 1 || wsSchema.Routines[0].Name != "f" || wsSchema.Routines[0].Type != tengo.ObjectTypeFunc {
+		t.Errorf("Unexpected routines in result: %+v", wsSchema.Routines)
+	}
+	if len(wsSchema.Failures) != 1 || !strings.Contains(wsSchema.Failures[0].Error(), "partitioning") {
+		t.Errorf("Unexpected failures in result: %v", wsSchema.Failures)
+	}
+
+	if _, err := New(opts); err != ErrParseWorkspace {
+		t.Errorf("Expected New to return ErrParseWorkspace, instead found %v", err)
+	}
+}
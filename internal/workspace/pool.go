@@ -0,0 +1,121 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// With docker-cleanup=pool, containers are left running at the end of each
+// invocation, so that subsequent invocations (for example lint, diff, and push
+// in a CI pipeline) can reuse them without paying container startup costs
+// again. Pooled containers are keyed by flavor via their container name. The
+// last use of each pooled container is tracked by the modification time of a
+// file named after the container, in poolDir(). Any invocation using the pool
+// stops other pooled containers which have been idle longer than the
+// docker-idle-expiry option.
+
+// poolDir returns the directory used for tracking pooled containers' last use.
+func poolDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "skeema", "workspace-pool"), nil
+}
+
+// touchPoolEntry records that the named container has just been used.
+func touchPoolEntry(dir, name string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	now := time.Now()
+	err := os.Chtimes(path, now, now)
+	if errors.Is(err, os.ErrNotExist) {
+		var f *os.File
+		if f, err = os.Create(path); err == nil {
+			err = f.Close()
+		}
+	}
+	return err
+}
+
+// idlePoolEntries returns the names of containers in dir which have not been
+// used within expiry, excluding any names in inUse.
+func idlePoolEntries(dir string, expiry time.Duration, inUse map[string]*tengo.DockerizedInstance) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, "skeema-") || inUse[name] != nil {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > expiry {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// expireIdlePoolContainers stops pooled containers which have been idle for
+// longer than expiry. Errors are logged but otherwise ignored, since they
+// should not prevent use of the workspace.
+func expireIdlePoolContainers(expiry time.Duration, inUse map[string]*tengo.DockerizedInstance) {
+	dir, err := poolDir()
+	if err != nil {
+		log.Debugf("Unable to determine workspace pool directory: %v", err)
+		return
+	}
+	names, err := idlePoolEntries(dir, expiry, inUse)
+	if err != nil {
+		log.Debugf("Unable to examine workspace pool directory %s: %v", dir, err)
+		return
+	}
+	for _, name := range names {
+		log.Infof("Stopping container %s, which has been idle for more than %s", name, expiry)
+		if err := tengo.StopDockerContainer(name); err != nil {
+			log.Debugf("Failed to stop container %s: %v", name, err)
+		}
+		os.Remove(filepath.Join(dir, name))
+	}
+}
+
+// recordPoolUse records the current time as the last use of the named pooled
+// container. Errors are logged but otherwise ignored.
+func recordPoolUse(name string) {
+	dir, err := poolDir()
+	if err == nil {
+		err = touchPoolEntry(dir, name)
+	}
+	if err != nil {
+		log.Debugf("Unable to record use of pooled container %s: %v", name, err)
+	}
+}
+
+// getOrCreatePooledInstance behaves like tengo.GetOrCreateDockerizedInstance,
+// except that an existing container which fails its health check (because it
+// cannot be started, connected to, or has an unexpected flavor) is destroyed
+// and recreated, rather than returning an error.
+func getOrCreatePooledInstance(opts tengo.DockerizedInstanceOptions) (*tengo.DockerizedInstance, error) {
+	di, getErr := tengo.GetDockerizedInstance(opts)
+	if getErr == nil {
+		return di, nil
+	} else if !strings.Contains(strings.ToLower(getErr.Error()), "no such container") {
+		log.Warnf("Pooled container %s failed health check, so it will be recreated: %v", opts.Name, getErr)
+		if err := tengo.DestroyDockerContainer(opts.Name); err != nil {
+			return nil, getErr
+		}
+	}
+	return tengo.CreateDockerizedInstance(opts)
+}
@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestIdlePoolEntries(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pool")
+
+	// Nonexistent dir should not be an error
+	if names, err := idlePoolEntries(dir, time.Minute, nil); len(names) > 0 || err != nil {
+		t.Fatalf("Unexpected return from idlePoolEntries on nonexistent dir: %v, %v", names, err)
+	}
+
+	for _, name := range []string{"skeema-mysql-8.0", "skeema-mariadb-11.4", "skeema-percona-8.0", "unrelated"} {
+		if err := touchPoolEntry(dir, name); err != nil {
+			t.Fatalf("Unexpected error from touchPoolEntry: %v", err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	for _, name := range []string{"skeema-mysql-8.0", "skeema-mariadb-11.4", "unrelated"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatalf("Unexpected error from Chtimes: %v", err)
+		}
+	}
+
+	inUse := map[string]*tengo.DockerizedInstance{"skeema-mariadb-11.4": {}}
+	names, err := idlePoolEntries(dir, time.Hour, inUse)
+	if err != nil || !slices.Equal(names, []string{"skeema-mysql-8.0"}) {
+		t.Errorf("Unexpected return from idlePoolEntries: %v, %v", names, err)
+	}
+
+	// Touching an existing entry should make it no longer idle
+	if err := touchPoolEntry(dir, "skeema-mysql-8.0"); err != nil {
+		t.Fatalf("Unexpected error from touchPoolEntry: %v", err)
+	}
+	if names, err := idlePoolEntries(dir, time.Hour, inUse); len(names) > 0 || err != nil {
+		t.Errorf("Unexpected return from idlePoolEntries: %v, %v", names, err)
+	}
+}
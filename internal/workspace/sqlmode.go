@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// sqlModeTextDependency describes an sql_mode value which affects how the
+// text of a statement is parsed, if the statement text matches a pattern.
+type sqlModeTextDependency struct {
+	mode        string
+	pattern     *regexp.Regexp
+	description string
+}
+
+var sqlModeTextDependencies = []sqlModeTextDependency{
+	{"ANSI_QUOTES", regexp.MustCompile(`"`), "double-quoted text, which is treated as an identifier rather than a string"},
+	{"NO_BACKSLASH_ESCAPES", regexp.MustCompile(`\\`), "backslashes, which are treated literally rather than as escape characters"},
+	{"PIPES_AS_CONCAT", regexp.MustCompile(`\|\|`), "the || operator, which is treated as string concatenation rather than logical OR"},
+	{"REAL_AS_FLOAT", regexp.MustCompile(`(?i)\breal\b`), "the REAL type, which is treated as FLOAT rather than DOUBLE"},
+}
+
+// sqlModeSet returns the individual values of a comma-separated sql_mode.
+func sqlModeSet(sqlMode string) map[string]bool {
+	result := make(map[string]bool)
+	for _, mode := range strings.Split(strings.ToUpper(sqlMode), ",") {
+		if mode != "" {
+			result[mode] = true
+		}
+	}
+	return result
+}
+
+// strictSQLMode returns true if modes includes a strict mode, which is
+// required for NO_ZERO_DATE and NO_ZERO_IN_DATE to cause errors rather than
+// warnings.
+func strictSQLMode(modes map[string]bool) bool {
+	return modes["STRICT_TRANS_TABLES"] || modes["STRICT_ALL_TABLES"] || modes["TRADITIONAL"]
+}
+
+// SQLModeProblems compares the sql_mode of the workspace session which
+// executed the statements of wsSchema to targetSQLMode, and returns a
+// description of each statement which may behave differently on the target as
+// a result. This includes statements which succeeded in the workspace but would
+// be rejected on the target, such as columns with zero-date defaults;
+// statements which failed in the workspace due to sql_mode but may succeed on
+// the target; and statements whose text is parsed differently under the two
+// modes. If either sql_mode is unknown, or they are identical, nil is
+// returned.
+func (wsSchema *Schema) SQLModeProblems(targetSQLMode string) (problems []string) {
+	if wsSchema == nil || wsSchema.SQLMode == "" || targetSQLMode == "" {
+		return nil
+	}
+	wsModes, targetModes := sqlModeSet(wsSchema.SQLMode), sqlModeSet(targetSQLMode)
+	if onlyWorkspace, onlyTarget := SQLModeDifferences(wsSchema.SQLMode, targetSQLMode); len(onlyWorkspace)+len(onlyTarget) == 0 {
+		return nil
+	}
+
+	// Tables created successfully in the workspace, but with zero-date defaults
+	// which the target's sql_mode rejects
+	if wsSchema.Schema != nil && strictSQLMode(targetModes) {
+		for _, table := range wsSchema.Tables {
+			for _, col := range table.Columns {
+				if col.Type.Base != "timestamp" && !strings.HasPrefix(col.Type.Base, "date") {
+					continue
+				}
+				var mode string
+				if strings.HasPrefix(col.Default, "'0000-00-00") {
+					mode = "NO_ZERO_DATE"
+				} else if strings.HasPrefix(col.Default, "'0000-") || strings.Contains(col.Default, "-00") {
+					mode = "NO_ZERO_IN_DATE"
+				}
+				if mode != "" && targetModes[mode] && (!wsModes[mode] || !strictSQLMode(wsModes)) {
+					problems = append(problems, fmt.Sprintf("Column %s of %s has a default value of %s, which is permitted by the workspace's sql_mode but rejected by the target's %s and strict sql_mode", tengo.EscapeIdentifier(col.Name), table.ObjectKey(), col.Default, mode))
+				}
+			}
+		}
+	}
+
+	// Statements which failed in the workspace due to an invalid default or
+	// value, which may be permitted by the target's sql_mode
+	for _, stmtErr := range wsSchema.Failures {
+		if code := stmtErr.ErrorNumber(); code == 1067 || code == 1292 {
+			problems = append(problems, fmt.Sprintf("%s failed in the workspace with error %d, but may succeed on the target, which has a different sql_mode", stmtErr.ObjectKey(), code))
+		}
+	}
+
+	// Statements whose text is parsed differently depending on sql_mode
+	if wsSchema.LogicalSchema != nil {
+		statements := make([]*tengo.Statement, 0, len(wsSchema.LogicalSchema.Creates)+len(wsSchema.LogicalSchema.Alters))
+		for _, stmt := range wsSchema.LogicalSchema.Creates {
+			statements = append(statements, stmt)
+		}
+		sort.Slice(statements, func(i, j int) bool {
+			return statements[i].ObjectKey().String() < statements[j].ObjectKey().String()
+		})
+		statements = append(statements, wsSchema.LogicalSchema.Alters...)
+		for _, dep := range sqlModeTextDependencies {
+			if wsModes[dep.mode] == targetModes[dep.mode] {
+				continue
+			}
+			for _, stmt := range statements {
+				if dep.pattern.MatchString(stmt.Body()) {
+					problems = append(problems, fmt.Sprintf("%s contains %s under %s, which is enabled only in the %s sql_mode", stmt.ObjectKey(), dep.description, dep.mode, sqlModeSide(wsModes[dep.mode])))
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// SQLModeDifferences returns the sorted individual sql_mode values which are
+// present only in a, and those present only in b.
+func SQLModeDifferences(a, b string) (onlyA, onlyB []string) {
+	aModes, bModes := sqlModeSet(a), sqlModeSet(b)
+	for mode := range aModes {
+		if !bModes[mode] {
+			onlyA = append(onlyA, mode)
+		}
+	}
+	for mode := range bModes {
+		if !aModes[mode] {
+			onlyB = append(onlyB, mode)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
+
+func sqlModeSide(inWorkspace bool) string {
+	if inWorkspace {
+		return "workspace"
+	}
+	return "target"
+}
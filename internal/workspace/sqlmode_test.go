@@ -0,0 +1,71 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestSchemaSQLModeProblems(t *testing.T) {
+	statements, err := tengo.ParseStatementsInString("CREATE TABLE foo (id int unsigned NOT NULL, created date NOT NULL DEFAULT '0000-00-00', PRIMARY KEY (id));\n" +
+		"CREATE TABLE bar (id int, name varchar(30) DEFAULT \"anon\");\n")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseStatementsInString: %v", err)
+	}
+	logicalSchema := fs.NewLogicalSchema()
+	for _, stmt := range statements {
+		if err := logicalSchema.AddStatement(stmt); err != nil {
+			t.Fatalf("Unexpected error from AddStatement: %v", err)
+		}
+	}
+	wsSchema := &Schema{
+		Schema: &tengo.Schema{
+			Tables: []*tengo.Table{
+				{
+					Name: "foo",
+					Columns: []*tengo.Column{
+						{Name: "id", Type: tengo.ParseColumnType("int unsigned")},
+						{Name: "created", Type: tengo.ParseColumnType("date"), Default: "'0000-00-00'"},
+					},
+				},
+			},
+		},
+		LogicalSchema: logicalSchema,
+		SQLMode:       "NO_ENGINE_SUBSTITUTION",
+	}
+
+	// Identical or unknown sql_mode: no problems
+	for _, targetMode := range []string{"NO_ENGINE_SUBSTITUTION", ""} {
+		if problems := wsSchema.SQLModeProblems(targetMode); len(problems) > 0 {
+			t.Errorf("Expected no problems with target sql_mode %q, instead found %v", targetMode, problems)
+		}
+	}
+
+	// Differences which don't affect any statements: no problems
+	if problems := wsSchema.SQLModeProblems("NO_ENGINE_SUBSTITUTION,ONLY_FULL_GROUP_BY"); len(problems) > 0 {
+		t.Errorf("Expected no problems, instead found %v", problems)
+	}
+
+	// NO_ZERO_DATE without strict mode only causes warnings, not errors
+	if problems := wsSchema.SQLModeProblems("NO_ZERO_DATE,NO_ENGINE_SUBSTITUTION"); len(problems) > 0 {
+		t.Errorf("Expected no problems, instead found %v", problems)
+	}
+
+	problems := wsSchema.SQLModeProblems("STRICT_TRANS_TABLES,NO_ZERO_DATE,ANSI_QUOTES,NO_ENGINE_SUBSTITUTION")
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, instead found %d: %v", len(problems), problems)
+	}
+	if !strings.Contains(problems[0], "`created`") || !strings.Contains(problems[0], "NO_ZERO_DATE") {
+		t.Errorf("Unexpected first problem: %s", problems[0])
+	}
+	if !strings.Contains(problems[1], "table `bar`") || !strings.Contains(problems[1], "ANSI_QUOTES") {
+		t.Errorf("Unexpected second problem: %s", problems[1])
+	}
+
+	onlyA, onlyB := SQLModeDifferences("STRICT_TRANS_TABLES,ANSI_QUOTES,NO_ENGINE_SUBSTITUTION", "no_engine_substitution,NO_ZERO_DATE")
+	if strings.Join(onlyA, ",") != "ANSI_QUOTES,STRICT_TRANS_TABLES" || strings.Join(onlyB, ",") != "NO_ZERO_DATE" {
+		t.Errorf("Unexpected result from SQLModeDifferences: %v, %v", onlyA, onlyB)
+	}
+}
@@ -0,0 +1,157 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// SyntaxError represents a single problem found by CheckSyntax, along with its
+// position in the checked SQL.
+type SyntaxError struct {
+	File        string // file path supplied to CheckSyntax, if any
+	Line        int    // line number, starting at 1
+	Column      int    // column number, starting at 1
+	Message     string // description of the problem
+	ErrorNumber uint16 // server error code, or 0 if found without a workspace
+}
+
+// Error satisfies the builtin error interface.
+func (se *SyntaxError) Error() string {
+	return se.Location() + ": " + se.Message
+}
+
+// Location returns a string of the form "file:line:column". If the file path
+// is unknown, "unknown" is used in its place, consistent with
+// tengo.Statement.Location().
+func (se *SyntaxError) Location() string {
+	file := se.File
+	if file == "" {
+		file = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%d", file, se.Line, se.Column)
+}
+
+// CheckSyntax validates the SQL statements in sql, returning one SyntaxError
+// per problem found. The supplied filePath is only used for reporting
+// positions, and may be blank.
+//
+// If opts is nil, only offline checks are performed, using Skeema's own SQL
+// parser: this detects unterminated quotes or comments, statements which
+// cannot be parsed or are not supported in *.sql files, and duplicate
+// definitions. Otherwise, the supported CREATE statements are also executed in
+// the workspace described by opts, which detects all syntax errors and most
+// other errors for the workspace's flavor.
+//
+// The error return value only represents fatal problems, such as the inability
+// to obtain a workspace.
+func CheckSyntax(sql, filePath string, opts *Options) ([]*SyntaxError, error) {
+	var result []*SyntaxError
+	statements, err := tengo.ParseStatements(strings.NewReader(sql), filePath)
+	var mse *tengo.MalformedSQLError
+	if errors.As(err, &mse) {
+		// The parser halts upon malformed SQL, so any statements that it did return
+		// are still checked, but the final one is incomplete
+		if len(statements) > 0 {
+			statements = statements[:len(statements)-1]
+		}
+		line, col := mse.Position()
+		result = append(result, &SyntaxError{
+			File:    filePath,
+			Line:    line,
+			Column:  max(col, 1),
+			Message: strings.TrimPrefix(mse.Error(), "File "+filePath+": "),
+		})
+	} else if err != nil {
+		return nil, err
+	}
+
+	logicalSchema := fs.NewLogicalSchema()
+	for _, stmt := range statements {
+		var message string
+		switch stmt.Type {
+		case tengo.StatementTypeUnknown:
+			message = "Unable to parse statement, or statement type is not supported"
+		case tengo.StatementTypeCreateUnsupported:
+			message = "CREATE TABLE ... SELECT and system-versioned tables are not supported"
+		case tengo.StatementTypeCreate, tengo.StatementTypeAlter:
+			if err := logicalSchema.AddStatement(stmt); err != nil {
+				message = err.Error()
+			}
+		}
+		if message != "" {
+			result = append(result, &SyntaxError{
+				File:    filePath,
+				Line:    stmt.LineNo,
+				Column:  stmt.CharNo,
+				Message: message,
+			})
+		}
+	}
+	if opts == nil || logicalSchema.Empty() {
+		return result, nil
+	}
+
+	wsSchema, err := ExecLogicalSchema(logicalSchema, *opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmtErr := range wsSchema.Failures {
+		se := &SyntaxError{
+			File:        filePath,
+			Message:     strings.TrimPrefix(stmtErr.Err.Error(), "Error executing DDL in workspace: "),
+			ErrorNumber: stmtErr.ErrorNumber(),
+		}
+		se.Line, se.Column = serverErrorPosition(stmtErr.Statement, stmtErr.Err)
+		result = append(result, se)
+	}
+	return result, nil
+}
+
+// reSyntaxErrorNear matches the position suffix of a server syntax error
+// message, e.g. "... for the right syntax to use near 'foo' at line 3"
+var reSyntaxErrorNear = regexp.MustCompile(`(?s)near '(.*)' at line (\d+)$`)
+
+// serverErrorPosition returns the line and column of err, which resulted from
+// executing stmt. For syntax errors, the server reports the problematic text
+// and its line relative to the start of the statement, which is used to narrow
+// down the position. Otherwise, the position of the start of stmt is returned.
+func serverErrorPosition(stmt *tengo.Statement, err error) (line, col int) {
+	var merr *mysql.MySQLError
+	if !errors.As(err, &merr) || !tengo.IsSyntaxError(err) {
+		return stmt.LineNo, stmt.CharNo
+	}
+	matches := reSyntaxErrorNear.FindStringSubmatch(merr.Message)
+	if matches == nil {
+		return stmt.LineNo, stmt.CharNo
+	}
+	relLine, _ := strconv.Atoi(matches[2])
+	lines := strings.Split(stmt.Body(), "\n")
+	if relLine < 1 || relLine > len(lines) {
+		return stmt.LineNo, stmt.CharNo
+	}
+	line = stmt.LineNo + relLine - 1
+
+	// The near text may span multiple lines, and is truncated by the server, so
+	// only its first line is searched for. If it is blank, the problem is at the
+	// end of the statement.
+	near, _, _ := strings.Cut(matches[1], "\n")
+	lineText := lines[relLine-1]
+	if near == "" {
+		col = len(lineText) + 1
+	} else if pos := strings.Index(lineText, near); pos >= 0 {
+		col = pos + 1
+	} else {
+		col = 1
+	}
+	if relLine == 1 {
+		col += stmt.CharNo - 1
+	}
+	return line, col
+}
@@ -0,0 +1,64 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestCheckSyntaxOffline(t *testing.T) {
+	sql := "CREATE TABLE foo (id int);\n" +
+		"\n" +
+		"CREATE TABL bar (id int);\n" +
+		"CREATE TABLE foo (name varchar(30));\n" +
+		"  CREATE TABLE `baz (id int);\n"
+	errs, err := CheckSyntax(sql, "snippet.sql", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error from CheckSyntax: %v", err)
+	}
+	expected := []struct {
+		line, col int
+	}{
+		{5, 16}, // unterminated quote
+		{3, 1},  // unparseable statement
+		{4, 1},  // duplicate definition of foo
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, instead found %d: %v", len(expected), len(errs), errs)
+	}
+	for n, exp := range expected {
+		if errs[n].File != "snippet.sql" || errs[n].Line != exp.line || errs[n].Column != exp.col || errs[n].ErrorNumber != 0 {
+			t.Errorf("Unexpected error[%d]: %+v", n, *errs[n])
+		}
+	}
+	if expected := "snippet.sql:5:16: Identifier is missing closing quote at line 5, column 16"; errs[0].Error() != expected {
+		t.Errorf("Unexpected error string:\nexpected: %s\nfound:    %s", expected, errs[0].Error())
+	}
+
+	if errs, err := CheckSyntax("CREATE TABLE foo (id int);\nCREATE FUNCTION f() RETURNS int RETURN 1;\n", "", nil); err != nil || len(errs) > 0 {
+		t.Errorf("Expected no errors, instead found %v, %v", errs, err)
+	}
+}
+
+func TestServerErrorPosition(t *testing.T) {
+	statements, err := tengo.ParseStatementsInString("\n  CREATE TABLE foo (\n  id int,\n  name varchar(30) NOT NUL\n);\n")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing statements: %v", err)
+	}
+	stmt := statements[1]
+	cases := []struct {
+		err       error
+		line, col int
+	}{
+		{&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'NOT NUL\n)' at line 3"}, 4, 20},
+		{&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'TABLE foo (' at line 1"}, 2, 10},
+		{&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near '' at line 4"}, 5, 2},
+		{&mysql.MySQLError{Number: 1113, Message: "A table must have at least 1 column"}, 2, 3},
+	}
+	for n, c := range cases {
+		if line, col := serverErrorPosition(stmt, c.err); line != c.line || col != c.col {
+			t.Errorf("Case %d: expected position %d:%d, instead found %d:%d", n, c.line, c.col, line, col)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package workspace
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -56,12 +57,28 @@ func NewTempSchema(opts Options) (_ *TempSchema, retErr error) {
 		ts.dropChunkSize++
 	}
 
-	lockName := fmt.Sprintf("skeema.%s", ts.schemaName)
-	if ts.releaseLock, err = getLock(ts.inst, lockName, opts.LockTimeout); err != nil {
+	// With a schema pool, use whichever pooled schema name can be locked first.
+	// Each pooled schema is only ever used by the process holding its lock.
+	schemaNames := []string{ts.schemaName}
+	if opts.SchemaPoolSize > 0 {
+		schemaNames = PooledSchemaNames(ts.schemaName, opts.SchemaPoolSize)
+	}
+	lockNames := make([]string, len(schemaNames))
+	for n, schemaName := range schemaNames {
+		lockNames[n] = fmt.Sprintf("skeema.%s", schemaName)
+	}
+	var lockIndex int
+	if ts.releaseLock, lockIndex, err = getAnyLock(ts.inst, lockNames, opts.LockTimeout); err != nil {
+		if opts.SchemaPoolSize > 0 {
+			return nil, fmt.Errorf("Unable to lock any of %d pooled temp-schema workspaces on %s: %s\n"+
+				"Usually this means other copies of Skeema are already holding all of the locks and operating on this database server. Try increasing --temp-schema-pool-size to permit more concurrent runs.",
+				len(lockNames), ts.inst, err)
+		}
 		return nil, fmt.Errorf("Unable to lock temp-schema workspace on %s: %s\n"+
 			"Usually this means another copy of Skeema is already holding the lock and operating on this database server. If you are certain that your operation will not conflict, try supplying a different name for --temp-schema on the command-line.",
 			ts.inst, err)
 	}
+	ts.schemaName = schemaNames[lockIndex]
 
 	// If NewTempSchema errors, don't continue to hold the lock
 	defer func() {
@@ -110,6 +127,30 @@ func NewTempSchema(opts Options) (_ *TempSchema, retErr error) {
 	return ts, nil
 }
 
+// PooledSchemaNames returns the names of the temp schemas in a pool of the
+// supplied size, which are numbered suffixes of baseName.
+func PooledSchemaNames(baseName string, size int) []string {
+	names := make([]string, size)
+	for n := range names {
+		names[n] = fmt.Sprintf("%s_%d", baseName, n+1)
+	}
+	return names
+}
+
+// IsTempSchemaName returns true if name is either baseName or one of the
+// pooled schema names derived from it, regardless of pool size.
+func IsTempSchemaName(name, baseName string) bool {
+	if name == baseName {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(name, baseName+"_")
+	if !ok || suffix == "" || suffix[0] == '0' {
+		return false
+	}
+	_, err := strconv.ParseUint(suffix, 10, 16)
+	return err == nil
+}
+
 func (ts *TempSchema) bulkDropOptions() tengo.BulkDropOptions {
 	return tengo.BulkDropOptions{
 		ChunkSize:       ts.dropChunkSize,
@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -110,9 +111,7 @@ func (s WorkspaceIntegrationSuite) TestTempSchemaCleanupDrop(t *testing.T) {
 // keys and metadata locking. This is necessary because MySQL 8.0 extends
 // metadata locks across both sides of an FK, which can be problematic with DDL.
 func (s WorkspaceIntegrationSuite) TestTempSchemaCrossDBFK(t *testing.T) {
-	if !s.d.Flavor().MinMySQL(8) {
-		t.Skip("Test only relevant for flavors that extend metadata locks across FK relations")
-	}
+	tengo.SkipUnlessFlavor(t, "mysql:8+") // flavors that extend metadata locks across FK relations
 
 	s.sourceSQL(t, "crossdbfk-setup1.sql")
 
@@ -208,6 +207,53 @@ func (s WorkspaceIntegrationSuite) TestTempSchemaCrossDBFK(t *testing.T) {
 	}
 }
 
+func (s WorkspaceIntegrationSuite) TestTempSchemaPool(t *testing.T) {
+	opts := Options{
+		Type:                TypeTempSchema,
+		CleanupAction:       CleanupActionDrop,
+		Instance:            s.d.Instance,
+		SchemaName:          "_skeema_tmp",
+		SchemaPoolSize:      2,
+		DefaultCharacterSet: "latin1",
+		DefaultCollation:    "latin1_swedish_ci",
+		LockTimeout:         100 * time.Millisecond,
+		Concurrency:         5,
+	}
+	ts1, err := NewTempSchema(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewTempSchema: %s", err)
+	}
+	ts2, err := NewTempSchema(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewTempSchema: %s", err)
+	}
+	if ts1.schemaName != "_skeema_tmp_1" || ts2.schemaName != "_skeema_tmp_2" {
+		t.Errorf("Unexpected pooled schema names: %s, %s", ts1.schemaName, ts2.schemaName)
+	}
+	if _, err := NewTempSchema(opts); err == nil {
+		t.Fatal("Expected error from NewTempSchema with exhausted pool, instead err is nil")
+	}
+
+	// Once a pooled schema is released, it should become available again
+	if err := ts1.Cleanup(nil); err != nil {
+		t.Errorf("Unexpected error from cleanup: %s", err)
+	}
+	if has, err := ts1.inst.HasSchema(ts1.schemaName); has || err != nil {
+		t.Errorf("Expected pooled schema to be dropped upon cleanup: has=%t err=%v", has, err)
+	}
+	ts3, err := NewTempSchema(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewTempSchema: %s", err)
+	} else if ts3.schemaName != ts1.schemaName {
+		t.Errorf("Expected NewTempSchema to reuse %s, instead used %s", ts1.schemaName, ts3.schemaName)
+	}
+	for _, ts := range []*TempSchema{ts2, ts3} {
+		if err := ts.Cleanup(nil); err != nil {
+			t.Errorf("Unexpected error from cleanup: %s", err)
+		}
+	}
+}
+
 func TestTempSchemaNilInstance(t *testing.T) {
 	opts := Options{
 		Type:                TypeTempSchema,
@@ -223,3 +269,21 @@ func TestTempSchemaNilInstance(t *testing.T) {
 		t.Fatal("Expected non-nil error from NewTempSchema, but return was nil")
 	}
 }
+
+func TestPooledSchemaNames(t *testing.T) {
+	names := PooledSchemaNames("_skeema_tmp", 3)
+	expected := []string{"_skeema_tmp_1", "_skeema_tmp_2", "_skeema_tmp_3"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Unexpected result from PooledSchemaNames: %v", names)
+	}
+	for _, name := range append(names, "_skeema_tmp", "_skeema_tmp_12") {
+		if !IsTempSchemaName(name, "_skeema_tmp") {
+			t.Errorf("Expected IsTempSchemaName to return true for %q", name)
+		}
+	}
+	for _, name := range []string{"_skeema_tmp_", "_skeema_tmp_0", "_skeema_tmp_01", "_skeema_tmp_x", "_skeema_tmp_1x", "_skeema_tmp2", "skeema_tmp_1"} {
+		if IsTempSchemaName(name, "_skeema_tmp") {
+			t.Errorf("Expected IsTempSchemaName to return false for %q", name)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 // Package workspace provides functions for interacting with a temporary MySQL
 // schema. It manages creating a schema on a desired location (either an
-// existing MySQL instance, or a dynamically-controlled Docker instance),
+// existing MySQL instance, or a dynamically-controlled Docker instance or
+// Kubernetes pod),
 // running SQL DDL or DML, introspecting the resulting schema, and cleaning
-// up the schema when it is no longer needed.
+// up the schema when it is no longer needed. Alternatively, a schema can be
+// approximated without any database server, by only parsing the SQL.
 package workspace
 
 import (
@@ -10,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/tracing"
 )
 
 // Workspace represents a "scratch space" for DDL operations and schema
@@ -48,8 +52,24 @@ type Type int
 const (
 	TypeTempSchema  Type = iota // A temporary schema on a real pre-supplied Instance
 	TypeLocalDocker             // A schema on an ephemeral Docker container on localhost
+	TypeKubernetes              // A schema on an ephemeral database pod in a Kubernetes cluster
+	TypeParse                   // No database server; statements are only parsed, with limited fidelity
 )
 
+// String returns the workspace option value corresponding to t.
+func (t Type) String() string {
+	switch t {
+	case TypeLocalDocker:
+		return "docker"
+	case TypeKubernetes:
+		return "kubernetes"
+	case TypeParse:
+		return "parse"
+	default:
+		return "temp-schema"
+	}
+}
+
 // CleanupAction represents how to clean up a workspace.
 type CleanupAction int
 
@@ -70,6 +90,12 @@ const (
 	// CleanupActionDestroy means to destroy the MySQL instance container in
 	// Shutdown(). Only used with TypeLocalDocker.
 	CleanupActionDestroy
+
+	// CleanupActionPool means to leave the MySQL instance container running in
+	// Shutdown(), for reuse by subsequent invocations, which will stop it once it
+	// has been idle for longer than Options.IdleExpiry. Only used with
+	// TypeLocalDocker.
+	CleanupActionPool
 )
 
 // IntrospectionResult bundles a tengo.Schema with metadata from the workspace's
@@ -86,13 +112,23 @@ type Options struct {
 	Type                Type
 	CleanupAction       CleanupAction
 	Instance            *tengo.Instance // only TypeTempSchema
-	Flavor              tengo.Flavor    // only TypeLocalDocker
+	Flavor              tengo.Flavor    // only TypeLocalDocker, TypeKubernetes, or TypeParse
 	ContainerName       string          // only TypeLocalDocker
 	SchemaName          string
+	SchemaPoolSize      int // only TypeTempSchema; 0 means SchemaName is used as-is
 	DefaultCharacterSet string
 	DefaultCollation    string
-	DefaultConnParams   string // only TypeLocalDocker
-	RootPassword        string // only TypeLocalDocker
+	DefaultConnParams   string        // only TypeLocalDocker or TypeKubernetes
+	RootPassword        string        // only TypeLocalDocker or TypeKubernetes
+	DataTmpfs           bool          // only TypeLocalDocker; always true with CleanupActionDestroy
+	ServerArgs          []string      // only TypeLocalDocker; additional args for the database server in new containers
+	IdleExpiry          time.Duration // only TypeLocalDocker with CleanupActionPool
+	KubeNamespace       string        // only TypeKubernetes; blank means kubectl's current namespace
+	KubeImage           string        // only TypeKubernetes; blank means derive from Flavor
+	KubeCPU             string        // only TypeKubernetes; resource quantity, e.g. "500m"
+	KubeMemory          string        // only TypeKubernetes; resource quantity, e.g. "1Gi"
+	KubeStartTimeout    time.Duration // only TypeKubernetes
+	KubeMaxLifetime     time.Duration // only TypeKubernetes; pod is terminated by Kubernetes after this long
 	NameCaseMode        tengo.NameCaseMode
 	LockTimeout         time.Duration // max wait for workspace user-level locking, via GET_LOCK()
 	Concurrency         int
@@ -107,6 +143,10 @@ func New(opts Options) (Workspace, error) {
 		return NewTempSchema(opts)
 	case TypeLocalDocker:
 		return NewLocalDocker(opts)
+	case TypeKubernetes:
+		return NewKubernetes(opts)
+	case TypeParse:
+		return nil, ErrParseWorkspace
 	}
 	return nil, fmt.Errorf("Unsupported workspace type %v", opts.Type)
 }
@@ -117,7 +157,7 @@ func New(opts Options) (Workspace, error) {
 // This method relies on option definitions from AddCommandOptions(), as well
 // as the "flavor" option from util.AddGlobalOptions().
 func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
-	requestedType, err := dir.Config.GetEnum("workspace", "temp-schema", "docker")
+	requestedType, err := dir.Config.GetEnum("workspace", "temp-schema", "docker", "kubernetes", "parse")
 	if err != nil {
 		return Options{}, err
 	}
@@ -127,8 +167,31 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 		LockTimeout:   30 * time.Second,
 		Concurrency:   2,
 	}
-	if requestedType == "docker" {
+	if requestedType == "parse" {
+		// No database server is used, but an instance (if supplied) still provides
+		// the flavor and lower_case_table_names.
+		opts.Type = TypeParse
+		opts.Flavor = tengo.ParseFlavor(dir.Config.Get("flavor"))
+		if instance != nil {
+			opts.NameCaseMode = instance.NameCaseMode()
+			if !opts.Flavor.Known() {
+				opts.Flavor = instance.Flavor().Family()
+			}
+		}
+	} else if requestedType == "docker" || requestedType == "kubernetes" {
 		opts.Type = TypeLocalDocker
+		var matchVersion string
+		if requestedType == "kubernetes" {
+			opts.Type = TypeKubernetes
+		} else if matchVersion, err = dir.Config.GetEnum("docker-match-version", "none", "minor", "patch"); err != nil {
+			return Options{}, err
+		} else if runtime, err := dir.Config.GetEnum("docker-runtime", "auto", "docker", "podman", "nerdctl"); err != nil {
+			return Options{}, err
+		} else if err := tengo.SetContainerRuntime(runtime); err != nil {
+			return Options{}, err
+		} else if err := tengo.SetContainerHost(dir.Config.Get("docker-host")); err != nil {
+			return Options{}, err
+		}
 		opts.Flavor = tengo.ParseFlavor(dir.Config.Get("flavor"))
 		opts.SkipBinlog = true
 		if instance == nil {
@@ -142,7 +205,8 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 		} else {
 			// With an instance, we can copy the instance's default params (which
 			// typically came from connect-options / dir.InstanceDefaultParams anyway),
-			// sql_mode, lower_case_table_names, and (if needed) flavor.
+			// sql_mode, lower_case_table_names, and (if needed or requested via
+			// docker-match-version) flavor.
 			// Note that we're manually shoving the instance's sql_mode into the params;
 			// we need it present regardless of whether connect-options set it explicitly.
 			// Many companies use non-default global sql_mode, especially on RDS, and we
@@ -152,27 +216,58 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 			opts.DefaultConnParams = instance.BuildParamString(overrides)
 			opts.NameCaseMode = instance.NameCaseMode()
 			instFlavor := instance.Flavor()
-			if !opts.Flavor.Known() {
+			if matchVersion == "patch" && instFlavor.Known() {
+				opts.Flavor = instFlavor
+			} else if (matchVersion == "minor" && instFlavor.Known()) || !opts.Flavor.Known() {
 				opts.Flavor = instFlavor.Family()
 			}
 			// Percona Server 8.0 or 8.4 on ARM: we need a specific patch release, due to
 			// how ARM images are tagged on DockerHub. If the flavor option is missing a
 			// patch number but is otherwise equal to the real instance flavor, copy the
 			// patch release number from the instance.
-			if opts.Flavor.IsPercona(8) && instFlavor.Version[2] > 0 && opts.Flavor == instFlavor.Family() {
+			if opts.Type == TypeLocalDocker && opts.Flavor.IsPercona(8) && instFlavor.Version[2] > 0 && opts.Flavor == instFlavor.Family() {
 				if arch, _ := tengo.DockerEngineArchitecture(); arch == "arm64" {
 					opts.Flavor = instFlavor
 				}
 			}
 		}
+		if opts.Type == TypeKubernetes {
+			opts.KubeNamespace = dir.Config.Get("kubernetes-namespace")
+			opts.KubeImage = dir.Config.Get("kubernetes-image")
+			opts.KubeCPU = dir.Config.Get("kubernetes-cpu")
+			opts.KubeMemory = dir.Config.Get("kubernetes-memory")
+			if opts.KubeStartTimeout, err = time.ParseDuration(dir.Config.Get("kubernetes-start-timeout")); err != nil || opts.KubeStartTimeout <= 0 {
+				return Options{}, errors.New("option kubernetes-start-timeout must be a positive duration, for example \"5m\"")
+			}
+			if opts.KubeMaxLifetime, err = time.ParseDuration(dir.Config.Get("kubernetes-max-lifetime")); err != nil || opts.KubeMaxLifetime <= opts.KubeStartTimeout {
+				return Options{}, errors.New("option kubernetes-max-lifetime must be a duration longer than kubernetes-start-timeout, for example \"2h\"")
+			}
+			return opts, nil
+		}
 		opts.ContainerName = "skeema-" + tengo.ContainerNameForImage(opts.Flavor.String())
-		if cleanup, err := dir.Config.GetEnum("docker-cleanup", "none", "stop", "destroy"); err != nil {
+		if opts.ServerArgs = strings.Fields(dir.Config.Get("docker-server-args")); len(opts.ServerArgs) > 0 {
+			for _, arg := range opts.ServerArgs {
+				if !strings.HasPrefix(arg, "--") || len(arg) < 3 {
+					return Options{}, fmt.Errorf("option docker-server-args must consist of server options beginning with \"--\", but found %q", arg)
+				}
+			}
+			// Containers with different server configurations must not be reused
+			// interchangeably, so the container name includes a hash of the args
+			opts.ContainerName += "-" + serverArgsHash(opts.ServerArgs)
+		}
+		if cleanup, err := dir.Config.GetEnum("docker-cleanup", "none", "stop", "destroy", "pool"); err != nil {
 			return Options{}, err
 		} else if cleanup == "stop" {
 			opts.CleanupAction = CleanupActionStop
 		} else if cleanup == "destroy" {
 			opts.CleanupAction = CleanupActionDestroy
+		} else if cleanup == "pool" {
+			opts.CleanupAction = CleanupActionPool
+			if opts.IdleExpiry, err = time.ParseDuration(dir.Config.Get("docker-idle-expiry")); err != nil || opts.IdleExpiry <= 0 {
+				return Options{}, errors.New("option docker-idle-expiry must be a positive duration, for example \"30m\"")
+			}
 		}
+		opts.DataTmpfs = (opts.CleanupAction == CleanupActionDestroy || dir.Config.GetBool("docker-tmpfs"))
 	} else {
 		opts.Type = TypeTempSchema
 		opts.Instance = instance
@@ -187,6 +282,13 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 		} else {
 			opts.Concurrency = concurrency
 		}
+		if poolSize, err := dir.Config.GetInt("temp-schema-pool-size"); err != nil {
+			return Options{}, err
+		} else if poolSize < 0 {
+			return Options{}, errors.New("temp-schema-pool-size cannot be negative")
+		} else {
+			opts.SchemaPoolSize = poolSize
+		}
 		binlogEnum, err := dir.Config.GetEnum("temp-schema-binlog", "on", "off", "auto")
 		if err != nil {
 			return Options{}, err
@@ -206,8 +308,21 @@ func AddCommandOptions(cmd *mybase.Command) {
 		mybase.StringOption("temp-schema", 't', "_skeema_tmp", "Name of temporary schema for intermediate operations, created and dropped each run"),
 		mybase.StringOption("temp-schema-binlog", 0, "auto", `Controls whether temp schema DDL operations are replicated (valid values: "on", "off", "auto")`),
 		mybase.StringOption("temp-schema-threads", 0, "5", "Max number of concurrent CREATE/DROP with workspace=temp-schema"),
-		mybase.StringOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker")`),
-		mybase.StringOption("docker-cleanup", 0, "none", `With --workspace=docker, specifies how to clean up containers (valid values: "none", "stop", "destroy")`),
+		mybase.StringOption("temp-schema-pool-size", 0, "0", "With workspace=temp-schema, use the first unlocked of this many numbered temp schemas, permitting concurrent runs against one server"),
+		mybase.StringOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker", "kubernetes", "parse")`),
+		mybase.StringOption("docker-cleanup", 0, "none", `With --workspace=docker, specifies how to clean up containers (valid values: "none", "stop", "destroy", "pool")`),
+		mybase.StringOption("docker-idle-expiry", 0, "30m", "With --docker-cleanup=pool, stop pooled containers which have not been used for this long"),
+		mybase.StringOption("docker-runtime", 0, "auto", `With --workspace=docker, specifies which container runtime client to use (valid values: "auto", "docker", "podman", "nerdctl")`),
+		mybase.StringOption("docker-match-version", 0, "none", `With --workspace=docker, derive the image from the live server's version instead of --flavor (valid values: "none", "minor", "patch")`),
+		mybase.StringOption("docker-host", 0, "", "With --workspace=docker, container engine host to use, e.g. ssh://user@host (default from DOCKER_HOST env var or current docker context)"),
+		mybase.StringOption("docker-server-args", 0, "", `With --workspace=docker, additional options for the database server in new containers, e.g. "--sql-mode=STRICT_ALL_TABLES --character-set-server=latin1"`),
+		mybase.BoolOption("docker-tmpfs", 0, false, "With --workspace=docker, store new containers' data in memory and tune the server for ephemeral use"),
+		mybase.StringOption("kubernetes-namespace", 0, "", "With --workspace=kubernetes, namespace for the database pod (default kubectl's current namespace)"),
+		mybase.StringOption("kubernetes-image", 0, "", "With --workspace=kubernetes, image for the database pod (default based on --flavor)"),
+		mybase.StringOption("kubernetes-cpu", 0, "1", "With --workspace=kubernetes, CPU request and limit for the database pod"),
+		mybase.StringOption("kubernetes-memory", 0, "1Gi", "With --workspace=kubernetes, memory request and limit for the database pod"),
+		mybase.StringOption("kubernetes-start-timeout", 0, "5m", "With --workspace=kubernetes, maximum time to wait for the database pod to be ready"),
+		mybase.StringOption("kubernetes-max-lifetime", 0, "2h", "With --workspace=kubernetes, maximum lifetime of the database pod, after which Kubernetes terminates it even if Skeema exited uncleanly"),
 		mybase.BoolOption("reuse-temp-schema", 0, false, "Do not drop temp-schema when done").Hidden(), // DEPRECATED -- hidden for this reason
 	)
 }
@@ -289,6 +404,7 @@ type Schema struct {
 	*tengo.Schema
 	LogicalSchema *fs.LogicalSchema
 	Flavor        tengo.Flavor
+	SQLMode       string // session sql_mode used to execute statements, if known
 	Failures      []*StatementError
 }
 
@@ -310,7 +426,25 @@ func (wsSchema *Schema) FailedKeys() (result []tengo.ObjectKey) {
 // only represents fatal errors that prevented the entire process.
 // Note that if opts.NameCaseMode > tengo.NameCaseAsIs, logicalSchema may be
 // modified in-place to force some identifiers to lowercase.
-func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema, retErr error) {
+func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (*Schema, error) {
+	return execLogicalSchema(logicalSchema, opts, nil)
+}
+
+// ExecLogicalSchemaFunc behaves like ExecLogicalSchema, but additionally calls
+// f after the workspace schema has been introspected, and before the workspace
+// is cleaned up. This permits the caller to run other statements in the
+// workspace, for example DML for testing stored routines. Any error returned by
+// f is returned as-is. f must leave all tables in the workspace empty, since
+// cleanup of some workspace types refuses to drop tables containing rows.
+// ErrParseWorkspace is returned if opts.Type is TypeParse.
+func ExecLogicalSchemaFunc(logicalSchema *fs.LogicalSchema, opts Options, f func(Workspace, *Schema) error) (*Schema, error) {
+	if opts.Type == TypeParse {
+		return nil, ErrParseWorkspace
+	}
+	return execLogicalSchema(logicalSchema, opts, f)
+}
+
+func execLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options, f func(Workspace, *Schema) error) (_ *Schema, retErr error) {
 	if logicalSchema.CharSet != "" {
 		opts.DefaultCharacterSet = logicalSchema.CharSet
 	}
@@ -323,12 +457,26 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 		}
 	}
 
+	if opts.Type == TypeParse {
+		return execParsedLogicalSchema(logicalSchema, opts), nil
+	}
+
+	wsSpan := tracing.Start(nil, "workspace",
+		tracing.String("db.name", logicalSchema.Name),
+		tracing.String("skeema.workspace_type", opts.Type.String()),
+		tracing.Int("skeema.statement_count", int64(len(logicalSchema.Creates)+len(logicalSchema.Alters))),
+	)
+	defer func() {
+		wsSpan.End(retErr)
+	}()
+	span := tracing.Start(wsSpan, "create workspace")
 	ws, err := New(opts)
+	span.End(err)
 	if err != nil {
 		return nil, err
 	}
 
-	// ExecLogicalSchema names its error return so that a deferred func can check
+	// execLogicalSchema names its error return so that a deferred func can check
 	// if an error occurred, but otherwise intentionally does not use named return
 	// variables, and instead declares new local vars for all other usage. This is
 	// to avoid mistakes with variable shadowing, nil pointer panics, etc which are
@@ -353,8 +501,13 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 	if err != nil {
 		return nil, fmt.Errorf("Cannot connect to workspace: %w", err)
 	}
+	// Record the session sql_mode actually in effect, which may differ from the
+	// instance's global value due to connection params or fallback logic in the
+	// workspace type's ConnectionPool. Errors here are non-fatal.
+	db.QueryRow("SELECT @@SESSION.sql_mode").Scan(&wsSchema.SQLMode)
 
 	// Run CREATEs in parallel, bounded by opts.Concurrency
+	span = tracing.Start(wsSpan, "execute workspace statements")
 	creates := make(chan *tengo.Statement, opts.Concurrency)
 	errs := make(chan error, opts.Concurrency)
 	go func() {
@@ -402,10 +555,17 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 			wsSchema.Failures = append(wsSchema.Failures, wrapFailure(statement, err))
 		}
 	}
+	span.SetAttributes(tracing.Int("skeema.failure_count", int64(len(wsSchema.Failures))))
+	span.End(nil)
 
+	span = tracing.Start(wsSpan, "introspect workspace")
 	result, err := ws.IntrospectSchema()
+	span.End(err)
 	wsSchema.Schema = result.Schema
 	wsSchema.Flavor = result.Flavor
+	if err == nil && f != nil {
+		err = f(ws, wsSchema)
+	}
 
 	return wsSchema, err
 }
@@ -426,20 +586,27 @@ func wrapFailure(statement *tengo.Statement, err error) *StatementError {
 type releaseFunc func()
 
 func getLock(instance *tengo.Instance, lockName string, maxWait time.Duration) (releaseFunc, error) {
+	release, _, err := getAnyLock(instance, []string{lockName}, maxWait)
+	return release, err
+}
+
+// getAnyLock obtains whichever of the supplied lock names is available first,
+// returning its release function and its index in lockNames.
+func getAnyLock(instance *tengo.Instance, lockNames []string, maxWait time.Duration) (releaseFunc, int, error) {
 	db, err := instance.CachedConnectionPool("", "")
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	lockConn, err := db.Conn(context.Background())
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	done := make(chan struct{})
 	release := func() {
 		close(done)
 	}
-	connMaintainer := func() {
+	connMaintainer := func(lockName string) {
 		var result int
 		defer lockConn.Close()
 		for {
@@ -463,18 +630,26 @@ func getLock(instance *tengo.Instance, lockName string, maxWait time.Duration) (
 	var getLockResult, attempts int
 	start := time.Now()
 	for time.Since(start) < maxWait {
-		// Only using a timeout of 1 sec on each query to avoid potential issues with
-		// query killers, spurious slow query logging, etc
-		err := lockConn.QueryRowContext(context.Background(), "SELECT GET_LOCK(?, 1)", lockName).Scan(&getLockResult)
-		if err == nil && getLockResult == 1 {
-			// Launch a goroutine to keep the connection active, and release the lock
-			// once the ReleaseFunc is called
-			go connMaintainer()
-			return release, nil
+		for n, lockName := range lockNames {
+			// Only using a timeout of 1 sec on each query to avoid potential issues with
+			// query killers, spurious slow query logging, etc. With multiple lock names,
+			// only the last one in each pass waits at all.
+			var wait int
+			if n == len(lockNames)-1 {
+				wait = 1
+			}
+			err := lockConn.QueryRowContext(context.Background(), "SELECT GET_LOCK(?, ?)", lockName, wait).Scan(&getLockResult)
+			if err == nil && getLockResult == 1 {
+				// Launch a goroutine to keep the connection active, and release the lock
+				// once the ReleaseFunc is called
+				go connMaintainer(lockName)
+				return release, n, nil
+			}
 		}
 		if attempts++; attempts == 3 {
 			log.Warnf("Obtaining a workspace lock on %s is taking longer than expected. Some other Skeema process or thread may be holding the lock already. This operation will be re-attempted for up to %s total.", instance, maxWait)
 		}
 	}
-	return nil, errors.New("Unable to acquire lock before timeout")
+	lockConn.Close()
+	return nil, 0, errors.New("Unable to acquire lock before timeout")
 }
@@ -161,6 +161,44 @@ func (s WorkspaceIntegrationSuite) TestExecLogicalSchemaFK(t *testing.T) {
 	}
 }
 
+func (s WorkspaceIntegrationSuite) TestExecLogicalSchemaFunc(t *testing.T) {
+	dir := s.getParsedDir(t, "testdata/simple", "")
+	opts, err := OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %s", err)
+	}
+	opts.LockTimeout = 100 * time.Millisecond
+
+	// The callback should be able to query the workspace's tables before cleanup
+	var called bool
+	wsSchema, err := ExecLogicalSchemaFunc(dir.LogicalSchemas[0], opts, func(ws Workspace, wsSchema *Schema) error {
+		called = true
+		db, err := ws.ConnectionPool("")
+		if err != nil {
+			return err
+		}
+		var count int
+		return db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from ExecLogicalSchemaFunc: %s", err)
+	} else if !called || wsSchema.Table("users") == nil {
+		t.Errorf("Unexpected result from ExecLogicalSchemaFunc: called=%t, schema=%+v", called, wsSchema.Schema)
+	}
+
+	// Errors from the callback should be returned
+	callbackErr := fmt.Errorf("callback error")
+	if _, err := ExecLogicalSchemaFunc(dir.LogicalSchemas[0], opts, func(Workspace, *Schema) error { return callbackErr }); err != callbackErr {
+		t.Errorf("Expected callback error to be returned, instead found %v", err)
+	}
+
+	// Parse workspaces are not supported
+	opts.Type = TypeParse
+	if _, err := ExecLogicalSchemaFunc(dir.LogicalSchemas[0], opts, func(Workspace, *Schema) error { return nil }); err != ErrParseWorkspace {
+		t.Errorf("Expected ErrParseWorkspace, instead found %v", err)
+	}
+}
+
 func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 	getOpts := func(cliFlags string) Options {
 		t.Helper()
@@ -191,6 +229,13 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 	// Test error conditions
 	assertOptsError("--workspace=invalid", true)
 	assertOptsError("--workspace=docker --docker-cleanup=invalid", true)
+	assertOptsError("--workspace=docker --docker-runtime=lxc", true)
+	assertOptsError("--workspace=docker --docker-host=tcp://docker.example.com:2376", true)
+	assertOptsError("--workspace=docker --docker-match-version=major", true)
+	assertOptsError("--workspace=docker --docker-server-args=sql-mode=ANSI", true)
+	assertOptsError("--workspace=kubernetes --kubernetes-start-timeout=forever", true)
+	assertOptsError("--workspace=docker --docker-cleanup=pool --docker-idle-expiry=soon", true)
+	assertOptsError("--workspace=docker --docker-cleanup=pool --docker-idle-expiry=0", true)
 	assertOptsError("--workspace=docker --connect-options='autocommit=0'", false)
 	assertOptsError("--workspace=temp-schema --temp-schema-threads=0", true)
 	assertOptsError("--workspace=temp-schema --temp-schema-threads=-20", true)
@@ -215,7 +260,7 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 		expectFlavorString = s.d.Flavor().String()
 	}
 	opts = getOpts("--workspace=docker")
-	if opts.Type != TypeLocalDocker || opts.CleanupAction != CleanupActionNone || opts.Flavor.String() != expectFlavorString {
+	if opts.Type != TypeLocalDocker || opts.CleanupAction != CleanupActionNone || opts.DataTmpfs || opts.Flavor.String() != expectFlavorString {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
 
@@ -223,9 +268,28 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 	if opts = getOpts("--workspace=docker --docker-cleanup=StOp"); opts.CleanupAction != CleanupActionStop {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
-	if opts = getOpts("--workspace=docker --docker-cleanup=destroy"); opts.CleanupAction != CleanupActionDestroy {
+	if opts = getOpts("--workspace=docker --docker-cleanup=destroy"); opts.CleanupAction != CleanupActionDestroy || !opts.DataTmpfs {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+	if opts = getOpts("--workspace=docker --docker-tmpfs"); opts.CleanupAction != CleanupActionNone || !opts.DataTmpfs {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+	if opts = getOpts("--workspace=kubernetes --kubernetes-namespace=ci"); opts.Type != TypeKubernetes || opts.KubeNamespace != "ci" || opts.KubeMemory != "1Gi" || opts.KubeStartTimeout != 5*time.Minute || opts.KubeMaxLifetime != 2*time.Hour {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+	if opts = getOpts("--workspace=docker --docker-cleanup=pool"); opts.CleanupAction != CleanupActionPool || opts.IdleExpiry != 30*time.Minute {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+
+	// Test docker with custom server args, which should affect container name
+	defaultName := getOpts("--workspace=docker").ContainerName
+	opts = getOpts("--workspace=docker --docker-server-args='--sql-mode=ANSI  --character-set-server=latin1'")
+	if len(opts.ServerArgs) != 2 || opts.ServerArgs[0] != "--sql-mode=ANSI" || !strings.HasPrefix(opts.ContainerName, defaultName+"-") {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
+	if otherName := getOpts("--workspace=docker --docker-server-args=--sql-mode=ANSI").ContainerName; otherName == opts.ContainerName {
+		t.Errorf("Expected different server args to yield different container names, but both were %s", otherName)
+	}
 
 	// Test docker with specific flavor
 	if opts = getOpts("--workspace=docker --flavor=mysql:5.5"); opts.Flavor.String() != "mysql:5.5" {
@@ -245,6 +309,15 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 	if patch := opts.Flavor.Version[2]; patch != expectPatch {
 		t.Errorf("Expected Flavor option patch release number to be %d, instead found %d", expectPatch, patch)
 	}
+
+	// docker-match-version should override the flavor option with the instance's
+	// version, either with or without the patch release number
+	if opts = getOpts("--workspace=docker --flavor=mysql:5.5 --docker-match-version=minor"); opts.Flavor.String() != "percona:8.0" {
+		t.Errorf("Unexpected flavor %s with docker-match-version=minor", opts.Flavor)
+	}
+	if opts = getOpts("--workspace=docker --flavor=mysql:5.5 --docker-match-version=patch"); opts.Flavor.String() != "percona:8.0.35" {
+		t.Errorf("Unexpected flavor %s with docker-match-version=patch", opts.Flavor)
+	}
 	s.d.ForceFlavor(realFlavor)
 
 	// Mess with the instance and its sql_mode, to simulate docker workspace using
@@ -291,6 +364,10 @@ func (s *WorkspaceIntegrationSuite) Teardown(backend string) error {
 	return tengo.SkeemaTestContainerCleanup(s.d)
 }
 
+func (s *WorkspaceIntegrationSuite) BackendFlavor(backend string) tengo.Flavor {
+	return s.d.Flavor()
+}
+
 func (s *WorkspaceIntegrationSuite) BeforeTest(backend string) error {
 	return s.d.NukeData()
 }
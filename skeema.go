@@ -5,7 +5,9 @@ import (
 	"os"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tracing"
 	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/skeema/internal/workspace"
 )
@@ -46,8 +48,21 @@ func main() {
 		Exit(WrapExitCode(CodeBadConfig, err))
 	}
 
+	if err := tracing.Configure(cfg.Get("otlp-endpoint"), "skeema "+cfg.CLI.Command.Name, versionString()); err != nil {
+		Exit(WrapExitCode(CodeBadConfig, err))
+	}
+
 	err = cfg.HandleCommand()
 	workspace.Shutdown()
+	// Only mark the root span as failed for fatal errors, not for exit code 1
+	// which merely indicates differences were found
+	traceStatus := err
+	if ExitCode(err) <= CodeDifferencesFound {
+		traceStatus = nil
+	}
+	if traceErr := tracing.Shutdown(traceStatus); traceErr != nil {
+		log.Warnf("Unable to export trace spans: %v", traceErr)
+	}
 	Exit(err)
 }
 
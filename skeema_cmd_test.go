@@ -10,6 +10,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
 )
@@ -140,6 +141,52 @@ func (s SkeemaIntegrationSuite) TestInitHandler(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir hasoptionfile --schema product -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 }
 
+func (s SkeemaIntegrationSuite) TestInitFromDump(t *testing.T) {
+	dump := "-- MySQL dump 10.13\n" +
+		"CREATE DATABASE /*!32312 IF NOT EXISTS*/ `product` /*!40100 DEFAULT CHARACTER SET latin1 COLLATE latin1_swedish_ci */;\n" +
+		"USE `product`;\n" +
+		"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(30) NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=latin1;\n" +
+		"INSERT INTO `users` VALUES (1,'alice');\n" +
+		"USE `analytics`;\n" +
+		"CREATE TABLE `pageviews` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	if err := os.WriteFile("dump.sql", []byte(dump), 0666); err != nil {
+		t.Fatalf("Unable to write dump file: %v", err)
+	}
+
+	// Invalid option combinations
+	s.handleCommand(t, CodeBadUsage, ".", "skeema init --from-dump=dump.sql -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --from-dump=dump.sql --dir bad1")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --from-dump=dump.sql --dir bad2 --workspace=docker")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --from-dump=dump.sql --dir bad3 --workspace=parse --flavor=mysql:8.0 --schema=doesnt_exist")
+	s.handleCommand(t, CodeNoInput, ".", "skeema init --from-dump=doesnt-exist.sql --workspace=parse --flavor=mysql:8.0")
+
+	// Default dir name is based on the dump file name
+	cfg := s.handleCommand(t, CodeSuccess, ".", "skeema init --from-dump=dump.sql --workspace=parse --flavor=mysql:8.0")
+	if fi, err := os.Stat("dump/.skeema"); err != nil || fi.IsDir() {
+		t.Fatalf("Expected dump/.skeema to exist, but it does not: %v", err)
+	}
+	if optionFile := getOptionFile(t, "dump/product", cfg); optionFile.SomeSectionHasOption("host") {
+		t.Error("Expected option file from --from-dump to omit host")
+	} else if val, _ := optionFile.OptionValue("default-character-set"); val != "latin1" {
+		t.Errorf("Expected default-character-set to be latin1, instead found %q", val)
+	}
+	for _, path := range []string{"dump/product/users.sql", "dump/analytics/pageviews.sql"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist, but it does not: %v", path, err)
+		}
+	}
+
+	// With --schema, only that schema is used, and schema-level subdirs are
+	// skipped
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --from-dump=dump.sql --workspace=parse --flavor=mysql:8.0 --schema=analytics --dir onlyanalytics")
+	if _, err := os.Stat("onlyanalytics/pageviews.sql"); err != nil {
+		t.Errorf("Expected onlyanalytics/pageviews.sql to exist, but it does not: %v", err)
+	}
+	if _, err := os.Stat("onlyanalytics/users.sql"); err == nil {
+		t.Error("Expected onlyanalytics/users.sql to not exist, but it does")
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestAddEnvHandler(t *testing.T) {
 	cfg := s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -218,7 +265,21 @@ func (s SkeemaIntegrationSuite) TestPullHandler(t *testing.T) {
 	// In analytics db, add one table and alter the schema's charset and collation;
 	// Create a new db and put one table in it
 	s.sourceSQL(t, "pull1.sql")
-	cfg := s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+
+	// With --changed-only, differences are reported but no files are modified.
+	// A file which only differs in line endings is not considered changed.
+	contents := fs.ReadTestFile(t, "mydb/product/users.sql")
+	fs.WriteTestFile(t, "mydb/product/users.sql", strings.ReplaceAll(contents, "\n", "\r\n"))
+	cfg := s.handleCommand(t, CodeDifferencesFound, ".", "skeema pull --changed-only")
+	fs.WriteTestFile(t, "mydb/product/users.sql", contents)
+	s.verifyFiles(t, cfg, "../golden/init")
+	fs.WriteTestFile(t, "mydb/product/users.sql", strings.ReplaceAll(contents, "\n", "\r\n"))
+
+	cfg = s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/users.sql") != strings.ReplaceAll(contents, "\n", "\r\n") {
+		t.Error("Expected mydb/product/users.sql to be left as-is, since it only differed in line endings")
+	}
+	fs.WriteTestFile(t, "mydb/product/users.sql", contents)
 	s.verifyFiles(t, cfg, "../golden/pull1")
 
 	// Revert db back to previous state, and pull again to test the opposite
@@ -237,7 +298,7 @@ func (s SkeemaIntegrationSuite) TestPullHandler(t *testing.T) {
 	// there were other changes triggering a file rewrite. Files containing
 	// commands plus a table that doesn't exist should be deleted, instead of
 	// leaving a file with lingering commands. Generator string should be updated.
-	contents := fs.ReadTestFile(t, "mydb/analytics/activity.sql")
+	contents = fs.ReadTestFile(t, "mydb/analytics/activity.sql")
 	fs.WriteTestFile(t, "mydb/analytics/activity.sql", strings.Replace(contents, "DEFAULT", "DEFALUT", 1))
 	s.dbExec(t, "product", "INSERT INTO comments (post_id, user_id) VALUES (555, 777)")
 	contents = fs.ReadTestFile(t, "mydb/product/comments.sql")
@@ -517,6 +578,138 @@ func (s SkeemaIntegrationSuite) TestDiffHandler(t *testing.T) {
 	}
 }
 
+func (s SkeemaIntegrationSuite) TestDiffAgainstDump(t *testing.T) {
+	cfg := s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Build a mysqldump-style file from the dir's *.sql files, with a USE before
+	// each schema's statements
+	var dump strings.Builder
+	for _, schemaName := range []string{"analytics", "product"} {
+		dir, err := fs.ParseDir("mydb/"+schemaName, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDir: %v", err)
+		}
+		fmt.Fprintf(&dump, "USE `%s`;\n", schemaName)
+		for _, sf := range dir.SQLFiles {
+			for _, stmt := range sf.Statements {
+				dump.WriteString(stmt.Text)
+			}
+		}
+	}
+	writeDump := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile("dump.sql", []byte(contents), 0666); err != nil {
+			t.Fatalf("Unable to write dump file: %v", err)
+		}
+	}
+	writeDump(dump.String())
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --against-dump=dump.sql")
+
+	// Removing the analytics schema's tables from the dump should result in
+	// differences for that dir only
+	writeDump(dump.String()[strings.Index(dump.String(), "USE `product`"):])
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema diff --against-dump=../../dump.sql")
+	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff --against-dump=../../dump.sql")
+
+	// Nonexistent dump path
+	s.handleCommand(t, CodeNoInput, ".", "skeema diff --against-dump=doesnt-exist.sql")
+}
+
+func (s SkeemaIntegrationSuite) TestCompareHandler(t *testing.T) {
+	host := fmt.Sprintf("%s:%d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeBadUsage, ".", "skeema compare %s %s", host, host)
+
+	// The suite only has one database server, so compare two of its schemas to
+	// each other to exercise the diff logic
+	product, err := s.d.Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	}
+	analytics, err := s.d.Schema("analytics")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	}
+	mods := tengo.StatementModifiers{AllowUnsafe: true, Flavor: s.d.Flavor()}
+	if diffCount, skipCount := compareSchemas(product, product, "product", mods); diffCount != 0 || skipCount != 0 {
+		t.Errorf("Expected no differences comparing schema to itself, instead found %d, %d", diffCount, skipCount)
+	}
+	expected := len(product.Tables) + len(product.Routines) + len(analytics.Tables) + len(analytics.Routines)
+	if diffCount, _ := compareSchemas(product, analytics, "product", mods); diffCount < expected {
+		t.Errorf("Expected at least %d differences, instead found %d", expected, diffCount)
+	}
+	if diffCount, _ := compareSchemas(nil, analytics, "analytics", mods); diffCount != 1+len(analytics.Tables)+len(analytics.Routines) {
+		t.Errorf("Unexpected difference count %d for schema only present on one side", diffCount)
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestPlanFile(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Save a plan, and confirm push --plan applies it
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --save-plan=plan.json")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --plan=plan.json")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Drift on the database side after saving the plan should cause push --plan
+	// to refuse to run
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --save-plan=plan.json")
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews ADD COLUMN extra int")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push --plan=plan.json")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+
+	// Nonexistent plan file
+	s.handleCommand(t, CodeBadConfig, ".", "skeema push --plan=doesnt-exist.json")
+}
+
+func (s SkeemaIntegrationSuite) TestSaveRollback(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Pushing a reversible change: the rollback script should undo it
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --save-rollback=down.sql")
+	contents := fs.ReadTestFile(t, "down.sql")
+	if !strings.Contains(contents, "USE `analytics`;\n") || !strings.Contains(contents, "DROP COLUMN `domain`") || strings.Contains(contents, "IRREVERSIBLE") {
+		t.Errorf("Unexpected rollback script contents:\n%s", contents)
+	}
+
+	// Pushing a destructive change: the rollback script should re-create the
+	// table, but note that its data is lost
+	s.dbExec(t, "analytics", "CREATE TABLE extra (id int unsigned NOT NULL, PRIMARY KEY (id))")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe --save-rollback=down.sql")
+	contents = fs.ReadTestFile(t, "down.sql")
+	if !strings.Contains(contents, "-- IRREVERSIBLE") || !strings.Contains(contents, "CREATE TABLE `extra`") {
+		t.Errorf("Unexpected rollback script contents:\n%s", contents)
+	}
+
+	// diff should also support writing a rollback script, without executing
+	// anything
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --save-rollback=down.sql")
+	if contents = fs.ReadTestFile(t, "down.sql"); !strings.Contains(contents, "DROP COLUMN `domain`") {
+		t.Errorf("Unexpected rollback script contents:\n%s", contents)
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestHistory(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeBadConfig, "mydb/analytics", "skeema history")                          // history-schema not set
+	s.handleCommand(t, CodeBadConfig, "mydb/analytics", "skeema history --history-schema=_skeema") // table doesn't exist yet
+
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --history-schema=_skeema")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema history --history-schema=_skeema")
+	entries, err := applier.QueryHistory(s.d.Instance, "_skeema", "analytics", 10)
+	if err != nil {
+		t.Fatalf("Unexpected error from QueryHistory: %v", err)
+	} else if len(entries) != 1 {
+		t.Fatalf("Expected 1 history entry, instead found %d", len(entries))
+	} else if e := entries[0]; !e.Success || e.ObjectName != "pageviews" || !strings.Contains(e.Statement, "ADD COLUMN `domain`") || len(e.Checksum) != 64 {
+		t.Errorf("Unexpected history entry: %+v", e)
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -1046,11 +1239,7 @@ func (s SkeemaIntegrationSuite) TestDirEdgeCases(t *testing.T) {
 func (s SkeemaIntegrationSuite) TestNonInnoClauses(t *testing.T) {
 	// MariaDB does not consider STORAGE or COLUMN_FORMAT clauses as valid SQL.
 	// Ditto for MySQL 5.5.
-	if s.d.Flavor().IsMariaDB() {
-		t.Skip("Test not relevant for MariaDB-based image", s.d.Flavor())
-	} else if s.d.Flavor().IsMySQL(5, 5) {
-		t.Skip("Test not relevant for 5.5-based image", s.d.Flavor())
-	}
+	tengo.SkipUnlessFlavor(t, "mysql:5.6+")
 
 	withClauses := "CREATE TABLE `problems` (\n" +
 		"  `name` varchar(30) /*!50606 STORAGE MEMORY */ /*!50606 COLUMN_FORMAT DYNAMIC */ DEFAULT NULL,\n" +
@@ -72,6 +72,10 @@ func (s *SkeemaIntegrationSuite) Teardown(backend string) error {
 	return nil
 }
 
+func (s *SkeemaIntegrationSuite) BackendFlavor(backend string) tengo.Flavor {
+	return s.d.Flavor()
+}
+
 func (s *SkeemaIntegrationSuite) BeforeTest(backend string) error {
 	// Clear data and re-source setup data
 	if err := s.d.NukeData(); err != nil {